@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/rogersnm/compass/internal/id"
+	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var adrCmd = &cobra.Command{
+	Use:   "adr",
+	Short: "Manage Architecture Decision Records",
+	Long: `Manage Architecture Decision Records (ADRs). ADRs are documents of kind
+"adr", numbered per project and moving from proposed to accepted, and to
+superseded when a later record replaces them. They can also be listed with
+"compass doc list --kind adr".`,
+}
+
+// adrTemplate is the body of a new ADR when nothing is piped on stdin.
+const adrTemplate = `## Context
+
+## Decision
+
+## Consequences
+`
+
+var adrNewCmd = &cobra.Command{
+	Use:   "new <title>",
+	Short: "Create a proposed ADR",
+	Long: `Create a proposed ADR with the next number in the project. The body is
+read from stdin; without it the record starts from a Context / Decision /
+Consequences template.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		body := readStdin()
+		if body == "" {
+			body = adrTemplate
+		}
+		d, err := s.CreateDocument(ctx, args[0], projectID, store.DocumentCreateOpts{Kind: model.DocADR, Body: body})
+		if err != nil {
+			return err
+		}
+		printCreated(d.ID, "Created %s %s (%s)\n", d.ADRLabel(), d.Title, d.ID)
+		return nil
+	},
+}
+
+var adrListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List a project's ADRs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		adrs, err := s.ListDocuments(ctx, store.DocumentFilter{ProjectID: projectID, Kind: model.DocADR})
+		if err != nil {
+			return err
+		}
+		slices.SortFunc(adrs, func(a, b model.Document) int { return cmp.Compare(a.Number, b.Number) })
+		out, err := markdown.RenderDocumentColumns(adrs, markdown.ADRColumns)
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+		return nil
+	},
+}
+
+var adrAcceptCmd = &cobra.Command{
+	Use:   "accept <id>",
+	Short: "Accept a proposed ADR",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		d, err := getADR(ctx, s, args[0])
+		if err != nil {
+			return err
+		}
+		if d.Status != model.ADRProposed {
+			return fmt.Errorf("%s is %s, only proposed ADRs can be accepted", d.ADRLabel(), d.Status)
+		}
+		accepted := model.ADRAccepted
+		if _, err := s.UpdateDocument(ctx, d.ID, store.DocumentUpdate{Status: &accepted}); err != nil {
+			return err
+		}
+		infof("Accepted %s %s\n", d.ADRLabel(), d.Title)
+		return nil
+	},
+}
+
+var adrSupersedeCmd = &cobra.Command{
+	Use:   "supersede <old> <new>",
+	Short: "Mark an ADR as superseded by a newer one",
+	Long: `Mark the old ADR as superseded and link the two records both ways
+(superseded_by on the old one, supersedes on the new one). A proposed new
+record is accepted, since it now carries the decision.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		old, err := getADR(ctx, s, args[0])
+		if err != nil {
+			return err
+		}
+		newer, err := getADR(ctx, s, args[1])
+		if err != nil {
+			return err
+		}
+		if old.ID == newer.ID {
+			return fmt.Errorf("an ADR cannot supersede itself")
+		}
+		if old.Project != newer.Project {
+			return fmt.Errorf("%s is in project %s, not %s", newer.ID, newer.Project, old.Project)
+		}
+		if old.Status == model.ADRSuperseded {
+			return fmt.Errorf("%s is already superseded by %s", old.ADRLabel(), old.SupersededBy)
+		}
+		if newer.Status == model.ADRSuperseded {
+			return fmt.Errorf("%s is itself superseded by %s", newer.ADRLabel(), newer.SupersededBy)
+		}
+
+		if err := supersedeADR(ctx, s, old, newer); err != nil {
+			return err
+		}
+		infof("%s %s is superseded by %s %s\n", old.ADRLabel(), old.Title, newer.ADRLabel(), newer.Title)
+		return nil
+	},
+}
+
+// supersedeADR links old and newer both ways, accepting newer if it was
+// proposed. If old can't be marked superseded, newer is put back as it was,
+// so neither record points at the other.
+func supersedeADR(ctx context.Context, s store.Store, old, newer *model.Document) error {
+	upd := store.DocumentUpdate{Supersedes: &old.ID}
+	if newer.Status == model.ADRProposed {
+		accepted := model.ADRAccepted
+		upd.Status = &accepted
+	}
+	if _, err := s.UpdateDocument(ctx, newer.ID, upd); err != nil {
+		return err
+	}
+	superseded := model.ADRSuperseded
+	if _, err := s.UpdateDocument(ctx, old.ID, store.DocumentUpdate{Status: &superseded, SupersededBy: &newer.ID}); err != nil {
+		revert := store.DocumentUpdate{Supersedes: &newer.Supersedes, Status: &newer.Status}
+		if _, rerr := s.UpdateDocument(ctx, newer.ID, revert); rerr != nil {
+			return fmt.Errorf("%w (reverting %s failed: %v)", err, newer.ID, rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// getADR fetches a document and checks that it is an ADR. Cloud responses
+// omit the project, so it is filled in from the ID.
+func getADR(ctx context.Context, s store.Store, docID string) (*model.Document, error) {
+	d, _, err := s.GetDocument(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Kind != model.DocADR {
+		return nil, fmt.Errorf("%s is not an ADR", d.ID)
+	}
+	if d.Project == "" {
+		d.Project, _ = id.ProjectKeyFrom(d.ID)
+	}
+	return d, nil
+}
+
+func init() {
+	adrNewCmd.Flags().StringP("project", "P", "", "project ID")
+	adrListCmd.Flags().StringP("project", "P", "", "project ID")
+
+	adrCmd.AddCommand(adrNewCmd)
+	adrCmd.AddCommand(adrListCmd)
+	adrCmd.AddCommand(adrAcceptCmd)
+	adrCmd.AddCommand(adrSupersedeCmd)
+	rootCmd.AddCommand(adrCmd)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+)
+
+// allStoresTasks lists the tasks filter matches on every store at once,
+// each marked with the store it came from, along with every task of those
+// stores' projects so blocked statuses show. A store without the filter's
+// project contributes nothing; one that doesn't answer is warned about.
+func allStoresTasks(ctx context.Context, filter store.TaskFilter) ([]model.Task, map[string]*model.Task, error) {
+	type listing struct {
+		tasks []model.Task
+		all   map[string]*model.Task
+	}
+	byStore, errs, err := store.FanOut(ctx, reg, "", fanOutTimeout, func(ctx context.Context, s store.Store) (listing, error) {
+		tasks, err := s.ListTasks(ctx, filter)
+		if err != nil {
+			return listing{}, ignoreNotFound(err)
+		}
+		all, err := s.AllTaskMap(ctx, filter.ProjectID)
+		return listing{tasks, all}, ignoreNotFound(err)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	warnUnreachable(errs)
+
+	var tasks []model.Task
+	allTasks := map[string]*model.Task{}
+	for _, name := range sortedKeys(byStore) {
+		for _, t := range byStore[name].tasks {
+			t.Store = name
+			tasks = append(tasks, t)
+		}
+		maps.Copy(allTasks, byStore[name].all)
+	}
+	return tasks, allTasks, nil
+}
+
+// allStoresDocuments is allStoresTasks for documents.
+func allStoresDocuments(ctx context.Context, filter store.DocumentFilter) ([]model.Document, error) {
+	byStore, errs, err := store.FanOut(ctx, reg, "", fanOutTimeout, func(ctx context.Context, s store.Store) ([]model.Document, error) {
+		docs, err := s.ListDocuments(ctx, filter)
+		return docs, ignoreNotFound(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	warnUnreachable(errs)
+
+	var docs []model.Document
+	for _, name := range sortedKeys(byStore) {
+		for _, d := range byStore[name] {
+			d.Store = name
+			docs = append(docs, d)
+		}
+	}
+	return docs, nil
+}
+
+func ignoreNotFound(err error) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// storeColumn adds the store column to the default columns of a listing
+// that spans stores, unless --columns chose them.
+func storeColumn(columns []string, chosen bool) []string {
+	if chosen {
+		return columns
+	}
+	return append(slices.Clip(columns), "store")
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rogersnm/compass/internal/ical"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var calendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "Export due dates and release targets as a calendar",
+}
+
+var calendarExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write an iCalendar (.ics) feed of task due dates and release target dates",
+	Long: `Write an iCalendar feed with an all-day event for every task with a due
+date and every release with a target date, for import into Google Calendar,
+Outlook and similar. "compass serve" offers the same feed over HTTP for
+calendar subscriptions.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+		feed, err := calendarFeed(ctx, []string{projectID})
+		if err != nil {
+			return err
+		}
+		if out, _ := cmd.Flags().GetString("output"); out != "" {
+			if err := os.WriteFile(out, feed, 0o644); err != nil {
+				return err
+			}
+			infof("Wrote %s\n", out)
+			return nil
+		}
+		_, err = os.Stdout.Write(feed)
+		return err
+	},
+}
+
+// calendarFeed builds one feed from the given projects' tasks and releases.
+func calendarFeed(ctx context.Context, projects []string) ([]byte, error) {
+	var tasks []model.Task
+	var releases []model.Release
+	for _, key := range projects {
+		s, err := storeForProject(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		pt, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: key})
+		if err != nil {
+			return nil, err
+		}
+		pr, err := s.ListReleases(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, pt...)
+		releases = append(releases, pr...)
+	}
+	name := "compass: " + strings.Join(projects, ", ")
+	return ical.Feed(name, tasks, releases, time.Now()), nil
+}
+
+func init() {
+	calendarExportCmd.Flags().StringP("project", "P", "", "project ID")
+	calendarExportCmd.Flags().StringP("output", "o", "", "write to file instead of stdout")
+
+	calendarCmd.AddCommand(calendarExportCmd)
+	rootCmd.AddCommand(calendarCmd)
+}
@@ -156,7 +156,7 @@ var claudeInitCmd = &cobra.Command{
 			return fmt.Errorf("writing agent file: %w", err)
 		}
 
-		fmt.Printf("Installed %s\n", agentPath)
+		infof("Installed %s\n", agentPath)
 		return nil
 	},
 }
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 
@@ -24,6 +25,7 @@ type fakeAPI struct {
 	documents map[string]map[string]any
 	taskSeq   int
 	docSeq    int
+	orgSlug   string // X-Org-Slug of the last request
 }
 
 func newFakeAPI() *fakeAPI {
@@ -51,7 +53,16 @@ func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		pfxDocuments = "/documents/"
 	)
 
+	f.orgSlug = r.Header.Get("X-Org-Slug")
+
 	switch {
+	// Organizations
+	case r.Method == "GET" && path == "/orgs":
+		json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+			{"slug": "personal", "name": "Personal", "role": "owner", "default": true},
+			{"slug": "acme", "name": "Acme Corp", "role": "member"},
+		}})
+
 	// Search
 	case r.Method == "GET" && path == "/search":
 		f.handleSearch(w, r)
@@ -184,6 +195,7 @@ func (f *fakeAPI) handleCreateTask(w http.ResponseWriter, r *http.Request, projI
 		"status":     "open",
 		"body":       body["body"],
 		"priority":   body["priority"],
+		"depends_on": body["depends_on"],
 		"project_key": projID,
 		"project":    projID,
 		"created_at": "2026-01-01T00:00:00Z",
@@ -241,6 +253,9 @@ func (f *fakeAPI) handleUpdateTask(w http.ResponseWriter, r *http.Request, taskI
 	if v, ok := body["priority"]; ok {
 		t["priority"] = v
 	}
+	if v, ok := body["depends_on"]; ok {
+		t["depends_on"] = v
+	}
 	f.tasks[taskID] = t
 	json.NewEncoder(w).Encode(map[string]any{"data": t})
 }
@@ -264,7 +279,7 @@ func (f *fakeAPI) handleReadyTasks(w http.ResponseWriter, projID string) {
 }
 
 func (f *fakeAPI) handleCreateDocument(w http.ResponseWriter, r *http.Request, projID string) {
-	var body map[string]string
+	var body map[string]any
 	json.NewDecoder(r.Body).Decode(&body)
 	f.docSeq++
 	hash := taskHashSuffixes[f.docSeq%len(taskHashSuffixes)]
@@ -275,6 +290,9 @@ func (f *fakeAPI) handleCreateDocument(w http.ResponseWriter, r *http.Request, p
 		"title":       body["title"],
 		"body":        body["body"],
 		"project":     projID,
+		"kind":        body["kind"],
+		"adr_number":  body["adr_number"],
+		"adr_status":  body["adr_status"],
 		"created_at":  "2026-01-01T00:00:00Z",
 	}
 	f.documents[displayID] = d
@@ -321,8 +339,10 @@ func (f *fakeAPI) handleUpdateDocument(w http.ResponseWriter, r *http.Request, d
 	if v, ok := body["title"]; ok {
 		d["title"] = v
 	}
-	if v, ok := body["body"]; ok {
-		d["body"] = v
+	for _, k := range []string{"body", "kind", "adr_number", "adr_status"} {
+		if v, ok := body[k]; ok {
+			d[k] = v
+		}
 	}
 	f.documents[docID] = d
 	json.NewEncoder(w).Encode(map[string]any{"data": d})
@@ -478,71 +498,39 @@ func TestCloudRouting_LocalMode(t *testing.T) {
 	// Should work fine with local store after migration
 	err := run(t, "project", "list")
 	assert.NoError(t, err)
-}
 
-// --- Config command tests ---
-
-func TestConfig_StatusLocal(t *testing.T) {
-	dir := t.TempDir()
-	dataDir = dir
-	cfg = &config.Config{
-		Version: 2, LocalEnabled: true, DefaultStore: "local",
-		Projects: map[string]string{},
-	}
-	require.NoError(t, config.Save(dir, cfg))
-	reg = store.NewRegistry(cfg, dir)
-	reg.Add("local", store.NewLocal(dir))
-
-	require.NoError(t, run(t, "config", "status"))
+	// and the upgrade is persisted
+	raw, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "mode:")
+	assert.Contains(t, string(raw), "version: 2")
 }
 
-func TestConfig_StatusCloud(t *testing.T) {
-	setupCloudEnv(t)
-
-	require.NoError(t, run(t, "config", "status"))
-}
+// --- Migrate command tests ---
 
-func TestConfig_StatusUnconfigured(t *testing.T) {
+func TestMigrateConfig(t *testing.T) {
 	dir := t.TempDir()
 	dataDir = dir
-	cfg = &config.Config{Version: 2, Projects: map[string]string{}}
-	require.NoError(t, config.Save(dir, cfg))
-	reg = store.NewRegistry(cfg, dir)
-
-	require.NoError(t, run(t, "config", "status"))
-}
-
-func TestConfig_Logout(t *testing.T) {
-	setupCloudEnv(t)
+	path := filepath.Join(dir, "config.yaml")
+	v1 := "cloud:\n  api_key: cpk_old\n"
+	require.NoError(t, os.WriteFile(path, []byte(v1), 0644))
 
-	require.NoError(t, run(t, "config", "logout"))
+	require.NoError(t, run(t, "migrate", "config", "--dry-run"))
+	raw, _ := os.ReadFile(path)
+	assert.Equal(t, v1, string(raw))
 
-	c, err := config.Load(dataDir)
+	require.NoError(t, run(t, "migrate", "config", "--dry-run=false"))
+	c, err := config.Load(dir)
 	require.NoError(t, err)
-	_, hasCloud := c.Stores["compasscloud.io"]
-	assert.False(t, hasCloud)
-}
-
-func TestConfig_LogoutWhenLocal(t *testing.T) {
-	dir := t.TempDir()
-	dataDir = dir
-	cfg = &config.Config{Version: 2, LocalEnabled: true, DefaultStore: "local", Projects: map[string]string{}}
-	require.NoError(t, config.Save(dir, cfg))
-	reg = store.NewRegistry(cfg, dir)
-	reg.Add("local", store.NewLocal(dir))
-
-	require.NoError(t, run(t, "config", "logout"))
-}
-
-func TestConfig_BypassesSetupPrompt(t *testing.T) {
-	dir := t.TempDir()
-	dataDir = dir
-	cfg = &config.Config{Version: 2, Projects: map[string]string{}}
-	require.NoError(t, config.Save(dir, cfg))
-	reg = store.NewRegistry(cfg, dir)
+	assert.Equal(t, 2, c.Version)
+	assert.Nil(t, c.Cloud)
+	assert.Equal(t, "cpk_old", c.Stores["compasscloud.io"].APIKey)
+	backup, err := os.ReadFile(path + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, v1, string(backup))
 
-	require.NoError(t, run(t, "config", "status"))
-	require.NoError(t, run(t, "config", "logout"))
+	// second run is a no-op
+	require.NoError(t, run(t, "migrate", "config"))
 }
 
 // --- Cloud mode project tests ---
@@ -633,6 +621,46 @@ func TestCloud_TaskCreate_Epic(t *testing.T) {
 	api.mu.Unlock()
 }
 
+func TestCloud_TaskDependencies(t *testing.T) {
+	api := setupCloudEnv(t)
+	api.mu.Lock()
+	seedProject(api, "CP")
+	api.mu.Unlock()
+	t.Cleanup(func() {
+		quiet = false
+		taskCreateCmd.Flags().Set("depends-on", "")
+		taskUpdateCmd.Flags().Set("depends-on", "")
+		taskReadyCmd.Flags().Set("all", "false")
+	})
+
+	first := captureStdout(t, func() { require.NoError(t, run(t, "-q", "task", "create", "First", "--project", "CP", "--type", "task")) })
+	first = strings.TrimSpace(first)
+	second := captureStdout(t, func() {
+		require.NoError(t, run(t, "-q", "task", "create", "Second", "--project", "CP", "--type", "task", "--depends-on", first))
+	})
+	second = strings.TrimSpace(second)
+
+	api.mu.Lock()
+	assert.Equal(t, []any{first}, api.tasks[second]["depends_on"])
+	api.mu.Unlock()
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "task", "ready", "--project", "CP", "--all")) })
+	assert.Contains(t, out, first)
+	assert.NotContains(t, out, second, "blocked by its dependency")
+
+	err := run(t, "task", "update", first, "--depends-on", second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cycle")
+
+	err = run(t, "task", "create", "Third", "--project", "CP", "--depends-on", "CP-TZZZZZ")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency CP-TZZZZZ not found")
+
+	require.NoError(t, run(t, "task", "close", first))
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "ready", "--project", "CP", "--all")) })
+	assert.Contains(t, out, second)
+}
+
 func TestCloud_TaskList(t *testing.T) {
 	api := setupCloudEnv(t)
 	api.mu.Lock()
@@ -1028,3 +1056,302 @@ func TestCloud_E2EWorkflow(t *testing.T) {
 	assert.Len(t, api.projects, 0)
 	api.mu.Unlock()
 }
+
+func TestCloud_StoreLogin_APIKey(t *testing.T) {
+	setupCloudEnv(t)
+	name := cfg.DefaultStore
+	t.Cleanup(func() { storeLoginCmd.Flags().Set("api-key", "") })
+
+	require.NoError(t, run(t, "store", "login", name, "--api-key", "fresh-key"))
+
+	saved, err := config.Load(dataDir)
+	require.NoError(t, err)
+	sc := saved.Stores[name]
+	assert.Equal(t, "fresh-key", sc.APIKey)
+	assert.Equal(t, name, sc.Hostname)
+	assert.Equal(t, "http", sc.Protocol)
+
+	assert.Error(t, run(t, "store", "login", "missing.example", "--api-key", "k"))
+	assert.Error(t, run(t, "store", "login", "local", "--api-key", "k"))
+}
+
+func TestCloud_StoreUseOrg(t *testing.T) {
+	api := setupCloudEnv(t)
+	name := cfg.DefaultStore
+	t.Cleanup(func() { storeUseOrgCmd.Flags().Set("clear", "false") })
+
+	require.NoError(t, run(t, "store", "orgs", name))
+
+	err := run(t, "store", "use-org", name, "nope", "--clear=false")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	require.NoError(t, run(t, "store", "use-org", name, "acme", "--clear=false"))
+	saved, err := config.Load(dataDir)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", saved.Stores[name].Org)
+
+	// Subsequent requests carry the org header.
+	require.NoError(t, run(t, "project", "list", "--only-store", ""))
+	api.mu.Lock()
+	assert.Equal(t, "acme", api.orgSlug)
+	api.mu.Unlock()
+
+	require.NoError(t, run(t, "store", "use-org", name, "--clear"))
+	saved, err = config.Load(dataDir)
+	require.NoError(t, err)
+	assert.Empty(t, saved.Stores[name].Org)
+}
+
+func TestCloud_TaskOpen(t *testing.T) {
+	api := setupCloudEnv(t)
+	api.mu.Lock()
+	seedProject(api, "CP")
+	taskID := seedTask(api, "CP", "AAAAA", "Cloud task")
+	api.mu.Unlock()
+
+	var opened, copied string
+	origOpen, origCopy := openBrowser, copyToClipboard
+	openBrowser = func(url string) { opened = url }
+	copyToClipboard = func(text string) error { copied = text; return nil }
+	t.Cleanup(func() {
+		openBrowser, copyToClipboard = origOpen, origCopy
+		f := taskOpenCmd.Flags().Lookup("copy")
+		f.Value.Set("")
+		f.Changed = false
+	})
+
+	require.NoError(t, run(t, "task", "open", taskID))
+	host := cfg.DefaultStore
+	assert.Equal(t, "http://"+host+"/tasks/"+taskID, opened)
+
+	require.NoError(t, run(t, "task", "open", taskID, "--copy"))
+	assert.Equal(t, opened, copied)
+
+	require.NoError(t, run(t, "task", "open", taskID, "--copy=id"))
+	assert.Equal(t, taskID, copied)
+}
+
+func TestCloud_TaskMoveAcrossStores(t *testing.T) {
+	api := setupCloudEnv(t)
+	api.mu.Lock()
+	seedProject(api, "CP")
+	taskID := seedTask(api, "CP", "AAAAA", "Cloud task")
+	api.mu.Unlock()
+
+	cfg.LocalEnabled = true
+	require.NoError(t, config.Save(dataDir, cfg))
+	ls := store.NewLocal(dataDir)
+	_, err := ls.CreateProject(t.Context(), "Local", "LP", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { taskMoveCmd.Flags().Set("to-project", "") })
+
+	require.NoError(t, run(t, "task", "move", taskID, "--to-project", "LP"))
+
+	moved, err := ls.ListTasks(t.Context(), store.TaskFilter{ProjectID: "LP"})
+	require.NoError(t, err)
+	require.Len(t, moved, 1)
+	assert.Equal(t, "Cloud task", moved[0].Title)
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	assert.NotContains(t, api.tasks, taskID)
+}
+
+func TestCloud_QualifiedRefs(t *testing.T) {
+	api := setupCloudEnv(t)
+	api.mu.Lock()
+	seedProject(api, "CP")
+	cloudID := seedTask(api, "CP", "AAAAA", "Cloud task")
+	api.mu.Unlock()
+
+	// The local store has a CP project too; the cache sends CP to the cloud.
+	cfg.LocalEnabled = true
+	require.NoError(t, config.Save(dataDir, cfg))
+	ls := store.NewLocal(dataDir)
+	_, err := ls.CreateProject(t.Context(), "Local", "CP", "")
+	require.NoError(t, err)
+	local, err := ls.CreateTask(t.Context(), "Local task", "CP", store.TaskCreateOpts{})
+	require.NoError(t, err)
+	t.Cleanup(func() { taskListCmd.Flags().Set("project", "") })
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "task", "show", cloudID)) })
+	assert.Contains(t, out, "Cloud task")
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "show", "local:"+local.ID)) })
+	assert.Contains(t, out, "Local task")
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "show", cfg.DefaultStore+":"+cloudID)) })
+	assert.Contains(t, out, "Cloud task")
+
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "list", "--project", "local:CP")) })
+	assert.Contains(t, out, "Local task")
+	assert.NotContains(t, out, "Cloud task")
+	assert.Equal(t, cfg.DefaultStore, cfg.Projects["CP"], "qualifying doesn't change the cache")
+}
+
+func TestCloud_StoreFetchConflict(t *testing.T) {
+	api := setupCloudEnv(t)
+	api.mu.Lock()
+	seedProject(api, "CP")
+	seedTask(api, "CP", "AAAAA", "Cloud task")
+	api.mu.Unlock()
+
+	cfg.LocalEnabled = true
+	require.NoError(t, config.Save(dataDir, cfg))
+	ls := store.NewLocal(dataDir)
+	_, err := ls.CreateProject(t.Context(), "Local", "CP", "")
+	require.NoError(t, err)
+	_, err = ls.CreateTask(t.Context(), "Local task", "CP", store.TaskCreateOpts{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		storeFetchCmd.Flags().Set("store", "")
+		storeFetchCmd.Flags().Set("all", "false")
+		storeFetchCmd.Flags().Set("on-conflict", "skip")
+		taskListCmd.Flags().Set("project", "")
+		taskCreateCmd.Flags().Set("project", "")
+	})
+	cloud := cfg.DefaultStore
+
+	out := captureStderr(t, func() { require.NoError(t, run(t, "store", "fetch", "--store", "local", "--all")) })
+	assert.Contains(t, out, "Warning: project already mapped, skipping (see --on-conflict) project=CP store="+cloud)
+	assert.ErrorContains(t, run(t, "store", "fetch", "--all", "--on-conflict", "merge"), "invalid --on-conflict")
+
+	require.NoError(t, run(t, "store", "fetch", "--store", "local", "--all", "--on-conflict", "alias"))
+	c, err := config.Load(dataDir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"CP": cloud, "CP@local": "local"}, c.Projects)
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "list", "--project", "CP@local")) })
+	assert.Contains(t, out, "Local task")
+	assert.NotContains(t, out, "Cloud task")
+
+	delete(c.Projects, "CP@local")
+	require.NoError(t, config.Save(dataDir, c))
+	require.NoError(t, run(t, "store", "fetch", "--store", "local", "--all", "--on-conflict", "both"))
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "list", "--project", "CP")) })
+	assert.Contains(t, out, "Local task")
+	assert.Contains(t, out, "Cloud task")
+	assert.ErrorIs(t, run(t, "task", "create", "New", "--project", "CP", "--type", "task"), store.ErrReadOnly)
+	require.NoError(t, run(t, "task", "create", "New", "--project", "local:CP", "--type", "task"))
+}
+
+func TestCloud_ListAllStores(t *testing.T) {
+	api := setupCloudEnv(t)
+	api.mu.Lock()
+	seedProject(api, "CP")
+	seedTask(api, "CP", "AAAAA", "Cloud task")
+	seedDoc(api, "CP", "AAAAA", "Cloud doc")
+	api.mu.Unlock()
+
+	cfg.LocalEnabled = true
+	require.NoError(t, config.Save(dataDir, cfg))
+	ls := store.NewLocal(dataDir)
+	_, err := ls.CreateProject(t.Context(), "Local", "LP", "")
+	require.NoError(t, err)
+	_, err = ls.CreateTask(t.Context(), "Local task", "LP", store.TaskCreateOpts{})
+	require.NoError(t, err)
+	_, err = ls.CreateDocument(t.Context(), "Local doc", "LP", store.DocumentCreateOpts{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		taskListCmd.Flags().Set("all-stores", "false")
+		taskListCmd.Flags().Set("project", "")
+		docListCmd.Flags().Set("all-stores", "false")
+		taskListCmd.Flags().Set("output", "table")
+		docListCmd.Flags().Set("output", "table")
+	})
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "task", "list", "--all-stores")) })
+	assert.Contains(t, out, "Store")
+	assert.Contains(t, out, "Local task")
+	assert.Contains(t, out, "Cloud task")
+	assert.Contains(t, out, cfg.DefaultStore)
+
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "list", "--all-stores", "--project", "LP", "-o", "csv")) })
+	assert.Contains(t, out, "Local task")
+	assert.NotContains(t, out, "Cloud task")
+	assert.Contains(t, out, ",local\n")
+
+	out = captureStdout(t, func() { require.NoError(t, run(t, "doc", "list", "--all-stores", "-o", "csv")) })
+	assert.Contains(t, out, "Local doc")
+	assert.Contains(t, out, "Cloud doc")
+	assert.Contains(t, out, ",store\n")
+}
+
+func TestCloud_StoreOverride(t *testing.T) {
+	api := setupCloudEnv(t)
+	api.mu.Lock()
+	seedProject(api, "CP")
+	seedTask(api, "CP", "AAAAA", "Cloud task")
+	api.mu.Unlock()
+
+	cfg.LocalEnabled = true
+	require.NoError(t, config.Save(dataDir, cfg))
+	ls := store.NewLocal(dataDir)
+	_, err := ls.CreateProject(t.Context(), "Local", "CP", "")
+	require.NoError(t, err)
+	_, err = ls.CreateTask(t.Context(), "Local task", "CP", store.TaskCreateOpts{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		storeFlag = ""
+		taskListCmd.Flags().Set("project", "")
+	})
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "task", "list", "--project", "CP", "--store", "local")) })
+	assert.Contains(t, out, "Local task")
+	assert.NotContains(t, out, "Cloud task")
+	storeFlag = ""
+
+	// COMPASS_PROJECT stands in for --project, and COMPASS_STORE for --store.
+	t.Setenv("COMPASS_PROJECT", "CP")
+	taskListCmd.Flags().Set("project", "")
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "list")) })
+	assert.Contains(t, out, "Cloud task")
+	t.Setenv("COMPASS_STORE", "local")
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "list")) })
+	assert.Contains(t, out, "Local task")
+	assert.NotContains(t, out, "Cloud task")
+
+	t.Setenv("COMPASS_STORE", "nowhere")
+	assert.ErrorContains(t, run(t, "task", "list"), `store "nowhere" not configured`)
+}
+
+func TestCloud_StorePing(t *testing.T) {
+	setupCloudEnv(t)
+	require.NoError(t, run(t, "store", "ping"))
+
+	cfg.Stores["dead.invalid"] = config.CloudStoreConfig{Hostname: "127.0.0.1:1", APIKey: "k", Protocol: "http"}
+	require.NoError(t, config.Save(dataDir, cfg))
+	err := run(t, "store", "ping", "dead.invalid")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 store(s) failed")
+}
+
+func TestCloud_Debug(t *testing.T) {
+	api := setupCloudEnv(t)
+	seedProject(api, "CP")
+	t.Cleanup(func() {
+		rootCmd.PersistentFlags().Set("debug", "")
+		debugOut = nil
+	})
+
+	require.NoError(t, run(t, "project", "list", "--debug=file"))
+	data, err := os.ReadFile(filepath.Join(dataDir, debugLogFile))
+	require.NoError(t, err)
+	assert.Regexp(t, `GET /api/v1/projects\?limit=100 200 \d+ms`, string(data))
+
+	t.Setenv("COMPASS_DEBUG", "bogus")
+	rootCmd.PersistentFlags().Set("debug", "")
+	err = run(t, "project", "list")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid debug mode")
+}
+
+func TestRotatingLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debug.log")
+	l := &rotatingLog{path: path, max: 10}
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		_, err := l.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	cur, _ := os.ReadFile(path)
+	old, _ := os.ReadFile(path + ".1")
+	assert.Equal(t, "third\n", string(cur))
+	assert.Equal(t, "second\n", string(old))
+}
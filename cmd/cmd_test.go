@@ -1,14 +1,35 @@
 package cmd
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/markdown"
 	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/pin"
+	"github.com/rogersnm/compass/internal/reminder"
 	"github.com/rogersnm/compass/internal/repofile"
 	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -43,7 +64,7 @@ func TestProjectCreate_Success(t *testing.T) {
 	s, _ := setupEnv(t)
 	require.NoError(t, run(t, "project", "create", "Test Project"))
 
-	projects, err := s.ListProjects()
+	projects, err := s.ListProjects(t.Context())
 	require.NoError(t, err)
 	require.Len(t, projects, 1)
 	assert.Equal(t, "Test Project", projects[0].Name)
@@ -53,7 +74,7 @@ func TestProjectCreate_WithKey(t *testing.T) {
 	s, _ := setupEnv(t)
 	require.NoError(t, run(t, "project", "create", "Test Project", "--key", "TP"))
 
-	projects, err := s.ListProjects()
+	projects, err := s.ListProjects(t.Context())
 	require.NoError(t, err)
 	require.Len(t, projects, 1)
 	assert.Equal(t, "TP", projects[0].ID)
@@ -78,7 +99,7 @@ func TestProjectShow_NotFound(t *testing.T) {
 
 func TestProjectSetDefault(t *testing.T) {
 	s, dir := setupEnv(t)
-	p, err := s.CreateProject("Test Project", "TP", "")
+	p, err := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	require.NoError(t, err)
 	reg.CacheProject(p.ID, "local")
 
@@ -91,36 +112,159 @@ func TestProjectSetDefault(t *testing.T) {
 
 func TestDocCreate_WithProject(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
 
 	require.NoError(t, run(t, "doc", "create", "My Doc", "--project", p.ID))
 
-	docs, err := s.ListDocuments(p.ID)
+	docs, err := s.ListDocuments(t.Context(), store.DocumentFilter{ProjectID: p.ID})
 	require.NoError(t, err)
 	assert.Len(t, docs, 1)
 }
 
 func TestDocCreate_DefaultProject(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
 
 	require.NoError(t, run(t, "doc", "create", "My Doc", "--project", p.ID))
 
-	docs, err := s.ListDocuments(p.ID)
+	docs, err := s.ListDocuments(t.Context(), store.DocumentFilter{ProjectID: p.ID})
 	require.NoError(t, err)
 	assert.Len(t, docs, 1)
 }
 
+func TestDocList_Kind(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	s.CreateDocument(t.Context(), "Deploy runbook", p.ID, store.DocumentCreateOpts{Kind: model.DocRunbook})
+
+	require.NoError(t, run(t, "doc", "create", "Use Postgres", "--project", p.ID, "--kind", "adr"))
+	t.Cleanup(func() {
+		docCreateCmd.Flags().Set("kind", "")
+		docListCmd.Flags().Set("kind", "")
+	})
+
+	var err error
+	out := captureStdout(t, func() { err = run(t, "doc", "list", "--project", p.ID, "--kind", "adr") })
+	require.NoError(t, err)
+	assert.Contains(t, out, "ADR-0001")
+	assert.Contains(t, out, "proposed")
+	assert.NotContains(t, out, "Deploy runbook")
+
+	assert.ErrorContains(t, run(t, "doc", "list", "--project", p.ID, "--kind", "memo"), "invalid document kind")
+}
+
+func TestDocList_Filters(t *testing.T) {
+	s, _ := setupEnv(t)
+	t.Setenv("COMPASS_ACTOR", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	t.Cleanup(func() {
+		store.SetActor("")
+		for _, f := range []string{"created-by", "since", "search", "sort"} {
+			docListCmd.Flags().Set(f, "")
+		}
+	})
+	store.SetActor("alice")
+	s.CreateDocument(t.Context(), "Login spec", p.ID, store.DocumentCreateOpts{Body: "OAuth flow"})
+	store.SetActor("bob")
+	s.CreateDocument(t.Context(), "Billing spec", p.ID, store.DocumentCreateOpts{Body: "Invoices"})
+	s.CreateDocument(t.Context(), "Auth runbook", p.ID, store.DocumentCreateOpts{Body: "Rotate oauth keys"})
+
+	list := func(args ...string) string {
+		var err error
+		out := captureStdout(t, func() { err = run(t, append([]string{"doc", "list", "-P", p.ID}, args...)...) })
+		require.NoError(t, err)
+		return out
+	}
+	out := list("--created-by", "bob", "--search", "oauth")
+	assert.Contains(t, out, "Auth runbook")
+	assert.NotContains(t, out, "Login spec")
+	assert.NotContains(t, out, "Billing spec")
+
+	out = list("--created-by", "", "--search", "", "--since", "1d", "--sort", "title")
+	assert.Less(t, strings.Index(out, "Auth runbook"), strings.Index(out, "Billing spec"))
+	assert.Less(t, strings.Index(out, "Billing spec"), strings.Index(out, "Login spec"))
+
+	assert.ErrorContains(t, run(t, "doc", "list", "-P", p.ID, "--since", "last week"), "invalid --since")
+}
+
+func TestADRWorkflow(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+
+	require.NoError(t, run(t, "adr", "new", "Use MySQL", "--project", p.ID))
+	require.NoError(t, run(t, "adr", "new", "Use Postgres", "--project", p.ID))
+	docs, err := s.ListDocuments(t.Context(), store.DocumentFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	byNumber := map[int]model.Document{}
+	for _, d := range docs {
+		byNumber[d.Number] = d
+	}
+	mysql, postgres := byNumber[1], byNumber[2]
+	_, body, _ := s.GetDocument(t.Context(), mysql.ID)
+	assert.Contains(t, body, "## Decision")
+
+	require.NoError(t, run(t, "adr", "accept", mysql.ID))
+	assert.ErrorContains(t, run(t, "adr", "accept", mysql.ID), "only proposed ADRs")
+
+	require.NoError(t, run(t, "adr", "supersede", mysql.ID, postgres.ID))
+	old, _, _ := s.GetDocument(t.Context(), mysql.ID)
+	assert.Equal(t, model.ADRSuperseded, old.Status)
+	assert.Equal(t, postgres.ID, old.SupersededBy)
+	newer, _, _ := s.GetDocument(t.Context(), postgres.ID)
+	assert.Equal(t, model.ADRAccepted, newer.Status)
+	assert.Equal(t, mysql.ID, newer.Supersedes)
+
+	assert.ErrorContains(t, run(t, "adr", "supersede", mysql.ID, postgres.ID), "already superseded")
+
+	spec, _ := s.CreateDocument(t.Context(), "Spec", p.ID, store.DocumentCreateOpts{Kind: model.DocSpec})
+	assert.ErrorContains(t, run(t, "adr", "accept", spec.ID), "is not an ADR")
+
+	out := captureStdout(t, func() { err = run(t, "adr", "list", "--project", p.ID) })
+	require.NoError(t, err)
+	assert.Contains(t, out, "ADR-0002")
+	assert.Contains(t, out, "superseded")
+}
+
+// failingUpdate is a store that refuses to update one document.
+type failingUpdate struct {
+	store.Store
+	id string
+}
+
+func (f failingUpdate) UpdateDocument(ctx context.Context, docID string, upd store.DocumentUpdate) (*model.Document, error) {
+	if docID == f.id {
+		return nil, errors.New("disk full")
+	}
+	return f.Store.UpdateDocument(ctx, docID, upd)
+}
+
+func TestSupersedeADR_Reverts(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	old, _ := s.CreateDocument(t.Context(), "Use MySQL", p.ID, store.DocumentCreateOpts{Kind: model.DocADR})
+	newer, _ := s.CreateDocument(t.Context(), "Use Postgres", p.ID, store.DocumentCreateOpts{Kind: model.DocADR})
+
+	err := supersedeADR(t.Context(), failingUpdate{s, old.ID}, old, newer)
+	require.ErrorContains(t, err, "disk full")
+	got, _, _ := s.GetDocument(t.Context(), newer.ID)
+	assert.Equal(t, model.ADRProposed, got.Status)
+	assert.Empty(t, got.Supersedes)
+}
+
 func TestTaskCreate_Minimal(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
 
 	require.NoError(t, run(t, "task", "create", "My Task", "--project", p.ID, "--type", "task"))
 
-	tasks, err := s.ListTasks(store.TaskFilter{ProjectID: p.ID})
+	tasks, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
 	require.NoError(t, err)
 	assert.Len(t, tasks, 1)
 	assert.Equal(t, model.TypeTask, tasks[0].Type)
@@ -128,12 +272,12 @@ func TestTaskCreate_Minimal(t *testing.T) {
 
 func TestTaskCreate_EpicType(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
 
 	require.NoError(t, run(t, "task", "create", "Auth Epic", "--project", p.ID, "--type", "epic"))
 
-	tasks, err := s.ListTasks(store.TaskFilter{ProjectID: p.ID, Type: model.TypeEpic})
+	tasks, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID, Type: model.TypeEpic})
 	require.NoError(t, err)
 	assert.Len(t, tasks, 1)
 	assert.Equal(t, "Auth Epic", tasks[0].Title)
@@ -142,12 +286,12 @@ func TestTaskCreate_EpicType(t *testing.T) {
 
 func TestTaskCreate_WithPriority(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
 
 	require.NoError(t, run(t, "task", "create", "Urgent", "--project", p.ID, "--type", "task", "--priority", "1"))
 
-	tasks, err := s.ListTasks(store.TaskFilter{ProjectID: p.ID})
+	tasks, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
 	require.NoError(t, err)
 	require.Len(t, tasks, 1)
 	require.NotNil(t, tasks[0].Priority)
@@ -156,12 +300,12 @@ func TestTaskCreate_WithPriority(t *testing.T) {
 
 func TestTaskCreate_NoPriority(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
 
 	require.NoError(t, run(t, "task", "create", "Normal", "--project", p.ID, "--type", "task", "--priority", "-1"))
 
-	tasks, err := s.ListTasks(store.TaskFilter{ProjectID: p.ID})
+	tasks, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
 	require.NoError(t, err)
 	require.Len(t, tasks, 1)
 	assert.Nil(t, tasks[0].Priority)
@@ -169,13 +313,13 @@ func TestTaskCreate_NoPriority(t *testing.T) {
 
 func TestTaskUpdate_Priority(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	task, _ := s.CreateTask("Task", p.ID, store.TaskCreateOpts{})
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
 
 	require.NoError(t, run(t, "task", "update", task.ID, "--priority", "0"))
 
-	got, _, err := s.GetTask(task.ID)
+	got, _, err := s.GetTask(t.Context(), task.ID)
 	require.NoError(t, err)
 	require.NotNil(t, got.Priority)
 	assert.Equal(t, 0, *got.Priority)
@@ -183,61 +327,98 @@ func TestTaskUpdate_Priority(t *testing.T) {
 
 func TestTaskCreate_WithDeps(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	t1, _ := s.CreateTask("Dep", p.ID, store.TaskCreateOpts{})
+	t1, _ := s.CreateTask(t.Context(), "Dep", p.ID, store.TaskCreateOpts{})
 
 	require.NoError(t, run(t, "task", "create", "My Task", "--project", p.ID, "--type", "task", "--depends-on", t1.ID))
 
-	tasks, err := s.ListTasks(store.TaskFilter{ProjectID: p.ID})
+	tasks, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
 	require.NoError(t, err)
 	assert.Len(t, tasks, 2)
 }
 
 func TestTaskUpdate_Status(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	task, _ := s.CreateTask("Task", p.ID, store.TaskCreateOpts{})
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
 
 	require.NoError(t, run(t, "task", "update", task.ID, "--status", "in_progress"))
 
-	got, _, err := s.GetTask(task.ID)
+	got, _, err := s.GetTask(t.Context(), task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, model.StatusInProgress, got.Status)
 }
 
 func TestTaskStart(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	task, _ := s.CreateTask("Task", p.ID, store.TaskCreateOpts{})
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
 
 	require.NoError(t, run(t, "task", "start", task.ID))
 
-	got, _, err := s.GetTask(task.ID)
+	got, _, err := s.GetTask(t.Context(), task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, model.StatusInProgress, got.Status)
 }
 
 func TestTaskClose(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	task, _ := s.CreateTask("Task", p.ID, store.TaskCreateOpts{})
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
 
 	require.NoError(t, run(t, "task", "close", task.ID))
 
-	got, _, err := s.GetTask(task.ID)
+	got, _, err := s.GetTask(t.Context(), task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, model.StatusClosed, got.Status)
 }
 
+func TestTaskClose_AutoClosesEpic(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	t.Cleanup(func() {
+		projectUpdateCmd.Flags().Set("auto-close-epics", "false")
+		epicUpdateCmd.Flags().Set("auto-close", "false")
+	})
+	require.NoError(t, run(t, "project", "update", p.ID, "--auto-close-epics"))
+
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
+	a, _ := s.CreateTask(t.Context(), "A", p.ID, store.TaskCreateOpts{Epic: epic.ID})
+	b, _ := s.CreateTask(t.Context(), "B", p.ID, store.TaskCreateOpts{Epic: epic.ID})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "task", "close", a.ID))
+	})
+	assert.NotContains(t, out, "Closed epic")
+
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "task", "close", b.ID))
+	})
+	assert.Contains(t, out, "Closed epic "+epic.ID+" (all 2 tasks closed)")
+
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "task", "close", b.ID))
+	})
+	assert.NotContains(t, out, "Closed epic", "closing a closed task reports nothing")
+
+	require.NoError(t, run(t, "epic", "update", epic.ID, "--auto-close=false"))
+	got, _, err := s.GetTask(t.Context(), epic.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.AutoClose)
+	assert.False(t, *got.AutoClose)
+	assert.Nil(t, got.ClosedAt, "an epic that doesn't auto-close isn't closed")
+}
+
 func TestTaskStart_EpicRejected(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	epic, _ := s.CreateTask("Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
 
 	err := run(t, "task", "start", epic.ID)
 	assert.Error(t, err)
@@ -246,9 +427,9 @@ func TestTaskStart_EpicRejected(t *testing.T) {
 
 func TestTaskClose_EpicRejected(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	epic, _ := s.CreateTask("Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
 
 	err := run(t, "task", "close", epic.ID)
 	assert.Error(t, err)
@@ -257,9 +438,9 @@ func TestTaskClose_EpicRejected(t *testing.T) {
 
 func TestTaskUpdate_EpicStatusRejected(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	epic, _ := s.CreateTask("Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
 
 	err := run(t, "task", "update", epic.ID, "--status", "in_progress")
 	assert.Error(t, err)
@@ -268,62 +449,123 @@ func TestTaskUpdate_EpicStatusRejected(t *testing.T) {
 
 func TestTaskReady(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	s.CreateTask("Ready Task", p.ID, store.TaskCreateOpts{})
+	s.CreateTask(t.Context(), "Ready Task", p.ID, store.TaskCreateOpts{})
 
 	require.NoError(t, run(t, "task", "ready", "--project", p.ID))
 }
 
 func TestTaskReady_All(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	s.CreateTask("T1", p.ID, store.TaskCreateOpts{})
-	s.CreateTask("T2", p.ID, store.TaskCreateOpts{})
+	s.CreateTask(t.Context(), "T1", p.ID, store.TaskCreateOpts{})
+	s.CreateTask(t.Context(), "T2", p.ID, store.TaskCreateOpts{})
 
 	require.NoError(t, run(t, "task", "ready", "--project", p.ID, "--all"))
 }
 
 func TestTaskDelete_Force(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	task, _ := s.CreateTask("Task", p.ID, store.TaskCreateOpts{})
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
 
 	require.NoError(t, run(t, "task", "delete", task.ID, "--force"))
 
-	_, _, err := s.GetTask(task.ID)
+	_, _, err := s.GetTask(t.Context(), task.ID)
 	assert.Error(t, err)
 }
 
+func TestTaskDelete_Dependents(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	t.Cleanup(func() {
+		taskDeleteCmd.Flags().Set("force", "false")
+		taskDeleteCmd.Flags().Set("cascade", "false")
+	})
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
+	other, _ := s.CreateTask(t.Context(), "Other", p.ID, store.TaskCreateOpts{})
+	only, _ := s.CreateTask(t.Context(), "Only", p.ID, store.TaskCreateOpts{DependsOn: []string{task.ID}})
+	both, _ := s.CreateTask(t.Context(), "Both", p.ID, store.TaskCreateOpts{DependsOn: []string{task.ID, other.ID}})
+
+	var err error
+	out := captureStdout(t, func() {
+		err = run(t, "task", "delete", task.ID, "--force")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is a dependency of 2 tasks")
+	assert.Contains(t, out, only.ID+"  Only (left with a dangling dependency)")
+	_, _, err = s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err, "nothing is deleted")
+
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "task", "delete", task.ID, "--force", "--cascade"))
+	})
+	assert.Contains(t, out, only.ID+"  Only (unblocked)")
+	assert.NotContains(t, out, both.ID+"  Both (")
+	assert.Contains(t, out, both.ID+" no longer depends on "+task.ID)
+	got, _, _ := s.GetTask(t.Context(), both.ID)
+	assert.Equal(t, []string{other.ID}, got.DependsOn)
+	got, _, _ = s.GetTask(t.Context(), only.ID)
+	assert.Empty(t, got.DependsOn)
+}
+
+func TestTaskClose_ShowsUnblocked(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
+	other, _ := s.CreateTask(t.Context(), "Other", p.ID, store.TaskCreateOpts{})
+	only, _ := s.CreateTask(t.Context(), "Only", p.ID, store.TaskCreateOpts{DependsOn: []string{task.ID}})
+	both, _ := s.CreateTask(t.Context(), "Both", p.ID, store.TaskCreateOpts{DependsOn: []string{task.ID, other.ID}})
+
+	t.Cleanup(func() { rootCmd.PersistentFlags().Set("dry-run", "false") })
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "task", "close", task.ID, "--dry-run"))
+	})
+	assert.Contains(t, out, only.ID+"  Only (unblocked)")
+	got, _, _ := s.GetTask(t.Context(), task.ID)
+	assert.Equal(t, model.StatusOpen, got.Status, "nothing is closed")
+	rootCmd.PersistentFlags().Set("dry-run", "false")
+
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "task", "close", task.ID))
+	})
+	assert.Contains(t, out, only.ID+"  Only (unblocked)")
+	assert.NotContains(t, out, both.ID)
+	assert.Less(t, strings.Index(out, "(unblocked)"), strings.Index(out, "Closed task"), "impact is shown before closing")
+}
+
 func TestDocDelete_Force(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	d, _ := s.CreateDocument("Doc", p.ID, "body")
+	d, _ := s.CreateDocument(t.Context(), "Doc", p.ID, store.DocumentCreateOpts{Body: "body"})
 
 	require.NoError(t, run(t, "doc", "delete", d.ID, "--force"))
 
-	_, _, err := s.GetDocument(d.ID)
+	_, _, err := s.GetDocument(t.Context(), d.ID)
 	assert.Error(t, err)
 }
 
 func TestProjectDelete_Force(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	s.CreateTask("Task", p.ID, store.TaskCreateOpts{})
+	s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
 
 	require.NoError(t, run(t, "project", "delete", p.ID, "--force"))
 
-	_, _, err := s.GetProject(p.ID)
+	_, _, err := s.GetProject(t.Context(), p.ID)
 	assert.Error(t, err)
 }
 
 func TestProjectDelete_ClearsDefault(t *testing.T) {
 	s, dir := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
 	cfg.DefaultProject = p.ID
 	config.Save(dir, cfg)
@@ -337,7 +579,7 @@ func TestProjectDelete_ClearsDefault(t *testing.T) {
 
 func TestProjectDelete_UncachesProject(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
 
 	require.NoError(t, run(t, "project", "delete", p.ID, "--force"))
@@ -345,9 +587,76 @@ func TestProjectDelete_UncachesProject(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestProjectRekey(t *testing.T) {
+	s, dir := setupEnv(t)
+	s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject("TP", "local")
+	task, _ := s.CreateTask(t.Context(), "Task", "TP", store.TaskCreateOpts{})
+	cfg.DefaultProject = "TP"
+	config.Save(dir, cfg)
+
+	origDir, _ := os.Getwd()
+	tmpDir := t.TempDir()
+	os.Chdir(tmpDir)
+	defer os.Chdir(origDir)
+	require.NoError(t, repofile.Write(tmpDir, "TP"))
+
+	require.NoError(t, run(t, "project", "rekey", "TP", "NEW"))
+
+	_, _, err := s.GetTask(t.Context(), "NEW"+strings.TrimPrefix(task.ID, "TP"))
+	require.NoError(t, err)
+	c, err := config.Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "NEW", c.DefaultProject)
+	assert.Equal(t, "local", c.Projects["NEW"])
+	assert.NotContains(t, c.Projects, "TP")
+	linked, _ := repofile.Read(tmpDir)
+	assert.Equal(t, "NEW", linked)
+
+	require.NoError(t, run(t, "project", "rename", "NEW", "--name", "Renamed"))
+	p, _, err := s.GetProject(t.Context(), "NEW")
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", p.Name)
+}
+
+func TestProjectUpdate(t *testing.T) {
+	s, _ := setupEnv(t)
+	s.CreateProject(t.Context(), "Auth", "AUTH", "Old overview")
+	reg.CacheProject("AUTH", "local")
+	t.Cleanup(func() { projectUpdateCmd.Flags().Set("name", "") })
+
+	withStdin(t, "## Goals\n\nSingle sign-on.\n")
+	require.NoError(t, run(t, "project", "update", "AUTH", "--name", "Authentication"))
+	p, body, err := s.GetProject(t.Context(), "AUTH")
+	require.NoError(t, err)
+	assert.Equal(t, "Authentication", p.Name)
+	assert.Contains(t, body, "Single sign-on.")
+	assert.NotContains(t, body, "Old overview")
+}
+
+func TestProjectList_FlagsUnconfirmed(t *testing.T) {
+	s, _ := setupEnv(t)
+	s.CreateProject(t.Context(), "Live", "LIVE", "")
+	reg.CacheProject("LIVE", "local")
+	cfg.Projects["GONE"] = "local"
+	cfg.Projects["FAR"] = "far.example"
+
+	listed := []markdown.ProjectRow{{Project: model.Project{ID: "LIVE"}, StoreName: "local"}}
+	errs := []store.StoreError{{Store: "far.example", Err: fmt.Errorf("timed out")}}
+	rows := unconfirmedProjects(listed, errs, "")
+	require.Len(t, rows, 2)
+	assert.Equal(t, "FAR", rows[0].Project.ID)
+	assert.Equal(t, "unreachable", rows[0].Note)
+	assert.Equal(t, "GONE", rows[1].Project.ID)
+	assert.Equal(t, "stale", rows[1].Note)
+
+	assert.Empty(t, unconfirmedProjects(listed, nil, "other"))
+	require.NoError(t, run(t, "project", "list"))
+}
+
 func TestProjectSetStore(t *testing.T) {
 	s, dir := setupEnv(t)
-	p, err := s.CreateProject("Test Project", "TP", "")
+	p, err := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	require.NoError(t, err)
 	reg.CacheProject(p.ID, "local")
 
@@ -360,7 +669,7 @@ func TestProjectSetStore(t *testing.T) {
 
 func TestProjectSetStore_InvalidStore(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, err := s.CreateProject("Test Project", "TP", "")
+	p, err := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	require.NoError(t, err)
 	reg.CacheProject(p.ID, "local")
 
@@ -371,25 +680,82 @@ func TestProjectSetStore_InvalidStore(t *testing.T) {
 
 func TestTaskGraph(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	s.CreateTask("Root", p.ID, store.TaskCreateOpts{})
+	s.CreateTask(t.Context(), "Root", p.ID, store.TaskCreateOpts{})
 
 	require.NoError(t, run(t, "task", "graph", "--project", p.ID))
 }
 
 func TestSearch_NoResults(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
 	require.NoError(t, run(t, "search", "xyznonexistent"))
 }
 
+func TestSearch_Query(t *testing.T) {
+	s, _ := setupEnv(t)
+	t.Cleanup(func() {
+		for _, f := range []string{"project", "type", "regex", "title-only", "body-only"} {
+			searchCmd.Flags().Set(f, searchCmd.Flags().Lookup(f).DefValue)
+		}
+	})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	p1 := 1
+	s.CreateTask(t.Context(), "OAuth login", p.ID, store.TaskCreateOpts{Priority: &p1, Assignee: "alice"})
+	s.CreateTask(t.Context(), "OAuth logout", p.ID, store.TaskCreateOpts{Assignee: "bob"})
+	s.CreateDocument(t.Context(), "OAuth notes", p.ID, store.DocumentCreateOpts{})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "search", "oauth", "assignee:alice", "priority<=1"))
+	})
+	assert.Contains(t, out, "OAuth login")
+	assert.NotContains(t, out, "OAuth logout")
+	assert.NotContains(t, out, "OAuth notes")
+
+	// Plain text still searches documents too.
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "search", "oauth", "--project", p.ID))
+	})
+	assert.Contains(t, out, "OAuth notes")
+
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "search", "oauth", "--type", "doc"))
+	})
+	assert.Contains(t, out, "OAuth notes")
+	assert.NotContains(t, out, "OAuth login")
+
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "search", "--regex", "--title-only", "--type", "task", "log(in|out)$"))
+	})
+	assert.Contains(t, out, "OAuth login")
+	assert.Contains(t, out, "OAuth logout")
+
+	err := run(t, "search", "label:backend")
+	require.Error(t, err)
+	assert.Equal(t, ExitInvalid, ExitCode(err))
+}
+
+func TestTaskSimilar(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Flaky login test", p.ID, store.TaskCreateOpts{})
+	s.CreateTask(t.Context(), "Login test flaky on CI", p.ID, store.TaskCreateOpts{})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "task", "similar", task.ID))
+	})
+	assert.Contains(t, out, "Login test flaky on CI (open)")
+}
+
 func TestTaskDownload(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	task, _ := s.CreateTask("My Task", p.ID, store.TaskCreateOpts{Body: "task body"})
+	task, _ := s.CreateTask(t.Context(), "My Task", p.ID, store.TaskCreateOpts{Body: "task body"})
 
 	// Change to a temp dir so .compass/ is created there
 	origDir, _ := os.Getwd()
@@ -405,9 +771,9 @@ func TestTaskDownload(t *testing.T) {
 
 func TestTaskUpload(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	task, _ := s.CreateTask("My Task", p.ID, store.TaskCreateOpts{Body: "old body"})
+	task, _ := s.CreateTask(t.Context(), "My Task", p.ID, store.TaskCreateOpts{Body: "old body"})
 
 	origDir, _ := os.Getwd()
 	tmpDir := t.TempDir()
@@ -422,16 +788,16 @@ func TestTaskUpload(t *testing.T) {
 	assert.NoFileExists(t, localPath)
 
 	// Store should still have the task
-	got, _, err := s.GetTask(task.ID)
+	got, _, err := s.GetTask(t.Context(), task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "My Task", got.Title)
 }
 
 func TestDocDownload(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	doc, _ := s.CreateDocument("My Doc", p.ID, "doc body")
+	doc, _ := s.CreateDocument(t.Context(), "My Doc", p.ID, store.DocumentCreateOpts{Body: "doc body"})
 
 	origDir, _ := os.Getwd()
 	tmpDir := t.TempDir()
@@ -446,9 +812,9 @@ func TestDocDownload(t *testing.T) {
 
 func TestDocUpload(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	doc, _ := s.CreateDocument("My Doc", p.ID, "old body")
+	doc, _ := s.CreateDocument(t.Context(), "My Doc", p.ID, store.DocumentCreateOpts{Body: "old body"})
 
 	origDir, _ := os.Getwd()
 	tmpDir := t.TempDir()
@@ -461,14 +827,14 @@ func TestDocUpload(t *testing.T) {
 	localPath := filepath.Join(".compass", doc.ID+".md")
 	assert.NoFileExists(t, localPath)
 
-	got, _, err := s.GetDocument(doc.ID)
+	got, _, err := s.GetDocument(t.Context(), doc.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "My Doc", got.Title)
 }
 
 func TestProjectLink_Success(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
 
 	origDir, _ := os.Getwd()
@@ -483,6 +849,40 @@ func TestProjectLink_Success(t *testing.T) {
 	assert.Equal(t, p.ID, got)
 }
 
+func TestProjectLink_Path(t *testing.T) {
+	s, _ := setupEnv(t)
+	auth, _ := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	bill, _ := s.CreateProject(t.Context(), "Billing", "BILL", "")
+	reg.CacheProject(auth.ID, "local")
+	reg.CacheProject(bill.ID, "local")
+	t.Cleanup(func() {
+		projectLinkCmd.Flags().Set("path", "")
+		projectUnlinkCmd.Flags().Set("path", "")
+	})
+
+	origDir, _ := os.Getwd()
+	root := t.TempDir()
+	os.Chdir(root)
+	defer os.Chdir(origDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "services", "auth"), 0755))
+
+	require.NoError(t, run(t, "project", "link", auth.ID, "--path", "services/auth/"))
+	require.NoError(t, run(t, "project", "link", bill.ID, "--path", "services/billing"))
+	m, err := repofile.ReadMap(root)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"services/auth": "AUTH", "services/billing": "BILL"}, m)
+
+	id, _, err := repofile.Find(filepath.Join(root, "services", "auth"))
+	require.NoError(t, err)
+	assert.Equal(t, "AUTH", id)
+
+	assert.Error(t, run(t, "project", "link", auth.ID, "--path", "../elsewhere"))
+
+	require.NoError(t, run(t, "project", "unlink", "--path", "services/billing"))
+	m, _ = repofile.ReadMap(root)
+	assert.Equal(t, map[string]string{"services/auth": "AUTH"}, m)
+}
+
 func TestProjectLink_InvalidProject(t *testing.T) {
 	setupEnv(t)
 
@@ -496,7 +896,7 @@ func TestProjectLink_InvalidProject(t *testing.T) {
 
 func TestProjectUnlink_Success(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
 
 	origDir, _ := os.Getwd()
@@ -514,8 +914,8 @@ func TestProjectUnlink_Success(t *testing.T) {
 
 func TestResolveProject_RepoFileOverridesDefault(t *testing.T) {
 	s, _ := setupEnv(t)
-	_, _ = s.CreateProject("Default Project", "DP", "")
-	p2, _ := s.CreateProject("Repo Project", "RP", "")
+	_, _ = s.CreateProject(t.Context(), "Default Project", "DP", "")
+	p2, _ := s.CreateProject(t.Context(), "Repo Project", "RP", "")
 	reg.CacheProject("DP", "local")
 	reg.CacheProject("RP", "local")
 
@@ -529,7 +929,7 @@ func TestResolveProject_RepoFileOverridesDefault(t *testing.T) {
 	// Task create without --project should use repo file (p2), not global default (p1)
 	require.NoError(t, run(t, "task", "create", "Repo Task", "--project", "", "--type", "task", "--priority", "-1", "--depends-on", "", "--parent-epic", ""))
 
-	tasks, err := s.ListTasks(store.TaskFilter{ProjectID: p2.ID})
+	tasks, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p2.ID})
 	require.NoError(t, err)
 	assert.Len(t, tasks, 1)
 	assert.Equal(t, "Repo Task", tasks[0].Title)
@@ -537,9 +937,9 @@ func TestResolveProject_RepoFileOverridesDefault(t *testing.T) {
 
 func TestTaskList_UsesRepoFile(t *testing.T) {
 	s, _ := setupEnv(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	reg.CacheProject(p.ID, "local")
-	s.CreateTask("A Task", p.ID, store.TaskCreateOpts{})
+	s.CreateTask(t.Context(), "A Task", p.ID, store.TaskCreateOpts{})
 
 	origDir, _ := os.Getwd()
 	tmpDir := t.TempDir()
@@ -554,8 +954,8 @@ func TestTaskList_UsesRepoFile(t *testing.T) {
 
 func TestResolveProject_FlagOverridesRepoFile(t *testing.T) {
 	s, _ := setupEnv(t)
-	p1, _ := s.CreateProject("Flag Project", "FP", "")
-	p2, _ := s.CreateProject("Repo Project", "RP", "")
+	p1, _ := s.CreateProject(t.Context(), "Flag Project", "FP", "")
+	p2, _ := s.CreateProject(t.Context(), "Repo Project", "RP", "")
 	reg.CacheProject(p1.ID, "local")
 	reg.CacheProject(p2.ID, "local")
 
@@ -569,7 +969,7 @@ func TestResolveProject_FlagOverridesRepoFile(t *testing.T) {
 	// Explicit --project flag should win over repo file
 	require.NoError(t, run(t, "task", "create", "Flag Task", "--project", p1.ID, "--type", "task", "--priority", "-1", "--depends-on", ""))
 
-	tasks, err := s.ListTasks(store.TaskFilter{ProjectID: p1.ID})
+	tasks, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p1.ID})
 	require.NoError(t, err)
 	assert.Len(t, tasks, 1)
 	assert.Equal(t, "Flag Task", tasks[0].Title)
@@ -579,52 +979,2278 @@ func TestE2EWorkflow(t *testing.T) {
 	s, _ := setupEnv(t)
 
 	// 1. Create project
-	p, err := s.CreateProject("E2E Project", "", "")
+	p, err := s.CreateProject(t.Context(), "E2E Project", "", "")
 	require.NoError(t, err)
 	reg.CacheProject(p.ID, "local")
 
 	// 2. Create docs
-	d1, _ := s.CreateDocument("Design Doc", p.ID, "")
-	d2, _ := s.CreateDocument("API Spec", p.ID, "")
+	d1, _ := s.CreateDocument(t.Context(), "Design Doc", p.ID, store.DocumentCreateOpts{})
+	d2, _ := s.CreateDocument(t.Context(), "API Spec", p.ID, store.DocumentCreateOpts{})
 	_ = d1
 	_ = d2
 
 	// 3. Create epic (now a task with type=epic)
-	epic, _ := s.CreateTask("Auth Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
+	epic, _ := s.CreateTask(t.Context(), "Auth Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
 
 	// 4. Create tasks
-	tA, _ := s.CreateTask("Task A", p.ID, store.TaskCreateOpts{Epic: epic.ID})
-	_, _ = s.CreateTask("Task B", p.ID, store.TaskCreateOpts{})
-	tC, _ := s.CreateTask("Task C", p.ID, store.TaskCreateOpts{DependsOn: []string{tA.ID}})
+	tA, _ := s.CreateTask(t.Context(), "Task A", p.ID, store.TaskCreateOpts{Epic: epic.ID})
+	_, _ = s.CreateTask(t.Context(), "Task B", p.ID, store.TaskCreateOpts{})
+	tC, _ := s.CreateTask(t.Context(), "Task C", p.ID, store.TaskCreateOpts{DependsOn: []string{tA.ID}})
 
 	// 5. Verify counts
-	docs, _ := s.ListDocuments(p.ID)
+	docs, _ := s.ListDocuments(t.Context(), store.DocumentFilter{ProjectID: p.ID})
 	assert.Len(t, docs, 2)
-	tasks, _ := s.ListTasks(store.TaskFilter{ProjectID: p.ID})
+	tasks, _ := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
 	assert.Len(t, tasks, 4) // 3 tasks + 1 epic
 
 	// 6. Task C should be blocked
-	allTasks, _ := s.AllTaskMap(p.ID)
-	assert.True(t, tC.IsBlocked(allTasks))
+	allTasks, _ := s.AllTaskMap(t.Context(), p.ID)
+	assert.True(t, tC.IsBlocked(allTasks, model.StrictDeps))
 
 	// 7. Close Task A
 	closed := model.StatusClosed
-	s.UpdateTask(tA.ID, store.TaskUpdate{Status: &closed})
+	s.UpdateTask(t.Context(), tA.ID, store.TaskUpdate{Status: &closed})
 
 	// 8. Task C no longer blocked
-	allTasks, _ = s.AllTaskMap(p.ID)
-	gotC, _, _ := s.GetTask(tC.ID)
-	assert.False(t, gotC.IsBlocked(allTasks))
+	allTasks, _ = s.AllTaskMap(t.Context(), p.ID)
+	gotC, _, _ := s.GetTask(t.Context(), tC.ID)
+	assert.False(t, gotC.IsBlocked(allTasks, model.StrictDeps))
 
 	// 9. Graph via CLI
 	require.NoError(t, run(t, "task", "graph", "--project", p.ID))
 
 	// 10. Search
-	results, _ := s.Search("Auth", "")
+	results, _ := s.Search(t.Context(), "Auth", store.SearchOpts{})
 	assert.GreaterOrEqual(t, len(results), 1)
 
 	// 11. Ready tasks
-	ready, err := s.ReadyTasks(p.ID)
+	ready, err := s.ReadyTasks(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(ready), 1)
 }
+
+func TestReleaseCreateAndCut(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
+
+	require.NoError(t, run(t, "release", "create", "1.0.0", "--project", p.ID, "--target-date", "2026-03-01", "--include", task.ID))
+
+	releases, err := s.ListReleases(t.Context(), p.ID)
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+	rel := releases[0]
+
+	assert.Error(t, run(t, "release", "cut", rel.ID))
+
+	require.NoError(t, run(t, "task", "close", task.ID))
+	require.NoError(t, run(t, "release", "cut", rel.ID))
+
+	got, _, err := s.GetRelease(t.Context(), rel.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ReleaseCut, got.Status)
+	assert.NotEmpty(t, got.Changelog)
+}
+
+func TestReportRelease(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	epic, _ := s.CreateTask(t.Context(), "Login", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
+	old, _ := s.CreateTask(t.Context(), "Old fix", p.ID, store.TaskCreateOpts{})
+	recent, _ := s.CreateTask(t.Context(), "Password reset", p.ID, store.TaskCreateOpts{Epic: epic.ID})
+	s.CreateTask(t.Context(), "Still open", p.ID, store.TaskCreateOpts{})
+
+	for _, id := range []string{old.ID, recent.ID} {
+		require.NoError(t, run(t, "task", "close", id))
+	}
+	// Backdate the first close.
+	got, body, _ := s.GetTask(t.Context(), old.ID)
+	longAgo := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	got.ClosedAt = &longAgo
+	path, _ := s.ResolveEntityPath(old.ID)
+	require.NoError(t, s.WriteEntity(path, got, body))
+
+	var err error
+	out := captureStdout(t, func() { err = run(t, "report", "release", "--project", p.ID, "--since", "2021-01-01") })
+	require.NoError(t, err)
+	assert.Contains(t, out, "## Login ("+epic.ID+")")
+	assert.Contains(t, out, "- Password reset ("+recent.ID+")")
+	assert.NotContains(t, out, "Old fix")
+	assert.NotContains(t, out, "Still open")
+
+	assert.ErrorContains(t, run(t, "report", "release", "--project", p.ID, "--since", "no-such-tag"), "not a date")
+
+	require.NoError(t, run(t, "report", "release", "--project", p.ID, "--since", "2021-01-01", "--save"))
+	t.Cleanup(func() { reportReleaseCmd.Flags().Set("save", "false") })
+	docs, err := s.ListDocuments(t.Context(), store.DocumentFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Changes since 2021-01-01", docs[0].Title)
+}
+
+func TestReportStandup(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	done, _ := s.CreateTask(t.Context(), "Ship login", p.ID, store.TaskCreateOpts{})
+	doing, _ := s.CreateTask(t.Context(), "Write docs", p.ID, store.TaskCreateOpts{})
+	s.CreateTask(t.Context(), "Untouched", p.ID, store.TaskCreateOpts{})
+
+	require.NoError(t, run(t, "task", "start", done.ID))
+	require.NoError(t, run(t, "task", "close", done.ID))
+	require.NoError(t, run(t, "task", "start", doing.ID))
+
+	var err error
+	out := captureStdout(t, func() { err = run(t, "report", "standup", "--author", "me") })
+	t.Cleanup(func() { reportStandupCmd.Flags().Set("author", "") })
+	require.NoError(t, err)
+	assert.Contains(t, out, "*"+store.CurrentUser()+"*")
+	assert.Contains(t, out, "Closed:\n- "+done.ID+" Ship login")
+	assert.Contains(t, out, "In progress:\n- "+doing.ID+" Write docs")
+	assert.NotContains(t, out, "Untouched")
+
+	out = captureStdout(t, func() { err = run(t, "report", "standup", "--author", "someone-else") })
+	require.NoError(t, err)
+	assert.Equal(t, "No activity.\n", out)
+}
+
+func TestReportHeatmap(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	done, _ := s.CreateTask(t.Context(), "Ship login", p.ID, store.TaskCreateOpts{})
+	s.CreateTask(t.Context(), "Write docs", p.ID, store.TaskCreateOpts{})
+	require.NoError(t, run(t, "task", "close", done.ID))
+	t.Cleanup(func() { reportHeatmapCmd.Flags().Set("weeks", "12") })
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "report", "heatmap", "--project", p.ID, "--weeks", "4")) })
+	assert.Contains(t, out, "2 created, 1 closed in 4 weeks")
+	assert.Contains(t, out, "█")
+	assert.ErrorContains(t, run(t, "report", "heatmap", "--project", p.ID, "--weeks", "0"), "at least 1")
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"today":      time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+		"yesterday":  time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+		"2026-03-01": time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		"3d":         time.Date(2026, 3, 7, 15, 30, 0, 0, time.UTC),
+		"2h":         time.Date(2026, 3, 10, 13, 30, 0, 0, time.UTC),
+	}
+	for in, want := range cases {
+		got, err := parseSince(in, now)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := parseSince("last week", now)
+	assert.Error(t, err)
+}
+
+func TestCalendarExport(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	require.NoError(t, run(t, "task", "create", "Ship login", "--project", p.ID, "--due", "2026-03-01"))
+	t.Cleanup(func() { taskCreateCmd.Flags().Set("due", "") })
+	s.CreateRelease(t.Context(), "1.0.0", p.ID, store.ReleaseCreateOpts{TargetDate: "2026-03-31"})
+
+	assert.Error(t, run(t, "task", "create", "Bad", "--project", p.ID, "--due", "March 1"))
+
+	out := filepath.Join(dir, "tasks.ics")
+	require.NoError(t, run(t, "calendar", "export", "--project", p.ID, "-o", out))
+	t.Cleanup(func() { calendarExportCmd.Flags().Set("output", "") })
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "SUMMARY:Due: Ship login")
+	assert.Contains(t, string(data), "DTSTART;VALUE=DATE:20260301")
+	assert.Contains(t, string(data), "SUMMARY:Release 1.0.0")
+
+	rec := httptest.NewRecorder()
+	serveMux().ServeHTTP(rec, httptest.NewRequest("GET", "/calendar.ics?project="+p.ID, nil))
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "SUMMARY:Due: Ship login")
+}
+
+func TestTaskWait(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
+
+	require.NoError(t, run(t, "task", "wait", task.ID, "vendor reply", "--url", "https://example.com/1", "--until", "2099-01-01", "--clear=false"))
+
+	got, _, err := s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.Waiting)
+	assert.Equal(t, "vendor reply", got.Waiting.Description)
+	assert.Equal(t, "2099-01-01", got.Waiting.Until)
+
+	require.NoError(t, run(t, "task", "waiting", "--project", p.ID))
+
+	require.NoError(t, run(t, "task", "wait", task.ID, "--clear"))
+	got, _, err = s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.Waiting)
+}
+
+func TestTaskBlock(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
+
+	assert.ErrorContains(t, run(t, "task", "unblock", task.ID), "is not blocked")
+	assert.ErrorContains(t, run(t, "task", "block", task.ID), "--reason is required")
+
+	require.NoError(t, run(t, "task", "block", task.ID, "--reason", "waiting on vendor"))
+	t.Cleanup(func() { taskBlockCmd.Flags().Set("reason", "") })
+	got, _, err := s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusBlocked, got.Status)
+	assert.Equal(t, "waiting on vendor", got.BlockedReason)
+
+	require.NoError(t, run(t, "task", "unblock", task.ID))
+	got, _, err = s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusOpen, got.Status)
+	assert.Empty(t, got.BlockedReason)
+}
+
+func TestTaskRemind(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Call vendor", p.ID, store.TaskCreateOpts{})
+	later, _ := s.CreateTask(t.Context(), "Later", p.ID, store.TaskCreateOpts{})
+
+	var notified []string
+	orig := sendNotification
+	sendNotification = func(title, body string) error { notified = append(notified, body); return nil }
+	t.Cleanup(func() {
+		sendNotification = orig
+		taskRemindCmd.Flags().Set("at", "")
+		taskRemindCmd.Flags().Set("note", "")
+		taskRemindCmd.Flags().Set("clear", "false")
+		remindersDueCmd.Flags().Set("notify", "false")
+	})
+
+	assert.ErrorContains(t, run(t, "task", "remind", task.ID), "--at is required")
+	require.NoError(t, run(t, "task", "remind", later.ID, "--at", "3d"))
+	require.NoError(t, run(t, "task", "remind", task.ID, "--at", "2020-01-01T09:00", "--note", "ask about pricing"))
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "reminders", "due", "--notify")) })
+	assert.Contains(t, out, task.ID+"  Call vendor (ask about pricing)")
+	assert.NotContains(t, out, later.ID)
+	require.Len(t, notified, 1)
+
+	// Due reminders fire once.
+	out = captureStdout(t, func() { require.NoError(t, run(t, "reminders", "due")) })
+	assert.Empty(t, out)
+
+	rs, err := reminder.Load(dir)
+	require.NoError(t, err)
+	require.Len(t, rs, 1)
+	assert.Equal(t, later.ID, rs[0].Task)
+
+	require.NoError(t, run(t, "task", "remind", later.ID, "--clear"))
+	rs, err = reminder.Load(dir)
+	require.NoError(t, err)
+	assert.Empty(t, rs)
+}
+
+func TestSlugRefs(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	t.Cleanup(func() {
+		quiet = false
+		taskCreateCmd.Flags().Set("slug", "")
+		docCreateCmd.Flags().Set("slug", "")
+		taskShowCmd.Flags().Set("pretty", "false")
+		for _, name := range []string{"slug", "title"} {
+			f := taskUpdateCmd.Flags().Lookup(name)
+			f.Value.Set("")
+			f.Changed = false
+		}
+	})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "-q", "task", "create", "Build the login form", "--project", p.ID, "--type", "task", "--slug", "auto"))
+	})
+	taskID := strings.TrimSpace(out)
+	task, _, err := s.GetTask(t.Context(), taskID)
+	require.NoError(t, err)
+	assert.Equal(t, "build-the-login-form", task.Slug)
+
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "show", p.ID+"/build-the-login-form", "--pretty")) })
+	assert.Contains(t, out, taskID)
+	assert.Contains(t, out, p.ID+"/build-the-login-form")
+
+	// A derived slug that's taken gets a suffix; a given one is refused.
+	require.NoError(t, run(t, "doc", "create", "Build the login form", "--project", p.ID, "--slug", "auto"))
+	docs, _ := s.ListDocuments(t.Context(), store.DocumentFilter{ProjectID: p.ID})
+	require.Len(t, docs, 1)
+	assert.Equal(t, "build-the-login-form-2", docs[0].Slug)
+	assert.ErrorContains(t, run(t, "task", "create", "Other", "--project", p.ID, "--type", "task", "--slug", "build-the-login-form"), "already used")
+
+	require.NoError(t, run(t, "task", "update", p.ID+"/build-the-login-form", "--title", "Login form", "--slug", "auto"))
+	require.NoError(t, run(t, "task", "close", p.ID+"/login-form"))
+	task, _, err = s.GetTask(t.Context(), taskID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusClosed, task.Status)
+
+	assert.ErrorContains(t, run(t, "task", "show", p.ID+"/missing"), `has slug "missing"`)
+}
+
+func TestTaskPin(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	first, _ := s.CreateTask(t.Context(), "First", p.ID, store.TaskCreateOpts{})
+	second, _ := s.CreateTask(t.Context(), "Second", p.ID, store.TaskCreateOpts{})
+	d, _ := s.CreateDocument(t.Context(), "Spec", p.ID, store.DocumentCreateOpts{})
+
+	require.NoError(t, run(t, "task", "pin", second.ID))
+	require.NoError(t, run(t, "doc", "pin", d.ID))
+	assert.ErrorContains(t, run(t, "task", "pin", d.ID), "not a task")
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "task", "list", "--project", p.ID)) })
+	assert.Less(t, strings.Index(out, second.ID), strings.Index(out, first.ID), "pinned task listed first")
+
+	out = captureStdout(t, func() { require.NoError(t, run(t, "pinned")) })
+	assert.Contains(t, out, second.ID+"  Second (open)")
+	assert.Contains(t, out, d.ID+"  Spec (doc)")
+	assert.NotContains(t, out, first.ID)
+
+	require.NoError(t, run(t, "task", "unpin", second.ID))
+	assert.ErrorContains(t, run(t, "task", "unpin", second.ID), "not pinned")
+	require.NoError(t, run(t, "doc", "unpin", d.ID))
+	ps, err := pin.Load(dir)
+	require.NoError(t, err)
+	assert.Empty(t, ps)
+
+	t.Cleanup(func() { rootCmd.PersistentFlags().Set("dry-run", "false") })
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "pin", first.ID, "--dry-run")) })
+	assert.Equal(t, "Would pin "+first.ID+"\n", out)
+	ps, err = pin.Load(dir)
+	require.NoError(t, err)
+	assert.Empty(t, ps)
+}
+
+func TestNotifications(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+
+	var events []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]any
+		json.NewDecoder(r.Body).Decode(&m)
+		events = append(events, m["event"].(string))
+	}))
+	defer hook.Close()
+	cfg.Notifications = []config.NotifySink{{Name: "hook", Type: "webhook", URL: hook.URL}}
+	require.NoError(t, config.Save(dir, cfg))
+	t.Cleanup(func() {
+		taskCreateCmd.Flags().Set("priority", "-1")
+		taskCreateCmd.Flags().Set("assignee", "")
+		notifier = nil
+	})
+
+	require.NoError(t, run(t, "task", "create", "Outage", "--project", p.ID, "--priority", "0", "--assignee", "me"))
+	assert.Equal(t, []string{"p0_created", "task_assigned"}, events)
+	tasks, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, store.CurrentUser(), tasks[0].Assignee)
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "notify", "test")) })
+	assert.Equal(t, "hook: ok\n", out)
+	assert.Equal(t, "test", events[len(events)-1])
+}
+
+func TestMaintain_Escalation(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	cfg.Escalation = map[string]config.EscalationPolicy{p.ID: {AfterDays: 7}}
+	require.NoError(t, config.Save(dir, cfg))
+	t.Cleanup(func() { maintainCmd.Flags().Set("dry-run", "false") })
+
+	// age backdates a task's last update.
+	age := func(title string, priority *int, days int) string {
+		task, err := s.CreateTask(t.Context(), title, p.ID, store.TaskCreateOpts{Priority: priority})
+		require.NoError(t, err)
+		got, body, err := s.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		got.UpdatedAt = time.Now().UTC().AddDate(0, 0, -days)
+		path, err := s.ResolveEntityPath(task.ID)
+		require.NoError(t, err)
+		require.NoError(t, s.WriteEntity(path, got, body))
+		return task.ID
+	}
+	p1, p2 := 1, 2
+	stale := age("Stale", &p2, 10)
+	unset := age("Unprioritized", nil, 10)
+	capped := age("Already P1", &p1, 10)
+	fresh := age("Fresh", &p2, 2)
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "maintain", "--dry-run")) })
+	assert.Contains(t, out, "Would escalate "+stale+" Stale: priority P2 -> P1 (untouched 10d)")
+	assert.Contains(t, out, "Would escalate "+unset+" Unprioritized: priority none -> P3")
+	got, _, _ := s.GetTask(t.Context(), stale)
+	assert.Equal(t, 2, *got.Priority)
+
+	maintainCmd.Flags().Set("dry-run", "false")
+	out = captureStdout(t, func() { require.NoError(t, run(t, "maintain")) })
+	assert.NotContains(t, out, capped)
+	assert.NotContains(t, out, fresh)
+	got, _, _ = s.GetTask(t.Context(), stale)
+	assert.Equal(t, 1, *got.Priority)
+	got, _, _ = s.GetTask(t.Context(), unset)
+	assert.Equal(t, 3, *got.Priority)
+
+	data, err := os.ReadFile(filepath.Join(dir, "audit.log"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(data), `"change":"priority P2 -> P1"`)
+
+	// Escalating touched the tasks, so nothing is due again yet.
+	out = captureStdout(t, func() { require.NoError(t, run(t, "maintain")) })
+	assert.Equal(t, "Nothing to escalate.\n", out)
+}
+
+func TestMaintainPruneRefs(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	t.Cleanup(func() { rootCmd.PersistentFlags().Set("dry-run", "false") })
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
+	gone, _ := s.CreateTask(t.Context(), "Gone", p.ID, store.TaskCreateOpts{})
+	kept, _ := s.CreateTask(t.Context(), "Kept", p.ID, store.TaskCreateOpts{})
+	dep, _ := s.CreateTask(t.Context(), "Dependent", p.ID, store.TaskCreateOpts{DependsOn: []string{gone.ID, kept.ID}, Epic: epic.ID})
+	for _, id := range []string{gone.ID, epic.ID} {
+		path, err := s.ResolveEntityPath(id)
+		require.NoError(t, err)
+		require.NoError(t, os.Remove(path))
+	}
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "maintain", "prune-refs", "--dry-run")) })
+	assert.Equal(t, "Would prune "+dep.ID+" Dependent: removed missing depends_on "+gone.ID+" and epic "+epic.ID+"\n", out)
+	got, _, _ := s.GetTask(t.Context(), dep.ID)
+	assert.Len(t, got.DependsOn, 2)
+
+	rootCmd.PersistentFlags().Set("dry-run", "false")
+	out = captureStdout(t, func() { require.NoError(t, run(t, "maintain", "prune-refs")) })
+	assert.Contains(t, out, "Pruned "+dep.ID)
+	got, _, _ = s.GetTask(t.Context(), dep.ID)
+	assert.Equal(t, []string{kept.ID}, got.DependsOn)
+	assert.Empty(t, got.Epic)
+	data, err := os.ReadFile(filepath.Join(dir, "audit.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"reason":"prune-refs: referenced tasks do not exist"`)
+
+	out = captureStdout(t, func() { require.NoError(t, run(t, "maintain", "prune-refs")) })
+	assert.Equal(t, "No dangling references.\n", out)
+}
+
+func TestTask_MissingDeps(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	t.Cleanup(func() {
+		taskListCmd.Flags().Set("project", "")
+		taskShowCmd.Flags().Set("pretty", "false")
+	})
+	gone, _ := s.CreateTask(t.Context(), "Gone", p.ID, store.TaskCreateOpts{})
+	dep, _ := s.CreateTask(t.Context(), "Dependent", p.ID, store.TaskCreateOpts{DependsOn: []string{gone.ID}})
+	path, err := s.ResolveEntityPath(gone.ID)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(path))
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "task", "show", dep.ID, "--pretty")) })
+	assert.Contains(t, out, "missing "+gone.ID)
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "list", "-P", p.ID)) })
+	assert.Contains(t, out, dep.ID+" is blocked by missing "+gone.ID)
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "ready", "-P", p.ID)) })
+	assert.Contains(t, out, "No ready tasks.")
+
+	cfg.MissingDeps = "lenient"
+	require.NoError(t, config.Save(dir, cfg))
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "show", dep.ID, "--pretty")) })
+	assert.Contains(t, out, gone.ID+" (ignored)")
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "ready", "-P", p.ID)) })
+	assert.Contains(t, out, dep.ID)
+}
+
+func TestGoRun_ClosesTasks(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	t1, _ := s.CreateTask(t.Context(), "First", p.ID, store.TaskCreateOpts{Body: "do the first thing"})
+	t2, _ := s.CreateTask(t.Context(), "Second", p.ID, store.TaskCreateOpts{DependsOn: []string{t1.ID}})
+
+	out := filepath.Join(dir, "agent.log")
+	require.NoError(t, run(t, "go", "run", "--project", p.ID, "--agent-cmd", "cat >> "+out, "--max", "0", "--log-file", ""))
+
+	for _, id := range []string{t1.ID, t2.ID} {
+		got, _, err := s.GetTask(t.Context(), id)
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusClosed, got.Status)
+	}
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "do the first thing")
+}
+
+func TestGoRun_DryRun(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
+	t.Cleanup(func() { rootCmd.PersistentFlags().Set("dry-run", "false") })
+
+	agentLog := filepath.Join(dir, "agent.log")
+	var err error
+	out := captureStdout(t, func() {
+		err = run(t, "--dry-run", "go", "run", "--project", p.ID, "--agent-cmd", "cat >> "+agentLog, "--max", "0", "--log-file", "")
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "Would claim task "+task.ID)
+	assert.Contains(t, out, "Would run cat >> "+agentLog+" on "+task.ID)
+	assert.NoFileExists(t, agentLog, "the agent isn't run")
+	got, _, _ := s.GetTask(t.Context(), task.ID)
+	assert.Equal(t, model.StatusOpen, got.Status)
+}
+
+func TestGoRun_AgentFailureReopens(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
+
+	assert.Error(t, run(t, "go", "run", "--project", p.ID, "--agent-cmd", "exit 1", "--max", "0", "--log-file", ""))
+
+	got, _, err := s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusOpen, got.Status)
+}
+
+func TestEpicAdopt_FromFilter(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
+	loose, _ := s.CreateTask(t.Context(), "Loose", p.ID, store.TaskCreateOpts{})
+	done, _ := s.CreateTask(t.Context(), "Done", p.ID, store.TaskCreateOpts{})
+	closed := model.StatusClosed
+	s.UpdateTask(t.Context(), done.ID, store.TaskUpdate{Status: &closed})
+
+	require.NoError(t, run(t, "epic", "adopt", epic.ID, "--from-filter", "status=open,epic=none"))
+
+	got, _, _ := s.GetTask(t.Context(), loose.ID)
+	assert.Equal(t, epic.ID, got.Epic)
+	got, _, _ = s.GetTask(t.Context(), done.ID)
+	assert.Empty(t, got.Epic)
+}
+
+func TestEpicCommands(t *testing.T) {
+	s, _ := setupEnv(t)
+	t.Cleanup(func() {
+		quiet = false
+		epicCreateCmd.Flags().Set("project", "")
+		epicListCmd.Flags().Set("project", "")
+		epicUpdateCmd.Flags().Set("title", "")
+	})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "--quiet", "epic", "create", "Login", "--project", p.ID))
+	})
+	epicID := strings.TrimSpace(out)
+	epic, _, err := s.GetTask(t.Context(), epicID)
+	require.NoError(t, err)
+	assert.Equal(t, model.TypeEpic, epic.Type)
+
+	done, _ := s.CreateTask(t.Context(), "Form", p.ID, store.TaskCreateOpts{Epic: epicID})
+	s.CreateTask(t.Context(), "API", p.ID, store.TaskCreateOpts{Epic: epicID})
+	closed := model.StatusClosed
+	s.UpdateTask(t.Context(), done.ID, store.TaskUpdate{Status: &closed})
+
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "epic", "list", "--project", p.ID))
+	})
+	assert.Contains(t, out, "1/2   50%  Login")
+
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "epic", "progress", epicID))
+	})
+	assert.Contains(t, out, "1/2  50%")
+	assert.Contains(t, out, "open: 1")
+
+	require.NoError(t, run(t, "epic", "update", epicID, "--title", "Sign in"))
+	epic, _, _ = s.GetTask(t.Context(), epicID)
+	assert.Equal(t, "Sign in", epic.Title)
+
+	err = run(t, "epic", "show", done.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not an epic")
+}
+
+func TestProjectDashboard(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	epic, _ := s.CreateTask(t.Context(), "Launch", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
+	first, _ := s.CreateTask(t.Context(), "First", p.ID, store.TaskCreateOpts{Epic: epic.ID})
+	s.CreateTask(t.Context(), "Second", p.ID, store.TaskCreateOpts{Epic: epic.ID, DependsOn: []string{first.ID}, Due: time.Now().Format(model.DateFormat)})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "project", "dashboard", p.ID))
+	})
+	assert.Contains(t, out, "Test Project (TP)")
+	assert.Contains(t, out, "0/2   0%  Launch")
+	assert.Contains(t, out, first.ID+"  First")
+	assert.Contains(t, out, "1 held up")
+	assert.Contains(t, out, "Second")
+
+	t.Cleanup(func() { projectDashboardCmd.Flags().Set("limit", "5") })
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "project", "dashboard", p.ID, "-n", "-1"))
+	})
+	assert.Contains(t, out, first.ID+"  First")
+}
+
+func TestDocCreate_File(t *testing.T) {
+	s, dir := setupEnv(t)
+	// --file accumulates across runs of the same command.
+	resetFiles := func() { docCreateCmd.Flags().Lookup("file").Value.(pflag.SliceValue).Replace(nil) }
+	t.Cleanup(func() {
+		quiet = false
+		resetFiles()
+		docCreateCmd.Flags().Set("project", "")
+		docUpdateCmd.Flags().Set("file", "")
+	})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	docs := filepath.Join(dir, "repo-docs")
+	require.NoError(t, os.MkdirAll(docs, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(docs, "a.md"), []byte("# Architecture\n\nThree services.\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(docs, "runbook.md"), []byte("Restart the pods.\n"), 0644))
+
+	require.NoError(t, run(t, "doc", "create", "--project", p.ID, "--file", filepath.Join(docs, "*.md")))
+	list, err := s.ListDocuments(t.Context(), store.DocumentFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	byTitle := map[string]string{}
+	for _, d := range list {
+		_, body, _ := s.GetDocument(t.Context(), d.ID)
+		byTitle[d.Title] = body
+	}
+	assert.Equal(t, "Three services.", strings.TrimSpace(byTitle["Architecture"]))
+	assert.Equal(t, "Restart the pods.", strings.TrimSpace(byTitle["runbook"]))
+
+	resetFiles()
+	err = run(t, "doc", "create", "Title", "--project", p.ID, "--file", filepath.Join(docs, "*.md"))
+	assert.ErrorContains(t, err, "can't be given for 2 files")
+	resetFiles()
+	assert.Error(t, run(t, "doc", "create", "--project", p.ID, "--file", filepath.Join(docs, "*.txt")))
+
+	resetFiles()
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "-q", "doc", "create", "Spec", "--project", p.ID, "--file", filepath.Join(docs, "a.md")))
+	})
+	id := strings.TrimSpace(out)
+	d, body, err := s.GetDocument(t.Context(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "Spec", d.Title)
+	assert.Equal(t, "# Architecture\n\nThree services.", strings.TrimSpace(body))
+
+	require.NoError(t, run(t, "doc", "update", id, "--file", filepath.Join(docs, "runbook.md")))
+	_, body, _ = s.GetDocument(t.Context(), id)
+	assert.Equal(t, "Restart the pods.", strings.TrimSpace(body))
+}
+
+func TestActorFlag(t *testing.T) {
+	s, _ := setupEnv(t)
+	t.Setenv("COMPASS_ACTOR", "")
+	t.Cleanup(func() {
+		actorFlag = ""
+		store.SetActor("")
+		taskCreateCmd.Flags().Set("project", "")
+		taskListCmd.Flags().Set("project", "")
+		taskListCmd.Flags().Set("columns", strings.Join(markdown.DefaultTaskColumns, ","))
+	})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+
+	require.NoError(t, run(t, "--as", "claude-code", "task", "create", "Agent task", "--project", p.ID))
+	tasks, _ := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "claude-code", tasks[0].CreatedBy)
+
+	require.NoError(t, run(t, "--as", "review-bot", "task", "close", tasks[0].ID))
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "--as", "", "task", "list", "--project", p.ID, "--columns", "id,created_by,changed_by"))
+	})
+	assert.Contains(t, out, "claude-code")
+	assert.Contains(t, out, "review-bot")
+}
+
+func TestTaskWatch_MyWork(t *testing.T) {
+	s, _ := setupEnv(t)
+	t.Setenv("COMPASS_ACTOR", "")
+	t.Cleanup(func() {
+		actorFlag = ""
+		store.SetActor("")
+		myWorkCmd.Flags().Set("all", "false")
+		myWorkCmd.Flags().Set("json", "false")
+	})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	create := func(by, title string, opts store.TaskCreateOpts) *model.Task {
+		store.SetActor(by)
+		task, err := s.CreateTask(t.Context(), title, p.ID, opts)
+		require.NoError(t, err)
+		return task
+	}
+	mine := create("alice", "Created by alice", store.TaskCreateOpts{})
+	assigned := create("bob", "Assigned to alice", store.TaskCreateOpts{Assignee: "alice"})
+	watched := create("bob", "Watched by alice", store.TaskCreateOpts{})
+	other := create("bob", "Nothing to do with alice", store.TaskCreateOpts{})
+	closed := create("alice", "Closed by alice", store.TaskCreateOpts{})
+	status := model.StatusClosed
+	_, err := s.UpdateTask(t.Context(), closed.ID, store.TaskUpdate{Status: &status})
+	require.NoError(t, err)
+
+	require.NoError(t, run(t, "--as", "alice", "task", "watch", watched.ID))
+	require.NoError(t, run(t, "--as", "alice", "task", "watch", watched.ID)) // already watching
+	require.NoError(t, run(t, "--as", "carol", "task", "watch", watched.ID))
+	got, _, _ := s.GetTask(t.Context(), watched.ID)
+	assert.Equal(t, []string{"alice", "carol"}, got.Watchers)
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "--as", "alice", "my", "work")) })
+	for _, task := range []*model.Task{mine, assigned, watched} {
+		assert.Contains(t, out, task.ID)
+	}
+	assert.NotContains(t, out, other.ID)
+	assert.NotContains(t, out, closed.ID)
+
+	out = captureStdout(t, func() { require.NoError(t, run(t, "--as", "alice", "my", "work", "--all", "--json")) })
+	var listed []model.Task
+	require.NoError(t, json.Unmarshal([]byte(out), &listed))
+	var ids []string
+	for _, task := range listed {
+		ids = append(ids, task.ID)
+	}
+	assert.ElementsMatch(t, []string{mine.ID, assigned.ID, watched.ID, closed.ID}, ids)
+
+	require.NoError(t, run(t, "--as", "alice", "task", "unwatch", watched.ID))
+	got, _, _ = s.GetTask(t.Context(), watched.ID)
+	assert.Equal(t, []string{"carol"}, got.Watchers)
+	assert.Error(t, run(t, "--as", "alice", "task", "unwatch", watched.ID))
+	out = captureStdout(t, func() { require.NoError(t, run(t, "--as", "alice", "my", "work", "--json=false")) })
+	assert.NotContains(t, out, watched.ID)
+}
+
+func TestTaskMatrix(t *testing.T) {
+	s, _ := setupEnv(t)
+	t.Cleanup(func() {
+		taskMatrixCmd.Flags().Set("project", "")
+		taskMatrixCmd.Flags().Set("json", "false")
+	})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	p1 := 1
+	ready, _ := s.CreateTask(t.Context(), "Ready one", p.ID, store.TaskCreateOpts{Priority: &p1})
+	held, _ := s.CreateTask(t.Context(), "Held up", p.ID, store.TaskCreateOpts{Priority: &p1, DependsOn: []string{ready.ID}})
+
+	out := captureStdout(t, func() { require.NoError(t, run(t, "task", "matrix", "--project", p.ID)) })
+	assert.Contains(t, out, ready.ID)
+	assert.Contains(t, out, held.ID)
+
+	out = captureStdout(t, func() { require.NoError(t, run(t, "task", "matrix", "--project", p.ID, "--json")) })
+	var cells map[string]map[string][]string
+	require.NoError(t, json.Unmarshal([]byte(out), &cells))
+	assert.Equal(t, []string{ready.ID}, cells["ready"]["P1"])
+	assert.Equal(t, []string{held.ID}, cells["blocked"]["P1"])
+	assert.Empty(t, cells["in_progress"]["P1"])
+}
+
+func TestLoadSkill_Builtin(t *testing.T) {
+	setupEnv(t)
+	tmpl, err := loadSkill("")
+	require.NoError(t, err)
+	assert.Equal(t, goSkillPrompt, tmpl)
+
+	_, err = loadSkill("nope")
+	assert.Error(t, err)
+}
+
+func TestLoadSkill_UserOverrideWithVariables(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Review login", p.ID, store.TaskCreateOpts{})
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "skills"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skills", "review.md"),
+		[]byte("Review {{project}}: {{ready_task}}\n"), 0644))
+
+	tmpl, err := loadSkill("review")
+	require.NoError(t, err)
+
+	require.NoError(t, goCmd.Flags().Set("project", p.ID))
+	t.Cleanup(func() { goCmd.Flags().Set("project", "") })
+	assert.Equal(t, "Review TP: "+task.ID+" Review login\n", renderSkill(goCmd, tmpl))
+}
+
+func TestProjectBlueprint_ExportApply(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Source", "SRC", "")
+	reg.CacheProject(p.ID, "local")
+	s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
+
+	file := filepath.Join(dir, "bp.yaml")
+	require.NoError(t, run(t, "project", "blueprint", "export", p.ID, "--output", file))
+	require.NoError(t, run(t, "project", "blueprint", "apply", file, "--name", "Copy", "--key", "CPY", "--store", "local"))
+
+	tasks, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: "CPY"})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "local", cfg.Projects["CPY"])
+}
+
+func TestTaskClaim(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{})
+
+	require.NoError(t, run(t, "task", "claim", "--project", p.ID))
+
+	got, _, err := s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusInProgress, got.Status)
+}
+
+func TestStoreSetLimitAndUsage(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+
+	require.NoError(t, run(t, "store", "set-limit", "--disk-mb", "100", "--entities", "10"))
+	c, err := config.Load(dir)
+	require.NoError(t, err)
+	require.NotNil(t, c.Limits)
+	assert.Equal(t, int64(100), c.Limits.DiskMB)
+	assert.Equal(t, 10, c.Limits.Entities)
+
+	require.NoError(t, run(t, "store", "usage", "--store", "local"))
+}
+
+func TestLimitWarning(t *testing.T) {
+	format := func(n int64) string { return fmt.Sprint(n) }
+	assert.Empty(t, limitWarning("x", 50, 100, format))
+	assert.Empty(t, limitWarning("x", 500, 0, format))
+	assert.Contains(t, limitWarning("x", 85, 100, format)[0], "approaching")
+	assert.Contains(t, limitWarning("x", 120, 100, format)[0], "exceeds")
+}
+
+func TestStoreSetReadOnly(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+
+	require.NoError(t, run(t, "store", "set-readonly", "local", "--off=false"))
+	c, err := config.Load(dir)
+	require.NoError(t, err)
+	assert.True(t, c.LocalReadOnly)
+
+	err = run(t, "task", "create", "Blocked", "--project", p.ID, "--type", "task")
+	assert.ErrorIs(t, err, store.ErrReadOnly)
+
+	require.NoError(t, run(t, "store", "set-readonly", "local", "--off"))
+	require.NoError(t, run(t, "task", "create", "Allowed", "--project", p.ID, "--type", "task"))
+}
+
+func TestProjectList_OnlyStore(t *testing.T) {
+	s, _ := setupEnv(t)
+	_, err := s.CreateProject(t.Context(), "Test", "TP", "")
+	require.NoError(t, err)
+
+	require.NoError(t, run(t, "project", "list", "--only-store", "local"))
+	assert.Equal(t, "local", cfg.Projects["TP"])
+
+	err = run(t, "project", "list", "--only-store", "nope.example")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not configured")
+	require.NoError(t, run(t, "project", "list", "--only-store", ""))
+}
+
+// staleDownload downloads a task and backdates the local copy so the store's
+// copy looks newer, then edits the local body.
+func staleDownload(t *testing.T, taskID, localBody string) string {
+	t.Helper()
+	require.NoError(t, run(t, "task", "download", taskID))
+	localPath := filepath.Join(".compass", taskID+".md")
+	local, _, err := store.ReadEntity[model.Task](localPath)
+	require.NoError(t, err)
+	local.UpdatedAt = local.UpdatedAt.Add(-time.Hour)
+	data, err := markdown.Marshal(&local, localBody)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(localPath, data, 0644))
+	return localPath
+}
+
+func TestTaskUpload_ConflictResolve(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "My Task", p.ID, store.TaskCreateOpts{Body: "original"})
+	t.Cleanup(func() { taskUploadCmd.Flags().Set("resolve", "") })
+
+	origDir, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(origDir)
+
+	storeBody := "changed in store"
+
+	// remote: the store copy wins and the local copy is discarded
+	localPath := staleDownload(t, task.ID, "changed locally")
+	_, err := s.UpdateTask(t.Context(), task.ID, store.TaskUpdate{Body: &storeBody})
+	require.NoError(t, err)
+	require.NoError(t, run(t, "task", "upload", task.ID, "--resolve", "remote"))
+	assert.NoFileExists(t, localPath)
+	_, body, _ := s.GetTask(t.Context(), task.ID)
+	assert.Equal(t, storeBody, body)
+
+	// local: the local copy overwrites the store
+	staleDownload(t, task.ID, "changed locally")
+	require.NoError(t, run(t, "task", "upload", task.ID, "--resolve", "local"))
+	_, body, _ = s.GetTask(t.Context(), task.ID)
+	assert.Equal(t, "changed locally", body)
+
+	// merge: markers left in place abort the upload
+	t.Setenv("EDITOR", "true")
+	staleDownload(t, task.ID, "merge me")
+	err = run(t, "task", "upload", task.ID, "--resolve", "merge")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "conflict markers remain")
+	_, body, _ = s.GetTask(t.Context(), task.ID)
+	assert.Equal(t, "changed locally", body)
+
+	// merge: an editor that keeps the store half uploads the result
+	script := filepath.Join(t.TempDir(), "keep-store.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nsed -i '/^<<<<<<< /,/^=======$/d; /^>>>>>>> /d' \"$1\"\n"), 0755))
+	t.Setenv("EDITOR", script)
+	require.NoError(t, run(t, "task", "upload", task.ID, "--resolve", "merge"))
+	_, body, _ = s.GetTask(t.Context(), task.ID)
+	assert.Equal(t, "changed locally", body)
+}
+
+func TestDocUpload_NoConflictWhenUnchanged(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	doc, _ := s.CreateDocument(t.Context(), "My Doc", p.ID, store.DocumentCreateOpts{Body: "body"})
+	t.Cleanup(func() { docUploadCmd.Flags().Set("resolve", "") })
+
+	origDir, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(origDir)
+
+	require.NoError(t, run(t, "doc", "download", doc.ID))
+	// An invalid --resolve is never consulted without a conflict.
+	require.NoError(t, run(t, "doc", "upload", doc.ID, "--resolve", "bogus"))
+}
+
+func TestDocExport(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	doc, _ := s.CreateDocument(t.Context(), "Design", p.ID, store.DocumentCreateOpts{Body: "Hello **world**"})
+	dir := t.TempDir()
+	t.Cleanup(func() {
+		docExportCmd.Flags().Set("format", "html")
+		docExportCmd.Flags().Set("output", "")
+	})
+
+	htmlPath := filepath.Join(dir, "design.html")
+	require.NoError(t, run(t, "doc", "export", doc.ID, "--format", "html", "-o", htmlPath))
+	data, err := os.ReadFile(htmlPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<strong>world</strong>")
+
+	gfmPath := filepath.Join(dir, "design.md")
+	require.NoError(t, run(t, "doc", "export", doc.ID, "--format", "gfm", "-o", gfmPath))
+	data, err = os.ReadFile(gfmPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# Design\n\nHello **world**\n", string(data))
+
+	t.Setenv("PATH", t.TempDir())
+	err = run(t, "doc", "export", doc.ID, "--format", "pdf", "-o", filepath.Join(dir, "design.pdf"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wkhtmltopdf")
+
+	err = run(t, "doc", "export", doc.ID, "--format", "docx", "-o", "")
+	require.Error(t, err)
+}
+
+// withStdin replaces os.Stdin with a pipe holding content for one test.
+func withStdin(t *testing.T, content string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	_, err = w.WriteString(content)
+	require.NoError(t, err)
+	w.Close()
+	orig := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = orig
+		r.Close()
+	})
+}
+
+// captureStdout runs fn and returns what it wrote to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	orig := os.Stdout
+	os.Stdout = w
+	fn()
+	os.Stdout = orig
+	w.Close()
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+// captureStderr is captureStdout for stderr, where warnings are logged.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	orig := os.Stderr
+	os.Stderr = w
+	fn()
+	os.Stderr = orig
+	w.Close()
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestQuiet(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	t.Cleanup(func() {
+		quiet = false
+		taskCreateCmd.Flags().Set("project", "")
+	})
+
+	var err error
+	out := captureStdout(t, func() { err = run(t, "--quiet", "task", "create", "Quiet", "--project", p.ID) })
+	require.NoError(t, err)
+	tasks, _ := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
+	require.Len(t, tasks, 1)
+	assert.Equal(t, tasks[0].ID+"\n", out)
+
+	out = captureStdout(t, func() { err = run(t, "-q", "task", "close", tasks[0].ID) })
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestDocEditSection(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	doc, _ := s.CreateDocument(t.Context(), "Design", p.ID, store.DocumentCreateOpts{Body: "## API\n\nold\n\n## Usage\n\nkeep\n"})
+
+	require.NoError(t, run(t, "doc", "sections", doc.ID))
+
+	withStdin(t, "new\n")
+	require.NoError(t, run(t, "doc", "edit-section", doc.ID, "## API"))
+
+	_, body, err := s.GetDocument(t.Context(), doc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "## API\n\nnew\n\n## Usage\n\nkeep", strings.TrimSpace(body))
+
+	withStdin(t, "x\n")
+	err = run(t, "doc", "edit-section", doc.ID, "#missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestDocAppendAndPatch(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	t.Cleanup(func() {
+		docPatchCmd.Flags().Set("find", "")
+		docPatchCmd.Flags().Set("replace", "")
+		docPatchCmd.Flags().Set("all", "false")
+	})
+	doc, _ := s.CreateDocument(t.Context(), "Design", p.ID, store.DocumentCreateOpts{Body: "Status: draft\n\nTODO one\nTODO two\n"})
+
+	withStdin(t, "## Rollout\n\nBy region.\n")
+	require.NoError(t, run(t, "doc", "append", doc.ID))
+	_, body, err := s.GetDocument(t.Context(), doc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Status: draft\n\nTODO one\nTODO two\n\n## Rollout\n\nBy region.", strings.TrimSpace(body))
+
+	require.NoError(t, run(t, "doc", "patch", doc.ID, "--find", "Status: draft", "--replace", "Status: final"))
+	_, body, _ = s.GetDocument(t.Context(), doc.ID)
+	assert.True(t, strings.HasPrefix(body, "Status: final\n"))
+
+	err = run(t, "doc", "patch", doc.ID, "--find", "TODO", "--replace", "DONE")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "occurs 2 times")
+	err = run(t, "doc", "patch", doc.ID, "--find", "missing", "--replace", "x")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	require.NoError(t, run(t, "doc", "patch", doc.ID, "--find", "TODO", "--replace", "DONE", "--all"))
+	_, body, _ = s.GetDocument(t.Context(), doc.ID)
+	assert.Contains(t, body, "DONE one\nDONE two\n")
+}
+
+func TestJSONInput_CreateAndUpdate(t *testing.T) {
+	s, _ := setupEnv(t)
+	t.Cleanup(func() {
+		projectCreateCmd.Flags().Set("json", "false")
+		taskCreateCmd.Flags().Set("json", "false")
+		taskUpdateCmd.Flags().Set("json", "false")
+		docCreateCmd.Flags().Set("json", "false")
+	})
+
+	withStdin(t, `{"name": "Scripted", "key": "SC", "body": "it's \"quoted\""}`)
+	require.NoError(t, run(t, "project", "create", "--json"))
+	p, body, err := s.GetProject(t.Context(), "SC")
+	require.NoError(t, err)
+	assert.Equal(t, "Scripted", p.Name)
+	assert.Equal(t, `it's "quoted"`, strings.TrimSpace(body))
+
+	withStdin(t, `{"title": "From JSON", "project": "SC", "priority": 1, "body": "line1\nline2"}`)
+	require.NoError(t, run(t, "task", "create", "--json"))
+	tasks, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: "SC"})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.NotNil(t, tasks[0].Priority)
+	assert.Equal(t, 1, *tasks[0].Priority)
+
+	withStdin(t, `{"status": "in_progress", "priority": null}`)
+	require.NoError(t, run(t, "task", "update", tasks[0].ID, "--json"))
+	got, _, err := s.GetTask(t.Context(), tasks[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusInProgress, got.Status)
+	assert.Nil(t, got.Priority)
+
+	withStdin(t, `{"title": "Spec", "project": "SC", "body": "# Spec"}`)
+	require.NoError(t, run(t, "doc", "create", "--json"))
+	docs, err := s.ListDocuments(t.Context(), store.DocumentFilter{ProjectID: "SC"})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Spec", docs[0].Title)
+
+	withStdin(t, `{"title": "Typo", "projet": "SC"}`)
+	err = run(t, "task", "create", "--json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown field")
+}
+
+func TestTaskList_PagingAndColumns(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	s.CreateTask(t.Context(), "One", p.ID, store.TaskCreateOpts{})
+	s.CreateTask(t.Context(), "Two", p.ID, store.TaskCreateOpts{})
+	s.CreateDocument(t.Context(), "Doc", p.ID, store.DocumentCreateOpts{})
+	t.Cleanup(func() {
+		for _, c := range []*cobra.Command{taskListCmd, docListCmd} {
+			c.Flags().Set("limit", "0")
+			c.Flags().Set("sort", "")
+		}
+		taskListCmd.Flags().Set("columns", strings.Join(markdown.DefaultTaskColumns, ","))
+		docListCmd.Flags().Set("columns", strings.Join(markdown.DefaultDocumentColumns, ","))
+	})
+
+	require.NoError(t, run(t, "task", "list", "-P", p.ID, "--limit", "1", "--sort", "-title", "--columns", "id,title,updated"))
+	require.NoError(t, run(t, "doc", "list", "-P", p.ID, "--sort", "title", "--columns", "title,updated"))
+
+	err := run(t, "task", "list", "-P", p.ID, "--columns", "id,colour")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown column")
+
+	err = run(t, "task", "list", "-P", p.ID, "--sort", "colour")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid sort field")
+}
+
+func TestView_SaveRunDelete(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	s.CreateTask(t.Context(), "One", p.ID, store.TaskCreateOpts{})
+	t.Cleanup(func() {
+		taskListCmd.Flags().Set("project", "")
+		taskListCmd.Flags().Set("status", "")
+		taskListCmd.Flags().Set("limit", "0")
+	})
+
+	require.NoError(t, run(t, "view", "save", "backlog", "--", "task", "list", "--project", p.ID, "--status", "open"))
+	c, err := config.Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task", "list", "--project", p.ID, "--status", "open"}, c.Views["backlog"])
+
+	require.NoError(t, run(t, "view", "run", "backlog", "--", "--limit", "1"))
+	limit, _ := taskListCmd.Flags().GetInt("limit")
+	assert.Equal(t, 1, limit)
+
+	require.NoError(t, run(t, "view", "list"))
+
+	// saved references are resolved when the view runs
+	taskListCmd.Flags().Set("limit", "0")
+	require.NoError(t, run(t, "view", "save", "qualified", "--", "task", "list", "--project", "local:"+p.ID))
+	c, err = config.Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task", "list", "--project", "local:" + p.ID}, c.Views["qualified"])
+	out := captureStdout(t, func() { require.NoError(t, run(t, "view", "run", "qualified")) })
+	assert.Contains(t, out, "One")
+
+	assert.Error(t, run(t, "view", "save", "loop", "--", "view", "run", "backlog"))
+	assert.Error(t, run(t, "view", "save", "bad", "--", "task"))
+	assert.Error(t, run(t, "view", "run", "missing"))
+
+	require.NoError(t, run(t, "view", "delete", "backlog"))
+	require.NoError(t, run(t, "view", "delete", "qualified"))
+	c, err = config.Load(dir)
+	require.NoError(t, err)
+	assert.Empty(t, c.Views)
+}
+
+func TestTaskDep_AddRemoveList(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	a, _ := s.CreateTask(t.Context(), "A", p.ID, store.TaskCreateOpts{})
+	b, _ := s.CreateTask(t.Context(), "B", p.ID, store.TaskCreateOpts{})
+	c, _ := s.CreateTask(t.Context(), "C", p.ID, store.TaskCreateOpts{DependsOn: []string{a.ID}})
+
+	require.NoError(t, run(t, "task", "dep", "add", c.ID, b.ID, a.ID))
+	got, _, err := s.GetTask(t.Context(), c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{a.ID, b.ID}, got.DependsOn)
+
+	require.NoError(t, run(t, "task", "dep", "list", c.ID))
+
+	// cycles are still rejected, naming the edge to drop
+	err = run(t, "task", "dep", "add", a.ID, c.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "removing "+a.ID+" -> "+c.ID)
+
+	err = run(t, "task", "update", a.ID, "--depends-on", c.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--fix-cycle")
+	f := taskUpdateCmd.Flags().Lookup("depends-on")
+	f.Value.Set("")
+	f.Changed = false
+
+	require.NoError(t, run(t, "task", "dep", "remove", c.ID, a.ID))
+	got, _, err = s.GetTask(t.Context(), c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{b.ID}, got.DependsOn)
+
+	assert.Error(t, run(t, "task", "dep", "remove", c.ID, a.ID))
+}
+
+func TestTaskMove(t *testing.T) {
+	s, _ := setupEnv(t)
+	s.CreateProject(t.Context(), "Source", "SRC", "")
+	s.CreateProject(t.Context(), "Target", "DST", "")
+	reg.CacheProject("SRC", "local")
+	reg.CacheProject("DST", "local")
+	a, _ := s.CreateTask(t.Context(), "A", "SRC", store.TaskCreateOpts{})
+	b, _ := s.CreateTask(t.Context(), "B", "SRC", store.TaskCreateOpts{DependsOn: []string{a.ID}})
+	t.Cleanup(func() { taskMoveCmd.Flags().Set("to-project", "") })
+
+	require.NoError(t, run(t, "task", "move", a.ID, "--to-project", "DST"))
+	moved, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: "DST"})
+	require.NoError(t, err)
+	require.Len(t, moved, 1)
+	assert.Equal(t, "A", moved[0].Title)
+	got, _, err := s.GetTask(t.Context(), b.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.DependsOn)
+}
+
+func TestTaskNext_Context(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	doc, _ := s.CreateDocument(t.Context(), "Design", p.ID, store.DocumentCreateOpts{Body: "design body"})
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic, Body: "See " + doc.ID + "."})
+	dep, _ := s.CreateTask(t.Context(), "Dep", p.ID, store.TaskCreateOpts{Body: "dep summary\n\nmore"})
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, store.TaskCreateOpts{Epic: epic.ID, DependsOn: []string{dep.ID}, Body: "Also " + doc.ID + " and TP-DZZZZZ."})
+
+	c, err := taskContext(t.Context(), s, task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, c.Epic)
+	assert.Equal(t, epic.ID, c.Epic.ID)
+	require.Len(t, c.Dependencies, 1)
+	assert.Equal(t, "open", c.Dependencies[0].Status)
+	// mentioned twice, listed once; the missing doc is skipped
+	require.Len(t, c.Documents, 1)
+	assert.Equal(t, "design body", c.Documents[0].Body)
+
+	t.Cleanup(func() {
+		taskNextCmd.Flags().Set("context", "false")
+		taskNextCmd.Flags().Set("project", "")
+	})
+	require.NoError(t, run(t, "task", "next", "--project", p.ID, "--context"))
+}
+
+func TestTaskWhyBlocked(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	a, _ := s.CreateTask(t.Context(), "A", p.ID, store.TaskCreateOpts{})
+	b, _ := s.CreateTask(t.Context(), "B", p.ID, store.TaskCreateOpts{DependsOn: []string{a.ID}})
+	c, _ := s.CreateTask(t.Context(), "C", p.ID, store.TaskCreateOpts{DependsOn: []string{b.ID}})
+
+	var err error
+	out := captureStdout(t, func() { err = run(t, "task", "why-blocked", c.ID) })
+	require.NoError(t, err)
+	assert.Contains(t, out, c.ID+" C is blocked.")
+	assert.Contains(t, out, "Depends on:")
+	assert.Contains(t, out, b.ID)
+	assert.Contains(t, out, "Start with: "+a.ID+"\n")
+
+	out = captureStdout(t, func() { err = run(t, "task", "why-blocked", a.ID) })
+	require.NoError(t, err)
+	assert.Equal(t, a.ID+" is not blocked.\n", out)
+}
+
+// fakeEditor points $EDITOR at a shell script; the file is "$1".
+func fakeEditor(t *testing.T, script string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "editor.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	t.Setenv("EDITOR", path)
+}
+
+func TestTaskCreate_Edit(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	t.Cleanup(func() {
+		taskCreateCmd.Flags().Set("edit", "false")
+		taskCreateCmd.Flags().Set("project", "")
+		docCreateCmd.Flags().Set("edit", "false")
+		docCreateCmd.Flags().Set("project", "")
+	})
+
+	fakeEditor(t, `sed -i 's/^priority: /priority: 2/' "$1" && echo "Long description." >> "$1"`)
+	require.NoError(t, run(t, "task", "create", "Edited task", "--edit", "-P", p.ID))
+	tasks, err := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Edited task", tasks[0].Title)
+	require.NotNil(t, tasks[0].Priority)
+	assert.Equal(t, 2, *tasks[0].Priority)
+	_, body, _ := s.GetTask(t.Context(), tasks[0].ID)
+	assert.Equal(t, "Long description.", body)
+
+	fakeEditor(t, `sed -i 's/^title: .*/title: "Edited doc"/' "$1"`)
+	require.NoError(t, run(t, "doc", "create", "--edit", "-P", p.ID))
+	docs, err := s.ListDocuments(t.Context(), store.DocumentFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "Edited doc", docs[0].Title)
+
+	// an untouched template with no title creates nothing
+	fakeEditor(t, "true")
+	err = run(t, "doc", "create", "--edit", "-P", p.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "title is empty")
+}
+
+func TestConfigCommands(t *testing.T) {
+	_, dir := setupEnv(t)
+	path := filepath.Join(dir, "config.yaml")
+
+	require.NoError(t, run(t, "config", "set", "stores.work.hostname", "compass.example.com"))
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "config", "get", "stores.work.hostname"))
+	})
+	assert.Equal(t, "compass.example.com\n", out)
+
+	err := run(t, "config", "set", "default_store", "home")
+	assert.ErrorContains(t, err, `default_store "home" is not a configured store`)
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "config", "get", "default_store"))
+	})
+	assert.Equal(t, "local\n", out, "a rejected set leaves config.yaml alone")
+
+	fakeEditor(t, `sed -i 's/^default_store: .*/default_store: work/' "$1"`)
+	require.NoError(t, run(t, "config", "edit"))
+	c, err := config.Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "work", c.DefaultStore)
+
+	// an invalid edit isn't saved
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+	fakeEditor(t, `echo "colour: blue" >> "$1"`)
+	err = run(t, "config", "edit")
+	assert.ErrorContains(t, err, "config.yaml not saved")
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+}
+
+func TestUpgrade(t *testing.T) {
+	_, dir := setupEnv(t)
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create("compass.exe")
+	require.NoError(t, err)
+	w.Write([]byte("new binary"))
+	require.NoError(t, zw.Close())
+	sum := sha256.Sum256(archive.Bytes())
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tag_name": "v1.4.0", "assets": [
+			{"name": "checksums.txt", "browser_download_url": %q},
+			{"name": "compass_1.4.0_windows_amd64.zip", "browser_download_url": %q}]}`,
+			srv.URL+"/checksums.txt", srv.URL+"/archive")
+	})
+	mux.HandleFunc("/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  compass_1.4.0_windows_amd64.zip\n", hex.EncodeToString(sum[:]))
+	})
+	mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) { w.Write(archive.Bytes()) })
+
+	cfg.UpdateSource = srv.URL + "/latest"
+	require.NoError(t, config.Save(dir, cfg))
+	exe := filepath.Join(t.TempDir(), "compass.exe")
+	require.NoError(t, os.WriteFile(exe, []byte("old binary"), 0755))
+	oldVersion, oldExecutable, oldGOOS, oldGOARCH := version, executable, goos, goarch
+	version, goos, goarch = "1.3.0", "windows", "amd64"
+	executable = func() (string, error) { return exe, nil }
+	t.Cleanup(func() {
+		version, executable, goos, goarch = oldVersion, oldExecutable, oldGOOS, oldGOARCH
+		upgradeCmd.Flags().Set("check", "false")
+	})
+
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "upgrade", "--check"))
+	})
+	assert.Equal(t, "compass 1.4.0 is available (you have 1.3.0)\n", out)
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "old binary", string(data), "--check doesn't install")
+
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "upgrade", "--check=false"))
+	})
+	assert.Contains(t, out, "Upgraded compass 1.3.0 to 1.4.0")
+	data, err = os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(data))
+
+	version = "1.4.0"
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "upgrade"))
+	})
+	assert.Equal(t, "compass 1.4.0 is up to date\n", out)
+}
+
+func TestTelemetry(t *testing.T) {
+	_, dir := setupEnv(t)
+	var sent int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Events []map[string]any `json:"events"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		sent += len(body.Events)
+	}))
+	defer srv.Close()
+	t.Cleanup(func() { telemetryOnCmd.Flags().Set("endpoint", "") })
+
+	// off by default: nothing is recorded
+	recordTelemetry(taskListCmd, time.Millisecond, nil)
+	_, err := os.Stat(filepath.Join(dir, "telemetry.jsonl"))
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, run(t, "telemetry", "on", "--endpoint", srv.URL))
+	c, err := config.Load(dir)
+	require.NoError(t, err)
+	require.NotNil(t, c.Telemetry)
+	assert.True(t, c.Telemetry.Enabled)
+
+	recordTelemetry(taskListCmd, 30*time.Millisecond, nil)
+	data, err := os.ReadFile(filepath.Join(dir, "telemetry.jsonl"))
+	require.NoError(t, err)
+	var e map[string]any
+	require.NoError(t, json.Unmarshal(data, &e))
+	assert.Equal(t, "task list", e["command"])
+	assert.Equal(t, "local", e["store_type"])
+	assert.EqualValues(t, 30, e["duration_ms"])
+
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "telemetry", "status"))
+	})
+	assert.Equal(t, "Telemetry: on\nEndpoint: "+srv.URL+"\nUnsent events: 1\n", out)
+
+	// a full batch is sent
+	for range 19 {
+		recordTelemetry(taskListCmd, time.Millisecond, errors.New("failed"))
+	}
+	assert.Equal(t, 20, sent)
+
+	recordTelemetry(taskListCmd, time.Millisecond, nil)
+	require.NoError(t, run(t, "telemetry", "off"))
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "telemetry", "status"))
+	})
+	assert.Equal(t, "Telemetry: off\nUnsent events: 0\n", out, "turning off deletes unsent events")
+}
+
+func TestSetupLogging(t *testing.T) {
+	_, dir := setupEnv(t)
+	t.Cleanup(func() {
+		logLevel = ""
+		setupLogging(nil)
+	})
+
+	var buf strings.Builder
+	h := &cliHandler{w: &buf, level: slog.LevelInfo, mu: new(sync.Mutex)}
+	log := slog.New(h).With("project", "AUTH")
+	log.Debug("hidden")
+	log.Info("refreshed")
+	log.Warn("skipping project", "err", errors.New("connection refused"))
+	log.WithGroup("req").Error("failed", "status", 502)
+	assert.Equal(t, "refreshed project=AUTH\n"+
+		"Warning: skipping project project=AUTH err=\"connection refused\"\n"+
+		"Error: failed project=AUTH req.status=502\n", buf.String())
+
+	// --log-level beats the config's log_level, which beats the default
+	require.NoError(t, setupLogging(cfg))
+	assert.False(t, slog.Default().Enabled(t.Context(), slog.LevelInfo))
+	cfg.LogLevel = "info"
+	cfg.LogFile = true
+	require.NoError(t, setupLogging(cfg))
+	assert.True(t, slog.Default().Enabled(t.Context(), slog.LevelInfo))
+	logLevel = "error"
+	require.NoError(t, setupLogging(cfg))
+	assert.False(t, slog.Default().Enabled(t.Context(), slog.LevelWarn))
+	slog.Error("written to the file too")
+	data, err := os.ReadFile(filepath.Join(dir, "compass.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `level=ERROR msg="written to the file too"`)
+
+	logLevel = "loud"
+	assert.ErrorContains(t, setupLogging(cfg), `invalid log level "loud"`)
+}
+
+func TestDryRun(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Doomed", p.ID, store.TaskCreateOpts{})
+	t.Cleanup(func() { rootCmd.PersistentFlags().Set("dry-run", "false") })
+
+	// no confirmation prompt: nothing is deleted
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "task", "delete", task.ID, "--dry-run"))
+	})
+	path := filepath.Join(dir, "projects", "TP", "tasks", task.ID+".md")
+	assert.Equal(t, "Would delete task "+task.ID+": remove "+path+"\n", out)
+	assert.FileExists(t, path)
+
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "project", "delete", p.ID, "--dry-run"))
+	})
+	assert.Contains(t, out, "Would delete project TP with 1 tasks and 0 documents: remove ")
+	_, ok := cfg.Projects["TP"]
+	assert.True(t, ok, "the project stays cached")
+	_, _, err := s.GetProject(t.Context(), p.ID)
+	require.NoError(t, err)
+
+	// config and the project cache are left alone too
+	out = captureStdout(t, func() {
+		require.NoError(t, run(t, "project", "set-store", p.ID, "local", "--dry-run"))
+		require.NoError(t, run(t, "project", "set-default", p.ID, "--dry-run"))
+	})
+	assert.Contains(t, out, "Would cache project TP on local\n")
+	assert.Contains(t, out, "Would write "+filepath.Join(dir, "config.yaml")+"\n")
+	saved, err := config.Load(dir)
+	require.NoError(t, err)
+	assert.Empty(t, saved.DefaultProject)
+}
+
+func TestTaskStart_WIPLimit(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	ls := s.(*store.LocalStore)
+	path, err := ls.ResolveEntityPath(p.ID)
+	require.NoError(t, err)
+	proj, body, err := store.ReadEntity[model.Project](path)
+	require.NoError(t, err)
+	proj.WIPLimits = map[model.Status]int{model.StatusInProgress: 1}
+	require.NoError(t, ls.WriteEntity(path, &proj, body))
+	t.Cleanup(func() {
+		taskStartCmd.Flags().Set("override", "false")
+		taskListCmd.Flags().Set("project", "")
+	})
+
+	first, _ := s.CreateTask(t.Context(), "First", p.ID, store.TaskCreateOpts{})
+	second, _ := s.CreateTask(t.Context(), "Second", p.ID, store.TaskCreateOpts{})
+	require.NoError(t, run(t, "task", "start", first.ID))
+
+	err = run(t, "task", "start", second.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WIP limit 1")
+	got, _, _ := s.GetTask(t.Context(), second.ID)
+	assert.Equal(t, model.StatusOpen, got.Status)
+
+	require.NoError(t, run(t, "task", "start", second.ID, "--override"))
+	got, _, _ = s.GetTask(t.Context(), second.ID)
+	assert.Equal(t, model.StatusInProgress, got.Status)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, run(t, "task", "list", "-P", p.ID))
+	})
+	assert.Contains(t, out, "WIP limit exceeded: 2 in_progress (limit 1)")
+}
+
+func TestTaskClaim_WIPLimit(t *testing.T) {
+	s, dir := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	ls := s.(*store.LocalStore)
+	path, err := ls.ResolveEntityPath(p.ID)
+	require.NoError(t, err)
+	proj, body, err := store.ReadEntity[model.Project](path)
+	require.NoError(t, err)
+	proj.WIPLimits = map[model.Status]int{model.StatusInProgress: 1}
+	require.NoError(t, ls.WriteEntity(path, &proj, body))
+	t.Cleanup(func() { taskClaimCmd.Flags().Set("override", "false") })
+
+	first, _ := s.CreateTask(t.Context(), "First", p.ID, store.TaskCreateOpts{})
+	second, _ := s.CreateTask(t.Context(), "Second", p.ID, store.TaskCreateOpts{})
+	require.NoError(t, run(t, "task", "start", first.ID))
+
+	err = run(t, "task", "claim", "-P", p.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WIP limit 1")
+	agentLog := filepath.Join(dir, "agent.log")
+	err = run(t, "go", "run", "--project", p.ID, "--agent-cmd", "cat >> "+agentLog, "--max", "0", "--log-file", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WIP limit 1")
+	assert.NoFileExists(t, agentLog)
+	got, _, _ := s.GetTask(t.Context(), second.ID)
+	assert.Equal(t, model.StatusOpen, got.Status)
+
+	require.NoError(t, run(t, "task", "claim", "-P", p.ID, "--override"))
+	got, _, _ = s.GetTask(t.Context(), second.ID)
+	assert.Equal(t, model.StatusInProgress, got.Status)
+}
+
+func TestTriage(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	one := 1
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
+	done, _ := s.CreateTask(t.Context(), "Prioritized", p.ID, store.TaskCreateOpts{Priority: &one})
+	first, _ := s.CreateTask(t.Context(), "First", p.ID, store.TaskCreateOpts{})
+	second, _ := s.CreateTask(t.Context(), "Second", p.ID, store.TaskCreateOpts{})
+
+	var asked []string
+	orig := triagePrompt
+	t.Cleanup(func() {
+		triagePrompt = orig
+		triageCmd.Flags().Set("project", "")
+	})
+	triagePrompt = func(title string, epics, candidates []model.Task) (triageChoice, error) {
+		asked = append(asked, title)
+		assert.Len(t, epics, 1)
+		if strings.Contains(title, first.ID) {
+			return triageChoice{Priority: 0, Epic: epic.ID, DependsOn: []string{done.ID}}, nil
+		}
+		return triageChoice{Priority: -1}, nil
+	}
+
+	require.NoError(t, run(t, "triage", "-P", p.ID))
+	require.Len(t, asked, 2)
+
+	got, _, err := s.GetTask(t.Context(), first.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.Priority)
+	assert.Equal(t, 0, *got.Priority)
+	assert.Equal(t, epic.ID, got.Epic)
+	assert.Equal(t, []string{done.ID}, got.DependsOn)
+
+	got, _, err = s.GetTask(t.Context(), second.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.Priority)
+	assert.Empty(t, got.Epic)
+}
+
+func TestInit(t *testing.T) {
+	s, _ := setupEnv(t)
+	existing, _ := s.CreateProject(t.Context(), "Existing", "EX", "")
+	reg.CacheProject(existing.ID, "local")
+	t.Cleanup(func() {
+		for _, f := range []string{"project", "name", "key"} {
+			initCmd.Flags().Set(f, "")
+		}
+		initCmd.Flags().Set("gitignore", "false")
+		initCmd.Flags().Set("starter", "false")
+	})
+
+	origDir, _ := os.Getwd()
+	repo := t.TempDir()
+	os.Chdir(repo)
+	defer os.Chdir(origDir)
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".gitignore"), []byte("bin/"), 0644))
+
+	require.NoError(t, run(t, "init", "--name", "My App", "--key", "APP", "--gitignore", "--starter"))
+
+	linked, err := repofile.Read(repo)
+	require.NoError(t, err)
+	assert.Equal(t, "APP", linked)
+	ignore, _ := os.ReadFile(filepath.Join(repo, ".gitignore"))
+	assert.Equal(t, "bin/\n.compass/\n", string(ignore))
+	epics, _ := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: "APP", Type: model.TypeEpic})
+	assert.Len(t, epics, 1)
+	docs, _ := s.ListDocuments(t.Context(), store.DocumentFilter{ProjectID: "APP"})
+	assert.Len(t, docs, len(starterDocs))
+
+	err = run(t, "init", "--project", existing.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already linked to APP")
+
+	other := t.TempDir()
+	os.Chdir(other)
+	initCmd.Flags().Set("name", "")
+	initCmd.Flags().Set("gitignore", "false")
+	initCmd.Flags().Set("starter", "false")
+	require.NoError(t, run(t, "init", "--project", existing.ID))
+	linked, _ = repofile.Read(other)
+	assert.Equal(t, existing.ID, linked)
+	assert.NoFileExists(t, filepath.Join(other, ".gitignore"))
+}
+
+// gitRepo makes dir a git repository with one commit on main and chdirs
+// into it for the rest of the test.
+func gitRepo(t *testing.T, dir string) {
+	t.Helper()
+	gitEnv(t)
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(origDir) })
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"commit", "-q", "--allow-empty", "-m", "Initial commit"},
+	} {
+		_, err := git(args...)
+		require.NoError(t, err)
+	}
+}
+
+// gitEnv gives git a fixed identity and no user config.
+func gitEnv(t *testing.T) {
+	for _, kv := range [][2]string{
+		{"GIT_AUTHOR_NAME", "Test"}, {"GIT_AUTHOR_EMAIL", "test@example.com"},
+		{"GIT_COMMITTER_NAME", "Test"}, {"GIT_COMMITTER_EMAIL", "test@example.com"},
+		{"GIT_CONFIG_GLOBAL", os.DevNull},
+	} {
+		t.Setenv(kv[0], kv[1])
+	}
+}
+
+func TestTaskCurrent_FromBranch(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Login", p.ID, store.TaskCreateOpts{})
+	t.Cleanup(func() {
+		quiet = false
+		taskCreateCmd.Flags().Set("project", "")
+	})
+
+	gitRepo(t, t.TempDir())
+	err := run(t, "task", "current")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "branch main does not name a task")
+
+	_, err = git("checkout", "-q", "-b", "feature/"+task.ID+"-login")
+	require.NoError(t, err)
+	out := captureStdout(t, func() { err = run(t, "-q", "task", "current") })
+	require.NoError(t, err)
+	assert.Equal(t, task.ID+"\n", out)
+
+	// The branch also picks the project when no --project is given.
+	require.NoError(t, run(t, "task", "create", "Follow-up"))
+	tasks, _ := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
+	assert.Len(t, tasks, 2)
+}
+
+func TestResolveProject_BranchWithAliasOnlyCache(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(config.AliasKey(p.ID, "local"), "local")
+	task, _ := s.CreateTask(t.Context(), "Login", p.ID, store.TaskCreateOpts{})
+	t.Cleanup(func() { taskCreateCmd.Flags().Set("project", "") })
+
+	gitRepo(t, t.TempDir())
+	_, err := git("checkout", "-q", "-b", "feature/"+task.ID+"-login")
+	require.NoError(t, err)
+	require.NoError(t, run(t, "task", "create", "Follow-up"))
+	tasks, _ := s.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
+	assert.Len(t, tasks, 2)
+}
+
+func TestTaskBranchAndPR(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	task, _ := s.CreateTask(t.Context(), "Login page", p.ID, store.TaskCreateOpts{Body: "Build the login page."})
+	gitRepo(t, t.TempDir())
+
+	require.NoError(t, run(t, "task", "branch", task.ID))
+	assert.Equal(t, "task/"+task.ID+"-login-page", currentBranch())
+	got, _, _ := s.GetTask(t.Context(), task.ID)
+	assert.Equal(t, model.StatusInProgress, got.Status)
+
+	cfg.BranchTemplate = "feature/{slug}"
+	require.NoError(t, config.Save(dataDir, cfg))
+	_, err := git("checkout", "-q", "main")
+	require.NoError(t, err)
+	require.NoError(t, run(t, "task", "branch", task.ID))
+	assert.Equal(t, "feature/login-page", currentBranch())
+
+	var ghArgs []string
+	orig := runGH
+	t.Cleanup(func() { runGH = orig })
+	runGH = func(args ...string) error {
+		ghArgs = args
+		return nil
+	}
+	require.NoError(t, run(t, "task", "pr", task.ID))
+	assert.Equal(t, []string{"pr", "create", "--title", "Login page", "--body", "Build the login page.\n\nCloses " + task.ID + "\n"}, ghArgs)
+}
+
+func TestClosingRefs(t *testing.T) {
+	msg := "Fix login\n\nFixes typo in AUTH-TABCDE docs\nCloses: AUTH-TBCDEF, AUTH-TCDEFG\nresolves AUTH-DABCDE\nfixed AUTH-TDEFGH"
+	assert.Equal(t, []string{"AUTH-TBCDEF", "AUTH-TCDEFG", "AUTH-TDEFGH"}, closingRefs(msg))
+
+	// longer hashes, from projects that asked for them
+	assert.Equal(t, []string{"AUTH-TBCDEFG", "AUTH-TCDEFGHJ"}, closingRefs("Closes AUTH-TBCDEFG, AUTH-TCDEFGHJ"))
+}
+
+func TestGitScan_Close(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	done, _ := s.CreateTask(t.Context(), "Login page", p.ID, store.TaskCreateOpts{})
+	other, _ := s.CreateTask(t.Context(), "Signup page", p.ID, store.TaskCreateOpts{})
+	t.Cleanup(func() { gitScanCmd.Flags().Set("close", "false") })
+	gitRepo(t, t.TempDir())
+	_, err := git("commit", "-q", "--allow-empty", "-m", "Add login page\n\nCloses "+done.ID)
+	require.NoError(t, err)
+	_, err = git("commit", "-q", "--allow-empty", "-m", "Mention "+other.ID+" without closing it")
+	require.NoError(t, err)
+
+	out := captureStdout(t, func() { err = run(t, "git", "scan") })
+	require.NoError(t, err)
+	assert.Contains(t, out, done.ID+" Login page  closed by")
+	assert.NotContains(t, out, other.ID)
+	got, _, _ := s.GetTask(t.Context(), done.ID)
+	assert.Equal(t, model.StatusOpen, got.Status)
+
+	require.NoError(t, run(t, "git", "scan", "--close"))
+	got, _, _ = s.GetTask(t.Context(), done.ID)
+	assert.Equal(t, model.StatusClosed, got.Status)
+	got, _, _ = s.GetTask(t.Context(), other.ID)
+	assert.Equal(t, model.StatusOpen, got.Status)
+}
+
+func TestStoreFetch_Prune(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Live", "LIVE", "")
+	reg.CacheProject(p.ID, "local")
+	reg.CacheProject("GONE", "local")
+	reg.CacheProject("LOST", "old.example")
+	t.Cleanup(func() {
+		storeFetchCmd.Flags().Set("all", "false")
+		storeFetchCmd.Flags().Set("prune", "false")
+	})
+
+	var err error
+	out := captureStdout(t, func() { err = run(t, "store", "fetch", "--all", "--prune") })
+	require.NoError(t, err)
+	assert.Contains(t, out, "Pruned GONE (no longer on local)")
+
+	c, err := config.Load(dataDir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"LIVE": "local", "LOST": "old.example"}, c.Projects)
+}
+
+func TestNonInteractive(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	reg.CacheProject(p.ID, "local")
+	require.NoError(t, config.Save(dataDir, cfg))
+	t.Cleanup(func() { nonInteractive = false })
+
+	// Test stdin isn't a terminal, so prompts are already off.
+	err := run(t, "store", "remove", "local")
+	assert.ErrorIs(t, err, errNonInteractive)
+	assert.ErrorContains(t, err, "pass --force")
+	assert.Equal(t, ExitInvalid, ExitCode(err))
+
+	orig := stdinTerminal
+	stdinTerminal = func() bool { return true }
+	t.Cleanup(func() { stdinTerminal = orig })
+	err = run(t, "--non-interactive", "store", "fetch")
+	assert.ErrorIs(t, err, errNonInteractive)
+	assert.ErrorContains(t, err, "pass --all")
+}
+
+func TestValidate(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	task, err := s.CreateTask(t.Context(), "Login", p.ID, store.TaskCreateOpts{})
+	require.NoError(t, err)
+	t.Cleanup(func() { validateCmd.Flags().Set("json", "false") })
+
+	require.NoError(t, run(t, "validate"))
+	require.NoError(t, run(t, "validate", p.ID))
+
+	path := filepath.Join(t.TempDir(), task.ID+".md")
+	data, err := os.ReadFile(filepath.Join(dataDir, "projects", p.ID, "tasks", task.ID+".md"))
+	require.NoError(t, err)
+	data = []byte(strings.Replace(string(data), "title: Login\n", "title: Login\nepic: AUTH-TZZZZZ\n", 1))
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	out := captureStdout(t, func() { err = run(t, "validate", "--json", path) })
+	require.EqualError(t, err, "1 problem(s) in 1 file(s) checked")
+	var res struct {
+		Files    int             `json:"files"`
+		Problems []store.Problem `json:"problems"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &res))
+	assert.Equal(t, []store.Problem{{File: path, Entity: task.ID, Message: "epic: AUTH-TZZZZZ: not found"}}, res.Problems)
+}
+
+func TestStoreGitInitAndSync(t *testing.T) {
+	gitEnv(t)
+	remote := t.TempDir()
+	_, err := gitIn(remote, "init", "-q", "--bare", "-b", "main")
+	require.NoError(t, err)
+	t.Cleanup(func() { storeGitInitCmd.Flags().Set("remote", "") })
+	lastCommit := func() string {
+		msg, err := gitIn(dataDir, "log", "-1", "--format=%s")
+		require.NoError(t, err)
+		return msg
+	}
+
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	require.NoError(t, run(t, "store", "git-init", "--remote", remote))
+	assert.Equal(t, "compass data", lastCommit())
+	tracked, err := gitIn(dataDir, "ls-files")
+	require.NoError(t, err)
+	assert.Equal(t, ".gitignore\nprojects/AUTH/project.md", tracked)
+	driver, err := gitIn(dataDir, "config", "merge.compass.driver")
+	require.NoError(t, err)
+	assert.Equal(t, "compass merge-file %O %A %B", driver)
+
+	task, _ := s.CreateTask(t.Context(), "Login", p.ID, store.TaskCreateOpts{})
+	captureStdout(t, func() { require.NoError(t, run(t, "task", "list", "-P", p.ID)) })
+	assert.Equal(t, "compass data", lastCommit(), "commands that change nothing don't commit")
+	require.NoError(t, run(t, "task", "close", task.ID))
+	assert.Equal(t, "close "+task.ID, lastCommit())
+	require.NoError(t, run(t, "store", "git-sync"))
+
+	// A second machine picks up the project and task.
+	setupEnv(t)
+	require.NoError(t, run(t, "store", "git-init", "--remote", remote))
+	got, _, err := store.NewLocal(dataDir).GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusClosed, got.Status)
+}
+
+func TestCommitMessage(t *testing.T) {
+	ids := changedEntities("?? projects/AUTH/tasks/AUTH-TABCDE.md\x00 M projects/AUTH/project.md\x00 M projects/AUTH/.lock\x00")
+	assert.Equal(t, []string{"AUTH", "AUTH-TABCDE"}, ids)
+	assert.Equal(t, "create AUTH AUTH-TABCDE", commitMessage("create", ids))
+	assert.Equal(t, "rekey 4 entities", commitMessage("rekey", []string{"A", "B", "C", "D"}))
+}
+
+func TestMergeFile(t *testing.T) {
+	setupEnv(t)
+	dir := t.TempDir()
+	write := func(name, status, updated, body string) string {
+		path := filepath.Join(dir, name)
+		content := "---\nid: AUTH-TABCDE\ntitle: Login\nstatus: " + status + "\nupdated_at: " + updated + "\n---\n\n" + body + "\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	base := write("base", "open", "2026-01-01T00:00:00Z", "one\n\ntwo\n\nthree")
+	ours := write("ours", "closed", "2026-01-03T00:00:00Z", "ONE\n\ntwo\n\nthree")
+	theirs := write("theirs", "in_progress", "2026-01-02T00:00:00Z", "one\n\ntwo\n\nTHREE")
+
+	require.NoError(t, run(t, "merge-file", base, ours, theirs))
+	got, err := os.ReadFile(ours)
+	require.NoError(t, err)
+	assert.Equal(t, "---\nid: AUTH-TABCDE\ntitle: Login\nstatus: closed\nupdated_at: 2026-01-03T00:00:00Z\n---\n\nONE\n\ntwo\n\nTHREE\n", string(got))
+
+	ours = write("ours", "closed", "2026-01-03T00:00:00Z", "uno\n\ntwo\n\nthree")
+	theirs = write("theirs", "open", "2026-01-02T00:00:00Z", "eins\n\ntwo\n\nthree")
+	require.Error(t, run(t, "merge-file", base, ours, theirs))
+	got, err = os.ReadFile(ours)
+	require.NoError(t, err)
+	assert.Contains(t, string(got), "<<<<<<< ours\nuno\n=======\neins\n>>>>>>> theirs")
+}
+
+func TestWorkspaceSync(t *testing.T) {
+	s, _ := setupEnv(t)
+	ls := s.(*store.LocalStore)
+	p, _ := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	task, _ := s.CreateTask(t.Context(), "Login", p.ID, store.TaskCreateOpts{})
+	doc, _ := s.CreateDocument(t.Context(), "Design", p.ID, store.DocumentCreateOpts{Body: "v1"})
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() {
+		os.Chdir(origDir)
+		workspaceSyncCmd.Flags().Set("resolve", "")
+	})
+	// storeEdit rewrites a document in the store an hour later, so the
+	// change is newer than the checkout whatever the clock says.
+	storeEdit := func(body string) {
+		d, _, err := s.GetDocument(t.Context(), doc.ID)
+		require.NoError(t, err)
+		d.UpdatedAt = d.UpdatedAt.Add(time.Hour)
+		path, err := ls.ResolveEntityPath(doc.ID)
+		require.NoError(t, err)
+		require.NoError(t, ls.WriteEntity(path, d, body))
+	}
+
+	require.NoError(t, run(t, "workspace", "add", p.ID))
+	taskPath := filepath.Join(".compass", p.ID, "tasks", task.ID+".md")
+	docPath := filepath.Join(".compass", p.ID, "documents", doc.ID+".md")
+	assert.FileExists(t, docPath)
+
+	data, err := os.ReadFile(taskPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(taskPath, []byte(strings.Replace(string(data), "title: Login", "title: Login page", 1)), 0644))
+	storeEdit("v2")
+
+	out := captureStdout(t, func() { err = run(t, "workspace", "sync") })
+	require.NoError(t, err)
+	assert.Contains(t, out, "Pushed "+task.ID)
+	assert.Contains(t, out, "Pulled "+doc.ID)
+	got, _, err := s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Login page", got.Title)
+	data, err = os.ReadFile(docPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "v2")
+
+	// Edited on both sides: --resolve remote keeps the store's copy.
+	require.NoError(t, os.WriteFile(docPath, []byte(strings.Replace(string(data), "v2", "local", 1)), 0644))
+	storeEdit("v3")
+	out = captureStdout(t, func() { err = run(t, "workspace", "sync", p.ID, "--resolve", "remote") })
+	require.NoError(t, err)
+	assert.Contains(t, out, "Pulled "+doc.ID+" (kept the store version)")
+	data, err = os.ReadFile(docPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "v3")
+
+	// --quiet leaves stdout empty
+	t.Cleanup(func() { quiet = false })
+	storeEdit("v4")
+	out = captureStdout(t, func() { err = run(t, "-q", "workspace", "sync", p.ID) })
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestDocTrackSync(t *testing.T) {
+	s, _ := setupEnv(t)
+	ls := s.(*store.LocalStore)
+	p, _ := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	reg.CacheProject(p.ID, "local")
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() {
+		os.Chdir(origDir)
+		docTrackCmd.Flags().Set("project", "")
+		docTrackCmd.Flags().Set("doc", "")
+		docSyncCmd.Flags().Set("resolve", "")
+	})
+	require.NoError(t, os.MkdirAll("docs", 0755))
+	require.NoError(t, os.WriteFile("docs/design.md", []byte("# Design\n\nv1\n"), 0644))
+
+	require.NoError(t, run(t, "doc", "track", "docs/design.md", "--project", p.ID))
+	tracked, err := readTracked()
+	require.NoError(t, err)
+	docID := tracked["docs/design.md"].Doc
+	d, body, err := s.GetDocument(t.Context(), docID)
+	require.NoError(t, err)
+	assert.Equal(t, "Design", d.Title)
+	assert.Contains(t, body, "v1")
+
+	// Nothing changed.
+	out := captureStdout(t, func() { err = run(t, "doc", "sync") })
+	require.NoError(t, err)
+	assert.Contains(t, out, "Pushed 0, pulled 0")
+
+	// A file edit is pushed.
+	require.NoError(t, os.WriteFile("docs/design.md", []byte("# Design\n\nv2\n"), 0644))
+	out = captureStdout(t, func() { err = run(t, "doc", "sync") })
+	require.NoError(t, err)
+	assert.Contains(t, out, "Pushed docs/design.md to "+docID)
+	_, body, _ = s.GetDocument(t.Context(), docID)
+	assert.Contains(t, body, "v2")
+
+	// A document edit, an hour later, is pulled.
+	storeEdit := func(body string) {
+		d, _, err := s.GetDocument(t.Context(), docID)
+		require.NoError(t, err)
+		d.UpdatedAt = d.UpdatedAt.Add(time.Hour)
+		path, err := ls.ResolveEntityPath(docID)
+		require.NoError(t, err)
+		require.NoError(t, ls.WriteEntity(path, d, body))
+	}
+	storeEdit("# Design\n\nv3")
+	out = captureStdout(t, func() { err = run(t, "doc", "sync", "docs/design.md") })
+	require.NoError(t, err)
+	assert.Contains(t, out, "Pulled "+docID)
+	data, _ := os.ReadFile("docs/design.md")
+	assert.Contains(t, string(data), "v3")
+
+	// Both edited: --resolve local keeps the file.
+	require.NoError(t, os.WriteFile("docs/design.md", []byte("# Design\n\nmine\n"), 0644))
+	storeEdit("# Design\n\ntheirs")
+	require.NoError(t, run(t, "doc", "sync", "--resolve", "local"))
+	_, body, _ = s.GetDocument(t.Context(), docID)
+	assert.Contains(t, body, "mine")
+
+	// --quiet leaves stdout empty
+	t.Cleanup(func() { quiet = false })
+	require.NoError(t, os.WriteFile("docs/design.md", []byte("# Design\n\nquiet\n"), 0644))
+	out = captureStdout(t, func() { err = run(t, "-q", "doc", "sync") })
+	require.NoError(t, err)
+	assert.Empty(t, out)
+	quiet = false
+
+	// Linking an existing document writes the missing file.
+	other, _ := s.CreateDocument(t.Context(), "Runbook", p.ID, store.DocumentCreateOpts{Body: "restart"})
+	require.NoError(t, run(t, "doc", "track", "docs/runbook.md", "--doc", other.ID))
+	data, _ = os.ReadFile("docs/runbook.md")
+	assert.Equal(t, "restart", string(data))
+
+	require.NoError(t, run(t, "doc", "untrack", "docs/runbook.md"))
+	assert.Error(t, run(t, "doc", "sync", "docs/runbook.md"))
+}
+
+func TestListOutputCSV(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	dep, _ := s.CreateTask(t.Context(), `Fix "login", again`, p.ID, store.TaskCreateOpts{})
+	task, _ := s.CreateTask(t.Context(), "Ship", p.ID, store.TaskCreateOpts{DependsOn: []string{dep.ID}})
+	s.CreateDocument(t.Context(), "Design", p.ID, store.DocumentCreateOpts{})
+	t.Cleanup(func() {
+		for _, c := range []*cobra.Command{taskListCmd, docListCmd} {
+			c.Flags().Set("output", "table")
+			c.Flags().Set("project", "")
+		}
+		taskListCmd.Flags().Set("columns", strings.Join(markdown.DefaultTaskColumns, ","))
+		docListCmd.Flags().Set("columns", strings.Join(markdown.DefaultDocumentColumns, ","))
+	})
+
+	var err error
+	out := captureStdout(t, func() {
+		err = run(t, "task", "list", "-P", p.ID, "--output", "csv", "--columns", "id,title,status")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "id,title,status\n"+dep.ID+`,"Fix ""login"", again",open`+"\n"+task.ID+",Ship,open (blocked)\n", out)
+
+	out = captureStdout(t, func() { err = run(t, "doc", "list", "-P", p.ID, "-o", "tsv", "--columns", "title,kind") })
+	require.NoError(t, err)
+	assert.Equal(t, "title\tkind\nDesign\t\n", out)
+
+	assert.EqualError(t, run(t, "task", "list", "-P", p.ID, "-o", "xml"), `invalid --output "xml" (valid: table, csv, tsv)`)
+}
+
+func TestServeMetrics(t *testing.T) {
+	s, _ := setupEnv(t)
+	p, _ := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	reg.CacheProject(p.ID, "local")
+	dep, _ := s.CreateTask(t.Context(), "Schema", p.ID, store.TaskCreateOpts{})
+	s.CreateTask(t.Context(), "Login", p.ID, store.TaskCreateOpts{DependsOn: []string{dep.ID}})
+	done, _ := s.CreateTask(t.Context(), "Spike", p.ID, store.TaskCreateOpts{})
+	closed := model.StatusClosed
+	_, err := s.UpdateTask(t.Context(), done.ID, store.TaskUpdate{Status: &closed})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	serveMux().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics?project=AUTH&project=GONE", nil))
+	assert.Equal(t, 200, rec.Code)
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE compass_tasks gauge",
+		`compass_project_up{project="AUTH"} 1`,
+		`compass_project_up{project="GONE"} 0`,
+		`compass_tasks{project="AUTH",status="open"} 2`,
+		`compass_tasks{project="AUTH",status="in_progress"} 0`,
+		`compass_tasks{project="AUTH",status="closed"} 1`,
+		`compass_tasks_blocked{project="AUTH"} 1`,
+		`compass_tasks_ready{project="AUTH"} 1`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	s, _ := setupEnv(t)
+	_, err := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	require.NoError(t, err)
+
+	err = run(t, "task", "show", "TP-TZZZZZ")
+	assert.Equal(t, ExitNotFound, ExitCode(err))
+	err = run(t, "project", "create", "Again", "--key", "TP")
+	assert.Equal(t, ExitConflict, ExitCode(err))
+	assert.Equal(t, ExitUnavailable, ExitCode(&url.Error{Op: "Get", URL: "https://x", Err: errors.New("connection refused")}))
+	assert.Equal(t, ExitDenied, ExitCode(fmt.Errorf("work: %w", store.ErrUnauthorized)))
+	assert.Equal(t, ExitError, ExitCode(errors.New("boom")))
+	assert.Equal(t, 0, ExitCode(nil))
+
+	var buf strings.Builder
+	printError(&buf, taskCreateCmd, errors.New("boom"))
+	assert.Equal(t, "Error: boom\n", buf.String())
+	taskCreateCmd.Flags().Set("json", "true")
+	t.Cleanup(func() { taskCreateCmd.Flags().Set("json", "false") })
+	buf.Reset()
+	printError(&buf, taskCreateCmd, fmt.Errorf("epic %w", store.ErrNotFound))
+	assert.JSONEq(t, `{"error": {"code": "not_found", "message": "epic not found", "exit_code": 3}}`, buf.String())
+}
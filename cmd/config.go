@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rogersnm/compass/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change config.yaml",
+	Long: `View and change config.yaml in the data directory. Changes are checked
+before they are saved: unknown keys are rejected, default_store must name a
+configured store, and each store needs a hostname.`,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit config.yaml in $EDITOR, validating it before saving",
+	Long: `Open a copy of config.yaml in $EDITOR and save it once it parses and
+validates. Invalid edits are not saved; they are kept in a temp file whose
+path is printed, so nothing is lost.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := filepath.Join(dataDir, "config.yaml")
+		orig, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		edited, err := editTemp("compass-config-*.yaml", orig)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(edited, orig) {
+			infof("No changes to config.yaml\n")
+			return nil
+		}
+		if _, err := config.Parse(edited); err != nil {
+			return keepEdits(edited, fmt.Errorf("config.yaml not saved: %w", err))
+		}
+		if err := os.MkdirAll(dataDir, 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, edited, 0644); err != nil {
+			return err
+		}
+		infof("Saved config.yaml\n")
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <dotted.path>",
+	Short: "Print a setting, such as default_store or stores.work.hostname",
+	Long: `Print the setting at a dotted path of config.yaml keys, such as
+default_store, stores.work.hostname or notifications.0.url. Sections are
+printed as YAML.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := config.Load(dataDir)
+		if err != nil {
+			return err
+		}
+		v, err := config.Get(c, args[0])
+		if err != nil {
+			return err
+		}
+		switch v.(type) {
+		case map[string]any, []any:
+			out, err := yaml.Marshal(v)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(out))
+		default:
+			fmt.Println(v)
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <dotted.path> <value>",
+	Short: "Change a setting, validating the result before saving",
+	Long: `Set the config.yaml key at a dotted path, creating sections as needed:
+
+  compass config set default_store work
+  compass config set stores.work.read_only true
+  compass config set branch_template "{id}-{slug}"
+
+The value is read as YAML, so true and 3 are a bool and a number. An empty
+value ("") removes the setting. The whole config is validated before it is
+saved.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := config.Load(dataDir)
+		if err != nil {
+			return err
+		}
+		c, err = config.Set(c, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		if err := saveConfig(c); err != nil {
+			return err
+		}
+		infof("Set %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// saveConfig writes c to config.yaml, or under --dry-run says it would.
+func saveConfig(c *config.Config) error {
+	if dryRun {
+		fmt.Printf("Would write %s\n", filepath.Join(dataDir, "config.yaml"))
+		return nil
+	}
+	return config.Save(dataDir, c)
+}
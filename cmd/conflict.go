@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+)
+
+// Conflict resolutions offered by resolveConflict and accepted by --resolve.
+const (
+	resolveLocal  = "local"
+	resolveRemote = "remote"
+	resolveMerge  = "merge"
+)
+
+// conflict is a local copy of an entity that diverged from the store's copy.
+type conflict struct {
+	ID     string
+	Local  []byte
+	Remote []byte
+}
+
+// resolveConflict asks how to settle a conflict and returns the resolution
+// and, for merges, the merged content. The --resolve flag, when set, skips
+// the prompt. Merging opens $EDITOR on both versions separated by
+// conflict markers.
+func resolveConflict(cmd *cobra.Command, c conflict) (string, []byte, error) {
+	choice, _ := cmd.Flags().GetString("resolve")
+	if choice == "" {
+		fmt.Fprintf(os.Stderr, "%s changed in the store since it was downloaded.\n", c.ID)
+		if err := canPrompt("pass --resolve local, remote or merge"); err != nil {
+			return "", nil, err
+		}
+		if err := huh.NewSelect[string]().
+			Title(fmt.Sprintf("Resolve conflict on %s", c.ID)).
+			Options(
+				huh.NewOption("Keep my local copy (overwrite the store)", resolveLocal),
+				huh.NewOption("Keep the store version (discard my copy)", resolveRemote),
+				huh.NewOption("Edit a merged copy", resolveMerge),
+			).
+			Value(&choice).
+			Run(); err != nil {
+			return "", nil, fmt.Errorf("cancelled")
+		}
+	}
+
+	switch choice {
+	case resolveLocal:
+		return choice, c.Local, nil
+	case resolveRemote:
+		return choice, c.Remote, nil
+	case resolveMerge:
+		merged, err := editMerged(c)
+		return choice, merged, err
+	default:
+		return "", nil, fmt.Errorf("invalid --resolve %q (valid: local, remote, merge)", choice)
+	}
+}
+
+// editMerged opens both versions in the editor and returns the result once
+// all conflict markers have been removed.
+func editMerged(c conflict) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<<<<<<< local\n%s", c.Local)
+	fmt.Fprintf(&buf, "=======\n%s", c.Remote)
+	fmt.Fprintf(&buf, ">>>>>>> store\n")
+
+	merged, err := editTemp(c.ID+"-merge-*.md", buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	for _, marker := range []string{"<<<<<<< ", "=======\n", ">>>>>>> "} {
+		if bytes.Contains(merged, []byte(marker)) {
+			return nil, fmt.Errorf("conflict markers remain in merged copy of %s; nothing uploaded", c.ID)
+		}
+	}
+	return merged, nil
+}
+
+// checkinConflict compares a downloaded copy with the store's current copy.
+// If the store changed since download, the conflict is resolved and the
+// chosen content written back to localPath. It reports whether the local
+// copy should be uploaded; when it should not, the local copy is removed.
+func checkinConflict(cmd *cobra.Command, id, localPath string, localUpdated, storeUpdated time.Time, remote []byte) (bool, error) {
+	if !storeUpdated.After(localUpdated) {
+		return true, nil
+	}
+	local, err := os.ReadFile(localPath)
+	if err != nil {
+		return false, fmt.Errorf("reading local file: %w", err)
+	}
+	choice, content, err := resolveConflict(cmd, conflict{ID: id, Local: local, Remote: remote})
+	if err != nil {
+		return false, err
+	}
+	if choice == resolveRemote {
+		os.Remove(localPath)
+		return false, nil
+	}
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
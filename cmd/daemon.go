@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rogersnm/compass/internal/daemon"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// openLocal returns the local store, reading listings and search through
+// the daemon when one is running.
+func openLocal() store.Store {
+	ls := store.NewLocal(dataDir)
+	ls.SetDepPolicy(depPolicy())
+	sock := daemon.SocketPath(dataDir)
+	if _, err := os.Stat(sock); err != nil {
+		return ls
+	}
+	return daemon.NewClient(sock, ls)
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Keep the local store cached in memory for faster list and search",
+	Long: `Run in the foreground, keeping every local project, task, document and
+release parsed in memory, and answer list and search queries from other
+compass commands over a unix socket in the data directory. Changed files
+are picked up every --interval and re-checked on every query, so results
+always match the disk. Writes don't go through the daemon.
+
+Commands use the daemon automatically while it runs and fall back to
+reading the disk when it isn't. Stop it with Ctrl-C or SIGTERM.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cfg.LocalEnabled {
+			return fmt.Errorf("the local store is not enabled; run: compass store add local")
+		}
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+
+		ln, err := daemon.Listen(dataDir)
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ls, dreg := daemon.NewLocal(dataDir)
+		go daemon.Watch(ctx, ls, interval, func(err error) {
+			slog.Warn("refreshing cache", "err", err)
+		})
+		infof("Listening on %s\n", ln.Addr())
+		slog.Info("daemon started", "socket", ln.Addr().String(), "interval", interval)
+		err = daemon.Serve(ctx, ln, dreg)
+		slog.Info("daemon stopped")
+		return err
+	},
+}
+
+func init() {
+	daemonCmd.Flags().Duration("interval", 2*time.Second, "how often to pick up changed files")
+	rootCmd.AddCommand(daemonCmd)
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/store"
+)
+
+// debugMode is the --debug flag: "" (off), "stderr" or "file".
+var debugMode string
+
+// debugOut receives the request log of every cloud store, nil when
+// --debug and COMPASS_DEBUG are both unset.
+var debugOut io.Writer
+
+// debugLogFile is the request log kept in the data directory by
+// --debug=file. It's rotated to debug.log.1 when it reaches debugLogMax.
+const (
+	debugLogFile = "debug.log"
+	debugLogMax  = 1 << 20
+)
+
+// openDebugLog sets debugOut from --debug, or COMPASS_DEBUG when the flag
+// isn't given.
+func openDebugLog() error {
+	mode := debugMode
+	if mode == "" {
+		mode = os.Getenv("COMPASS_DEBUG")
+	}
+	switch mode {
+	case "", "0", "false":
+		debugOut = nil
+	case "1", "true", "stderr":
+		debugOut = os.Stderr
+	case "file":
+		debugOut = &rotatingLog{path: filepath.Join(dataDir, debugLogFile), max: debugLogMax}
+	default:
+		return fmt.Errorf("invalid debug mode %q (want stderr or file)", mode)
+	}
+	return nil
+}
+
+// openCloud opens a configured cloud store, logging its requests under
+// --debug and applying the missing_deps policy.
+func openCloud(name string, sc config.CloudStoreConfig) (*store.CloudStore, error) {
+	cs, err := store.NewCloudStoreFromConfig(name, sc)
+	if err != nil {
+		return nil, err
+	}
+	cs.SetTrace(debugOut)
+	cs.SetDepPolicy(depPolicy())
+	return cs, nil
+}
+
+// rotatingLog appends to a file, moving it to <path>.1 once it outgrows
+// max so the log never takes more than twice that. Writes from stores
+// queried in parallel are serialized.
+type rotatingLog struct {
+	path string
+	max  int64
+
+	mu sync.Mutex
+}
+
+func (l *rotatingLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if info, err := os.Stat(l.path); err == nil && info.Size()+int64(len(p)) > l.max {
+		if err := os.Rename(l.path, l.path+".1"); err != nil {
+			return 0, err
+		}
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return f.Write(p)
+}
@@ -1,12 +1,20 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/rogersnm/compass/internal/editor"
 	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
 	"github.com/spf13/cobra"
 )
 
@@ -18,55 +26,280 @@ var docCmd = &cobra.Command{
 var docCreateCmd = &cobra.Command{
 	Use:   "create <title>",
 	Short: "Create a new document",
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new document. The body is read from stdin.
+
+With --json, stdin is a JSON object instead and the created document is
+printed as JSON:
+
+  {"title": "...", "project": "AUTH", "kind": "spec", "body": "..."}
+
+A title argument overrides the object's title; the project falls back to
+--project and the usual resolution.
+
+With --edit, $EDITOR opens on a frontmatter template prefilled from the
+title and --project, and the document is created when the editor exits.
+
+With --file, the body is read from a file instead. Without a title argument
+the file's first H1 becomes the title (and is dropped from the body), or
+else its name. --file takes glob patterns and can be repeated, creating
+one document per file for bulk imports; quote patterns so compass expands
+them rather than the shell:
+
+  compass doc create --file 'docs/*.md' --kind spec`,
+	Args: titleArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		projectID, err := resolveProject(cmd)
+		ctx := cmd.Context()
+		if files, _ := cmd.Flags().GetStringArray("file"); len(files) > 0 {
+			return docCreateFiles(cmd, args, files)
+		}
+		if edit, _ := cmd.Flags().GetBool("edit"); edit {
+			return docCreateEdit(cmd, args)
+		}
+		asJSON, _ := cmd.Flags().GetBool("json")
+		var title, projectID string
+		var opts store.DocumentCreateOpts
+		if asJSON {
+			in, err := readJSONInput[struct {
+				Title   string        `json:"title"`
+				Project string        `json:"project"`
+				Kind    model.DocKind `json:"kind"`
+				Body    string        `json:"body"`
+			}]()
+			if err != nil {
+				return err
+			}
+			title, projectID, opts.Kind, opts.Body = in.Title, in.Project, in.Kind, in.Body
+		} else {
+			opts.Body = readStdin()
+		}
+		if cmd.Flags().Changed("kind") {
+			kind, _ := cmd.Flags().GetString("kind")
+			opts.Kind = model.DocKind(kind)
+		}
+		if len(args) == 1 {
+			title = args[0]
+		}
+		if title == "" {
+			return fmt.Errorf("title is required")
+		}
+		if projectID == "" {
+			var err error
+			if projectID, err = resolveProject(cmd); err != nil {
+				return err
+			}
+		}
+
+		s, err := storeForProject(ctx, projectID)
 		if err != nil {
 			return err
 		}
+		slug, _ := cmd.Flags().GetString("slug")
+		if opts.Slug, err = slugFlag(ctx, s, slug, projectID, title, ""); err != nil {
+			return err
+		}
 
-		s, err := storeForProject(projectID)
+		d, err := s.CreateDocument(ctx, title, projectID, opts)
 		if err != nil {
 			return err
 		}
+		if asJSON {
+			return printJSON(d)
+		}
+		if d.Kind == model.DocADR {
+			printCreated(d.ID, "Created %s %s (%s)\n", d.ADRLabel(), d.Title, d.ID)
+		} else {
+			printCreated(d.ID, "Created document %s (%s)\n", d.Title, d.ID)
+		}
+		return nil
+	},
+}
 
-		body := readStdin()
+// docFile is a document read from disk by doc create --file.
+type docFile struct {
+	path, title, body string
+}
 
-		d, err := s.CreateDocument(args[0], projectID, body)
+// docCreateFiles creates a document from each file matching patterns.
+// Every file is read before any document is created, so a missing file
+// creates nothing.
+func docCreateFiles(cmd *cobra.Command, args, patterns []string) error {
+	ctx := cmd.Context()
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		return fmt.Errorf("--file can't be combined with --json")
+	}
+	if edit, _ := cmd.Flags().GetBool("edit"); edit {
+		return fmt.Errorf("--file can't be combined with --edit")
+	}
+	paths, err := expandFiles(patterns)
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 && len(paths) > 1 {
+		return fmt.Errorf("a title can't be given for %d files; each takes its title from its H1", len(paths))
+	}
+
+	var files []docFile
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Created document %s (%s)\n", d.Title, d.ID)
-		return nil
-	},
+		f := docFile{path: path, body: string(data)}
+		if len(args) == 1 {
+			f.title = args[0]
+		} else if f.title, f.body = markdown.SplitTitle(f.body); f.title == "" {
+			f.title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		files = append(files, f)
+	}
+
+	projectID, err := resolveProject(cmd)
+	if err != nil {
+		return err
+	}
+	s, err := storeForProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	kind, _ := cmd.Flags().GetString("kind")
+	for i, f := range files {
+		d, err := s.CreateDocument(ctx, f.title, projectID, store.DocumentCreateOpts{Kind: model.DocKind(kind), Body: f.body})
+		if err != nil {
+			return fmt.Errorf("%s: %w (created %d of %d)", f.path, err, i, len(files))
+		}
+		printCreated(d.ID, "Created document %s (%s) from %s\n", d.Title, d.ID, f.path)
+	}
+	return nil
+}
+
+// expandFiles expands glob patterns, in order and without repeats. A
+// pattern without glob characters is taken as a path even if it doesn't
+// exist, so reading it reports the error.
+func expandFiles(patterns []string) ([]string, error) {
+	var paths []string
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, fmt.Errorf("bad pattern %q: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", pattern)
+			}
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				paths = append(paths, m)
+			}
+		}
+	}
+	return paths, nil
 }
 
 var docListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List documents",
+	Long: `List documents, narrowed by --kind, --created-by ("me" for yourself),
+--since (updated since a YYYY-MM-DD date, today, yesterday or a duration
+like 3d) and --search, which keeps documents whose title or body contains
+the text. Order them with --sort title, created or updated. With
+--all-stores every configured store is asked at once and the results are
+merged, with a Store column.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		projectID, _ := cmd.Flags().GetString("project")
-
-		s, err := storeForProject(projectID)
+		ctx := cmd.Context()
+		allStores, _ := cmd.Flags().GetBool("all-stores")
+		var projectID string
+		var err error
+		if allStores {
+			projectID, _ = cmd.Flags().GetString("project")
+		} else if projectID, err = resolveProject(cmd); err != nil {
+			return err
+		}
+		asRecords, err := listOutput(cmd)
 		if err != nil {
 			return err
 		}
+		kind, _ := cmd.Flags().GetString("kind")
+		if kind != "" {
+			if err := model.ValidateDocKind(model.DocKind(kind)); err != nil {
+				return err
+			}
+		}
+		createdBy, _ := cmd.Flags().GetString("created-by")
+		search, _ := cmd.Flags().GetString("search")
+		filter := store.DocumentFilter{
+			ProjectID: projectID,
+			Kind:      model.DocKind(kind),
+			CreatedBy: resolveMe(createdBy),
+			Search:    search,
+		}
+		if since, _ := cmd.Flags().GetString("since"); since != "" {
+			if filter.Since, err = parseSince(since, time.Now()); err != nil {
+				return err
+			}
+		}
 
-		docs, err := s.ListDocuments(projectID)
+		page, paged := listPage(cmd)
+		var docs []model.Document
+		var next string
+		if allStores {
+			if docs, err = allStoresDocuments(ctx, filter); err == nil && paged {
+				docs, next, err = store.PageDocuments(docs, page)
+			}
+		} else {
+			s, err := storeForProject(ctx, projectID)
+			if err != nil {
+				return err
+			}
+			if paged {
+				docs, next, err = s.ListDocumentsPage(ctx, filter, page)
+			} else {
+				docs, err = s.ListDocuments(ctx, filter)
+			}
+		}
 		if err != nil {
 			return err
 		}
-		fmt.Println(markdown.RenderDocumentTable(docs))
+		if !paged {
+			pinnedFirst(docs, func(d *model.Document) string { return d.ID })
+		}
+		columns := listColumns(cmd)
+		if kind == string(model.DocADR) && !cmd.Flags().Changed("columns") {
+			columns = markdown.ADRColumns
+		}
+		if allStores {
+			columns = storeColumn(columns, cmd.Flags().Changed("columns"))
+		}
+		if asRecords {
+			rows, err := markdown.DocumentRecords(docs, columns)
+			if err != nil {
+				return err
+			}
+			printNextPage(next)
+			return printRecords(cmd, rows)
+		}
+		out, err := markdown.RenderDocumentColumns(docs, columns)
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+		printNextPage(next)
 		return nil
 	},
 }
 
+const docKindUsage = "document kind (design, spec, runbook, meeting-notes, adr)"
+
 var docShowCmd = &cobra.Command{
 	Use:   "show <id>",
 	Short: "Show document details",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
@@ -83,7 +316,7 @@ var docShowCmd = &cobra.Command{
 				return nil
 			}
 			// Cloud mode: marshal from API response
-			d, body, err := s.GetDocument(args[0])
+			d, body, err := s.GetDocument(ctx, args[0])
 			if err != nil {
 				return err
 			}
@@ -95,16 +328,39 @@ var docShowCmd = &cobra.Command{
 			return nil
 		}
 
-		d, body, err := s.GetDocument(args[0])
+		d, body, err := s.GetDocument(ctx, args[0])
 		if err != nil {
 			return err
 		}
 		fields := []string{
 			markdown.RenderField("ID", d.ID),
 			markdown.RenderField("Project", d.Project),
+		}
+		if d.Slug != "" {
+			fields = append(fields, markdown.RenderField("Slug", d.Project+"/"+d.Slug))
+		}
+		if d.Kind != "" {
+			fields = append(fields, markdown.RenderField("Kind", string(d.Kind)))
+		}
+		if d.Kind == model.DocADR {
+			fields = append(fields,
+				markdown.RenderField("Number", d.ADRLabel()),
+				markdown.RenderField("Status", string(d.Status)),
+			)
+			if d.Supersedes != "" {
+				fields = append(fields, markdown.RenderField("Supersedes", d.Supersedes))
+			}
+			if d.SupersededBy != "" {
+				fields = append(fields, markdown.RenderField("Superseded by", d.SupersededBy))
+			}
+		}
+		fields = append(fields,
 			markdown.RenderField("Created by", d.CreatedBy),
 			markdown.RenderField("Created", d.CreatedAt.Format("2006-01-02 15:04:05")),
 			markdown.RenderField("Updated", d.UpdatedAt.Format("2006-01-02 15:04:05")),
+		)
+		if d.DeletedAt != nil {
+			fields = append(fields, markdown.RenderField("Deleted", d.DeletedAt.Format("2006-01-02 15:04:05")))
 		}
 		fmt.Print(markdown.RenderEntityHeader(d.Title, fields))
 		if body != "" {
@@ -123,32 +379,78 @@ var docUpdateCmd = &cobra.Command{
 	Short: "Update a document",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
 
-		var titlePtr, bodyPtr *string
+		var upd store.DocumentUpdate
 
-		if cmd.Flags().Changed("title") {
-			title, _ := cmd.Flags().GetString("title")
-			titlePtr = &title
-		}
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if asJSON {
+			in, err := readJSONInput[struct {
+				Title *string        `json:"title"`
+				Kind  *model.DocKind `json:"kind"`
+				Body  *string        `json:"body"`
+			}]()
+			if err != nil {
+				return err
+			}
+			upd.Title, upd.Kind, upd.Body = in.Title, in.Kind, in.Body
+		} else {
+			if cmd.Flags().Changed("title") {
+				title, _ := cmd.Flags().GetString("title")
+				upd.Title = &title
+			}
 
-		body := readStdin()
-		if body != "" {
-			bodyPtr = &body
+			body := readStdin()
+			if path, _ := cmd.Flags().GetString("file"); path != "" {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				body = string(data)
+			}
+			if body != "" {
+				upd.Body = &body
+			}
+		}
+		if cmd.Flags().Changed("kind") {
+			kind, _ := cmd.Flags().GetString("kind")
+			k := model.DocKind(kind)
+			upd.Kind = &k
+		}
+		if cmd.Flags().Changed("slug") {
+			slug, _ := cmd.Flags().GetString("slug")
+			if slug == autoSlug {
+				d, _, err := s.GetDocument(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				title := d.Title
+				if upd.Title != nil {
+					title = *upd.Title
+				}
+				if slug, err = slugFlag(ctx, s, slug, d.Project, title, d.ID); err != nil {
+					return err
+				}
+			}
+			upd.Slug = &slug
 		}
 
-		if titlePtr == nil && bodyPtr == nil {
-			return fmt.Errorf("at least one update is required (--title, stdin)")
+		if upd == (store.DocumentUpdate{}) {
+			return fmt.Errorf("at least one update is required (--title, --kind, --slug, --file, stdin)")
 		}
 
-		d, err := s.UpdateDocument(args[0], titlePtr, bodyPtr)
+		d, err := s.UpdateDocument(ctx, args[0], upd)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Updated document %s\n", d.ID)
+		if asJSON {
+			return printJSON(d)
+		}
+		infof("Updated document %s\n", d.ID)
 		return nil
 	},
 }
@@ -158,23 +460,24 @@ var docDeleteCmd = &cobra.Command{
 	Short: "Delete a document",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
 
-		d, _, err := s.GetDocument(args[0])
+		d, _, err := s.GetDocument(ctx, args[0])
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Document: %s (%s)\n", d.Title, d.ID)
+		infof("Document: %s (%s)\n", d.Title, d.ID)
 		if err := confirmDelete(cmd, d.ID); err != nil {
 			return err
 		}
-		if err := s.DeleteDocument(d.ID); err != nil {
+		if err := s.DeleteDocument(ctx, d.ID); err != nil {
 			return err
 		}
-		fmt.Printf("Deleted document %s\n", d.ID)
+		infof("Deleted document %s\n", d.ID)
 		return nil
 	},
 }
@@ -184,7 +487,7 @@ var docEditCmd = &cobra.Command{
 	Short: "Edit a document in $EDITOR",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		s, err := storeForEntity(cmd.Context(), args[0])
 		if err != nil {
 			return err
 		}
@@ -196,16 +499,175 @@ var docEditCmd = &cobra.Command{
 	},
 }
 
+var docSectionsCmd = &cobra.Command{
+	Use:   "sections <id>",
+	Short: "List a document's headings with their anchors",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		_, body, err := s.GetDocument(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		sections := markdown.ParseSections(body)
+		if len(sections) == 0 {
+			fmt.Println("No sections found.")
+			return nil
+		}
+		for _, sec := range sections {
+			fmt.Printf("%s%s %s  #%s\n", strings.Repeat("  ", sec.Level-1), strings.Repeat("#", sec.Level), sec.Title, sec.Anchor)
+		}
+		return nil
+	},
+}
+
+var docEditSectionCmd = &cobra.Command{
+	Use:   "edit-section <id> <heading>",
+	Short: "Replace one section of a document with stdin",
+	Long: `Replace the content under a heading with stdin, leaving the rest of the
+document untouched. The heading may be given as "## API", "API" or an anchor
+from "compass doc sections" ("#api"). The heading line is kept; its
+subsections are replaced along with the content.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		content := readStdin()
+		if content == "" {
+			return fmt.Errorf("section content is required on stdin")
+		}
+		d, err := editDocBody(ctx, s, args[0], func(body string) (string, error) {
+			return markdown.ReplaceSection(body, args[1], content)
+		})
+		if err != nil {
+			return err
+		}
+		infof("Updated section %s of document %s\n", args[1], d.ID)
+		return nil
+	},
+}
+
+var docAppendCmd = &cobra.Command{
+	Use:   "append <id>",
+	Short: "Add stdin to the end of a document",
+	Long: `Add the text on stdin to the end of a document's body, after a blank
+line, without resending the rest:
+
+  printf '## Rollout\n\nStaged by region.\n' | compass doc append AUTH-DXXXXX`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		text := readStdin()
+		if strings.TrimSpace(text) == "" {
+			return fmt.Errorf("text to append is required on stdin")
+		}
+		d, err := editDocBody(ctx, s, args[0], func(body string) (string, error) {
+			body = strings.TrimRight(body, "\n")
+			if body == "" {
+				return text, nil
+			}
+			return body + "\n\n" + text, nil
+		})
+		if err != nil {
+			return err
+		}
+		infof("Appended to document %s\n", d.ID)
+		return nil
+	},
+}
+
+var docPatchCmd = &cobra.Command{
+	Use:   "patch <id> --find <text> --replace <text>",
+	Short: "Replace text in a document",
+	Long: `Replace the text given by --find with --replace in a document's body. The
+text must occur exactly once unless --all replaces every occurrence, so a
+script can't change the wrong line by accident.
+
+  compass doc patch AUTH-DXXXXX --find "Status: draft" --replace "Status: final"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		find, _ := cmd.Flags().GetString("find")
+		if find == "" {
+			return fmt.Errorf("--find is required")
+		}
+		if !cmd.Flags().Changed("replace") {
+			return fmt.Errorf("--replace is required (pass --replace \"\" to delete the text)")
+		}
+		replace, _ := cmd.Flags().GetString("replace")
+		all, _ := cmd.Flags().GetBool("all")
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		var n int
+		d, err := editDocBody(ctx, s, args[0], func(body string) (string, error) {
+			switch n = strings.Count(body, find); {
+			case n == 0:
+				return "", fmt.Errorf("%q not found in %s", find, args[0])
+			case n > 1 && !all:
+				return "", fmt.Errorf("%q occurs %d times in %s; make --find more specific or pass --all", find, n, args[0])
+			}
+			return strings.ReplaceAll(body, find, replace), nil
+		})
+		if err != nil {
+			return err
+		}
+		infof("Replaced %d occurrence(s) in document %s\n", n, d.ID)
+		return nil
+	},
+}
+
+// maxEditAttempts bounds how often editDocBody retries an edit that lost a
+// race with another writer.
+const maxEditAttempts = 3
+
+// editDocBody replaces a document's body with edit applied to it. The
+// document is read and written back only if unchanged in between; if
+// another writer got there first the edit is applied again to the new body.
+func editDocBody(ctx context.Context, s store.Store, docID string, edit func(body string) (string, error)) (*model.Document, error) {
+	for attempt := 1; ; attempt++ {
+		d, body, err := s.GetDocument(ctx, docID)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := edit(body)
+		if err != nil {
+			return nil, err
+		}
+		d, err = s.UpdateDocument(ctx, docID, store.DocumentUpdate{
+			Body:        &updated,
+			IfUnchanged: &store.DocumentVersion{UpdatedAt: d.UpdatedAt, Body: body},
+		})
+		if errors.Is(err, store.ErrConflict) && attempt < maxEditAttempts {
+			continue
+		}
+		return d, err
+	}
+}
+
 var docDownloadCmd = &cobra.Command{
 	Use:   "download <id>",
 	Short: "Copy a document to .compass/ in the current directory for local editing",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
-		path, err := s.DownloadEntity(args[0], ".compass")
+		path, err := s.DownloadEntity(ctx, args[0], ".compass")
 		if err != nil {
 			return err
 		}
@@ -219,26 +681,159 @@ var docUploadCmd = &cobra.Command{
 	Short: "Write a locally edited document back to the store and remove the local copy",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
 		localPath := fmt.Sprintf(".compass/%s.md", args[0])
-		d, err := s.UploadDocument(localPath)
+		local, _, err := store.ReadEntity[model.Document](localPath)
+		if err != nil {
+			return fmt.Errorf("reading local file: %w", err)
+		}
+		current, body, err := s.GetDocument(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		remote, err := markdown.Marshal(current, body)
+		if err != nil {
+			return err
+		}
+		upload, err := checkinConflict(cmd, args[0], localPath, local.UpdatedAt, current.UpdatedAt, remote)
+		if err != nil {
+			return err
+		}
+		if !upload {
+			infof("Kept store version of %s; local copy removed\n", args[0])
+			return nil
+		}
+
+		d, err := s.UploadDocument(ctx, localPath)
+		if err != nil {
+			return err
+		}
+		infof("Uploaded document %s\n", d.ID)
+		return nil
+	},
+}
+
+var docExportCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "Export a document as HTML, PDF, or GitHub-flavored markdown",
+	Long: `Export a document for people who don't use compass.
+
+  --format html   self-contained HTML page (default)
+  --format gfm    plain markdown with the title as a heading, no frontmatter
+  --format pdf    HTML converted with wkhtmltopdf or weasyprint (must be on PATH)
+
+Without -o, html and gfm are written to stdout; pdf requires -o.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		format, _ := cmd.Flags().GetString("format")
+		out, _ := cmd.Flags().GetString("output")
+		if format == "pdf" && out == "" {
+			return fmt.Errorf("--format pdf requires -o <file>")
+		}
+
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Uploaded document %s\n", d.ID)
+		d, body, err := s.GetDocument(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		switch format {
+		case "gfm":
+			data = markdown.ExportGFM(d, body)
+		case "html", "pdf":
+			if data, err = markdown.ExportHTML(d, body); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("invalid --format %q (valid: html, pdf, gfm)", format)
+		}
+
+		if format == "pdf" {
+			if err := htmlToPDF(data, out); err != nil {
+				return err
+			}
+		} else if out == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		} else if err := os.WriteFile(out, data, 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", out, err)
+		}
+		infof("Exported %s to %s\n", d.ID, out)
 		return nil
 	},
 }
 
+// pdfConverters are HTML-to-PDF tools tried in order; each is invoked as
+// "<tool> <input.html> <output.pdf>".
+var pdfConverters = []string{"wkhtmltopdf", "weasyprint"}
+
+func htmlToPDF(html []byte, out string) error {
+	var tool string
+	for _, name := range pdfConverters {
+		if _, err := exec.LookPath(name); err == nil {
+			tool = name
+			break
+		}
+	}
+	if tool == "" {
+		return fmt.Errorf("PDF export needs one of %s on PATH (or export --format html and print from a browser)", strings.Join(pdfConverters, ", "))
+	}
+
+	f, err := os.CreateTemp("", "compass-export-*.html")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(html); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+
+	c := exec.Command(tool, f.Name(), out)
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("%s: %w", tool, err)
+	}
+	return nil
+}
+
 func init() {
 	docCreateCmd.Flags().StringP("project", "P", "", "project ID")
 	docShowCmd.Flags().Bool("pretty", false, "render with ANSI styling")
 	docListCmd.Flags().StringP("project", "P", "", "filter by project")
+	docListCmd.Flags().String("kind", "", "filter by kind (design, spec, runbook, meeting-notes, adr)")
+	docListCmd.Flags().String("created-by", "", `only documents created by this person ("me" for yourself)`)
+	docListCmd.Flags().String("since", "", "only documents updated since (YYYY-MM-DD, today, yesterday, or a duration like 3d)")
+	docListCmd.Flags().String("search", "", "only documents whose title or body contains this text")
+	docListCmd.Flags().Bool("all-stores", false, "list documents from every configured store, with a Store column")
+	addListFlags(docListCmd, store.DocumentSortFields, markdown.DocumentColumnNames, markdown.DefaultDocumentColumns)
+	docCreateCmd.Flags().String("kind", "", docKindUsage)
+	docCreateCmd.Flags().String("slug", "", slugUsage)
+	docCreateCmd.Flags().Bool("edit", false, "write the document in $EDITOR, starting from a template")
+	docCreateCmd.Flags().Bool("json", false, "read the document as a JSON object from stdin and print the result as JSON")
+	docCreateCmd.Flags().StringArray("file", nil, "read the body from a file; globs and repeats create one document per file")
 	docUpdateCmd.Flags().String("title", "", "new title")
+	docUpdateCmd.Flags().String("kind", "", docKindUsage)
+	docUpdateCmd.Flags().String("slug", "", slugUsage+`, or "" to clear`)
+	docUpdateCmd.Flags().Bool("json", false, "read changes as a JSON object from stdin and print the result as JSON")
+	docUpdateCmd.Flags().String("file", "", "replace the body with a file's contents")
 	docDeleteCmd.Flags().BoolP("force", "f", false, "skip confirmation")
+	docPatchCmd.Flags().String("find", "", "text to replace")
+	docPatchCmd.Flags().String("replace", "", "text to put in its place")
+	docPatchCmd.Flags().Bool("all", false, "replace every occurrence rather than requiring exactly one")
+	docExportCmd.Flags().String("format", "html", "output format (html, pdf, gfm)")
+	docExportCmd.Flags().StringP("output", "o", "", "write to file instead of stdout")
+	docUploadCmd.Flags().String("resolve", "", "resolve a conflict without prompting (local, remote, merge)")
 
 	docCmd.AddCommand(docCreateCmd)
 	docCmd.AddCommand(docListCmd)
@@ -246,8 +841,13 @@ func init() {
 	docCmd.AddCommand(docUpdateCmd)
 	docCmd.AddCommand(docDeleteCmd)
 	docCmd.AddCommand(docEditCmd)
+	docCmd.AddCommand(docSectionsCmd)
+	docCmd.AddCommand(docEditSectionCmd)
+	docCmd.AddCommand(docAppendCmd)
+	docCmd.AddCommand(docPatchCmd)
 	docCmd.AddCommand(docDownloadCmd)
 	docCmd.AddCommand(docUploadCmd)
+	docCmd.AddCommand(docExportCmd)
 	rootCmd.AddCommand(docCmd)
 }
 
@@ -255,7 +855,7 @@ func init() {
 // Returns nil if confirmed, error otherwise. Skipped with --force.
 func confirmDelete(cmd *cobra.Command, entityID string) error {
 	force, _ := cmd.Flags().GetBool("force")
-	if force {
+	if force || dryRun {
 		return nil
 	}
 	fmt.Printf("Type %s to confirm deletion: ", entityID)
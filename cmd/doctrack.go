@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// trackedFile lists the repo files linked to documents by doc track, keyed
+// by slash-separated path relative to the directory holding .compass/.
+var trackedFile = filepath.Join(".compass", "tracked.yaml")
+
+// trackedDoc is a file's link to a document and the state of both when
+// they were last synced.
+type trackedDoc struct {
+	Doc       string    `yaml:"doc"`
+	UpdatedAt time.Time `yaml:"updated_at"` // the document's, when synced
+	Sum       string    `yaml:"sum"`        // sha256 of the file, when synced
+}
+
+func readTracked() (map[string]trackedDoc, error) {
+	data, err := os.ReadFile(trackedFile)
+	if os.IsNotExist(err) {
+		return map[string]trackedDoc{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	tracked := map[string]trackedDoc{}
+	if err := yaml.Unmarshal(data, &tracked); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", trackedFile, err)
+	}
+	return tracked, nil
+}
+
+func saveTracked(tracked map[string]trackedDoc) error {
+	if err := os.MkdirAll(filepath.Dir(trackedFile), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(tracked)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(trackedFile, data, 0644)
+}
+
+// trackedKey normalizes a path given on the command line.
+func trackedKey(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+var docTrackCmd = &cobra.Command{
+	Use:   "track <file>",
+	Short: "Link a repo file to a document, kept in step by doc sync",
+	Long: `Link a file in the repo to a compass document, so the spec can live in
+both places. The file's whole content is the document body.
+
+Without --doc, a new document is created from the file, titled by its first
+H1 or else its file name. With --doc, the file is linked to an existing
+document and written from it if it doesn't exist yet; if both exist and
+differ, the next doc sync treats them as a conflict.
+
+Links are kept in .compass/tracked.yaml, with paths relative to the
+current directory, so run doc track and doc sync from the same place
+(usually the repo root).`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		key := trackedKey(args[0])
+		tracked, err := readTracked()
+		if err != nil {
+			return err
+		}
+		if t, ok := tracked[key]; ok {
+			return fmt.Errorf("%s is already tracked as %s", key, t.Doc)
+		}
+
+		docID, _ := cmd.Flags().GetString("doc")
+		var entry trackedDoc
+		if docID == "" {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			projectID, err := resolveProject(cmd)
+			if err != nil {
+				return err
+			}
+			s, err := storeForProject(ctx, projectID)
+			if err != nil {
+				return err
+			}
+			title, _ := markdown.SplitTitle(string(data))
+			if title == "" {
+				title = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+			}
+			kind, _ := cmd.Flags().GetString("kind")
+			d, err := s.CreateDocument(ctx, title, projectID, store.DocumentCreateOpts{Kind: model.DocKind(kind), Body: string(data)})
+			if err != nil {
+				return err
+			}
+			entry = trackedDoc{Doc: d.ID, UpdatedAt: d.UpdatedAt, Sum: fileSum(args[0])}
+			printCreated(d.ID, "Created document %s (%s) tracking %s\n", d.Title, d.ID, key)
+		} else {
+			s, err := storeForEntity(ctx, docID)
+			if err != nil {
+				return err
+			}
+			d, body, err := s.GetDocument(ctx, docID)
+			if err != nil {
+				return err
+			}
+			entry.Doc = d.ID
+			data, err := os.ReadFile(args[0])
+			switch {
+			case os.IsNotExist(err):
+				if err := os.WriteFile(args[0], []byte(body), 0644); err != nil {
+					return err
+				}
+				fallthrough
+			case err == nil && sameBody(string(data), body):
+				entry.UpdatedAt, entry.Sum = d.UpdatedAt, fileSum(args[0])
+			case err != nil:
+				return err
+			}
+			infof("Tracking %s as %s\n", key, d.ID)
+		}
+		tracked[key] = entry
+		return saveTracked(tracked)
+	},
+}
+
+// sameBody compares a file with a document body, which stores trim.
+func sameBody(file, body string) bool {
+	return strings.TrimSpace(file) == strings.TrimSpace(body)
+}
+
+var docUntrackCmd = &cobra.Command{
+	Use:   "untrack <file>",
+	Short: "Stop syncing a file with its document",
+	Long:  `Remove a file's link to its document. Neither the file nor the document is deleted.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := trackedKey(args[0])
+		tracked, err := readTracked()
+		if err != nil {
+			return err
+		}
+		t, ok := tracked[key]
+		if !ok {
+			return fmt.Errorf("%s is not tracked", key)
+		}
+		delete(tracked, key)
+		if err := saveTracked(tracked); err != nil {
+			return err
+		}
+		infof("Stopped tracking %s (%s)\n", key, t.Doc)
+		return nil
+	},
+}
+
+var docSyncCmd = &cobra.Command{
+	Use:   "sync [file...]",
+	Short: "Push file edits to tracked documents and pull document edits to files",
+	Long: `Compare each tracked file (or just those given) with its document since
+the last sync: a file edit updates the document, a document edit rewrites
+the file. When both changed, the conflict is settled as on upload: a prompt,
+or --resolve local (the file), remote (the document) or merge.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		tracked, err := readTracked()
+		if err != nil {
+			return err
+		}
+		keys := sortedKeys(tracked)
+		if len(args) > 0 {
+			keys = nil
+			for _, a := range args {
+				key := trackedKey(a)
+				if _, ok := tracked[key]; !ok {
+					return fmt.Errorf("%s is not tracked (link it with: compass doc track %s)", key, a)
+				}
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("no tracked files (link one with: compass doc track <file>)")
+		}
+
+		var pushed, pulled int
+		for _, key := range keys {
+			t := tracked[key]
+			s, err := storeForEntity(ctx, t.Doc)
+			if err != nil {
+				return err
+			}
+			d, body, err := s.GetDocument(ctx, t.Doc)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			path := filepath.FromSlash(key)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w (restore it or run compass doc untrack %s)", key, err, key)
+			}
+			fileEdit := fileSum(path) != t.Sum
+			docEdit := d.UpdatedAt.After(t.UpdatedAt)
+			if fileEdit && docEdit && sameBody(string(data), body) {
+				fileEdit, docEdit = false, false
+				t.UpdatedAt, t.Sum = d.UpdatedAt, fileSum(path)
+			}
+
+			push := fileEdit
+			content := data
+			if fileEdit && docEdit {
+				choice, merged, err := resolveConflict(cmd, conflict{ID: key, Local: data, Remote: []byte(body)})
+				if err != nil {
+					return err
+				}
+				push = choice != resolveRemote
+				content = merged
+			}
+			switch {
+			case push:
+				text := string(content)
+				d, err = s.UpdateDocument(ctx, t.Doc, store.DocumentUpdate{Body: &text})
+				if err != nil {
+					return fmt.Errorf("%s: %w", key, err)
+				}
+				if dryRun {
+					continue
+				}
+				if err := os.WriteFile(path, content, 0644); err != nil {
+					return err
+				}
+				pushed++
+				infof("Pushed %s to %s\n", key, t.Doc)
+			case docEdit:
+				if dryRun {
+					fmt.Printf("Would pull %s: write %s\n", t.Doc, path)
+					continue
+				}
+				if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+					return err
+				}
+				pulled++
+				infof("Pulled %s into %s\n", t.Doc, key)
+			}
+			if push || docEdit {
+				t.UpdatedAt, t.Sum = d.UpdatedAt, fileSum(path)
+			}
+			tracked[key] = t
+		}
+		if dryRun {
+			return nil
+		}
+		if err := saveTracked(tracked); err != nil {
+			return err
+		}
+		infof("Pushed %d, pulled %d\n", pushed, pulled)
+		return nil
+	},
+}
+
+func init() {
+	docTrackCmd.Flags().StringP("project", "P", "", "project for the new document")
+	docTrackCmd.Flags().String("doc", "", "link to this existing document instead of creating one")
+	docTrackCmd.Flags().String("kind", "", docKindUsage)
+	docSyncCmd.Flags().String("resolve", "", "settle conflicts without prompting: local, remote or merge")
+	docCmd.AddCommand(docTrackCmd)
+	docCmd.AddCommand(docUntrackCmd)
+	docCmd.AddCommand(docSyncCmd)
+}
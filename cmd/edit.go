@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/editor"
+	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// editTemp writes content to a temp file named after pattern, opens it in
+// $EDITOR, and returns what was saved.
+func editTemp(pattern string, content []byte) ([]byte, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return nil, err
+	}
+	f.Close()
+
+	if err := editor.Open(f.Name()); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(f.Name())
+}
+
+// keepEdits saves edited content that couldn't be used, so a failed create
+// doesn't throw away a long description, and adds its path to err.
+func keepEdits(content []byte, err error) error {
+	f, ferr := os.CreateTemp("", "compass-unsaved-*.md")
+	if ferr != nil {
+		return err
+	}
+	defer f.Close()
+	if _, ferr := f.Write(content); ferr != nil {
+		return err
+	}
+	return fmt.Errorf("%w (your edits are saved in %s)", err, f.Name())
+}
+
+type taskTemplate struct {
+	Title     string         `yaml:"title"`
+	Project   string         `yaml:"project"`
+	Type      model.TaskType `yaml:"type"`
+	Priority  *int           `yaml:"priority"`
+	Epic      string         `yaml:"epic"`
+	DependsOn []string       `yaml:"depends_on"`
+}
+
+// taskCreateEdit implements "task create --edit": the flags and title
+// prefill a frontmatter template that is created once the editor exits.
+func taskCreateEdit(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	// The project can be filled in the template when it can't be resolved.
+	projectID, _ := resolveProject(cmd)
+	tmpl := taskTemplate{Project: projectID, Type: model.TypeTask}
+	if len(args) == 1 {
+		tmpl.Title = args[0]
+	}
+	if typeStr, _ := cmd.Flags().GetString("type"); typeStr != "" {
+		tmpl.Type = model.TaskType(typeStr)
+	}
+	tmpl.Epic, _ = cmd.Flags().GetString("parent-epic")
+	if p, _ := cmd.Flags().GetInt("priority"); p >= 0 {
+		tmpl.Priority = &p
+	}
+	if depsStr, _ := cmd.Flags().GetString("depends-on"); depsStr != "" {
+		tmpl.DependsOn = strings.Split(depsStr, ",")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	fmt.Fprintf(&buf, "title: %s\n", strconv.Quote(tmpl.Title))
+	fmt.Fprintf(&buf, "project: %s\n", tmpl.Project)
+	fmt.Fprintf(&buf, "type: %s # task or epic\n", tmpl.Type)
+	buf.WriteString("priority: ")
+	if tmpl.Priority != nil {
+		buf.WriteString(strconv.Itoa(*tmpl.Priority))
+	}
+	buf.WriteString(" # 0 (critical) to 3 (low); empty for none\n")
+	fmt.Fprintf(&buf, "epic: %s\n", strconv.Quote(tmpl.Epic))
+	fmt.Fprintf(&buf, "depends_on: [%s]\n", strings.Join(tmpl.DependsOn, ", "))
+	buf.WriteString("---\n\n")
+
+	content, err := editTemp("compass-task-*.md", buf.Bytes())
+	if err != nil {
+		return err
+	}
+	meta, body, err := markdown.Parse[taskTemplate](bytes.NewReader(content))
+	if err != nil {
+		return keepEdits(content, err)
+	}
+	if strings.TrimSpace(meta.Title) == "" {
+		return fmt.Errorf("title is empty; no task created")
+	}
+	if meta.Project == "" {
+		return keepEdits(content, fmt.Errorf("project is empty; no task created"))
+	}
+
+	s, err := storeForProject(ctx, meta.Project)
+	if err != nil {
+		return keepEdits(content, err)
+	}
+	t, err := s.CreateTask(ctx, meta.Title, meta.Project, store.TaskCreateOpts{
+		Type:      meta.Type,
+		Epic:      meta.Epic,
+		Priority:  meta.Priority,
+		DependsOn: meta.DependsOn,
+		Body:      body,
+	})
+	if err != nil {
+		return keepEdits(content, err)
+	}
+	printCreated(t.ID, "Created task %s (%s)\n", t.Title, t.ID)
+	return nil
+}
+
+type docTemplate struct {
+	Title   string        `yaml:"title"`
+	Project string        `yaml:"project"`
+	Kind    model.DocKind `yaml:"kind"`
+}
+
+// docCreateEdit implements "doc create --edit".
+func docCreateEdit(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	// The project can be filled in the template when it can't be resolved.
+	projectID, _ := resolveProject(cmd)
+	title := ""
+	if len(args) == 1 {
+		title = args[0]
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	fmt.Fprintf(&buf, "title: %s\n", strconv.Quote(title))
+	fmt.Fprintf(&buf, "project: %s\n", projectID)
+	kind, _ := cmd.Flags().GetString("kind")
+	fmt.Fprintf(&buf, "kind: %s\n", kind)
+	buf.WriteString("---\n\n")
+
+	content, err := editTemp("compass-doc-*.md", buf.Bytes())
+	if err != nil {
+		return err
+	}
+	meta, body, err := markdown.Parse[docTemplate](bytes.NewReader(content))
+	if err != nil {
+		return keepEdits(content, err)
+	}
+	if strings.TrimSpace(meta.Title) == "" {
+		return fmt.Errorf("title is empty; no document created")
+	}
+	if meta.Project == "" {
+		return keepEdits(content, fmt.Errorf("project is empty; no document created"))
+	}
+
+	s, err := storeForProject(ctx, meta.Project)
+	if err != nil {
+		return keepEdits(content, err)
+	}
+	d, err := s.CreateDocument(ctx, meta.Title, meta.Project, store.DocumentCreateOpts{Kind: meta.Kind, Body: body})
+	if err != nil {
+		return keepEdits(content, err)
+	}
+	printCreated(d.ID, "Created document %s (%s)\n", d.Title, d.ID)
+	return nil
+}
@@ -0,0 +1,387 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var epicCmd = &cobra.Command{
+	Use:   "epic",
+	Short: "Manage epics",
+}
+
+// The epic commands below are shorthands for task commands on type=epic
+// tasks, so both work on the same data.
+
+var epicCreateCmd = &cobra.Command{
+	Use:   "create <title>",
+	Short: "Create an epic (a task with type epic)",
+	Long:  `Create an epic. The body is read from stdin. Same as task create --type epic.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		var priority *int
+		if p, _ := cmd.Flags().GetInt("priority"); p >= 0 {
+			priority = &p
+		}
+		e, err := s.CreateTask(ctx, args[0], projectID, store.TaskCreateOpts{
+			Type:     model.TypeEpic,
+			Priority: priority,
+			Body:     readStdin(),
+		})
+		if err != nil {
+			return err
+		}
+		printCreated(e.ID, "Created epic %s (%s)\n", e.Title, e.ID)
+		return nil
+	},
+}
+
+var epicListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List a project's epics with their progress",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		tasks, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: projectID})
+		if err != nil {
+			return err
+		}
+		var epics []model.Task
+		children := map[string][]model.Task{}
+		for _, t := range tasks {
+			if t.Type == model.TypeEpic {
+				epics = append(epics, t)
+			} else if t.Epic != "" {
+				children[t.Epic] = append(children[t.Epic], t)
+			}
+		}
+		if len(epics) == 0 {
+			fmt.Println("No epics found.")
+			return nil
+		}
+		for _, e := range epics {
+			p := model.ProgressOf(children[e.ID])
+			fmt.Printf("%s  %s  %3d%%  %s\n", e.ID, markdown.RenderProgress(p.Closed, p.Total, 20), p.Percent(), e.Title)
+		}
+		return nil
+	},
+}
+
+var epicShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show an epic and its tasks",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := getEpic(cmd, args[0]); err != nil {
+			return err
+		}
+		return taskShowCmd.RunE(cmd, args)
+	},
+}
+
+var epicUpdateCmd = &cobra.Command{
+	Use:   "update <id>",
+	Short: "Update an epic's title, priority, auto-close or body",
+	Long: `Update an epic. A body piped on stdin replaces the current one.
+--auto-close=true or false sets whether the epic closes when its last open
+task does, overriding the project's auto_close_epics.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := getEpic(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		var upd store.TaskUpdate
+		if cmd.Flags().Changed("title") {
+			title, _ := cmd.Flags().GetString("title")
+			upd.Title = &title
+		}
+		if cmd.Flags().Changed("priority") {
+			var pp *int
+			if p, _ := cmd.Flags().GetInt("priority"); p >= 0 {
+				pp = &p
+			}
+			upd.Priority = &pp
+		}
+		if cmd.Flags().Changed("auto-close") {
+			on, _ := cmd.Flags().GetBool("auto-close")
+			upd.AutoClose = &on
+		}
+		if body := readStdin(); body != "" {
+			upd.Body = &body
+		}
+		if upd.Title == nil && upd.Priority == nil && upd.AutoClose == nil && upd.Body == nil {
+			return fmt.Errorf("at least one update flag or piped body is required (--title, --priority, --auto-close, stdin)")
+		}
+		e, err := s.UpdateTask(ctx, args[0], upd)
+		if err != nil {
+			return err
+		}
+		infof("Updated epic %s\n", e.ID)
+		return nil
+	},
+}
+
+var epicProgressCmd = &cobra.Command{
+	Use:   "progress <id>",
+	Short: "Show how many of an epic's tasks are closed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := getEpic(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		children, err := s.ListTasks(ctx, store.TaskFilter{EpicID: args[0]})
+		if err != nil {
+			return err
+		}
+		p := model.ProgressOf(children)
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			return printJSON(p)
+		}
+		fmt.Printf("%s  %d%%\n", markdown.RenderProgress(p.Closed, p.Total, 30), p.Percent())
+		for _, st := range model.Statuses {
+			if n := p.ByStatus[st]; n > 0 {
+				fmt.Printf("  %s %d\n", markdown.StatusStyle(string(st)).Render(string(st)+":"), n)
+			}
+		}
+		return nil
+	},
+}
+
+// reportEpicClosed tells the user when closing t closed its epic, which
+// epics set to auto-close do once all their tasks are closed.
+func reportEpicClosed(ctx context.Context, s store.Store, t *model.Task) {
+	if t.Epic == "" || t.Status != model.StatusClosed {
+		return
+	}
+	epic, _, err := s.GetTask(ctx, t.Epic)
+	if err != nil || epic.ClosedAt == nil {
+		return
+	}
+	children, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: epic.Project, EpicID: epic.ID})
+	if err != nil {
+		return
+	}
+	infof("Closed epic %s (all %d tasks closed)\n", epic.ID, len(children))
+}
+
+// getEpic returns the store holding id, failing unless id is an epic.
+func getEpic(cmd *cobra.Command, id string) (store.Store, error) {
+	s, err := storeForEntity(cmd.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	t, _, err := s.GetTask(cmd.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Type != model.TypeEpic {
+		return nil, fmt.Errorf("%s is a task, not an epic (use compass task)", id)
+	}
+	return s, nil
+}
+
+var epicPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Create epics and tasks from a markdown outline on stdin",
+	Long: `Create epics and tasks from a markdown outline read from stdin.
+
+Each H1 heading becomes an epic; text under the heading becomes its body.
+Each top-level list item beneath it becomes a task in that epic, with any
+indented lines as the task body. A trailing "(after: ...)" marker adds
+dependencies: a 1-based task number in the outline, the title of an earlier
+task, or an existing task ID, comma-separated.
+
+  # Login
+  Session-based login for the web app.
+  - Design session schema
+  - Build login API (after: 1)
+  - Login form (after: Build login API)
+
+Creation is all-or-nothing: if any entity fails, everything created by the
+command is deleted.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		src := readStdin()
+		if strings.TrimSpace(src) == "" {
+			return fmt.Errorf("no outline on stdin")
+		}
+		outline, err := markdown.ParseOutline(src)
+		if err != nil {
+			return err
+		}
+
+		res, err := store.CreatePlan(ctx, s, projectID, outline)
+		if err != nil {
+			return err
+		}
+		for _, e := range res.Epics {
+			printCreated(e.ID, "Created epic %s (%s)\n", e.Title, e.ID)
+		}
+		infof("Created %d task(s)\n", len(res.Tasks))
+		for _, t := range res.Tasks {
+			fmt.Printf("  %s  %s\n", t.ID, t.Title)
+		}
+		return nil
+	},
+}
+
+var epicAdoptCmd = &cobra.Command{
+	Use:   "adopt <epic-id> [task-id...]",
+	Short: "Set the parent epic on many tasks at once",
+	Long: `Set the parent epic on many tasks at once. Tasks can be named explicitly,
+selected with --from-filter, or both. The filter is a comma-separated list of
+key=value pairs matched against tasks in the epic's project:
+
+  status=open          task status (open, in_progress, blocked, closed)
+  epic=none            tasks without a parent epic (or epic=<id>)
+  title=login          case-insensitive title substring
+
+Everything is validated before any task is changed.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		epicID := args[0]
+		s, err := storeForEntity(ctx, epicID)
+		if err != nil {
+			return err
+		}
+
+		taskIDs := args[1:]
+		if expr, _ := cmd.Flags().GetString("from-filter"); expr != "" {
+			epic, _, err := s.GetTask(ctx, epicID)
+			if err != nil {
+				return err
+			}
+			matched, err := filterTasks(ctx, s, epic.Project, expr)
+			if err != nil {
+				return err
+			}
+			for _, t := range matched {
+				if t.ID != epicID {
+					taskIDs = append(taskIDs, t.ID)
+				}
+			}
+		}
+		if len(taskIDs) == 0 {
+			return fmt.Errorf("no tasks to adopt (pass task IDs or --from-filter)")
+		}
+
+		adopted, err := store.AdoptTasks(ctx, s, epicID, taskIDs)
+		for _, t := range adopted {
+			fmt.Printf("  %s  %s\n", t.ID, t.Title)
+		}
+		if err != nil {
+			return err
+		}
+		infof("Adopted %d task(s) into %s\n", len(adopted), epicID)
+		return nil
+	},
+}
+
+// filterTasks returns the type=task tasks in a project matching a
+// comma-separated key=value filter expression.
+func filterTasks(ctx context.Context, s store.Store, projectID, expr string) ([]model.Task, error) {
+	filter := store.TaskFilter{ProjectID: projectID, Type: model.TypeTask}
+	var noEpic bool
+	var titleSub string
+	for _, part := range strings.Split(expr, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid filter %q: expected key=value", part)
+		}
+		switch key {
+		case "status":
+			if err := model.ValidateStatus(model.Status(val)); err != nil {
+				return nil, err
+			}
+			filter.Status = model.Status(val)
+		case "epic":
+			if val == "none" {
+				noEpic = true
+			} else {
+				filter.EpicID = val
+			}
+		case "title":
+			titleSub = strings.ToLower(val)
+		default:
+			return nil, fmt.Errorf("unknown filter key %q (valid: status, epic, title)", key)
+		}
+	}
+
+	tasks, err := s.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var matched []model.Task
+	for _, t := range tasks {
+		if noEpic && t.Epic != "" {
+			continue
+		}
+		if titleSub != "" && !strings.Contains(strings.ToLower(t.Title), titleSub) {
+			continue
+		}
+		matched = append(matched, t)
+	}
+	return matched, nil
+}
+
+func init() {
+	epicPlanCmd.Flags().StringP("project", "P", "", "project ID")
+
+	epicAdoptCmd.Flags().String("from-filter", "", "select tasks by filter, e.g. \"status=open,epic=none\"")
+
+	epicCreateCmd.Flags().StringP("project", "P", "", "project ID")
+	epicCreateCmd.Flags().IntP("priority", "p", -1, "priority (0=P0 critical, 1=P1 high, 2=P2 medium, 3=P3 low)")
+	epicListCmd.Flags().StringP("project", "P", "", "project ID")
+	epicShowCmd.Flags().Bool("pretty", false, "render with ANSI styling and list the epic's tasks")
+	epicUpdateCmd.Flags().String("title", "", "new title")
+	epicUpdateCmd.Flags().IntP("priority", "p", -1, "priority (0-3, or -1 to clear)")
+	epicUpdateCmd.Flags().Bool("auto-close", false, "close the epic when its last open task closes")
+	epicProgressCmd.Flags().Bool("json", false, "print the counts as JSON")
+
+	epicCmd.AddCommand(epicCreateCmd)
+	epicCmd.AddCommand(epicListCmd)
+	epicCmd.AddCommand(epicShowCmd)
+	epicCmd.AddCommand(epicUpdateCmd)
+	epicCmd.AddCommand(epicProgressCmd)
+	epicCmd.AddCommand(epicPlanCmd)
+	epicCmd.AddCommand(epicAdoptCmd)
+	rootCmd.AddCommand(epicCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes, so scripts can tell a missing task from a network outage.
+// 2 is left for usage errors, as shells use it.
+const (
+	ExitError       = 1 // any other failure
+	ExitNotFound    = 3 // the project or entity doesn't exist
+	ExitConflict    = 4 // clashes with the current state: key taken, release cut, lock held
+	ExitInvalid     = 5 // bad input: field values, references, cycles; a prompt with prompts off
+	ExitDenied      = 6 // API key rejected, or the store is read-only
+	ExitUnavailable = 7 // a store couldn't be reached, timed out or rate limited
+	ExitInterrupted = 130
+)
+
+// errorKinds pairs each exit code with the code name used in JSON errors.
+var errorKinds = map[int]string{
+	ExitError:       "error",
+	ExitNotFound:    "not_found",
+	ExitConflict:    "conflict",
+	ExitInvalid:     "invalid",
+	ExitDenied:      "denied",
+	ExitUnavailable: "unavailable",
+	ExitInterrupted: "interrupted",
+}
+
+// ExitCode returns the process exit code for an error returned by Execute.
+func ExitCode(err error) int {
+	var netErr net.Error
+	var urlErr *url.Error
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, store.ErrUnauthorized), errors.Is(err, store.ErrReadOnly):
+		return ExitDenied
+	case errors.Is(err, store.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, store.ErrConflict):
+		return ExitConflict
+	case errors.Is(err, store.ErrValidation), errors.Is(err, errNonInteractive):
+		return ExitInvalid
+	case errors.Is(err, store.ErrRateLimited), errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr), errors.As(err, &urlErr):
+		return ExitUnavailable
+	}
+	return ExitError
+}
+
+// printError reports a failed command on w: as a JSON object when the
+// command was run with --json, so scripts parsing its output can parse the
+// failure too, and otherwise as cobra would.
+func printError(w io.Writer, cmd *cobra.Command, err error) {
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		code := ExitCode(err)
+		out, _ := json.Marshal(map[string]any{"error": map[string]any{
+			"code":      errorKinds[code],
+			"message":   err.Error(),
+			"exit_code": code,
+		}})
+		fmt.Fprintln(w, string(out))
+		return
+	}
+	fmt.Fprintln(w, "Error:", err)
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/rogersnm/compass/internal/id"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// git runs git in the current directory and returns its trimmed output.
+// Git's own message is returned as the error when it fails.
+func git(args ...string) (string, error) {
+	return gitIn("", args...)
+}
+
+// gitIn runs git in dir, or the current directory if dir is "".
+func gitIn(dir string, args ...string) (string, error) {
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	out, err := c.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// currentBranch returns the checked-out branch, or "" outside a git repo
+// or on a detached HEAD.
+func currentBranch() string {
+	b, err := git("symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return b
+}
+
+// branchTask returns the first task ID in the current branch's name, as in
+// feature/AUTH-TABCDE-login, or "" if it names none. Git is asked at most
+// once per command; the root command resets it before each run.
+var branchTask = sync.OnceValue(findBranchTask)
+
+func findBranchTask() string {
+	for _, ref := range id.FindRefs(currentBranch()) {
+		if t, err := id.TypeOf(ref); err == nil && t == id.Task {
+			return ref
+		}
+	}
+	return ""
+}
+
+const defaultBranchTemplate = "{type}/{id}-{slug}"
+
+// branchName fills in the branch_template from config.yaml for t.
+func branchName(t *model.Task) string {
+	tmpl := cfg.BranchTemplate
+	if tmpl == "" {
+		tmpl = defaultBranchTemplate
+	}
+	return strings.NewReplacer(
+		"{type}", string(t.Type),
+		"{id}", t.ID,
+		"{project}", t.Project,
+		"{slug}", model.Slugify(t.Title),
+	).Replace(tmpl)
+}
+
+// runGH runs the GitHub CLI attached to the terminal. It is a variable so
+// tests can stub it.
+var runGH = func(args ...string) error {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return fmt.Errorf("the GitHub CLI (gh) is not on PATH; install it from https://cli.github.com")
+	}
+	c := exec.Command("gh", args...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return c.Run()
+}
+
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Connect tasks to git history",
+}
+
+var gitScanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Find commits that close tasks, and optionally close them",
+	Long: `Scan commit messages for "Closes AUTH-TXXXXX" references (also Fixes and
+Resolves, with or without a colon, several IDs separated by commas) and
+list the open tasks they close. With --close the tasks are closed, each in
+its own store, so a CI job after a merge finishes the loop:
+
+  compass git scan --range "$BEFORE..$AFTER" --close
+
+Without --range the last --max commits on HEAD are scanned.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		doClose, _ := cmd.Flags().GetBool("close")
+		rng, _ := cmd.Flags().GetString("range")
+		max, _ := cmd.Flags().GetInt("max")
+
+		logArgs := []string{"log", "--format=%h%x00%s%x00%B%x1e"}
+		if rng != "" {
+			logArgs = append(logArgs, rng)
+		} else {
+			logArgs = append(logArgs, fmt.Sprintf("--max-count=%d", max))
+		}
+		out, err := git(logArgs...)
+		if err != nil {
+			return err
+		}
+
+		found := 0
+		seen := map[string]bool{}
+		for _, c := range parseCommits(out) {
+			for _, taskID := range closingRefs(c.message) {
+				if seen[taskID] {
+					continue
+				}
+				seen[taskID] = true
+				s, err := storeForEntity(ctx, taskID)
+				if err != nil {
+					slog.Warn("skipping task", "id", taskID, "commit", c.hash, "err", err)
+					continue
+				}
+				t, _, err := s.GetTask(ctx, taskID)
+				if err != nil {
+					slog.Warn("skipping task", "id", taskID, "commit", c.hash, "err", err)
+					continue
+				}
+				if t.Status == model.StatusClosed {
+					continue
+				}
+				found++
+				if !doClose {
+					fmt.Printf("%s %s  closed by %s %s\n", t.ID, t.Title, c.hash, c.subject)
+					continue
+				}
+				status := model.StatusClosed
+				if _, err := s.UpdateTask(ctx, t.ID, store.TaskUpdate{Status: &status}); err != nil {
+					return err
+				}
+				fmt.Printf("Closed %s %s (%s %s)\n", t.ID, t.Title, c.hash, c.subject)
+			}
+		}
+		if found == 0 {
+			infof("No open tasks referenced.\n")
+		}
+		return nil
+	},
+}
+
+type commit struct {
+	hash, subject, message string
+}
+
+// parseCommits splits the output of the git log format used by git scan.
+func parseCommits(out string) []commit {
+	var commits []commit
+	for _, rec := range strings.Split(out, "\x1e") {
+		parts := strings.SplitN(strings.TrimSpace(rec), "\x00", 3)
+		if len(parts) == 3 {
+			commits = append(commits, commit{hash: parts[0], subject: parts[1], message: parts[2]})
+		}
+	}
+	return commits
+}
+
+// closingRe matches a closing keyword directly followed by a
+// comma-separated list of IDs; the keyword is case-insensitive, IDs aren't.
+// An ID's suffix is its type letter and a hash of any allowed length.
+var closingRe = regexp.MustCompile(fmt.Sprintf(
+	`\b(?i:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?[ \t]+((?:[A-Z0-9]{2,5}-[A-Z0-9]{%d,%d}\b(?:[ \t]*,[ \t]*)?)+)`,
+	id.DefaultHashLen+1, id.MaxHashLen+1))
+
+// closingRefs returns the task IDs a commit message says it closes.
+func closingRefs(message string) []string {
+	var refs []string
+	for _, m := range closingRe.FindAllStringSubmatch(message, -1) {
+		for _, ref := range id.FindRefs(m[1]) {
+			if t, err := id.TypeOf(ref); err == nil && t == id.Task {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+func init() {
+	gitScanCmd.Flags().Bool("close", false, "close the referenced tasks")
+	gitScanCmd.Flags().String("range", "", `revision range to scan, e.g. "origin/main..HEAD"`)
+	gitScanCmd.Flags().Int("max", 50, "commits to scan when no --range is given")
+	gitCmd.AddCommand(gitScanCmd)
+	rootCmd.AddCommand(gitCmd)
+}
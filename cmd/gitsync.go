@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/id"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// dataGitignore keeps everything but the entity files out of the data
+// dir's repository: config.yaml holds per-machine settings and tokens.
+const dataGitignore = `# Written by compass store git-init: only entity files are versioned.
+/*
+!/.gitignore
+!/projects/
+*.lock
+.*.tmp-*
+`
+
+var storeGitInitCmd = &cobra.Command{
+	Use:   "git-init",
+	Short: "Turn the local data directory into a git repository",
+	Long: `Make the local data directory a git repository so every change is
+committed as it's made ("close AUTH-TABCDE", "create AUTH-DFGHJK"), giving
+history and, with a remote, sync between machines without a cloud store.
+Only the projects/ directory is versioned; config.yaml and the caches
+stay per machine.
+
+With --remote the repository is connected to it. If the remote already has
+history, as on a second machine, it's merged in before pushing.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cfg.LocalEnabled {
+			return fmt.Errorf("the local store is not enabled; run: compass store add local")
+		}
+		if dataRepo() {
+			return fmt.Errorf("%s is already a git repository; use compass store git-sync", dataDir)
+		}
+		remote, _ := cmd.Flags().GetString("remote")
+
+		if err := os.WriteFile(filepath.Join(dataDir, ".gitignore"), []byte(dataGitignore), 0644); err != nil {
+			return err
+		}
+		if _, err := gitIn(dataDir, "init", "-q", "-b", "main"); err != nil {
+			return err
+		}
+		if err := configureMergeDriver(); err != nil {
+			return err
+		}
+		if _, err := gitIn(dataDir, "add", "-A"); err != nil {
+			return err
+		}
+		if _, err := gitIn(dataDir, "commit", "-q", "--allow-empty", "-m", "compass data"); err != nil {
+			return err
+		}
+		infof("Initialized git repository in %s\n", dataDir)
+		if remote == "" {
+			return nil
+		}
+
+		if _, err := gitIn(dataDir, "remote", "add", "origin", remote); err != nil {
+			return err
+		}
+		if _, err := gitIn(dataDir, "fetch", "-q", "origin"); err != nil {
+			return err
+		}
+		if _, err := gitIn(dataDir, "rev-parse", "--verify", "-q", "origin/main"); err == nil {
+			if _, err := gitIn(dataDir, "merge", "-q", "--allow-unrelated-histories", "-m", "merge "+remote, "origin/main"); err != nil {
+				return err
+			}
+		}
+		if _, err := gitIn(dataDir, "push", "-q", "-u", "origin", "main"); err != nil {
+			return err
+		}
+		infof("Synced with %s\n", remote)
+		return nil
+	},
+}
+
+var storeGitSyncCmd = &cobra.Command{
+	Use:   "git-sync",
+	Short: "Commit pending changes and pull and push the data directory's remote",
+	Long: `Commit any uncommitted entity changes, then, if the data directory's
+repository has a remote, pull (rebasing local commits) and push. Run it by
+hand or from cron to keep machines in step.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !dataRepo() {
+			return fmt.Errorf("%s is not a git repository; run: compass store git-init", dataDir)
+		}
+		if dryRun {
+			return planSync()
+		}
+		if err := commitData("sync"); err != nil {
+			return err
+		}
+		remotes, err := gitIn(dataDir, "remote")
+		if err != nil {
+			return err
+		}
+		if remotes == "" {
+			infof("No remote; local changes are committed.\n")
+			return nil
+		}
+		if err := configureMergeDriver(); err != nil {
+			return err
+		}
+		if _, err := gitIn(dataDir, "pull", "-q", "--rebase"); err != nil {
+			return fmt.Errorf("%v\nresolve the conflict in %s, then run: git -C %s rebase --continue", err, dataDir, dataDir)
+		}
+		if _, err := gitIn(dataDir, "push", "-q"); err != nil {
+			return err
+		}
+		infof("Synced %s\n", dataDir)
+		return nil
+	},
+}
+
+// planSync prints what git-sync would do: the commit it would make and
+// the remote it would pull from and push to.
+func planSync() error {
+	status, err := gitIn(dataDir, "status", "--porcelain", "-z", "--untracked-files=all", "--", "projects")
+	if err != nil {
+		return err
+	}
+	if status != "" {
+		fmt.Printf("Would commit %q\n", commitMessage("sync", changedEntities(status)))
+	}
+	remotes, err := gitIn(dataDir, "remote")
+	if err != nil {
+		return err
+	}
+	if remotes != "" {
+		fmt.Printf("Would pull --rebase from and push to %s\n", strings.Join(strings.Fields(remotes), ", "))
+	}
+	return nil
+}
+
+// dataRepo reports whether the data directory is a git repository of its
+// own, as set up by store git-init.
+func dataRepo() bool {
+	_, err := os.Stat(filepath.Join(dataDir, ".git"))
+	return err == nil
+}
+
+// autoCommit commits the entity changes a command made, named after the
+// command and the entities it touched. It runs after every command and
+// does nothing unless the command wrote to the local store and the data
+// directory is a git repository, nor while git is merging or rebasing in
+// it (merge-file runs then).
+func autoCommit(cmd *cobra.Command) {
+	if cmd == mergeFileCmd || dryRun || cfg == nil || !cfg.LocalEnabled || !localChanged() || !dataRepo() || dataRepoBusy() {
+		return
+	}
+	if err := commitData(cmd.Name()); err != nil {
+		slog.Warn("committing data changes", "err", err)
+	}
+}
+
+// localChanged reports whether the command wrote to the local store.
+func localChanged() bool {
+	s, err := reg.Get("local")
+	if err != nil {
+		return false
+	}
+	ls, ok := store.AsLocal(s)
+	return ok && ls.Changed()
+}
+
+// dataRepoBusy reports whether a merge or rebase is in progress in the
+// data directory's repository.
+func dataRepoBusy() bool {
+	for _, f := range []string{"MERGE_HEAD", "rebase-merge", "rebase-apply"} {
+		if _, err := os.Stat(filepath.Join(dataDir, ".git", f)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// configureMergeDriver registers compass merge-file as the merge driver
+// for entity files in the data directory's repository. It's kept in
+// .git/config and .git/info/attributes, like the rest of a machine's
+// setup, and repeated on every sync so repos made before it get it.
+func configureMergeDriver() error {
+	if _, err := gitIn(dataDir, "config", "merge.compass.name", "compass entity merge"); err != nil {
+		return err
+	}
+	if _, err := gitIn(dataDir, "config", "merge.compass.driver", "compass merge-file %O %A %B"); err != nil {
+		return err
+	}
+	path := filepath.Join(dataDir, ".git", "info", "attributes")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	const line = "*.md merge=compass"
+	if strings.Contains(string(data), line) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	return os.WriteFile(path, append(data, line+"\n"...), 0644)
+}
+
+// commitData commits everything changed under projects/, with a message
+// of verb and the changed entities' IDs.
+func commitData(verb string) error {
+	status, err := gitIn(dataDir, "status", "--porcelain", "-z", "--untracked-files=all", "--", "projects")
+	if err != nil || status == "" {
+		return err
+	}
+	if _, err := gitIn(dataDir, "add", "-A", "--", "projects"); err != nil {
+		return err
+	}
+	_, err = gitIn(dataDir, "commit", "-q", "-m", commitMessage(verb, changedEntities(status)))
+	return err
+}
+
+// changedEntities returns the IDs of the entity files in the output of
+// git status --porcelain -z, sorted.
+func changedEntities(status string) []string {
+	seen := map[string]bool{}
+	for _, rec := range strings.Split(status, "\x00") {
+		// Records are "XY path"; a rename's original path follows as a
+		// record of its own.
+		path := rec
+		if len(rec) > 3 && rec[2] == ' ' {
+			path = rec[3:]
+		}
+		var entityID string
+		if filepath.Base(path) == "project.md" {
+			entityID = filepath.Base(filepath.Dir(path))
+		} else {
+			entityID = strings.TrimSuffix(filepath.Base(path), ".md")
+		}
+		if _, _, _, err := id.Parse(entityID); err == nil && strings.HasSuffix(path, ".md") {
+			seen[entityID] = true
+		}
+	}
+	ids := make([]string, 0, len(seen))
+	for entityID := range seen {
+		ids = append(ids, entityID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// maxCommitIDs is how many entity IDs a commit message lists before it
+// just counts them.
+const maxCommitIDs = 3
+
+func commitMessage(verb string, ids []string) string {
+	switch {
+	case len(ids) == 0:
+		return verb
+	case len(ids) > maxCommitIDs:
+		return fmt.Sprintf("%s %d entities", verb, len(ids))
+	}
+	return verb + " " + strings.Join(ids, " ")
+}
+
+func init() {
+	storeGitInitCmd.Flags().String("remote", "", "remote repository URL to sync with")
+	storeCmd.AddCommand(storeGitInitCmd)
+	storeCmd.AddCommand(storeGitSyncCmd)
+}
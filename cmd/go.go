@@ -1,8 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/repofile"
+	"github.com/rogersnm/compass/internal/store"
 	"github.com/spf13/cobra"
 )
 
@@ -49,12 +59,198 @@ Run ` + "`compass task close <id>`" + ` to mark the task as complete. Then stop.
 var goCmd = &cobra.Command{
 	Use:   "go",
 	Short: "Print the lets-go skill prompt for Claude Code",
+	Long: `Print the lets-go skill prompt for Claude Code.
+
+The built-in prompt can be overridden. Without --skill, compass uses
+.compass/skill.md in the linked repo, then ~/.compass/skills/default.md.
+With --skill NAME, it uses .compass/skills/NAME.md in the linked repo, then
+~/.compass/skills/NAME.md.
+
+Templates may reference {{project}} (the resolved project key) and
+{{ready_task}} (the next ready task's ID and title).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Print(goSkillPrompt)
+		name, _ := cmd.Flags().GetString("skill")
+		tmpl, err := loadSkill(name)
+		if err != nil {
+			return err
+		}
+		fmt.Print(renderSkill(cmd, tmpl))
 		return nil
 	},
 }
 
+// loadSkill returns the skill prompt template for name ("" selects the
+// default), preferring repo-level overrides over user-level ones.
+func loadSkill(name string) (string, error) {
+	repoDir, _ := os.Getwd()
+	if _, dir, _ := repofile.Find(repoDir); dir != "" {
+		repoDir = dir
+	}
+
+	var candidates []string
+	if name == "" {
+		candidates = []string{
+			filepath.Join(repoDir, ".compass", "skill.md"),
+			filepath.Join(dataDir, "skills", "default.md"),
+		}
+	} else {
+		candidates = []string{
+			filepath.Join(repoDir, ".compass", "skills", name+".md"),
+			filepath.Join(dataDir, "skills", name+".md"),
+		}
+	}
+	for _, path := range candidates {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("reading skill %s: %w", path, err)
+		}
+	}
+	if name == "" || name == "default" {
+		return goSkillPrompt, nil
+	}
+	return "", fmt.Errorf("skill %q not found (looked in %s)", name, strings.Join(candidates, ", "))
+}
+
+// renderSkill substitutes template variables. Variables that cannot be
+// resolved (no linked project, no ready tasks) render as empty strings.
+func renderSkill(cmd *cobra.Command, tmpl string) string {
+	ctx := cmd.Context()
+	if !strings.Contains(tmpl, "{{") {
+		return tmpl
+	}
+	projectID, _ := resolveProject(cmd)
+
+	var readyTask string
+	if projectID != "" && strings.Contains(tmpl, "{{ready_task}}") {
+		if s, err := storeForProject(ctx, projectID); err == nil {
+			if ready, err := s.ReadyTasks(ctx, projectID); err == nil && len(ready) > 0 {
+				readyTask = fmt.Sprintf("%s %s", ready[0].ID, ready[0].Title)
+			}
+		}
+	}
+
+	return strings.NewReplacer(
+		"{{project}}", projectID,
+		"{{ready_task}}", readyTask,
+	).Replace(tmpl)
+}
+
+var goRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Work through ready tasks with an agent command until none are left",
+	Long: `Repeatedly claim the next ready task (see "task claim") and run the agent
+command through "sh -c" with the task's title and body on stdin. The task ID
+and project are exported as COMPASS_TASK_ID and COMPASS_PROJECT.
+
+If the agent exits successfully the task is closed and the loop continues.
+If it fails, the task is returned to open and the loop stops. With --dry-run
+only the first claim is shown; the agent isn't run.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		agentCmd, _ := cmd.Flags().GetString("agent-cmd")
+		if strings.TrimSpace(agentCmd) == "" {
+			return fmt.Errorf("--agent-cmd is required")
+		}
+		maxTasks, _ := cmd.Flags().GetInt("max")
+		override, _ := cmd.Flags().GetBool("override")
+
+		var logOut io.Writer = os.Stderr
+		if logFile, _ := cmd.Flags().GetString("log-file"); logFile != "" {
+			f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return fmt.Errorf("opening log file: %w", err)
+			}
+			defer f.Close()
+			logOut = io.MultiWriter(os.Stderr, f)
+		}
+		logger := log.New(logOut, "compass go: ", log.LstdFlags)
+
+		done := 0
+		for maxTasks <= 0 || done < maxTasks {
+			if err := checkProjectWIPLimit(ctx, s, projectID, model.StatusInProgress, override); err != nil {
+				return err
+			}
+			t, err := s.ClaimTask(ctx, projectID)
+			if err != nil {
+				return err
+			}
+			if t == nil {
+				logger.Printf("no ready tasks in %s", projectID)
+				break
+			}
+			if dryRun {
+				// Nothing is claimed, so the loop would get the same task
+				// forever, and the agent would really run.
+				fmt.Printf("Would run %s on %s\n", agentCmd, t.ID)
+				return nil
+			}
+			logger.Printf("started %s: %s", t.ID, t.Title)
+
+			if err := runAgentOnTask(ctx, s, t, agentCmd, logger); err != nil {
+				return err
+			}
+			done++
+		}
+		logger.Printf("completed %d task(s)", done)
+		return nil
+	},
+}
+
+// runAgentOnTask runs the agent command against a claimed task and closes the
+// task if the agent succeeds. On failure the task is reopened.
+func runAgentOnTask(ctx context.Context, s store.Store, t *model.Task, agentCmd string, logger *log.Logger) error {
+	_, body, err := s.GetTask(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+
+	c := exec.Command("sh", "-c", agentCmd)
+	c.Stdin = strings.NewReader(fmt.Sprintf("# %s (%s)\n\n%s", t.Title, t.ID, body))
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(), "COMPASS_TASK_ID="+t.ID, "COMPASS_PROJECT="+t.Project)
+
+	if runErr := c.Run(); runErr != nil {
+		logger.Printf("agent failed on %s: %v", t.ID, runErr)
+		open := model.StatusOpen
+		if _, err := s.UpdateTask(ctx, t.ID, store.TaskUpdate{Status: &open}); err != nil {
+			logger.Printf("reopening %s: %v", t.ID, err)
+		}
+		return fmt.Errorf("agent command failed on %s: %w", t.ID, runErr)
+	}
+
+	closed := model.StatusClosed
+	if _, err := s.UpdateTask(ctx, t.ID, store.TaskUpdate{Status: &closed}); err != nil {
+		return fmt.Errorf("closing %s: %w", t.ID, err)
+	}
+	logger.Printf("closed %s", t.ID)
+	return nil
+}
+
 func init() {
+	goCmd.Flags().String("skill", "", "named skill prompt to print (from .compass/skills/ or ~/.compass/skills/)")
+	goCmd.Flags().StringP("project", "P", "", "project ID for template variables")
+
+	goRunCmd.Flags().StringP("project", "P", "", "project ID")
+	goRunCmd.Flags().String("agent-cmd", "", "command to run for each task (task body on stdin), e.g. \"claude -p\"")
+	goRunCmd.Flags().Int("max", 0, "stop after this many tasks (0 = until no ready tasks remain)")
+	goRunCmd.Flags().String("log-file", "", "also append the run log to this file")
+	goRunCmd.Flags().Bool("override", false, "claim even if it exceeds the project's WIP limit")
+
+	goCmd.AddCommand(goRunCmd)
 	rootCmd.AddCommand(goCmd)
 }
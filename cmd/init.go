@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/repofile"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// newProjectChoice is the project picker's "create one" option; project
+// keys are uppercase, so it can't collide with one.
+const newProjectChoice = "new"
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up the current repo: create or pick a project and link it",
+	Long: `Set up the current repo in one step: create a project (or pick an existing
+one), link the directory to it with .compass-project, and optionally add
+.compass/ to .gitignore and create a starter epic and documents.
+
+Without flags, init asks for each choice. With --project or --name nothing
+is asked, and --gitignore and --starter opt in to the extras.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if linked, _ := repofile.Read(cwd); linked != "" {
+			return fmt.Errorf("%s is already linked to %s (change it with: compass project link)", cwd, linked)
+		}
+
+		projectID, _ := cmd.Flags().GetString("project")
+		name, _ := cmd.Flags().GetString("name")
+		key, _ := cmd.Flags().GetString("key")
+		ignore, _ := cmd.Flags().GetBool("gitignore")
+		starter, _ := cmd.Flags().GetBool("starter")
+
+		if projectID == "" && name == "" {
+			if err := canPrompt("pass --project to link an existing project, or --name (and --key) to create one"); err != nil {
+				return err
+			}
+			if projectID, err = pickInitProject(ctx); err != nil {
+				return err
+			}
+			if projectID == newProjectChoice {
+				projectID = ""
+				name = filepath.Base(cwd)
+				if err := huh.NewForm(huh.NewGroup(
+					huh.NewInput().Title("Project name").Value(&name),
+					huh.NewInput().Title("Key (2-5 uppercase letters or digits, blank to generate)").Value(&key),
+				)).Run(); err != nil {
+					return fmt.Errorf("cancelled")
+				}
+			}
+			var fields []huh.Field
+			if !gitignoreHas(cwd, ".compass/") {
+				fields = append(fields, huh.NewConfirm().Title("Add .compass/ (local task copies) to .gitignore?").Value(&ignore))
+			}
+			if projectID == "" {
+				fields = append(fields, huh.NewConfirm().Title("Create a starter epic and documents?").Value(&starter))
+			}
+			if len(fields) > 0 {
+				if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+					return fmt.Errorf("cancelled")
+				}
+			}
+		}
+
+		var s store.Store
+		if projectID == "" {
+			var storeName string
+			if s, storeName, err = storeForNewProject(cmd); err != nil {
+				return err
+			}
+			p, err := s.CreateProject(ctx, strings.TrimSpace(name), strings.TrimSpace(key), "")
+			if err != nil {
+				return err
+			}
+			reg.CacheProject(p.ID, storeName)
+			projectID = p.ID
+			infof("Created project %s (%s)\n", p.Name, p.ID)
+		} else {
+			if s, err = storeForProject(ctx, projectID); err != nil {
+				return err
+			}
+			if _, _, err := s.GetProject(ctx, projectID); err != nil {
+				return fmt.Errorf("project %s not found", projectID)
+			}
+		}
+
+		if err := repofile.Write(cwd, projectID); err != nil {
+			return err
+		}
+		infof("Linked %s to project %s\n", repofile.FileName, projectID)
+
+		if ignore {
+			added, err := addGitignore(cwd, ".compass/")
+			if err != nil {
+				return err
+			}
+			if added {
+				infof("Added .compass/ to .gitignore\n")
+			}
+		}
+		if starter {
+			if err := createStarter(ctx, s, projectID); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// pickInitProject offers the existing projects and a "create one" option.
+func pickInitProject(ctx context.Context) (string, error) {
+	byStore, errs, err := store.FanOut(ctx, reg, "", fanOutTimeout, func(ctx context.Context, s store.Store) ([]model.Project, error) {
+		return s.ListProjects(ctx)
+	})
+	if err != nil {
+		return "", err
+	}
+	warnUnreachable(errs)
+	opts := []huh.Option[string]{huh.NewOption("Create a new project", newProjectChoice)}
+	for _, name := range sortedKeys(byStore) {
+		for _, p := range byStore[name] {
+			opts = append(opts, huh.NewOption(fmt.Sprintf("%s  %s  (%s)", p.ID, p.Name, name), p.ID))
+		}
+	}
+	choice := newProjectChoice
+	if len(opts) == 1 {
+		return choice, nil
+	}
+	if err := huh.NewSelect[string]().
+		Title("Project for this repo").
+		Options(opts...).
+		Value(&choice).
+		Run(); err != nil {
+		return "", fmt.Errorf("selection cancelled")
+	}
+	return choice, nil
+}
+
+func gitignoreHas(dir, entry string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// addGitignore appends entry to dir/.gitignore, creating it if needed,
+// and reports false if the entry was already there.
+func addGitignore(dir, entry string) (bool, error) {
+	if gitignoreHas(dir, entry) {
+		return false, nil
+	}
+	path := filepath.Join(dir, ".gitignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+	data = append(data, entry+"\n"...)
+	return true, os.WriteFile(path, data, 0644)
+}
+
+// starterDocs are the documents init --starter creates.
+var starterDocs = []struct {
+	Title string
+	Kind  model.DocKind
+	Body  string
+}{
+	{"Overview", model.DocDesign, "## Goals\n\n## Non-goals\n\n## Architecture\n"},
+	{"Runbook", model.DocRunbook, "## Deploying\n\n## Rolling back\n\n## Alerts\n"},
+}
+
+func createStarter(ctx context.Context, s store.Store, projectID string) error {
+	epic, err := s.CreateTask(ctx, "Getting started", projectID, store.TaskCreateOpts{
+		Type: model.TypeEpic,
+		Body: "Tasks for the first working version.",
+	})
+	if err != nil {
+		return err
+	}
+	infof("Created epic %s\n", epic.ID)
+	for _, d := range starterDocs {
+		doc, err := s.CreateDocument(ctx, d.Title, projectID, store.DocumentCreateOpts{Kind: d.Kind, Body: d.Body})
+		if err != nil {
+			return err
+		}
+		infof("Created document %s (%s)\n", doc.ID, d.Title)
+	}
+	return nil
+}
+
+func init() {
+	initCmd.Flags().StringP("project", "P", "", "link to this existing project")
+	initCmd.Flags().String("name", "", "create a project with this name")
+	initCmd.Flags().StringP("key", "k", "", "key for the new project (2-5 uppercase alphanumeric chars)")
+	initCmd.Flags().String("store", "", "store to create the project on (\"local\" or hostname)")
+	initCmd.Flags().Bool("gitignore", false, "add .compass/ to .gitignore")
+	initCmd.Flags().Bool("starter", false, "create a starter epic and documents")
+	rootCmd.AddCommand(initCmd)
+}
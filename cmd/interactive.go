@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// nonInteractive is --non-interactive. Prompts are also off whenever stdin
+// isn't a terminal, as in CI and pipes, where they would wait for input
+// that never comes.
+var nonInteractive bool
+
+// errNonInteractive marks a command that needed to prompt while prompts
+// were off.
+var errNonInteractive = errors.New("input required but prompts are off (--non-interactive, or stdin isn't a terminal)")
+
+// stdinTerminal reports whether stdin is a terminal. It is a variable so
+// tests can pretend it is.
+var stdinTerminal = func() bool { return term.IsTerminal(int(os.Stdin.Fd())) }
+
+// canPrompt returns an error saying what to do instead, hint, when prompts
+// are off, so commands fail at once rather than hang or report a
+// cancellation.
+func canPrompt(hint string) error {
+	if nonInteractive || !stdinTerminal() {
+		return fmt.Errorf("%w: %s", errNonInteractive, hint)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// titleArgs requires the title argument unless --json, --edit or --file is
+// set, in which case the title may come from the JSON object, the editor or
+// the file instead.
+func titleArgs(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	edit, _ := cmd.Flags().GetBool("edit")
+	if asJSON || edit || cmd.Flags().Changed("file") {
+		return cobra.MaximumNArgs(1)(cmd, args)
+	}
+	return cobra.ExactArgs(1)(cmd, args)
+}
+
+// readJSONInput decodes a single JSON object from stdin into a T. Unknown
+// fields are rejected so typos don't silently drop data.
+func readJSONInput[T any]() (T, error) {
+	var v T
+	data := readStdin()
+	if strings.TrimSpace(data) == "" {
+		return v, fmt.Errorf("--json requires a JSON object on stdin")
+	}
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&v); err != nil {
+		return v, fmt.Errorf("parsing JSON from stdin: %w", err)
+	}
+	return v, nil
+}
+
+// optionalField decodes a field that distinguishes "absent" (nil), "null"
+// (clear), and a value, matching the double-pointer fields of
+// store.TaskUpdate.
+func optionalField[T any](name string, raw json.RawMessage) (**T, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var v *T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, fmt.Errorf("parsing %q: %w", name, err)
+	}
+	return &v, nil
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
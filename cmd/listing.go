@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// addListFlags registers the paging, sorting and column flags shared by
+// list commands.
+func addListFlags(cmd *cobra.Command, sortFields, columns, defaultColumns []string) {
+	cmd.Flags().Int("limit", 0, "show at most this many rows (0 = all)")
+	cmd.Flags().Int("offset", 0, "skip this many rows")
+	cmd.Flags().String("cursor", "", "continue from the cursor printed by a previous page")
+	cmd.Flags().String("sort", "", "sort by field, prefix with - to reverse ("+strings.Join(sortFields, ", ")+")")
+	cmd.Flags().String("columns", strings.Join(defaultColumns, ","), "comma-separated columns ("+strings.Join(columns, ", ")+")")
+	cmd.Flags().StringP("output", "o", "table", "output format: table, csv or tsv")
+}
+
+// listOutput reads --output, reporting whether rows should be written as
+// CSV or TSV records instead of a table.
+func listOutput(cmd *cobra.Command) (records bool, err error) {
+	switch out, _ := cmd.Flags().GetString("output"); out {
+	case "table":
+		return false, nil
+	case "csv", "tsv":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid --output %q (valid: table, csv, tsv)", out)
+	}
+}
+
+// printRecords writes rows as CSV, or as TSV for --output tsv, quoting
+// fields that need it.
+func printRecords(cmd *cobra.Command, rows [][]string) error {
+	w := csv.NewWriter(os.Stdout)
+	if out, _ := cmd.Flags().GetString("output"); out == "tsv" {
+		w.Comma = '\t'
+	}
+	w.WriteAll(rows)
+	return w.Error()
+}
+
+// listPage reads the paging flags. paged is false when none were set, so
+// callers can keep their default display order.
+func listPage(cmd *cobra.Command) (page store.PageOpts, paged bool) {
+	page.Limit, _ = cmd.Flags().GetInt("limit")
+	page.Offset, _ = cmd.Flags().GetInt("offset")
+	page.Cursor, _ = cmd.Flags().GetString("cursor")
+	page.Sort, _ = cmd.Flags().GetString("sort")
+	return page, page != store.PageOpts{}
+}
+
+func listColumns(cmd *cobra.Command) []string {
+	cols, _ := cmd.Flags().GetString("columns")
+	return strings.Split(cols, ",")
+}
+
+// printNextPage tells the user how to fetch the next page, on stderr so
+// piped output stays clean.
+func printNextPage(cursor string) {
+	if cursor != "" {
+		fmt.Fprintf(os.Stderr, "More results: repeat with --cursor %s\n", cursor)
+	}
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rogersnm/compass/internal/config"
+)
+
+// logLevel is the --log-level flag.
+var logLevel string
+
+// logFile is the log kept in the data directory with log_file: true in
+// config.yaml, rotated like debug.log.
+const (
+	logFile    = "compass.log"
+	logFileMax = 1 << 20
+)
+
+// setupLogging points slog's default logger at stderr, and at compass.log
+// when c turns it on. The level comes from --log-level, then
+// COMPASS_LOG_LEVEL, then log_level in c, and defaults to warn. c may be
+// nil before config.yaml is loaded.
+func setupLogging(c *config.Config) error {
+	name := logLevel
+	if name == "" {
+		name = os.Getenv("COMPASS_LOG_LEVEL")
+	}
+	if name == "" && c != nil {
+		name = c.LogLevel
+	}
+	var level slog.Level
+	if name != "" {
+		if err := level.UnmarshalText([]byte(name)); err != nil {
+			return fmt.Errorf("invalid log level %q (want debug, info, warn or error)", name)
+		}
+	} else {
+		level = slog.LevelWarn
+	}
+
+	var h slog.Handler = &cliHandler{w: os.Stderr, level: level, mu: new(sync.Mutex)}
+	if c != nil && c.LogFile {
+		file := slog.NewTextHandler(&rotatingLog{path: filepath.Join(dataDir, logFile), max: logFileMax}, &slog.HandlerOptions{Level: level})
+		h = teeHandler{h, file}
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+// cliHandler writes records for people reading a terminal: a level prefix
+// such as "Warning:", the message, then attributes as key=value.
+type cliHandler struct {
+	w      io.Writer
+	level  slog.Level
+	attrs  string // preformatted by WithAttrs
+	prefix string // group names, dotted
+	mu     *sync.Mutex
+}
+
+func (h *cliHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level
+}
+
+func (h *cliHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	case r.Level < slog.LevelInfo:
+		b.WriteString("Debug: ")
+	}
+	b.WriteString(r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.prefix, a)
+		return true
+	})
+	b.WriteByte('\n')
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *cliHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, a := range attrs {
+		writeAttr(&b, h.prefix, a)
+	}
+	h2 := *h
+	h2.attrs += b.String()
+	return &h2
+}
+
+func (h *cliHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.prefix += name + "."
+	return &h2
+}
+
+func writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, g := range a.Value.Group() {
+			writeAttr(b, prefix+a.Key+".", g)
+		}
+		return
+	}
+	v := a.Value.String()
+	if v == "" || strings.ContainsAny(v, " \"=\t\n") {
+		v = strconv.Quote(v)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, a.Key, v)
+}
+
+// teeHandler sends each record to every handler that wants it.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return slices.ContainsFunc(t, func(h slog.Handler) bool { return h.Enabled(ctx, l) })
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range t {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(teeHandler, len(t))
+	for i, h := range t {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	out := make(teeHandler, len(t))
+	for i, h := range t {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
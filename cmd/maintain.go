@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var maintainCmd = &cobra.Command{
+	Use:   "maintain",
+	Short: "Apply project upkeep policies such as priority escalation",
+	Long: `Apply the escalation policies in config.yaml. An open task that has gone
+after_days without an update moves up one priority (unprioritized tasks
+become P3), never past max_priority (default P1):
+
+  escalation:
+    AUTH:
+      after_days: 14
+
+Every change is appended to audit.log in the data directory. Run it from
+cron or a launchd agent; --dry-run reports what would change.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projects := sortedKeys(cfg.Escalation)
+		if p, _ := cmd.Flags().GetString("project"); p != "" {
+			if _, ok := cfg.Escalation[p]; !ok {
+				return fmt.Errorf("project %s has no escalation policy in config.yaml", p)
+			}
+			projects = []string{p}
+		}
+		if len(projects) == 0 {
+			infof("No escalation policies configured.\n")
+			return nil
+		}
+
+		now := time.Now().UTC()
+		changed := 0
+		for _, key := range projects {
+			policy := cfg.Escalation[key]
+			if policy.AfterDays <= 0 {
+				return fmt.Errorf("escalation policy for %s: after_days must be positive", key)
+			}
+			s, err := storeForProject(ctx, key)
+			if err != nil {
+				slog.Warn("skipping project", "project", key, "err", err)
+				continue
+			}
+			tasks, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: key, Type: model.TypeTask, Status: model.StatusOpen})
+			if err != nil {
+				slog.Warn("skipping project", "project", key, "err", err)
+				continue
+			}
+			for _, e := range escalations(tasks, policy, now) {
+				change := fmt.Sprintf("priority %s -> %s", priorityOrNone(e.task.Priority), model.FormatPriority(&e.to))
+				verb := "Would escalate"
+				if !dryRun {
+					verb = "Escalated"
+					pp := &e.to
+					if _, err := s.UpdateTask(ctx, e.task.ID, store.TaskUpdate{Priority: &pp}); err != nil {
+						return err
+					}
+					err := appendAudit(auditEntry{
+						At:     now,
+						By:     store.CurrentUser(),
+						Entity: e.task.ID,
+						Change: change,
+						Reason: fmt.Sprintf("escalation policy: untouched for %d days", e.idleDays),
+					})
+					if err != nil {
+						return err
+					}
+				}
+				changed++
+				fmt.Printf("%s %s %s: %s (untouched %dd)\n", verb, e.task.ID, e.task.Title, change, e.idleDays)
+			}
+		}
+		if changed == 0 {
+			infof("Nothing to escalate.\n")
+		}
+		return nil
+	},
+}
+
+var maintainPruneRefsCmd = &cobra.Command{
+	Use:   "prune-refs",
+	Short: "Remove depends_on and epic references to tasks that no longer exist",
+	Long: `Scan every task in the local store for depends_on and epic references to
+IDs that don't exist, as left behind when a task file is deleted by hand,
+and remove them. A missing dependency blocks a task for good, so pruning
+it lets the task become ready. Each change is appended to audit.log;
+--dry-run lists the references without removing them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if !cfg.LocalEnabled {
+			return fmt.Errorf("the local store is not enabled; run: compass store add local")
+		}
+		s, err := reg.Get("local")
+		if err != nil {
+			return err
+		}
+		tasks, err := s.ListTasks(ctx, store.TaskFilter{})
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		pruned := 0
+		for _, r := range danglingRefs(tasks) {
+			var upd store.TaskUpdate
+			var gone []string
+			if len(r.deps) > 0 {
+				keep := slices.DeleteFunc(slices.Clone(r.task.DependsOn), func(dep string) bool { return slices.Contains(r.deps, dep) })
+				upd.DependsOn = &keep
+				gone = append(gone, "depends_on "+strings.Join(r.deps, ", "))
+			}
+			if r.epic {
+				none := ""
+				upd.Epic = &none
+				gone = append(gone, "epic "+r.task.Epic)
+			}
+			change := "removed missing " + strings.Join(gone, " and ")
+			verb := "Would prune"
+			if !dryRun {
+				verb = "Pruned"
+				if _, err := s.UpdateTask(ctx, r.task.ID, upd); err != nil {
+					return err
+				}
+				err := appendAudit(auditEntry{
+					At:     now,
+					By:     store.CurrentUser(),
+					Entity: r.task.ID,
+					Change: change,
+					Reason: "prune-refs: referenced tasks do not exist",
+				})
+				if err != nil {
+					return err
+				}
+			}
+			pruned++
+			fmt.Printf("%s %s %s: %s\n", verb, r.task.ID, r.task.Title, change)
+		}
+		if pruned == 0 {
+			infof("No dangling references.\n")
+		}
+		return nil
+	},
+}
+
+// danglingRef is a task's references to tasks that don't exist.
+type danglingRef struct {
+	task model.Task
+	deps []string
+	epic bool
+}
+
+// danglingRefs returns the tasks whose depends_on or epic name a task
+// missing from tasks, in task order.
+func danglingRefs(tasks []model.Task) []danglingRef {
+	exists := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		exists[t.ID] = true
+	}
+	var out []danglingRef
+	for _, t := range tasks {
+		r := danglingRef{task: t, epic: t.Epic != "" && !exists[t.Epic]}
+		for _, dep := range t.DependsOn {
+			if !exists[dep] {
+				r.deps = append(r.deps, dep)
+			}
+		}
+		if len(r.deps) > 0 || r.epic {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+type escalation struct {
+	task     model.Task
+	to       int
+	idleDays int
+}
+
+// escalations returns the tasks policy raises at now and their new
+// priorities. Updating a task resets its clock, so a neglected task climbs
+// one step per after_days.
+func escalations(tasks []model.Task, policy config.EscalationPolicy, now time.Time) []escalation {
+	var out []escalation
+	for _, t := range tasks {
+		idle := int(now.Sub(t.UpdatedAt).Hours() / 24)
+		if idle < policy.AfterDays {
+			continue
+		}
+		to := 3
+		if t.Priority != nil {
+			to = *t.Priority - 1
+		}
+		if to < policy.Ceiling() {
+			continue
+		}
+		out = append(out, escalation{task: t, to: to, idleDays: idle})
+	}
+	return out
+}
+
+func priorityOrNone(p *int) string {
+	if p == nil {
+		return "none"
+	}
+	return model.FormatPriority(p)
+}
+
+// auditEntry is one line of audit.log, recording changes compass makes on
+// its own rather than at a user's request.
+type auditEntry struct {
+	At     time.Time `json:"at"`
+	By     string    `json:"by"`
+	Entity string    `json:"entity"`
+	Change string    `json:"change"`
+	Reason string    `json:"reason"`
+}
+
+func appendAudit(e auditEntry) error {
+	f, err := os.OpenFile(filepath.Join(dataDir, "audit.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(e)
+}
+
+func init() {
+	maintainCmd.Flags().StringP("project", "P", "", "only this project")
+	maintainCmd.AddCommand(maintainPruneRefsCmd)
+	rootCmd.AddCommand(maintainCmd)
+}
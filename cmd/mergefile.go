@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/spf13/cobra"
+)
+
+var mergeFileCmd = &cobra.Command{
+	Use:   "merge-file <base> <ours> <theirs>",
+	Short: "Three-way merge an entity file field by field (a git merge driver)",
+	Long: `Merge two edits of an entity file from their common base and write the
+result over <ours>, as git expects of a merge driver. Frontmatter is merged
+field by field, so it never gets conflict markers: a field one side changed
+takes that change, depends_on and release items are merged as sets, and
+anything else both sides changed comes from the side updated last, so the
+latest status wins. Bodies are merged line by line; overlapping edits get
+conflict markers and a non-zero exit.
+
+compass store git-init registers it for the data directory. To register it
+in another repository of compass files:
+
+  git config merge.compass.name "compass entity merge"
+  git config merge.compass.driver "compass merge-file %O %A %B"
+  echo "*.md merge=compass" >> .gitattributes`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var files [3][]byte
+		for i, path := range args {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files[i] = data
+		}
+		oursPath := args[1]
+
+		var fronts [3][]byte
+		var bodies [3]string
+		for i, data := range files {
+			var err error
+			if len(data) == 0 && i == 0 {
+				continue // both sides added the file
+			}
+			if fronts[i], bodies[i], err = markdown.Split(data); err != nil {
+				// Not an entity file: merge it as plain text.
+				out, conflict, err := mergeText(files[0], files[1], files[2])
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(oursPath, out, 0644); err != nil {
+					return err
+				}
+				if conflict {
+					return fmt.Errorf("conflicts in %s", oursPath)
+				}
+				return nil
+			}
+		}
+
+		meta, err := markdown.MergeFrontmatter(fronts[0], fronts[1], fronts[2])
+		if err != nil {
+			return err
+		}
+		body, conflict, err := mergeBody(bodies[0], bodies[1], bodies[2])
+		if err != nil {
+			return err
+		}
+		out, err := markdown.Marshal(meta, body)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(oursPath, out, 0644); err != nil {
+			return err
+		}
+		if conflict {
+			return fmt.Errorf("conflicts in the body of %s", oursPath)
+		}
+		return nil
+	},
+}
+
+// mergeBody merges entity bodies, reporting whether conflict markers were
+// needed.
+func mergeBody(base, ours, theirs string) (string, bool, error) {
+	switch {
+	case ours == theirs, theirs == base:
+		return ours, false, nil
+	case ours == base:
+		return theirs, false, nil
+	}
+	out, conflict, err := mergeText([]byte(base+"\n"), []byte(ours+"\n"), []byte(theirs+"\n"))
+	return strings.TrimSpace(string(out)), conflict, err
+}
+
+// mergeText runs git merge-file on copies of the three versions and
+// returns the result, which has conflict markers when conflict is true.
+func mergeText(base, ours, theirs []byte) (out []byte, conflict bool, err error) {
+	dir, err := os.MkdirTemp("", "compass-merge-")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.RemoveAll(dir)
+	var paths []string
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{"ours", ours}, {"base", base}, {"theirs", theirs}} {
+		p := filepath.Join(dir, f.name)
+		if err := os.WriteFile(p, f.data, 0644); err != nil {
+			return nil, false, err
+		}
+		paths = append(paths, p)
+	}
+	out, err = exec.Command("git", append([]string{"merge-file", "-p", "-L", "ours", "-L", "base", "-L", "theirs"}, paths...)...).Output()
+	// git merge-file exits with the number of conflicts; negative (255 and
+	// up) means it failed.
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && ee.ExitCode() > 0 && ee.ExitCode() < 128 {
+		return out, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("git merge-file: %w", err)
+	}
+	return out, false, nil
+}
+
+func init() {
+	rootCmd.AddCommand(mergeFileCmd)
+}
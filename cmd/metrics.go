@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// serveMetrics exposes backlog gauges in the Prometheus text format.
+func serveMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	projects := r.URL.Query()["project"]
+	if len(projects) == 0 {
+		projects = cachedProjects()
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(ctx, w, projects)
+}
+
+// projectMetrics are one project's backlog counts.
+type projectMetrics struct {
+	byStatus map[model.Status]int
+	blocked  int // unclosed tasks blocked manually, by dependencies or by a wait
+	ready    int
+}
+
+func collectMetrics(ctx context.Context, projectID string) (*projectMetrics, error) {
+	s, err := storeForProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.AllTaskMap(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	ready, err := s.ReadyTasks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	m := &projectMetrics{byStatus: map[model.Status]int{}, ready: len(ready)}
+	for _, t := range all {
+		if t.Type != model.TypeTask {
+			continue
+		}
+		m.byStatus[t.Status]++
+		if t.Status == model.StatusBlocked || (t.Status != model.StatusClosed && t.IsBlocked(all, depPolicy())) {
+			m.blocked++
+		}
+	}
+	return m, nil
+}
+
+// writeMetrics writes gauges for each project. A project whose store
+// can't be read gets compass_project_up 0 and no other series, so one
+// unreachable store doesn't fail the scrape.
+func writeMetrics(ctx context.Context, w io.Writer, projects []string) {
+	collected := map[string]*projectMetrics{}
+	for _, p := range projects {
+		m, err := collectMetrics(ctx, p)
+		if err != nil {
+			slog.Warn("skipping metrics", "project", p, "err", err)
+			continue
+		}
+		collected[p] = m
+	}
+
+	gauge := func(name, help string, each func(project string, m *projectMetrics)) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, p := range projects {
+			if m := collected[p]; m != nil {
+				each(p, m)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "# HELP compass_project_up Whether the project's store could be read.\n# TYPE compass_project_up gauge\n")
+	for _, p := range projects {
+		up := 0
+		if collected[p] != nil {
+			up = 1
+		}
+		fmt.Fprintf(w, "compass_project_up{project=%q} %d\n", p, up)
+	}
+	gauge("compass_tasks", "Tasks by status.", func(p string, m *projectMetrics) {
+		for _, s := range model.Statuses {
+			fmt.Fprintf(w, "compass_tasks{project=%q,status=%q} %d\n", p, s, m.byStatus[s])
+		}
+	})
+	gauge("compass_tasks_blocked", "Unclosed tasks blocked by dependencies, an external wait or a manual block.", func(p string, m *projectMetrics) {
+		fmt.Fprintf(w, "compass_tasks_blocked{project=%q} %d\n", p, m.blocked)
+	})
+	gauge("compass_tasks_ready", "Tasks in the ready queue: open and unblocked.", func(p string, m *projectMetrics) {
+		fmt.Fprintf(w, "compass_tasks_ready{project=%q} %d\n", p, m.ready)
+	})
+}
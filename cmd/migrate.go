@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rogersnm/compass/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade on-disk data to the current format",
+}
+
+var migrateConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Upgrade config.yaml to the current version",
+	Long: `Upgrade config.yaml to the current version and report what changed.
+
+Older configs are also upgraded automatically on first use; this command
+lets you preview the changes with --dry-run. A backup of the original is
+written to config.yaml.bak before saving.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, changes, err := config.LoadAndUpgrade(dataDir)
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		if len(changes) == 0 {
+			fmt.Printf("config.yaml is up to date (version %d)\n", config.CurrentVersion)
+			return nil
+		}
+
+		if dryRun {
+			fmt.Println("Would upgrade config.yaml:")
+			printChanges(os.Stdout, changes)
+			return nil
+		}
+
+		path := filepath.Join(dataDir, "config.yaml")
+		orig, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading config: %w", err)
+		}
+		if err := os.WriteFile(path+".bak", orig, 0600); err != nil {
+			return fmt.Errorf("writing backup: %w", err)
+		}
+		if err := config.Save(dataDir, c); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		cfg = c
+		fmt.Println("Upgraded config.yaml:")
+		printChanges(os.Stdout, changes)
+		fmt.Printf("Backup: %s.bak\n", path)
+		return nil
+	},
+}
+
+func printChanges(w io.Writer, changes []string) {
+	for _, c := range changes {
+		fmt.Fprintf(w, "  - %s\n", c)
+	}
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateConfigCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var myCmd = &cobra.Command{
+	Use:   "my",
+	Short: "Personal views across every project",
+}
+
+// myWorkColumns adds the project to the default task columns, as the list
+// spans projects.
+var myWorkColumns = []string{"id", "title", "project", "priority", "status", "assignee"}
+
+var myWorkCmd = &cobra.Command{
+	Use:   "work",
+	Short: "List tasks you created, are assigned or watch, across all stores",
+	Long: `List the tasks you created, are assigned to or watch (see task watch), in
+every project on every store. Closed tasks are left out unless --all is
+given. "You" is the current user, or the actor set by --as.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		me := store.CurrentUser()
+		all, _ := cmd.Flags().GetBool("all")
+		only, _ := cmd.Flags().GetString("only-store")
+		byStore, errs, err := store.FanOut(ctx, reg, only, fanOutTimeout, func(ctx context.Context, s store.Store) ([]model.Task, error) {
+			return s.ListTasks(ctx, store.TaskFilter{})
+		})
+		if err != nil {
+			return err
+		}
+		warnUnreachable(errs)
+
+		// Every task goes in allTasks so blocked tasks show as such; only
+		// tasks of projects cached against the store that returned them are
+		// listed, as with project list.
+		allTasks := map[string]*model.Task{}
+		var mine []model.Task
+		for _, name := range sortedKeys(byStore) {
+			for _, t := range byStore[name] {
+				allTasks[t.ID] = &t
+				if _, ok := cfg.Projects[t.Project]; ok && !config.CachedOn(cfg.Projects, t.Project, name) {
+					continue
+				}
+				if t.Involves(me) && (all || t.Status != model.StatusClosed) {
+					mine = append(mine, t)
+				}
+			}
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if mine == nil {
+				mine = []model.Task{}
+			}
+			markdown.SortTasks(mine, allTasks, depPolicy())
+			return printJSON(mine)
+		}
+		if len(mine) == 0 {
+			fmt.Printf("Nothing for %s.\n", me)
+			return nil
+		}
+		markdown.SortTasks(mine, allTasks, depPolicy())
+		out, err := markdown.RenderTaskColumns(mine, allTasks, depPolicy(), myWorkColumns)
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+		return nil
+	},
+}
+
+func init() {
+	myWorkCmd.Flags().BoolP("all", "a", false, "include closed tasks")
+	myWorkCmd.Flags().String("only-store", "", "list only tasks on this store (\"local\" or hostname)")
+	myWorkCmd.Flags().Bool("json", false, "print the tasks as JSON")
+	myCmd.AddCommand(myWorkCmd)
+	rootCmd.AddCommand(myCmd)
+}
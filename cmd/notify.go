@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/notify"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// notifier sends notifications for changes made through storeForProject and
+// storeForEntity. It is nil when no sinks are configured.
+var notifier *notify.Notifier
+
+// loadNotifier builds notifier from cfg. A broken sink is reported and
+// disables notifications rather than failing the command.
+func loadNotifier() {
+	notifier = nil
+	if len(cfg.Notifications) == 0 {
+		return
+	}
+	n, err := notify.New(cfg.Notifications, store.CurrentUser())
+	if err != nil {
+		slog.Warn("notifications disabled", "err", err)
+		return
+	}
+	notifier = n
+}
+
+func withNotifications(s store.Store) store.Store {
+	if s == nil || notifier == nil || dryRun {
+		return s
+	}
+	return notify.Wrap(s, notifier, func(err error) {
+		slog.Warn("notification failed", "err", err)
+	})
+}
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Check notification sinks",
+	Long: `Notifications are sent to the sinks listed under "notifications" in
+config.yaml when tasks change through this CLI:
+
+  notifications:
+    - type: slack
+      url: ${SLACK_WEBHOOK_URL}
+      events: [p0_created, epic_completed]
+    - type: webhook
+      url: https://example.com/hooks/compass
+    - type: smtp
+      host: smtp.example.com:587
+      username: me@example.com
+      password: ${SMTP_PASSWORD}
+      from: me@example.com
+      to: [me@example.com]
+      events: [task_assigned]
+
+Events are task_assigned (a task is assigned to the sink's assignee, which
+defaults to the current user), p0_created and epic_completed (the last open
+task in an epic is closed). A sink without events gets all of them.`,
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test message to every configured sink",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(cfg.Notifications) == 0 {
+			return fmt.Errorf("no notification sinks configured; add them under \"notifications\" in %s", filepath.Join(dataDir, "config.yaml"))
+		}
+		n, err := notify.New(cfg.Notifications, store.CurrentUser())
+		if err != nil {
+			return err
+		}
+		only, _ := cmd.Flags().GetString("sink")
+		msg := notify.Message{
+			Event:   notify.EventTest,
+			Subject: "compass test notification",
+			Text:    fmt.Sprintf("Notifications from %s are working.", store.CurrentUser()),
+		}
+		var failed []string
+		sent := 0
+		for _, s := range n.Sinks {
+			if only != "" && s.Name != only {
+				continue
+			}
+			sent++
+			if err := s.Send(msg); err != nil {
+				fmt.Printf("%s: FAILED: %v\n", s.Name, err)
+				failed = append(failed, s.Name)
+				continue
+			}
+			fmt.Printf("%s: ok\n", s.Name)
+		}
+		if sent == 0 {
+			return fmt.Errorf("no sink named %q", only)
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("%d sink(s) failed: %s", len(failed), strings.Join(failed, ", "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	notifyTestCmd.Flags().String("sink", "", "only test the sink with this name (defaults to its type)")
+
+	notifyCmd.AddCommand(notifyTestCmd)
+	rootCmd.AddCommand(notifyCmd)
+}
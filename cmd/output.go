@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/markdown"
+)
+
+var (
+	quiet   bool
+	noColor bool
+	// dryRun is --dry-run: stores report mutations instead of making them.
+	dryRun bool
+	// renderWidth and renderStyle are --width and --style, for markdown
+	// bodies.
+	renderWidth int
+	renderStyle string
+	// fullTables and wrapTables are --full and --wrap: leave long titles in
+	// tables whole, or wrap them, instead of truncating them to fit.
+	fullTables bool
+	wrapTables bool
+)
+
+// applyOutputFlags sets up plain output for --no-color or a non-empty
+// NO_COLOR (https://no-color.org), and how tables fit the terminal.
+func applyOutputFlags() {
+	markdown.SetPlain(noColor || os.Getenv("NO_COLOR") != "")
+	switch {
+	case fullTables:
+		markdown.SetTableFit(markdown.FitFull)
+	case wrapTables:
+		markdown.SetTableFit(markdown.FitWrap)
+	default:
+		markdown.SetTableFit(markdown.FitTruncate)
+	}
+}
+
+// applyRenderFlags sets how markdown bodies are rendered from --width and
+// --style, falling back to render_width and render_style in config.
+func applyRenderFlags(c *config.Config) error {
+	width, style := renderWidth, renderStyle
+	if c != nil {
+		if width == 0 {
+			width = c.RenderWidth
+		}
+		if style == "" {
+			style = c.RenderStyle
+		}
+	}
+	return markdown.SetBodyRendering(width, style)
+}
+
+// infof prints a confirmation or progress message. --quiet suppresses it, so
+// stdout carries only command output: listings, shown entities and IDs. So
+// does --dry-run, where nothing was done to confirm.
+func infof(format string, a ...any) {
+	if !quiet && !dryRun {
+		fmt.Printf(format, a...)
+	}
+}
+
+// printCreated reports a new entity. Under --quiet only its ID is printed,
+// so scripts can capture it.
+func printCreated(id, format string, a ...any) {
+	if dryRun {
+		return
+	}
+	if quiet {
+		fmt.Println(id)
+		return
+	}
+	fmt.Printf(format, a...)
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/rogersnm/compass/internal/id"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/pin"
+	"github.com/spf13/cobra"
+)
+
+var taskPinCmd = &cobra.Command{
+	Use:   "pin <id>",
+	Short: "Pin a task to the top of task lists",
+	Long: `Pin a task so it sits at the top of "task list" and shows in
+"compass pinned". Pins are kept in the data directory on this machine, not
+on the task, so they make a personal working set without changing status.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPinned(cmd.Context(), args[0], id.Task, true)
+	},
+}
+
+var taskUnpinCmd = &cobra.Command{
+	Use:   "unpin <id>",
+	Short: "Unpin a task",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPinned(cmd.Context(), args[0], id.Task, false)
+	},
+}
+
+var docPinCmd = &cobra.Command{
+	Use:   "pin <id>",
+	Short: "Pin a document to the top of document lists",
+	Long: `Pin a document so it sits at the top of "doc list" and shows in
+"compass pinned". Pins are kept in the data directory on this machine.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPinned(cmd.Context(), args[0], id.Document, true)
+	},
+}
+
+var docUnpinCmd = &cobra.Command{
+	Use:   "unpin <id>",
+	Short: "Unpin a document",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPinned(cmd.Context(), args[0], id.Document, false)
+	},
+}
+
+var pinnedCmd = &cobra.Command{
+	Use:   "pinned",
+	Short: "List pinned tasks and documents across projects",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		ps, err := pin.Load(dataDir)
+		if err != nil {
+			return err
+		}
+		if len(ps) == 0 {
+			infof("Nothing pinned.\n")
+			return nil
+		}
+		for _, p := range ps {
+			fmt.Println(pinnedLine(ctx, p.ID))
+		}
+		return nil
+	},
+}
+
+// setPinned pins or unpins entityID, which must be of type kind. Pinning
+// checks the entity exists; unpinning doesn't, so pins of deleted
+// entities can be cleared.
+func setPinned(ctx context.Context, entityID string, kind id.EntityType, on bool) error {
+	if t, err := id.TypeOf(entityID); err != nil {
+		return err
+	} else if t != kind {
+		return fmt.Errorf("%s is a %s, not a %s", entityID, t, kind)
+	}
+	ps, err := pin.Load(dataDir)
+	if err != nil {
+		return err
+	}
+	if !on {
+		ps, removed := pin.Remove(ps, entityID)
+		if !removed {
+			return fmt.Errorf("%s is not pinned", entityID)
+		}
+		if dryRun {
+			fmt.Printf("Would unpin %s\n", entityID)
+			return nil
+		}
+		if err := pin.Save(dataDir, ps); err != nil {
+			return err
+		}
+		infof("Unpinned %s\n", entityID)
+		return nil
+	}
+
+	s, err := storeForEntity(ctx, entityID)
+	if err != nil {
+		return err
+	}
+	if kind == id.Task {
+		_, _, err = s.GetTask(ctx, entityID)
+	} else {
+		_, _, err = s.GetDocument(ctx, entityID)
+	}
+	if err != nil {
+		return err
+	}
+	ps, added := pin.Add(ps, entityID, time.Now().UTC())
+	if !added {
+		infof("%s is already pinned\n", entityID)
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("Would pin %s\n", entityID)
+		return nil
+	}
+	if err := pin.Save(dataDir, ps); err != nil {
+		return err
+	}
+	infof("Pinned %s\n", entityID)
+	return nil
+}
+
+// pinnedLine formats a pin with its entity's title and, for tasks, status.
+// Entities that can't be fetched (deleted, or a store that is offline)
+// show their ID alone.
+func pinnedLine(ctx context.Context, entityID string) string {
+	line := entityID
+	s, err := storeForEntity(ctx, entityID)
+	if err != nil {
+		return line
+	}
+	if kind, _ := id.TypeOf(entityID); kind == id.Document {
+		if d, _, err := s.GetDocument(ctx, entityID); err == nil {
+			line += "  " + d.Title + " (doc)"
+		}
+		return line
+	}
+	if t, _, err := s.GetTask(ctx, entityID); err == nil {
+		status := string(t.Status)
+		if t.Type == model.TypeEpic {
+			status = "epic"
+		}
+		line += "  " + t.Title + " (" + status + ")"
+	}
+	return line
+}
+
+// pinnedFirst moves the pinned entities in list to the front.
+func pinnedFirst[T any](list []T, entityID func(*T) string) {
+	ps, err := pin.Load(dataDir)
+	if err != nil {
+		slog.Warn("reading pins", "err", err)
+		return
+	}
+	pin.First(list, pin.Set(ps), entityID)
+}
+
+func init() {
+	taskCmd.AddCommand(taskPinCmd)
+	taskCmd.AddCommand(taskUnpinCmd)
+	docCmd.AddCommand(docPinCmd)
+	docCmd.AddCommand(docUnpinCmd)
+	rootCmd.AddCommand(pinnedCmd)
+}
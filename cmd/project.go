@@ -1,15 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/charmbracelet/huh"
 	"github.com/rogersnm/compass/internal/config"
 	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/model"
 	"github.com/rogersnm/compass/internal/repofile"
 	"github.com/rogersnm/compass/internal/store"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var projectCmd = &cobra.Command{
@@ -20,105 +26,201 @@ var projectCmd = &cobra.Command{
 var projectCreateCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new project",
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new project. The body is read from stdin.
+
+With --json, stdin is a JSON object instead and the created project is
+printed as JSON:
+
+  {"name": "...", "key": "AUTH", "store": "local", "body": "..."}
+
+A name argument overrides the object's name; --key and --store fill in
+fields the object leaves out.`,
+	Args: titleArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		key, _ := cmd.Flags().GetString("key")
-		body := readStdin()
+		asJSON, _ := cmd.Flags().GetBool("json")
 
-		// Resolve which store to use
-		storeName, _ := cmd.Flags().GetString("store")
+		var name, body string
 		var s store.Store
+		var storeName string
 		var err error
-
-		if storeName != "" {
-			s, err = reg.Get(storeName)
+		if asJSON {
+			in, err := readJSONInput[struct {
+				Name  string `json:"name"`
+				Key   string `json:"key"`
+				Store string `json:"store"`
+				Body  string `json:"body"`
+			}]()
 			if err != nil {
 				return err
 			}
-		} else {
-			s, storeName, err = reg.Default()
-			if err != nil {
-				// Multiple stores, no default: prompt
-				names := reg.Names()
-				if len(names) == 0 {
-					return fmt.Errorf("no stores configured; run 'compass store add local' or 'compass store add <hostname>'")
-				}
-				opts := make([]huh.Option[string], len(names))
-				for i, n := range names {
-					opts[i] = huh.NewOption(n, n)
-				}
-				if err := huh.NewSelect[string]().
-					Title("Which store should this project be created on?").
-					Options(opts...).
-					Value(&storeName).
-					Run(); err != nil {
-					return fmt.Errorf("selection cancelled")
-				}
-				s, err = reg.Get(storeName)
-				if err != nil {
+			name, body = in.Name, in.Body
+			if in.Key != "" {
+				key = in.Key
+			}
+			if in.Store != "" {
+				storeName = in.Store
+				if s, err = reg.Get(storeName); err != nil {
 					return err
 				}
 			}
+		} else {
+			body = readStdin()
+		}
+		if len(args) == 1 {
+			name = args[0]
+		}
+		if name == "" {
+			return fmt.Errorf("name is required")
+		}
+
+		if s == nil {
+			if s, storeName, err = storeForNewProject(cmd); err != nil {
+				return err
+			}
 		}
 
-		p, err := s.CreateProject(args[0], key, body)
+		p, err := s.CreateProject(ctx, name, key, body)
 		if err != nil {
 			return err
 		}
 		reg.CacheProject(p.ID, storeName)
-		fmt.Printf("Created project %s (%s)\n", p.Name, p.ID)
+		if asJSON {
+			return printJSON(p)
+		}
+		printCreated(p.ID, "Created project %s (%s)\n", p.Name, p.ID)
 		return nil
 	},
 }
 
+// storeForNewProject resolves the store a new project should be created on:
+// the --store flag, else the default store, else an interactive choice.
+func storeForNewProject(cmd *cobra.Command) (store.Store, string, error) {
+	storeName, _ := cmd.Flags().GetString("store")
+	if storeName != "" {
+		s, err := reg.Get(storeName)
+		return s, storeName, err
+	}
+
+	s, storeName, err := reg.Default()
+	if err == nil {
+		return s, storeName, nil
+	}
+
+	// Multiple stores, no default: prompt
+	names := reg.Names()
+	if len(names) == 0 {
+		return nil, "", fmt.Errorf("no stores configured; run 'compass store add local' or 'compass store add <hostname>'")
+	}
+	if err := canPrompt("pass --store, set COMPASS_STORE, or set a default with 'compass store set-default'"); err != nil {
+		return nil, "", err
+	}
+	opts := make([]huh.Option[string], len(names))
+	for i, n := range names {
+		opts[i] = huh.NewOption(n, n)
+	}
+	if err := huh.NewSelect[string]().
+		Title("Which store should this project be created on?").
+		Options(opts...).
+		Value(&storeName).
+		Run(); err != nil {
+		return nil, "", fmt.Errorf("selection cancelled")
+	}
+	s, err = reg.Get(storeName)
+	return s, storeName, err
+}
+
 var projectListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all projects",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var rows []markdown.ProjectRow
-
-		// Read from cache, fetch metadata from each mapped store
-		if cfg.Projects != nil {
-			for key, storeName := range cfg.Projects {
-				s, err := reg.Get(storeName)
-				if err != nil {
-					continue
-				}
-				p, _, err := s.GetProject(key)
-				if err != nil {
-					continue // stale cache entry
-				}
-				rows = append(rows, markdown.ProjectRow{Project: *p, StoreName: storeName})
-			}
+		ctx := cmd.Context()
+		only, _ := cmd.Flags().GetString("only-store")
+		byStore, errs, err := store.FanOut(ctx, reg, only, fanOutTimeout, func(ctx context.Context, s store.Store) ([]model.Project, error) {
+			return s.ListProjects(ctx)
+		})
+		if err != nil {
+			return err
 		}
+		warnUnreachable(errs)
 
-		// Also discover any local projects not yet cached
-		if cfg.LocalEnabled {
-			s, err := reg.Get("local")
-			if err == nil {
-				projects, err := s.ListProjects()
-				if err == nil {
-					for _, p := range projects {
-						if cfg.Projects == nil || cfg.Projects[p.ID] == "" {
-							rows = append(rows, markdown.ProjectRow{Project: p, StoreName: "local"})
-							reg.CacheProject(p.ID, "local")
-						}
-					}
+		// Show projects cached against the store that returned them, plus any
+		// local projects not yet cached.
+		var rows []markdown.ProjectRow
+		for _, storeName := range sortedKeys(byStore) {
+			for _, p := range byStore[storeName] {
+				_, cached := cfg.Projects[p.ID]
+				switch {
+				case config.CachedOn(cfg.Projects, p.ID, storeName):
+				case !cached && storeName == "local":
+					reg.CacheProject(p.ID, "local")
+				default:
+					continue
 				}
+				rows = append(rows, markdown.ProjectRow{Project: p, StoreName: storeName})
 			}
 		}
+		rows = append(rows, unconfirmedProjects(rows, errs, only)...)
 
 		fmt.Println(markdown.RenderProjectTableWithStores(rows))
 		return nil
 	},
 }
 
+// cachedProjects returns the keys of the cached projects, sorted, for
+// commands that cover every project. Projects cached under an alias are
+// left out: they share a key with another store's project.
+func cachedProjects() []string {
+	var keys []string
+	for _, key := range sortedKeys(cfg.Projects) {
+		if _, _, alias := config.SplitAlias(key); !alias {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// unconfirmedProjects returns rows for cached projects missing from the
+// listing, so they are flagged rather than silently dropped: "unreachable"
+// when their store failed to answer, "stale" when it answered without them.
+func unconfirmedProjects(listed []markdown.ProjectRow, errs []store.StoreError, only string) []markdown.ProjectRow {
+	seen := make(map[config.ProjectMapping]bool, len(listed))
+	for _, r := range listed {
+		seen[config.ProjectMapping{Key: r.Project.ID, Store: r.StoreName}] = true
+	}
+	failed := make(map[string]bool, len(errs))
+	for _, e := range errs {
+		failed[e.Store] = true
+	}
+
+	var rows []markdown.ProjectRow
+	stale := 0
+	for _, m := range config.Mappings(cfg.Projects) {
+		if seen[config.ProjectMapping{Key: m.Key, Store: m.Store}] || (only != "" && m.Store != only) {
+			continue
+		}
+		note := "stale"
+		if failed[m.Store] {
+			note = "unreachable"
+		} else {
+			stale++
+		}
+		rows = append(rows, markdown.ProjectRow{Project: model.Project{ID: m.Entry}, StoreName: m.Store, Note: note})
+	}
+	if stale > 0 {
+		slog.Warn("cached projects no longer exist on their store; fix with 'compass project set-store' or 'compass store fetch'", "count", stale)
+	}
+	return rows
+}
+
 var projectShowCmd = &cobra.Command{
 	Use:   "show <id>",
 	Short: "Show project details",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForProject(args[0])
+		ctx := cmd.Context()
+		s, err := storeForProject(ctx, args[0])
 		if err != nil {
 			return err
 		}
@@ -135,7 +237,7 @@ var projectShowCmd = &cobra.Command{
 				return nil
 			}
 			// Cloud mode: marshal from API response
-			p, body, err := s.GetProject(args[0])
+			p, body, err := s.GetProject(ctx, args[0])
 			if err != nil {
 				return err
 			}
@@ -147,7 +249,7 @@ var projectShowCmd = &cobra.Command{
 			return nil
 		}
 
-		p, body, err := s.GetProject(args[0])
+		p, body, err := s.GetProject(ctx, args[0])
 		if err != nil {
 			return err
 		}
@@ -169,23 +271,105 @@ var projectShowCmd = &cobra.Command{
 	},
 }
 
+var projectDashboardCmd = &cobra.Command{
+	Use:   "dashboard <id>",
+	Short: "Show a one-screen summary of a project",
+	Long: `Show a project's status counts, epic progress, the head of the ready queue,
+tasks due within --due-days (and overdue ones), and the latest status
+changes.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForProject(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		p, _, err := s.GetProject(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		allTasks, err := s.AllTaskMap(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		ready, err := s.ReadyTasks(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+		dueDays, _ := cmd.Flags().GetInt("due-days")
+		limit, _ := cmd.Flags().GetInt("limit")
+		now := time.Now()
+		d := markdown.Dashboard{
+			Project: *p,
+			Counts:  map[model.Status]int{},
+			Today:   now.Format(model.DateFormat),
+		}
+		horizon := now.AddDate(0, 0, dueDays).Format(model.DateFormat)
+
+		children := map[string][]model.Task{}
+		var tasks []model.Task
+		for _, t := range allTasks {
+			tasks = append(tasks, *t)
+		}
+		sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+		for _, t := range tasks {
+			if t.Type == model.TypeEpic {
+				continue
+			}
+			children[t.Epic] = append(children[t.Epic], t)
+			d.Counts[t.Status]++
+			for _, c := range t.History {
+				d.Recent = append(d.Recent, markdown.Activity{Task: t, Change: c})
+			}
+			if t.Status == model.StatusClosed {
+				continue
+			}
+			if t.IsBlocked(allTasks, depPolicy()) {
+				d.Blocked++
+			}
+			if t.Due != "" && t.Due <= horizon {
+				d.Due = append(d.Due, t)
+			}
+		}
+		for _, t := range tasks {
+			if t.Type == model.TypeEpic {
+				d.Epics = append(d.Epics, markdown.EpicProgress{Epic: t, Progress: model.ProgressOf(children[t.ID])})
+			}
+		}
+		sort.SliceStable(d.Due, func(i, j int) bool { return d.Due[i].Due < d.Due[j].Due })
+		sort.SliceStable(d.Recent, func(i, j int) bool { return d.Recent[i].Change.At.After(d.Recent[j].Change.At) })
+		for _, t := range ready {
+			d.Ready = append(d.Ready, *t)
+		}
+		if limit > 0 {
+			d.Ready = d.Ready[:min(len(d.Ready), limit)]
+			d.Due = d.Due[:min(len(d.Due), limit)]
+			d.Recent = d.Recent[:min(len(d.Recent), limit)]
+		}
+
+		fmt.Print(markdown.RenderDashboard(d))
+		return nil
+	},
+}
+
 var projectSetDefaultCmd = &cobra.Command{
 	Use:   "set-default <id>",
 	Short: "Set the default project",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForProject(args[0])
+		ctx := cmd.Context()
+		s, err := storeForProject(ctx, args[0])
 		if err != nil {
 			return err
 		}
-		if _, _, err := s.GetProject(args[0]); err != nil {
+		if _, _, err := s.GetProject(ctx, args[0]); err != nil {
 			return err
 		}
 		cfg.DefaultProject = args[0]
-		if err := config.Save(dataDir, cfg); err != nil {
+		if err := saveConfig(cfg); err != nil {
 			return err
 		}
-		fmt.Printf("Default project set to %s\n", args[0])
+		infof("Default project set to %s\n", args[0])
 		return nil
 	},
 }
@@ -195,35 +379,154 @@ var projectDeleteCmd = &cobra.Command{
 	Short: "Delete a project and all its tasks and documents",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForProject(args[0])
+		ctx := cmd.Context()
+		s, err := storeForProject(ctx, args[0])
 		if err != nil {
 			return err
 		}
 
-		p, _, err := s.GetProject(args[0])
+		p, _, err := s.GetProject(ctx, args[0])
 		if err != nil {
 			return err
 		}
 
-		tasks, _ := s.ListTasks(store.TaskFilter{ProjectID: p.ID})
-		docs, _ := s.ListDocuments(p.ID)
-		fmt.Printf("Project: %s (%s), %d tasks, %d documents\n", p.Name, p.ID, len(tasks), len(docs))
+		tasks, _ := s.ListTasks(ctx, store.TaskFilter{ProjectID: p.ID})
+		docs, _ := s.ListDocuments(ctx, store.DocumentFilter{ProjectID: p.ID})
+		infof("Project: %s (%s), %d tasks, %d documents\n", p.Name, p.ID, len(tasks), len(docs))
 
 		if err := confirmDelete(cmd, p.ID); err != nil {
 			return err
 		}
-		if err := s.DeleteProject(p.ID); err != nil {
+		if err := s.DeleteProject(ctx, p.ID); err != nil {
 			return err
 		}
+		if dryRun {
+			return nil
+		}
 
 		reg.UncacheProject(p.ID)
 
 		if cfg.DefaultProject == p.ID {
 			cfg.DefaultProject = ""
-			config.Save(dataDir, cfg)
+			saveConfig(cfg)
 		}
 
-		fmt.Printf("Deleted project %s\n", p.ID)
+		infof("Deleted project %s\n", p.ID)
+		return nil
+	},
+}
+
+var projectUpdateCmd = &cobra.Command{
+	Use:   "update <id>",
+	Short: "Change a project's name, description or settings (the key is unchanged)",
+	Long: `Change a project's name with --name, and its description by piping the
+new body on stdin:
+
+  compass project update AUTH --name "Authentication"
+  cat overview.md | compass project update AUTH
+
+--auto-close-epics turns auto_close_epics on or off, closing or reopening
+the project's epics to match.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		var upd store.ProjectUpdate
+		if cmd.Flags().Changed("name") {
+			name, _ := cmd.Flags().GetString("name")
+			upd.Name = &name
+		}
+		if cmd.Flags().Changed("auto-close-epics") {
+			on, _ := cmd.Flags().GetBool("auto-close-epics")
+			upd.AutoCloseEpics = &on
+		}
+		if body := readStdin(); body != "" {
+			upd.Body = &body
+		}
+		if upd.Name == nil && upd.AutoCloseEpics == nil && upd.Body == nil {
+			return fmt.Errorf("at least one update flag or piped body is required (--name, --auto-close-epics, stdin)")
+		}
+		s, err := storeForProject(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		p, err := s.UpdateProject(ctx, args[0], upd)
+		if err != nil {
+			return err
+		}
+		infof("Updated project %s\n", p.ID)
+		return nil
+	},
+}
+
+var projectRenameCmd = &cobra.Command{
+	Use:   "rename <id> --name <name>",
+	Short: "Change a project's name (the key is unchanged)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		name, _ := cmd.Flags().GetString("name")
+		if name == "" {
+			return fmt.Errorf("--name is required")
+		}
+		s, err := storeForProject(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		p, err := s.UpdateProject(ctx, args[0], store.ProjectUpdate{Name: &name})
+		if err != nil {
+			return err
+		}
+		infof("Renamed project %s to %s\n", p.ID, p.Name)
+		return nil
+	},
+}
+
+var projectRekeyCmd = &cobra.Command{
+	Use:   "rekey <old-key> <new-key>",
+	Short: "Change a project's key, rewriting the IDs of its tasks, documents and releases",
+	Long: `Change a project's key. Every task, document and release ID in the project
+is rewritten from OLD-... to NEW-..., as are the dependency, epic and release
+references between them. IDs mentioned in markdown bodies are not changed.
+
+The project cache, the default project and the .compass-project link found
+from the current directory are updated. Links in other checkouts must be
+re-created with 'compass project link'.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		oldKey, newKey := args[0], args[1]
+		s, storeName, err := reg.ForProject(ctx, oldKey)
+		if err != nil {
+			return err
+		}
+		p, err := s.RekeyProject(ctx, oldKey, newKey)
+		if err != nil {
+			return err
+		}
+
+		reg.UncacheProject(oldKey)
+		reg.CacheProject(p.ID, storeName)
+		if cfg.DefaultProject == oldKey {
+			cfg.DefaultProject = p.ID
+			if err := saveConfig(cfg); err != nil {
+				return err
+			}
+		}
+		infof("Rekeyed project %s to %s\n", oldKey, p.ID)
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if linked, dir, err := repofile.Find(cwd); err == nil && linked == oldKey {
+			changed, err := repofile.Rekey(dir, oldKey, p.ID)
+			if err != nil {
+				return err
+			}
+			for _, f := range changed {
+				infof("Updated %s\n", f)
+			}
+		}
 		return nil
 	},
 }
@@ -233,6 +536,7 @@ var projectSetStoreCmd = &cobra.Command{
 	Short: "Change which store a project is mapped to",
 	Args:  cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		key := args[0]
 		storeName := args[1]
 
@@ -241,15 +545,15 @@ var projectSetStoreCmd = &cobra.Command{
 			return fmt.Errorf("store %q not found: %w", storeName, err)
 		}
 
-		if _, _, err := s.GetProject(key); err != nil {
+		if _, _, err := s.GetProject(ctx, key); err != nil {
 			return fmt.Errorf("project %s not found on store %q: %w", key, storeName, err)
 		}
 
 		reg.CacheProject(key, storeName)
-		if err := config.Save(dataDir, cfg); err != nil {
+		if err := saveConfig(cfg); err != nil {
 			return err
 		}
-		fmt.Printf("Project %s mapped to %s\n", key, storeName)
+		infof("Project %s mapped to %s\n", key, storeName)
 		return nil
 	},
 }
@@ -259,24 +563,32 @@ var projectLinkCmd = &cobra.Command{
 	Short: "Link the current directory to a project",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		var projectID string
 		if len(args) == 1 {
 			projectID = args[0]
 		} else {
 			// Collect projects from all stores
+			only, _ := cmd.Flags().GetString("only-store")
+			byStore, errs, err := store.FanOut(ctx, reg, only, fanOutTimeout, func(ctx context.Context, s store.Store) ([]model.Project, error) {
+				return s.ListProjects(ctx)
+			})
+			if err != nil {
+				return err
+			}
+			warnUnreachable(errs)
 			var rows []markdown.ProjectRow
-			for name, s := range reg.All() {
-				projects, err := s.ListProjects()
-				if err != nil {
-					continue
-				}
-				for _, p := range projects {
+			for _, name := range sortedKeys(byStore) {
+				for _, p := range byStore[name] {
 					rows = append(rows, markdown.ProjectRow{Project: p, StoreName: name})
 				}
 			}
 			if len(rows) == 0 {
 				return fmt.Errorf("no projects exist; create one first with: compass project create <name>")
 			}
+			if err := canPrompt("pass the project to link, as in: compass project link AUTH"); err != nil {
+				return err
+			}
 			opts := make([]huh.Option[string], len(rows))
 			for i, r := range rows {
 				opts[i] = huh.NewOption(fmt.Sprintf("%s  %s  (%s)", r.Project.ID, r.Project.Name, r.StoreName), r.Project.ID)
@@ -290,11 +602,11 @@ var projectLinkCmd = &cobra.Command{
 			}
 		}
 
-		s, err := storeForProject(projectID)
+		s, err := storeForProject(ctx, projectID)
 		if err != nil {
 			return fmt.Errorf("project %s not found", projectID)
 		}
-		if _, _, err := s.GetProject(projectID); err != nil {
+		if _, _, err := s.GetProject(ctx, projectID); err != nil {
 			return fmt.Errorf("project %s not found", projectID)
 		}
 
@@ -302,10 +614,13 @@ var projectLinkCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if prefix, _ := cmd.Flags().GetString("path"); prefix != "" {
+			return linkPrefix(cwd, prefix, projectID)
+		}
 		if err := repofile.Write(cwd, projectID); err != nil {
 			return err
 		}
-		fmt.Printf("Linked %s to project %s\n", repofile.FileName, projectID)
+		infof("Linked %s to project %s\n", repofile.FileName, projectID)
 		return nil
 	},
 }
@@ -318,6 +633,9 @@ var projectUnlinkCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if prefix, _ := cmd.Flags().GetString("path"); prefix != "" {
+			return linkPrefix(cwd, prefix, "")
+		}
 		path := cwd + "/" + repofile.FileName
 		if err := os.Remove(path); err != nil {
 			if os.IsNotExist(err) {
@@ -326,7 +644,110 @@ var projectUnlinkCmd = &cobra.Command{
 			}
 			return err
 		}
-		fmt.Println("Unlinked project.")
+		infof("Unlinked project.\n")
+		return nil
+	},
+}
+
+// linkPrefix maps prefix to projectID in dir's .compass-projects, or
+// removes the mapping when projectID is empty.
+func linkPrefix(dir, prefix, projectID string) error {
+	prefix, err := repofile.CleanPrefix(prefix)
+	if err != nil {
+		return err
+	}
+	m, err := repofile.ReadMap(dir)
+	if err != nil {
+		return err
+	}
+	if projectID == "" {
+		if _, ok := m[prefix]; !ok {
+			fmt.Printf("No project linked for %s.\n", prefix)
+			return nil
+		}
+		delete(m, prefix)
+		if err := repofile.WriteMap(dir, m); err != nil {
+			return err
+		}
+		infof("Unlinked %s.\n", prefix)
+		return nil
+	}
+	if m == nil {
+		m = map[string]string{}
+	}
+	m[prefix] = projectID
+	if err := repofile.WriteMap(dir, m); err != nil {
+		return err
+	}
+	infof("Linked %s to project %s in %s\n", prefix, projectID, repofile.MapFileName)
+	return nil
+}
+
+var projectBlueprintCmd = &cobra.Command{
+	Use:   "blueprint",
+	Short: "Export and apply project blueprints (reusable project skeletons)",
+}
+
+var projectBlueprintExportCmd = &cobra.Command{
+	Use:   "export <key>",
+	Short: "Export a project's settings, epics, task skeletons, and documents as a blueprint",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForProject(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		bp, err := store.ExportBlueprint(ctx, s, args[0])
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(bp)
+		if err != nil {
+			return err
+		}
+
+		out, _ := cmd.Flags().GetString("output")
+		if out == "" {
+			fmt.Print(string(data))
+			return nil
+		}
+		if err := os.WriteFile(out, data, 0644); err != nil {
+			return err
+		}
+		infof("Exported blueprint of %s to %s (%d tasks, %d documents)\n", args[0], out, len(bp.Tasks), len(bp.Documents))
+		return nil
+	},
+}
+
+var projectBlueprintApplyCmd = &cobra.Command{
+	Use:   "apply <file>",
+	Short: "Create a new project from a blueprint",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		var bp store.Blueprint
+		if err := yaml.Unmarshal(data, &bp); err != nil {
+			return fmt.Errorf("parsing blueprint: %w", err)
+		}
+
+		s, storeName, err := storeForNewProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		name, _ := cmd.Flags().GetString("name")
+		key, _ := cmd.Flags().GetString("key")
+		p, err := store.ApplyBlueprint(ctx, s, &bp, name, key)
+		if err != nil {
+			return err
+		}
+		reg.CacheProject(p.ID, storeName)
+		printCreated(p.ID, "Created project %s (%s) from blueprint (%d tasks, %d documents)\n", p.Name, p.ID, len(bp.Tasks), len(bp.Documents))
 		return nil
 	},
 }
@@ -334,16 +755,38 @@ var projectUnlinkCmd = &cobra.Command{
 func init() {
 	projectCreateCmd.Flags().StringP("key", "k", "", "project key (2-5 uppercase alphanumeric chars)")
 	projectCreateCmd.Flags().String("store", "", "store to create the project on (\"local\" or hostname)")
+	projectCreateCmd.Flags().Bool("json", false, "read the project as a JSON object from stdin and print the result as JSON")
+	projectListCmd.Flags().String("only-store", "", "list only projects on this store (\"local\" or hostname)")
+	projectLinkCmd.Flags().String("only-store", "", "pick only from projects on this store (\"local\" or hostname)")
+	projectLinkCmd.Flags().String("path", "", "link only this subdirectory, in the monorepo mapping file .compass-projects")
+	projectUnlinkCmd.Flags().String("path", "", "remove this subdirectory's entry from .compass-projects")
 	projectShowCmd.Flags().Bool("pretty", false, "render with ANSI styling")
+	projectDashboardCmd.Flags().Int("due-days", 14, "show tasks due within this many days")
+	projectDashboardCmd.Flags().IntP("limit", "n", 5, "rows per panel (0 for all)")
 	projectDeleteCmd.Flags().BoolP("force", "f", false, "skip confirmation")
+	projectUpdateCmd.Flags().String("name", "", "new project name")
+	projectUpdateCmd.Flags().Bool("auto-close-epics", false, "close epics when their last open task closes")
+	projectRenameCmd.Flags().String("name", "", "new project name")
+
+	projectBlueprintExportCmd.Flags().StringP("output", "o", "", "write to file instead of stdout")
+	projectBlueprintApplyCmd.Flags().String("name", "", "project name (defaults to the blueprint's)")
+	projectBlueprintApplyCmd.Flags().StringP("key", "k", "", "project key (2-5 uppercase alphanumeric chars)")
+	projectBlueprintApplyCmd.Flags().String("store", "", "store to create the project on (\"local\" or hostname)")
+	projectBlueprintCmd.AddCommand(projectBlueprintExportCmd)
+	projectBlueprintCmd.AddCommand(projectBlueprintApplyCmd)
 
 	projectCmd.AddCommand(projectCreateCmd)
 	projectCmd.AddCommand(projectListCmd)
 	projectCmd.AddCommand(projectShowCmd)
+	projectCmd.AddCommand(projectDashboardCmd)
 	projectCmd.AddCommand(projectSetDefaultCmd)
 	projectCmd.AddCommand(projectDeleteCmd)
+	projectCmd.AddCommand(projectUpdateCmd)
+	projectCmd.AddCommand(projectRenameCmd)
+	projectCmd.AddCommand(projectRekeyCmd)
 	projectCmd.AddCommand(projectSetStoreCmd)
 	projectCmd.AddCommand(projectLinkCmd)
 	projectCmd.AddCommand(projectUnlinkCmd)
+	projectCmd.AddCommand(projectBlueprintCmd)
 	rootCmd.AddCommand(projectCmd)
 }
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// refFlags are the flags that name a project or task, and so take the
+// same references as positional arguments.
+var refFlags = []string{"project", "parent-epic", "to-project"}
+
+// resolveRefs rewrites the positional arguments and ref flags of cmd that
+// are store-qualified ("work:AUTH-TABCDE") or slug ("AUTH/login-form")
+// references to plain keys and IDs, so commands only ever see those.
+func resolveRefs(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	for _, name := range refFlags {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || !f.Changed {
+			continue
+		}
+		ref, err := resolveRef(ctx, f.Value.String())
+		if err != nil {
+			return err
+		}
+		if err := f.Value.Set(ref); err != nil {
+			return err
+		}
+	}
+	for i, arg := range args {
+		var err error
+		if args[i], err = resolveRef(ctx, arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveRef routes a store-qualified reference to its store and returns
+// it unqualified, and returns the ID of the task or document a slug
+// reference names. Anything else, including a path that exists, is
+// returned as it is.
+func resolveRef(ctx context.Context, ref string) (string, error) {
+	ref, err := reg.Qualify(ref)
+	if err != nil {
+		return "", err
+	}
+	key, slug, ok := store.ParseSlugRef(ref)
+	if !ok {
+		return ref, nil
+	}
+	if _, err := os.Stat(ref); err == nil {
+		return ref, nil
+	}
+	s, err := storeForProject(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return store.ResolveSlug(ctx, s, key, slug)
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Manage releases",
+}
+
+var releaseCreateCmd = &cobra.Command{
+	Use:   "create <version>",
+	Short: "Create a new release",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		targetDate, _ := cmd.Flags().GetString("target-date")
+		includeStr, _ := cmd.Flags().GetString("include")
+
+		var items []string
+		if includeStr != "" {
+			items = strings.Split(includeStr, ",")
+		}
+
+		r, err := s.CreateRelease(ctx, args[0], projectID, store.ReleaseCreateOpts{
+			TargetDate: targetDate,
+			Items:      items,
+			Body:       readStdin(),
+		})
+		if err != nil {
+			return err
+		}
+		printCreated(r.ID, "Created release %s (%s)\n", r.Version, r.ID)
+		return nil
+	},
+}
+
+var releaseListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List releases",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		releases, err := s.ListReleases(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		fmt.Println(markdown.RenderReleaseTable(releases))
+		return nil
+	},
+}
+
+var releaseShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show release details",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		pretty, _ := cmd.Flags().GetBool("pretty")
+		if !pretty {
+			path, err := s.ResolveEntityPath(args[0])
+			if err == nil {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				fmt.Print(string(data))
+				return nil
+			}
+			// Cloud mode: marshal from API response
+			r, body, err := s.GetRelease(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			data, err := markdown.Marshal(r, body)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(data))
+			return nil
+		}
+
+		r, body, err := s.GetRelease(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		fields := []string{
+			markdown.RenderField("ID", r.ID),
+			markdown.RenderField("Project", r.Project),
+			markdown.RenderField("Status", string(r.Status)),
+		}
+		if r.TargetDate != "" {
+			fields = append(fields, markdown.RenderField("Target date", r.TargetDate))
+		}
+		if r.CutAt != nil {
+			fields = append(fields, markdown.RenderField("Cut", r.CutAt.Format("2006-01-02 15:04:05")))
+		}
+		if r.Changelog != "" {
+			fields = append(fields, markdown.RenderField("Changelog", r.Changelog))
+		}
+		fields = append(fields,
+			markdown.RenderField("Created by", r.CreatedBy),
+			markdown.RenderField("Created", r.CreatedAt.Format("2006-01-02 15:04:05")),
+		)
+
+		fmt.Print(markdown.RenderEntityHeader("Release "+r.Version, fields))
+		if body != "" {
+			rendered, err := markdown.RenderMarkdown(body)
+			if err != nil {
+				return err
+			}
+			fmt.Print(rendered)
+		}
+
+		grouped, err := store.ReleaseTasks(ctx, s, r)
+		if err != nil {
+			return err
+		}
+		var tasks []model.Task
+		for _, ts := range grouped {
+			tasks = append(tasks, ts...)
+		}
+		if len(tasks) > 0 {
+			allTasks, _ := s.AllTaskMap(ctx, r.Project)
+			fmt.Println("\nTasks:")
+			fmt.Println(markdown.RenderTaskTable(tasks, allTasks, depPolicy()))
+		}
+		return nil
+	},
+}
+
+var releaseCutCmd = &cobra.Command{
+	Use:   "cut <id>",
+	Short: "Cut a release once all included tasks are closed, writing a changelog document",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		r, d, err := store.CutRelease(ctx, s, args[0])
+		if err != nil {
+			return err
+		}
+		infof("Cut release %s (%s)\n", r.Version, r.ID)
+		infof("Changelog: %s\n", d.ID)
+		return nil
+	},
+}
+
+func init() {
+	releaseCreateCmd.Flags().StringP("project", "P", "", "project ID")
+	releaseCreateCmd.Flags().String("target-date", "", "target release date (YYYY-MM-DD)")
+	releaseCreateCmd.Flags().String("include", "", "comma-separated epic/task IDs included in the release")
+
+	releaseListCmd.Flags().StringP("project", "P", "", "project ID")
+
+	releaseShowCmd.Flags().Bool("pretty", false, "render with ANSI styling")
+
+	releaseCmd.AddCommand(releaseCreateCmd)
+	releaseCmd.AddCommand(releaseListCmd)
+	releaseCmd.AddCommand(releaseShowCmd)
+	releaseCmd.AddCommand(releaseCutCmd)
+	rootCmd.AddCommand(releaseCmd)
+}
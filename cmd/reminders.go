@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/rogersnm/compass/internal/reminder"
+	"github.com/spf13/cobra"
+)
+
+var remindersCmd = &cobra.Command{
+	Use:   "reminders",
+	Short: "List personal task reminders",
+	Long: `List personal task reminders set with "compass task remind". Reminders
+live in the data directory of this machine and never touch the task.`,
+}
+
+var remindersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List pending reminders, soonest first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		rs, err := reminder.Load(dataDir)
+		if err != nil {
+			return err
+		}
+		if len(rs) == 0 {
+			infof("No reminders.\n")
+			return nil
+		}
+		for _, r := range rs {
+			fmt.Println(reminderLine(ctx, r))
+		}
+		return nil
+	},
+}
+
+var remindersDueCmd = &cobra.Command{
+	Use:   "due",
+	Short: "Print reminders that have come due and clear them",
+	Long: `Print reminders whose time has passed, one per line, and clear them so
+each fires once. It prints nothing when none are due, so it can be polled
+from a shell prompt, cron or a launchd agent. --notify also raises a
+desktop notification for each reminder (notify-send on Linux, osascript
+on macOS).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		rs, err := reminder.Load(dataDir)
+		if err != nil {
+			return err
+		}
+		due, pending := reminder.Due(rs, time.Now())
+		if len(due) == 0 {
+			return nil
+		}
+
+		notify, _ := cmd.Flags().GetBool("notify")
+		for _, r := range due {
+			line := reminderLine(ctx, r)
+			fmt.Println(line)
+			if notify {
+				if err := sendNotification("compass reminder", line); err != nil {
+					slog.Warn("reminder notification failed", "err", err)
+				}
+			}
+		}
+		if keep, _ := cmd.Flags().GetBool("keep"); keep {
+			return nil
+		}
+		return reminder.Save(dataDir, pending)
+	},
+}
+
+// reminderLine formats a reminder with its task's title. Tasks that can't be
+// fetched (deleted, or a store that is offline) show their ID alone.
+func reminderLine(ctx context.Context, r reminder.Reminder) string {
+	line := fmt.Sprintf("%s  %s", r.At.Local().Format("2006-01-02 15:04"), r.Task)
+	if s, err := storeForEntity(ctx, r.Task); err == nil {
+		if t, _, err := s.GetTask(ctx, r.Task); err == nil {
+			line += "  " + t.Title
+		}
+	}
+	if r.Note != "" {
+		line += " (" + r.Note + ")"
+	}
+	return line
+}
+
+// sendNotification is a variable so tests can stub it.
+var sendNotification = func(title, body string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", body, title))
+	case "linux":
+		c = exec.Command("notify-send", title, body)
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+	return c.Run()
+}
+
+func init() {
+	remindersDueCmd.Flags().Bool("notify", false, "also raise a desktop notification for each due reminder")
+	remindersDueCmd.Flags().Bool("keep", false, "don't clear the reminders after printing them")
+
+	remindersCmd.AddCommand(remindersListCmd)
+	remindersCmd.AddCommand(remindersDueCmd)
+	rootCmd.AddCommand(remindersCmd)
+}
@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate reports from task activity",
+}
+
+var reportReleaseCmd = &cobra.Command{
+	Use:   "release --since <tag-or-date>",
+	Short: "Generate release notes from tasks closed since a tag or date",
+	Long: `Collect the project's tasks closed since --since and print them as a
+markdown changelog, grouped by epic (default) or priority. --since is a
+date (YYYY-MM-DD), the version of a cut compass release, or a git tag in
+the current repository. With --save the notes are written to a new
+document in the project instead.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		sinceArg, _ := cmd.Flags().GetString("since")
+		if sinceArg == "" {
+			return fmt.Errorf("--since is required")
+		}
+		since, err := resolveSince(ctx, s, projectID, sinceArg)
+		if err != nil {
+			return err
+		}
+
+		tasks, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: projectID, Type: model.TypeTask, Status: model.StatusClosed})
+		if err != nil {
+			return err
+		}
+		var closed []model.Task
+		for _, t := range tasks {
+			if !t.ClosedTime().Before(since) {
+				closed = append(closed, t)
+			}
+		}
+		sort.Slice(closed, func(i, j int) bool {
+			return closed[i].ClosedTime().Before(closed[j].ClosedTime())
+		})
+
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		groups, err := groupReleaseNotes(ctx, s, closed, groupBy)
+		if err != nil {
+			return err
+		}
+		title := fmt.Sprintf("Changes since %s", sinceArg)
+		summary := fmt.Sprintf("%d task(s) closed in %s since %s.", len(closed), projectID, since.Format("2006-01-02 15:04"))
+		notes := markdown.RenderReleaseNotes(title, summary, groups)
+
+		if save, _ := cmd.Flags().GetBool("save"); save {
+			d, err := s.CreateDocument(ctx, title, projectID, store.DocumentCreateOpts{Body: notes})
+			if err != nil {
+				return err
+			}
+			printCreated(d.ID, "Created document %s (%s)\n", d.Title, d.ID)
+			return nil
+		}
+		fmt.Print(notes)
+		return nil
+	},
+}
+
+// parseSince reads a --since window start as model.ParseWhen does, with a
+// YYYY-MM-DD date meaning local midnight and a duration such as "36h" or
+// "3d" counted back from now.
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if t, ok := model.ParseWhen(since, now, 0, true); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use YYYY-MM-DD, today, yesterday or a duration like 3d", since)
+}
+
+// resolveSince extends parseSince for release notes: the value may also be
+// the version of a cut compass release or a git tag in the current
+// directory.
+func resolveSince(ctx context.Context, s store.Store, projectID, since string) (time.Time, error) {
+	if t, err := parseSince(since, time.Now()); err == nil {
+		return t, nil
+	}
+	if releases, err := s.ListReleases(ctx, projectID); err == nil {
+		for _, r := range releases {
+			if r.Version == since && r.CutAt != nil {
+				return *r.CutAt, nil
+			}
+		}
+	}
+	out, err := exec.Command("git", "log", "-1", "--format=%cI", "refs/tags/"+since).Output()
+	if err == nil {
+		if t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(out))); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("--since %q is not a date (YYYY-MM-DD), a cut release or a git tag", since)
+}
+
+var reportStandupCmd = &cobra.Command{
+	Use:   "standup",
+	Short: "Summarize tasks started, closed and in progress per person",
+	Long: `Summarize, per person, the tasks they closed and started since --since
+(default: yesterday) and the tasks they still have in progress, across all
+cached projects or just --project. Activity comes from each task's status
+history, so changes made before history was recorded are not shown.
+
+--author limits the report to one person; "me" is the current user.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		sinceArg, _ := cmd.Flags().GetString("since")
+		since, err := parseSince(sinceArg, time.Now())
+		if err != nil {
+			return err
+		}
+		author, _ := cmd.Flags().GetString("author")
+		author = resolveMe(author)
+
+		projects := cachedProjects()
+		if p, _ := cmd.Flags().GetString("project"); p != "" {
+			projects = []string{p}
+		}
+		var tasks []model.Task
+		for _, key := range projects {
+			s, err := storeForProject(ctx, key)
+			if err == nil {
+				var pt []model.Task
+				if pt, err = s.ListTasks(ctx, store.TaskFilter{ProjectID: key, Type: model.TypeTask}); err == nil {
+					tasks = append(tasks, pt...)
+					continue
+				}
+			}
+			slog.Warn("skipping project", "project", key, "err", err)
+		}
+
+		entries := standupEntries(tasks, since)
+		if author != "" {
+			var mine []markdown.StandupEntry
+			for _, e := range entries {
+				if e.Person == author {
+					mine = append(mine, e)
+				}
+			}
+			entries = mine
+		}
+		fmt.Println(markdown.RenderStandup(entries))
+		return nil
+	},
+}
+
+// standupEntries attributes each task's status changes since the window
+// start to whoever made them. In-progress tasks belong to whoever last
+// started them, or their creator when there is no record of that.
+func standupEntries(tasks []model.Task, since time.Time) []markdown.StandupEntry {
+	byPerson := map[string]*markdown.StandupEntry{}
+	entry := func(person string) *markdown.StandupEntry {
+		if byPerson[person] == nil {
+			byPerson[person] = &markdown.StandupEntry{Person: person}
+		}
+		return byPerson[person]
+	}
+	for _, t := range tasks {
+		closed, started := map[string]bool{}, map[string]bool{}
+		for _, c := range t.History {
+			if c.At.Before(since) {
+				continue
+			}
+			switch {
+			case c.Status == model.StatusClosed && !closed[c.By]:
+				closed[c.By] = true
+				entry(c.By).Closed = append(entry(c.By).Closed, t)
+			case c.Status == model.StatusInProgress && !started[c.By]:
+				started[c.By] = true
+				entry(c.By).Started = append(entry(c.By).Started, t)
+			}
+		}
+		if t.Status == model.StatusInProgress {
+			owner := t.CreatedBy
+			if c := t.LastChangeTo(model.StatusInProgress); c != nil {
+				owner = c.By
+			}
+			entry(owner).InProgress = append(entry(owner).InProgress, t)
+		}
+	}
+
+	entries := make([]markdown.StandupEntry, 0, len(byPerson))
+	for _, person := range sortedKeys(byPerson) {
+		entries = append(entries, *byPerson[person])
+	}
+	return entries
+}
+
+var reportHeatmapCmd = &cobra.Command{
+	Use:   "heatmap",
+	Short: "Draw a grid of tasks created and closed per day",
+	Long: `Draw a contribution grid of the project's activity over the last
+--weeks weeks: one cell per day, shaded by how many tasks were created and
+closed that day. Closes come from each task's status history, so a task
+closed and reopened several times counts each close; tasks closed before
+history was recorded count once, on their close date.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+		weeks, _ := cmd.Flags().GetInt("weeks")
+		if weeks < 1 {
+			return fmt.Errorf("--weeks must be at least 1")
+		}
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		tasks, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: projectID, Type: model.TypeTask})
+		if err != nil {
+			return err
+		}
+		h := heatmap(tasks)
+		h.End, h.Weeks = time.Now(), weeks
+		fmt.Println(markdown.RenderHeatmap(h))
+		return nil
+	},
+}
+
+// heatmap counts the tasks created and closed on each local day.
+func heatmap(tasks []model.Task) markdown.Heatmap {
+	h := markdown.Heatmap{Created: map[string]int{}, Closed: map[string]int{}}
+	day := func(t time.Time) string { return t.Local().Format(time.DateOnly) }
+	for _, t := range tasks {
+		h.Created[day(t.CreatedAt)]++
+		closes := 0
+		for _, c := range t.History {
+			if c.Status == model.StatusClosed {
+				h.Closed[day(c.At)]++
+				closes++
+			}
+		}
+		if closes == 0 && t.Status == model.StatusClosed {
+			h.Closed[day(t.ClosedTime())]++
+		}
+	}
+	return h
+}
+
+// groupReleaseNotes splits tasks into note sections. Epic sections are
+// ordered by epic ID with unparented tasks last; priority sections run P0
+// to P3 then unprioritized.
+func groupReleaseNotes(ctx context.Context, s store.Store, tasks []model.Task, groupBy string) ([]markdown.NoteGroup, error) {
+	var key func(t model.Task) string
+	var heading func(k string) string
+	switch groupBy {
+	case "epic":
+		key = func(t model.Task) string { return t.Epic }
+		heading = func(k string) string {
+			if k == "" {
+				return "Other changes"
+			}
+			if epic, _, err := s.GetTask(ctx, k); err == nil {
+				return fmt.Sprintf("%s (%s)", epic.Title, epic.ID)
+			}
+			return k
+		}
+	case "priority":
+		key = func(t model.Task) string { return model.FormatPriority(t.Priority) }
+		heading = func(k string) string {
+			if k == "" {
+				return "Unprioritized"
+			}
+			return k
+		}
+	default:
+		return nil, fmt.Errorf("invalid --group-by %q: must be epic or priority", groupBy)
+	}
+
+	byKey := map[string][]model.Task{}
+	for _, t := range tasks {
+		byKey[key(t)] = append(byKey[key(t)], t)
+	}
+	keys := make([]string, 0, len(byKey))
+	for k := range byKey {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i] == "") != (keys[j] == "") {
+			return keys[j] == ""
+		}
+		return keys[i] < keys[j]
+	})
+
+	groups := make([]markdown.NoteGroup, 0, len(keys))
+	for _, k := range keys {
+		groups = append(groups, markdown.NoteGroup{Heading: heading(k), Tasks: byKey[k]})
+	}
+	return groups, nil
+}
+
+func init() {
+	reportReleaseCmd.Flags().StringP("project", "P", "", "project ID")
+	reportReleaseCmd.Flags().String("since", "", "date (YYYY-MM-DD), cut release version or git tag")
+	reportReleaseCmd.Flags().String("group-by", "epic", "group tasks by epic or priority")
+	reportReleaseCmd.Flags().Bool("save", false, "write the notes to a new document in the project instead of stdout")
+
+	reportStandupCmd.Flags().StringP("project", "P", "", "only this project (default: all cached projects)")
+	reportStandupCmd.Flags().String("author", "", `only this person ("me" for the current user)`)
+	reportStandupCmd.Flags().String("since", "yesterday", "window start: YYYY-MM-DD, today, yesterday or a duration like 3d")
+
+	reportHeatmapCmd.Flags().StringP("project", "P", "", "project ID")
+	reportHeatmapCmd.Flags().Int("weeks", 12, "number of weeks to draw")
+
+	reportCmd.AddCommand(reportReleaseCmd)
+	reportCmd.AddCommand(reportStandupCmd)
+	reportCmd.AddCommand(reportHeatmapCmd)
+	rootCmd.AddCommand(reportCmd)
+}
@@ -1,20 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/huh"
 	mtp "github.com/modeltoolsprotocol/go-sdk"
 	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/id"
 	"github.com/rogersnm/compass/internal/repofile"
+	"github.com/rogersnm/compass/internal/rpc"
 	"github.com/rogersnm/compass/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +30,10 @@ var (
 	dataDir string
 	reg     *store.Registry
 	cfg     *config.Config
+	// actorFlag is --as, the identity changes are attributed to.
+	actorFlag string
+	// storeFlag is --store, the store every project is routed to.
+	storeFlag string
 )
 
 func defaultDataDir() string {
@@ -39,60 +49,134 @@ var rootCmd = &cobra.Command{
 	Short:   "Markdown-native task and document tracking",
 	Version: version,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		applyOutputFlags()
+		branchTask = sync.OnceValue(findBranchTask)
+		if err := setupLogging(nil); err != nil {
+			return err
+		}
+
+		// Help and shell completion never need config or stores.
+		switch cmd.Name() {
+		case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return nil
+		}
+		if cmd.Parent() != nil && cmd.Parent().Name() == "completion" {
+			return nil
+		}
+		// migrate reads and upgrades the config itself so it can report.
+		if cmd.Name() == "migrate" || (cmd.Parent() != nil && cmd.Parent().Name() == "migrate") {
+			return nil
+		}
+		// config reads and writes config.yaml itself, so it can repair one
+		// that doesn't load.
+		if cmd.Parent() != nil && cmd.Parent().Name() == "config" && cmd.Parent().Parent() == cmd.Root() {
+			return nil
+		}
+
 		if err := os.MkdirAll(dataDir, 0755); err != nil {
 			return fmt.Errorf("creating data directory: %w", err)
 		}
 
+		if err := openDebugLog(); err != nil {
+			return err
+		}
+		store.SetActor(actorFlag)
+
+		var changes []string
 		var err error
-		cfg, err = config.Load(dataDir)
+		cfg, changes, err = config.LoadAndUpgrade(dataDir)
 		if err != nil {
 			return fmt.Errorf("loading config: %w", err)
 		}
-
-		// Migrate v1 config to v2 on disk
-		if cfg.Version == 2 && cfg.Mode == "" && cfg.Cloud == nil {
-			// already v2, good
-		} else if cfg.Version < 2 && (cfg.Mode != "" || cfg.Cloud != nil || cfg.DefaultProject != "") {
-			// Migration happened in Load; persist it
+		if err := setupLogging(cfg); err != nil {
+			return err
+		}
+		if err := applyRenderFlags(cfg); err != nil {
+			return err
+		}
+		if len(changes) > 0 && !dryRun {
 			if err := config.Save(dataDir, cfg); err != nil {
-				return fmt.Errorf("saving migrated config: %w", err)
+				return fmt.Errorf("saving upgraded config: %w", err)
+			}
+			if !quiet {
+				fmt.Fprintln(os.Stderr, "Upgraded config.yaml:")
+				printChanges(os.Stderr, changes)
 			}
 		}
 
-		// Build registry
+		// Build registry. Cloud stores are constructed on first use so
+		// commands that only touch the local store never build HTTP clients.
 		reg = store.NewRegistry(cfg, dataDir)
+		if dryRun {
+			reg.SetDryRun(os.Stdout)
+		}
 
 		if cfg.LocalEnabled {
-			reg.Add("local", store.NewLocal(dataDir))
+			reg.Add("local", openLocal())
 		}
 		for storeName, sc := range cfg.Stores {
-			reg.Add(storeName, store.NewCloudStoreWithBase(sc.URL(), sc.APIKey))
+			reg.AddLazy(storeName, func() (store.Store, error) {
+				return openCloud(storeName, sc)
+			})
 		}
+		loadNotifier()
+		noticeUpdate(cmd)
 
 		// Store commands work without configured stores
 		if cmd.Name() == "store" || (cmd.Parent() != nil && cmd.Parent().Name() == "store") {
 			return nil
 		}
-		// go and claude-init don't need stores
-		if cmd.Name() == "go" || cmd.Name() == "claude-init" {
-			return nil
+		if err := applyStoreOverride(); err != nil {
+			return err
 		}
-		// Config commands (legacy, kept for backwards compat during transition)
-		if cmd.Name() == "config" || (cmd.Parent() != nil && cmd.Parent().Name() == "config") {
+		// go, claude-init, merge-file, upgrade and telemetry don't need stores
+		if cmd.Name() == "go" || cmd.Name() == "claude-init" || cmd.Name() == "merge-file" || cmd == upgradeCmd || cmd.Parent() == telemetryCmd {
 			return nil
 		}
 
 		// First-run setup if no stores configured
 		if reg.IsEmpty() {
+			if rpcMode, _ := cmd.Flags().GetBool("rpc"); rpcMode {
+				return fmt.Errorf("no stores configured; run: compass store add local")
+			}
 			return runSetupPrompt(cmd)
 		}
-		return nil
+		if cmd.Name() == "save" && cmd.Parent() != nil && cmd.Parent().Name() == "view" {
+			return nil // saved as typed, and resolved each time the view runs
+		}
+		return resolveRefs(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if rpcMode, _ := cmd.Flags().GetBool("rpc"); rpcMode {
+			return rpc.NewServer(reg).Serve(cmd.Context(), os.Stdin, os.Stdout)
+		}
+		return cmd.Help()
 	},
-	SilenceUsage: true,
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		autoCommit(cmd)
+		waitUpdateCheck()
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true, // Execute prints them, as JSON under --json
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&dataDir, "data-dir", defaultDataDir(), "data directory path")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable ANSI colors and styling (also set by a non-empty NO_COLOR)")
+	rootCmd.PersistentFlags().IntVar(&renderWidth, "width", 0, "wrap rendered markdown bodies at this column (default: the terminal width)")
+	rootCmd.PersistentFlags().StringVar(&renderStyle, "style", "", "markdown body style: auto, dark, light, notty or a glamour .json style file")
+	rootCmd.PersistentFlags().BoolVar(&fullTables, "full", false, "show long titles in tables whole instead of truncating them to the terminal width")
+	rootCmd.PersistentFlags().BoolVar(&wrapTables, "wrap", false, "wrap long titles in tables to the terminal width instead of truncating them")
+	rootCmd.MarkFlagsMutuallyExclusive("full", "wrap")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress confirmation messages; create commands print only the new ID")
+	rootCmd.PersistentFlags().StringVar(&debugMode, "debug", "", "log cloud store requests to stderr, or with --debug=file to debug.log in the data directory (also set by COMPASS_DEBUG)")
+	rootCmd.PersistentFlags().Lookup("debug").NoOptDefVal = "stderr"
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the changes a command would make (files written or removed, API requests) without making them")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "log debug, info, warn or error messages and above to stderr (default warn; also set by COMPASS_LOG_LEVEL)")
+	rootCmd.PersistentFlags().StringVar(&actorFlag, "as", "", "record changes as made by this actor, e.g. an agent or bot (also set by COMPASS_ACTOR)")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "fail instead of prompting for input (automatic when stdin isn't a terminal)")
+	rootCmd.PersistentFlags().StringVar(&storeFlag, "store", "", "route every project to this store, ignoring the project cache, and create new projects on it (also set by COMPASS_STORE)")
+	rootCmd.Flags().Bool("rpc", false, "serve JSON-RPC 2.0 requests on stdin, one per line")
 
 	mtpOpts := &mtp.DescribeOptions{
 		Commands: map[string]*mtp.CommandAnnotation{
@@ -172,6 +256,15 @@ func init() {
 					{Description: "Show all ready tasks", Command: "compass task ready --project AUTH --all"},
 				},
 			},
+			"task claim": {
+				Stdout: &mtp.IODescriptor{
+					ContentType: "text/plain",
+					Description: "ID and title of the claimed task, or \"No ready tasks.\"",
+				},
+				Examples: []mtp.Example{
+					{Description: "Claim the next ready task (use instead of ready+start when several agents share a queue)", Command: "compass task claim --project AUTH"},
+				},
+			},
 			"task update": {
 				Stdin: &mtp.IODescriptor{
 					ContentType: "text/markdown",
@@ -215,6 +308,47 @@ func init() {
 					Description: "ASCII tree visualization of the task dependency DAG",
 				},
 			},
+			"epic plan": {
+				Stdin: &mtp.IODescriptor{
+					ContentType: "text/markdown",
+					Description: "Markdown outline: H1 headings are epics, list items are tasks, optional trailing (after: N, Title, KEY-TXXXXX) dependency markers",
+				},
+				Examples: []mtp.Example{
+					{Description: "Create an epic and its tasks from a plan", Command: "printf '# Login\\n- Schema\\n- API (after: 1)\\n' | compass epic plan --project AUTH"},
+				},
+			},
+			"release create": {
+				Stdin: &mtp.IODescriptor{
+					ContentType: "text/markdown",
+					Description: "Markdown release notes body",
+				},
+				Examples: []mtp.Example{
+					{Description: "Create a release covering an epic and a task", Command: "compass release create 1.2.0 --project AUTH --target-date 2026-03-01 --include AUTH-TXXXXX,AUTH-TYYYYY"},
+				},
+			},
+			"release cut": {
+				Stdout: &mtp.IODescriptor{
+					ContentType: "text/plain",
+					Description: "Cut confirmation and the ID of the generated changelog document",
+				},
+				Examples: []mtp.Example{
+					{Description: "Cut a release once all its tasks are closed", Command: "compass release cut AUTH-RXXXXX"},
+				},
+			},
+			"adr new": {
+				Stdin: &mtp.IODescriptor{
+					ContentType: "text/markdown",
+					Description: "Markdown body for the ADR; a Context / Decision / Consequences template is used when empty",
+				},
+				Examples: []mtp.Example{
+					{Description: "Propose a new architecture decision", Command: "compass adr new \"Use Postgres\" --project AUTH"},
+				},
+			},
+			"adr supersede": {
+				Examples: []mtp.Example{
+					{Description: "Replace an accepted decision with a newer record", Command: "compass adr supersede AUTH-DXXXXX AUTH-DYYYYY"},
+				},
+			},
 			"search": {
 				Stdout: &mtp.IODescriptor{
 					ContentType: "text/plain",
@@ -275,14 +409,31 @@ func init() {
 	mtp.WithDescribe(rootCmd, mtpOpts)
 }
 
+// Execute runs the command under a context that Ctrl-C cancels, stopping
+// cloud requests in flight. A second Ctrl-C kills the process as usual.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	if err != nil {
+		printError(os.Stderr, cmd, err)
+	}
+	recordTelemetry(cmd, time.Since(start), err)
+	return err
 }
 
 const signupURL = "https://compasscloud.io/signup"
 
 // runSetupPrompt presents the interactive first-run prompt.
 func runSetupPrompt(cmd *cobra.Command) error {
+	if err := canPrompt("no stores configured; run 'compass store add local' or 'compass store add <hostname> --api-key KEY'"); err != nil {
+		return err
+	}
 	var choice string
 	err := huh.NewSelect[string]().
 		Title("Welcome to Compass! No stores configured.").
@@ -313,10 +464,10 @@ func runSetupPrompt(cmd *cobra.Command) error {
 		cfg.Version = 2
 		cfg.LocalEnabled = true
 		cfg.DefaultStore = "local"
-		if err := config.Save(dataDir, cfg); err != nil {
+		if err := saveConfig(cfg); err != nil {
 			return fmt.Errorf("saving config: %w", err)
 		}
-		reg.Add("local", store.NewLocal(dataDir))
+		reg.Add("local", openLocal())
 		reg.SetDefault("local")
 		fmt.Println("Local mode enabled. Data will be stored in " + dataDir)
 		return nil
@@ -333,8 +484,12 @@ func runDeviceFlowLogin(storeName string) error {
 		return fmt.Errorf("store %q not found in config", storeName)
 	}
 	server := sc.URL()
+	client, err := store.NewHTTPClient(sc)
+	if err != nil {
+		return err
+	}
 
-	resp, err := http.Post(server+"/auth/device", "application/json", nil)
+	resp, err := client.Post(server+"/auth/device", "application/json", nil)
 	if err != nil {
 		return fmt.Errorf("requesting device code: %w", err)
 	}
@@ -385,7 +540,7 @@ func runDeviceFlowLogin(storeName string) error {
 	for time.Now().Before(deadline) {
 		time.Sleep(interval)
 
-		tokenResp, err := pollToken(server, d.DeviceCode)
+		tokenResp, err := pollToken(client, server, d.DeviceCode)
 		if err != nil {
 			return err
 		}
@@ -395,7 +550,7 @@ func runDeviceFlowLogin(storeName string) error {
 		}
 
 		if tokenResp.Status == "authorized" {
-			sc.APIKey = tokenResp.APIKey
+			sc.APIKey, sc.APIKeyCmd = tokenResp.APIKey, ""
 			if cfg.Stores == nil {
 				cfg.Stores = make(map[string]config.CloudStoreConfig)
 			}
@@ -404,11 +559,15 @@ func runDeviceFlowLogin(storeName string) error {
 				cfg.DefaultStore = storeName
 			}
 			cfg.Version = 2
-			if err := config.Save(dataDir, cfg); err != nil {
+			if err := saveConfig(cfg); err != nil {
 				return fmt.Errorf("saving config: %w", err)
 			}
 
-			reg.Add(storeName, store.NewCloudStoreWithBase(sc.URL(), sc.APIKey))
+			cs, err := openCloud(storeName, sc)
+			if err != nil {
+				return err
+			}
+			reg.Add(storeName, cs)
 			if reg.DefaultName() == "" {
 				reg.SetDefault(storeName)
 			}
@@ -427,74 +586,15 @@ func runDeviceFlowLogin(storeName string) error {
 	return fmt.Errorf("authorization timed out")
 }
 
-var configCmd = &cobra.Command{
-	Use:        "config",
-	Short:      "Configure Compass (deprecated, use 'compass store')",
-	Deprecated: "use 'compass store' instead",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSetupPrompt(cmd)
-	},
-}
-
-var configLoginCmd = &cobra.Command{
-	Use:        "login",
-	Short:      "Authenticate with Compass Cloud (deprecated, use 'compass store add')",
-	Deprecated: "use 'compass store add <hostname>' instead",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return runDeviceFlowLogin("compasscloud.io")
-	},
-}
-
-var configLogoutCmd = &cobra.Command{
-	Use:        "logout",
-	Short:      "Log out of Compass Cloud (deprecated, use 'compass store remove')",
-	Deprecated: "use 'compass store remove <hostname>' instead",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		delete(cfg.Stores, "compasscloud.io")
-		if cfg.DefaultStore == "compasscloud.io" {
-			cfg.DefaultStore = ""
-		}
-		if err := config.Save(dataDir, cfg); err != nil {
-			return fmt.Errorf("saving config: %w", err)
-		}
-		fmt.Println("Logged out")
-		return nil
-	},
-}
-
-var configStatusCmd = &cobra.Command{
-	Use:        "status",
-	Short:      "Show current configuration (deprecated, use 'compass store list')",
-	Deprecated: "use 'compass store list' instead",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if cfg.LocalEnabled {
-			fmt.Println("Local store: enabled")
-			fmt.Println("Data: " + dataDir)
-		}
-		for name, sc := range cfg.Stores {
-			if name == sc.Hostname {
-				fmt.Printf("Cloud store: %s\n", name)
-			} else {
-				fmt.Printf("Cloud store: %s (%s)\n", name, sc.Hostname)
-			}
-			fmt.Printf("  API key: %s...\n", sc.APIKey[:min(8, len(sc.APIKey))])
-		}
-		if cfg.DefaultStore != "" {
-			fmt.Printf("Default store: %s\n", cfg.DefaultStore)
-		}
-		return nil
-	},
-}
-
 type tokenResult struct {
 	Status  string
 	APIKey  string
 	OrgName string
 }
 
-func pollToken(server, deviceCode string) (*tokenResult, error) {
+func pollToken(client *http.Client, server, deviceCode string) (*tokenResult, error) {
 	body := fmt.Sprintf(`{"device_code":"%s"}`, deviceCode)
-	resp, err := http.Post(
+	resp, err := client.Post(
 		server+"/auth/device/token",
 		"application/json",
 		strings.NewReader(body),
@@ -536,7 +636,8 @@ func pollToken(server, deviceCode string) (*tokenResult, error) {
 	return result, nil
 }
 
-func openBrowser(url string) {
+// openBrowser and copyToClipboard are variables so tests can stub them.
+var openBrowser = func(url string) {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
 	case "darwin":
@@ -551,31 +652,93 @@ func openBrowser(url string) {
 	}
 }
 
-func init() {
-	configCmd.AddCommand(configLoginCmd)
-	configCmd.AddCommand(configLogoutCmd)
-	configCmd.AddCommand(configStatusCmd)
-	rootCmd.AddCommand(configCmd)
+var copyToClipboard = func(text string) error {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		candidates = [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(c[0], c[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return fmt.Errorf("no clipboard tool found (install one of: pbcopy, wl-copy, xclip, xsel)")
 }
 
 // storeForProject resolves a project key to its store.
-func storeForProject(projectKey string) (store.Store, error) {
-	s, _, err := reg.ForProject(projectKey)
-	return s, err
+func storeForProject(ctx context.Context, projectKey string) (store.Store, error) {
+	s, _, err := reg.ForProject(ctx, projectKey)
+	return withNotifications(s), err
 }
 
 // storeForEntity resolves an entity ID to its store.
-func storeForEntity(entityID string) (store.Store, error) {
-	s, _, err := reg.ForEntity(entityID)
-	return s, err
+func storeForEntity(ctx context.Context, entityID string) (store.Store, error) {
+	s, _, err := reg.ForEntity(ctx, entityID)
+	return withNotifications(s), err
+}
+
+// resolveMe maps "me" to the current user, for flags that name a person.
+func resolveMe(name string) string {
+	if name == "me" {
+		return store.CurrentUser()
+	}
+	return name
 }
 
-// resolveProject returns the project ID from the flag, repo-local file, or global default.
+// fanOutTimeout is the per-store timeout for commands that query every store.
+var fanOutTimeout = store.FanOutTimeout
+
+// warnUnreachable logs a warning naming stores that were skipped
+// during a fan-out, so partial output is not mistaken for complete output.
+func warnUnreachable(errs []store.StoreError) {
+	if len(errs) == 0 {
+		return
+	}
+	slog.Warn(fmt.Sprintf("showing partial results, %d store(s) unreachable", len(errs)))
+	for _, e := range errs {
+		slog.Warn("unreachable", "store", e.Store, "err", e.Err)
+	}
+}
+
+// applyStoreOverride routes every project to the store named by --store,
+// or COMPASS_STORE when the flag is unset. Commands with a --store flag of
+// their own, such as project create, read it themselves.
+func applyStoreOverride() error {
+	name := storeFlag
+	if name == "" {
+		name = os.Getenv("COMPASS_STORE")
+	}
+	if name == "" {
+		return nil
+	}
+	return reg.Override(name)
+}
+
+// resolveProject returns the project ID from the flag, COMPASS_PROJECT,
+// the task named in the current git branch, or the repo-local file. A
+// branch only counts when its task's project is in the project cache, so
+// branch names that merely look like IDs fall through to the repo file.
 func resolveProject(cmd *cobra.Command) (string, error) {
 	p, _ := cmd.Flags().GetString("project")
 	if p != "" {
 		return p, nil
 	}
+	if p := os.Getenv("COMPASS_PROJECT"); p != "" {
+		return reg.Qualify(p)
+	}
+	if t := branchTask(); t != "" {
+		if key, err := id.ProjectKeyFrom(t); err == nil && projectCached(key) {
+			return key, nil
+		}
+	}
 	if cwd, err := os.Getwd(); err == nil {
 		if rp, _, _ := repofile.Find(cwd); rp != "" {
 			return rp, nil
@@ -583,3 +746,14 @@ func resolveProject(cmd *cobra.Command) (string, error) {
 	}
 	return "", fmt.Errorf("--project is required (or link a repo with: compass project link)")
 }
+
+// projectCached reports whether the project cache maps key on any store,
+// directly, as an alias or among several stores.
+func projectCached(key string) bool {
+	for _, m := range config.Mappings(cfg.Projects) {
+		if m.Key == key {
+			return true
+		}
+	}
+	return false
+}
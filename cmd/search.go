@@ -1,30 +1,75 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"sort"
+	"strings"
 
+	"github.com/rogersnm/compass/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var searchCmd = &cobra.Command{
 	Use:   "search <query>",
 	Short: "Search across all entities",
-	Args:  cobra.ExactArgs(1),
+	Long: `Search titles and bodies of projects, epics, tasks and documents.
+
+The query can also filter tasks by field, in which case only tasks are
+returned:
+
+  compass search 'status:open priority<=1 assignee:alice "oauth flow"'
+
+Fields are status, type, project, epic, assignee, priority and due, joined
+to a value with ":" (or "=") or "!=". Priority and due also take <, <=, >
+and >=. Repeating a field with ":" matches any of its values. Other words
+and "quoted phrases" must all appear in the task; quote text containing
+a colon.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		projectID, _ := cmd.Flags().GetString("project")
+		q, err := store.ParseQuery(strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		if projectID != "" {
+			q.Filter.ProjectID = projectID
+		}
+		opts := store.SearchOpts{ProjectID: q.Filter.ProjectID}
+		opts.Regex, _ = cmd.Flags().GetBool("regex")
+		opts.TitleOnly, _ = cmd.Flags().GetBool("title-only")
+		opts.BodyOnly, _ = cmd.Flags().GetBool("body-only")
+		opts.Type, _ = cmd.Flags().GetString("type")
+		if opts.Type == "doc" {
+			opts.Type = "document"
+		}
+		if q.HasFilters() && opts.Type != "" && opts.Type != "task" {
+			return fmt.Errorf("field filters only match tasks; drop --type %s", opts.Type)
+		}
+
+		// Plain text searches every kind of entity; field filters narrow
+		// the search to tasks.
+		search := func(ctx context.Context, s store.Store) ([]store.SearchResult, error) {
+			return s.Search(ctx, strings.Join(q.Text, " "), opts)
+		}
+		if q.HasFilters() {
+			search = func(ctx context.Context, s store.Store) ([]store.SearchResult, error) {
+				return store.QueryTasks(ctx, s, q, opts)
+			}
+		}
 
 		type result struct {
 			typ, id, title, snippet string
 		}
 		var results []result
 
-		if projectID != "" {
-			s, err := storeForProject(projectID)
+		if q.Filter.ProjectID != "" {
+			s, err := storeForProject(ctx, q.Filter.ProjectID)
 			if err != nil {
 				return err
 			}
-			sr, err := s.Search(args[0], projectID)
+			sr, err := search(ctx, s)
 			if err != nil {
 				return err
 			}
@@ -32,14 +77,14 @@ var searchCmd = &cobra.Command{
 				results = append(results, result{r.Type, r.ID, r.Title, r.Snippet})
 			}
 		} else {
-			// Fan out across all stores
-			for name, s := range reg.All() {
-				sr, err := s.Search(args[0], "")
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "warning: %s: %v\n", name, err)
-					continue
-				}
-				for _, r := range sr {
+			only, _ := cmd.Flags().GetString("only-store")
+			byStore, errs, err := store.FanOut(ctx, reg, only, fanOutTimeout, search)
+			if err != nil {
+				return err
+			}
+			warnUnreachable(errs)
+			for _, name := range sortedKeys(byStore) {
+				for _, r := range byStore[name] {
 					results = append(results, result{r.Type, r.ID, r.Title, r.Snippet})
 				}
 			}
@@ -82,7 +127,23 @@ func capitalize(s string) string {
 	return string(s[0]-32) + s[1:]
 }
 
+// sortedKeys returns a fan-out result map's store names in order, so output
+// does not depend on which store answered first.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func init() {
 	searchCmd.Flags().StringP("project", "P", "", "filter by project")
+	searchCmd.Flags().String("only-store", "", "search only this store (\"local\" or hostname)")
+	searchCmd.Flags().Bool("regex", false, "treat the text as a case-insensitive regular expression")
+	searchCmd.Flags().Bool("title-only", false, "match titles only")
+	searchCmd.Flags().Bool("body-only", false, "match bodies only")
+	searchCmd.Flags().String("type", "", "limit results to task, doc or project")
 	rootCmd.AddCommand(searchCmd)
 }
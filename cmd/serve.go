@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve read-only feeds and metrics over HTTP",
+	Long: `Serve read-only feeds over HTTP on a local address:
+
+  /calendar.ics?project=AUTH   iCalendar feed of due dates and release
+                               targets; repeat project or omit it for every
+                               cached project
+  /metrics?project=AUTH        Prometheus gauges per project: tasks by
+                               status, blocked tasks and the ready queue
+
+Point a calendar app's "subscribe by URL" at the feed to keep deadlines in
+sync.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		infof("Serving on http://%s\n", addr)
+		return http.ListenAndServe(addr, logRequests(serveMux()))
+	},
+}
+
+// logRequests logs each request at info level, and failed ones as
+// warnings, so --log-level info gives an access log.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		level := slog.LevelInfo
+		if rec.status >= 500 {
+			level = slog.LevelWarn
+		}
+		slog.Log(r.Context(), level, "request", "method", r.Method, "path", r.URL.RequestURI(),
+			"status", rec.status, "duration", time.Since(start).Round(time.Millisecond))
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// serveMu serializes handlers: the registry and config are not safe for
+// concurrent use.
+var serveMu sync.Mutex
+
+func serveMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /calendar.ics", serveCalendar)
+	mux.HandleFunc("GET /metrics", serveMetrics)
+	return mux
+}
+
+func serveCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	projects := r.URL.Query()["project"]
+	if len(projects) == 0 {
+		projects = cachedProjects()
+	}
+	feed, err := calendarFeed(ctx, projects)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", strings.Join(projects, "-")+".ics"))
+	w.Write(feed)
+}
+
+func init() {
+	serveCmd.Flags().String("addr", "127.0.0.1:7600", "address to listen on")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+)
+
+// autoSlug is the --slug value that makes a slug from the title.
+const autoSlug = "auto"
+
+const slugUsage = `slug to refer to it by as KEY/slug, "auto" to make one from the title`
+
+// slugFlag returns the slug --slug asks for. "auto" makes one from title,
+// suffixed if needed to be free in projectID; selfID, the entity being
+// updated, may keep its own.
+func slugFlag(ctx context.Context, s store.Store, slug, projectID, title, selfID string) (string, error) {
+	if slug != autoSlug {
+		return slug, nil
+	}
+	base := model.Slugify(title)
+	if base == "" {
+		return "", fmt.Errorf("can't make a slug from %q; pass one with --slug", title)
+	}
+	return store.FreeSlug(ctx, s, projectID, base, selfID)
+}
@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/charmbracelet/huh"
 	"github.com/rogersnm/compass/internal/config"
@@ -20,6 +27,7 @@ var storeAddCmd = &cobra.Command{
 	Short: "Add a store (\"local\" or cloud hostname)",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		arg := args[0]
 
 		if arg == "local" {
@@ -28,23 +36,23 @@ var storeAddCmd = &cobra.Command{
 			if cfg.DefaultStore == "" {
 				cfg.DefaultStore = "local"
 			}
-			if err := config.Save(dataDir, cfg); err != nil {
+			if err := saveConfig(cfg); err != nil {
 				return fmt.Errorf("saving config: %w", err)
 			}
-			reg.Add("local", store.NewLocal(dataDir))
+			reg.Add("local", openLocal())
 			if reg.DefaultName() == "" {
 				reg.SetDefault("local")
 			}
-			fmt.Println("Local store enabled. Data will be stored in " + dataDir)
+			infof("Local store enabled. Data will be stored in %s\n", dataDir)
 
 			// Discover existing local projects
 			s, _ := reg.Get("local")
-			projects, err := s.ListProjects()
+			projects, err := s.ListProjects(ctx)
 			if err == nil && len(projects) > 0 {
 				for _, p := range projects {
 					reg.CacheProject(p.ID, "local")
 				}
-				fmt.Printf("Discovered %d local project(s)\n", len(projects))
+				infof("Discovered %d local project(s)\n", len(projects))
 			}
 			return nil
 		}
@@ -61,6 +69,9 @@ var storeAddCmd = &cobra.Command{
 
 		// Handle name collision
 		if _, exists := cfg.Stores[storeName]; exists {
+			if err := canPrompt(fmt.Sprintf("store %q already exists; pick another with --name, or update its key with 'compass store login %s'", storeName, storeName)); err != nil {
+				return err
+			}
 			var choice string
 			if err := huh.NewSelect[string]().
 				Title(fmt.Sprintf("Store %q already exists.", storeName)).
@@ -92,11 +103,24 @@ var storeAddCmd = &cobra.Command{
 		apiKey, _ := cmd.Flags().GetString("api-key")
 		path, _ := cmd.Flags().GetString("path")
 		protocol, _ := cmd.Flags().GetString("protocol")
+		proxy, _ := cmd.Flags().GetString("proxy")
+		caFile, _ := cmd.Flags().GetString("ca-file")
+		insecure, _ := cmd.Flags().GetBool("insecure-skip-verify")
 
 		sc := config.CloudStoreConfig{
-			Hostname: hostname,
-			Path:     path,
-			Protocol: protocol,
+			Hostname:           hostname,
+			Path:               path,
+			Protocol:           protocol,
+			Proxy:              proxy,
+			CAFile:             caFile,
+			InsecureSkipVerify: insecure,
+		}
+		// Validate network settings before any request is made.
+		if _, err := store.NewHTTPClient(sc); err != nil {
+			return err
+		}
+		if insecure {
+			slog.Warn("TLS certificate verification is disabled for this store")
 		}
 
 		if apiKey != "" {
@@ -112,7 +136,7 @@ var storeAddCmd = &cobra.Command{
 				return err
 			}
 			// runDeviceFlowLogin updates cfg.Stores[storeName] with the API key
-			return fetchProjectsInteractive(storeName)
+			return fetchProjectsInteractive(ctx, storeName)
 		}
 
 		if cfg.Stores == nil {
@@ -123,22 +147,26 @@ var storeAddCmd = &cobra.Command{
 		if cfg.DefaultStore == "" {
 			cfg.DefaultStore = storeName
 		}
-		if err := config.Save(dataDir, cfg); err != nil {
+		if err := saveConfig(cfg); err != nil {
 			return fmt.Errorf("saving config: %w", err)
 		}
 
-		reg.Add(storeName, store.NewCloudStoreWithBase(sc.URL(), sc.APIKey))
+		cs, err := openCloud(storeName, sc)
+		if err != nil {
+			return err
+		}
+		reg.Add(storeName, cs)
 		if reg.DefaultName() == "" {
 			reg.SetDefault(storeName)
 		}
 
 		if storeName == hostname {
-			fmt.Printf("Added cloud store '%s'\n", storeName)
+			infof("Added cloud store '%s'\n", storeName)
 		} else {
-			fmt.Printf("Added cloud store '%s' (%s)\n", storeName, hostname)
+			infof("Added cloud store '%s' (%s)\n", storeName, hostname)
 		}
 
-		return fetchProjectsInteractive(storeName)
+		return fetchProjectsInteractive(ctx, storeName)
 	},
 }
 
@@ -157,11 +185,16 @@ var storeListCmd = &cobra.Command{
 			if name == cfg.DefaultStore {
 				def = "*"
 			}
-			hostname := ""
+			hostname, org := "", ""
 			if sc, ok := cfg.Stores[name]; ok {
 				hostname = sc.Hostname
+				org = sc.Org
+			}
+			ro := ""
+			if cfg.IsReadOnly(name) {
+				ro = "yes"
 			}
-			rows[i] = []string{name, hostname, def}
+			rows[i] = []string{name, hostname, org, def, ro}
 		}
 		fmt.Println(markdown.RenderStoreTable(rows))
 		return nil
@@ -177,15 +210,20 @@ var storeRemoveCmd = &cobra.Command{
 		force, _ := cmd.Flags().GetBool("force")
 
 		// Count affected projects
-		var affected []string
-		for key, storeName := range cfg.Projects {
-			if storeName == name {
-				affected = append(affected, key)
+		var affected []config.ProjectMapping
+		var keys []string
+		for _, m := range config.Mappings(cfg.Projects) {
+			if m.Store == name {
+				affected = append(affected, m)
+				keys = append(keys, m.Entry)
 			}
 		}
 
 		if len(affected) > 0 && !force {
-			msg := fmt.Sprintf("This will remove %d project mapping(s) (%s). Continue?", len(affected), joinKeys(affected))
+			if err := canPrompt(fmt.Sprintf("removing %s drops %d project mapping(s); pass --force to go ahead", name, len(affected))); err != nil {
+				return err
+			}
+			msg := fmt.Sprintf("This will remove %d project mapping(s) (%s). Continue?", len(affected), joinKeys(keys))
 			var confirm bool
 			if err := huh.NewConfirm().Title(msg).Value(&confirm).Run(); err != nil || !confirm {
 				return fmt.Errorf("removal cancelled")
@@ -199,18 +237,18 @@ var storeRemoveCmd = &cobra.Command{
 		}
 
 		// Prune project mappings
-		for _, key := range affected {
-			delete(cfg.Projects, key)
+		for _, m := range affected {
+			config.Uncache(cfg.Projects, m)
 		}
 
 		if cfg.DefaultStore == name {
 			cfg.DefaultStore = ""
 		}
 
-		if err := config.Save(dataDir, cfg); err != nil {
+		if err := saveConfig(cfg); err != nil {
 			return fmt.Errorf("saving config: %w", err)
 		}
-		fmt.Printf("Removed store %s\n", name)
+		infof("Removed store %s\n", name)
 		return nil
 	},
 }
@@ -226,10 +264,139 @@ var storeSetDefaultCmd = &cobra.Command{
 		}
 		cfg.DefaultStore = name
 		reg.SetDefault(name)
-		if err := config.Save(dataDir, cfg); err != nil {
+		if err := saveConfig(cfg); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		infof("Default store set to %s\n", name)
+		return nil
+	},
+}
+
+var storeLoginCmd = &cobra.Command{
+	Use:   "login <name>",
+	Short: "Re-authenticate an existing cloud store",
+	Long: `Replace the API key of an existing cloud store, keeping its name, hostname,
+path, protocol, and other settings. Runs the device flow unless --api-key is
+given. Use this when commands fail with "API key expired or revoked".`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if name == "local" {
+			return fmt.Errorf("the local store does not use an API key")
+		}
+		sc, ok := cfg.Stores[name]
+		if !ok {
+			return fmt.Errorf("store %q not configured", name)
+		}
+
+		apiKey, _ := cmd.Flags().GetString("api-key")
+		if apiKey == "" {
+			return runDeviceFlowLogin(name)
+		}
+
+		sc.APIKey, sc.APIKeyCmd = apiKey, ""
+		cfg.Stores[name] = sc
+		if err := saveConfig(cfg); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		cs, err := openCloud(name, sc)
+		if err != nil {
+			return err
+		}
+		reg.Add(name, cs)
+		infof("Updated API key for store %s\n", name)
+		return nil
+	},
+}
+
+var storeOrgsCmd = &cobra.Command{
+	Use:   "orgs <name>",
+	Short: "List the organizations available to a cloud store's API key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		name := args[0]
+		sc, ok := cfg.Stores[name]
+		if !ok {
+			return fmt.Errorf("cloud store %q not configured", name)
+		}
+		cs, err := openCloud(name, sc)
+		if err != nil {
+			return err
+		}
+		orgs, err := cs.ListOrgs(ctx)
+		if err != nil {
+			return err
+		}
+		rows := make([][]string, len(orgs))
+		for i, o := range orgs {
+			active := ""
+			if o.Slug == sc.Org || (sc.Org == "" && o.Default) {
+				active = "*"
+			}
+			rows[i] = []string{o.Slug, o.Name, o.Role, active}
+		}
+		fmt.Println(markdown.RenderOrgTable(rows))
+		return nil
+	},
+}
+
+var storeUseOrgCmd = &cobra.Command{
+	Use:   "use-org <name> [slug]",
+	Short: "Scope a cloud store's requests to an organization",
+	Long: `Scope every request to a cloud store to one of its organizations (sent as
+the X-Org-Slug header). The slug is checked against "compass store orgs".
+Use --clear to go back to the API key's default organization.
+
+Projects cached against the store may belong to the previous organization;
+run "compass store fetch --store <name>" afterwards to refresh them.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		name := args[0]
+		sc, ok := cfg.Stores[name]
+		if !ok {
+			return fmt.Errorf("cloud store %q not configured", name)
+		}
+		clearOrg, _ := cmd.Flags().GetBool("clear")
+		if clearOrg == (len(args) == 2) {
+			return fmt.Errorf("pass an org slug or --clear")
+		}
+
+		if clearOrg {
+			sc.Org = ""
+		} else {
+			slug := args[1]
+			cs, err := openCloud(name, sc)
+			if err != nil {
+				return err
+			}
+			orgs, err := cs.ListOrgs(ctx)
+			if err != nil {
+				return err
+			}
+			found := false
+			for _, o := range orgs {
+				if o.Slug == slug {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("org %q not found on store %s (see: compass store orgs %s)", slug, name, name)
+			}
+			sc.Org = slug
+		}
+
+		cfg.Stores[name] = sc
+		if err := saveConfig(cfg); err != nil {
 			return fmt.Errorf("saving config: %w", err)
 		}
-		fmt.Printf("Default store set to %s\n", name)
+		if sc.Org == "" {
+			infof("Store %s uses its default organization\n", name)
+		} else {
+			infof("Store %s scoped to organization %s\n", name, sc.Org)
+		}
 		return nil
 	},
 }
@@ -237,40 +404,287 @@ var storeSetDefaultCmd = &cobra.Command{
 var storeFetchCmd = &cobra.Command{
 	Use:   "fetch",
 	Short: "Fetch and cache projects from stores",
+	Long: `Fetch projects from stores and add them to the project cache. With
+--prune, cached projects that their store no longer has are dropped first.
+Projects cached against a store that is no longer configured are reported.
+
+When a store has a project whose key is already cached against another
+store, fetch asks what to do, or with --all does as --on-conflict says:
+
+  skip   keep the existing mapping (the default with --all)
+  remap  map the key to this store instead
+  alias  keep both, caching this store's project as KEY@store; use the
+         alias or a store-qualified reference (store:KEY) to reach it
+  both   map the key to both stores; reads merge them and changes need a
+         store-qualified reference`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		storeName, _ := cmd.Flags().GetString("store")
 		all, _ := cmd.Flags().GetBool("all")
+		onConflict, _ := cmd.Flags().GetString("on-conflict")
+		if !slices.Contains([]string{fetchSkip, fetchRemap, fetchAlias, fetchBoth}, onConflict) {
+			return fmt.Errorf("invalid --on-conflict %q (valid: skip, remap, alias, both)", onConflict)
+		}
+		if !all {
+			if err := canPrompt("pass --all to add every project, with --on-conflict for keys already mapped to another store"); err != nil {
+				return err
+			}
+		}
+
+		if prune, _ := cmd.Flags().GetBool("prune"); prune {
+			names := cfg.StoreNames()
+			if storeName != "" {
+				names = []string{storeName}
+			}
+			sort.Strings(names)
+			pruneProjectCache(ctx, names)
+		}
+		reportRemovedStores()
 
 		if storeName != "" {
 			if all {
-				return fetchProjectsAll(storeName)
+				return fetchProjectsAll(ctx, storeName, onConflict)
 			}
-			return fetchProjectsInteractive(storeName)
+			return fetchProjectsInteractive(ctx, storeName)
 		}
 
 		// Fetch from all stores
 		for _, name := range cfg.StoreNames() {
 			if all {
-				if err := fetchProjectsAll(name); err != nil {
-					fmt.Printf("warning: %s: %v\n", name, err)
+				if err := fetchProjectsAll(ctx, name, onConflict); err != nil {
+					slog.Warn("fetching projects", "store", name, "err", err)
 				}
 			} else {
-				if err := fetchProjectsInteractive(name); err != nil {
-					fmt.Printf("warning: %s: %v\n", name, err)
+				if err := fetchProjectsInteractive(ctx, name); err != nil {
+					slog.Warn("fetching projects", "store", name, "err", err)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+var storeUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show entity counts and disk usage per store and project",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		only, _ := cmd.Flags().GetString("store")
+		names := cfg.StoreNames()
+		sort.Strings(names)
+
+		var rows [][]string
+		var warnings []string
+		for _, name := range names {
+			if only != "" && name != only {
+				continue
+			}
+			s, err := reg.Get(name)
+			if err != nil {
+				continue
+			}
+			projects, err := s.ListProjects(ctx)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+
+			entities := 0
+			for _, p := range projects {
+				u, err := store.Usage(ctx, s, p.ID)
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("%s/%s: %v", name, p.ID, err))
+					continue
+				}
+				entities += u.Entities()
+				rows = append(rows, []string{
+					name, p.ID,
+					strconv.Itoa(u.Tasks), strconv.Itoa(u.Epics), strconv.Itoa(u.Documents), strconv.Itoa(u.Releases),
+					markdown.FormatBytes(u.Bytes),
+				})
+			}
+			if cfg.Limits != nil {
+				warnings = append(warnings, limitWarning(name+" entities", int64(entities), int64(cfg.Limits.Entities), func(n int64) string {
+					return strconv.FormatInt(n, 10)
+				})...)
+			}
+
+			if ls, ok := store.AsLocal(s); ok {
+				size, err := ls.DataDirSize()
+				if err != nil {
+					return err
 				}
+				fmt.Printf("Local data directory %s: %s\n", dataDir, markdown.FormatBytes(size))
+				if cfg.Limits != nil {
+					warnings = append(warnings, limitWarning("local disk", size, cfg.Limits.DiskMB<<20, markdown.FormatBytes)...)
+				}
+			}
+		}
+
+		fmt.Println(markdown.RenderUsageTable(rows))
+		for _, w := range warnings {
+			slog.Warn(w)
+		}
+		return nil
+	},
+}
+
+// limitWarning returns a warning when used reaches 80% of a soft limit.
+func limitWarning(what string, used, limit int64, format func(int64) string) []string {
+	if limit <= 0 || used*5 < limit*4 {
+		return nil
+	}
+	verb := "is approaching"
+	if used >= limit {
+		verb = "exceeds"
+	}
+	return []string{fmt.Sprintf("%s (%s) %s the soft limit of %s", what, format(used), verb, format(limit))}
+}
+
+var storeSetLimitCmd = &cobra.Command{
+	Use:   "set-limit",
+	Short: "Set soft usage limits reported by 'store usage' (0 clears a limit)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("disk-mb") && !cmd.Flags().Changed("entities") {
+			return fmt.Errorf("at least one of --disk-mb or --entities is required")
+		}
+		if cfg.Limits == nil {
+			cfg.Limits = &config.UsageLimits{}
+		}
+		if cmd.Flags().Changed("disk-mb") {
+			cfg.Limits.DiskMB, _ = cmd.Flags().GetInt64("disk-mb")
+		}
+		if cmd.Flags().Changed("entities") {
+			cfg.Limits.Entities, _ = cmd.Flags().GetInt("entities")
+		}
+		if cfg.Limits.DiskMB == 0 && cfg.Limits.Entities == 0 {
+			cfg.Limits = nil
+		}
+		if err := saveConfig(cfg); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		infof("Usage limits updated.\n")
+		return nil
+	},
+}
+
+var storeSetReadOnlyCmd = &cobra.Command{
+	Use:   "set-readonly <name>",
+	Short: "Mark a store read-only so commands cannot modify it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		off, _ := cmd.Flags().GetBool("off")
+		if err := cfg.SetReadOnly(args[0], !off); err != nil {
+			return err
+		}
+		if err := saveConfig(cfg); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		if off {
+			infof("Store %s is writable\n", args[0])
+		} else {
+			infof("Store %s is read-only\n", args[0])
+		}
+		return nil
+	},
+}
+
+var storePingCmd = &cobra.Command{
+	Use:   "ping [name]",
+	Short: "Check that stores are reachable and their API keys are valid",
+	Long: `Send a cheap authenticated request to each store (or only the named one)
+and report whether it answered, whether the API key was accepted, the server
+version and the round-trip latency. Exits non-zero if any store fails.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		only := ""
+		if len(args) == 1 {
+			only = args[0]
+		}
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		results, errs, err := store.FanOut(ctx, reg, only, timeout+time.Second, func(ctx context.Context, s store.Store) (store.PingResult, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return s.Ping(ctx)
+		})
+		if err != nil {
+			return err
+		}
+
+		var rows [][]string
+		for _, name := range sortedKeys(results) {
+			r := results[name]
+			version := r.Version
+			if version == "" {
+				version = "-"
+			}
+			rows = append(rows, []string{name, "ok", version, fmt.Sprintf("%dms", r.Latency.Milliseconds())})
+		}
+		for _, e := range errs {
+			status := "unreachable: " + e.Err.Error()
+			if errors.Is(e.Err, store.ErrUnauthorized) {
+				status = "API key rejected"
 			}
+			rows = append(rows, []string{e.Store, status, "-", "-"})
+		}
+		fmt.Println(markdown.RenderPingTable(rows))
+		if len(errs) > 0 {
+			return fmt.Errorf("%d store(s) failed", len(errs))
 		}
 		return nil
 	},
 }
 
-func fetchProjectsInteractive(storeName string) error {
+// pruneProjectCache drops cached projects that the named stores answer
+// without. Stores that can't be listed are left alone.
+func pruneProjectCache(ctx context.Context, names []string) {
+	for _, name := range names {
+		s, err := reg.Get(name)
+		if err != nil {
+			slog.Warn("pruning project cache", "store", name, "err", err)
+			continue
+		}
+		projects, err := s.ListProjects(ctx)
+		if err != nil {
+			slog.Warn("pruning project cache", "store", name, "err", err)
+			continue
+		}
+		live := make(map[string]bool, len(projects))
+		for _, p := range projects {
+			live[p.ID] = true
+		}
+		for _, m := range config.Mappings(cfg.Projects) {
+			if m.Store == name && !live[m.Key] {
+				reg.Uncache(m)
+				infof("Pruned %s (no longer on %s)\n", m.Entry, name)
+			}
+		}
+	}
+}
+
+// reportRemovedStores warns about cached projects whose store has been
+// removed from config, which project list would otherwise skip.
+func reportRemovedStores() {
+	configured := make(map[string]bool)
+	for _, name := range cfg.StoreNames() {
+		configured[name] = true
+	}
+	for _, m := range config.Mappings(cfg.Projects) {
+		if !configured[m.Store] {
+			slog.Warn("project is cached on a removed store; fix with 'compass project set-store'", "project", m.Entry, "store", m.Store)
+		}
+	}
+}
+
+func fetchProjectsInteractive(ctx context.Context, storeName string) error {
 	s, err := reg.Get(storeName)
 	if err != nil {
 		return err
 	}
 
-	projects, err := s.ListProjects()
+	projects, err := s.ListProjects(ctx)
 	if err != nil {
 		return fmt.Errorf("fetching projects from %s: %w", storeName, err)
 	}
@@ -285,11 +699,13 @@ func fetchProjectsInteractive(storeName string) error {
 	for i, p := range projects {
 		label := fmt.Sprintf("%s  %s", p.ID, p.Name)
 		if existing, ok := cfg.Projects[p.ID]; ok {
-			if existing == storeName {
+			if config.CachedOn(cfg.Projects, p.ID, storeName) {
 				label += " (already cached)"
 			} else {
 				label += fmt.Sprintf(" (mapped to %s)", existing)
 			}
+		} else if config.CachedOn(cfg.Projects, p.ID, storeName) {
+			label += fmt.Sprintf(" (cached as %s)", config.AliasKey(p.ID, storeName))
 		}
 		opts[i] = huh.NewOption(label, p.ID)
 	}
@@ -305,47 +721,101 @@ func fetchProjectsInteractive(storeName string) error {
 
 	added := 0
 	for _, key := range selected {
-		if existing, ok := cfg.Projects[key]; ok && existing != storeName {
-			// Collision; prompt
-			var remap bool
-			msg := fmt.Sprintf("%s is mapped to store '%s'. Remap to '%s'?", key, existing, storeName)
-			if err := huh.NewConfirm().Title(msg).Value(&remap).Run(); err != nil || !remap {
-				continue
-			}
+		existing, ok := cfg.Projects[key]
+		if !ok || existing == storeName {
+			reg.CacheProject(key, storeName)
+			added++
+			continue
+		}
+		if config.CachedOn(cfg.Projects, key, storeName) {
+			continue
+		}
+		// Collision; prompt
+		choice := fetchSkip
+		if err := huh.NewSelect[string]().
+			Title(fmt.Sprintf("%s is mapped to store '%s'. What about the %s on '%s'?", key, existing, key, storeName)).
+			Options(
+				huh.NewOption(fmt.Sprintf("Keep %s on %s", key, existing), fetchSkip),
+				huh.NewOption(fmt.Sprintf("Remap %s to %s", key, storeName), fetchRemap),
+				huh.NewOption(fmt.Sprintf("Keep both, caching this one as %s", config.AliasKey(key, storeName)), fetchAlias),
+				huh.NewOption(fmt.Sprintf("Map %s to both stores (read-only)", key), fetchBoth),
+			).
+			Value(&choice).
+			Run(); err != nil {
+			continue
+		}
+		if cacheConflict(key, existing, storeName, choice) {
+			added++
 		}
-		reg.CacheProject(key, storeName)
-		added++
 	}
 
-	fmt.Printf("Added %d project(s) from %s\n", added, storeName)
+	infof("Added %d project(s) from %s\n", added, storeName)
 	return nil
 }
 
-func fetchProjectsAll(storeName string) error {
+func fetchProjectsAll(ctx context.Context, storeName, onConflict string) error {
 	s, err := reg.Get(storeName)
 	if err != nil {
 		return err
 	}
 
-	projects, err := s.ListProjects()
+	projects, err := s.ListProjects(ctx)
 	if err != nil {
 		return fmt.Errorf("fetching projects from %s: %w", storeName, err)
 	}
 
 	added := 0
 	for _, p := range projects {
-		if existing, ok := cfg.Projects[p.ID]; ok && existing != storeName {
-			fmt.Printf("warning: %s already mapped to %s, skipping\n", p.ID, existing)
+		existing, ok := cfg.Projects[p.ID]
+		if !ok || existing == storeName {
+			reg.CacheProject(p.ID, storeName)
+			added++
 			continue
 		}
-		reg.CacheProject(p.ID, storeName)
-		added++
+		if config.CachedOn(cfg.Projects, p.ID, storeName) {
+			continue
+		}
+		if onConflict == fetchSkip {
+			slog.Warn("project already mapped, skipping (see --on-conflict)", "project", p.ID, "store", existing)
+			continue
+		}
+		if cacheConflict(p.ID, existing, storeName, onConflict) {
+			added++
+		}
 	}
 
-	fmt.Printf("Added %d project(s) from %s\n", added, storeName)
+	infof("Added %d project(s) from %s\n", added, storeName)
 	return nil
 }
 
+// Ways to settle a project key that store fetch finds on a second store,
+// offered by fetchProjectsInteractive and accepted by --on-conflict.
+const (
+	fetchSkip  = "skip"
+	fetchRemap = "remap"
+	fetchAlias = "alias"
+	fetchBoth  = "both"
+)
+
+// cacheConflict caches key, found on storeName while mapped to existing,
+// as choice says, and reports whether it cached anything.
+func cacheConflict(key, existing, storeName, choice string) bool {
+	switch choice {
+	case fetchRemap:
+		reg.CacheProject(key, storeName)
+	case fetchAlias:
+		reg.CacheProject(config.AliasKey(key, storeName), storeName)
+		infof("Cached %s on %s as %s\n", key, storeName, config.AliasKey(key, storeName))
+	case fetchBoth:
+		both := config.JoinStores(append(config.SplitStores(existing), storeName)...)
+		reg.CacheProject(key, both)
+		infof("Mapped %s to %s; changes need a store-qualified reference such as %s:%s\n", key, both, storeName, key)
+	default:
+		return false
+	}
+	return true
+}
+
 func joinKeys(keys []string) string {
 	if len(keys) <= 3 {
 		s := ""
@@ -365,16 +835,41 @@ func init() {
 	storeAddCmd.Flags().String("api-key", "", "API key (skip device flow)")
 	storeAddCmd.Flags().String("path", "", "API path override (default: /api/v1)")
 	storeAddCmd.Flags().String("protocol", "", "protocol override (default: https)")
+	storeAddCmd.Flags().String("proxy", "", "HTTP(S) proxy URL for this store")
+	storeAddCmd.Flags().String("ca-file", "", "PEM CA bundle to trust for this store")
+	storeAddCmd.Flags().Bool("insecure-skip-verify", false, "disable TLS certificate verification (testing only)")
 
 	storeRemoveCmd.Flags().BoolP("force", "f", false, "skip confirmation")
 
+	storeLoginCmd.Flags().String("api-key", "", "new API key (skip device flow)")
+
+	storeUseOrgCmd.Flags().Bool("clear", false, "use the API key's default organization")
+
 	storeFetchCmd.Flags().String("store", "", "fetch from a specific store")
 	storeFetchCmd.Flags().Bool("all", false, "non-interactive, add all projects")
+	storeFetchCmd.Flags().Bool("prune", false, "drop cached projects that no longer exist on their store")
+	storeFetchCmd.Flags().String("on-conflict", fetchSkip, "with --all, what to do with a key already mapped to another store: skip, remap, alias or both")
+
+	storeSetReadOnlyCmd.Flags().Bool("off", false, "make the store writable again")
+
+	storePingCmd.Flags().Duration("timeout", 5*time.Second, "how long to wait for each store")
+
+	storeUsageCmd.Flags().String("store", "", "only report on this store")
+
+	storeSetLimitCmd.Flags().Int64("disk-mb", 0, "soft limit on local data directory size in MB")
+	storeSetLimitCmd.Flags().Int("entities", 0, "soft limit on entities per store")
 
 	storeCmd.AddCommand(storeAddCmd)
 	storeCmd.AddCommand(storeListCmd)
 	storeCmd.AddCommand(storeRemoveCmd)
 	storeCmd.AddCommand(storeSetDefaultCmd)
+	storeCmd.AddCommand(storeLoginCmd)
+	storeCmd.AddCommand(storeOrgsCmd)
+	storeCmd.AddCommand(storeUseOrgCmd)
 	storeCmd.AddCommand(storeFetchCmd)
+	storeCmd.AddCommand(storeUsageCmd)
+	storeCmd.AddCommand(storeSetLimitCmd)
+	storeCmd.AddCommand(storeSetReadOnlyCmd)
+	storeCmd.AddCommand(storePingCmd)
 	rootCmd.AddCommand(storeCmd)
 }
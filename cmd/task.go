@@ -1,14 +1,24 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/huh"
 	"github.com/rogersnm/compass/internal/dag"
 	"github.com/rogersnm/compass/internal/editor"
+	"github.com/rogersnm/compass/internal/id"
 	"github.com/rogersnm/compass/internal/markdown"
 	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/reminder"
 	"github.com/rogersnm/compass/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -21,14 +31,36 @@ var taskCmd = &cobra.Command{
 var taskCreateCmd = &cobra.Command{
 	Use:   "create <title>",
 	Short: "Create a new task",
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new task. The body is read from stdin.
+
+With --json, stdin is a JSON object instead and the created task is printed
+as JSON:
+
+  {"title": "...", "project": "AUTH", "type": "task", "epic": "AUTH-TXXXXX",
+   "priority": 1, "depends_on": ["AUTH-TXXXXX"], "due": "2026-03-01",
+   "assignee": "me", "body": "..."}
+
+A title argument overrides the object's title; the project falls back to
+--project and the usual resolution.
+
+With --edit, $EDITOR opens on a frontmatter template prefilled from the
+title and flags, and the task is created when the editor exits.`,
+	Args: titleArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			return taskCreateJSON(cmd, args)
+		}
+		if edit, _ := cmd.Flags().GetBool("edit"); edit {
+			return taskCreateEdit(cmd, args)
+		}
+
 		projectID, err := resolveProject(cmd)
 		if err != nil {
 			return err
 		}
 
-		s, err := storeForProject(projectID)
+		s, err := storeForProject(ctx, projectID)
 		if err != nil {
 			return err
 		}
@@ -42,6 +74,12 @@ var taskCreateCmd = &cobra.Command{
 			deps = strings.Split(depsStr, ",")
 		}
 
+		due, _ := cmd.Flags().GetString("due")
+		assignee, _ := cmd.Flags().GetString("assignee")
+		slug, _ := cmd.Flags().GetString("slug")
+		if slug, err = slugFlag(ctx, s, slug, projectID, args[0], ""); err != nil {
+			return err
+		}
 		body := readStdin()
 
 		var priority *int
@@ -49,32 +87,98 @@ var taskCreateCmd = &cobra.Command{
 			priority = &p
 		}
 
-		t, err := s.CreateTask(args[0], projectID, store.TaskCreateOpts{
+		t, err := s.CreateTask(ctx, args[0], projectID, store.TaskCreateOpts{
 			Type:      model.TaskType(typeStr),
 			Epic:      epicID,
 			Priority:  priority,
 			DependsOn: deps,
+			Due:       due,
+			Assignee:  resolveMe(assignee),
+			Slug:      slug,
 			Body:      body,
 		})
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Created task %s (%s)\n", t.Title, t.ID)
+		printCreated(t.ID, "Created task %s (%s)\n", t.Title, t.ID)
 		return nil
 	},
 }
 
+func taskCreateJSON(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	in, err := readJSONInput[struct {
+		Title     string         `json:"title"`
+		Project   string         `json:"project"`
+		Type      model.TaskType `json:"type"`
+		Epic      string         `json:"epic"`
+		Priority  *int           `json:"priority"`
+		DependsOn []string       `json:"depends_on"`
+		Due       string         `json:"due"`
+		Assignee  string         `json:"assignee"`
+		Body      string         `json:"body"`
+	}]()
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		in.Title = args[0]
+	}
+	if in.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if in.Project == "" {
+		if in.Project, err = resolveProject(cmd); err != nil {
+			return err
+		}
+	}
+	if in.Type == "" {
+		in.Type = model.TypeTask
+	}
+
+	s, err := storeForProject(ctx, in.Project)
+	if err != nil {
+		return err
+	}
+	t, err := s.CreateTask(ctx, in.Title, in.Project, store.TaskCreateOpts{
+		Type:      in.Type,
+		Epic:      in.Epic,
+		Priority:  in.Priority,
+		DependsOn: in.DependsOn,
+		Due:       in.Due,
+		Assignee:  resolveMe(in.Assignee),
+		Body:      in.Body,
+	})
+	if err != nil {
+		return err
+	}
+	return printJSON(t)
+}
+
 var taskListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List tasks",
+	Long: `List a project's tasks. With --all-stores every configured store is
+asked at once and the results are merged, with a Store column, so local
+and cloud tasks show in one table; --project then narrows them to that key
+on each store.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		projectID, err := resolveProject(cmd)
-		if err != nil {
+		ctx := cmd.Context()
+		allStores, _ := cmd.Flags().GetBool("all-stores")
+		var projectID string
+		var err error
+		if allStores {
+			projectID, _ = cmd.Flags().GetString("project")
+		} else if projectID, err = resolveProject(cmd); err != nil {
 			return err
 		}
 		epicID, _ := cmd.Flags().GetString("parent-epic")
 		statusStr, _ := cmd.Flags().GetString("status")
 		typeStr, _ := cmd.Flags().GetString("type")
+		asRecords, err := listOutput(cmd)
+		if err != nil {
+			return err
+		}
 
 		filter := store.TaskFilter{
 			ProjectID: projectID,
@@ -83,12 +187,27 @@ var taskListCmd = &cobra.Command{
 			Type:      model.TaskType(typeStr),
 		}
 
-		s, err := storeForProject(projectID)
-		if err != nil {
-			return err
+		page, paged := listPage(cmd)
+		var (
+			s        store.Store
+			tasks    []model.Task
+			allTasks map[string]*model.Task
+			next     string
+		)
+		if allStores {
+			if tasks, allTasks, err = allStoresTasks(ctx, filter); err == nil && paged {
+				tasks, next, err = store.PageTasks(tasks, page)
+			}
+		} else if s, err = storeForProject(ctx, projectID); err == nil {
+			if paged {
+				tasks, next, err = s.ListTasksPage(ctx, filter, page)
+			} else {
+				tasks, err = s.ListTasks(ctx, filter)
+			}
+			if err == nil {
+				allTasks, err = s.AllTaskMap(ctx, projectID)
+			}
 		}
-
-		tasks, err := s.ListTasks(filter)
 		if err != nil {
 			return err
 		}
@@ -104,18 +223,100 @@ var taskListCmd = &cobra.Command{
 			tasks = filtered
 		}
 
-		allTasks, _ := s.AllTaskMap(projectID)
-		fmt.Println(markdown.RenderTaskTable(tasks, allTasks))
+		if !paged {
+			markdown.SortTasks(tasks, allTasks, depPolicy())
+			pinnedFirst(tasks, func(t *model.Task) string { return t.ID })
+		}
+		columns := listColumns(cmd)
+		if allStores {
+			columns = storeColumn(columns, cmd.Flags().Changed("columns"))
+		}
+		if asRecords {
+			rows, err := markdown.TaskRecords(tasks, allTasks, depPolicy(), columns)
+			if err != nil {
+				return err
+			}
+			printNextPage(next)
+			return printRecords(cmd, rows)
+		}
+		out, err := markdown.RenderTaskColumns(tasks, allTasks, depPolicy(), columns)
+		if err != nil {
+			return err
+		}
+		// WIP limits are per project, so --all-stores doesn't check them.
+		if !allStores {
+			if p, _, err := s.GetProject(ctx, projectID); err == nil {
+				all := make([]model.Task, 0, len(allTasks))
+				for _, t := range allTasks {
+					all = append(all, *t)
+				}
+				for _, v := range p.WIPViolations(all) {
+					fmt.Println(markdown.RenderWarning(fmt.Sprintf("WIP limit exceeded: %d %s (limit %d)", v.Count, v.Status, v.Limit)))
+				}
+			}
+		}
+		if depPolicy() == model.StrictDeps {
+			for _, t := range tasks {
+				if missing := t.MissingDeps(allTasks); len(missing) > 0 && t.Status != model.StatusClosed {
+					fmt.Println(markdown.RenderWarning(fmt.Sprintf("%s is blocked by missing %s (run: compass maintain prune-refs)", t.ID, strings.Join(missing, ", "))))
+				}
+			}
+		}
+		fmt.Println(out)
+		printNextPage(next)
 		return nil
 	},
 }
 
+// depPolicy is how dependencies on tasks that no longer exist count, from
+// missing_deps in config.
+func depPolicy() model.DepPolicy {
+	return model.ParseDepPolicy(cfg.MissingDeps)
+}
+
+// checkWIPLimit refuses to move task id into status when its project's
+// WIP limit for status is already reached. With override the move goes
+// ahead with a warning.
+func checkWIPLimit(ctx context.Context, s store.Store, id string, status model.Status, override bool) error {
+	t, _, err := s.GetTask(ctx, id)
+	if err != nil || t.Type != model.TypeTask || t.Status == status {
+		return nil // let the update report missing tasks and epics
+	}
+	return checkProjectWIPLimit(ctx, s, t.Project, status, override)
+}
+
+// checkProjectWIPLimit refuses to move one more of projectID's tasks into
+// status when the project's WIP limit for status is already reached.
+func checkProjectWIPLimit(ctx context.Context, s store.Store, projectID string, status model.Status, override bool) error {
+	p, _, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	limit, ok := p.WIPLimits[status]
+	if !ok {
+		return nil
+	}
+	tasks, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: projectID, Type: model.TypeTask, Status: status})
+	if err != nil {
+		return err
+	}
+	if len(tasks) < limit {
+		return nil
+	}
+	if !override {
+		return fmt.Errorf("%s already has %d %s tasks (WIP limit %d); finish one first or use --override", p.ID, len(tasks), status, limit)
+	}
+	slog.Warn("exceeding WIP limit", "project", p.ID, "status", status, "limit", limit)
+	return nil
+}
+
 var taskShowCmd = &cobra.Command{
 	Use:   "show <id>",
 	Short: "Show task details",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
@@ -132,7 +333,7 @@ var taskShowCmd = &cobra.Command{
 				return nil
 			}
 			// Cloud mode: marshal from API response
-			t, body, err := s.GetTask(args[0])
+			t, body, err := s.GetTask(ctx, args[0])
 			if err != nil {
 				return err
 			}
@@ -144,18 +345,21 @@ var taskShowCmd = &cobra.Command{
 			return nil
 		}
 
-		t, body, err := s.GetTask(args[0])
+		t, body, err := s.GetTask(ctx, args[0])
 		if err != nil {
 			return err
 		}
 
-		allTasks, _ := s.AllTaskMap(t.Project)
-		blocked := t.IsBlocked(allTasks)
+		allTasks, _ := s.AllTaskMap(ctx, t.Project)
+		blocked := t.IsBlocked(allTasks, depPolicy())
 
 		var statusDisplay string
-		if t.Type == model.TypeEpic {
+		switch {
+		case t.Type == model.TypeEpic && t.ClosedAt != nil:
+			statusDisplay = markdown.RenderStatus(string(model.StatusClosed), false)
+		case t.Type == model.TypeEpic:
 			statusDisplay = "N/A"
-		} else {
+		default:
 			statusDisplay = markdown.RenderStatus(string(t.Status), blocked)
 		}
 
@@ -165,6 +369,9 @@ var taskShowCmd = &cobra.Command{
 			markdown.RenderField("Project", t.Project),
 			markdown.RenderField("Status", statusDisplay),
 		}
+		if t.Slug != "" {
+			fields = append(fields, markdown.RenderField("Slug", t.Project+"/"+t.Slug))
+		}
 		if t.Priority != nil {
 			fields = append(fields, markdown.RenderField("Priority", model.FormatPriority(t.Priority)))
 		}
@@ -173,15 +380,51 @@ var taskShowCmd = &cobra.Command{
 			markdown.RenderField("Created", t.CreatedAt.Format("2006-01-02 15:04:05")),
 			markdown.RenderField("Updated", t.UpdatedAt.Format("2006-01-02 15:04:05")),
 		)
+		if t.DeletedAt != nil {
+			fields = append(fields, markdown.RenderField("Deleted", t.DeletedAt.Format("2006-01-02 15:04:05")))
+		}
 		if t.Epic != "" {
 			fields = append(fields, markdown.RenderField("Parent Epic", t.Epic))
 		}
 		if len(t.DependsOn) > 0 {
 			fields = append(fields, markdown.RenderField("Depends on", strings.Join(t.DependsOn, ", ")))
 		}
+		if t.Due != "" {
+			fields = append(fields, markdown.RenderField("Due", t.Due))
+		}
+		if t.Assignee != "" {
+			fields = append(fields, markdown.RenderField("Assignee", t.Assignee))
+		}
+		if len(t.Watchers) > 0 {
+			fields = append(fields, markdown.RenderField("Watchers", strings.Join(t.Watchers, ", ")))
+		}
+		if t.BlockedReason != "" {
+			fields = append(fields, markdown.RenderField("Blocked", t.BlockedReason))
+		}
+		if w := t.Waiting; w != nil {
+			waitingOn := w.Description
+			if w.URL != "" {
+				waitingOn += " <" + w.URL + ">"
+			}
+			if w.Until != "" {
+				waitingOn += " (until " + w.Until + ")"
+			}
+			if !w.Active(time.Now()) {
+				waitingOn += " [lapsed]"
+			}
+			fields = append(fields, markdown.RenderField("Waiting on", waitingOn))
+		}
+
+		if missing := t.MissingDeps(allTasks); len(missing) > 0 {
+			if depPolicy() == model.LenientDeps {
+				fields = append(fields, markdown.RenderField("Missing deps", strings.Join(missing, ", ")+" (ignored)"))
+			} else {
+				fields = append(fields, markdown.RenderField("Blocked by", "missing "+strings.Join(missing, ", ")))
+			}
+		}
 
 		// Show dependents
-		projectTasks, _ := s.ListTasks(store.TaskFilter{ProjectID: t.Project})
+		projectTasks, _ := s.ListTasks(ctx, store.TaskFilter{ProjectID: t.Project})
 		var dependents []string
 		for _, pt := range projectTasks {
 			for _, dep := range pt.DependsOn {
@@ -203,15 +446,22 @@ var taskShowCmd = &cobra.Command{
 			fmt.Print(rendered)
 		}
 
+		if len(t.History) > 0 {
+			fmt.Println("\nHistory:")
+			for _, c := range t.History {
+				fmt.Printf("  %s  %s  %s\n", c.At.Local().Format("2006-01-02 15:04"), markdown.StatusStyle(string(c.Status)).Render(fmt.Sprintf("%-11s", c.Status)), c.By)
+			}
+		}
+
 		// If epic-type, list child tasks
 		if t.Type == model.TypeEpic {
-			children, err := s.ListTasks(store.TaskFilter{EpicID: t.ID})
+			children, err := s.ListTasks(ctx, store.TaskFilter{EpicID: t.ID})
 			if err != nil {
 				return err
 			}
 			if len(children) > 0 {
 				fmt.Println("\nTasks:")
-				fmt.Println(markdown.RenderTaskTable(children, allTasks))
+				fmt.Println(markdown.RenderTaskTable(children, allTasks, depPolicy()))
 			}
 		}
 
@@ -222,13 +472,58 @@ var taskShowCmd = &cobra.Command{
 var taskUpdateCmd = &cobra.Command{
 	Use:   "update <id>",
 	Short: "Update a task",
-	Args:  cobra.ExactArgs(1),
+	Long: `Update a task. A piped body replaces the task's body.
+
+With --json, stdin is a JSON object of the fields to change and the updated
+task is printed as JSON. Absent fields are left alone; null clears priority
+and waiting:
+
+  {"title": "...", "status": "in_progress", "priority": null, "epic": "...",
+   "depends_on": [], "waiting": {"description": "..."}, "due": "2026-03-01",
+   "assignee": "me", "body": "..."}
+
+An empty due or assignee string clears it.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
 
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			in, err := readJSONInput[struct {
+				Title     *string         `json:"title"`
+				Status    *model.Status   `json:"status"`
+				Priority  json.RawMessage `json:"priority"` // null clears
+				Epic      *string         `json:"epic"`
+				DependsOn *[]string       `json:"depends_on"`
+				Waiting   json.RawMessage `json:"waiting"` // null clears
+				Due       *string         `json:"due"`
+				Assignee  *string         `json:"assignee"`
+				Body      *string         `json:"body"`
+			}]()
+			if err != nil {
+				return err
+			}
+			upd := store.TaskUpdate{Title: in.Title, Status: in.Status, Epic: in.Epic, DependsOn: in.DependsOn, Due: in.Due, Body: in.Body}
+			if in.Assignee != nil {
+				assignee := resolveMe(*in.Assignee)
+				upd.Assignee = &assignee
+			}
+			if upd.Priority, err = optionalField[int]("priority", in.Priority); err != nil {
+				return err
+			}
+			if upd.Waiting, err = optionalField[model.WaitingOn]("waiting", in.Waiting); err != nil {
+				return err
+			}
+			t, err := s.UpdateTask(ctx, args[0], upd)
+			if err != nil {
+				return err
+			}
+			return printJSON(t)
+		}
+
 		upd := store.TaskUpdate{}
 
 		if cmd.Flags().Changed("title") {
@@ -252,31 +547,219 @@ var taskUpdateCmd = &cobra.Command{
 			}
 			upd.DependsOn = &deps
 		}
+		if cmd.Flags().Changed("due") {
+			due, _ := cmd.Flags().GetString("due")
+			upd.Due = &due
+		}
+		if cmd.Flags().Changed("assignee") {
+			assignee, _ := cmd.Flags().GetString("assignee")
+			assignee = resolveMe(assignee)
+			upd.Assignee = &assignee
+		}
+		if cmd.Flags().Changed("slug") {
+			slug, _ := cmd.Flags().GetString("slug")
+			if slug == autoSlug {
+				t, _, err := s.GetTask(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				title := t.Title
+				if upd.Title != nil {
+					title = *upd.Title
+				}
+				if slug, err = slugFlag(ctx, s, slug, t.Project, title, t.ID); err != nil {
+					return err
+				}
+			}
+			upd.Slug = &slug
+		}
 
 		body := readStdin()
 		if body != "" {
 			upd.Body = &body
 		}
 
-		if upd.Title == nil && upd.Status == nil && upd.Priority == nil && upd.DependsOn == nil && upd.Body == nil {
-			return fmt.Errorf("at least one update flag or piped body is required (--title, --status, --priority, --depends-on, stdin)")
+		if upd.Title == nil && upd.Status == nil && upd.Priority == nil && upd.DependsOn == nil && upd.Due == nil && upd.Assignee == nil && upd.Slug == nil && upd.Body == nil {
+			return fmt.Errorf("at least one update flag or piped body is required (--title, --status, --priority, --depends-on, --due, --assignee, --slug, stdin)")
+		}
+		if upd.Status != nil {
+			override, _ := cmd.Flags().GetBool("override")
+			if err := checkWIPLimit(ctx, s, args[0], *upd.Status, override); err != nil {
+				return err
+			}
+		}
+		prev, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		t, err := s.UpdateTask(ctx, args[0], upd)
+		var ce *dag.CycleError
+		if errors.As(err, &ce) && len(ce.Breakers) > 0 {
+			if fix, _ := cmd.Flags().GetBool("fix-cycle"); !fix {
+				return fmt.Errorf("%w\nre-run with --fix-cycle to drop the offending dependencies", err)
+			}
+			if t, err = fixCycle(ctx, s, args[0], upd, ce); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return err
+		}
+		infof("Updated task %s\n", t.ID)
+		if prev.Status != model.StatusClosed {
+			reportEpicClosed(ctx, s, t)
+		}
+		return nil
+	},
+}
+
+var taskDepCmd = &cobra.Command{
+	Use:   "dep",
+	Short: "Add, remove and list task dependencies",
+}
+
+var taskDepAddCmd = &cobra.Command{
+	Use:   "add <id> <dep-id>...",
+	Short: "Add dependencies to a task, keeping existing ones",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		deps := slices.Clone(t.DependsOn)
+		var added []string
+		for _, dep := range args[1:] {
+			if slices.Contains(deps, dep) {
+				infof("%s already depends on %s\n", t.ID, dep)
+				continue
+			}
+			deps = append(deps, dep)
+			added = append(added, dep)
+		}
+		if len(added) == 0 {
+			return nil
+		}
+		if _, err := s.UpdateTask(ctx, t.ID, store.TaskUpdate{DependsOn: &deps}); err != nil {
+			return err
+		}
+		infof("%s now depends on %s\n", t.ID, strings.Join(added, ", "))
+		return nil
+	},
+}
+
+var taskDepRemoveCmd = &cobra.Command{
+	Use:   "remove <id> <dep-id>...",
+	Short: "Remove dependencies from a task, keeping the rest",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		for _, dep := range args[1:] {
+			if !slices.Contains(t.DependsOn, dep) {
+				return fmt.Errorf("%s does not depend on %s", t.ID, dep)
+			}
+		}
+		deps := slices.DeleteFunc(slices.Clone(t.DependsOn), func(dep string) bool {
+			return slices.Contains(args[1:], dep)
+		})
+		if _, err := s.UpdateTask(ctx, t.ID, store.TaskUpdate{DependsOn: &deps}); err != nil {
+			return err
 		}
+		infof("%s no longer depends on %s\n", t.ID, strings.Join(args[1:], ", "))
+		return nil
+	},
+}
 
-		t, err := s.UpdateTask(args[0], upd)
+var taskDepListCmd = &cobra.Command{
+	Use:   "list <id>",
+	Short: "List a task's dependencies and their status",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if len(t.DependsOn) == 0 {
+			fmt.Printf("%s has no dependencies.\n", t.ID)
+			return nil
+		}
+		allTasks, _ := s.AllTaskMap(ctx, t.Project)
+		var deps []model.Task
+		for _, id := range t.DependsOn {
+			if dep, ok := allTasks[id]; ok {
+				deps = append(deps, *dep)
+				continue
+			}
+			slog.Warn("dependency not found", "id", id)
+		}
+		out, err := markdown.RenderTaskColumns(deps, allTasks, depPolicy(), markdown.DefaultTaskColumns)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Updated task %s\n", t.ID)
+		fmt.Println(out)
 		return nil
 	},
 }
 
+// fixCycle asks whether to drop the dependencies that close a cycle and, if
+// confirmed, retries the update without them.
+func fixCycle(ctx context.Context, s store.Store, id string, upd store.TaskUpdate, ce *dag.CycleError) (*model.Task, error) {
+	fmt.Fprintln(os.Stderr, "Cycle: "+strings.Join(ce.Path, " -> "))
+	if err := canPrompt("rerun without --fix-cycle and leave out one of the cycle's dependencies"); err != nil {
+		return nil, err
+	}
+	drop := make([]string, len(ce.Breakers))
+	opts := make([]huh.Option[string], len(ce.Breakers))
+	for i, b := range ce.Breakers {
+		drop[i] = b[1]
+		opts[i] = huh.NewOption(b[0]+" -> "+b[1], b[1]).Selected(true)
+	}
+	if err := huh.NewMultiSelect[string]().
+		Title("Drop these dependencies to break the cycle?").
+		Options(opts...).
+		Value(&drop).
+		Run(); err != nil {
+		return nil, fmt.Errorf("cancelled")
+	}
+	if len(drop) == 0 {
+		return nil, ce
+	}
+	deps := slices.DeleteFunc(slices.Clone(*upd.DependsOn), func(dep string) bool {
+		return slices.Contains(drop, dep)
+	})
+	upd.DependsOn = &deps
+	t, err := s.UpdateTask(ctx, id, upd)
+	if err == nil {
+		infof("Dropped dependencies on %s\n", strings.Join(drop, ", "))
+	}
+	return t, err
+}
+
 var taskEditCmd = &cobra.Command{
 	Use:   "edit <id>",
 	Short: "Edit a task in $EDITOR",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		s, err := storeForEntity(cmd.Context(), args[0])
 		if err != nil {
 			return err
 		}
@@ -292,17 +775,18 @@ var taskGraphCmd = &cobra.Command{
 	Use:   "graph",
 	Short: "Show task dependency graph",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		projectID, err := resolveProject(cmd)
 		if err != nil {
 			return err
 		}
 
-		s, err := storeForProject(projectID)
+		s, err := storeForProject(ctx, projectID)
 		if err != nil {
 			return err
 		}
 
-		tasks, err := s.ListTasks(store.TaskFilter{ProjectID: projectID, Type: model.TypeTask})
+		tasks, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: projectID, Type: model.TypeTask})
 		if err != nil {
 			return err
 		}
@@ -313,186 +797,1154 @@ var taskGraphCmd = &cobra.Command{
 		}
 
 		g := dag.BuildFromTasks(ptrs)
-		fmt.Println(dag.RenderASCII(g))
+		fmt.Println(dag.RenderASCII(g, depPolicy()))
 		return nil
 	},
 }
 
-var taskStartCmd = &cobra.Command{
-	Use:   "start <id>",
-	Short: "Start a task (set status to in_progress)",
-	Args:  cobra.ExactArgs(1),
+var taskMatrixCmd = &cobra.Command{
+	Use:   "matrix",
+	Short: "Show unfinished tasks in a priority by state grid",
+	Long: `Show a project's unfinished tasks in a grid for planning: a column per
+priority, P0 to P3 (and None for unprioritized tasks), and a row each for
+blocked, ready and in-progress tasks. Open tasks held up by dependencies
+or a wait count as blocked.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
 		if err != nil {
 			return err
 		}
-		status := model.StatusInProgress
-		t, err := s.UpdateTask(args[0], store.TaskUpdate{Status: &status})
+		s, err := storeForProject(ctx, projectID)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Started task %s\n", t.ID)
-		return nil
-	},
-}
-
-var taskCloseCmd = &cobra.Command{
-	Use:   "close <id>",
-	Short: "Close a task (set status to closed)",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		tasks, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: projectID, Type: model.TypeTask})
 		if err != nil {
 			return err
 		}
-		status := model.StatusClosed
-		t, err := s.UpdateTask(args[0], store.TaskUpdate{Status: &status})
+		allTasks, err := s.AllTaskMap(ctx, projectID)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Closed task %s\n", t.ID)
+		m := markdown.NewMatrix(tasks, allTasks, depPolicy())
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			out := map[string]map[string][]string{}
+			for r, row := range m.Cells {
+				key := strings.ReplaceAll(strings.ToLower(markdown.MatrixRows[r]), " ", "_")
+				out[key] = map[string][]string{}
+				for c, cell := range row {
+					col := "none"
+					if c < 4 {
+						col = fmt.Sprintf("P%d", c)
+					}
+					ids := []string{}
+					for _, t := range cell {
+						ids = append(ids, t.ID)
+					}
+					out[key][col] = ids
+				}
+			}
+			return printJSON(out)
+		}
+		m.Limit, _ = cmd.Flags().GetInt("limit")
+		fmt.Println(markdown.RenderMatrix(m))
 		return nil
 	},
 }
 
-var taskDeleteCmd = &cobra.Command{
-	Use:   "delete <id>",
-	Short: "Delete a task",
+var taskWhyBlockedCmd = &cobra.Command{
+	Use:   "why-blocked <id>",
+	Short: "Explain which upstream tasks block a task",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
-		t, _, err := s.GetTask(args[0])
+		t, _, err := s.GetTask(ctx, args[0])
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Task: %s (%s)\n", t.Title, t.ID)
-		if err := confirmDelete(cmd, t.ID); err != nil {
+		allTasks, err := s.AllTaskMap(ctx, t.Project)
+		if err != nil {
 			return err
 		}
-		if err := s.DeleteTask(t.ID); err != nil {
-			return err
+		allTasks[t.ID] = t
+		if !t.IsBlocked(allTasks, depPolicy()) {
+			fmt.Printf("%s is not blocked.\n", t.ID)
+			return nil
+		}
+
+		fmt.Printf("%s %s is blocked.\n", t.ID, t.Title)
+		if w := t.Waiting; w.Active(time.Now()) {
+			waitingOn := w.Description
+			if w.Until != "" {
+				waitingOn += " (until " + w.Until + ")"
+			}
+			fmt.Printf("\nWaiting on: %s\n", waitingOn)
+		}
+
+		ptrs := make([]*model.Task, 0, len(allTasks))
+		for _, pt := range allTasks {
+			ptrs = append(ptrs, pt)
+		}
+		g := dag.BuildFromTasks(ptrs)
+		if tree := dag.RenderBlockers(g, t.ID, depPolicy()); tree != "" {
+			fmt.Printf("\nDepends on:\n%s", tree)
+		}
+
+		// The actionable blockers are unfinished upstream tasks that are
+		// not blocked themselves. Walk only unfinished tasks, since work
+		// behind a closed dependency doesn't block.
+		var open []*model.Task
+		for _, pt := range ptrs {
+			if pt.Status != model.StatusClosed {
+				open = append(open, pt)
+			}
+		}
+		var next []string
+		og := dag.BuildFromTasks(open)
+		for _, id := range og.TransitiveDeps(t.ID) {
+			if dt := og.Node(id); dt != nil && !dt.IsBlocked(allTasks, depPolicy()) {
+				next = append(next, id)
+			}
+		}
+		if len(next) > 0 {
+			fmt.Printf("\nStart with: %s\n", strings.Join(next, ", "))
 		}
-		fmt.Printf("Deleted task %s\n", t.ID)
 		return nil
 	},
 }
 
-var taskReadyCmd = &cobra.Command{
-	Use:   "ready",
-	Short: "Show next ready task(s)",
+var taskSimilarCmd = &cobra.Command{
+	Use:   "similar <id>",
+	Short: "List tasks and documents that look related to a task",
+	Long: `List the tasks and documents in a task's project that share the most words
+with its title and body, to find prior art and duplicates before starting
+work. Title words count twice as much as body words, and each match is
+scored from 0 to 100%.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		projectID, err := resolveProject(cmd)
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
-
-		s, err := storeForProject(projectID)
+		limit, _ := cmd.Flags().GetInt("limit")
+		similar, err := store.SimilarTasks(ctx, s, args[0], limit)
 		if err != nil {
 			return err
 		}
-
-		ready, err := s.ReadyTasks(projectID)
-		if err != nil {
-			return err
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if similar == nil {
+				similar = []store.Similar{}
+			}
+			return printJSON(similar)
 		}
-
-		if len(ready) == 0 {
-			fmt.Println("No ready tasks.")
+		if len(similar) == 0 {
+			fmt.Printf("Nothing similar to %s.\n", args[0])
 			return nil
 		}
-
-		showAll, _ := cmd.Flags().GetBool("all")
-		if showAll {
-			tasks := make([]model.Task, len(ready))
-			for i, t := range ready {
-				tasks[i] = *t
+		for _, m := range similar {
+			kind := string(m.Status)
+			switch {
+			case m.Type == "document":
+				kind = "doc"
+			case kind == "":
+				kind = "epic"
 			}
-			allTasks, _ := s.AllTaskMap(projectID)
-			fmt.Println(markdown.RenderTaskTable(tasks, allTasks))
-		} else {
-			fmt.Printf("%s  %s\n", ready[0].ID, ready[0].Title)
+			fmt.Printf("%3.0f%%  %s  %s (%s)\n", m.Score*100, m.ID, m.Title, kind)
 		}
 		return nil
 	},
 }
 
-var taskDownloadCmd = &cobra.Command{
-	Use:   "download <id>",
-	Short: "Copy a task to .compass/ in the current directory for local editing",
+var taskStartCmd = &cobra.Command{
+	Use:   "start <id>",
+	Short: "Start a task (set status to in_progress)",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
-		path, err := s.DownloadEntity(args[0], ".compass")
+		status := model.StatusInProgress
+		override, _ := cmd.Flags().GetBool("override")
+		if err := checkWIPLimit(ctx, s, args[0], status, override); err != nil {
+			return err
+		}
+		t, err := s.UpdateTask(ctx, args[0], store.TaskUpdate{Status: &status})
 		if err != nil {
 			return err
 		}
-		fmt.Println(path)
+		infof("Started task %s\n", t.ID)
 		return nil
 	},
 }
 
-var taskUploadCmd = &cobra.Command{
-	Use:   "upload <id>",
-	Short: "Write a locally edited task back to the store and remove the local copy",
+var taskCloseCmd = &cobra.Command{
+	Use:   "close <id>",
+	Short: "Close a task (set status to closed)",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		s, err := storeForEntity(args[0])
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
 		if err != nil {
 			return err
 		}
-		localPath := fmt.Sprintf(".compass/%s.md", args[0])
-		t, err := s.UploadTask(localPath)
+		prev, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		dependents, allTasks, err := dependentsOf(ctx, s, prev)
+		if err != nil {
+			return err
+		}
+		// The impact is shown before closing, so it's also what --dry-run
+		// previews.
+		if len(dependents) > 0 {
+			after := maps.Clone(allTasks)
+			closed := *prev
+			closed.Status = model.StatusClosed
+			after[prev.ID] = &closed
+			printImpact(dependents, allTasks, after, false)
+		}
+		status := model.StatusClosed
+		t, err := s.UpdateTask(ctx, args[0], store.TaskUpdate{Status: &status})
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Uploaded task %s\n", t.ID)
+		infof("Closed task %s\n", t.ID)
+		if prev.Status != model.StatusClosed {
+			reportEpicClosed(ctx, s, t)
+		}
 		return nil
 	},
 }
 
-func init() {
-	taskCreateCmd.Flags().StringP("project", "P", "", "project ID")
-	taskCreateCmd.Flags().StringP("parent-epic", "e", "", "parent epic ID (must reference a type=epic task)")
-	taskCreateCmd.Flags().StringP("type", "t", "task", "task type (task, epic)")
+var taskBlockCmd = &cobra.Command{
+	Use:   "block <id>",
+	Short: "Mark a task as blocked by something outside compass",
+	Long: `Mark a task as blocked by something outside compass, such as a vendor or
+another team. Blocked tasks are never ready or claimed; use "task unblock"
+to reopen them.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		reason, _ := cmd.Flags().GetString("reason")
+		if reason == "" {
+			return fmt.Errorf("--reason is required")
+		}
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		status := model.StatusBlocked
+		t, err := s.UpdateTask(ctx, args[0], store.TaskUpdate{Status: &status, BlockedReason: &reason})
+		if err != nil {
+			return err
+		}
+		infof("Blocked task %s: %s\n", t.ID, reason)
+		return nil
+	},
+}
+
+var taskUnblockCmd = &cobra.Command{
+	Use:   "unblock <id>",
+	Short: "Clear a manual block and reopen the task",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if t.Status != model.StatusBlocked {
+			return fmt.Errorf("task %s is not blocked", t.ID)
+		}
+		status := model.StatusOpen
+		if t, err = s.UpdateTask(ctx, args[0], store.TaskUpdate{Status: &status}); err != nil {
+			return err
+		}
+		infof("Unblocked task %s\n", t.ID)
+		return nil
+	},
+}
+
+var taskWatchCmd = &cobra.Command{
+	Use:   "watch <id>",
+	Short: "Follow a task, so it shows in my work",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		me := store.CurrentUser()
+		if t.WatchedBy(me) {
+			infof("Already watching task %s\n", t.ID)
+			return nil
+		}
+		watchers := append(slices.Clone(t.Watchers), me)
+		if t, err = s.UpdateTask(ctx, args[0], store.TaskUpdate{Watchers: &watchers}); err != nil {
+			return err
+		}
+		infof("Watching task %s\n", t.ID)
+		return nil
+	},
+}
+
+var taskUnwatchCmd = &cobra.Command{
+	Use:   "unwatch <id>",
+	Short: "Stop following a task",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		me := store.CurrentUser()
+		if !t.WatchedBy(me) {
+			return fmt.Errorf("not watching task %s", t.ID)
+		}
+		watchers := slices.DeleteFunc(slices.Clone(t.Watchers), func(w string) bool { return w == me })
+		if t, err = s.UpdateTask(ctx, args[0], store.TaskUpdate{Watchers: &watchers}); err != nil {
+			return err
+		}
+		infof("Stopped watching task %s\n", t.ID)
+		return nil
+	},
+}
+
+var taskDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a task",
+	Long: `Delete a task after typing its ID to confirm. Tasks that depend on it are
+listed first: they'd be left depending on a task that no longer exists,
+which blocks them for good. --cascade removes it from their depends_on,
+unblocking those with nothing else outstanding. --force skips the prompt,
+but only with --cascade when other tasks depend on it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		infof("Task: %s (%s)\n", t.Title, t.ID)
+		dependents, allTasks, err := dependentsOf(ctx, s, t)
+		if err != nil {
+			return err
+		}
+		cascade, _ := cmd.Flags().GetBool("cascade")
+		if len(dependents) > 0 {
+			after := maps.Clone(allTasks)
+			delete(after, t.ID)
+			if cascade {
+				// Dropping the reference is what unblocks a dependent.
+				after = withoutDep(after, dependents, t.ID)
+			}
+			printImpact(dependents, allTasks, after, !cascade)
+			if force, _ := cmd.Flags().GetBool("force"); force && !cascade && !dryRun {
+				return fmt.Errorf("%s is a dependency of %d tasks; pass --cascade to drop the references, or leave out --force to confirm", t.ID, len(dependents))
+			}
+		}
+		if err := confirmDelete(cmd, t.ID); err != nil {
+			return err
+		}
+		if err := s.DeleteTask(ctx, t.ID); err != nil {
+			return err
+		}
+		infof("Deleted task %s\n", t.ID)
+		if !cascade {
+			return nil
+		}
+		for _, d := range dependents {
+			deps := slices.DeleteFunc(slices.Clone(d.DependsOn), func(dep string) bool { return dep == t.ID })
+			if _, err := s.UpdateTask(ctx, d.ID, store.TaskUpdate{DependsOn: &deps}); err != nil {
+				return fmt.Errorf("updating %s: %w", d.ID, err)
+			}
+			infof("%s no longer depends on %s\n", d.ID, t.ID)
+		}
+		return nil
+	},
+}
+
+// dependentsOf returns the tasks in t's project that depend on it, and
+// every task in the project by ID.
+func dependentsOf(ctx context.Context, s store.Store, t *model.Task) ([]*model.Task, map[string]*model.Task, error) {
+	allTasks, err := s.AllTaskMap(ctx, t.Project)
+	if err != nil {
+		return nil, nil, err
+	}
+	var dependents []*model.Task
+	for _, pt := range allTasks {
+		if slices.Contains(pt.DependsOn, t.ID) {
+			dependents = append(dependents, pt)
+		}
+	}
+	slices.SortFunc(dependents, func(a, b *model.Task) int { return strings.Compare(a.ID, b.ID) })
+	return dependents, allTasks, nil
+}
+
+// withoutDep returns allTasks with dependents' references to depID dropped.
+func withoutDep(allTasks map[string]*model.Task, dependents []*model.Task, depID string) map[string]*model.Task {
+	out := maps.Clone(allTasks)
+	for _, d := range dependents {
+		c := *d
+		c.DependsOn = slices.DeleteFunc(slices.Clone(d.DependsOn), func(dep string) bool { return dep == depID })
+		out[d.ID] = &c
+	}
+	return out
+}
+
+// printImpact lists the open dependents of a task being closed or deleted:
+// those the change unblocks, judged by the task map after it, and, with
+// dangling, that they'll be left depending on a task that's gone.
+func printImpact(dependents []*model.Task, before, after map[string]*model.Task, dangling bool) {
+	if quiet {
+		return
+	}
+	for _, d := range dependents {
+		if d.Status == model.StatusClosed {
+			continue
+		}
+		var notes []string
+		if dangling {
+			notes = append(notes, "left with a dangling dependency")
+		}
+		if d.IsBlocked(before, depPolicy()) && !after[d.ID].IsBlocked(after, depPolicy()) {
+			notes = append(notes, "unblocked")
+		}
+		if len(notes) == 0 {
+			continue
+		}
+		fmt.Printf("  %s  %s (%s)\n", d.ID, d.Title, strings.Join(notes, ", "))
+	}
+}
+
+var taskMoveCmd = &cobra.Command{
+	Use:   "move <id> --to-project <key>",
+	Short: "Move a task to another project, giving it a new ID",
+	Long: `Move a task to another project. The task gets a new ID in the target
+project's key space. Dependencies can't cross projects, so the task's own
+depends_on and epic are cleared, and tasks that depended on it drop the
+reference; each change is printed.
+
+Within one store the task keeps its history. Between stores it is recreated
+on the target (title, type, status, priority, waiting and body are copied)
+and deleted from the source.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		to, _ := cmd.Flags().GetString("to-project")
+		if to == "" {
+			return fmt.Errorf("--to-project is required")
+		}
+		src, srcName, err := reg.ForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, body, err := src.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if t.Type == model.TypeEpic {
+			return fmt.Errorf("cannot move epic-type task %s; move its tasks individually", t.ID)
+		}
+		dst, dstName, err := reg.ForProject(ctx, to)
+		if err != nil {
+			return err
+		}
+
+		siblings, err := src.ListTasks(ctx, store.TaskFilter{ProjectID: t.Project})
+		if err != nil {
+			return err
+		}
+
+		var moved *model.Task
+		if srcName == dstName {
+			moved, err = src.MoveTask(ctx, t.ID, to)
+		} else {
+			moved, err = moveTaskAcross(ctx, src, dst, t, body, to)
+		}
+		if err != nil {
+			return err
+		}
+		printCreated(moved.ID, "Moved %s to %s\n", t.ID, moved.ID)
+		if t.Epic != "" {
+			infof("Removed from epic %s\n", t.Epic)
+		}
+		if len(t.DependsOn) > 0 {
+			infof("Dropped dependencies on %s\n", strings.Join(t.DependsOn, ", "))
+		}
+
+		for _, st := range siblings {
+			if !slices.Contains(st.DependsOn, t.ID) {
+				continue
+			}
+			deps := slices.DeleteFunc(slices.Clone(st.DependsOn), func(dep string) bool { return dep == t.ID })
+			if _, err := src.UpdateTask(ctx, st.ID, store.TaskUpdate{DependsOn: &deps}); err != nil {
+				return fmt.Errorf("updating %s: %w", st.ID, err)
+			}
+			infof("%s no longer depends on %s\n", st.ID, t.ID)
+		}
+		return nil
+	},
+}
+
+// moveTaskAcross recreates t on another store and deletes the original.
+func moveTaskAcross(ctx context.Context, src, dst store.Store, t *model.Task, body, projectID string) (*model.Task, error) {
+	moved, err := dst.CreateTask(ctx, t.Title, projectID, store.TaskCreateOpts{
+		Type:     t.Type,
+		Priority: t.Priority,
+		Waiting:  t.Waiting,
+		Body:     body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != moved.Status {
+		if moved, err = dst.UpdateTask(ctx, moved.ID, store.TaskUpdate{Status: &t.Status}); err != nil {
+			return nil, err
+		}
+	}
+	if err := src.DeleteTask(ctx, t.ID); err != nil {
+		return nil, fmt.Errorf("created %s but could not delete %s: %w", moved.ID, t.ID, err)
+	}
+	return moved, nil
+}
+
+var taskReadyCmd = &cobra.Command{
+	Use:   "ready",
+	Short: "Show next ready task(s)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		ready, err := s.ReadyTasks(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		if len(ready) == 0 {
+			fmt.Println("No ready tasks.")
+			return nil
+		}
+
+		showAll, _ := cmd.Flags().GetBool("all")
+		if showAll {
+			tasks := make([]model.Task, len(ready))
+			for i, t := range ready {
+				tasks[i] = *t
+			}
+			allTasks, _ := s.AllTaskMap(ctx, projectID)
+			fmt.Println(markdown.RenderTaskTable(tasks, allTasks, depPolicy()))
+		} else {
+			fmt.Printf("%s  %s\n", ready[0].ID, ready[0].Title)
+		}
+		return nil
+	},
+}
+
+var taskNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Show the next ready task, optionally with its full context",
+	Long: `Show the next ready task. With --context, print it as a single markdown
+payload for an agent: the task, its epic's body, a summary of each
+dependency (the first paragraph of its body) and every document whose ID is
+mentioned in the task or epic body.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		ready, err := s.ReadyTasks(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		if len(ready) == 0 {
+			fmt.Println("No ready tasks.")
+			return nil
+		}
+
+		withContext, _ := cmd.Flags().GetBool("context")
+		if !withContext {
+			fmt.Printf("%s  %s\n", ready[0].ID, ready[0].Title)
+			return nil
+		}
+		c, err := taskContext(ctx, s, ready[0].ID)
+		if err != nil {
+			return err
+		}
+		fmt.Print(markdown.RenderTaskContext(*c))
+		return nil
+	},
+}
+
+// taskContext collects the context bundle for a task. Dependencies live in
+// the task's store; mentioned documents may live in any store and are
+// skipped if they can't be read.
+func taskContext(ctx context.Context, s store.Store, taskID string) (*markdown.TaskContext, error) {
+	t, body, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	c := &markdown.TaskContext{Task: t, Body: body}
+	refText := body
+	if t.Epic != "" {
+		epic, epicBody, err := s.GetTask(ctx, t.Epic)
+		if err != nil {
+			return nil, err
+		}
+		c.Epic = &markdown.ContextItem{ID: epic.ID, Title: epic.Title, Body: epicBody}
+		refText += "\n" + epicBody
+	}
+	for _, depID := range t.DependsOn {
+		dep, depBody, err := s.GetTask(ctx, depID)
+		if err != nil {
+			return nil, err
+		}
+		c.Dependencies = append(c.Dependencies, markdown.ContextItem{ID: dep.ID, Title: dep.Title, Status: string(dep.Status), Body: depBody})
+	}
+	for _, ref := range id.FindRefs(refText) {
+		if typ, _ := id.TypeOf(ref); typ != id.Document {
+			continue
+		}
+		ds, err := storeForEntity(ctx, ref)
+		if err != nil {
+			continue
+		}
+		d, docBody, err := ds.GetDocument(ctx, ref)
+		if err != nil {
+			continue
+		}
+		c.Documents = append(c.Documents, markdown.ContextItem{ID: d.ID, Title: d.Title, Body: docBody})
+	}
+	return c, nil
+}
+
+var taskClaimCmd = &cobra.Command{
+	Use:   "claim",
+	Short: "Atomically take the next ready task and mark it in_progress",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		override, _ := cmd.Flags().GetBool("override")
+		if err := checkProjectWIPLimit(ctx, s, projectID, model.StatusInProgress, override); err != nil {
+			return err
+		}
+		t, err := s.ClaimTask(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			fmt.Println("No ready tasks.")
+			return nil
+		}
+		fmt.Printf("%s  %s\n", t.ID, t.Title)
+		return nil
+	},
+}
+
+var taskWaitCmd = &cobra.Command{
+	Use:   "wait <id> [description]",
+	Short: "Mark a task as waiting on an external event, or clear the wait with --clear",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		var waiting *model.WaitingOn
+		if clear, _ := cmd.Flags().GetBool("clear"); !clear {
+			if len(args) < 2 {
+				return fmt.Errorf("a description of what the task is waiting on is required (or --clear)")
+			}
+			url, _ := cmd.Flags().GetString("url")
+			until, _ := cmd.Flags().GetString("until")
+			waiting = &model.WaitingOn{Description: args[1], URL: url, Until: until}
+		}
+
+		t, err := s.UpdateTask(ctx, args[0], store.TaskUpdate{Waiting: &waiting})
+		if err != nil {
+			return err
+		}
+		if waiting == nil {
+			infof("Cleared wait on task %s\n", t.ID)
+		} else {
+			infof("Task %s is waiting on: %s\n", t.ID, waiting.Description)
+		}
+		return nil
+	},
+}
+
+var taskRemindCmd = &cobra.Command{
+	Use:   "remind <id>",
+	Short: "Set a personal reminder for a task, or clear it with --clear",
+	Long: `Set a personal reminder for a task. Reminders are kept in the data
+directory on this machine, not on the task. Setting a reminder again
+snoozes it. "compass reminders due" lists reminders that have come due.`,
+	Example: `  compass task remind AUTH-TABCDE --at 2026-02-03T09:00
+  compass task remind AUTH-TABCDE --at 2h --note "check the deploy"
+  compass task remind AUTH-TABCDE --clear`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		rs, err := reminder.Load(dataDir)
+		if err != nil {
+			return err
+		}
+		if clear, _ := cmd.Flags().GetBool("clear"); clear {
+			if err := reminder.Save(dataDir, reminder.Clear(rs, args[0])); err != nil {
+				return err
+			}
+			infof("Cleared reminder for %s\n", args[0])
+			return nil
+		}
+
+		at, _ := cmd.Flags().GetString("at")
+		if at == "" {
+			return fmt.Errorf("--at is required (or --clear)")
+		}
+		when, err := reminder.ParseAt(at, time.Now())
+		if err != nil {
+			return err
+		}
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		note, _ := cmd.Flags().GetString("note")
+		if err := reminder.Save(dataDir, reminder.Set(rs, reminder.Reminder{Task: t.ID, At: when, Note: note})); err != nil {
+			return err
+		}
+		infof("Reminder for %s set for %s\n", t.ID, when.Local().Format("2006-01-02 15:04"))
+		return nil
+	},
+}
+
+var taskWaitingCmd = &cobra.Command{
+	Use:   "waiting",
+	Short: "List tasks waiting on external events",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		tasks, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: projectID, Type: model.TypeTask})
+		if err != nil {
+			return err
+		}
+
+		var waiting []model.Task
+		for _, t := range tasks {
+			if t.Waiting != nil && t.Status != model.StatusClosed {
+				waiting = append(waiting, t)
+			}
+		}
+		fmt.Println(markdown.RenderWaitingTable(waiting, time.Now()))
+		return nil
+	},
+}
+
+var taskOpenCmd = &cobra.Command{
+	Use:   "open <id>",
+	Short: "Open a task in the cloud web UI, or copy its ID or URL",
+	Long: `Open a cloud-backed task in the browser.
+
+--copy puts the task's web URL on the clipboard instead; --copy=id copies
+the ID. Tasks on the local store have no web page, so --copy copies their
+ID.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, storeName, err := reg.ForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		url := ""
+		if sc, ok := cfg.Stores[storeName]; ok {
+			url = sc.WebURL("/tasks/" + t.ID)
+		}
+
+		if cmd.Flags().Changed("copy") {
+			what, _ := cmd.Flags().GetString("copy")
+			text := t.ID
+			switch what {
+			case "url":
+				if url != "" {
+					text = url
+				}
+			case "id":
+			default:
+				return fmt.Errorf("invalid --copy %q (valid: url, id)", what)
+			}
+			if err := copyToClipboard(text); err != nil {
+				return err
+			}
+			infof("Copied %s\n", text)
+			return nil
+		}
+
+		if url == "" {
+			return fmt.Errorf("%s is on the local store, which has no web UI", t.ID)
+		}
+		openBrowser(url)
+		fmt.Println(url)
+		return nil
+	},
+}
+
+var taskCurrentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Print the task named in the current git branch",
+	Long: `Print the task whose ID appears in the current git branch name, such as
+feature/AUTH-TABCDE-login. With --quiet only the ID is printed, for
+scripts like: compass task start $(compass task current -q)`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		taskID := branchTask()
+		if taskID == "" {
+			if b := currentBranch(); b != "" {
+				return fmt.Errorf("branch %s does not name a task (e.g. feature/AUTH-TABCDE-login)", b)
+			}
+			return fmt.Errorf("not on a git branch")
+		}
+		s, err := storeForEntity(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		t, _, err := s.GetTask(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if quiet {
+			fmt.Println(t.ID)
+			return nil
+		}
+		fmt.Printf("%s  %s (%s)\n", t.ID, t.Title, t.Status)
+		return nil
+	},
+}
+
+var taskBranchCmd = &cobra.Command{
+	Use:   "branch <id>",
+	Short: "Create a git branch for a task and start it",
+	Long: `Create and check out a git branch named from the task (branch_template in
+config.yaml, default {type}/{id}-{slug}), or switch to it if it exists,
+and mark the task in_progress.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, _, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if t.Type == model.TypeEpic {
+			return fmt.Errorf("%s is an epic; branch from one of its tasks", t.ID)
+		}
+		if _, err := git("rev-parse", "--is-inside-work-tree"); err != nil {
+			return fmt.Errorf("not in a git repository")
+		}
+
+		name := branchName(t)
+		if _, err := git("rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+			if _, err := git("checkout", "--quiet", name); err != nil {
+				return err
+			}
+			infof("Switched to branch %s\n", name)
+		} else {
+			if _, err := git("checkout", "--quiet", "-b", name); err != nil {
+				return err
+			}
+			infof("Created branch %s\n", name)
+		}
+
+		if noStart, _ := cmd.Flags().GetBool("no-start"); noStart || t.Status == model.StatusInProgress {
+			return nil
+		}
+		override, _ := cmd.Flags().GetBool("override")
+		if err := checkWIPLimit(ctx, s, t.ID, model.StatusInProgress, override); err != nil {
+			return err
+		}
+		status := model.StatusInProgress
+		if _, err := s.UpdateTask(ctx, t.ID, store.TaskUpdate{Status: &status}); err != nil {
+			return err
+		}
+		infof("Started task %s\n", t.ID)
+		return nil
+	},
+}
+
+var taskPRCmd = &cobra.Command{
+	Use:   "pr <id>",
+	Short: "Open a pull request for a task with the GitHub CLI",
+	Long: `Open a pull request for the current branch with gh, titled after the task.
+The body is the task's body followed by a "Closes <id>" trailer, which
+"compass git scan --close" picks up once the change is merged.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		t, body, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		ghArgs := []string{"pr", "create", "--title", t.Title, "--body", prBody(t.ID, body)}
+		if base, _ := cmd.Flags().GetString("base"); base != "" {
+			ghArgs = append(ghArgs, "--base", base)
+		}
+		if draft, _ := cmd.Flags().GetBool("draft"); draft {
+			ghArgs = append(ghArgs, "--draft")
+		}
+		return runGH(ghArgs...)
+	},
+}
+
+func prBody(taskID, body string) string {
+	body = strings.TrimSpace(body)
+	if body != "" {
+		body += "\n\n"
+	}
+	return body + "Closes " + taskID + "\n"
+}
+
+var taskDownloadCmd = &cobra.Command{
+	Use:   "download <id>",
+	Short: "Copy a task to .compass/ in the current directory for local editing",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		path, err := s.DownloadEntity(ctx, args[0], ".compass")
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	},
+}
+
+var taskUploadCmd = &cobra.Command{
+	Use:   "upload <id>",
+	Short: "Write a locally edited task back to the store and remove the local copy",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		s, err := storeForEntity(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		localPath := fmt.Sprintf(".compass/%s.md", args[0])
+		local, _, err := store.ReadEntity[model.Task](localPath)
+		if err != nil {
+			return fmt.Errorf("reading local file: %w", err)
+		}
+		current, body, err := s.GetTask(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		remote, err := markdown.Marshal(current, body)
+		if err != nil {
+			return err
+		}
+		upload, err := checkinConflict(cmd, args[0], localPath, local.UpdatedAt, current.UpdatedAt, remote)
+		if err != nil {
+			return err
+		}
+		if !upload {
+			infof("Kept store version of %s; local copy removed\n", args[0])
+			return nil
+		}
+
+		t, err := s.UploadTask(ctx, localPath)
+		if err != nil {
+			return err
+		}
+		infof("Uploaded task %s\n", t.ID)
+		return nil
+	},
+}
+
+func init() {
+	taskCreateCmd.Flags().StringP("project", "P", "", "project ID")
+	taskCreateCmd.Flags().StringP("parent-epic", "e", "", "parent epic ID (must reference a type=epic task)")
+	taskCreateCmd.Flags().StringP("type", "t", "task", "task type (task, epic)")
 	taskCreateCmd.Flags().IntP("priority", "p", -1, "priority (0=P0 critical, 1=P1 high, 2=P2 medium, 3=P3 low)")
 	taskCreateCmd.Flags().String("depends-on", "", "comma-separated task IDs")
+	taskCreateCmd.Flags().String("due", "", "due date (YYYY-MM-DD)")
+	taskCreateCmd.Flags().String("assignee", "", `who the task is assigned to ("me" for yourself)`)
+	taskCreateCmd.Flags().String("slug", "", slugUsage)
+	taskCreateCmd.Flags().Bool("edit", false, "write the task in $EDITOR, starting from a template")
+	taskCreateCmd.Flags().Bool("json", false, "read the task as a JSON object from stdin and print the result as JSON")
 
 	taskShowCmd.Flags().Bool("pretty", false, "render with ANSI styling")
 
 	taskListCmd.Flags().StringP("project", "P", "", "filter by project")
 	taskListCmd.Flags().StringP("parent-epic", "e", "", "filter by parent epic")
-	taskListCmd.Flags().StringP("status", "s", "", "filter by status (open, in_progress, closed)")
+	taskListCmd.Flags().StringP("status", "s", "", "filter by status (open, in_progress, blocked, closed)")
 	taskListCmd.Flags().StringP("type", "t", "", "filter by type (task, epic)")
+	taskListCmd.Flags().Bool("all-stores", false, "list tasks from every configured store, with a Store column")
+	addListFlags(taskListCmd, store.TaskSortFields, markdown.TaskColumnNames, markdown.DefaultTaskColumns)
 
 	taskUpdateCmd.Flags().String("title", "", "new title")
-	taskUpdateCmd.Flags().StringP("status", "s", "", "new status (open, in_progress, closed)")
+	taskUpdateCmd.Flags().StringP("status", "s", "", "new status (open, in_progress, blocked, closed)")
 	taskUpdateCmd.Flags().IntP("priority", "p", -1, "priority (0-3, or -1 to clear)")
 	taskUpdateCmd.Flags().String("depends-on", "", "comma-separated task IDs (replaces existing)")
+	taskUpdateCmd.Flags().String("due", "", `due date (YYYY-MM-DD, or "" to clear)`)
+	taskUpdateCmd.Flags().String("assignee", "", `who the task is assigned to ("me" for yourself, or "" to clear)`)
+	taskUpdateCmd.Flags().String("slug", "", slugUsage+`, or "" to clear`)
+	taskUpdateCmd.Flags().Bool("fix-cycle", false, "if the new dependencies form a cycle, offer to drop the ones that close it")
+	taskUpdateCmd.Flags().Bool("json", false, "read changes as a JSON object from stdin and print the result as JSON")
+	taskUpdateCmd.Flags().Bool("override", false, "change status even if it exceeds the project's WIP limit")
+	taskStartCmd.Flags().Bool("override", false, "start even if it exceeds the project's WIP limit")
+	taskBranchCmd.Flags().Bool("no-start", false, "leave the task's status alone")
+	taskBranchCmd.Flags().Bool("override", false, "start even if it exceeds the project's WIP limit")
+	taskClaimCmd.Flags().Bool("override", false, "claim even if it exceeds the project's WIP limit")
+	taskPRCmd.Flags().String("base", "", "branch to merge into (default: the repo's default branch)")
+	taskPRCmd.Flags().Bool("draft", false, "open the pull request as a draft")
 
 	taskGraphCmd.Flags().StringP("project", "P", "", "project ID")
+	taskMatrixCmd.Flags().StringP("project", "P", "", "project ID")
+	taskMatrixCmd.Flags().IntP("limit", "n", 5, "show at most this many tasks per cell (0 for all)")
+	taskMatrixCmd.Flags().Bool("json", false, "print the task IDs in each cell as JSON")
 
+	taskSimilarCmd.Flags().IntP("limit", "n", 10, "show at most this many (0 for all)")
+	taskSimilarCmd.Flags().Bool("json", false, "print matches as JSON")
 	taskReadyCmd.Flags().StringP("project", "P", "", "project ID")
 	taskReadyCmd.Flags().BoolP("all", "a", false, "show all ready tasks")
 
-	taskDeleteCmd.Flags().BoolP("force", "f", false, "skip confirmation")
+	taskBlockCmd.Flags().String("reason", "", "what the task is blocked on (required)")
+
+	taskDeleteCmd.Flags().BoolP("force", "f", false, "skip confirmation (not allowed when other tasks depend on it, unless --cascade)")
+	taskDeleteCmd.Flags().Bool("cascade", false, "remove the task from the depends_on of tasks that depend on it")
+	taskMoveCmd.Flags().String("to-project", "", "key of the project to move the task to")
+
+	taskClaimCmd.Flags().StringP("project", "P", "", "project ID")
+	taskNextCmd.Flags().StringP("project", "P", "", "project ID")
+	taskNextCmd.Flags().Bool("context", false, "include the epic, dependency summaries and mentioned documents as one markdown payload")
+
+	taskWaitCmd.Flags().String("url", "", "link to the external event (issue, ticket, thread)")
+	taskWaitCmd.Flags().String("until", "", "date the wait lapses automatically (YYYY-MM-DD)")
+	taskWaitCmd.Flags().Bool("clear", false, "clear the wait")
+
+	taskWaitingCmd.Flags().StringP("project", "P", "", "project ID")
+
+	taskRemindCmd.Flags().String("at", "", "when to remind (YYYY-MM-DDTHH:MM, YYYY-MM-DD, or a duration like 2h or 3d)")
+	taskRemindCmd.Flags().String("note", "", "note to show with the reminder")
+	taskRemindCmd.Flags().Bool("clear", false, "clear the reminder")
+
+	taskOpenCmd.Flags().String("copy", "", "copy the task's URL (or ID with --copy=id) instead of opening it")
+	taskOpenCmd.Flags().Lookup("copy").NoOptDefVal = "url"
+
+	taskUploadCmd.Flags().String("resolve", "", "resolve a conflict without prompting (local, remote, merge)")
 
 	taskCmd.AddCommand(taskCreateCmd)
 	taskCmd.AddCommand(taskListCmd)
 	taskCmd.AddCommand(taskShowCmd)
 	taskCmd.AddCommand(taskUpdateCmd)
 	taskCmd.AddCommand(taskEditCmd)
+	taskDepCmd.AddCommand(taskDepAddCmd)
+	taskDepCmd.AddCommand(taskDepRemoveCmd)
+	taskDepCmd.AddCommand(taskDepListCmd)
+	taskCmd.AddCommand(taskDepCmd)
+	taskCmd.AddCommand(taskWhyBlockedCmd)
+	taskCmd.AddCommand(taskSimilarCmd)
+	taskCmd.AddCommand(taskOpenCmd)
 	taskCmd.AddCommand(taskGraphCmd)
+	taskCmd.AddCommand(taskMatrixCmd)
 	taskCmd.AddCommand(taskStartCmd)
 	taskCmd.AddCommand(taskCloseCmd)
+	taskCmd.AddCommand(taskBlockCmd)
+	taskCmd.AddCommand(taskUnblockCmd)
+	taskCmd.AddCommand(taskWatchCmd)
+	taskCmd.AddCommand(taskUnwatchCmd)
 	taskCmd.AddCommand(taskDeleteCmd)
+	taskCmd.AddCommand(taskMoveCmd)
 	taskCmd.AddCommand(taskReadyCmd)
+	taskCmd.AddCommand(taskNextCmd)
+	taskCmd.AddCommand(taskClaimCmd)
+	taskCmd.AddCommand(taskWaitCmd)
+	taskCmd.AddCommand(taskWaitingCmd)
+	taskCmd.AddCommand(taskRemindCmd)
+	taskCmd.AddCommand(taskCurrentCmd)
+	taskCmd.AddCommand(taskBranchCmd)
+	taskCmd.AddCommand(taskPRCmd)
 	taskCmd.AddCommand(taskDownloadCmd)
 	taskCmd.AddCommand(taskUploadCmd)
 	rootCmd.AddCommand(taskCmd)
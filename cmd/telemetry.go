@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Turn anonymous usage telemetry on or off",
+	Long: `Anonymous usage telemetry helps decide which features to work on. It is
+off until you turn it on.
+
+When on, each command run records its name (such as "task create"), how long
+it took, whether it succeeded, the kind of store it used (local or cloud),
+and the compass version and platform. Arguments, IDs, titles, hostnames and
+content are never recorded. Events are kept in telemetry.jsonl in the data
+directory and sent in batches.`,
+}
+
+var telemetryOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Turn telemetry on",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpoint, _ := cmd.Flags().GetString("endpoint")
+		if endpoint != "" && !strings.HasPrefix(endpoint, "https://") && !strings.HasPrefix(endpoint, "http://") {
+			return fmt.Errorf("invalid endpoint %q: must be an http(s) URL", endpoint)
+		}
+		cfg.Telemetry = &config.TelemetryConfig{Enabled: true, Endpoint: endpoint}
+		if err := saveConfig(cfg); err != nil {
+			return err
+		}
+		infof("Telemetry on; events go to %s\n", telemetryEndpoint())
+		return nil
+	},
+}
+
+var telemetryOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Turn telemetry off and delete unsent events",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cfg.Telemetry != nil {
+			cfg.Telemetry.Enabled = false
+			if err := saveConfig(cfg); err != nil {
+				return err
+			}
+		}
+		if err := telemetry.Clear(dataDir); err != nil {
+			return err
+		}
+		infof("Telemetry off\n")
+		return nil
+	},
+}
+
+var telemetryStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether telemetry is on and how many events are waiting",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		events, err := telemetry.Buffered(dataDir)
+		if err != nil {
+			return err
+		}
+		if !telemetryEnabled() {
+			fmt.Println("Telemetry: off")
+		} else {
+			fmt.Println("Telemetry: on")
+			fmt.Printf("Endpoint: %s\n", telemetryEndpoint())
+		}
+		fmt.Printf("Unsent events: %d\n", len(events))
+		return nil
+	},
+}
+
+func telemetryEnabled() bool {
+	return cfg != nil && cfg.Telemetry != nil && cfg.Telemetry.Enabled
+}
+
+func telemetryEndpoint() string {
+	if cfg.Telemetry != nil && cfg.Telemetry.Endpoint != "" {
+		return cfg.Telemetry.Endpoint
+	}
+	return telemetry.DefaultEndpoint
+}
+
+// telemetryFlushTimeout bounds how long a command waits at exit to send a
+// batch of events.
+const telemetryFlushTimeout = 2 * time.Second
+
+// recordTelemetry buffers an event for the command that ran, when
+// telemetry is on, and sends the buffer once it is due. Failures are
+// ignored: telemetry never gets in the way of a command.
+func recordTelemetry(cmd *cobra.Command, took time.Duration, runErr error) {
+	if !telemetryEnabled() || cmd == nil {
+		return
+	}
+	storeType := "none"
+	if reg != nil {
+		for _, name := range reg.Opened() {
+			if name != "local" {
+				storeType = "cloud"
+				break
+			}
+			storeType = "local"
+		}
+	}
+	telemetry.Record(dataDir, telemetry.Event{
+		Command:    strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "),
+		DurationMS: took.Milliseconds(),
+		StoreType:  storeType,
+		OK:         runErr == nil,
+		Version:    version,
+		OS:         goos,
+		Arch:       goarch,
+		Time:       time.Now(),
+	})
+	events, err := telemetry.Buffered(dataDir)
+	if err != nil || !telemetry.Due(events, time.Now()) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), telemetryFlushTimeout)
+	defer cancel()
+	telemetry.Flush(ctx, dataDir, telemetryEndpoint())
+}
+
+func init() {
+	telemetryOnCmd.Flags().String("endpoint", "", "URL events are sent to (default "+telemetry.DefaultEndpoint+")")
+	telemetryCmd.AddCommand(telemetryOnCmd)
+	telemetryCmd.AddCommand(telemetryOffCmd)
+	telemetryCmd.AddCommand(telemetryStatusCmd)
+	rootCmd.AddCommand(telemetryCmd)
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/charmbracelet/huh"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var triageCmd = &cobra.Command{
+	Use:   "triage",
+	Short: "Walk untriaged tasks and set their priority, epic and dependencies",
+	Long: `Walk the project's untriaged tasks (open tasks with neither a priority nor
+an epic) one at a time, picking a priority, an epic and dependencies from
+lists instead of typing IDs. Leaving everything unset skips a task; Ctrl-C
+stops triage, keeping the changes made so far.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID, err := resolveProject(cmd)
+		if err != nil {
+			return err
+		}
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		tasks, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: projectID})
+		if err != nil {
+			return err
+		}
+		queue := untriaged(tasks)
+		if len(queue) == 0 {
+			infof("Nothing to triage in %s.\n", projectID)
+			return nil
+		}
+
+		var epics, open []model.Task
+		for _, t := range tasks {
+			switch {
+			case t.Type == model.TypeEpic:
+				epics = append(epics, t)
+			case t.Status != model.StatusClosed:
+				open = append(open, t)
+			}
+		}
+
+		triaged := 0
+		for i, t := range queue {
+			var candidates []model.Task
+			for _, o := range open {
+				if o.ID != t.ID {
+					candidates = append(candidates, o)
+				}
+			}
+			c, err := triagePrompt(fmt.Sprintf("[%d/%d] %s  %s", i+1, len(queue), t.ID, t.Title), epics, candidates)
+			if errors.Is(err, huh.ErrUserAborted) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			upd, ok := c.update()
+			if !ok {
+				continue
+			}
+			if _, err := s.UpdateTask(ctx, t.ID, upd); err != nil {
+				slog.Warn("task not updated", "id", t.ID, "err", err)
+				continue
+			}
+			triaged++
+		}
+		infof("Triaged %d of %d tasks in %s\n", triaged, len(queue), projectID)
+		return nil
+	},
+}
+
+// untriaged returns the open tasks that have neither a priority nor an
+// epic, the state a task is in when it's first captured.
+func untriaged(tasks []model.Task) []model.Task {
+	var out []model.Task
+	for _, t := range tasks {
+		if t.Type == model.TypeTask && t.Status != model.StatusClosed && t.Priority == nil && t.Epic == "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// triageChoice is what was picked for one task. Priority -1 and an empty
+// Epic leave those fields alone.
+type triageChoice struct {
+	Priority  int
+	Epic      string
+	DependsOn []string
+}
+
+// update converts c to a task update, reporting false when nothing was
+// picked.
+func (c triageChoice) update() (store.TaskUpdate, bool) {
+	var upd store.TaskUpdate
+	if c.Priority >= 0 {
+		p := c.Priority
+		pp := &p
+		upd.Priority = &pp
+	}
+	if c.Epic != "" {
+		upd.Epic = &c.Epic
+	}
+	if len(c.DependsOn) > 0 {
+		upd.DependsOn = &c.DependsOn
+	}
+	return upd, upd.Priority != nil || upd.Epic != nil || upd.DependsOn != nil
+}
+
+// triagePrompt asks for one task's choices. It is a variable so tests can
+// stub the interactive form.
+var triagePrompt = func(title string, epics, candidates []model.Task) (triageChoice, error) {
+	c := triageChoice{Priority: -1}
+	if err := canPrompt("triage is interactive; set priorities, epics and dependencies with 'compass task update' instead"); err != nil {
+		return c, err
+	}
+	priorities := []huh.Option[int]{huh.NewOption("Leave unprioritized", -1)}
+	for p := 0; p <= 3; p++ {
+		priorities = append(priorities, huh.NewOption(model.FormatPriority(&p), p))
+	}
+	fields := []huh.Field{
+		huh.NewNote().Title(title),
+		huh.NewSelect[int]().Title("Priority").Options(priorities...).Value(&c.Priority),
+	}
+	if len(epics) > 0 {
+		opts := []huh.Option[string]{huh.NewOption("No epic", "")}
+		for _, e := range epics {
+			opts = append(opts, huh.NewOption(e.ID+"  "+e.Title, e.ID))
+		}
+		fields = append(fields, huh.NewSelect[string]().Title("Epic").Options(opts...).Value(&c.Epic))
+	}
+	if len(candidates) > 0 {
+		opts := make([]huh.Option[string], len(candidates))
+		for i, t := range candidates {
+			opts[i] = huh.NewOption(t.ID+"  "+t.Title, t.ID)
+		}
+		fields = append(fields, huh.NewMultiSelect[string]().
+			Title("Depends on (space to toggle, / to filter)").
+			Options(opts...).
+			Filterable(true).
+			Height(10).
+			Value(&c.DependsOn))
+	}
+	err := huh.NewForm(huh.NewGroup(fields...)).Run()
+	return c, err
+}
+
+func init() {
+	triageCmd.Flags().StringP("project", "P", "", "project ID")
+	rootCmd.AddCommand(triageCmd)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/rogersnm/compass/internal/upgrade"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// executable, goos and goarch are variables so tests can stub them.
+var (
+	executable = os.Executable
+	goos       = runtime.GOOS
+	goarch     = runtime.GOARCH
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade compass to the latest release",
+	Long: `Check for the latest release and, if it is newer than this one, download
+the build for this platform, verify it against the release's checksums and
+replace the running binary with it.
+
+Releases come from GitHub unless update_source in config.yaml is
+"compasscloud" or the URL of a feed serving the same JSON. Homebrew installs
+are left to "brew upgrade".`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		check, _ := cmd.Flags().GetBool("check")
+		force, _ := cmd.Flags().GetBool("force")
+
+		feed, err := upgrade.FeedURL(cfg.UpdateSource)
+		if err != nil {
+			return err
+		}
+		client := upgrade.NewClient(feed)
+		rel, err := client.Latest(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if !upgrade.Newer(rel.Version, version) && !force {
+			if version == "dev" {
+				return fmt.Errorf("this is a development build; use --force to install %s", rel.Version)
+			}
+			fmt.Printf("compass %s is up to date\n", version)
+			return nil
+		}
+		if check {
+			fmt.Printf("compass %s is available (you have %s)\n", rel.Version, version)
+			return nil
+		}
+
+		exe, err := executable()
+		if err != nil {
+			return fmt.Errorf("finding the compass binary: %w", err)
+		}
+		if exe, err = filepath.EvalSymlinks(exe); err != nil {
+			return fmt.Errorf("finding the compass binary: %w", err)
+		}
+		if strings.Contains(filepath.ToSlash(exe), "/Cellar/") {
+			return fmt.Errorf("compass was installed with Homebrew; run: brew upgrade compass")
+		}
+
+		infof("Downloading compass %s for %s/%s\n", rel.Version, goos, goarch)
+		bin, err := client.Download(cmd.Context(), rel, goos, goarch)
+		if err != nil {
+			return err
+		}
+		if err := upgrade.Install(exe, bin); err != nil {
+			return err
+		}
+		infof("Upgraded compass %s to %s\n", version, rel.Version)
+		return nil
+	},
+}
+
+// The new version notice reads the result of the last check, kept in
+// update-check.yaml, and checks again in the background once a day.
+const (
+	updateCheckFile     = "update-check.yaml"
+	updateCheckInterval = 24 * time.Hour
+	updateCheckTimeout  = 2 * time.Second
+)
+
+type updateCheck struct {
+	Latest    string    `yaml:"latest,omitempty"`
+	CheckedAt time.Time `yaml:"checked_at"`
+}
+
+// updateDone is closed when a background update check finishes.
+var updateDone chan struct{}
+
+// noticeUpdate tells the user on stderr when the last check found a newer
+// release, and starts a new check if the last one is a day old. It stays
+// quiet for development builds, under --quiet and --dry-run, when stderr
+// isn't a terminal, and when turned off with update_check: false or
+// COMPASS_NO_UPDATE_CHECK.
+func noticeUpdate(cmd *cobra.Command) {
+	if cmd == upgradeCmd || version == "dev" || quiet || dryRun ||
+		(cfg.UpdateCheck != nil && !*cfg.UpdateCheck) || os.Getenv("COMPASS_NO_UPDATE_CHECK") != "" ||
+		!term.IsTerminal(int(os.Stderr.Fd())) {
+		return
+	}
+	path := filepath.Join(dataDir, updateCheckFile)
+	var last updateCheck
+	if data, err := os.ReadFile(path); err == nil {
+		yaml.Unmarshal(data, &last)
+	}
+	if upgrade.Newer(last.Latest, version) {
+		fmt.Fprintf(os.Stderr, "compass %s is available (you have %s); run: compass upgrade\n", last.Latest, version)
+	}
+	if time.Since(last.CheckedAt) < updateCheckInterval {
+		return
+	}
+	feed, err := upgrade.FeedURL(cfg.UpdateSource)
+	if err != nil {
+		return
+	}
+	updateDone = make(chan struct{})
+	go func() {
+		defer close(updateDone)
+		ctx, cancel := context.WithTimeout(cmd.Context(), updateCheckTimeout)
+		defer cancel()
+		next := updateCheck{Latest: last.Latest, CheckedAt: time.Now()}
+		if rel, err := upgrade.NewClient(feed).Latest(ctx); err == nil {
+			next.Latest = rel.Version
+		}
+		if data, err := yaml.Marshal(next); err == nil {
+			store.WriteFileAtomic(path, data)
+		}
+	}()
+}
+
+// waitUpdateCheck lets a background update check finish, which takes at
+// most updateCheckTimeout.
+func waitUpdateCheck() {
+	if updateDone != nil {
+		<-updateDone
+	}
+}
+
+func init() {
+	upgradeCmd.Flags().Bool("check", false, "only report whether a newer release is available")
+	upgradeCmd.Flags().Bool("force", false, "install the latest release even if it isn't newer")
+	rootCmd.AddCommand(upgradeCmd)
+}
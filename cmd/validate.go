@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rogersnm/compass/internal/id"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [path|project]",
+	Short: "Check entity files for unknown fields, bad values and broken references",
+	Long: `Parse every markdown file in the local store against the entity schemas
+and report unknown frontmatter fields, bad dates and values, IDs that don't
+match their file, and references (epic, depends_on, supersedes, release
+items and changelog) to entities that don't exist.
+
+The argument may be a data directory, a project directory, a project key
+in the local store, or a single file such as one checked out to .compass/;
+a single file's references are looked up in its store. Without one, the
+whole local store is checked.
+
+Exits non-zero if any problem is found, so it can gate CI on a repo of
+compass files; --json prints the problems for tools to read.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		target := dataDir
+		if len(args) == 1 {
+			target = args[0]
+		}
+		var problems []store.Problem
+		files := 1
+		info, err := os.Stat(target)
+		switch {
+		case err == nil && !info.IsDir():
+			problems = store.ValidateFile(target, func(entityID string) (bool, bool) {
+				return lookupEntity(ctx, entityID)
+			})
+		case err == nil:
+			if problems, files, err = store.ValidateDir(target); err != nil {
+				return err
+			}
+		case len(args) == 1 && id.ValidateKey(target) == nil:
+			_, storeName, serr := reg.ForProject(ctx, target)
+			if serr != nil {
+				return serr
+			}
+			if storeName != "local" {
+				return fmt.Errorf("project %s is on %s; validate checks local files only", target, storeName)
+			}
+			if problems, files, err = store.ValidateDir(filepath.Join(dataDir, "projects", target)); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		if asJSON {
+			if problems == nil {
+				problems = []store.Problem{}
+			}
+			if err := printJSON(struct {
+				Files    int             `json:"files"`
+				Problems []store.Problem `json:"problems"`
+			}{files, problems}); err != nil {
+				return err
+			}
+		} else {
+			for _, p := range problems {
+				if p.Entity != "" {
+					fmt.Printf("%s: %s: %s\n", p.File, p.Entity, p.Message)
+				} else {
+					fmt.Printf("%s: %s\n", p.File, p.Message)
+				}
+			}
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%d problem(s) in %d file(s) checked", len(problems), files)
+		}
+		if !asJSON {
+			infof("%d file(s) checked, no problems found\n", files)
+		}
+		return nil
+	},
+}
+
+// lookupEntity finds a referenced entity in whichever store holds it.
+func lookupEntity(ctx context.Context, entityID string) (found, epic bool) {
+	s, err := storeForEntity(ctx, entityID)
+	if err != nil {
+		return false, false
+	}
+	kind, _ := id.TypeOf(entityID)
+	switch kind {
+	case id.Task:
+		t, _, err := s.GetTask(ctx, entityID)
+		return err == nil, err == nil && t.Type == model.TypeEpic
+	case id.Document:
+		_, _, err = s.GetDocument(ctx, entityID)
+	case id.Release:
+		_, _, err = s.GetRelease(ctx, entityID)
+	default:
+		_, _, err = s.GetProject(ctx, entityID)
+	}
+	return err == nil, false
+}
+
+func init() {
+	validateCmd.Flags().Bool("json", false, "print the problems as JSON")
+	rootCmd.AddCommand(validateCmd)
+}
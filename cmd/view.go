@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var viewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Save and run named queries",
+}
+
+var viewSaveCmd = &cobra.Command{
+	Use:   "save <name> -- <command> [flags]",
+	Short: "Save a command line as a named view",
+	Long: `Save a command line as a named view, e.g.
+
+  compass view save my-backlog -- task list --project AUTH --status open
+
+Views are stored in config.yaml and replace any existing view of the same
+name.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, command := args[0], args[1:]
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("view name cannot be empty")
+		}
+		if _, _, err := findViewCommand(command); err != nil {
+			return err
+		}
+		if cfg.Views == nil {
+			cfg.Views = map[string][]string{}
+		}
+		cfg.Views[name] = command
+		if err := saveConfig(cfg); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		infof("Saved view %s: compass %s\n", name, strings.Join(command, " "))
+		return nil
+	},
+}
+
+var viewRunCmd = &cobra.Command{
+	Use:   "run <name> [-- extra flags]",
+	Short: "Run a saved view",
+	Long: `Run a saved view. Arguments after the name are appended to the saved
+command line, so later flags override saved ones:
+
+  compass view run my-backlog -- --limit 10`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		command, ok := cfg.Views[args[0]]
+		if !ok {
+			return fmt.Errorf("view %q not found", args[0])
+		}
+		return runView(cmd.Context(), append(append([]string{}, command...), args[1:]...))
+	},
+}
+
+var viewListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved views",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(cfg.Views) == 0 {
+			fmt.Println("No views saved.")
+			return nil
+		}
+		names := make([]string, 0, len(cfg.Views))
+		for name := range cfg.Views {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s: compass %s\n", name, strings.Join(cfg.Views[name], " "))
+		}
+		return nil
+	},
+}
+
+var viewDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a saved view",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, ok := cfg.Views[args[0]]; !ok {
+			return fmt.Errorf("view %q not found", args[0])
+		}
+		delete(cfg.Views, args[0])
+		if err := saveConfig(cfg); err != nil {
+			return fmt.Errorf("saving config: %w", err)
+		}
+		infof("Deleted view %s\n", args[0])
+		return nil
+	},
+}
+
+// findViewCommand resolves the command a view runs and the arguments left
+// for it. Views can't run other views.
+func findViewCommand(args []string) (*cobra.Command, []string, error) {
+	target, rest, err := rootCmd.Find(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target == rootCmd || !target.Runnable() {
+		return nil, nil, fmt.Errorf("%q is not a runnable command", strings.Join(args, " "))
+	}
+	for c := target; c != nil; c = c.Parent() {
+		if c == viewCmd {
+			return nil, nil, fmt.Errorf("views cannot run other views")
+		}
+	}
+	return target, rest, nil
+}
+
+// runView executes a saved command line in-process. Config and stores are
+// already set up by the view command's PersistentPreRunE; references in the
+// saved command are resolved here, as PersistentPreRunE would for it.
+func runView(ctx context.Context, args []string) error {
+	target, rest, err := findViewCommand(args)
+	if err != nil {
+		return err
+	}
+	if err := target.ParseFlags(rest); err != nil {
+		return err
+	}
+	target.SetContext(ctx)
+	posArgs := target.Flags().Args()
+	if err := target.ValidateArgs(posArgs); err != nil {
+		return err
+	}
+	if err := resolveRefs(target, posArgs); err != nil {
+		return err
+	}
+	if target.RunE != nil {
+		return target.RunE(target, posArgs)
+	}
+	target.Run(target, posArgs)
+	return nil
+}
+
+func init() {
+	viewCmd.AddCommand(viewSaveCmd)
+	viewCmd.AddCommand(viewRunCmd)
+	viewCmd.AddCommand(viewListCmd)
+	viewCmd.AddCommand(viewDeleteCmd)
+	rootCmd.AddCommand(viewCmd)
+}
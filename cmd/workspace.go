@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rogersnm/compass/internal/id"
+	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// workspaceFile records what a workspace held when it was last synced, so
+// sync can tell local edits from store changes.
+const workspaceFile = ".workspace.yaml"
+
+type workspace struct {
+	Project  string                     `yaml:"project"`
+	Entities map[string]workspaceEntity `yaml:"entities"`
+}
+
+type workspaceEntity struct {
+	UpdatedAt time.Time `yaml:"updated_at"` // the store's copy when synced
+	Sum       string    `yaml:"sum"`        // sha256 of the file as written
+}
+
+func workspaceDir(projectID string) string {
+	return filepath.Join(".compass", projectID)
+}
+
+// entityFile is where a workspace keeps an entity, laid out like the store.
+func entityFile(dir, entityID string) string {
+	sub := "tasks"
+	if t, _ := id.TypeOf(entityID); t == id.Document {
+		sub = "documents"
+	}
+	return filepath.Join(dir, sub, entityID+".md")
+}
+
+func readWorkspace(projectID string) (*workspace, error) {
+	data, err := os.ReadFile(filepath.Join(workspaceDir(projectID), workspaceFile))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no workspace for %s (create one with: compass workspace add %s)", projectID, projectID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ws workspace
+	if err := yaml.Unmarshal(data, &ws); err != nil {
+		return nil, fmt.Errorf("reading %s workspace: %w", projectID, err)
+	}
+	if ws.Entities == nil {
+		ws.Entities = map[string]workspaceEntity{}
+	}
+	return &ws, nil
+}
+
+func (ws *workspace) save() error {
+	data, err := yaml.Marshal(ws)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(workspaceDir(ws.Project), workspaceFile), data, 0644)
+}
+
+// fileSum returns the file's sha256, or "" if it doesn't exist.
+func fileSum(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// storeEntities returns the store's tasks and documents in a project by ID.
+func storeEntities(ctx context.Context, s store.Store, projectID string) (map[string]time.Time, error) {
+	tasks, err := s.ListTasks(ctx, store.TaskFilter{ProjectID: projectID})
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.ListDocuments(ctx, store.DocumentFilter{ProjectID: projectID})
+	if err != nil {
+		return nil, err
+	}
+	updated := make(map[string]time.Time, len(tasks)+len(docs))
+	for _, t := range tasks {
+		updated[t.ID] = t.UpdatedAt
+	}
+	for _, d := range docs {
+		updated[d.ID] = d.UpdatedAt
+	}
+	return updated, nil
+}
+
+// pull writes the store's copy of an entity into the workspace.
+func (ws *workspace) pull(ctx context.Context, s store.Store, entityID string, updatedAt time.Time) error {
+	path := entityFile(workspaceDir(ws.Project), entityID)
+	if dryRun {
+		fmt.Printf("Would pull %s: write %s\n", entityID, path)
+		return nil
+	}
+	if _, err := s.DownloadEntity(ctx, entityID, filepath.Dir(path)); err != nil {
+		return err
+	}
+	ws.Entities[entityID] = workspaceEntity{UpdatedAt: updatedAt, Sum: fileSum(path)}
+	return nil
+}
+
+// push uploads the workspace copy of an entity and pulls back the result.
+// Uploading removes the file it reads, so it reads a temporary copy.
+func (ws *workspace) push(ctx context.Context, s store.Store, entityID string, content []byte) error {
+	tmp, err := os.CreateTemp("", entityID+"-*.md")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	var updatedAt time.Time
+	if t, _ := id.TypeOf(entityID); t == id.Document {
+		d, err := s.UploadDocument(ctx, tmp.Name())
+		if err != nil {
+			return err
+		}
+		updatedAt = d.UpdatedAt
+	} else {
+		t, err := s.UploadTask(ctx, tmp.Name())
+		if err != nil {
+			return err
+		}
+		updatedAt = t.UpdatedAt
+	}
+	if dryRun {
+		return nil
+	}
+	return ws.pull(ctx, s, entityID, updatedAt)
+}
+
+// storeCopy returns the store's current copy of an entity as file content.
+func storeCopy(ctx context.Context, s store.Store, entityID string) ([]byte, error) {
+	if t, _ := id.TypeOf(entityID); t == id.Document {
+		d, body, err := s.GetDocument(ctx, entityID)
+		if err != nil {
+			return nil, err
+		}
+		return markdown.Marshal(d, body)
+	}
+	t, body, err := s.GetTask(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	return markdown.Marshal(t, body)
+}
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Edit a whole project as files under .compass/",
+	Long: `A workspace is an editable copy of a project's tasks and documents under
+.compass/<KEY>/ in the current directory, laid out like the local store
+(tasks/ and documents/), for bulk or offline editing in any editor.
+workspace sync sends local edits to the store and brings in changes made
+there since.`,
+}
+
+var workspaceAddCmd = &cobra.Command{
+	Use:   "add <project>",
+	Short: "Copy a project's tasks and documents into .compass/<KEY>/",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectID := args[0]
+		dir := workspaceDir(projectID)
+		if _, err := os.Stat(filepath.Join(dir, workspaceFile)); err == nil {
+			return fmt.Errorf("%s already has a workspace in %s; update it with: compass workspace sync %s", projectID, dir, projectID)
+		}
+		s, err := storeForProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		updated, err := storeEntities(ctx, s, projectID)
+		if err != nil {
+			return err
+		}
+		ws := &workspace{Project: projectID, Entities: map[string]workspaceEntity{}}
+		for _, entityID := range sortedKeys(updated) {
+			if err := ws.pull(ctx, s, entityID, updated[entityID]); err != nil {
+				return err
+			}
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if err := ws.save(); err != nil {
+			return err
+		}
+		infof("Checked out %d tasks and documents of %s to %s\n", len(ws.Entities), projectID, dir)
+		return nil
+	},
+}
+
+var workspaceSyncCmd = &cobra.Command{
+	Use:   "sync [project]",
+	Short: "Send workspace edits to the store and bring in the store's changes",
+	Long: `Upload the files edited since the last sync, and download what changed in
+the store. An entity changed on both sides is a conflict, settled as on
+upload: a prompt, or --resolve local, remote or merge.
+
+Without a project every workspace under .compass/ is synced. New files are
+not created as entities (use task create or doc create), and deleting a
+file doesn't delete the entity; sync restores it.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projects := args
+		if len(projects) == 0 {
+			matches, _ := filepath.Glob(filepath.Join(".compass", "*", workspaceFile))
+			for _, m := range matches {
+				projects = append(projects, filepath.Base(filepath.Dir(m)))
+			}
+			if len(projects) == 0 {
+				return fmt.Errorf("no workspaces in .compass/ (create one with: compass workspace add <project>)")
+			}
+		}
+		for _, projectID := range projects {
+			if err := syncWorkspace(cmd, projectID); err != nil {
+				return fmt.Errorf("%s: %w", projectID, err)
+			}
+		}
+		return nil
+	},
+}
+
+func syncWorkspace(cmd *cobra.Command, projectID string) error {
+	ctx := cmd.Context()
+	ws, err := readWorkspace(projectID)
+	if err != nil {
+		return err
+	}
+	s, err := storeForProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	updated, err := storeEntities(ctx, s, projectID)
+	if err != nil {
+		return err
+	}
+	dir := workspaceDir(projectID)
+
+	var pushed, pulled int
+	for _, entityID := range sortedKeys(updated) {
+		path := entityFile(dir, entityID)
+		known, ok := ws.Entities[entityID]
+		sum := fileSum(path)
+		localEdit := ok && sum != "" && sum != known.Sum
+		storeEdit := !ok || updated[entityID].After(known.UpdatedAt)
+
+		switch {
+		case !localEdit && (storeEdit || sum == ""):
+			if err := ws.pull(ctx, s, entityID, updated[entityID]); err != nil {
+				return err
+			}
+			pulled++
+			infof("Pulled %s\n", entityID)
+		case localEdit:
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if storeEdit {
+				remote, err := storeCopy(ctx, s, entityID)
+				if err != nil {
+					return err
+				}
+				choice, merged, err := resolveConflict(cmd, conflict{ID: entityID, Local: content, Remote: remote})
+				if err != nil {
+					return err
+				}
+				if choice == resolveRemote {
+					if err := ws.pull(ctx, s, entityID, updated[entityID]); err != nil {
+						return err
+					}
+					pulled++
+					infof("Pulled %s (kept the store version)\n", entityID)
+					continue
+				}
+				content = merged
+			}
+			if err := ws.push(ctx, s, entityID, content); err != nil {
+				return fmt.Errorf("%s: %w", entityID, err)
+			}
+			pushed++
+			infof("Pushed %s\n", entityID)
+		}
+	}
+
+	// Entities deleted from the store leave the workspace too, unless
+	// they were edited there.
+	for _, entityID := range sortedKeys(ws.Entities) {
+		if _, ok := updated[entityID]; ok {
+			continue
+		}
+		path := entityFile(dir, entityID)
+		if sum := fileSum(path); sum != "" && sum != ws.Entities[entityID].Sum {
+			slog.Warn("deleted from the store; keeping your edited copy", "id", entityID, "path", path)
+			continue
+		}
+		if dryRun {
+			fmt.Printf("Would remove %s: %s was deleted from the store\n", path, entityID)
+			continue
+		}
+		os.Remove(path)
+		delete(ws.Entities, entityID)
+		infof("Removed %s (deleted from the store)\n", entityID)
+	}
+
+	if dryRun {
+		return nil
+	}
+	if err := ws.save(); err != nil {
+		return err
+	}
+	infof("%s: pushed %d, pulled %d\n", projectID, pushed, pulled)
+	return nil
+}
+
+var workspaceRemoveCmd = &cobra.Command{
+	Use:   "remove <project>",
+	Short: "Delete a project's workspace from .compass/",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectID := args[0]
+		ws, err := readWorkspace(projectID)
+		if err != nil {
+			return err
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		dir := workspaceDir(projectID)
+		if !force {
+			var edited []string
+			for _, entityID := range sortedKeys(ws.Entities) {
+				if sum := fileSum(entityFile(dir, entityID)); sum != "" && sum != ws.Entities[entityID].Sum {
+					edited = append(edited, entityID)
+				}
+			}
+			if len(edited) > 0 {
+				return fmt.Errorf("%d unsynced edit(s) (%s); run compass workspace sync %s first, or use --force", len(edited), edited[0], projectID)
+			}
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		infof("Removed workspace %s\n", dir)
+		return nil
+	},
+}
+
+func init() {
+	workspaceSyncCmd.Flags().String("resolve", "", "settle conflicts without prompting: local, remote or merge")
+	workspaceRemoveCmd.Flags().Bool("force", false, "remove even with unsynced edits")
+	workspaceCmd.AddCommand(workspaceAddCmd)
+	workspaceCmd.AddCommand(workspaceSyncCmd)
+	workspaceCmd.AddCommand(workspaceRemoveCmd)
+	rootCmd.AddCommand(workspaceCmd)
+}
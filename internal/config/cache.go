@@ -0,0 +1,160 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectCacheFile holds the project-to-store cache in the data directory.
+// It is kept apart from config.yaml so caching a lookup never rewrites the
+// user's configuration, and it is safe to delete: lookups repopulate it.
+const ProjectCacheFile = "project-cache.yaml"
+
+const projectCacheHeader = "# Project-to-store cache maintained by compass. Safe to delete.\n"
+
+// LoadProjectCache reads the project cache, returning an empty map if there
+// is none yet.
+func LoadProjectCache(dataDir string) (map[string]string, error) {
+	cache := map[string]string{}
+	data, err := os.ReadFile(filepath.Join(dataDir, ProjectCacheFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return nil, fmt.Errorf("reading project cache: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("parsing %s (safe to delete): %w", ProjectCacheFile, err)
+	}
+	if cache == nil {
+		cache = map[string]string{}
+	}
+	return cache, nil
+}
+
+// SaveProjectCache replaces the project cache atomically, so concurrent
+// readers see either the old or the new cache, never a partial write.
+func SaveProjectCache(dataDir string, cache map[string]string) error {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return fmt.Errorf("creating config dir: %w", err)
+	}
+	data := []byte(projectCacheHeader)
+	if len(cache) > 0 {
+		body, err := yaml.Marshal(cache)
+		if err != nil {
+			return fmt.Errorf("marshaling project cache: %w", err)
+		}
+		data = append(data, body...)
+	}
+	tmp, err := os.CreateTemp(dataDir, ProjectCacheFile+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dataDir, ProjectCacheFile)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Most cache entries map a project key to its store. When store fetch
+// finds a key on more than one store, the user can keep the other copy
+// under an alias key, "AUTH@work", or map the key to all of its stores,
+// "local,work", so reads see both.
+
+// AliasKey is the cache key for projectKey on storeName kept alongside
+// another store's project of the same key.
+func AliasKey(projectKey, storeName string) string {
+	return projectKey + "@" + storeName
+}
+
+// SplitAlias splits an alias cache key into its project key and store.
+func SplitAlias(cacheKey string) (projectKey, storeName string, ok bool) {
+	return strings.Cut(cacheKey, "@")
+}
+
+// JoinStores is the cache value mapping a project key to several stores.
+func JoinStores(names ...string) string {
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// SplitStores returns the stores a cache value maps to: one, or several
+// for a key mapped to all of its stores.
+func SplitStores(value string) []string {
+	return strings.Split(value, ",")
+}
+
+// ProjectMapping is one project on one store, as the cache has it.
+type ProjectMapping struct {
+	Key   string // the project's key on its store
+	Store string
+	// Entry is the cache key holding the mapping: Key, or an alias.
+	Entry string
+}
+
+// Mappings expands cache into one mapping per project and store, sorted by
+// key, then store.
+func Mappings(cache map[string]string) []ProjectMapping {
+	var out []ProjectMapping
+	for entry, value := range cache {
+		key := entry
+		if k, _, ok := SplitAlias(entry); ok {
+			key = k
+		}
+		for _, name := range SplitStores(value) {
+			out = append(out, ProjectMapping{Key: key, Store: name, Entry: entry})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Key != out[j].Key {
+			return out[i].Key < out[j].Key
+		}
+		return out[i].Store < out[j].Store
+	})
+	return out
+}
+
+// CachedOn reports whether cache maps projectKey on storeName, directly,
+// under an alias or as one of several stores.
+func CachedOn(cache map[string]string, projectKey, storeName string) bool {
+	if cache[AliasKey(projectKey, storeName)] == storeName {
+		return true
+	}
+	value, ok := cache[projectKey]
+	return ok && slices.Contains(SplitStores(value), storeName)
+}
+
+// Uncache removes storeName from a mapping, leaving the entry to any
+// other stores it names, and reports whether the entry is gone.
+func Uncache(cache map[string]string, m ProjectMapping) bool {
+	var rest []string
+	for _, name := range SplitStores(cache[m.Entry]) {
+		if name != m.Store {
+			rest = append(rest, name)
+		}
+	}
+	if len(rest) == 0 {
+		delete(cache, m.Entry)
+		return true
+	}
+	cache[m.Entry] = JoinStores(rest...)
+	return false
+}
@@ -13,16 +13,45 @@ import (
 const defaultCloudHost = "compasscloud.io"
 
 type Config struct {
-	Version      int                         `yaml:"version,omitempty"`
-	DefaultStore string                      `yaml:"default_store,omitempty"` // "local" or store name
-	LocalEnabled bool                        `yaml:"local_enabled,omitempty"`
-	Stores       map[string]CloudStoreConfig `yaml:"stores,omitempty"`   // storeName -> config
-	Projects     map[string]string           `yaml:"projects,omitempty"` // projectKey -> storeName
+	Version       int                         `yaml:"version,omitempty"`
+	DefaultStore  string                      `yaml:"default_store,omitempty"` // "local" or store name
+	LocalEnabled  bool                        `yaml:"local_enabled,omitempty"`
+	LocalReadOnly bool                        `yaml:"local_read_only,omitempty"`
+	Stores        map[string]CloudStoreConfig `yaml:"stores,omitempty"`   // storeName -> config
+	Projects      map[string]string           `yaml:"projects,omitempty"` // projectKey -> storeName; kept in project-cache.yaml
+	Limits        *UsageLimits                `yaml:"limits,omitempty"`
+	Views         map[string][]string         `yaml:"views,omitempty"` // view name -> command arguments
+	Notifications []NotifySink                `yaml:"notifications,omitempty"`
+	Escalation    map[string]EscalationPolicy `yaml:"escalation,omitempty"` // projectKey -> policy
+	// BranchTemplate names branches made by "task branch", using {type},
+	// {id}, {project} and {slug}. Defaults to "{type}/{id}-{slug}".
+	BranchTemplate string `yaml:"branch_template,omitempty"`
+	// UpdateSource is where "compass upgrade" looks for releases: "github"
+	// (the default), "compasscloud" or a feed URL. UpdateCheck false turns
+	// off the new version notice.
+	UpdateSource string `yaml:"update_source,omitempty"`
+	UpdateCheck  *bool  `yaml:"update_check,omitempty"`
+	// LogLevel is debug, info, warn (the default) or error; --log-level
+	// overrides it. LogFile also writes the log to compass.log in the data
+	// directory.
+	LogLevel string `yaml:"log_level,omitempty"`
+	LogFile  bool   `yaml:"log_file,omitempty"`
+	// MissingDeps is how a dependency on a task that doesn't exist counts:
+	// "strict" (the default) keeps the task blocked, "lenient" ignores it.
+	MissingDeps string `yaml:"missing_deps,omitempty"`
+	// RenderWidth and RenderStyle set how markdown bodies are rendered, as
+	// --width and --style do: the wrap column (0 is the terminal width) and
+	// a glamour style name or .json style file ("auto" by default).
+	RenderWidth int    `yaml:"render_width,omitempty"`
+	RenderStyle string `yaml:"render_style,omitempty"`
+	// Telemetry is off unless turned on with "compass telemetry on".
+	Telemetry *TelemetryConfig `yaml:"telemetry,omitempty"`
 
-	// Legacy fields for migration detection
-	Mode           string       `yaml:"mode,omitempty"`
-	Cloud          *CloudConfig `yaml:"cloud,omitempty"`
-	DefaultProject string       `yaml:"default_project,omitempty"`
+	DefaultProject string `yaml:"default_project,omitempty"`
+
+	// Legacy v1 fields, read only so Upgrade can migrate them.
+	Mode  string       `yaml:"mode,omitempty"`
+	Cloud *CloudConfig `yaml:"cloud,omitempty"`
 }
 
 type CloudConfig struct {
@@ -31,9 +60,84 @@ type CloudConfig struct {
 
 type CloudStoreConfig struct {
 	Hostname string `yaml:"hostname,omitempty"`
-	APIKey   string `yaml:"api_key"`
-	Path     string `yaml:"path,omitempty"`     // defaults to "/api/v1"
-	Protocol string `yaml:"protocol,omitempty"` // defaults to "https"
+	// APIKey may reference environment variables ("${COMPASS_WORK_KEY}").
+	// APIKeyCmd, if set, takes precedence: its output is the key (e.g.
+	// "op read op://work/compass/key"). See ResolveAPIKey.
+	APIKey    string `yaml:"api_key,omitempty"`
+	APIKeyCmd string `yaml:"api_key_cmd,omitempty"`
+	Path      string `yaml:"path,omitempty"`     // defaults to "/api/v1"
+	Protocol  string `yaml:"protocol,omitempty"` // defaults to "https"
+	ReadOnly  bool   `yaml:"read_only,omitempty"`
+	Org       string `yaml:"org,omitempty"` // org slug sent as X-Org-Slug; empty uses the key's default org
+
+	// Network settings for self-hosted stores behind proxies or private CAs.
+	Proxy              string `yaml:"proxy,omitempty"`   // e.g. "http://proxy.corp:3128"
+	CAFile             string `yaml:"ca_file,omitempty"` // PEM bundle added to the system roots
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// WebURL returns the web UI address for path (e.g. "/tasks/AUTH-TXXXXX") on
+// the store's host.
+func (c CloudStoreConfig) WebURL(path string) string {
+	proto := c.Protocol
+	if proto == "" {
+		proto = "https"
+	}
+	return proto + "://" + c.Hostname + path
+}
+
+// UsageLimits are soft limits reported by "compass store usage". Zero means
+// no limit. DiskMB applies to the local data directory; Entities applies to
+// each store's total entity count.
+type UsageLimits struct {
+	DiskMB   int64 `yaml:"disk_mb,omitempty"`
+	Entities int   `yaml:"entities,omitempty"`
+}
+
+// TelemetryConfig controls anonymous usage telemetry.
+type TelemetryConfig struct {
+	Enabled  bool   `yaml:"enabled,omitempty"`
+	Endpoint string `yaml:"endpoint,omitempty"` // defaults to telemetry.DefaultEndpoint
+}
+
+// EscalationPolicy raises the priority of open tasks that sit untouched,
+// applied by "compass maintain".
+type EscalationPolicy struct {
+	// AfterDays is how long an open task may go without updates before its
+	// priority is raised one step. Unprioritized tasks become P3.
+	AfterDays int `yaml:"after_days"`
+	// MaxPriority is the highest priority escalation reaches; it defaults
+	// to 1 so P0 stays a human decision.
+	MaxPriority *int `yaml:"max_priority,omitempty"`
+}
+
+// Ceiling returns the highest priority the policy escalates to.
+func (p EscalationPolicy) Ceiling() int {
+	if p.MaxPriority == nil {
+		return 1
+	}
+	return *p.MaxPriority
+}
+
+// NotifySink is a destination for notifications. Type is "slack" (incoming
+// webhook), "webhook" (JSON POST) or "smtp". URL and Password may reference
+// environment variables ("${SLACK_WEBHOOK}"), like CloudStoreConfig.APIKey.
+type NotifySink struct {
+	Name string `yaml:"name,omitempty"` // defaults to the type
+	Type string `yaml:"type"`
+	// Events limits the sink to these events; empty means all of them.
+	Events []string `yaml:"events,omitempty"`
+	// Assignee is whose task_assigned events the sink gets; it defaults to
+	// the current user.
+	Assignee string `yaml:"assignee,omitempty"`
+
+	URL string `yaml:"url,omitempty"` // slack, webhook
+
+	Host     string   `yaml:"host,omitempty"` // smtp, as host:port
+	Username string   `yaml:"username,omitempty"`
+	Password string   `yaml:"password,omitempty"`
+	From     string   `yaml:"from,omitempty"`
+	To       []string `yaml:"to,omitempty"`
 }
 
 // URL assembles the full API base URL for a cloud store using c.Hostname.
@@ -50,38 +154,51 @@ func (c CloudStoreConfig) URL() string {
 }
 
 func Load(dataDir string) (*Config, error) {
+	cfg, _, err := LoadAndUpgrade(dataDir)
+	return cfg, err
+}
+
+// LoadAndUpgrade loads the config and upgrades it to the current schema in
+// memory. The returned changes describe what Upgrade did; the caller decides
+// whether to persist them.
+func LoadAndUpgrade(dataDir string) (*Config, []string, error) {
 	path := filepath.Join(dataDir, "config.yaml")
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return &Config{}, nil
+			return &Config{}, nil, nil
 		}
-		return nil, fmt.Errorf("reading config: %w", err)
+		return nil, nil, fmt.Errorf("reading config: %w", err)
 	}
 
 	var cfg Config
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("parsing config: %w", err)
+		return nil, nil, fmt.Errorf("parsing config: %w", err)
 	}
+	changes := Upgrade(&cfg)
 
-	if cfg.Version < 2 && needsMigration(&cfg) {
-		migrated := migrateV1(&cfg)
-		return migrated, nil
+	cache, err := LoadProjectCache(dataDir)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	// Backfill Hostname from map key for old configs missing the field
-	for name, sc := range cfg.Stores {
-		if sc.Hostname == "" {
-			sc.Hostname = name
-			cfg.Stores[name] = sc
+	if len(cfg.Projects) > 0 {
+		for key, storeName := range cfg.Projects {
+			if _, ok := cache[key]; !ok {
+				cache[key] = storeName
+			}
 		}
+		changes = append(changes, "moved the project cache to "+ProjectCacheFile)
 	}
-
-	return &cfg, nil
+	cfg.Projects = cache
+	return &cfg, changes, nil
 }
 
+// Save writes config.yaml, and cfg.Projects to the project cache so callers
+// that edit both (removing a store, say) stay consistent.
 func Save(dataDir string, cfg *Config) error {
-	data, err := yaml.Marshal(cfg)
+	c := *cfg
+	c.Projects = nil
+	data, err := yaml.Marshal(&c)
 	if err != nil {
 		return fmt.Errorf("marshaling config: %w", err)
 	}
@@ -89,29 +206,10 @@ func Save(dataDir string, cfg *Config) error {
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return fmt.Errorf("creating config dir: %w", err)
 	}
-	return os.WriteFile(path, data, 0644)
-}
-
-// needsMigration returns true if the config has v1 fields.
-func needsMigration(cfg *Config) bool {
-	return cfg.Mode != "" || cfg.Cloud != nil || cfg.DefaultProject != ""
-}
-
-func migrateV1(old *Config) *Config {
-	cfg := &Config{
-		Version:  2,
-		Stores:   map[string]CloudStoreConfig{},
-		Projects: map[string]string{},
-	}
-	if old.Cloud != nil && old.Cloud.APIKey != "" {
-		cfg.Stores[defaultCloudHost] = CloudStoreConfig{Hostname: defaultCloudHost, APIKey: old.Cloud.APIKey}
-		cfg.DefaultStore = defaultCloudHost
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
 	}
-	if old.Mode == "local" {
-		cfg.LocalEnabled = true
-		cfg.DefaultStore = "local"
-	}
-	return cfg
+	return SaveProjectCache(dataDir, cfg.Projects)
 }
 
 // IsEmpty returns true when no stores are configured.
@@ -131,6 +229,33 @@ func (c *Config) StoreNames() []string {
 	return names
 }
 
+// IsReadOnly reports whether the named store is marked read-only.
+func (c *Config) IsReadOnly(name string) bool {
+	if name == "local" {
+		return c.LocalReadOnly
+	}
+	return c.Stores[name].ReadOnly
+}
+
+// SetReadOnly marks the named store read-only (or writable). Returns an error
+// if the store is not configured.
+func (c *Config) SetReadOnly(name string, readOnly bool) error {
+	if name == "local" {
+		if !c.LocalEnabled {
+			return fmt.Errorf("store %q not configured", name)
+		}
+		c.LocalReadOnly = readOnly
+		return nil
+	}
+	sc, ok := c.Stores[name]
+	if !ok {
+		return fmt.Errorf("store %q not configured", name)
+	}
+	sc.ReadOnly = readOnly
+	c.Stores[name] = sc
+	return nil
+}
+
 // ValidateStoreName rejects empty, whitespace-only, and reserved store names.
 func ValidateStoreName(name string) error {
 	if strings.TrimSpace(name) == "" {
@@ -142,5 +267,8 @@ func ValidateStoreName(name string) error {
 	if name == "local" {
 		return fmt.Errorf("\"local\" is reserved for the local store")
 	}
+	if strings.Contains(name, ",") {
+		return fmt.Errorf("store name cannot contain a comma")
+	}
 	return nil
 }
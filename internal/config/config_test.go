@@ -85,6 +85,25 @@ func TestMigrateV1_CloudMode(t *testing.T) {
 	assert.Equal(t, "cpk_test123", cfg.Stores["compasscloud.io"].APIKey)
 }
 
+func TestMigrateV1_KeepsDefaultProject(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("mode: local\ndefault_project: AUTH\n"), 0644)
+
+	cfg, changes, err := LoadAndUpgrade(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "AUTH", cfg.DefaultProject)
+	assert.Empty(t, cfg.Mode)
+	assert.Contains(t, changes, "enabled local store (was mode: local)")
+	assert.Contains(t, changes, "set version: 2")
+}
+
+func TestUpgrade_Idempotent(t *testing.T) {
+	cfg := &Config{Cloud: &CloudConfig{APIKey: "cpk_x"}}
+	require.NotEmpty(t, Upgrade(cfg))
+	assert.Nil(t, cfg.Cloud)
+	assert.Empty(t, Upgrade(cfg))
+}
+
 func TestMigrateV1_Empty(t *testing.T) {
 	// Empty config (no v1 fields) should NOT migrate
 	dir := t.TempDir()
@@ -170,9 +189,10 @@ func TestLoad_BackfillHostname(t *testing.T) {
 	yaml := "version: 2\nstores:\n  compasscloud.io:\n    api_key: cpk_xxx\n"
 	os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0644)
 
-	cfg, err := Load(dir)
+	cfg, changes, err := LoadAndUpgrade(dir)
 	require.NoError(t, err)
 	assert.Equal(t, "compasscloud.io", cfg.Stores["compasscloud.io"].Hostname)
+	assert.Equal(t, []string{`set hostname of store "compasscloud.io" to "compasscloud.io"`}, changes)
 }
 
 func TestLoad_BackfillHostname_Mixed(t *testing.T) {
@@ -248,3 +268,200 @@ func TestValidateStoreName(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_UsageLimits(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("version: 2\nlimits:\n  disk_mb: 500\n  entities: 1000\n"), 0644)
+
+	cfg, err := Load(dir)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Limits)
+	assert.Equal(t, int64(500), cfg.Limits.DiskMB)
+	assert.Equal(t, 1000, cfg.Limits.Entities)
+}
+
+func TestConfig_SetReadOnly(t *testing.T) {
+	cfg := &Config{LocalEnabled: true, Stores: map[string]CloudStoreConfig{"work": {Hostname: "work.example.com"}}}
+
+	require.NoError(t, cfg.SetReadOnly("work", true))
+	assert.True(t, cfg.IsReadOnly("work"))
+	assert.False(t, cfg.IsReadOnly("local"))
+
+	require.NoError(t, cfg.SetReadOnly("local", true))
+	assert.True(t, cfg.IsReadOnly("local"))
+
+	require.NoError(t, cfg.SetReadOnly("work", false))
+	assert.False(t, cfg.IsReadOnly("work"))
+
+	assert.Error(t, cfg.SetReadOnly("missing", true))
+}
+
+func TestResolveAPIKey_EnvExpansion(t *testing.T) {
+	t.Setenv("COMPASS_TEST_KEY", "secret")
+	key, err := CloudStoreConfig{APIKey: "${COMPASS_TEST_KEY}"}.ResolveAPIKey()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", key)
+
+	key, err = CloudStoreConfig{APIKey: "plain-key"}.ResolveAPIKey()
+	require.NoError(t, err)
+	assert.Equal(t, "plain-key", key)
+
+	_, err = CloudStoreConfig{APIKey: "${COMPASS_TEST_UNSET}"}.ResolveAPIKey()
+	assert.ErrorContains(t, err, "COMPASS_TEST_UNSET")
+
+	// Only references to variables are expanded; other $s are kept.
+	for _, literal := range []string{"$5 budget", "pa$$word", "key$", "a$COMPASS_TEST_UNSET", "${not valid}"} {
+		key, err = CloudStoreConfig{APIKey: literal}.ResolveAPIKey()
+		require.NoError(t, err, literal)
+		assert.Equal(t, literal, key)
+	}
+	key, err = CloudStoreConfig{APIKey: "pre-$COMPASS_TEST_KEY"}.ResolveAPIKey()
+	require.NoError(t, err)
+	assert.Equal(t, "pre-secret", key)
+}
+
+func TestResolveAPIKey_Cmd(t *testing.T) {
+	key, err := CloudStoreConfig{APIKey: "ignored", APIKeyCmd: "echo from-cmd"}.ResolveAPIKey()
+	require.NoError(t, err)
+	assert.Equal(t, "from-cmd", key)
+
+	_, err = CloudStoreConfig{APIKeyCmd: "echo oops >&2; exit 3"}.ResolveAPIKey()
+	assert.ErrorContains(t, err, "oops")
+}
+
+func TestSave_KeepsAPIKeyReference(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("COMPASS_TEST_KEY", "secret")
+	cfg := &Config{Version: 2, Stores: map[string]CloudStoreConfig{
+		"work": {Hostname: "work.example.com", APIKey: "${COMPASS_TEST_KEY}"},
+	}}
+	require.NoError(t, Save(dir, cfg))
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "${COMPASS_TEST_KEY}")
+	assert.NotContains(t, string(data), "secret")
+}
+
+func TestSave_ProjectCacheSeparate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, Save(dir, &Config{Version: 2, LocalEnabled: true, Projects: map[string]string{"AUTH": "local"}}))
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "AUTH")
+	cache, err := LoadProjectCache(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"AUTH": "local"}, cache)
+
+	// The cache is safe to delete.
+	require.NoError(t, os.Remove(filepath.Join(dir, ProjectCacheFile)))
+	cfg, err := Load(dir)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Projects)
+}
+
+func TestMappings(t *testing.T) {
+	cache := map[string]string{
+		"AUTH":      "local",
+		"AUTH@work": "work",
+		"API":       JoinStores("work", "local"),
+	}
+	assert.Equal(t, "local,work", cache["API"])
+	assert.Equal(t, []ProjectMapping{
+		{Key: "API", Store: "local", Entry: "API"},
+		{Key: "API", Store: "work", Entry: "API"},
+		{Key: "AUTH", Store: "local", Entry: "AUTH"},
+		{Key: "AUTH", Store: "work", Entry: "AUTH@work"},
+	}, Mappings(cache))
+
+	assert.True(t, CachedOn(cache, "AUTH", "work"))
+	assert.True(t, CachedOn(cache, "API", "work"))
+	assert.False(t, CachedOn(cache, "AUTH", "home"))
+
+	assert.False(t, Uncache(cache, ProjectMapping{Key: "API", Store: "work", Entry: "API"}))
+	assert.Equal(t, "local", cache["API"])
+	assert.True(t, Uncache(cache, ProjectMapping{Key: "AUTH", Store: "work", Entry: "AUTH@work"}))
+	assert.Equal(t, map[string]string{"AUTH": "local", "API": "local"}, cache)
+}
+
+func TestLoad_MovesLegacyProjects(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("version: 2\nlocal_enabled: true\nprojects:\n  AUTH: local\n  API: local\n"), 0644)
+	require.NoError(t, SaveProjectCache(dir, map[string]string{"API": "api.example"}))
+
+	cfg, changes, err := LoadAndUpgrade(dir)
+	require.NoError(t, err)
+	assert.Contains(t, changes, "moved the project cache to "+ProjectCacheFile)
+	assert.Equal(t, map[string]string{"AUTH": "local", "API": "api.example"}, cfg.Projects)
+
+	require.NoError(t, Save(dir, cfg))
+	_, changes, err = LoadAndUpgrade(dir)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestParse_Validates(t *testing.T) {
+	cfg, err := Parse([]byte("version: 2\ndefault_store: work\nstores:\n  work:\n    hostname: compass.example.com\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "compass.example.com", cfg.Stores["work"].Hostname)
+
+	for in, want := range map[string]string{
+		"defualt_store: local\n":                               "field defualt_store not found",
+		"default_store: local\n":                               "local_enabled is false",
+		"default_store: work\n":                                `default_store "work" is not a configured store`,
+		"stores:\n  work:\n    protocol: https\n":              "stores.work.hostname is required",
+		"stores:\n  work:\n    hostname: h\n    protocol: ftp": "stores.work.protocol must be http or https",
+		"notifications:\n  - type: pager\n":                    "notifications.0.type",
+		"escalation:\n  AUTH:\n    after_days: 0\n":            "escalation.AUTH.after_days",
+		"log_level: loud\n":                                    "log_level must be debug, info, warn or error",
+		"missing_deps: loose\n":                                "missing_deps must be strict or lenient",
+		"render_width: -1\n":                                   "render_width must not be negative",
+	} {
+		_, err := Parse([]byte(in))
+		assert.ErrorContains(t, err, want, in)
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	cfg := &Config{
+		Version:      2,
+		LocalEnabled: true,
+		DefaultStore: "local",
+		Projects:     map[string]string{"AUTH": "local"},
+		Stores:       map[string]CloudStoreConfig{"work": {Hostname: "compass.example.com"}},
+	}
+
+	v, err := Get(cfg, "stores.work.hostname")
+	require.NoError(t, err)
+	assert.Equal(t, "compass.example.com", v)
+	_, err = Get(cfg, "stores.home.hostname")
+	assert.ErrorContains(t, err, "is not set")
+	_, err = Get(cfg, "projects")
+	assert.Error(t, err, "the project cache isn't part of config.yaml")
+
+	out, err := Set(cfg, "stores.work.read_only", "true")
+	require.NoError(t, err)
+	assert.True(t, out.Stores["work"].ReadOnly)
+	assert.False(t, cfg.Stores["work"].ReadOnly, "Set works on a copy")
+	assert.Equal(t, "local", out.Projects["AUTH"])
+
+	out, err = Set(out, "default_store", "work")
+	require.NoError(t, err)
+	assert.Equal(t, "work", out.DefaultStore)
+
+	out, err = Set(out, "escalation.AUTH.after_days", "7")
+	require.NoError(t, err)
+	assert.Equal(t, 7, out.Escalation["AUTH"].AfterDays)
+
+	out, err = Set(out, "branch_template", "")
+	require.NoError(t, err)
+	assert.Empty(t, out.BranchTemplate)
+
+	_, err = Set(out, "default_store", "nowhere")
+	assert.ErrorContains(t, err, "not a configured store")
+	_, err = Set(out, "colour", "blue")
+	assert.ErrorContains(t, err, "field colour not found")
+	_, err = Set(out, "default_store.name", "x")
+	assert.ErrorContains(t, err, "default_store is a value, not a section")
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parse reads config.yaml content strictly, rejecting unknown keys, and
+// validates it. Use it to check hand edits before they are saved.
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks the settings commands are routed by: the default store
+// must be configured, and each store needs a usable address.
+func (c *Config) Validate() error {
+	for _, name := range slices.Sorted(maps.Keys(c.Stores)) {
+		sc := c.Stores[name]
+		if err := ValidateStoreName(name); err != nil {
+			return fmt.Errorf("stores: %w", err)
+		}
+		if sc.Hostname == "" {
+			return fmt.Errorf("stores.%s.hostname is required", name)
+		}
+		if sc.Protocol != "" && sc.Protocol != "http" && sc.Protocol != "https" {
+			return fmt.Errorf("stores.%s.protocol must be http or https, not %q", name, sc.Protocol)
+		}
+		if sc.Path != "" && !strings.HasPrefix(sc.Path, "/") {
+			return fmt.Errorf("stores.%s.path must start with /", name)
+		}
+	}
+	switch c.DefaultStore {
+	case "":
+	case "local":
+		if !c.LocalEnabled {
+			return fmt.Errorf("default_store is local, but local_enabled is false")
+		}
+	default:
+		if _, ok := c.Stores[c.DefaultStore]; !ok {
+			return fmt.Errorf("default_store %q is not a configured store", c.DefaultStore)
+		}
+	}
+	switch strings.ToLower(c.LogLevel) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("log_level must be debug, info, warn or error, not %q", c.LogLevel)
+	}
+	if c.MissingDeps != "" && c.MissingDeps != "strict" && c.MissingDeps != "lenient" {
+		return fmt.Errorf("missing_deps must be strict or lenient, not %q", c.MissingDeps)
+	}
+	if c.RenderWidth < 0 {
+		return fmt.Errorf("render_width must not be negative")
+	}
+	for i, n := range c.Notifications {
+		if n.Type != "slack" && n.Type != "webhook" && n.Type != "smtp" {
+			return fmt.Errorf("notifications.%d.type must be slack, webhook or smtp, not %q", i, n.Type)
+		}
+	}
+	for key, p := range c.Escalation {
+		if p.AfterDays <= 0 {
+			return fmt.Errorf("escalation.%s.after_days must be at least 1", key)
+		}
+	}
+	return nil
+}
+
+// Get returns the setting at a dotted path of YAML keys, such as
+// "stores.work.hostname" or "notifications.0.url": a scalar, or a map or
+// list for a section.
+func Get(c *Config, path string) (any, error) {
+	node, err := tree(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch n := node.(type) {
+		case map[string]any:
+			node = n[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("%s is not set", path)
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("%s is not set", path)
+		}
+		if node == nil {
+			return nil, fmt.Errorf("%s is not set", path)
+		}
+	}
+	return node, nil
+}
+
+// Set returns a copy of c with the setting at a dotted path replaced by
+// value, read as YAML so "true" and "3" are a bool and a number. An empty
+// value removes the setting. The result is parsed and validated as a whole,
+// so unknown keys and broken routing are rejected.
+func Set(c *Config, path, value string) (*Config, error) {
+	node, err := tree(c)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+		return nil, fmt.Errorf("invalid value %q: %w", value, err)
+	}
+	if node, err = setPath(node, strings.Split(path, "."), v, ""); err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	out, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	out.Projects = c.Projects
+	return out, nil
+}
+
+// tree converts c to generic YAML values, leaving out the project cache
+// that isn't part of config.yaml.
+func tree(c *Config) (any, error) {
+	cp := *c
+	cp.Projects = nil
+	data, err := yaml.Marshal(&cp)
+	if err != nil {
+		return nil, err
+	}
+	var node any
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// setPath sets keys under node, found at the dotted path at, to v,
+// creating sections as needed, and returns the updated node; a nil v
+// removes the key.
+func setPath(node any, keys []string, v any, at string) (any, error) {
+	if len(keys) == 0 {
+		return v, nil
+	}
+	if node == nil {
+		if v == nil {
+			return nil, nil
+		}
+		node = map[string]any{}
+	}
+	key, here := keys[0], keys[0]
+	if at != "" {
+		here = at + "." + key
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		child, err := setPath(n[key], keys[1:], v, here)
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			delete(n, key)
+		} else {
+			n[key] = child
+		}
+		return n, nil
+	case []any:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(n) {
+			return nil, fmt.Errorf("%s: no such item (the list has %d)", here, len(n))
+		}
+		child, err := setPath(n[i], keys[1:], v, here)
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			return slices.Delete(n, i, i+1), nil
+		}
+		n[i] = child
+		return n, nil
+	}
+	return nil, fmt.Errorf("%s is a value, not a section", at)
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CurrentVersion is the config schema version written by this build.
+const CurrentVersion = 2
+
+// Upgrade migrates cfg to CurrentVersion in place and returns a description
+// of each change, in the order applied. A nil result means cfg was already
+// current. Upgrade is idempotent.
+func Upgrade(cfg *Config) []string {
+	var changes []string
+
+	if cfg.Version < CurrentVersion && needsMigration(cfg) {
+		changes = append(changes, migrateV1(cfg)...)
+	}
+
+	// Old v2 configs may lack the hostname field; it defaults to the store name.
+	names := make([]string, 0, len(cfg.Stores))
+	for name := range cfg.Stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sc := cfg.Stores[name]
+		if sc.Hostname == "" {
+			sc.Hostname = name
+			cfg.Stores[name] = sc
+			changes = append(changes, fmt.Sprintf("set hostname of store %q to %q", name, name))
+		}
+	}
+
+	return changes
+}
+
+// needsMigration returns true if the config has v1 fields.
+func needsMigration(cfg *Config) bool {
+	return cfg.Mode != "" || cfg.Cloud != nil || cfg.DefaultProject != ""
+}
+
+// migrateV1 converts a v1 config (single mode, single cloud key) to the v2
+// multi-store layout.
+func migrateV1(cfg *Config) []string {
+	var changes []string
+	if cfg.Stores == nil {
+		cfg.Stores = map[string]CloudStoreConfig{}
+	}
+	if cfg.Projects == nil {
+		cfg.Projects = map[string]string{}
+	}
+	if cfg.Cloud != nil {
+		if cfg.Cloud.APIKey != "" {
+			cfg.Stores[defaultCloudHost] = CloudStoreConfig{Hostname: defaultCloudHost, APIKey: cfg.Cloud.APIKey}
+			cfg.DefaultStore = defaultCloudHost
+			changes = append(changes, fmt.Sprintf("moved cloud.api_key to store %q", defaultCloudHost))
+		}
+		cfg.Cloud = nil
+		changes = append(changes, "removed cloud section")
+	}
+	if cfg.Mode != "" {
+		if cfg.Mode == "local" {
+			cfg.LocalEnabled = true
+			cfg.DefaultStore = "local"
+			changes = append(changes, "enabled local store (was mode: local)")
+		}
+		changes = append(changes, fmt.Sprintf("removed mode: %s", cfg.Mode))
+		cfg.Mode = ""
+	}
+	if cfg.DefaultStore != "" {
+		changes = append(changes, fmt.Sprintf("set default store to %q", cfg.DefaultStore))
+	}
+	cfg.Version = CurrentVersion
+	changes = append(changes, fmt.Sprintf("set version: %d", CurrentVersion))
+	return changes
+}
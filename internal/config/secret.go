@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// ResolveAPIKey returns the API key to send to the store. If APIKeyCmd is
+// set it is run through the shell and its trimmed stdout is the key.
+// Otherwise ${VAR} and $VAR references in APIKey are expanded from the
+// environment. config.yaml keeps the unexpanded form, so saving the config
+// never writes a secret to disk.
+func (c CloudStoreConfig) ResolveAPIKey() (string, error) {
+	if c.APIKeyCmd != "" {
+		return runKeyCmd(c.APIKeyCmd)
+	}
+	return expandEnv("api_key", c.APIKey)
+}
+
+// Resolve returns a copy of the sink with environment references in URL and
+// Password expanded.
+func (s NotifySink) Resolve() (NotifySink, error) {
+	var err error
+	if s.URL, err = expandEnv("url", s.URL); err != nil {
+		return s, err
+	}
+	if s.Password, err = expandEnv("password", s.Password); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// envRef matches ${VAR} and $VAR, where VAR is a valid variable name.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// expandEnv expands ${VAR} references in value, failing if any of them is
+// unset, and $VAR references to variables that are set. Anything else,
+// such as the $ in "pa$$word" or "$5", is kept as written, so a secret
+// containing $ needn't be escaped. field names the setting in the error.
+func expandEnv(field, value string) (string, error) {
+	var missing []string
+	v := envRef.ReplaceAllStringFunc(value, func(ref string) string {
+		m := envRef.FindStringSubmatch(ref)
+		if m[1] == "" {
+			if v, ok := os.LookupEnv(m[2]); ok {
+				return v
+			}
+			return ref
+		}
+		v, ok := os.LookupEnv(m[1])
+		if !ok {
+			missing = append(missing, m[1])
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%s references unset environment variable %s", field, strings.Join(missing, ", "))
+	}
+	return v, nil
+}
+
+func runKeyCmd(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin // secret managers may prompt for unlock
+	out, err := cmd.Output()
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && len(ee.Stderr) > 0 {
+			return "", fmt.Errorf("api_key_cmd failed: %w: %s", err, strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", fmt.Errorf("api_key_cmd failed: %w", err)
+	}
+	key := strings.TrimSpace(string(out))
+	if key == "" {
+		return "", fmt.Errorf("api_key_cmd printed nothing")
+	}
+	return key, nil
+}
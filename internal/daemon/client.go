@@ -0,0 +1,173 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/rpc"
+	"github.com/rogersnm/compass/internal/store"
+)
+
+// Client is the local store as seen by a CLI invocation while a daemon is
+// running: listings and search are answered by the daemon, everything else
+// goes to disk. If the daemon stops answering, Client falls back to disk
+// for the rest of the process.
+type Client struct {
+	*store.LocalStore
+	socket string
+	down   bool
+}
+
+var _ store.Store = (*Client)(nil)
+
+// NewClient wraps ls to query the daemon listening on socket.
+func NewClient(socket string, ls *store.LocalStore) *Client {
+	return &Client{LocalStore: ls, socket: socket}
+}
+
+// Local returns the LocalStore c reads from disk, so code that needs the
+// files themselves, such as store usage, can reach them.
+func (c *Client) Local() *store.LocalStore {
+	return c.LocalStore
+}
+
+// errUnavailable means the daemon could not be reached and the caller
+// should read from disk instead.
+var errUnavailable = errors.New("daemon unavailable")
+
+// call sends one request per connection; closing the write side ends the
+// daemon's read loop, so connections never queue behind each other.
+func (c *Client) call(method string, params, result any) error {
+	if c.down {
+		return errUnavailable
+	}
+	err := c.roundTrip(method, params, result)
+	if errors.Is(err, errUnavailable) {
+		c.down = true
+	}
+	return err
+}
+
+func (c *Client) roundTrip(method string, params, result any) error {
+	conn, err := net.DialTimeout("unix", c.socket, time.Second)
+	if err != nil {
+		return errUnavailable
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(connTimeout))
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := json.Marshal(rpc.Request{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: method, Params: raw})
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(append(req, '\n')); err != nil {
+		return errUnavailable
+	}
+	if uc, ok := conn.(*net.UnixConn); ok {
+		uc.CloseWrite()
+	}
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return errUnavailable
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpc.Error      `json:"error"`
+	}
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return errUnavailable
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%s", resp.Error.Message)
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+func (c *Client) ListProjects(ctx context.Context) ([]model.Project, error) {
+	var projects []model.Project
+	err := c.call("ListProjects", map[string]string{"store": "local"}, &projects)
+	if errors.Is(err, errUnavailable) {
+		return c.LocalStore.ListProjects(ctx)
+	}
+	return projects, err
+}
+
+// ListTasks asks the daemon for one project's tasks; listings across every
+// project read from disk, since the daemon's RPC is per project.
+func (c *Client) ListTasks(ctx context.Context, filter store.TaskFilter) ([]model.Task, error) {
+	if filter.ProjectID == "" {
+		return c.LocalStore.ListTasks(ctx, filter)
+	}
+	var tasks []model.Task
+	err := c.call("ListTasks", map[string]string{
+		"project": filter.ProjectID,
+		"epic":    filter.EpicID,
+		"status":  string(filter.Status),
+		"type":    string(filter.Type),
+	}, &tasks)
+	if errors.Is(err, errUnavailable) {
+		return c.LocalStore.ListTasks(ctx, filter)
+	}
+	return tasks, err
+}
+
+func (c *Client) ListTasksPage(ctx context.Context, filter store.TaskFilter, page store.PageOpts) ([]model.Task, string, error) {
+	tasks, err := c.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	return store.PageTasks(tasks, page)
+}
+
+func (c *Client) ListDocuments(ctx context.Context, filter store.DocumentFilter) ([]model.Document, error) {
+	if filter.ProjectID == "" {
+		return c.LocalStore.ListDocuments(ctx, filter)
+	}
+	params := map[string]any{
+		"project": filter.ProjectID, "kind": filter.Kind, "created_by": filter.CreatedBy, "search": filter.Search,
+	}
+	if !filter.Since.IsZero() {
+		params["since"] = filter.Since
+	}
+	var docs []model.Document
+	err := c.call("ListDocuments", params, &docs)
+	if errors.Is(err, errUnavailable) {
+		return c.LocalStore.ListDocuments(ctx, filter)
+	}
+	return docs, err
+}
+
+func (c *Client) ListDocumentsPage(ctx context.Context, filter store.DocumentFilter, page store.PageOpts) ([]model.Document, string, error) {
+	docs, err := c.ListDocuments(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	return store.PageDocuments(docs, page)
+}
+
+func (c *Client) Search(ctx context.Context, query string, opts store.SearchOpts) ([]store.SearchResult, error) {
+	params := map[string]any{
+		"query": query, "project": opts.ProjectID, "regex": opts.Regex,
+		"title_only": opts.TitleOnly, "body_only": opts.BodyOnly, "type": opts.Type,
+	}
+	if opts.ProjectID == "" {
+		params["store"] = "local"
+	}
+	var results []store.SearchResult
+	err := c.call("Search", params, &results)
+	if errors.Is(err, errUnavailable) {
+		return c.LocalStore.Search(ctx, query, opts)
+	}
+	return results, err
+}
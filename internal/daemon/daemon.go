@@ -0,0 +1,107 @@
+// Package daemon keeps the local store's entities parsed in memory in a
+// long-running process and answers list and search queries for CLI
+// invocations over a unix socket, so large data directories aren't
+// re-parsed on every command.
+//
+// The protocol is the JSON-RPC of internal/rpc. Only reads go through the
+// daemon; CLI writes still go straight to disk, and the daemon's cache
+// re-stats files on every read, so answers are never staler than the disk.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/rpc"
+	"github.com/rogersnm/compass/internal/store"
+)
+
+// SocketName is the daemon's socket in the data directory.
+const SocketName = "daemon.sock"
+
+// connTimeout bounds one client connection, so a stuck client can't hold
+// the daemon, which serves connections one at a time.
+const connTimeout = 30 * time.Second
+
+func SocketPath(dataDir string) string {
+	return filepath.Join(dataDir, SocketName)
+}
+
+// Listen opens the daemon socket in dataDir. A socket left behind by a
+// daemon that exited uncleanly is replaced; a live one is an error.
+func Listen(dataDir string) (net.Listener, error) {
+	path := SocketPath(dataDir)
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return net.Listen("unix", path)
+}
+
+// NewLocal returns a local store with its entity cache enabled, routed
+// through a registry whose project cache stays in memory.
+func NewLocal(dataDir string) (*store.LocalStore, *store.Registry) {
+	ls := store.NewLocal(dataDir)
+	ls.EnableCache()
+	reg := store.NewRegistry(&config.Config{Version: 2, LocalEnabled: true, DefaultStore: "local"}, "")
+	reg.Add("local", ls)
+	return ls, reg
+}
+
+// Serve answers JSON-RPC connections on ln until ctx is done. Connections
+// are handled one at a time because the registry is not safe for
+// concurrent use; CLI queries are short.
+func Serve(ctx context.Context, ln net.Listener, reg *store.Registry) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	srv := rpc.NewServer(reg)
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		start := time.Now()
+		conn.SetDeadline(start.Add(connTimeout))
+		srv.Serve(ctx, conn, conn)
+		conn.Close()
+		slog.Debug("served connection", "duration", time.Since(start))
+	}
+}
+
+// Watch re-reads changed files every interval until ctx is done, so the
+// cache is warm before the next query arrives. Errors go to onErr.
+//
+// It polls, checking file modification times, rather than subscribing to
+// filesystem notifications: that needs no platform-specific watcher, and
+// since every query re-checks its files anyway, the interval (--interval,
+// 2s by default) only bounds how stale the warm cache can get, never the
+// answers.
+func Watch(ctx context.Context, ls *store.LocalStore, every time.Duration, onErr func(error)) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		if err := ls.WarmCache(ctx); err != nil && onErr != nil {
+			onErr(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startDaemon serves dir's local store until the test ends or the returned
+// func stops it.
+func startDaemon(t *testing.T, dir string) func() {
+	t.Helper()
+	ln, err := Listen(dir)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	ls, reg := NewLocal(dir)
+	go Watch(ctx, ls, 10*time.Millisecond, nil)
+	done := make(chan struct{})
+	go func() {
+		Serve(ctx, ln, reg)
+		close(done)
+	}()
+	stop := func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestClient_QueriesDaemon(t *testing.T) {
+	dir := t.TempDir()
+	ls := store.NewLocal(dir)
+	p, err := ls.CreateProject(t.Context(), "Auth", "AUTH", "")
+	require.NoError(t, err)
+	_, err = ls.CreateTask(t.Context(), "Login form", p.ID, store.TaskCreateOpts{Body: "needs a captcha"})
+	require.NoError(t, err)
+	_, err = ls.CreateDocument(t.Context(), "Design", p.ID, store.DocumentCreateOpts{})
+	require.NoError(t, err)
+	startDaemon(t, dir)
+
+	c := NewClient(SocketPath(dir), store.NewLocal(dir))
+	projects, err := c.ListProjects(t.Context())
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+
+	tasks, err := c.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	// Writes go to disk and the next query sees them.
+	_, err = c.CreateTask(t.Context(), "Logout", p.ID, store.TaskCreateOpts{})
+	require.NoError(t, err)
+	tasks, _, err = c.ListTasksPage(t.Context(), store.TaskFilter{ProjectID: p.ID}, store.PageOpts{Sort: "title"})
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "Login form", tasks[0].Title)
+
+	docs, err := c.ListDocuments(t.Context(), store.DocumentFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	assert.Len(t, docs, 1)
+
+	results, err := c.Search(t.Context(), "captcha", store.SearchOpts{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Login form", results[0].Title)
+
+	_, err = c.ListTasks(t.Context(), store.TaskFilter{ProjectID: "NOPE"})
+	assert.Error(t, err)
+	assert.False(t, c.down)
+}
+
+func TestClient_FallsBackToDisk(t *testing.T) {
+	dir := t.TempDir()
+	ls := store.NewLocal(dir)
+	p, err := ls.CreateProject(t.Context(), "Auth", "AUTH", "")
+	require.NoError(t, err)
+	_, err = ls.CreateTask(t.Context(), "Login form", p.ID, store.TaskCreateOpts{})
+	require.NoError(t, err)
+	stop := startDaemon(t, dir)
+
+	_, err = Listen(dir)
+	assert.ErrorContains(t, err, "already listening")
+
+	stop()
+	c := NewClient(SocketPath(dir), store.NewLocal(dir))
+	tasks, err := c.ListTasks(t.Context(), store.TaskFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.True(t, c.down)
+
+	// usage sizes the files behind the client
+	local, ok := store.AsLocal(c)
+	require.True(t, ok)
+	assert.Same(t, c.LocalStore, local)
+	u, err := store.Usage(t.Context(), c, p.ID)
+	require.NoError(t, err)
+	assert.Positive(t, u.Bytes)
+}
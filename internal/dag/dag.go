@@ -2,7 +2,9 @@ package dag
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/rogersnm/compass/internal/model"
 )
@@ -29,8 +31,29 @@ func BuildFromTasks(tasks []*model.Task) *Graph {
 	return g
 }
 
-// ValidateAcyclic checks for cycles using DFS. Returns an error describing
-// the cycle path if one exists.
+// CycleError describes a dependency cycle. Path lists the cycle in
+// dependency order, starting and ending with the same task. Breakers, when
+// set, are the edges whose removal breaks every cycle through the task being
+// changed, each as [task, dependency].
+type CycleError struct {
+	Path     []string
+	Breakers [][2]string
+}
+
+func (e *CycleError) Error() string {
+	msg := "cycle detected: " + strings.Join(e.Path, " -> ")
+	if len(e.Breakers) > 0 {
+		edges := make([]string, len(e.Breakers))
+		for i, b := range e.Breakers {
+			edges[i] = b[0] + " -> " + b[1]
+		}
+		msg += "; removing " + strings.Join(edges, ", ") + " breaks it"
+	}
+	return msg
+}
+
+// ValidateAcyclic checks for cycles using DFS. Returns a *CycleError
+// describing the cycle path if one exists.
 func (g *Graph) ValidateAcyclic() error {
 	const (
 		white = 0 // unvisited
@@ -49,8 +72,7 @@ func (g *Graph) ValidateAcyclic() error {
 				continue
 			}
 			if color[dep] == gray {
-				cycle := buildCyclePath(parent, node, dep)
-				return fmt.Errorf("cycle detected: %s", cycle)
+				return &CycleError{Path: buildCyclePath(parent, node, dep)}
 			}
 			if color[dep] == white {
 				parent[dep] = node
@@ -73,7 +95,7 @@ func (g *Graph) ValidateAcyclic() error {
 	return nil
 }
 
-func buildCyclePath(parent map[string]string, from, to string) string {
+func buildCyclePath(parent map[string]string, from, to string) []string {
 	path := []string{to}
 	cur := from
 	for cur != to {
@@ -85,11 +107,20 @@ func buildCyclePath(parent map[string]string, from, to string) string {
 	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
 		path[i], path[j] = path[j], path[i]
 	}
-	result := path[0]
-	for _, p := range path[1:] {
-		result += " -> " + p
+	return path
+}
+
+// CycleBreakers returns the dependencies of id that lead back to id. If the
+// graph was acyclic before id's dependencies changed, dropping these edges
+// is the smallest change to id that removes every cycle through it.
+func (g *Graph) CycleBreakers(id string) [][2]string {
+	var edges [][2]string
+	for _, dep := range g.edges[id] {
+		if dep == id || slices.Contains(g.TransitiveDeps(dep), id) {
+			edges = append(edges, [2]string{id, dep})
+		}
 	}
-	return result
+	return edges
 }
 
 // TopologicalSort returns tasks in dependency order using Kahn's algorithm.
@@ -154,3 +154,44 @@ func TestLeaves(t *testing.T) {
 	})
 	assert.Equal(t, []string{"B"}, g.Leaves())
 }
+
+func TestRenderBlockers(t *testing.T) {
+	a := task("A")
+	b := task("B", "A")
+	c := task("C", "B", "X")
+	closed := task("D")
+	closed.Status = model.StatusClosed
+	e := task("E", "C", "D")
+	g := BuildFromTasks([]*model.Task{a, b, c, closed, e})
+
+	out := RenderBlockers(g, "E", model.StrictDeps)
+	assert.Contains(t, out, "C ")
+	assert.Contains(t, out, "B ")
+	assert.Contains(t, out, "A ")
+	assert.Contains(t, out, "X (not found)")
+	assert.NotContains(t, out, "D ")
+	assert.Empty(t, RenderBlockers(g, "A", model.StrictDeps))
+
+	out = RenderBlockers(g, "E", model.LenientDeps)
+	assert.Contains(t, out, "C ")
+	assert.NotContains(t, out, "X (not found)")
+}
+
+func TestCycleBreakers(t *testing.T) {
+	// A was just given deps on B and D; B -> C -> A closes a cycle.
+	g := BuildFromTasks([]*model.Task{
+		task("A", "B", "D"),
+		task("B", "C"),
+		task("C", "A"),
+		task("D"),
+	})
+	err := g.ValidateAcyclic()
+	var ce *CycleError
+	require.ErrorAs(t, err, &ce)
+	assert.Len(t, ce.Path, 4)
+	assert.Equal(t, ce.Path[0], ce.Path[3])
+
+	ce.Breakers = g.CycleBreakers("A")
+	assert.Equal(t, [][2]string{{"A", "B"}}, ce.Breakers)
+	assert.Contains(t, ce.Error(), "removing A -> B breaks it")
+}
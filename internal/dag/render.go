@@ -16,8 +16,8 @@ var (
 	blockedStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))  // red
 )
 
-func statusStyle(t *model.Task, allTasks map[string]*model.Task) lipgloss.Style {
-	if t.IsBlocked(allTasks) {
+func statusStyle(t *model.Task, allTasks map[string]*model.Task, deps model.DepPolicy) lipgloss.Style {
+	if t.Status == model.StatusBlocked || t.IsBlocked(allTasks, deps) {
 		return blockedStyle
 	}
 	switch t.Status {
@@ -30,8 +30,9 @@ func statusStyle(t *model.Task, allTasks map[string]*model.Task) lipgloss.Style
 	}
 }
 
-// RenderASCII produces an ASCII tree visualization of the task DAG.
-func RenderASCII(g *Graph) string {
+// RenderASCII produces an ASCII tree visualization of the task DAG, marking
+// tasks blocked under deps.
+func RenderASCII(g *Graph, deps model.DepPolicy) string {
 	if len(g.nodes) == 0 {
 		return "No tasks."
 	}
@@ -49,13 +50,13 @@ func RenderASCII(g *Graph) string {
 		if i > 0 {
 			sb.WriteString("\n")
 		}
-		renderNode(&sb, g, root, "", true, visited, allTasks)
+		renderNode(&sb, g, root, "", true, visited, allTasks, deps)
 	}
 
 	return sb.String()
 }
 
-func renderNode(sb *strings.Builder, g *Graph, id, prefix string, isLast bool, visited map[string]bool, allTasks map[string]*model.Task) {
+func renderNode(sb *strings.Builder, g *Graph, id, prefix string, isLast bool, visited map[string]bool, allTasks map[string]*model.Task, deps model.DepPolicy) {
 	t := g.nodes[id]
 	if t == nil {
 		return
@@ -69,9 +70,9 @@ func renderNode(sb *strings.Builder, g *Graph, id, prefix string, isLast bool, v
 		connector = ""
 	}
 
-	style := statusStyle(t, allTasks)
+	style := statusStyle(t, allTasks, deps)
 	statusStr := string(t.Status)
-	if t.IsBlocked(allTasks) {
+	if t.IsBlocked(allTasks, deps) {
 		statusStr = fmt.Sprintf("%s (blocked)", t.Status)
 	}
 
@@ -98,6 +99,49 @@ func renderNode(sb *strings.Builder, g *Graph, id, prefix string, isLast bool, v
 	}
 
 	for i, child := range children {
-		renderNode(sb, g, child, childPrefix, i == len(children)-1, visited, allTasks)
+		renderNode(sb, g, child, childPrefix, i == len(children)-1, visited, allTasks, deps)
+	}
+}
+
+// RenderBlockers renders the unfinished upstream tasks of id as a tree, each
+// task followed by the tasks it waits on. Closed dependencies don't block, so
+// they and everything above them are left out. Dependencies missing from the
+// graph are shown as not found, since they also block, unless policy is
+// lenient.
+func RenderBlockers(g *Graph, id string, policy model.DepPolicy) string {
+	var sb strings.Builder
+	renderBlockers(&sb, g, id, "", map[string]bool{}, policy)
+	return sb.String()
+}
+
+func renderBlockers(sb *strings.Builder, g *Graph, id, prefix string, visited map[string]bool, policy model.DepPolicy) {
+	var deps []string
+	for _, dep := range g.edges[id] {
+		t := g.nodes[dep]
+		if t == nil && policy == model.LenientDeps {
+			continue
+		}
+		if t == nil || t.Status != model.StatusClosed {
+			deps = append(deps, dep)
+		}
+	}
+	for i, dep := range deps {
+		connector, childPrefix := "├── ", prefix+"│   "
+		if i == len(deps)-1 {
+			connector, childPrefix = "└── ", prefix+"    "
+		}
+		t := g.nodes[dep]
+		if t == nil {
+			sb.WriteString(prefix + connector + dep + " (not found)\n")
+			continue
+		}
+		label := statusStyle(t, g.nodes, policy).Render(fmt.Sprintf("%s %s [%s, created by %s]", t.ID, t.Title, t.Status, t.CreatedBy))
+		if visited[dep] {
+			sb.WriteString(prefix + connector + label + " (see above)\n")
+			continue
+		}
+		visited[dep] = true
+		sb.WriteString(prefix + connector + label + "\n")
+		renderBlockers(sb, g, dep, childPrefix, visited, policy)
 	}
 }
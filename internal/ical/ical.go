@@ -0,0 +1,89 @@
+// Package ical renders task due dates and release target dates as an
+// iCalendar (RFC 5545) feed that calendar apps can import or subscribe to.
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// Feed returns a calendar with one all-day event per dated task and
+// release. Tasks and releases without a date are skipped. stamp is written
+// as each event's DTSTAMP.
+func Feed(name string, tasks []model.Task, releases []model.Release, stamp time.Time) []byte {
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//compass//compass//EN")
+	w.line("CALSCALE:GREGORIAN")
+	w.line("X-WR-CALNAME:" + escape(name))
+
+	dtstamp := stamp.UTC().Format("20060102T150405Z")
+	for _, t := range tasks {
+		if t.Due == "" {
+			continue
+		}
+		summary := "Due: " + t.Title
+		if t.Status == model.StatusClosed {
+			summary = "Done: " + t.Title
+		}
+		desc := fmt.Sprintf("%s in %s", t.ID, t.Project)
+		if t.Status != "" {
+			desc += ", " + string(t.Status)
+		}
+		w.event(t.ID, dtstamp, t.Due, summary, desc)
+	}
+	for _, r := range releases {
+		if r.TargetDate == "" {
+			continue
+		}
+		desc := fmt.Sprintf("%s in %s, %s", r.ID, r.Project, r.Status)
+		w.event(r.ID, dtstamp, r.TargetDate, "Release "+r.Version, desc)
+	}
+	w.line("END:VCALENDAR")
+	return []byte(w.String())
+}
+
+type writer struct{ strings.Builder }
+
+func (w *writer) event(uid, dtstamp, date, summary, desc string) {
+	start, err := time.Parse(model.DateFormat, date)
+	if err != nil {
+		return
+	}
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + uid + "@compass")
+	w.line("DTSTAMP:" + dtstamp)
+	w.line("DTSTART;VALUE=DATE:" + start.Format("20060102"))
+	w.line("DTEND;VALUE=DATE:" + start.AddDate(0, 0, 1).Format("20060102"))
+	w.line("SUMMARY:" + escape(summary))
+	w.line("DESCRIPTION:" + escape(desc))
+	w.line("TRANSP:TRANSPARENT")
+	w.line("END:VEVENT")
+}
+
+// line writes one content line, folded at 75 octets (including the leading
+// space of continuation lines) and CRLF-terminated. Folds never split a
+// UTF-8 sequence.
+func (w *writer) line(s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+	w.WriteString(s + "\r\n")
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
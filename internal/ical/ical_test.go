@@ -0,0 +1,41 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeed(t *testing.T) {
+	tasks := []model.Task{
+		{ID: "AUTH-TAAAAA", Title: "Ship login, finally", Project: "AUTH", Status: model.StatusOpen, Due: "2026-03-01"},
+		{ID: "AUTH-TBBBBB", Title: "Undated", Project: "AUTH", Status: model.StatusOpen},
+	}
+	releases := []model.Release{
+		{ID: "AUTH-RCCCCC", Version: "1.0.0", Project: "AUTH", Status: model.ReleasePlanned, TargetDate: "2026-03-31"},
+	}
+	out := string(Feed("AUTH", tasks, releases, time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)))
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+	assert.Contains(t, out, "UID:AUTH-TAAAAA@compass\r\n")
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20260301\r\nDTEND;VALUE=DATE:20260302\r\n")
+	assert.Contains(t, out, `SUMMARY:Due: Ship login\, finally`)
+	assert.Contains(t, out, "SUMMARY:Release 1.0.0\r\n")
+	assert.Contains(t, out, "DTSTAMP:20260201T120000Z\r\n")
+	assert.NotContains(t, out, "Undated")
+}
+
+func TestLineFolding(t *testing.T) {
+	w := &writer{}
+	w.line("SUMMARY:" + strings.Repeat("é", 100))
+	for _, l := range strings.Split(strings.TrimSuffix(w.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(l), 75)
+	}
+	unfolded := strings.ReplaceAll(w.String(), "\r\n ", "")
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("é", 100)+"\r\n", unfolded)
+}
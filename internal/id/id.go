@@ -3,12 +3,22 @@ package id
 import (
 	"crypto/rand"
 	"fmt"
+	"regexp"
 	"strings"
 	"unicode"
 )
 
 const charset = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
-const hashLen = 5
+
+// DefaultHashLen is the length of the hash in new IDs. Projects large
+// enough for it to collide often can ask for up to MaxHashLen, so IDs of
+// any length between the two are valid.
+const (
+	DefaultHashLen = 5
+	MaxHashLen     = 8
+)
+
+var refRe = regexp.MustCompile(`\b[A-Z0-9]{2,5}-[TDR][` + charset + `]{5,8}\b`)
 
 type EntityType string
 
@@ -16,6 +26,7 @@ const (
 	Project  EntityType = "project"
 	Document EntityType = "document"
 	Task     EntityType = "task"
+	Release  EntityType = "release"
 )
 
 // GenerateKey produces a project key from a name: strip non-alpha, uppercase, first 4 chars.
@@ -49,8 +60,8 @@ func ValidateKey(key string) error {
 	return nil
 }
 
-func randomHash() (string, error) {
-	b := make([]byte, hashLen)
+func randomHash(n int) (string, error) {
+	b := make([]byte, n)
 	if _, err := rand.Read(b); err != nil {
 		return "", fmt.Errorf("generating id: %w", err)
 	}
@@ -62,30 +73,48 @@ func randomHash() (string, error) {
 
 // NewTaskID returns a task ID like "AUTH-TABCDE".
 func NewTaskID(projectKey string) (string, error) {
-	if err := ValidateKey(projectKey); err != nil {
-		return "", err
-	}
-	h, err := randomHash()
-	if err != nil {
-		return "", err
-	}
-	return projectKey + "-T" + h, nil
+	return New(projectKey, Task, DefaultHashLen)
 }
 
 // NewDocID returns a document ID like "AUTH-DABCDE".
 func NewDocID(projectKey string) (string, error) {
+	return New(projectKey, Document, DefaultHashLen)
+}
+
+// NewReleaseID returns a release ID like "AUTH-RABCDE".
+func NewReleaseID(projectKey string) (string, error) {
+	return New(projectKey, Release, DefaultHashLen)
+}
+
+// New returns a random ID for an entity of type t in project projectKey,
+// with a hash of hashLen characters.
+func New(projectKey string, t EntityType, hashLen int) (string, error) {
 	if err := ValidateKey(projectKey); err != nil {
 		return "", err
 	}
-	h, err := randomHash()
+	if hashLen < DefaultHashLen || hashLen > MaxHashLen {
+		return "", fmt.Errorf("invalid id length %d: must be %d-%d", hashLen, DefaultHashLen, MaxHashLen)
+	}
+	var indicator string
+	switch t {
+	case Task:
+		indicator = "T"
+	case Document:
+		indicator = "D"
+	case Release:
+		indicator = "R"
+	default:
+		return "", fmt.Errorf("cannot generate an id for a %s", t)
+	}
+	h, err := randomHash(hashLen)
 	if err != nil {
 		return "", err
 	}
-	return projectKey + "-D" + h, nil
+	return projectKey + "-" + indicator + h, nil
 }
 
 // Parse parses an ID into (projectKey, entityType, hash, error).
-// No dash = project key. With dash: suffix must be 6 chars (T/D + 5 hash chars).
+// No dash = project key. With dash: suffix must be T/D/R and 5-8 hash chars.
 func Parse(id string) (string, EntityType, string, error) {
 	idx := strings.LastIndex(id, "-")
 	if idx < 0 {
@@ -103,8 +132,8 @@ func Parse(id string) (string, EntityType, string, error) {
 		return "", "", "", fmt.Errorf("invalid id %q: bad key: %w", id, err)
 	}
 
-	if len(suffix) != hashLen+1 {
-		return "", "", "", fmt.Errorf("invalid id %q: suffix must be %d chars (type indicator + %d hash)", id, hashLen+1, hashLen)
+	if len(suffix) < DefaultHashLen+1 || len(suffix) > MaxHashLen+1 {
+		return "", "", "", fmt.Errorf("invalid id %q: suffix must be %d-%d chars (type indicator + %d-%d hash)", id, DefaultHashLen+1, MaxHashLen+1, DefaultHashLen, MaxHashLen)
 	}
 
 	typeChar := suffix[0]
@@ -116,6 +145,8 @@ func Parse(id string) (string, EntityType, string, error) {
 		t = Task
 	case 'D':
 		t = Document
+	case 'R':
+		t = Release
 	default:
 		return "", "", "", fmt.Errorf("invalid id %q: unknown type indicator %q", id, string(typeChar))
 	}
@@ -140,3 +171,17 @@ func ProjectKeyFrom(id string) (string, error) {
 	key, _, _, err := Parse(id)
 	return key, err
 }
+
+// FindRefs returns the task, document and release IDs mentioned in text, in
+// order of first appearance and without duplicates.
+func FindRefs(text string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, ref := range refRe.FindAllString(text, -1) {
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
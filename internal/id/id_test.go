@@ -72,6 +72,7 @@ func TestValidateKey_HasDash(t *testing.T) {
 
 var taskPattern = regexp.MustCompile(`^[A-Z0-9]{2,5}-T[23456789ABCDEFGHJKMNPQRSTUVWXYZ]{5}$`)
 var docPattern = regexp.MustCompile(`^[A-Z0-9]{2,5}-D[23456789ABCDEFGHJKMNPQRSTUVWXYZ]{5}$`)
+var releasePattern = regexp.MustCompile(`^[A-Z0-9]{2,5}-R[23456789ABCDEFGHJKMNPQRSTUVWXYZ]{5}$`)
 
 func TestNewTaskID_Format(t *testing.T) {
 	id, err := NewTaskID("AUTH")
@@ -85,6 +86,12 @@ func TestNewDocID_Format(t *testing.T) {
 	assert.Regexp(t, docPattern, id)
 }
 
+func TestNewReleaseID_Format(t *testing.T) {
+	id, err := NewReleaseID("AUTH")
+	require.NoError(t, err)
+	assert.Regexp(t, releasePattern, id)
+}
+
 func TestNewTaskID_Uniqueness(t *testing.T) {
 	seen := make(map[string]bool)
 	for i := 0; i < 1000; i++ {
@@ -134,6 +141,14 @@ func TestParse_DocID(t *testing.T) {
 	assert.Equal(t, "ABCDE", hash)
 }
 
+func TestParse_ReleaseID(t *testing.T) {
+	key, typ, hash, err := Parse("AUTH-RABCDE")
+	require.NoError(t, err)
+	assert.Equal(t, "AUTH", key)
+	assert.Equal(t, Release, typ)
+	assert.Equal(t, "ABCDE", hash)
+}
+
 func TestParse_KeyWithDigit(t *testing.T) {
 	key, typ, _, err := Parse("AUTH2-T23456")
 	require.NoError(t, err)
@@ -148,7 +163,7 @@ func TestParse_Invalid(t *testing.T) {
 		"a",               // lowercase
 		"AUTH-",           // empty suffix
 		"AUTH-T",          // suffix too short
-		"AUTH-TABCDEF",    // suffix too long
+		"AUTH-TABCDEFGHJ", // suffix too long
 		"AUTH-XABCDE",    // unknown type indicator
 		"AUTH-T00000",    // 0 not in charset
 		"AUTH-TABCD1",    // 1 not in charset
@@ -164,6 +179,28 @@ func TestParse_Invalid(t *testing.T) {
 	}
 }
 
+func TestParse_LongHash(t *testing.T) {
+	key, typ, hash, err := Parse("AUTH-TABCDEF")
+	require.NoError(t, err)
+	assert.Equal(t, "AUTH", key)
+	assert.Equal(t, Task, typ)
+	assert.Equal(t, "ABCDEF", hash)
+}
+
+func TestNew_Length(t *testing.T) {
+	id, err := New("AUTH", Document, MaxHashLen)
+	require.NoError(t, err)
+	_, typ, hash, err := Parse(id)
+	require.NoError(t, err)
+	assert.Equal(t, Document, typ)
+	assert.Len(t, hash, MaxHashLen)
+
+	_, err = New("AUTH", Task, MaxHashLen+1)
+	assert.Error(t, err)
+	_, err = New("AUTH", Project, DefaultHashLen)
+	assert.Error(t, err)
+}
+
 func TestParse_RoundTrip_Task(t *testing.T) {
 	id, err := NewTaskID("AUTH")
 	require.NoError(t, err)
@@ -172,7 +209,7 @@ func TestParse_RoundTrip_Task(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "AUTH", key)
 	assert.Equal(t, Task, typ)
-	assert.Len(t, hash, hashLen)
+	assert.Len(t, hash, DefaultHashLen)
 }
 
 func TestParse_RoundTrip_Doc(t *testing.T) {
@@ -183,7 +220,7 @@ func TestParse_RoundTrip_Doc(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "AUTH", key)
 	assert.Equal(t, Document, typ)
-	assert.Len(t, hash, hashLen)
+	assert.Len(t, hash, DefaultHashLen)
 }
 
 func TestTypeOf(t *testing.T) {
@@ -217,3 +254,9 @@ func TestProjectKeyFrom_DocID(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "AUTH", key)
 }
+
+func TestFindRefs(t *testing.T) {
+	text := "See AUTH-DABCDE and API-TXYZ23, then AUTH-DABCDE again. Not AUTH-D12345 or auth-dabcde."
+	assert.Equal(t, []string{"AUTH-DABCDE", "API-TXYZ23"}, FindRefs(text))
+	assert.Empty(t, FindRefs("no refs here"))
+}
@@ -0,0 +1,80 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// ContextItem is a related entity included in a task's context bundle.
+// Status is empty for documents and epics.
+type ContextItem struct {
+	ID     string
+	Title  string
+	Status string
+	Body   string
+}
+
+// TaskContext gathers what an agent needs to start on a task: the task
+// itself, its epic, its dependencies and the documents it mentions.
+type TaskContext struct {
+	Task         *model.Task
+	Body         string
+	Epic         *ContextItem
+	Dependencies []ContextItem
+	Documents    []ContextItem
+}
+
+// RenderTaskContext renders a context bundle as a single markdown payload.
+// Epic and document bodies are included in full; dependencies are
+// summarized by the first paragraph of their body.
+func RenderTaskContext(c TaskContext) string {
+	var b strings.Builder
+	t := c.Task
+	fmt.Fprintf(&b, "# %s: %s\n\n", t.ID, t.Title)
+	fmt.Fprintf(&b, "- Project: %s\n", t.Project)
+	fmt.Fprintf(&b, "- Status: %s\n", t.Status)
+	if t.Priority != nil {
+		fmt.Fprintf(&b, "- Priority: %s\n", model.FormatPriority(t.Priority))
+	}
+	if c.Epic != nil {
+		fmt.Fprintf(&b, "- Epic: %s %s\n", c.Epic.ID, c.Epic.Title)
+	}
+	writeBody(&b, c.Body)
+
+	if c.Epic != nil {
+		fmt.Fprintf(&b, "## Epic: %s %s\n", c.Epic.ID, c.Epic.Title)
+		writeBody(&b, c.Epic.Body)
+	}
+
+	if len(c.Dependencies) > 0 {
+		b.WriteString("## Dependencies\n\n")
+		for _, d := range c.Dependencies {
+			fmt.Fprintf(&b, "### %s %s [%s]\n", d.ID, d.Title, d.Status)
+			writeBody(&b, firstParagraph(d.Body))
+		}
+	}
+
+	for _, d := range c.Documents {
+		fmt.Fprintf(&b, "## Document: %s %s\n", d.ID, d.Title)
+		writeBody(&b, d.Body)
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func writeBody(b *strings.Builder, body string) {
+	b.WriteString("\n")
+	if body = strings.TrimSpace(body); body != "" {
+		b.WriteString(body)
+		b.WriteString("\n\n")
+	}
+}
+
+func firstParagraph(body string) string {
+	body = strings.TrimSpace(body)
+	if i := strings.Index(body, "\n\n"); i >= 0 {
+		return body[:i]
+	}
+	return body
+}
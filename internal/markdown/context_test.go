@@ -0,0 +1,45 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTaskContext(t *testing.T) {
+	p := 1
+	out := RenderTaskContext(TaskContext{
+		Task: &model.Task{ID: "TP-TAAAAA", Title: "Add login", Project: "TP", Status: model.StatusOpen, Priority: &p},
+		Body: "Use the flow in TP-DBBBBB.",
+		Epic: &ContextItem{ID: "TP-TEEEEE", Title: "Auth", Body: "Epic goals."},
+		Dependencies: []ContextItem{
+			{ID: "TP-TCCCCC", Title: "Schema", Status: "closed", Body: "Summary line.\n\nDetails not included."},
+		},
+		Documents: []ContextItem{{ID: "TP-DBBBBB", Title: "Login flow", Body: "Steps."}},
+	})
+
+	assert.Equal(t, `# TP-TAAAAA: Add login
+
+- Project: TP
+- Status: open
+- Priority: P1
+- Epic: TP-TEEEEE Auth
+
+Use the flow in TP-DBBBBB.
+
+## Epic: TP-TEEEEE Auth
+
+Epic goals.
+
+## Dependencies
+
+### TP-TCCCCC Schema [closed]
+
+Summary line.
+
+## Document: TP-DBBBBB Login flow
+
+Steps.
+`, out)
+}
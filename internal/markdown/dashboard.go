@@ -0,0 +1,153 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// Dashboard is a one-screen summary of a project, built by the caller from
+// store queries.
+type Dashboard struct {
+	Project model.Project
+	Epics   []EpicProgress
+	// Counts holds the number of tasks in each status.
+	Counts map[model.Status]int
+	// Blocked counts unfinished tasks held up by dependencies, waits or a
+	// manual block.
+	Blocked int
+	Ready   []model.Task // head of the ready queue
+	Due     []model.Task // unfinished tasks due soon or overdue, soonest first
+	Recent  []Activity   // latest status changes, newest first
+	Today   string       // YYYY-MM-DD, to mark overdue tasks
+}
+
+// EpicProgress pairs an epic with its tasks' progress.
+type EpicProgress struct {
+	Epic     model.Task
+	Progress model.Progress
+}
+
+// Activity is one status change on a task.
+type Activity struct {
+	Task   model.Task
+	Change model.StatusChange
+}
+
+var panelStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("8")).
+	Padding(0, 1)
+
+// dashboardWide is the terminal width from which panels sit side by side.
+const dashboardWide = 100
+
+// RenderDashboard lays the dashboard out in panels, two to a row on wide
+// terminals and stacked otherwise.
+func RenderDashboard(d Dashboard) string {
+	return renderDashboard(d, termWidth())
+}
+
+func renderDashboard(d Dashboard, width int) string {
+	// Panels fill their column; the border takes a cell on each side.
+	col := width
+	if width >= dashboardWide {
+		col = width / 2
+	}
+	panel := func(title string, lines []string) string {
+		return panelStyle.Width(col - 2).Render(headerStyle.Render(title) + "\n" + strings.Join(lines, "\n"))
+	}
+	panels := []string{
+		panel("Status", statusLines(d)),
+		panel("Epics", epicLines(d.Epics)),
+		panel("Ready", taskLines(d.Ready, "Nothing ready.", func(t model.Task) string {
+			return model.FormatPriority(t.Priority)
+		})),
+		panel("Due soon", taskLines(d.Due, "Nothing due.", func(t model.Task) string {
+			if t.Due < d.Today {
+				return blockedSty.Render(t.Due)
+			}
+			return t.Due
+		})),
+		panel("Recent activity", activityLines(d.Recent)),
+	}
+
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(fmt.Sprintf("%s (%s)", d.Project.Name, d.Project.ID)))
+	b.WriteString("\n")
+	if col == width {
+		b.WriteString(lipgloss.JoinVertical(lipgloss.Left, panels...))
+		b.WriteString("\n")
+		return b.String()
+	}
+	for i := 0; i < len(panels); i += 2 {
+		row := panels[i:min(i+2, len(panels))]
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, row...))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func statusLines(d Dashboard) []string {
+	var lines []string
+	for _, st := range model.Statuses {
+		lines = append(lines, fmt.Sprintf("%s %d", StatusStyle(string(st)).Render(fmt.Sprintf("%-12s", st)), d.Counts[st]))
+	}
+	blocked := fmt.Sprintf("%d held up", d.Blocked)
+	if d.Blocked > 0 {
+		blocked = blockedSty.Render(blocked)
+	}
+	return append(lines, "", blocked)
+}
+
+func epicLines(epics []EpicProgress) []string {
+	if len(epics) == 0 {
+		return []string{"No epics."}
+	}
+	var lines []string
+	for _, e := range epics {
+		lines = append(lines, fmt.Sprintf("%s %3d%%  %s", RenderProgress(e.Progress.Closed, e.Progress.Total, 12), e.Progress.Percent(), truncate(e.Epic.Title, 28)))
+	}
+	return lines
+}
+
+func taskLines(tasks []model.Task, none string, note func(model.Task) string) []string {
+	if len(tasks) == 0 {
+		return []string{none}
+	}
+	var lines []string
+	for _, t := range tasks {
+		line := t.ID + "  " + truncate(t.Title, 32)
+		if n := note(t); n != "" {
+			line += "  " + n
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func activityLines(recent []Activity) []string {
+	if len(recent) == 0 {
+		return []string{"No activity yet."}
+	}
+	var lines []string
+	for _, a := range recent {
+		lines = append(lines, fmt.Sprintf("%s  %s %s %s",
+			labelStyle.Render(a.Change.At.Format("Jan 02 15:04")),
+			a.Task.ID,
+			StatusStyle(string(a.Change.Status)).Render(string(a.Change.Status)),
+			labelStyle.Render("by "+a.Change.By)))
+	}
+	return lines
+}
+
+// truncate shortens s to n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
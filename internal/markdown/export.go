@@ -0,0 +1,77 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// ExportGFM renders a document as standalone GitHub-flavored markdown: the
+// title as an H1 followed by the body, with frontmatter dropped.
+func ExportGFM(d *model.Document, body string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n\n", d.Title)
+	buf.WriteString(strings.TrimLeft(body, "\n"))
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// ExportHTML renders a document as a self-contained HTML page suitable for
+// sharing or printing to PDF.
+func ExportHTML(d *model.Document, body string) ([]byte, error) {
+	var content bytes.Buffer
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	if err := md.Convert([]byte(body), &content); err != nil {
+		return nil, fmt.Errorf("rendering markdown: %w", err)
+	}
+
+	var out bytes.Buffer
+	err := exportTemplate.Execute(&out, map[string]any{
+		"Title":     d.Title,
+		"ID":        d.ID,
+		"Project":   d.Project,
+		"CreatedBy": d.CreatedBy,
+		"Updated":   d.UpdatedAt.Format("2006-01-02"),
+		// goldmark escapes raw HTML in the source by default.
+		"Content": template.HTML(content.String()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+var exportTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.6; color: #1f2328; max-width: 46em; margin: 2em auto; padding: 0 1em; }
+  header { border-bottom: 1px solid #d0d7de; margin-bottom: 1.5em; }
+  header p { color: #59636e; font-size: 0.9em; margin-top: 0; }
+  pre, code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.9em; }
+  pre { background: #f6f8fa; padding: 1em; overflow-x: auto; border-radius: 6px; }
+  table { border-collapse: collapse; }
+  th, td { border: 1px solid #d0d7de; padding: 0.3em 0.8em; }
+  blockquote { color: #59636e; border-left: 0.25em solid #d0d7de; margin-left: 0; padding-left: 1em; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p>{{.ID}} &middot; {{.Project}} &middot; {{.CreatedBy}} &middot; updated {{.Updated}}</p>
+</header>
+<main>
+{{.Content}}
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,36 @@
+package markdown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportDoc() *model.Document {
+	return &model.Document{
+		ID: "AUTH-DABCDE", Title: "Login <design>", Project: "AUTH", CreatedBy: "ann",
+		UpdatedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestExportGFM(t *testing.T) {
+	out := ExportGFM(exportDoc(), "\nSome *text*.")
+	assert.Equal(t, "# Login <design>\n\nSome *text*.\n", string(out))
+}
+
+func TestExportHTML(t *testing.T) {
+	body := "## Goals\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n- [x] done\n\n<script>alert(1)</script>\n"
+	out, err := ExportHTML(exportDoc(), body)
+	require.NoError(t, err)
+	html := string(out)
+
+	assert.Contains(t, html, "<title>Login &lt;design&gt;</title>")
+	assert.Contains(t, html, "<h2>Goals</h2>")
+	assert.Contains(t, html, "<table>")          // GFM tables
+	assert.Contains(t, html, `type="checkbox"`)  // GFM task lists
+	assert.NotContains(t, html, "<script>alert") // raw HTML is not passed through
+	assert.Contains(t, html, "updated 2026-03-01")
+}
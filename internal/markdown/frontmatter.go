@@ -20,6 +20,27 @@ func Parse[T any](r io.Reader) (T, string, error) {
 	return meta, strings.TrimSpace(string(body)), nil
 }
 
+// Split returns the YAML between a file's leading "---" lines and the
+// trimmed body after them, without decoding either.
+func Split(data []byte) ([]byte, string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	rest, ok := bytes.CutPrefix(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), []byte("---\n"))
+	if !ok {
+		return nil, "", fmt.Errorf("no frontmatter: file must start with a --- line")
+	}
+	if body, ok := bytes.CutPrefix(rest, []byte("---\n")); ok {
+		return nil, strings.TrimSpace(string(body)), nil
+	}
+	front, body, ok := bytes.Cut(rest, []byte("\n---\n"))
+	if !ok {
+		front, ok = bytes.CutSuffix(bytes.TrimRight(rest, "\n"), []byte("\n---"))
+	}
+	if !ok {
+		return nil, "", fmt.Errorf("frontmatter is not closed with a --- line")
+	}
+	return front, strings.TrimSpace(string(body)), nil
+}
+
 // Marshal serializes meta as YAML frontmatter followed by body.
 func Marshal[T any](meta T, body string) ([]byte, error) {
 	yamlBytes, err := yaml.Marshal(meta)
@@ -0,0 +1,88 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Heatmap counts task activity per day for a contribution grid.
+type Heatmap struct {
+	// End is the last day drawn. The grid runs Monday to Sunday, ending
+	// with End's week.
+	End   time.Time
+	Weeks int
+	// Created and Closed count tasks by local day, keyed YYYY-MM-DD.
+	Created map[string]int
+	Closed  map[string]int
+}
+
+// heatGlyphs shade a day by its activity relative to the busiest day,
+// none first.
+var heatGlyphs = []string{"·", "░", "▒", "▓", "█"}
+
+var heatStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+
+// RenderHeatmap draws h as a grid of days, one column per week with
+// month labels above, followed by the totals and a legend.
+func RenderHeatmap(h Heatmap) string {
+	end := time.Date(h.End.Year(), h.End.Month(), h.End.Day(), 0, 0, 0, 0, h.End.Location())
+	// Monday of End's week, then back to the first week shown.
+	start := end.AddDate(0, 0, -((int(end.Weekday())+6)%7)-7*(h.Weeks-1))
+
+	var created, closed, busiest int
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		key := d.Format(time.DateOnly)
+		created += h.Created[key]
+		closed += h.Closed[key]
+		busiest = max(busiest, h.Created[key]+h.Closed[key])
+	}
+
+	var b strings.Builder
+	months := []byte(strings.Repeat(" ", 4+2*h.Weeks))
+	// Label each week that starts a new month, unless the label would run
+	// into the previous one or off the grid.
+	for w, last, free := 0, time.Month(0), 0; w < h.Weeks; w++ {
+		d, col := start.AddDate(0, 0, 7*w), 4+2*w
+		if d.Month() != last && col >= free && col+3 <= len(months) {
+			copy(months[col:], d.Format("Jan"))
+			free = col + 4
+		}
+		last = d.Month()
+	}
+	b.WriteString(strings.TrimRight(string(months), " ") + "\n")
+
+	for day, label := range []string{"Mon", "", "Wed", "", "Fri", "", "Sun"} {
+		var cells []string
+		for w := 0; w < h.Weeks; w++ {
+			d := start.AddDate(0, 0, 7*w+day)
+			if d.After(end) {
+				break
+			}
+			key := d.Format(time.DateOnly)
+			cells = append(cells, heatCell(h.Created[key]+h.Closed[key], busiest))
+		}
+		fmt.Fprintf(&b, "%-4s%s\n", label, strings.Join(cells, " "))
+	}
+
+	fmt.Fprintf(&b, "\n%d created, %d closed in %d weeks    Less", created, closed, h.Weeks)
+	for i, g := range heatGlyphs {
+		if i > 0 {
+			g = heatStyle.Render(g)
+		}
+		b.WriteString(" " + g)
+	}
+	b.WriteString(" More")
+	return b.String()
+}
+
+// heatCell shades a day with n events, scaled so the busiest day is full.
+func heatCell(n, busiest int) string {
+	if n == 0 {
+		return heatGlyphs[0]
+	}
+	level := (n*(len(heatGlyphs)-1) + busiest - 1) / busiest
+	return heatStyle.Render(heatGlyphs[level])
+}
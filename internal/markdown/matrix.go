@@ -0,0 +1,120 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// Matrix sorts a project's unfinished tasks into a grid by state and
+// priority, for planning.
+type Matrix struct {
+	// Cells is indexed by MatrixRows and then by priority, with tasks that
+	// have none in the last column.
+	Cells [3][5][]model.Task
+	// Limit caps the tasks listed per cell; the rest are counted. 0 lists
+	// all.
+	Limit int
+}
+
+// MatrixRows names the matrix rows, top to bottom.
+var MatrixRows = []string{"Blocked", "Ready", "In progress"}
+
+// NewMatrix places each open, blocked or in-progress task of tasks in its
+// row and column. Open tasks held up by dependencies or a wait count as
+// blocked; closed tasks and epics are left out.
+func NewMatrix(tasks []model.Task, allTasks map[string]*model.Task, deps model.DepPolicy) Matrix {
+	var m Matrix
+	SortTasks(tasks, allTasks, deps)
+	for _, t := range tasks {
+		var row int
+		switch {
+		case t.Type == model.TypeEpic || t.Status == model.StatusClosed:
+			continue
+		case t.Status == model.StatusInProgress:
+			row = 2
+		case t.Status == model.StatusBlocked || t.IsBlocked(allTasks, deps):
+			row = 0
+		default:
+			row = 1
+		}
+		col := 4
+		if t.Priority != nil {
+			col = *t.Priority
+		}
+		m.Cells[row][col] = append(m.Cells[row][col], t)
+	}
+	return m
+}
+
+// RenderMatrix draws the matrix as a table sized to the terminal, with a
+// column for tasks without a priority only when there are some.
+func RenderMatrix(m Matrix) string {
+	return renderMatrix(m, termWidth())
+}
+
+func renderMatrix(m Matrix, width int) string {
+	headers := []string{"", "P0", "P1", "P2", "P3"}
+	cols := 4
+	for _, row := range m.Cells {
+		if len(row[4]) > 0 {
+			headers = append(headers, "None")
+			cols = 5
+			break
+		}
+	}
+	// Share what the borders, padding and row labels leave between the
+	// priority columns.
+	label := len("In progress") + 2
+	cellWidth := max((width-label-cols-2)/cols-2, 12)
+
+	var rows [][]string
+	for r, name := range MatrixRows {
+		row := []string{name}
+		for c := range cols {
+			row = append(row, matrixCell(m.Cells[r][c], m.Limit, cellWidth))
+		}
+		rows = append(rows, row)
+	}
+	rowStyles := []lipgloss.Style{blockedSty, StatusStyle(string(model.StatusOpen)), StatusStyle(string(model.StatusInProgress))}
+	return table.New().
+		Headers(headers...).
+		Rows(rows...).
+		BorderRow(true).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("8"))).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			var st lipgloss.Style
+			switch {
+			case row == table.HeaderRow:
+				st = headerRowStyle
+			case col == 0:
+				st = rowStyles[row].Bold(true)
+			default:
+				st = cellStyle
+			}
+			if col > 0 {
+				st = st.Width(cellWidth + 2)
+			}
+			return st.Padding(0, 1)
+		}).
+		Render()
+}
+
+func matrixCell(tasks []model.Task, limit, width int) string {
+	shown := tasks
+	if limit > 0 && len(tasks) > limit {
+		shown = tasks[:limit]
+	}
+	var lines []string
+	for _, t := range shown {
+		// Titles go under their IDs, as the columns are narrow.
+		lines = append(lines, t.ID, labelStyle.Render(truncate(t.Title, width)))
+	}
+	if more := len(tasks) - len(shown); more > 0 {
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("+%d more", more)))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,185 @@
+package markdown
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setFields are list fields merged as sets: an entry either side added is
+// kept, and one either side removed is dropped.
+var setFields = map[string]bool{
+	"depends_on": true,
+	"items":      true,
+	"history":    true,
+}
+
+// statusFields go with the status they were set alongside.
+var statusFields = map[string]bool{
+	"closed_at":      true,
+	"blocked_reason": true,
+}
+
+// MergeFrontmatter merges two edits of an entity's YAML frontmatter from
+// their common base, field by field, so the result never needs conflict
+// markers. A field only one side changed takes that change. When both
+// changed it, set fields such as depends_on are merged and any other field
+// takes the value from the side with the later updated_at, so the latest
+// status wins, along with the fields tied to it such as closed_at. base may
+// be empty when both sides added the file.
+func MergeFrontmatter(base, ours, theirs []byte) (*yaml.Node, error) {
+	var docs [3]*yaml.Node
+	for i, src := range [][]byte{base, ours, theirs} {
+		m, err := mappingOf(src)
+		if err != nil {
+			return nil, err
+		}
+		docs[i] = m
+	}
+	b, o, t := docs[0], docs[1], docs[2]
+
+	theirsNewer := timeField(t, "updated_at").After(timeField(o, "updated_at"))
+	// statusFrom is the side whose status is kept, nil if neither changed it.
+	var statusFrom *yaml.Node
+	bs, us, ts := field(b, "status"), field(o, "status"), field(t, "status")
+	switch {
+	case nodesEqual(bs, us) && nodesEqual(bs, ts):
+	case nodesEqual(us, ts), nodesEqual(bs, ts):
+		statusFrom = o
+	case nodesEqual(bs, us), theirsNewer:
+		statusFrom = t
+	default:
+		statusFrom = o
+	}
+	var keys []string
+	seen := map[string]bool{}
+	for _, m := range []*yaml.Node{o, t} {
+		for i := 0; i < len(m.Content); i += 2 {
+			if k := m.Content[i].Value; !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, k := range keys {
+		bv, ov, tv := field(b, k), field(o, k), field(t, k)
+		var v *yaml.Node
+		switch {
+		case statusFields[k] && statusFrom != nil:
+			v = field(statusFrom, k)
+		case nodesEqual(ov, tv), nodesEqual(bv, tv):
+			v = ov
+		case nodesEqual(bv, ov):
+			v = tv
+		case setFields[k] && (ov == nil || isSeq(ov)) && (tv == nil || isSeq(tv)):
+			v = mergeSet(bv, ov, tv, k == "history")
+		case theirsNewer:
+			v = tv
+		default:
+			v = ov
+		}
+		if v != nil {
+			out.Content = append(out.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, v)
+		}
+	}
+	return out, nil
+}
+
+func mappingOf(src []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, fmt.Errorf("parsing frontmatter: %w", err)
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode}, nil
+	}
+	if m := doc.Content[0]; m.Kind == yaml.MappingNode {
+		return m, nil
+	}
+	return nil, fmt.Errorf("parsing frontmatter: not a mapping")
+}
+
+func field(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func timeField(m *yaml.Node, key string) time.Time {
+	v := field(m, key)
+	if v == nil {
+		return time.Time{}
+	}
+	t, _ := time.Parse(time.RFC3339Nano, v.Value)
+	return t
+}
+
+func isSeq(n *yaml.Node) bool {
+	return n != nil && n.Kind == yaml.SequenceNode
+}
+
+// nodesEqual compares two values by their YAML encoding; a missing value
+// only equals another missing one.
+func nodesEqual(a, b *yaml.Node) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return encode(a) == encode(b)
+}
+
+func encode(n *yaml.Node) string {
+	out, _ := yaml.Marshal(n)
+	return string(out)
+}
+
+// mergeSet keeps the entries both sides kept from base plus those either
+// added, ours first. History entries are then put back in time order. A
+// missing list is empty, and an empty result is nil so the field is
+// dropped.
+func mergeSet(base, ours, theirs *yaml.Node, byTime bool) *yaml.Node {
+	in := func(n *yaml.Node) map[string]bool {
+		set := map[string]bool{}
+		if isSeq(n) {
+			for _, e := range n.Content {
+				set[encode(e)] = true
+			}
+		}
+		return set
+	}
+	inBase, inOurs, inTheirs := in(base), in(ours), in(theirs)
+
+	out := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	added := map[string]bool{}
+	for _, side := range []struct {
+		seq   *yaml.Node
+		other map[string]bool
+	}{{ours, inTheirs}, {theirs, inOurs}} {
+		if side.seq == nil {
+			continue
+		}
+		for _, e := range side.seq.Content {
+			k := encode(e)
+			if added[k] || (inBase[k] && !side.other[k]) {
+				continue
+			}
+			added[k] = true
+			out.Content = append(out.Content, e)
+		}
+	}
+	if byTime {
+		sort.SliceStable(out.Content, func(i, j int) bool {
+			return timeField(out.Content[i], "at").Before(timeField(out.Content[j], "at"))
+		})
+	}
+	if len(out.Content) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMergeFrontmatter(t *testing.T) {
+	base := `id: AUTH-TABCDE
+title: Login
+status: open
+depends_on:
+    - AUTH-T22222
+    - AUTH-T33333
+updated_at: 2026-01-01T00:00:00Z
+`
+	ours := `id: AUTH-TABCDE
+title: Login page
+status: closed
+depends_on:
+    - AUTH-T22222
+    - AUTH-T44444
+closed_at: 2026-01-02T00:00:00Z
+updated_at: 2026-01-02T00:00:00Z
+`
+	theirs := `id: AUTH-TABCDE
+title: Login
+status: in_progress
+assignee: alice
+depends_on:
+    - AUTH-T22222
+    - AUTH-T33333
+    - AUTH-T55555
+updated_at: 2026-01-03T00:00:00Z
+`
+	m, err := MergeFrontmatter([]byte(base), []byte(ours), []byte(theirs))
+	require.NoError(t, err)
+	out, err := yaml.Marshal(m)
+	require.NoError(t, err)
+	// Each side's lone changes survive, the dependency edits combine, and
+	// the later status wins without ours' closed_at.
+	assert.Equal(t, `id: AUTH-TABCDE
+title: Login page
+status: in_progress
+depends_on:
+    - AUTH-T22222
+    - AUTH-T44444
+    - AUTH-T55555
+updated_at: 2026-01-03T00:00:00Z
+assignee: alice
+`, string(out))
+}
+
+func TestMergeFrontmatter_BothAdded(t *testing.T) {
+	m, err := MergeFrontmatter(nil, []byte("id: A\ntitle: Ours\n"), []byte("id: A\ntitle: Theirs\nupdated_at: 2026-01-01T00:00:00Z\n"))
+	require.NoError(t, err)
+	out, err := yaml.Marshal(m)
+	require.NoError(t, err)
+	assert.Equal(t, "id: A\ntitle: Theirs\nupdated_at: 2026-01-01T00:00:00Z\n", string(out))
+}
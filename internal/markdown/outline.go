@@ -0,0 +1,171 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Outline is a plan parsed from markdown: each H1 heading is an epic and each
+// top-level list item beneath it is a task.
+type Outline struct {
+	Epics []OutlineEpic
+}
+
+type OutlineEpic struct {
+	Title string
+	Body  string
+	Tasks []OutlineTask
+}
+
+// OutlineTask is a list item. After holds the dependency references from a
+// trailing "(after: ...)" marker: a 1-based task number within the outline,
+// the title of an earlier task, or an existing task ID.
+type OutlineTask struct {
+	Title string
+	Body  string
+	After []string
+}
+
+var (
+	listItemRe = regexp.MustCompile(`^[-*+]\s+(.*)$|^\d+[.)]\s+(.*)$`)
+	afterRe    = regexp.MustCompile(`\s*[(\[]after:\s*([^)\]]*)[)\]]\s*$`)
+)
+
+// ParseOutline parses a markdown outline. Headings below H1 and text between
+// the heading and its first list item become the epic body; indented lines
+// under a list item become that task's body.
+func ParseOutline(src string) (*Outline, error) {
+	var o Outline
+	var epic *OutlineEpic
+	var task *OutlineTask
+	var epicBody, taskBody []string
+
+	flushTask := func() {
+		if task != nil {
+			task.Body = strings.TrimSpace(dedent(taskBody))
+			epic.Tasks = append(epic.Tasks, *task)
+			task, taskBody = nil, nil
+		}
+	}
+	flushEpic := func() {
+		flushTask()
+		if epic != nil {
+			epic.Body = strings.TrimSpace(strings.Join(epicBody, "\n"))
+			o.Epics = append(o.Epics, *epic)
+			epic, epicBody = nil, nil
+		}
+	}
+
+	for i, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(line, "# ") {
+			flushEpic()
+			epic = &OutlineEpic{Title: strings.TrimSpace(line[2:])}
+			continue
+		}
+		if epic == nil {
+			if trimmed != "" {
+				return nil, fmt.Errorf("line %d: content before first epic heading", i+1)
+			}
+			continue
+		}
+		if m := listItemRe.FindStringSubmatch(line); m != nil {
+			flushTask()
+			text := m[1] + m[2]
+			var after []string
+			if am := afterRe.FindStringSubmatch(text); am != nil {
+				for _, ref := range strings.Split(am[1], ",") {
+					if ref = strings.TrimSpace(ref); ref != "" {
+						after = append(after, ref)
+					}
+				}
+				text = text[:len(text)-len(am[0])]
+			}
+			text = strings.TrimSpace(text)
+			if text == "" {
+				return nil, fmt.Errorf("line %d: empty task title", i+1)
+			}
+			task = &OutlineTask{Title: text, After: after}
+			continue
+		}
+		if task != nil {
+			if trimmed == "" || line != strings.TrimLeft(line, " \t") {
+				taskBody = append(taskBody, line)
+				continue
+			}
+			flushTask()
+		}
+		if len(epic.Tasks) > 0 && trimmed != "" {
+			return nil, fmt.Errorf("line %d: unexpected text after tasks in epic %q", i+1, epic.Title)
+		}
+		epicBody = append(epicBody, line)
+	}
+	flushEpic()
+
+	if len(o.Epics) == 0 {
+		return nil, fmt.Errorf("outline has no epics (expected a '# Title' heading)")
+	}
+	return &o, nil
+}
+
+// TaskCount returns the total number of tasks across all epics.
+func (o *Outline) TaskCount() int {
+	n := 0
+	for _, e := range o.Epics {
+		n += len(e.Tasks)
+	}
+	return n
+}
+
+// ResolveAfter resolves a reference from an "after:" marker on the task at
+// position pos (0-based across the whole outline). Numbers and titles must
+// refer to an earlier task; the returned index is -1 when the reference
+// matches neither and should be treated as an existing task ID.
+func (o *Outline) ResolveAfter(ref string, pos int) (int, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > o.TaskCount() {
+			return 0, fmt.Errorf("after: task number %d out of range", n)
+		}
+		if n-1 >= pos {
+			return 0, fmt.Errorf("after: task %d must come earlier in the outline", n)
+		}
+		return n - 1, nil
+	}
+	k := 0
+	for _, e := range o.Epics {
+		for _, t := range e.Tasks {
+			if strings.EqualFold(t.Title, ref) {
+				if k >= pos {
+					return 0, fmt.Errorf("after: %q must come earlier in the outline", ref)
+				}
+				return k, nil
+			}
+			k++
+		}
+	}
+	return -1, nil
+}
+
+func dedent(lines []string) string {
+	minIndent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if minIndent < 0 || n < minIndent {
+			minIndent = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if len(l) >= minIndent && minIndent > 0 {
+			out[i] = l[minIndent:]
+		} else {
+			out[i] = strings.TrimSpace(l)
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,72 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutline(t *testing.T) {
+	src := `# Login
+Session-based login.
+
+- Design schema
+- Build API (after: 1)
+  Expose POST /login.
+- Login form (after: Build API, AUTH-TABCDE)
+
+# Logout
+1. Clear session (after: Design schema)
+`
+	o, err := ParseOutline(src)
+	require.NoError(t, err)
+	require.Len(t, o.Epics, 2)
+
+	login := o.Epics[0]
+	assert.Equal(t, "Login", login.Title)
+	assert.Equal(t, "Session-based login.", login.Body)
+	require.Len(t, login.Tasks, 3)
+	assert.Equal(t, "Design schema", login.Tasks[0].Title)
+	assert.Empty(t, login.Tasks[0].After)
+	assert.Equal(t, "Build API", login.Tasks[1].Title)
+	assert.Equal(t, []string{"1"}, login.Tasks[1].After)
+	assert.Equal(t, "Expose POST /login.", login.Tasks[1].Body)
+	assert.Equal(t, []string{"Build API", "AUTH-TABCDE"}, login.Tasks[2].After)
+
+	require.Len(t, o.Epics[1].Tasks, 1)
+	assert.Equal(t, "Clear session", o.Epics[1].Tasks[0].Title)
+	assert.Equal(t, 4, o.TaskCount())
+}
+
+func TestParseOutline_NoEpic(t *testing.T) {
+	_, err := ParseOutline("- orphan task\n")
+	assert.Error(t, err)
+
+	_, err = ParseOutline("\n\n")
+	assert.Error(t, err)
+}
+
+func TestOutlineResolveAfter(t *testing.T) {
+	o, err := ParseOutline("# E\n- A\n- B\n- C\n")
+	require.NoError(t, err)
+
+	idx, err := o.ResolveAfter("1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, idx)
+
+	idx, err = o.ResolveAfter("b", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx)
+
+	idx, err = o.ResolveAfter("AUTH-TABCDE", 2)
+	require.NoError(t, err)
+	assert.Equal(t, -1, idx)
+
+	_, err = o.ResolveAfter("3", 1)
+	assert.Error(t, err)
+	_, err = o.ResolveAfter("C", 1)
+	assert.Error(t, err)
+	_, err = o.ResolveAfter("9", 1)
+	assert.Error(t, err)
+}
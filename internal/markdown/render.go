@@ -1,8 +1,11 @@
 package markdown
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/glamour"
@@ -22,13 +25,42 @@ var (
 	blockedSty  = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
 )
 
+var (
+	plain        bool
+	savedProfile termenv.Profile
+)
+
+// SetPlain turns ANSI styling off (or back on) for every renderer: tables,
+// status colors, the dependency graph and markdown bodies.
+func SetPlain(on bool) {
+	if on == plain {
+		return
+	}
+	plain = on
+	if on {
+		savedProfile = lipgloss.ColorProfile()
+		lipgloss.SetColorProfile(termenv.Ascii)
+	} else {
+		lipgloss.SetColorProfile(savedProfile)
+	}
+}
+
 func termWidth() int {
-	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+	if w, ok := ttyWidth(); ok {
 		return w
 	}
 	return 80
 }
 
+// ttyWidth returns the terminal's width, or false when stdout isn't a
+// terminal.
+func ttyWidth() (int, bool) {
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		return w, true
+	}
+	return 0, false
+}
+
 func autoStyle() ansi.StyleConfig {
 	if termenv.HasDarkBackground() {
 		return styles.DarkStyleConfig
@@ -36,13 +68,63 @@ func autoStyle() ansi.StyleConfig {
 	return styles.LightStyleConfig
 }
 
+var (
+	bodyWidth int
+	bodyStyle *ansi.StyleConfig
+)
+
+// SetBodyRendering fixes how RenderMarkdown lays out bodies instead of
+// guessing from the terminal. width is the column to wrap at; 0 uses the
+// terminal's width. style is "auto" (or empty) to pick dark or light from
+// the terminal background, a glamour style name such as dark, light or
+// notty, or the path of a glamour JSON style file.
+func SetBodyRendering(width int, style string) error {
+	if width < 0 {
+		return fmt.Errorf("width must not be negative, not %d", width)
+	}
+	bodyWidth = width
+	switch {
+	case style == "" || style == "auto":
+		bodyStyle = nil
+	case styles.DefaultStyles[style] != nil:
+		bodyStyle = styles.DefaultStyles[style]
+	case strings.HasSuffix(style, ".json"):
+		data, err := os.ReadFile(style)
+		if err != nil {
+			return fmt.Errorf("reading style: %w", err)
+		}
+		var sc ansi.StyleConfig
+		if err := json.Unmarshal(data, &sc); err != nil {
+			return fmt.Errorf("parsing style %s: %w", style, err)
+		}
+		bodyStyle = &sc
+	default:
+		return fmt.Errorf("unknown style %q (want auto, %s or a .json style file)", style, strings.Join(slices.Sorted(maps.Keys(styles.DefaultStyles)), ", "))
+	}
+	return nil
+}
+
 func RenderMarkdown(content string) (string, error) {
 	style := autoStyle()
+	switch {
+	case plain:
+		style = styles.ASCIIStyleConfig
+	case bodyStyle != nil:
+		style = *bodyStyle
+	}
 	margin := 0
 	if style.Document.Margin != nil {
 		margin = int(*style.Document.Margin)
 	}
-	r, err := glamour.NewTermRenderer(glamour.WithStyles(style), glamour.WithWordWrap(termWidth()-margin))
+	width := bodyWidth
+	if width == 0 {
+		width = termWidth()
+	}
+	opts := []glamour.TermRendererOption{glamour.WithStyles(style), glamour.WithWordWrap(width - margin)}
+	if plain {
+		opts = append(opts, glamour.WithColorProfile(termenv.Ascii))
+	}
+	r, err := glamour.NewTermRenderer(opts...)
 	if err != nil {
 		return "", fmt.Errorf("creating renderer: %w", err)
 	}
@@ -59,6 +141,8 @@ func StatusStyle(status string) lipgloss.Style {
 		return closedSty
 	case "in_progress":
 		return inProgStyle
+	case "blocked":
+		return blockedSty
 	default:
 		return openStyle
 	}
@@ -76,6 +160,22 @@ func RenderStatus(status string, blocked bool) string {
 	return s
 }
 
+// RenderProgress draws a bar width cells wide, filled in proportion to
+// done out of total, followed by the counts.
+func RenderProgress(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = done * width / total
+	}
+	bar := closedSty.Render(strings.Repeat("█", filled)) + labelStyle.Render(strings.Repeat("░", width-filled))
+	return fmt.Sprintf("%s %d/%d", bar, done, total)
+}
+
+// RenderWarning styles a line that needs the reader's attention.
+func RenderWarning(s string) string {
+	return blockedSty.Bold(true).Render(s)
+}
+
 func RenderEntityHeader(title string, fields []string) string {
 	var sb strings.Builder
 	sb.WriteString(headerStyle.Render(title))
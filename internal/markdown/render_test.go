@@ -0,0 +1,187 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPlain(t *testing.T) {
+	orig := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() {
+		SetPlain(false)
+		lipgloss.SetColorProfile(orig)
+	})
+
+	assert.Contains(t, RenderStatus("closed", true), "\x1b[")
+
+	SetPlain(true)
+	assert.Equal(t, "closed (blocked)", RenderStatus("closed", true))
+	out, err := RenderMarkdown("# Title\n\nSome **bold** text.")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "\x1b[")
+	assert.Contains(t, out, "Title")
+
+	SetPlain(false)
+	assert.Equal(t, termenv.TrueColor, lipgloss.ColorProfile())
+}
+
+func TestSetBodyRendering(t *testing.T) {
+	orig := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() {
+		SetBodyRendering(0, "")
+		lipgloss.SetColorProfile(orig)
+	})
+
+	require.NoError(t, SetBodyRendering(30, "notty"))
+	out, err := RenderMarkdown(strings.Repeat("wrap these words ", 10))
+	require.NoError(t, err)
+	assert.NotContains(t, out, "\x1b[")
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		assert.LessOrEqual(t, len(strings.TrimRight(line, " ")), 30, line)
+	}
+
+	custom := filepath.Join(t.TempDir(), "style.json")
+	require.NoError(t, os.WriteFile(custom, []byte(`{"heading": {"prefix": "§ "}}`), 0o644))
+	require.NoError(t, SetBodyRendering(0, custom))
+	out, err = RenderMarkdown("# Title")
+	require.NoError(t, err)
+	assert.Contains(t, out, "§ Title")
+
+	assert.ErrorContains(t, SetBodyRendering(0, "neon"), `unknown style "neon"`)
+	assert.Error(t, SetBodyRendering(-1, ""))
+}
+
+func TestRenderDashboard(t *testing.T) {
+	p0 := 0
+	epic := model.Task{ID: "AUTH-TEPIC1", Title: "Login", Type: model.TypeEpic}
+	ready := model.Task{ID: "AUTH-TAAAAA", Title: "Build form", Priority: &p0}
+	late := model.Task{ID: "AUTH-TBBBBB", Title: "Ship it", Due: "2026-01-01"}
+	d := Dashboard{
+		Project: model.Project{ID: "AUTH", Name: "Auth"},
+		Epics:   []EpicProgress{{Epic: epic, Progress: model.Progress{Total: 4, Closed: 1}}},
+		Counts:  map[model.Status]int{model.StatusOpen: 3, model.StatusClosed: 1},
+		Blocked: 2,
+		Ready:   []model.Task{ready},
+		Due:     []model.Task{late},
+		Recent: []Activity{{Task: ready, Change: model.StatusChange{
+			Status: model.StatusClosed, At: time.Date(2026, 2, 3, 9, 30, 0, 0, time.UTC), By: "alice",
+		}}},
+		Today: "2026-02-10",
+	}
+
+	for _, width := range []int{80, 160} {
+		out := renderDashboard(d, width)
+		assert.Contains(t, out, "Auth (AUTH)")
+		assert.Contains(t, out, "1/4  25%  Login")
+		assert.Contains(t, out, "AUTH-TAAAAA  Build form  P0")
+		assert.Contains(t, out, "AUTH-TBBBBB  Ship it  2026-01-01")
+		assert.Contains(t, out, "2 held up")
+		assert.Contains(t, out, "Feb 03 09:30  AUTH-TAAAAA closed by alice")
+	}
+	// Side by side, Status and Epics share their first row.
+	wide := renderDashboard(d, 160)
+	assert.Less(t, len(strings.Split(wide, "\n")), len(strings.Split(renderDashboard(d, 80), "\n")))
+}
+
+func TestRenderMatrix(t *testing.T) {
+	p0, p2 := 0, 2
+	dep := model.Task{ID: "AUTH-TDEPDEP", Title: "Upstream", Type: model.TypeTask, Status: model.StatusOpen}
+	tasks := []model.Task{
+		dep,
+		{ID: "AUTH-TAAAAA", Title: "Urgent fix", Type: model.TypeTask, Status: model.StatusOpen, Priority: &p0},
+		{ID: "AUTH-TBBBBB", Title: "Waits on upstream", Type: model.TypeTask, Status: model.StatusOpen, Priority: &p0, DependsOn: []string{dep.ID}},
+		{ID: "AUTH-TCCCCC", Title: "Doing it", Type: model.TypeTask, Status: model.StatusInProgress, Priority: &p2},
+		{ID: "AUTH-TDDDDD", Title: "Done", Type: model.TypeTask, Status: model.StatusClosed, Priority: &p2},
+		{ID: "AUTH-TEPIC1", Title: "Epic", Type: model.TypeEpic},
+	}
+	all := map[string]*model.Task{}
+	for i := range tasks {
+		all[tasks[i].ID] = &tasks[i]
+	}
+
+	m := NewMatrix(tasks, all, model.StrictDeps)
+	ids := func(cell []model.Task) []string {
+		var out []string
+		for _, t := range cell {
+			out = append(out, t.ID)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"AUTH-TBBBBB"}, ids(m.Cells[0][0]))
+	assert.Equal(t, []string{"AUTH-TAAAAA"}, ids(m.Cells[1][0]))
+	assert.Equal(t, []string{"AUTH-TDEPDEP"}, ids(m.Cells[1][4]))
+	assert.Equal(t, []string{"AUTH-TCCCCC"}, ids(m.Cells[2][2]))
+
+	out := renderMatrix(m, 160)
+	for _, s := range []string{"P0", "P3", "None", "Blocked", "Ready", "In progress", "AUTH-TAAAAA", "Urgent fix", "Doing it"} {
+		assert.Contains(t, out, s)
+	}
+	assert.NotContains(t, out, "AUTH-TDDDDD")
+
+	m.Limit = 1
+	m.Cells[1][0] = append(m.Cells[1][0], tasks[1], tasks[1])
+	assert.Contains(t, renderMatrix(m, 160), "+2 more")
+}
+
+func TestRenderHeatmap(t *testing.T) {
+	h := Heatmap{
+		End:     time.Date(2026, 2, 4, 18, 0, 0, 0, time.UTC), // a Wednesday
+		Weeks:   2,
+		Created: map[string]int{"2026-01-27": 1, "2026-02-02": 4},
+		Closed:  map[string]int{"2026-02-02": 4, "2026-02-04": 2},
+	}
+	out := RenderHeatmap(h)
+	lines := strings.Split(out, "\n")
+	require.GreaterOrEqual(t, len(lines), 8)
+	assert.Equal(t, "    Jan", lines[0], "February starts too close to label")
+	assert.Equal(t, "Mon · █", lines[1])
+	assert.Equal(t, "    ░ ·", lines[2])
+	assert.Equal(t, "Wed · ░", lines[3])
+	assert.Equal(t, "Sun ·", lines[7], "days after End are left blank")
+	assert.Contains(t, out, "5 created, 6 closed in 2 weeks")
+}
+
+func TestFitTitles(t *testing.T) {
+	t.Cleanup(func() { SetTableFit(FitTruncate) })
+	headers := []string{"ID", "Title", "Status"}
+	long := "Rework the session refresh flow for mobile clients"
+	table := func() [][]string {
+		return [][]string{{"AUTH-TAAAAA", long, "open"}, {"AUTH-TBBBBB", "Short", "closed"}}
+	}
+
+	rows := table()
+	fitTitles(headers, rows, 1, 40)
+	// 40 less the ID and Status columns and 4 borders.
+	assert.Equal(t, "Rework the session…", rows[0][1])
+	assert.Equal(t, "Short", rows[1][1])
+
+	SetTableFit(FitWrap)
+	rows = table()
+	fitTitles(headers, rows, 1, 40)
+	for _, line := range strings.Split(rows[0][1], "\n") {
+		assert.LessOrEqual(t, lipgloss.Width(line), 19)
+	}
+	assert.Equal(t, long, strings.Join(strings.Fields(rows[0][1]), " "))
+
+	SetTableFit(FitFull)
+	rows = table()
+	fitTitles(headers, rows, 1, 40)
+	assert.Equal(t, long, rows[0][1])
+
+	// Never narrower than minTitleWidth.
+	SetTableFit(FitTruncate)
+	rows = table()
+	fitTitles(headers, rows, 1, 10)
+	assert.Equal(t, minTitleWidth, lipgloss.Width(rows[0][1]))
+}
@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// NoteGroup is one section of generated release notes.
+type NoteGroup struct {
+	Heading string
+	Tasks   []model.Task
+}
+
+// RenderReleaseNotes renders grouped tasks as a markdown changelog, one
+// bullet per task under a heading per group.
+func RenderReleaseNotes(title, summary string, groups []NoteGroup) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%s\n", title, summary)
+	if len(groups) == 0 {
+		b.WriteString("\nNo tasks were closed in this window.\n")
+		return b.String()
+	}
+	for _, g := range groups {
+		fmt.Fprintf(&b, "\n## %s\n\n", g.Heading)
+		for _, t := range g.Tasks {
+			fmt.Fprintf(&b, "- %s (%s)\n", t.Title, t.ID)
+		}
+	}
+	return b.String()
+}
+
+// StandupEntry is one person's activity in a standup report.
+type StandupEntry struct {
+	Person     string
+	Closed     []model.Task
+	Started    []model.Task
+	InProgress []model.Task
+}
+
+// RenderStandup renders standup entries as plain text that pastes cleanly
+// into chat: the person in bold, then their closed, started and in-progress
+// tasks. Empty sections are left out.
+func RenderStandup(entries []StandupEntry) string {
+	if len(entries) == 0 {
+		return "No activity."
+	}
+	var b strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "*%s*\n", e.Person)
+		for _, sec := range []struct {
+			label string
+			tasks []model.Task
+		}{{"Closed", e.Closed}, {"Started", e.Started}, {"In progress", e.InProgress}} {
+			if len(sec.tasks) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "%s:\n", sec.label)
+			for _, t := range sec.tasks {
+				fmt.Fprintf(&b, "- %s %s\n", t.ID, t.Title)
+			}
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
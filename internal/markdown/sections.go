@@ -0,0 +1,150 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Section is an ATX heading and the lines it owns: everything up to the next
+// heading of the same or a higher level. Lines are 0-based; End is exclusive.
+type Section struct {
+	Level  int
+	Title  string
+	Anchor string
+	Line   int
+	End    int
+}
+
+var (
+	headingRe    = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	anchorDropRe = regexp.MustCompile(`[^\p{L}\p{N}_\- ]`)
+)
+
+// ParseSections returns the headings in body in document order. Anchors
+// follow GitHub's scheme (lowercase, punctuation dropped, spaces to hyphens,
+// "-1", "-2"... appended to repeats), so they are stable across edits that
+// don't rename or reorder same-named headings. Headings inside fenced code
+// blocks are ignored.
+func ParseSections(body string) []Section {
+	lines := strings.Split(body, "\n")
+	var sections []Section
+	seen := map[string]int{}
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		m := headingRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		anchor := Anchor(m[2])
+		if n := seen[anchor]; n > 0 {
+			seen[anchor] = n + 1
+			anchor = fmt.Sprintf("%s-%d", anchor, n)
+		} else {
+			seen[anchor] = 1
+		}
+		sections = append(sections, Section{Level: len(m[1]), Title: m[2], Anchor: anchor, Line: i})
+	}
+
+	for i := range sections {
+		sections[i].End = len(lines)
+		for _, next := range sections[i+1:] {
+			if next.Level <= sections[i].Level {
+				sections[i].End = next.Line
+				break
+			}
+		}
+	}
+	return sections
+}
+
+// SplitTitle returns the text of body's first level-1 heading and the body
+// without that heading line, for documents that carry their title as an H1.
+// Without an H1 it returns "" and body unchanged.
+func SplitTitle(body string) (string, string) {
+	for _, s := range ParseSections(body) {
+		if s.Level != 1 {
+			continue
+		}
+		lines := strings.Split(body, "\n")
+		rest := lines[s.Line+1:]
+		if len(rest) > 0 && strings.TrimSpace(rest[0]) == "" {
+			rest = rest[1:]
+		}
+		return s.Title, strings.Join(append(lines[:s.Line:s.Line], rest...), "\n")
+	}
+	return "", body
+}
+
+// Anchor converts a heading title to its GitHub-style anchor.
+func Anchor(title string) string {
+	a := anchorDropRe.ReplaceAllString(strings.ToLower(strings.TrimSpace(title)), "")
+	return strings.ReplaceAll(a, " ", "-")
+}
+
+// FindSection looks up a section by heading ("## API"), title ("API") or
+// anchor ("api" or "#api"). A heading reference also matches the level.
+func FindSection(sections []Section, ref string) (Section, error) {
+	ref = strings.TrimSpace(ref)
+	level := 0
+	if m := headingRe.FindStringSubmatch(ref); m != nil {
+		level, ref = len(m[1]), m[2]
+	}
+	anchor := strings.TrimPrefix(ref, "#")
+
+	var matches []Section
+	for _, s := range sections {
+		if level != 0 && s.Level != level {
+			continue
+		}
+		if s.Anchor == anchor || strings.EqualFold(s.Title, ref) {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Section{}, fmt.Errorf("section %q not found", ref)
+	case 1:
+		return matches[0], nil
+	}
+	var anchors []string
+	for _, s := range matches {
+		anchors = append(anchors, "#"+s.Anchor)
+	}
+	return Section{}, fmt.Errorf("section %q is ambiguous; use an anchor: %s", ref, strings.Join(anchors, ", "))
+}
+
+// ReplaceSection replaces the content under the section matching ref,
+// including its subsections, with content. The heading line itself is kept.
+func ReplaceSection(body, ref, content string) (string, error) {
+	sec, err := FindSection(ParseSections(body), ref)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(body, "\n")
+
+	var out []string
+	out = append(out, lines[:sec.Line+1]...)
+	out = append(out, "")
+	if content = strings.Trim(content, "\n"); content != "" {
+		out = append(out, strings.Split(content, "\n")...)
+	}
+	if rest := lines[sec.End:]; len(rest) > 0 {
+		out = append(out, "")
+		out = append(out, rest...)
+	} else {
+		out = append(out, "")
+	}
+	return strings.Join(out, "\n"), nil
+}
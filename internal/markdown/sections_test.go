@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sectionsDoc = `Intro
+
+## API
+
+Old API text.
+
+### Errors
+
+Error codes.
+
+## Usage
+
+` + "```sh\n# not a heading\n```" + `
+
+## API
+
+Second API.
+`
+
+func TestParseSections(t *testing.T) {
+	secs := ParseSections(sectionsDoc)
+	require.Len(t, secs, 4)
+	assert.Equal(t, "api", secs[0].Anchor)
+	assert.Equal(t, "errors", secs[1].Anchor)
+	assert.Equal(t, 3, secs[1].Level)
+	assert.Equal(t, "usage", secs[2].Anchor)
+	assert.Equal(t, "api-1", secs[3].Anchor)
+	assert.Equal(t, secs[2].Line, secs[0].End)
+}
+
+func TestAnchor(t *testing.T) {
+	assert.Equal(t, "whats-new-in-v2", Anchor("What's new in v2?"))
+	assert.Equal(t, "foo_bar", Anchor("  Foo_Bar "))
+}
+
+func TestFindSection(t *testing.T) {
+	secs := ParseSections(sectionsDoc)
+
+	s, err := FindSection(secs, "#api-1")
+	require.NoError(t, err)
+	assert.Equal(t, secs[3].Line, s.Line)
+
+	s, err = FindSection(secs, "### Errors")
+	require.NoError(t, err)
+	assert.Equal(t, "Errors", s.Title)
+
+	_, err = FindSection(secs, "## Errors")
+	assert.Error(t, err)
+
+	_, err = FindSection(secs, "## API")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "#api, #api-1")
+}
+
+func TestReplaceSection(t *testing.T) {
+	out, err := ReplaceSection(sectionsDoc, "#api", "New API text.\n")
+	require.NoError(t, err)
+	assert.Contains(t, out, "## API\n\nNew API text.\n\n## Usage")
+	assert.NotContains(t, out, "Error codes.")
+	assert.Contains(t, out, "Second API.")
+
+	out, err = ReplaceSection(sectionsDoc, "api-1", "Last.")
+	require.NoError(t, err)
+	assert.True(t, len(out) > 0 && out[len(out)-1] == '\n')
+	assert.Contains(t, out, "## API\n\nLast.\n")
+}
+
+func TestSplitTitle(t *testing.T) {
+	title, body := SplitTitle("# Login Spec\n\nUsers sign in.\n\n## Flow\n")
+	assert.Equal(t, "Login Spec", title)
+	assert.Equal(t, "Users sign in.\n\n## Flow\n", body)
+
+	title, body = SplitTitle("Intro\n\n# Heading\nText")
+	assert.Equal(t, "Heading", title)
+	assert.Equal(t, "Intro\n\nText", body)
+
+	title, body = SplitTitle("```\n# not a heading\n```\n## Sub")
+	assert.Empty(t, title)
+	assert.Equal(t, "```\n# not a heading\n```\n## Sub", body)
+}
@@ -1,10 +1,15 @@
 package markdown
 
 import (
+	"fmt"
+	"slices"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	xansi "github.com/charmbracelet/x/ansi"
 	"github.com/rogersnm/compass/internal/model"
 )
 
@@ -17,6 +22,9 @@ var (
 type ProjectRow struct {
 	Project   model.Project
 	StoreName string
+	// Note flags a cached project its store didn't confirm ("stale",
+	// "unreachable"); only the ID is known for such rows.
+	Note string
 }
 
 func RenderProjectTable(projects []model.Project) string {
@@ -39,52 +47,353 @@ func RenderProjectTableWithStores(projectRows []ProjectRow) string {
 	})
 	rows := make([][]string, len(projectRows))
 	for i, r := range projectRows {
-		rows[i] = []string{r.Project.ID, r.Project.Name, r.StoreName, r.Project.CreatedAt.Format("2006-01-02")}
+		storeName, created := r.StoreName, "-"
+		if r.Note != "" {
+			storeName += " (" + r.Note + ")"
+		}
+		if !r.Project.CreatedAt.IsZero() {
+			created = r.Project.CreatedAt.Format("2006-01-02")
+		}
+		rows[i] = []string{r.Project.ID, r.Project.Name, storeName, created}
 	}
 	return renderTable([]string{"ID", "Name", "Store", "Created"}, rows)
 }
 
+// column is one selectable table column.
+type column[T any] struct {
+	name   string
+	header string
+	value  func(*T) string
+}
+
+var documentColumns = []column[model.Document]{
+	{"id", "ID", func(d *model.Document) string { return d.ID }},
+	{"title", "Title", func(d *model.Document) string { return d.Title }},
+	{"project", "Project", func(d *model.Document) string { return d.Project }},
+	{"kind", "Kind", func(d *model.Document) string { return string(d.Kind) }},
+	{"number", "ADR", func(d *model.Document) string { return d.ADRLabel() }},
+	{"status", "Status", func(d *model.Document) string { return string(d.Status) }},
+	{"created", "Created", func(d *model.Document) string { return d.CreatedAt.Format("2006-01-02") }},
+	{"updated", "Updated", func(d *model.Document) string { return d.UpdatedAt.Format("2006-01-02") }},
+	{"created_by", "Created By", func(d *model.Document) string { return d.CreatedBy }},
+	{"store", "Store", func(d *model.Document) string { return d.Store }},
+}
+
+// DefaultDocumentColumns are the columns shown by RenderDocumentTable.
+var DefaultDocumentColumns = []string{"id", "title", "kind", "project", "created"}
+
+// ADRColumns are the columns shown when listing ADRs, which are read by
+// number and status rather than by project.
+var ADRColumns = []string{"number", "title", "status", "id", "created"}
+
 func RenderDocumentTable(docs []model.Document) string {
-	if len(docs) == 0 {
-		return "No documents found."
+	out, _ := RenderDocumentColumns(docs, DefaultDocumentColumns)
+	return out
+}
+
+// RenderDocumentColumns renders docs in the given order with the named
+// columns.
+func RenderDocumentColumns(docs []model.Document, columns []string) (string, error) {
+	cols, err := pickColumns(documentColumns, columns)
+	if err != nil {
+		return "", err
 	}
-	rows := make([][]string, len(docs))
-	for i, d := range docs {
-		rows[i] = []string{d.ID, d.Title, d.Project, d.CreatedAt.Format("2006-01-02")}
+	if len(docs) == 0 {
+		return "No documents found.", nil
 	}
-	return renderTable([]string{"ID", "Title", "Project", "Created"}, rows)
+	return renderColumns(cols, docs), nil
 }
 
-func RenderTaskTable(tasks []model.Task, allTasks map[string]*model.Task) string {
-	if len(tasks) == 0 {
-		return "No tasks found."
+// taskColumns renders a task's status relative to allTasks, under deps, so
+// it is built per call.
+func taskColumns(allTasks map[string]*model.Task, deps model.DepPolicy) []column[model.Task] {
+	return []column[model.Task]{
+		{"id", "ID", func(t *model.Task) string { return t.ID }},
+		{"title", "Title", func(t *model.Task) string { return t.Title }},
+		{"type", "Type", func(t *model.Task) string { return string(t.Type) }},
+		{"priority", "Pri", func(t *model.Task) string { return model.FormatPriority(t.Priority) }},
+		{"status", "Status", func(t *model.Task) string {
+			if t.Type == model.TypeEpic {
+				if t.ClosedAt != nil {
+					return RenderStatus(string(model.StatusClosed), false)
+				}
+				return "N/A"
+			}
+			return RenderStatus(string(t.Status), t.IsBlocked(allTasks, deps))
+		}},
+		{"project", "Project", func(t *model.Task) string { return t.Project }},
+		{"epic", "Epic", func(t *model.Task) string { return t.Epic }},
+		{"depends_on", "Depends On", func(t *model.Task) string { return strings.Join(t.DependsOn, ", ") }},
+		{"due", "Due", func(t *model.Task) string { return t.Due }},
+		{"assignee", "Assignee", func(t *model.Task) string { return t.Assignee }},
+		{"watchers", "Watchers", func(t *model.Task) string { return strings.Join(t.Watchers, ", ") }},
+		{"created", "Created", func(t *model.Task) string { return t.CreatedAt.Format("2006-01-02") }},
+		{"updated", "Updated", func(t *model.Task) string { return t.UpdatedAt.Format("2006-01-02") }},
+		{"created_by", "Created By", func(t *model.Task) string { return t.CreatedBy }},
+		{"changed_by", "Changed By", func(t *model.Task) string {
+			if len(t.History) == 0 {
+				return ""
+			}
+			return t.History[len(t.History)-1].By
+		}},
+		{"store", "Store", func(t *model.Task) string { return t.Store }},
 	}
+}
+
+// DefaultTaskColumns are the columns shown by RenderTaskTable.
+var DefaultTaskColumns = []string{"id", "title", "type", "priority", "status", "project"}
+
+// TaskColumnNames and DocumentColumnNames list every selectable column.
+var (
+	TaskColumnNames     = columnNames(taskColumns(nil, model.StrictDeps))
+	DocumentColumnNames = columnNames(documentColumns)
+)
+
+func RenderTaskTable(tasks []model.Task, allTasks map[string]*model.Task, deps model.DepPolicy) string {
+	SortTasks(tasks, allTasks, deps)
+	out, _ := RenderTaskColumns(tasks, allTasks, deps, DefaultTaskColumns)
+	return out
+}
+
+// SortTasks orders tasks for display: unblocked before blocked, then oldest
+// first.
+func SortTasks(tasks []model.Task, allTasks map[string]*model.Task, deps model.DepPolicy) {
 	sort.Slice(tasks, func(i, j int) bool {
-		bi := tasks[i].IsBlocked(allTasks)
-		bj := tasks[j].IsBlocked(allTasks)
+		bi := tasks[i].IsBlocked(allTasks, deps)
+		bj := tasks[j].IsBlocked(allTasks, deps)
 		if bi != bj {
 			return !bi // unblocked first
 		}
 		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
 	})
+}
+
+// RenderTaskColumns renders tasks in the given order with the named columns.
+func RenderTaskColumns(tasks []model.Task, allTasks map[string]*model.Task, deps model.DepPolicy, columns []string) (string, error) {
+	cols, err := pickColumns(taskColumns(allTasks, deps), columns)
+	if err != nil {
+		return "", err
+	}
+	if len(tasks) == 0 {
+		return "No tasks found.", nil
+	}
+	return renderColumns(cols, tasks), nil
+}
+
+// TaskRecords returns a header row of column names and a row of unstyled
+// values per task, for CSV and TSV output.
+func TaskRecords(tasks []model.Task, allTasks map[string]*model.Task, deps model.DepPolicy, columns []string) ([][]string, error) {
+	cols, err := pickColumns(taskColumns(allTasks, deps), columns)
+	if err != nil {
+		return nil, err
+	}
+	return records(cols, tasks), nil
+}
+
+// DocumentRecords is TaskRecords for documents.
+func DocumentRecords(docs []model.Document, columns []string) ([][]string, error) {
+	cols, err := pickColumns(documentColumns, columns)
+	if err != nil {
+		return nil, err
+	}
+	return records(cols, docs), nil
+}
+
+func records[T any](cols []column[T], items []T) [][]string {
+	rows := make([][]string, 0, len(items)+1)
+	rows = append(rows, columnNames(cols))
+	for i := range items {
+		row := make([]string, len(cols))
+		for j, c := range cols {
+			row[j] = xansi.Strip(c.value(&items[i]))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func pickColumns[T any](all []column[T], names []string) ([]column[T], error) {
+	var cols []column[T]
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		i := slices.IndexFunc(all, func(c column[T]) bool { return c.name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown column %q (valid: %s)", name, strings.Join(columnNames(all), ", "))
+		}
+		cols = append(cols, all[i])
+	}
+	return cols, nil
+}
+
+func columnNames[T any](cols []column[T]) []string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.name
+	}
+	return names
+}
+
+func renderColumns[T any](cols []column[T], items []T) string {
+	headers := make([]string, len(cols))
+	for i, c := range cols {
+		headers[i] = c.header
+	}
+	rows := make([][]string, len(items))
+	for i := range items {
+		rows[i] = make([]string, len(cols))
+		for j, c := range cols {
+			rows[i][j] = c.value(&items[i])
+		}
+	}
+	if width, ok := ttyWidth(); ok {
+		fitTitles(headers, rows, slices.IndexFunc(cols, func(c column[T]) bool { return c.name == "title" }), width)
+	}
+	return renderTable(headers, rows)
+}
+
+// TableFit is how a table's title column is fitted to the terminal when
+// the table would be wider.
+type TableFit int
+
+const (
+	FitTruncate TableFit = iota // cut titles short with an ellipsis
+	FitWrap                     // wrap titles onto more lines
+	FitFull                     // leave titles whole and let lines overflow
+)
+
+var tableFit TableFit
+
+// SetTableFit sets how task and document tables fit a terminal. Tables
+// written to a pipe or file are never fitted.
+func SetTableFit(fit TableFit) {
+	tableFit = fit
+}
+
+// minTitleWidth is as narrow as fitting makes the title column.
+const minTitleWidth = 12
+
+// fitTitles narrows the title column, col, of a table so the table fits in
+// width, truncating or wrapping titles as tableFit says. Other columns are
+// short and left whole.
+func fitTitles(headers []string, rows [][]string, col, width int) {
+	if col < 0 || tableFit == FitFull {
+		return
+	}
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = lipgloss.Width(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+	// One border before each column and one after the last.
+	avail := width - len(headers) - 1
+	for i, w := range widths {
+		if i != col {
+			avail -= w
+		}
+	}
+	avail = max(avail, minTitleWidth)
+	if widths[col] <= avail {
+		return
+	}
+	for _, row := range rows {
+		if tableFit == FitWrap {
+			row[col] = xansi.Wrap(row[col], avail, "")
+		} else {
+			row[col] = xansi.Truncate(row[col], avail, "…")
+		}
+	}
+}
+
+// RenderWaitingTable lists tasks waiting on external events, soonest
+// wait-until date first; waits without a date sort last.
+func RenderWaitingTable(tasks []model.Task, now time.Time) string {
+	if len(tasks) == 0 {
+		return "No waiting tasks."
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		ui, uj := tasks[i].Waiting.Until, tasks[j].Waiting.Until
+		if (ui == "") != (uj == "") {
+			return ui != ""
+		}
+		if ui != uj {
+			return ui < uj
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
 	rows := make([][]string, len(tasks))
 	for i, t := range tasks {
-		var status string
-		if t.Type == model.TypeEpic {
-			status = "N/A"
-		} else {
-			status = RenderStatus(string(t.Status), t.IsBlocked(allTasks))
+		w := t.Waiting
+		state := "waiting"
+		if !w.Active(now) {
+			state = "lapsed"
 		}
-		rows[i] = []string{t.ID, t.Title, string(t.Type), model.FormatPriority(t.Priority), status, t.Project}
+		rows[i] = []string{t.ID, t.Title, w.Description, w.URL, w.Until, state}
+	}
+	return renderTable([]string{"ID", "Title", "Waiting on", "URL", "Until", "State"}, rows)
+}
+
+func RenderReleaseTable(releases []model.Release) string {
+	if len(releases) == 0 {
+		return "No releases found."
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].CreatedAt.Before(releases[j].CreatedAt)
+	})
+	rows := make([][]string, len(releases))
+	for i, r := range releases {
+		rows[i] = []string{r.ID, r.Version, string(r.Status), r.TargetDate, fmt.Sprintf("%d", len(r.Items)), r.Project}
 	}
-	return renderTable([]string{"ID", "Title", "Type", "Pri", "Status", "Project"}, rows)
+	return renderTable([]string{"ID", "Version", "Status", "Target", "Items", "Project"}, rows)
 }
 
 func RenderStoreTable(rows [][]string) string {
 	if len(rows) == 0 {
 		return "No stores configured."
 	}
-	return renderTable([]string{"Store", "Hostname", "Default"}, rows)
+	return renderTable([]string{"Store", "Hostname", "Org", "Default", "Read-only"}, rows)
+}
+
+func RenderPingTable(rows [][]string) string {
+	if len(rows) == 0 {
+		return "No stores configured."
+	}
+	return renderTable([]string{"Store", "Status", "Version", "Latency"}, rows)
+}
+
+func RenderOrgTable(rows [][]string) string {
+	if len(rows) == 0 {
+		return "No organizations found."
+	}
+	return renderTable([]string{"Slug", "Name", "Role", "Active"}, rows)
+}
+
+func RenderUsageTable(rows [][]string) string {
+	if len(rows) == 0 {
+		return "No projects found."
+	}
+	return renderTable([]string{"Store", "Project", "Tasks", "Epics", "Docs", "Releases", "Disk"}, rows)
+}
+
+// FormatBytes renders a byte count with a binary unit suffix, or "-" when
+// the size is unknown (negative).
+func FormatBytes(n int64) string {
+	if n < 0 {
+		return "-"
+	}
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
 func renderTable(headers []string, rows [][]string) string {
@@ -5,13 +5,60 @@ import (
 	"time"
 )
 
+// DocKind categorizes a document. The empty kind is an uncategorized
+// document.
+type DocKind string
+
+const (
+	DocDesign       DocKind = "design"
+	DocSpec         DocKind = "spec"
+	DocRunbook      DocKind = "runbook"
+	DocMeetingNotes DocKind = "meeting-notes"
+	DocADR          DocKind = "adr"
+)
+
+var DocKinds = []DocKind{DocDesign, DocSpec, DocRunbook, DocMeetingNotes, DocADR}
+
+func ValidateDocKind(k DocKind) error {
+	for _, v := range DocKinds {
+		if k == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid document kind %q: must be one of design, spec, runbook, meeting-notes, adr", k)
+}
+
+// ADRStatus is the lifecycle of an Architecture Decision Record.
+type ADRStatus string
+
+const (
+	ADRProposed   ADRStatus = "proposed"
+	ADRAccepted   ADRStatus = "accepted"
+	ADRSuperseded ADRStatus = "superseded"
+)
+
 type Document struct {
-	ID        string    `yaml:"id"`
-	Title     string    `yaml:"title"`
-	Project   string    `yaml:"project"`
-	CreatedBy string    `yaml:"created_by"`
-	CreatedAt time.Time `yaml:"created_at"`
-	UpdatedAt time.Time `yaml:"updated_at"`
+	ID      string  `yaml:"id" json:"id"`
+	Title   string  `yaml:"title" json:"title"`
+	Project string  `yaml:"project" json:"project"`
+	Kind    DocKind `yaml:"kind,omitempty" json:"kind,omitempty"`
+	// Slug names the document within its project, as a task's does.
+	Slug string `yaml:"slug,omitempty" json:"slug,omitempty"`
+	// Number and Status apply to ADRs only. Numbers are sequential per
+	// project and never reused.
+	Number int       `yaml:"number,omitempty" json:"number,omitempty"`
+	Status ADRStatus `yaml:"status,omitempty" json:"status,omitempty"`
+	// Supersedes and SupersededBy link an ADR to the record it replaced and
+	// the record that replaced it.
+	Supersedes   string    `yaml:"supersedes,omitempty" json:"supersedes,omitempty"`
+	SupersededBy string    `yaml:"superseded_by,omitempty" json:"superseded_by,omitempty"`
+	CreatedBy    string    `yaml:"created_by" json:"created_by"`
+	CreatedAt    time.Time `yaml:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `yaml:"updated_at" json:"updated_at"`
+	// DeletedAt is set on a document a cloud store has soft-deleted.
+	DeletedAt *time.Time `yaml:"-" json:"deleted_at,omitempty"`
+	// Store is set by listings that span stores, as a task's is.
+	Store string `yaml:"-" json:"store,omitempty"`
 }
 
 func (d *Document) Validate() error {
@@ -24,5 +71,55 @@ func (d *Document) Validate() error {
 	if d.Project == "" {
 		return fmt.Errorf("document project is required")
 	}
+	if d.Slug != "" {
+		if err := ValidateSlug(d.Slug); err != nil {
+			return err
+		}
+	}
+	if d.Kind != "" {
+		if err := ValidateDocKind(d.Kind); err != nil {
+			return err
+		}
+	}
+	if d.Kind != DocADR {
+		if d.Number != 0 || d.Status != "" || d.Supersedes != "" || d.SupersededBy != "" {
+			return fmt.Errorf("only ADRs have a number, status and supersede links")
+		}
+		return nil
+	}
+	if d.Number < 1 {
+		return fmt.Errorf("ADR number is required")
+	}
+	switch d.Status {
+	case ADRProposed, ADRAccepted, ADRSuperseded:
+	default:
+		return fmt.Errorf("invalid ADR status %q: must be proposed, accepted or superseded", d.Status)
+	}
+	if (d.Status == ADRSuperseded) != (d.SupersededBy != "") {
+		return fmt.Errorf("a superseded ADR must name the record that superseded it")
+	}
+	if d.Supersedes == d.ID || d.SupersededBy == d.ID || (d.Supersedes != "" && d.Supersedes == d.SupersededBy) {
+		return fmt.Errorf("an ADR cannot supersede itself or the record that superseded it")
+	}
 	return nil
 }
+
+// ADRLabel returns the conventional "ADR-0007" label, or "" for documents
+// that are not ADRs.
+func (d *Document) ADRLabel() string {
+	if d.Kind != DocADR {
+		return ""
+	}
+	return fmt.Sprintf("ADR-%04d", d.Number)
+}
+
+// NextADRNumber returns the number for a new ADR among docs.
+func NextADRNumber(docs []Document) int {
+	n := 0
+	for _, d := range docs {
+		if d.Kind == DocADR && d.Number > n {
+			n = d.Number
+		}
+	}
+	return n + 1
+}
@@ -1,7 +1,9 @@
 package model
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -49,6 +51,20 @@ func TestTask_Validate_EpicRejectsStoredStatus(t *testing.T) {
 	assert.Contains(t, task.Validate().Error(), "must not have a status")
 }
 
+func TestTask_AutoCloses(t *testing.T) {
+	on, off := true, false
+	epic := &Task{ID: "TEST-TABCDE", Title: "Test", Project: "TEST", Type: TypeEpic}
+	assert.False(t, epic.AutoCloses(&Project{}))
+	assert.True(t, epic.AutoCloses(&Project{AutoCloseEpics: true}))
+	epic.AutoClose = &off
+	assert.False(t, epic.AutoCloses(&Project{AutoCloseEpics: true}))
+	epic.AutoClose = &on
+	assert.True(t, epic.AutoCloses(nil))
+
+	task := &Task{ID: "TEST-TABCDE", Title: "Test", Project: "TEST", Type: TypeTask, Status: StatusOpen, AutoClose: &on}
+	assert.ErrorContains(t, task.Validate(), "auto_close is only allowed on epics")
+}
+
 func TestTask_Validate_EpicCannotHaveDeps(t *testing.T) {
 	task := &Task{
 		ID: "TEST-TABCDE", Title: "Test", Project: "TEST",
@@ -100,7 +116,7 @@ func TestChildrenOf_NoChildren(t *testing.T) {
 
 func TestTask_IsBlocked_NoDeps(t *testing.T) {
 	task := &Task{ID: "TEST-TABCDE", Status: StatusOpen}
-	assert.False(t, task.IsBlocked(nil))
+	assert.False(t, task.IsBlocked(nil, StrictDeps))
 }
 
 func TestTask_IsBlocked_AllDepsClosed(t *testing.T) {
@@ -108,7 +124,7 @@ func TestTask_IsBlocked_AllDepsClosed(t *testing.T) {
 		"TEST-T11111": {ID: "TEST-T11111", Status: StatusClosed},
 	}
 	task := &Task{ID: "TEST-TABCDE", Status: StatusOpen, DependsOn: []string{"TEST-T11111"}}
-	assert.False(t, task.IsBlocked(all))
+	assert.False(t, task.IsBlocked(all, StrictDeps))
 }
 
 func TestTask_IsBlocked_SomeDepOpen(t *testing.T) {
@@ -116,7 +132,7 @@ func TestTask_IsBlocked_SomeDepOpen(t *testing.T) {
 		"TEST-T11111": {ID: "TEST-T11111", Status: StatusOpen},
 	}
 	task := &Task{ID: "TEST-TABCDE", Status: StatusOpen, DependsOn: []string{"TEST-T11111"}}
-	assert.True(t, task.IsBlocked(all))
+	assert.True(t, task.IsBlocked(all, StrictDeps))
 }
 
 func TestTask_IsBlocked_MixedStatuses(t *testing.T) {
@@ -128,5 +144,160 @@ func TestTask_IsBlocked_MixedStatuses(t *testing.T) {
 		ID: "TEST-TABCDE", Status: StatusOpen,
 		DependsOn: []string{"TEST-T11111", "TEST-T22222"},
 	}
-	assert.True(t, task.IsBlocked(all))
+	assert.True(t, task.IsBlocked(all, StrictDeps))
+}
+
+func TestTask_IsBlocked_MissingDep(t *testing.T) {
+	all := map[string]*Task{
+		"TEST-T11111": {ID: "TEST-T11111", Status: StatusClosed},
+	}
+	task := &Task{
+		ID: "TEST-TABCDE", Status: StatusOpen,
+		DependsOn: []string{"TEST-T11111", "TEST-T22222"},
+	}
+	assert.Equal(t, []string{"TEST-T22222"}, task.MissingDeps(all))
+	assert.True(t, task.IsBlocked(all, StrictDeps))
+	assert.False(t, task.IsBlocked(all, LenientDeps))
+	assert.Equal(t, LenientDeps, ParseDepPolicy("lenient"))
+	assert.Equal(t, StrictDeps, ParseDepPolicy(""))
+}
+
+// --- Release tests ---
+
+func TestRelease_Validate_Valid(t *testing.T) {
+	r := &Release{ID: "TEST-RABCDE", Version: "1.0.0", Project: "TEST", Status: ReleasePlanned, TargetDate: "2026-03-01"}
+	assert.NoError(t, r.Validate())
+}
+
+func TestRelease_Validate_BadTargetDate(t *testing.T) {
+	r := &Release{ID: "TEST-RABCDE", Version: "1.0.0", Project: "TEST", Status: ReleasePlanned, TargetDate: "March 1"}
+	assert.Error(t, r.Validate())
+}
+
+func TestRelease_Validate_InvalidStatus(t *testing.T) {
+	r := &Release{ID: "TEST-RABCDE", Version: "1.0.0", Project: "TEST", Status: "shipped"}
+	assert.Error(t, r.Validate())
+}
+
+func TestRelease_Validate_DuplicateItems(t *testing.T) {
+	r := &Release{
+		ID: "TEST-RABCDE", Version: "1.0.0", Project: "TEST", Status: ReleasePlanned,
+		Items: []string{"TEST-T22222", "TEST-T22222"},
+	}
+	assert.Error(t, r.Validate())
+}
+
+func TestDocument_Validate_Kind(t *testing.T) {
+	d := &Document{ID: "TEST-DABCDE", Title: "Doc", Project: "TEST", Kind: DocRunbook}
+	assert.NoError(t, d.Validate())
+
+	d.Kind = "memo"
+	assert.Error(t, d.Validate())
+
+	d.Kind, d.Number, d.Status = DocSpec, 1, ADRProposed
+	assert.Error(t, d.Validate(), "only ADRs are numbered")
+
+	d.Kind = DocADR
+	assert.NoError(t, d.Validate())
+	assert.Equal(t, "ADR-0001", d.ADRLabel())
+
+	d.Status = "rejected"
+	assert.Error(t, d.Validate())
+
+	d.Status = ADRSuperseded
+	assert.Error(t, d.Validate(), "superseded needs a successor")
+	d.SupersededBy = "TEST-D22222"
+	assert.NoError(t, d.Validate())
+	d.Supersedes = d.SupersededBy
+	assert.Error(t, d.Validate())
+}
+
+// --- Waiting tests ---
+
+func TestTask_Validate_WaitingRequiresDescription(t *testing.T) {
+	task := &Task{ID: "TEST-TABCDE", Title: "T", Project: "TEST", Type: TypeTask, Status: StatusOpen, Waiting: &WaitingOn{}}
+	assert.Error(t, task.Validate())
+}
+
+func TestTask_Validate_WaitingBadDate(t *testing.T) {
+	task := &Task{ID: "TEST-TABCDE", Title: "T", Project: "TEST", Type: TypeTask, Status: StatusOpen,
+		Waiting: &WaitingOn{Description: "vendor", Until: "next week"}}
+	assert.Error(t, task.Validate())
+}
+
+func TestTask_Validate_EpicCannotWait(t *testing.T) {
+	task := &Task{ID: "TEST-TABCDE", Title: "E", Project: "TEST", Type: TypeEpic, Waiting: &WaitingOn{Description: "vendor"}}
+	assert.Error(t, task.Validate())
+}
+
+func TestWaitingOn_Active(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.False(t, (*WaitingOn)(nil).Active(at))
+	assert.True(t, (&WaitingOn{Description: "d"}).Active(at))
+	assert.True(t, (&WaitingOn{Description: "d", Until: "2026-03-02"}).Active(at))
+	assert.False(t, (&WaitingOn{Description: "d", Until: "2026-03-01"}).Active(at))
+}
+
+func TestTask_IsBlocked_Waiting(t *testing.T) {
+	task := &Task{ID: "TEST-TABCDE", Status: StatusOpen, Waiting: &WaitingOn{Description: "vendor"}}
+	assert.True(t, task.IsBlocked(nil, StrictDeps))
+
+	task.Waiting.Until = "2000-01-01"
+	assert.False(t, task.IsBlocked(nil, StrictDeps))
+}
+
+func TestProject_Validate_WIPLimits(t *testing.T) {
+	p := &Project{ID: "AUTH", Name: "Test", WIPLimits: map[Status]int{StatusInProgress: 3}}
+	assert.NoError(t, p.Validate())
+	p.WIPLimits = map[Status]int{"doing": 3}
+	assert.Error(t, p.Validate())
+	p.WIPLimits = map[Status]int{StatusInProgress: 0}
+	assert.Error(t, p.Validate())
+}
+
+func TestProject_WIPViolations(t *testing.T) {
+	p := &Project{ID: "AUTH", Name: "Test", WIPLimits: map[Status]int{StatusInProgress: 1, StatusBlocked: 2}}
+	tasks := []Task{
+		{Type: TypeTask, Status: StatusInProgress},
+		{Type: TypeTask, Status: StatusInProgress},
+		{Type: TypeTask, Status: StatusBlocked},
+		{Type: TypeEpic},
+	}
+	assert.Equal(t, []WIPViolation{{Status: StatusInProgress, Count: 2, Limit: 1}}, p.WIPViolations(tasks))
+	assert.Empty(t, p.WIPViolations(tasks[1:]))
+}
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "fix-oauth-login-on-safari", Slugify("Fix OAuth login (on Safari!)"))
+	assert.Equal(t, "caf-menu", Slugify("Café menu"))
+	assert.Equal(t, "word-word-word-word-word-word-word-word", Slugify(strings.Repeat("word ", 20)))
+}
+
+func TestValidateSlug(t *testing.T) {
+	assert.NoError(t, ValidateSlug("login-form"))
+	assert.NoError(t, ValidateSlug("v2"))
+	for _, bad := range []string{"", "Login", "login--form", "-login", "login form", strings.Repeat("a", MaxSlugLen+1)} {
+		assert.Error(t, ValidateSlug(bad), bad)
+	}
+	task := &Task{ID: "AUTH-TABCDE", Title: "T", Type: TypeTask, Project: "AUTH", Slug: "Bad Slug"}
+	assert.ErrorContains(t, task.Validate(), `like "bad-slug"`)
+}
+
+func TestParseWhen(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+	got, ok := ParseWhen("2026-03-01", now, 9*time.Hour, false)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), got)
+
+	got, _ = ParseWhen("3d", now, 0, false)
+	assert.Equal(t, time.Date(2026, 3, 13, 15, 30, 0, 0, time.UTC), got)
+	got, _ = ParseWhen("3d", now, 0, true)
+	assert.Equal(t, time.Date(2026, 3, 7, 15, 30, 0, 0, time.UTC), got)
+	got, _ = ParseWhen("2h", now, 0, true)
+	assert.Equal(t, time.Date(2026, 3, 10, 13, 30, 0, 0, time.UTC), got)
+
+	for _, bad := range []string{"", "last week", "-3d", "-2h"} {
+		_, ok := ParseWhen(bad, now, 0, false)
+		assert.False(t, ok, bad)
+	}
 }
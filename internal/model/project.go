@@ -3,14 +3,26 @@ package model
 import (
 	"fmt"
 	"time"
+
+	"github.com/rogersnm/compass/internal/id"
 )
 
 type Project struct {
-	ID        string    `yaml:"id"`
-	Name      string    `yaml:"name"`
-	CreatedBy string    `yaml:"created_by"`
-	CreatedAt time.Time `yaml:"created_at"`
-	UpdatedAt time.Time `yaml:"updated_at"`
+	ID        string    `yaml:"id" json:"id"`
+	Name      string    `yaml:"name" json:"name"`
+	CreatedBy string    `yaml:"created_by" json:"created_by"`
+	CreatedAt time.Time `yaml:"created_at" json:"created_at"`
+	UpdatedAt time.Time `yaml:"updated_at" json:"updated_at"`
+	// WIPLimits caps how many tasks may be in a status at once, e.g.
+	// {in_progress: 3}. Statuses without a limit are unlimited.
+	WIPLimits map[Status]int `yaml:"wip_limits,omitempty" json:"wip_limits,omitempty"`
+	// AutoCloseEpics closes an epic when its last open task closes. An
+	// epic's own auto_close overrides it.
+	AutoCloseEpics bool `yaml:"auto_close_epics,omitempty" json:"auto_close_epics,omitempty"`
+	// IDLength is the hash length of new task, document and release IDs,
+	// for projects big enough for the default 5 to collide. Existing IDs
+	// keep theirs.
+	IDLength int `yaml:"id_length,omitempty" json:"id_length,omitempty"`
 }
 
 func (p *Project) Validate() error {
@@ -20,5 +32,45 @@ func (p *Project) Validate() error {
 	if p.Name == "" {
 		return fmt.Errorf("project name is required")
 	}
+	if p.IDLength != 0 && (p.IDLength < id.DefaultHashLen || p.IDLength > id.MaxHashLen) {
+		return fmt.Errorf("id_length must be %d-%d, not %d", id.DefaultHashLen, id.MaxHashLen, p.IDLength)
+	}
+	for status, limit := range p.WIPLimits {
+		if err := ValidateStatus(status); err != nil {
+			return fmt.Errorf("wip_limits: %w", err)
+		}
+		if limit <= 0 {
+			return fmt.Errorf("wip_limits: limit for %s must be positive", status)
+		}
+	}
 	return nil
 }
+
+// WIPViolation is a status holding more tasks than its project allows.
+type WIPViolation struct {
+	Status Status
+	Count  int
+	Limit  int
+}
+
+// WIPViolations returns the limited statuses that tasks exceed, in status
+// order. Epics have no status and don't count.
+func (p *Project) WIPViolations(tasks []Task) []WIPViolation {
+	if len(p.WIPLimits) == 0 {
+		return nil
+	}
+	counts := map[Status]int{}
+	for _, t := range tasks {
+		if t.Type == TypeTask {
+			counts[t.Status]++
+		}
+	}
+	var out []WIPViolation
+	for _, status := range Statuses {
+		limit, ok := p.WIPLimits[status]
+		if ok && counts[status] > limit {
+			out = append(out, WIPViolation{Status: status, Count: counts[status], Limit: limit})
+		}
+	}
+	return out
+}
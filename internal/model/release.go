@@ -0,0 +1,58 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type ReleaseStatus string
+
+const (
+	ReleasePlanned ReleaseStatus = "planned"
+	ReleaseCut     ReleaseStatus = "cut"
+)
+
+// DateFormat is the layout used for date-only frontmatter fields.
+const DateFormat = "2006-01-02"
+
+type Release struct {
+	ID         string        `yaml:"id" json:"id"`
+	Version    string        `yaml:"version" json:"version"`
+	Project    string        `yaml:"project" json:"project"`
+	Status     ReleaseStatus `yaml:"status" json:"status"`
+	TargetDate string        `yaml:"target_date,omitempty" json:"target_date,omitempty"`
+	Items      []string      `yaml:"items,omitempty" json:"items,omitempty"`         // included epic/task IDs
+	Changelog  string        `yaml:"changelog,omitempty" json:"changelog,omitempty"` // document ID written on cut
+	CutAt      *time.Time    `yaml:"cut_at,omitempty" json:"cut_at,omitempty"`
+	CreatedBy  string        `yaml:"created_by" json:"created_by"`
+	CreatedAt  time.Time     `yaml:"created_at" json:"created_at"`
+	UpdatedAt  time.Time     `yaml:"updated_at" json:"updated_at"`
+}
+
+func (r *Release) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("release id is required")
+	}
+	if r.Version == "" {
+		return fmt.Errorf("release version is required")
+	}
+	if r.Project == "" {
+		return fmt.Errorf("release project is required")
+	}
+	if r.Status != ReleasePlanned && r.Status != ReleaseCut {
+		return fmt.Errorf("invalid release status %q: must be planned or cut", r.Status)
+	}
+	if r.TargetDate != "" {
+		if _, err := time.Parse(DateFormat, r.TargetDate); err != nil {
+			return fmt.Errorf("invalid target date %q: must be YYYY-MM-DD", r.TargetDate)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, item := range r.Items {
+		if seen[item] {
+			return fmt.Errorf("duplicate release item %q", item)
+		}
+		seen[item] = true
+	}
+	return nil
+}
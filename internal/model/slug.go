@@ -0,0 +1,39 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MaxSlugLen keeps slugs, and the branch names made from titles, readable
+// in prompts and PR lists.
+const MaxSlugLen = 40
+
+var slugRe = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Slugify lowercases s and joins its words with hyphens, keeping only
+// ASCII letters and digits and at most MaxSlugLen characters.
+func Slugify(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r >= unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	slug := strings.Join(words, "-")
+	if len(slug) > MaxSlugLen {
+		slug = strings.TrimRight(slug[:MaxSlugLen], "-")
+	}
+	return slug
+}
+
+// ValidateSlug checks that slug is lowercase letters and digits in
+// hyphenated words, as Slugify makes them.
+func ValidateSlug(slug string) error {
+	if !slugRe.MatchString(slug) {
+		return fmt.Errorf("invalid slug %q: use lowercase letters, digits and hyphens, like %q", slug, Slugify(slug))
+	}
+	if len(slug) > MaxSlugLen {
+		return fmt.Errorf("invalid slug %q: longer than %d characters", slug, MaxSlugLen)
+	}
+	return nil
+}
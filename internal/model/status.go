@@ -8,15 +8,19 @@ const (
 	StatusOpen       Status = "open"
 	StatusInProgress Status = "in_progress"
 	StatusClosed     Status = "closed"
+	// StatusBlocked marks a task held up by something outside compass, such
+	// as a vendor or another team. See Task.BlockedReason.
+	StatusBlocked Status = "blocked"
 )
 
-var validStatuses = []Status{StatusOpen, StatusInProgress, StatusClosed}
+// Statuses lists every task status in workflow order.
+var Statuses = []Status{StatusOpen, StatusInProgress, StatusBlocked, StatusClosed}
 
 func ValidateStatus(s Status) error {
-	for _, v := range validStatuses {
+	for _, v := range Statuses {
 		if s == v {
 			return nil
 		}
 	}
-	return fmt.Errorf("invalid status %q: must be one of open, in_progress, closed", s)
+	return fmt.Errorf("invalid status %q: must be one of open, in_progress, blocked, closed", s)
 }
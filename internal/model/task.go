@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -13,17 +14,49 @@ const (
 )
 
 type Task struct {
-	ID        string   `yaml:"id"`
-	Title     string   `yaml:"title"`
-	Type      TaskType `yaml:"type"`
-	Project   string   `yaml:"project"`
-	Epic      string   `yaml:"epic,omitempty"`
-	Status    Status   `yaml:"status,omitempty"`
-	Priority  *int     `yaml:"priority,omitempty"`
-	DependsOn []string `yaml:"depends_on,omitempty"`
-	CreatedBy string   `yaml:"created_by"`
-	CreatedAt time.Time `yaml:"created_at"`
-	UpdatedAt time.Time `yaml:"updated_at"`
+	ID        string     `yaml:"id" json:"id"`
+	Title     string     `yaml:"title" json:"title"`
+	Type      TaskType   `yaml:"type" json:"type"`
+	Project   string     `yaml:"project" json:"project"`
+	Epic      string     `yaml:"epic,omitempty" json:"epic,omitempty"`
+	Status    Status     `yaml:"status,omitempty" json:"status,omitempty"`
+	Priority  *int       `yaml:"priority,omitempty" json:"priority,omitempty"`
+	DependsOn []string   `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
+	Waiting   *WaitingOn `yaml:"waiting,omitempty" json:"waiting,omitempty"`
+	Due       string     `yaml:"due,omitempty" json:"due,omitempty"` // YYYY-MM-DD
+	Assignee  string     `yaml:"assignee,omitempty" json:"assignee,omitempty"`
+	// Slug names the task within its project for "KEY/slug" references.
+	// Slugs are unique across a project's tasks and documents.
+	Slug string `yaml:"slug,omitempty" json:"slug,omitempty"`
+	// Watchers lists the people following the task besides its creator and
+	// assignee.
+	Watchers []string `yaml:"watchers,omitempty" json:"watchers,omitempty"`
+	// BlockedReason explains a manual block and is only set while Status is
+	// blocked.
+	BlockedReason string `yaml:"blocked_reason,omitempty" json:"blocked_reason,omitempty"`
+	// ClosedAt is set when the task is closed and cleared if it reopens.
+	// An epic that auto-closes has it while all its tasks are closed.
+	ClosedAt *time.Time `yaml:"closed_at,omitempty" json:"closed_at,omitempty"`
+	// AutoClose, on an epic, overrides its project's auto_close_epics.
+	AutoClose *bool `yaml:"auto_close,omitempty" json:"auto_close,omitempty"`
+	// History lists status changes, oldest first.
+	History   []StatusChange `yaml:"history,omitempty" json:"history,omitempty"`
+	CreatedBy string         `yaml:"created_by" json:"created_by"`
+	CreatedAt time.Time      `yaml:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `yaml:"updated_at" json:"updated_at"`
+	// DeletedAt is set on a task a cloud store has soft-deleted. Local
+	// tasks are removed outright, so it's never written to a file.
+	DeletedAt *time.Time `yaml:"-" json:"deleted_at,omitempty"`
+	// Store names the store the task was listed from. Only listings that
+	// span stores set it.
+	Store string `yaml:"-" json:"store,omitempty"`
+}
+
+// StatusChange records who moved a task to a status, and when.
+type StatusChange struct {
+	Status Status    `yaml:"status" json:"status"`
+	At     time.Time `yaml:"at" json:"at"`
+	By     string    `yaml:"by" json:"by"`
 }
 
 func (t *Task) Validate() error {
@@ -36,6 +69,11 @@ func (t *Task) Validate() error {
 	if t.Project == "" {
 		return fmt.Errorf("task project is required")
 	}
+	if t.Slug != "" {
+		if err := ValidateSlug(t.Slug); err != nil {
+			return err
+		}
+	}
 	if t.Type != TypeTask && t.Type != TypeEpic {
 		return fmt.Errorf("invalid task type %q: must be task or epic", t.Type)
 	}
@@ -48,12 +86,31 @@ func (t *Task) Validate() error {
 			return err
 		}
 	}
+	if t.BlockedReason != "" && t.Status != StatusBlocked {
+		return fmt.Errorf("blocked reason is only allowed on blocked tasks")
+	}
 	if t.Priority != nil && (*t.Priority < 0 || *t.Priority > 3) {
 		return fmt.Errorf("invalid priority %d: must be 0-3", *t.Priority)
 	}
+	if t.Type != TypeEpic && t.AutoClose != nil {
+		return fmt.Errorf("auto_close is only allowed on epics")
+	}
 	if t.Type == TypeEpic && len(t.DependsOn) > 0 {
 		return fmt.Errorf("epic-type tasks cannot have dependencies")
 	}
+	if t.Due != "" {
+		if _, err := time.Parse(DateFormat, t.Due); err != nil {
+			return fmt.Errorf("invalid due date %q: must be YYYY-MM-DD", t.Due)
+		}
+	}
+	if t.Waiting != nil {
+		if t.Type == TypeEpic {
+			return fmt.Errorf("epic-type tasks cannot wait on external events")
+		}
+		if err := t.Waiting.Validate(); err != nil {
+			return err
+		}
+	}
 	seen := make(map[string]bool)
 	for _, dep := range t.DependsOn {
 		if dep == t.ID {
@@ -67,6 +124,45 @@ func (t *Task) Validate() error {
 	return nil
 }
 
+// WatchedBy reports whether user is one of the task's watchers.
+func (t *Task) WatchedBy(user string) bool {
+	return slices.Contains(t.Watchers, user)
+}
+
+// Involves reports whether user created, is assigned or watches the task.
+func (t *Task) Involves(user string) bool {
+	return t.CreatedBy == user || t.Assignee == user || t.WatchedBy(user)
+}
+
+// AutoCloses reports whether epic t closes when its last open task does,
+// by its own auto_close or else its project p's auto_close_epics.
+func (t *Task) AutoCloses(p *Project) bool {
+	if t.AutoClose != nil {
+		return *t.AutoClose
+	}
+	return p != nil && p.AutoCloseEpics
+}
+
+// ClosedTime returns when a closed task was closed. Tasks closed before
+// ClosedAt was recorded fall back to their last update.
+func (t *Task) ClosedTime() time.Time {
+	if t.ClosedAt != nil {
+		return *t.ClosedAt
+	}
+	return t.UpdatedAt
+}
+
+// LastChangeTo returns the most recent change to status, or nil if the
+// history has none.
+func (t *Task) LastChangeTo(status Status) *StatusChange {
+	for i := len(t.History) - 1; i >= 0; i-- {
+		if t.History[i].Status == status {
+			return &t.History[i]
+		}
+	}
+	return nil
+}
+
 // FormatPriority returns "P0"-"P3" or "" if unset.
 func FormatPriority(p *int) string {
 	if p == nil {
@@ -86,13 +182,122 @@ func ChildrenOf(epicID string, allTasks map[string]*Task) []*Task {
 	return children
 }
 
-// IsBlocked returns true if any dependency is not closed.
-func (t *Task) IsBlocked(allTasks map[string]*Task) bool {
+// WaitingOn records an external event a task is waiting for, such as a vendor
+// reply or another team's release. Until is an optional YYYY-MM-DD date on
+// which the wait lapses on its own.
+type WaitingOn struct {
+	Description string `yaml:"description" json:"description"`
+	URL         string `yaml:"url,omitempty" json:"url,omitempty"`
+	Until       string `yaml:"until,omitempty" json:"until,omitempty"`
+}
+
+func (w *WaitingOn) Validate() error {
+	if w.Description == "" {
+		return fmt.Errorf("waiting description is required")
+	}
+	if w.Until != "" {
+		if _, err := time.Parse(DateFormat, w.Until); err != nil {
+			return fmt.Errorf("invalid waiting until date %q: must be YYYY-MM-DD", w.Until)
+		}
+	}
+	return nil
+}
+
+// Active reports whether the wait still applies at the given time. A wait
+// with no Until date stays active until cleared; otherwise it lapses at the
+// start of the Until date (UTC).
+func (w *WaitingOn) Active(at time.Time) bool {
+	if w == nil {
+		return false
+	}
+	if w.Until == "" {
+		return true
+	}
+	until, err := time.Parse(DateFormat, w.Until)
+	if err != nil {
+		return true
+	}
+	return at.Before(until)
+}
+
+// DepPolicy is how IsBlocked counts a dependency on a task missing from
+// allTasks, as set by missing_deps in config.yaml.
+type DepPolicy int
+
+const (
+	// StrictDeps counts a missing dependency as open, so it blocks.
+	StrictDeps DepPolicy = iota
+	// LenientDeps skips missing dependencies.
+	LenientDeps
+)
+
+// ParseDepPolicy returns the policy a missing_deps value names: "lenient",
+// or strict for anything else, including unset.
+func ParseDepPolicy(name string) DepPolicy {
+	if name == "lenient" {
+		return LenientDeps
+	}
+	return StrictDeps
+}
+
+// IsBlocked returns true if any dependency is not closed or the task is
+// waiting on an external event that has not lapsed. deps says whether a
+// dependency missing from allTasks blocks.
+func (t *Task) IsBlocked(allTasks map[string]*Task, deps DepPolicy) bool {
+	if t.Waiting.Active(time.Now()) {
+		return true
+	}
 	for _, dep := range t.DependsOn {
 		dt, ok := allTasks[dep]
+		if !ok && deps == LenientDeps {
+			continue
+		}
 		if !ok || dt.Status != StatusClosed {
 			return true
 		}
 	}
 	return false
 }
+
+// MissingDeps returns the dependencies of t that aren't in allTasks.
+func (t *Task) MissingDeps(allTasks map[string]*Task) []string {
+	var missing []string
+	for _, dep := range t.DependsOn {
+		if _, ok := allTasks[dep]; !ok {
+			missing = append(missing, dep)
+		}
+	}
+	return missing
+}
+
+// Progress counts an epic's child tasks by status.
+type Progress struct {
+	Total    int            `json:"total"`
+	Closed   int            `json:"closed"`
+	ByStatus map[Status]int `json:"by_status"`
+}
+
+// ProgressOf counts the type=task entries of children, an epic's tasks.
+func ProgressOf(children []Task) Progress {
+	p := Progress{ByStatus: map[Status]int{}}
+	for _, t := range children {
+		if t.Type == TypeEpic {
+			continue
+		}
+		p.Total++
+		p.ByStatus[t.Status]++
+		if t.Status == StatusClosed {
+			p.Closed++
+		}
+	}
+	return p
+}
+
+// Percent returns the share of tasks closed, rounded down; 0 when there
+// are none.
+func (p Progress) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Closed * 100 / p.Total
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseWhen reads a time given on the command line: RFC 3339,
+// "YYYY-MM-DDTHH:MM" or "YYYY-MM-DD HH:MM", a bare date (dayStart into
+// that day), "today" or "yesterday" (local midnight), or a span such as
+// "2h" or "3d" counted forward from now, or back when past is set. Times
+// without a zone are in now's location. ok is false if s is none of these.
+func ParseWhen(s string, now time.Time, dayStart time.Duration, past bool) (t time.Time, ok bool) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch s {
+	case "today":
+		return midnight, true
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, true
+		}
+	}
+	if t, err := time.ParseInLocation(DateFormat, s, now.Location()); err == nil {
+		return t.Add(dayStart), true
+	}
+	sign := 1
+	if past {
+		sign = -1
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, sign*n), true
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(time.Duration(sign) * d), true
+	}
+	return time.Time{}, false
+}
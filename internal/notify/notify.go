@@ -0,0 +1,193 @@
+// Package notify sends messages about task events to the sinks configured
+// under "notifications" in config.yaml: Slack incoming webhooks, generic
+// JSON webhooks and email over SMTP.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rogersnm/compass/internal/config"
+)
+
+type Event string
+
+const (
+	EventAssigned      Event = "task_assigned"  // a task is assigned to the sink's person
+	EventP0Created     Event = "p0_created"     // a task is created at priority 0
+	EventEpicCompleted Event = "epic_completed" // the last open task in an epic is closed
+	EventTest          Event = "test"           // sent by "compass notify test"
+)
+
+// Events lists the events sinks can subscribe to.
+var Events = []Event{EventAssigned, EventP0Created, EventEpicCompleted}
+
+// Message is one notification. Webhook sinks receive it as JSON.
+type Message struct {
+	Event    Event  `json:"event"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	Task     string `json:"task,omitempty"`
+	Assignee string `json:"assignee,omitempty"`
+}
+
+// Sink delivers messages to one destination.
+type Sink interface {
+	Send(m Message) error
+}
+
+// NamedSink is a configured sink and the events it subscribes to.
+type NamedSink struct {
+	Name   string
+	Events []Event
+	// Assignee is the person whose task_assigned events the sink gets.
+	Assignee string
+	Sink
+}
+
+// Wants reports whether the sink subscribes to m.
+func (s NamedSink) Wants(m Message) bool {
+	if len(s.Events) > 0 && !slices.Contains(s.Events, m.Event) {
+		return false
+	}
+	return m.Event != EventAssigned || m.Assignee == s.Assignee
+}
+
+// Notifier fans messages out to the subscribed sinks.
+type Notifier struct {
+	Sinks []NamedSink
+}
+
+// New builds a Notifier from config. me is the default person for
+// task_assigned events on sinks that don't name one.
+func New(sinks []config.NotifySink, me string) (*Notifier, error) {
+	n := &Notifier{}
+	for i, c := range sinks {
+		name := c.Name
+		if name == "" {
+			name = c.Type
+		}
+		sink, err := NewSink(c)
+		if err != nil {
+			return nil, fmt.Errorf("notifications[%d] (%s): %w", i, name, err)
+		}
+		ns := NamedSink{Name: name, Assignee: me, Sink: sink}
+		for _, e := range c.Events {
+			if !slices.Contains(Events, Event(e)) {
+				return nil, fmt.Errorf("notifications[%d] (%s): unknown event %q (valid: %s)", i, name, e, eventNames())
+			}
+			ns.Events = append(ns.Events, Event(e))
+		}
+		if c.Assignee != "" {
+			ns.Assignee = c.Assignee
+		}
+		n.Sinks = append(n.Sinks, ns)
+	}
+	return n, nil
+}
+
+func eventNames() string {
+	names := make([]string, len(Events))
+	for i, e := range Events {
+		names[i] = string(e)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Notify sends m to every sink that wants it and joins their errors.
+func (n *Notifier) Notify(m Message) error {
+	var errs []error
+	for _, s := range n.Sinks {
+		if !s.Wants(m) {
+			continue
+		}
+		if err := s.Send(m); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewSink builds the sink for c, expanding environment references.
+func NewSink(c config.NotifySink) (Sink, error) {
+	c, err := c.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	switch c.Type {
+	case "slack", "webhook":
+		if c.URL == "" {
+			return nil, fmt.Errorf("url is required")
+		}
+		return &webhookSink{url: c.URL, slack: c.Type == "slack"}, nil
+	case "smtp":
+		if c.Host == "" || c.From == "" || len(c.To) == 0 {
+			return nil, fmt.Errorf("host, from and to are required")
+		}
+		if _, _, err := net.SplitHostPort(c.Host); err != nil {
+			return nil, fmt.Errorf("host must be host:port: %w", err)
+		}
+		return &smtpSink{cfg: c}, nil
+	}
+	return nil, fmt.Errorf("unknown type %q (valid: slack, webhook, smtp)", c.Type)
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// webhookSink POSTs the message as JSON. Slack incoming webhooks get
+// {"text": ...} instead of the full message.
+type webhookSink struct {
+	url   string
+	slack bool
+}
+
+func (w *webhookSink) Send(m Message) error {
+	var payload any = m
+	if w.slack {
+		payload = map[string]string{"text": "*" + m.Subject + "*\n" + m.Text}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Post(w.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// sendMail is a variable so tests can stub it.
+var sendMail = smtp.SendMail
+
+type smtpSink struct {
+	cfg config.NotifySink
+}
+
+func (s *smtpSink) Send(m Message) error {
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(s.cfg.Host)
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", m.Subject)
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(m.Text, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+	return sendMail(s.cfg.Host, auth, s.cfg.From, s.cfg.To, msg.Bytes())
+}
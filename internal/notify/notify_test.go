@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a webhook endpoint that keeps every payload it receives.
+func recorder(t *testing.T) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+	var got []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		got = append(got, m)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func TestNew_Validates(t *testing.T) {
+	_, err := New([]config.NotifySink{{Type: "pager"}}, "me")
+	assert.ErrorContains(t, err, `unknown type "pager"`)
+	_, err = New([]config.NotifySink{{Type: "slack"}}, "me")
+	assert.ErrorContains(t, err, "url is required")
+	_, err = New([]config.NotifySink{{Type: "webhook", URL: "http://x", Events: []string{"task_closed"}}}, "me")
+	assert.ErrorContains(t, err, `unknown event "task_closed"`)
+	_, err = New([]config.NotifySink{{Type: "smtp", Host: "mail", From: "a@x", To: []string{"b@x"}}}, "me")
+	assert.ErrorContains(t, err, "host:port")
+	_, err = New([]config.NotifySink{{Type: "slack", URL: "${COMPASS_TEST_UNSET_HOOK}"}}, "me")
+	assert.ErrorContains(t, err, "COMPASS_TEST_UNSET_HOOK")
+}
+
+func TestNotify_SlackAndWebhook(t *testing.T) {
+	slack, slackGot := recorder(t)
+	hook, hookGot := recorder(t)
+	t.Setenv("COMPASS_TEST_HOOK", hook.URL)
+
+	n, err := New([]config.NotifySink{
+		{Type: "slack", URL: slack.URL, Events: []string{"p0_created"}},
+		{Type: "webhook", URL: "${COMPASS_TEST_HOOK}"},
+	}, "alice")
+	require.NoError(t, err)
+
+	require.NoError(t, n.Notify(Message{Event: EventP0Created, Subject: "P0 created: Outage", Text: "details", Task: "AUTH-TAAAAA"}))
+	require.NoError(t, n.Notify(Message{Event: EventAssigned, Subject: "Assigned", Assignee: "alice"}))
+	require.NoError(t, n.Notify(Message{Event: EventAssigned, Subject: "Assigned", Assignee: "bob"}))
+
+	require.Len(t, *slackGot, 1)
+	assert.Equal(t, "*P0 created: Outage*\ndetails", (*slackGot)[0]["text"])
+	require.Len(t, *hookGot, 2)
+	assert.Equal(t, "AUTH-TAAAAA", (*hookGot)[0]["task"])
+	assert.Equal(t, "alice", (*hookGot)[1]["assignee"])
+}
+
+func TestNotify_ReportsFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+	n, err := New([]config.NotifySink{{Name: "team", Type: "webhook", URL: srv.URL}}, "me")
+	require.NoError(t, err)
+	assert.ErrorContains(t, n.Notify(Message{Event: EventP0Created}), "team: webhook returned 403")
+}
+
+func TestSMTPSink(t *testing.T) {
+	var gotAddr string
+	var gotTo []string
+	var gotMsg string
+	orig := sendMail
+	sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotTo, gotMsg = addr, to, string(msg)
+		return nil
+	}
+	t.Cleanup(func() { sendMail = orig })
+
+	s, err := NewSink(config.NotifySink{Type: "smtp", Host: "smtp.example.com:587", From: "compass@example.com", To: []string{"me@example.com"}})
+	require.NoError(t, err)
+	require.NoError(t, s.Send(Message{Subject: "Epic completed: Auth", Text: "All done."}))
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, []string{"me@example.com"}, gotTo)
+	assert.Contains(t, gotMsg, "Subject: Epic completed: Auth\r\n")
+	assert.True(t, strings.HasSuffix(gotMsg, "\r\n\r\nAll done.\r\n"))
+}
+
+func TestWrap_Events(t *testing.T) {
+	hook, got := recorder(t)
+	n, err := New([]config.NotifySink{{Type: "webhook", URL: hook.URL}}, "alice")
+	require.NoError(t, err)
+	ls := store.NewLocal(t.TempDir())
+	var errs []error
+	s := Wrap(ls, n, func(err error) { errs = append(errs, err) })
+
+	p, err := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	require.NoError(t, err)
+	epic, err := s.CreateTask(t.Context(), "Login", p.ID, store.TaskCreateOpts{Type: model.TypeEpic})
+	require.NoError(t, err)
+	p0 := 0
+	t1, err := s.CreateTask(t.Context(), "Outage", p.ID, store.TaskCreateOpts{Epic: epic.ID, Priority: &p0})
+	require.NoError(t, err)
+	t2, err := s.CreateTask(t.Context(), "Form", p.ID, store.TaskCreateOpts{Epic: epic.ID})
+	require.NoError(t, err)
+
+	alice, bob := "alice", "bob"
+	_, err = s.UpdateTask(t.Context(), t2.ID, store.TaskUpdate{Assignee: &bob})
+	require.NoError(t, err)
+	_, err = s.UpdateTask(t.Context(), t2.ID, store.TaskUpdate{Assignee: &alice})
+	require.NoError(t, err)
+
+	closed := model.StatusClosed
+	_, err = s.UpdateTask(t.Context(), t1.ID, store.TaskUpdate{Status: &closed})
+	require.NoError(t, err)
+	_, err = s.UpdateTask(t.Context(), t2.ID, store.TaskUpdate{Status: &closed})
+	require.NoError(t, err)
+	// Closing an already closed task is not a new completion.
+	_, err = s.UpdateTask(t.Context(), t2.ID, store.TaskUpdate{Status: &closed})
+	require.NoError(t, err)
+
+	require.Empty(t, errs)
+	var events []string
+	for _, m := range *got {
+		events = append(events, m["event"].(string)+" "+m["task"].(string))
+	}
+	assert.Equal(t, []string{
+		"p0_created " + t1.ID,
+		"task_assigned " + t2.ID,
+		"epic_completed " + epic.ID,
+	}, events)
+}
@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+)
+
+// notifyingStore wraps a Store and sends notifications for the task changes
+// made through it. Failed notifications are reported to onErr and never fail
+// the change itself.
+type notifyingStore struct {
+	store.Store
+	n     *Notifier
+	onErr func(error)
+}
+
+// Wrap returns s with notifications for task creates and updates.
+func Wrap(s store.Store, n *Notifier, onErr func(error)) store.Store {
+	return &notifyingStore{Store: s, n: n, onErr: onErr}
+}
+
+func (s *notifyingStore) notify(m Message) {
+	s.report(s.n.Notify(m))
+}
+
+func (s *notifyingStore) report(err error) {
+	if err != nil && s.onErr != nil {
+		s.onErr(err)
+	}
+}
+
+func (s *notifyingStore) CreateTask(ctx context.Context, title, projectID string, opts store.TaskCreateOpts) (*model.Task, error) {
+	t, err := s.Store.CreateTask(ctx, title, projectID, opts)
+	if err != nil {
+		return nil, err
+	}
+	if t.Priority != nil && *t.Priority == 0 {
+		s.notify(Message{
+			Event:   EventP0Created,
+			Subject: fmt.Sprintf("P0 created: %s", t.Title),
+			Text:    fmt.Sprintf("%s created P0 task %s %s.", t.CreatedBy, t.ID, t.Title),
+			Task:    t.ID,
+		})
+	}
+	if t.Assignee != "" {
+		s.notify(assignedMessage(t))
+	}
+	return t, nil
+}
+
+func (s *notifyingStore) UpdateTask(ctx context.Context, taskID string, upd store.TaskUpdate) (*model.Task, error) {
+	if upd.Assignee == nil && upd.Status == nil {
+		return s.Store.UpdateTask(ctx, taskID, upd)
+	}
+	before, _, err := s.Store.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.Store.UpdateTask(ctx, taskID, upd)
+	if err != nil {
+		return nil, err
+	}
+	if t.Assignee != "" && t.Assignee != before.Assignee {
+		s.notify(assignedMessage(t))
+	}
+	if t.Epic != "" && t.Status == model.StatusClosed && before.Status != model.StatusClosed {
+		s.checkEpic(ctx, t)
+	}
+	return t, nil
+}
+
+func assignedMessage(t *model.Task) Message {
+	return Message{
+		Event:    EventAssigned,
+		Subject:  fmt.Sprintf("Assigned: %s", t.Title),
+		Text:     fmt.Sprintf("%s %s was assigned to %s.", t.ID, t.Title, t.Assignee),
+		Task:     t.ID,
+		Assignee: t.Assignee,
+	}
+}
+
+// checkEpic notifies when t was the last open task in its epic.
+func (s *notifyingStore) checkEpic(ctx context.Context, t *model.Task) {
+	tasks, err := s.Store.ListTasks(ctx, store.TaskFilter{ProjectID: t.Project, EpicID: t.Epic})
+	if err != nil {
+		s.report(fmt.Errorf("checking epic %s: %w", t.Epic, err))
+		return
+	}
+	for _, c := range tasks {
+		if c.Status != model.StatusClosed {
+			return
+		}
+	}
+	title := t.Epic
+	if epic, _, err := s.Store.GetTask(ctx, t.Epic); err == nil {
+		title = epic.Title
+	}
+	s.notify(Message{
+		Event:   EventEpicCompleted,
+		Subject: fmt.Sprintf("Epic completed: %s", title),
+		Text:    fmt.Sprintf("All %d tasks in epic %s %s are closed.", len(tasks), t.Epic, title),
+		Task:    t.Epic,
+	})
+}
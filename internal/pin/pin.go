@@ -0,0 +1,96 @@
+// Package pin keeps the tasks and documents pinned on this machine in the
+// data directory: a personal working set that sits at the top of lists
+// without changing anything on the entity or its store.
+package pin
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const FileName = "pins.yaml"
+
+type Pin struct {
+	ID string    `yaml:"id"`
+	At time.Time `yaml:"at"`
+}
+
+// Load reads the pins in dataDir, oldest first. A missing file is an empty
+// list.
+func Load(dataDir string) ([]Pin, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, FileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ps []Pin
+	if err := yaml.Unmarshal(data, &ps); err != nil {
+		return nil, err
+	}
+	sortPins(ps)
+	return ps, nil
+}
+
+// Save writes ps to dataDir, removing the file when ps is empty.
+func Save(dataDir string, ps []Pin) error {
+	path := filepath.Join(dataDir, FileName)
+	if len(ps) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	sortPins(ps)
+	data, err := yaml.Marshal(ps)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Add pins id at at, unless it's already pinned, and reports whether it
+// was added.
+func Add(ps []Pin, id string, at time.Time) ([]Pin, bool) {
+	if slices.ContainsFunc(ps, func(p Pin) bool { return p.ID == id }) {
+		return ps, false
+	}
+	return append(ps, Pin{ID: id, At: at}), true
+}
+
+// Remove unpins id and reports whether it was pinned.
+func Remove(ps []Pin, id string) ([]Pin, bool) {
+	n := len(ps)
+	ps = slices.DeleteFunc(ps, func(p Pin) bool { return p.ID == id })
+	return ps, len(ps) < n
+}
+
+// Set returns the pinned IDs for lookups.
+func Set(ps []Pin) map[string]bool {
+	set := make(map[string]bool, len(ps))
+	for _, p := range ps {
+		set[p.ID] = true
+	}
+	return set
+}
+
+// First moves the items of list whose ID is pinned to the front, keeping
+// the order within both groups.
+func First[T any](list []T, pinned map[string]bool, id func(*T) string) {
+	if len(pinned) == 0 {
+		return
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return pinned[id(&list[i])] && !pinned[id(&list[j])]
+	})
+}
+
+func sortPins(ps []Pin) {
+	sort.SliceStable(ps, func(i, j int) bool { return ps[i].At.Before(ps[j].At) })
+}
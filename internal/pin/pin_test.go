@@ -0,0 +1,49 @@
+package pin
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+
+	ps, added := Add(nil, "AUTH-TBBBBB", now.Add(time.Hour))
+	assert.True(t, added)
+	ps, _ = Add(ps, "AUTH-DAAAAA", now)
+	ps, added = Add(ps, "AUTH-TBBBBB", now.Add(2*time.Hour))
+	assert.False(t, added, "already pinned")
+	require.NoError(t, Save(dir, ps))
+
+	got, err := Load(dir)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "AUTH-DAAAAA", got[0].ID)
+	assert.True(t, got[1].At.Equal(now.Add(time.Hour)))
+
+	got, removed := Remove(got, "AUTH-TBBBBB")
+	assert.True(t, removed)
+	_, removed = Remove(got, "AUTH-TCCCCC")
+	assert.False(t, removed)
+	got, _ = Remove(got, "AUTH-DAAAAA")
+	require.NoError(t, Save(dir, got))
+
+	got, err = Load(dir)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	assert.NoFileExists(t, filepath.Join(dir, FileName))
+}
+
+func TestFirst(t *testing.T) {
+	ids := []string{"A", "B", "C", "D"}
+	First(ids, Set([]Pin{{ID: "C"}, {ID: "D"}}), func(s *string) string { return *s })
+	assert.Equal(t, []string{"C", "D", "A", "B"}, ids)
+
+	First(ids, nil, func(s *string) string { return *s })
+	assert.Equal(t, []string{"C", "D", "A", "B"}, ids)
+}
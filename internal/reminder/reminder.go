@@ -0,0 +1,96 @@
+// Package reminder keeps personal task reminders in the data directory.
+// Reminders are local to the machine rather than stored on the task, so
+// they work the same for every store and never notify teammates.
+package reminder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/rogersnm/compass/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+const FileName = "reminders.yaml"
+
+type Reminder struct {
+	Task string    `yaml:"task"`
+	At   time.Time `yaml:"at"`
+	Note string    `yaml:"note,omitempty"`
+}
+
+// Load reads the reminders in dataDir, soonest first. A missing file is an
+// empty list.
+func Load(dataDir string) ([]Reminder, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, FileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var rs []Reminder
+	if err := yaml.Unmarshal(data, &rs); err != nil {
+		return nil, err
+	}
+	sortReminders(rs)
+	return rs, nil
+}
+
+// Save writes rs to dataDir, removing the file when rs is empty.
+func Save(dataDir string, rs []Reminder) error {
+	path := filepath.Join(dataDir, FileName)
+	if len(rs) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	sortReminders(rs)
+	data, err := yaml.Marshal(rs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Set replaces any reminder for r.Task with r. A task has at most one
+// reminder, so setting it again snoozes it.
+func Set(rs []Reminder, r Reminder) []Reminder {
+	return append(Clear(rs, r.Task), r)
+}
+
+// Clear drops the reminder for taskID, if any.
+func Clear(rs []Reminder, taskID string) []Reminder {
+	return slices.DeleteFunc(rs, func(r Reminder) bool { return r.Task == taskID })
+}
+
+// Due splits rs into reminders at or before now and the rest.
+func Due(rs []Reminder, now time.Time) (due, pending []Reminder) {
+	for _, r := range rs {
+		if r.At.After(now) {
+			pending = append(pending, r)
+		} else {
+			due = append(due, r)
+		}
+	}
+	return due, pending
+}
+
+// ParseAt reads a reminder time as model.ParseWhen does, with a bare date
+// meaning 09:00 that day and a duration such as "2h" or "3d" counted from
+// now.
+func ParseAt(s string, now time.Time) (time.Time, error) {
+	if t, ok := model.ParseWhen(s, now, 9*time.Hour, false); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use YYYY-MM-DDTHH:MM, YYYY-MM-DD or a duration like 2h or 3d", s)
+}
+
+func sortReminders(rs []Reminder) {
+	sort.SliceStable(rs, func(i, j int) bool { return rs[i].At.Before(rs[j].At) })
+}
@@ -0,0 +1,53 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+
+	rs := Set(nil, Reminder{Task: "AUTH-TAAAAA", At: now.Add(time.Hour)})
+	rs = Set(rs, Reminder{Task: "AUTH-TBBBBB", At: now.Add(-time.Hour), Note: "ping vendor"})
+	rs = Set(rs, Reminder{Task: "AUTH-TAAAAA", At: now.Add(2 * time.Hour)}) // snooze
+	require.NoError(t, Save(dir, rs))
+
+	got, err := Load(dir)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "AUTH-TBBBBB", got[0].Task)
+	assert.True(t, got[1].At.Equal(now.Add(2*time.Hour)))
+
+	due, pending := Due(got, now)
+	require.Len(t, due, 1)
+	assert.Equal(t, "ping vendor", due[0].Note)
+	require.NoError(t, Save(dir, Clear(pending, "AUTH-TAAAAA")))
+
+	got, err = Load(dir)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestParseAt(t *testing.T) {
+	now := time.Date(2026, 2, 3, 9, 30, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"2026-02-04T10:15":          time.Date(2026, 2, 4, 10, 15, 0, 0, time.UTC),
+		"2026-02-04 10:15":          time.Date(2026, 2, 4, 10, 15, 0, 0, time.UTC),
+		"2026-02-04":                time.Date(2026, 2, 4, 9, 0, 0, 0, time.UTC),
+		"2026-02-04T10:15:00+01:00": time.Date(2026, 2, 4, 9, 15, 0, 0, time.UTC),
+		"2h":                        time.Date(2026, 2, 3, 11, 30, 0, 0, time.UTC),
+		"2d":                        time.Date(2026, 2, 5, 9, 30, 0, 0, time.UTC),
+	}
+	for in, want := range cases {
+		got, err := ParseAt(in, now)
+		require.NoError(t, err, in)
+		assert.True(t, want.Equal(got), "%s: got %s", in, got)
+	}
+	_, err := ParseAt("tomorrow-ish", now)
+	assert.Error(t, err)
+}
@@ -1,19 +1,42 @@
 package repofile
 
 import (
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 const FileName = ".compass-project"
 
-// Find walks up from startDir looking for a .compass-project file.
+// MapFileName is a monorepo's mapping of subdirectories to projects, a
+// YAML map of slash-separated path prefixes (relative to the file) to
+// project keys:
+//
+//	services/auth: AUTH
+//	services/billing: BILL
+const MapFileName = ".compass-projects"
+
+// Find walks up from startDir looking for a .compass-project file, or a
+// .compass-projects file with a prefix covering startDir. Within one
+// directory a matching prefix wins over .compass-project.
 // Returns the project ID and the directory containing the file.
 // Returns ("", "", nil) if not found.
 func Find(startDir string) (projectID, dir string, err error) {
 	dir = startDir
 	for {
+		m, err := ReadMap(dir)
+		if err != nil {
+			return "", "", err
+		}
+		if rel, err := filepath.Rel(dir, startDir); err == nil {
+			if id := matchPrefix(m, filepath.ToSlash(rel)); id != "" {
+				return id, dir, nil
+			}
+		}
 		id, err := Read(dir)
 		if err != nil {
 			return "", "", err
@@ -46,3 +69,102 @@ func Read(dir string) (string, error) {
 	}
 	return strings.TrimSpace(string(data)), nil
 }
+
+// ReadMap reads dir/.compass-projects. Returns (nil, nil) if the file does
+// not exist.
+func ReadMap(dir string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, MapFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var m map[string]string
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, MapFileName), err)
+	}
+	return m, nil
+}
+
+// WriteMap writes m to dir/.compass-projects, removing the file when m is
+// empty.
+func WriteMap(dir string, m map[string]string) error {
+	path := filepath.Join(dir, MapFileName)
+	if len(m) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// CleanPrefix normalizes a mapping prefix to the slash-separated form
+// stored in .compass-projects. Prefixes must stay inside the directory.
+func CleanPrefix(prefix string) (string, error) {
+	p := path.Clean(filepath.ToSlash(prefix))
+	if path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("path %q must be relative and inside the current directory", prefix)
+	}
+	return p, nil
+}
+
+// matchPrefix returns the project of the longest prefix in m covering rel,
+// matching whole path components.
+func matchPrefix(m map[string]string, rel string) string {
+	best, bestLen := "", -1
+	for prefix, id := range m {
+		p, err := CleanPrefix(prefix)
+		if err != nil {
+			continue
+		}
+		n := len(p)
+		if p == "." {
+			n = 0
+		} else if rel != p && !strings.HasPrefix(rel, p+"/") {
+			continue
+		}
+		if n > bestLen {
+			best, bestLen = strings.TrimSpace(id), n
+		}
+	}
+	return best
+}
+
+// Rekey points the links in dir (.compass-project and .compass-projects
+// entries) at newKey where they name oldKey, and reports the files it
+// changed.
+func Rekey(dir, oldKey, newKey string) ([]string, error) {
+	var changed []string
+	if id, err := Read(dir); err != nil {
+		return nil, err
+	} else if id == oldKey {
+		if err := Write(dir, newKey); err != nil {
+			return nil, err
+		}
+		changed = append(changed, filepath.Join(dir, FileName))
+	}
+	m, err := ReadMap(dir)
+	if err != nil {
+		return changed, err
+	}
+	n := 0
+	for prefix, id := range m {
+		if id == oldKey {
+			m[prefix] = newKey
+			n++
+		}
+	}
+	if n > 0 {
+		if err := WriteMap(dir, m); err != nil {
+			return changed, err
+		}
+		changed = append(changed, filepath.Join(dir, MapFileName))
+	}
+	return changed, nil
+}
@@ -63,3 +63,57 @@ func TestFind_NotFound(t *testing.T) {
 	assert.Empty(t, id)
 	assert.Empty(t, foundDir)
 }
+
+func TestFind_MapFile(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, WriteMap(root, map[string]string{
+		"services/auth":    "AUTH",
+		"services/billing": "BILL",
+		".":                "MONO",
+	}))
+	for _, d := range []string{"services/auth/api", "services/billing", "services/authz", "docs"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, d), 0755))
+	}
+
+	cases := map[string]string{
+		"services/auth/api": "AUTH",
+		"services/billing":  "BILL",
+		"services/authz":    "MONO",
+		"docs":              "MONO",
+		".":                 "MONO",
+	}
+	for sub, want := range cases {
+		id, foundDir, err := Find(filepath.Join(root, sub))
+		require.NoError(t, err)
+		assert.Equal(t, want, id, sub)
+		assert.Equal(t, root, foundDir)
+	}
+
+	// A link in a subdirectory is nearer than the root mapping.
+	require.NoError(t, Write(filepath.Join(root, "docs"), "DOCS"))
+	id, _, err := Find(filepath.Join(root, "docs"))
+	require.NoError(t, err)
+	assert.Equal(t, "DOCS", id)
+}
+
+func TestRekey(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, Write(dir, "AUTH"))
+	require.NoError(t, WriteMap(dir, map[string]string{"auth": "AUTH", "billing": "BILL"}))
+
+	changed, err := Rekey(dir, "AUTH", "IAM")
+	require.NoError(t, err)
+	assert.Len(t, changed, 2)
+	id, _ := Read(dir)
+	assert.Equal(t, "IAM", id)
+	m, _ := ReadMap(dir)
+	assert.Equal(t, map[string]string{"auth": "IAM", "billing": "BILL"}, m)
+}
+
+func TestCleanPrefix(t *testing.T) {
+	p, err := CleanPrefix("services/auth/")
+	require.NoError(t, err)
+	assert.Equal(t, "services/auth", p)
+	_, err = CleanPrefix("../other")
+	assert.Error(t, err)
+}
@@ -0,0 +1,696 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rogersnm/compass/internal/model"
+	"github.com/rogersnm/compass/internal/store"
+)
+
+type method func(ctx context.Context, s *Server, params json.RawMessage) (any, error)
+
+// methods mirrors the Store interface. Methods taking an entity ID route to
+// the entity's store; methods taking a project route to the project's store.
+var methods = map[string]method{
+	"CreateProject": createProject,
+	"GetProject":    getProject,
+	"ListProjects":  listProjects,
+	"UpdateProject": updateProject,
+	"DeleteProject": deleteProject,
+	"RekeyProject":  rekeyProject,
+
+	"CreateTask":    createTask,
+	"GetTask":       getTask,
+	"ListTasks":     listTasks,
+	"ListTasksPage": listTasksPage,
+	"UpdateTask":    updateTask,
+	"DeleteTask":    deleteTask,
+	"MoveTask":      moveTask,
+	"ReadyTasks":    readyTasks,
+	"ClaimTask":     claimTask,
+
+	"CreateDocument":    createDocument,
+	"GetDocument":       getDocument,
+	"ListDocuments":     listDocuments,
+	"ListDocumentsPage": listDocumentsPage,
+	"UpdateDocument":    updateDocument,
+	"DeleteDocument":    deleteDocument,
+
+	"CreateRelease": createRelease,
+	"GetRelease":    getRelease,
+	"ListReleases":  listReleases,
+	"UpdateRelease": updateRelease,
+
+	"Search": search,
+}
+
+type idParams struct {
+	ID string `json:"id"`
+}
+
+type projectParams struct {
+	Project string `json:"project"`
+}
+
+// pageParams are the paging params of the *Page methods, as in PageOpts.
+type pageParams struct {
+	Sort   string `json:"sort"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Cursor string `json:"cursor"`
+}
+
+func (p pageParams) opts() store.PageOpts {
+	return store.PageOpts{Sort: p.Sort, Limit: p.Limit, Offset: p.Offset, Cursor: p.Cursor}
+}
+
+// byID decodes {"id": ...} and resolves the entity's store.
+func (s *Server) byID(ctx context.Context, raw json.RawMessage) (store.Store, string, error) {
+	p, err := decode[idParams](raw)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := requireParam("id", p.ID); err != nil {
+		return nil, "", err
+	}
+	st, _, err := s.reg.ForEntity(ctx, p.ID)
+	return st, p.ID, err
+}
+
+// byProject decodes {"project": ...} and resolves the project's store.
+func (s *Server) byProject(ctx context.Context, raw json.RawMessage) (store.Store, string, error) {
+	p, err := decode[projectParams](raw)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := requireParam("project", p.Project); err != nil {
+		return nil, "", err
+	}
+	st, _, err := s.reg.ForProject(ctx, p.Project)
+	return st, p.Project, err
+}
+
+// optional decodes a field that distinguishes "absent" (nil), "null"
+// (clear), and a value, matching the double-pointer fields of TaskUpdate.
+func optional[T any](raw json.RawMessage) (**T, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var v *T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, paramsError{"invalid params: " + err.Error()}
+	}
+	return &v, nil
+}
+
+// --- Projects ---
+
+func createProject(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		Name  string `json:"name"`
+		Key   string `json:"key"`
+		Body  string `json:"body"`
+		Store string `json:"store"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("name", p.Name); err != nil {
+		return nil, err
+	}
+	var st store.Store
+	storeName := p.Store
+	if storeName != "" {
+		st, err = s.reg.Get(storeName)
+	} else {
+		st, storeName, err = s.reg.Default()
+	}
+	if err != nil {
+		return nil, err
+	}
+	proj, err := st.CreateProject(ctx, p.Name, p.Key, p.Body)
+	if err != nil {
+		return nil, err
+	}
+	s.reg.CacheProject(proj.ID, storeName)
+	return proj, nil
+}
+
+func getProject(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[idParams](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("id", p.ID); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForProject(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	proj, body, err := st.GetProject(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"project": proj, "body": body}, nil
+}
+
+func listProjects(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		Store string `json:"store"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	byStore, _, err := store.FanOut(ctx, s.reg, p.Store, store.FanOutTimeout, func(ctx context.Context, st store.Store) ([]model.Project, error) {
+		return st.ListProjects(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	projects := []model.Project{}
+	for _, ps := range byStore {
+		projects = append(projects, ps...)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects, nil
+}
+
+func updateProject(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		ID             string  `json:"id"`
+		Name           *string `json:"name"`
+		AutoCloseEpics *bool   `json:"auto_close_epics"`
+		Body           *string `json:"body"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("id", p.ID); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForProject(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return st.UpdateProject(ctx, p.ID, store.ProjectUpdate{Name: p.Name, AutoCloseEpics: p.AutoCloseEpics, Body: p.Body})
+}
+
+func deleteProject(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[idParams](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("id", p.ID); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForProject(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.DeleteProject(ctx, p.ID); err != nil {
+		return nil, err
+	}
+	s.reg.UncacheProject(p.ID)
+	return map[string]any{"deleted": p.ID}, nil
+}
+
+func rekeyProject(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("id", p.ID); err != nil {
+		return nil, err
+	}
+	if err := requireParam("key", p.Key); err != nil {
+		return nil, err
+	}
+	st, storeName, err := s.reg.ForProject(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	proj, err := st.RekeyProject(ctx, p.ID, p.Key)
+	if err != nil {
+		return nil, err
+	}
+	s.reg.UncacheProject(p.ID)
+	s.reg.CacheProject(proj.ID, storeName)
+	return proj, nil
+}
+
+// --- Tasks ---
+
+func createTask(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		Title     string         `json:"title"`
+		Project   string         `json:"project"`
+		Type      model.TaskType `json:"type"`
+		Epic      string         `json:"epic"`
+		Priority  *int           `json:"priority"`
+		DependsOn []string       `json:"depends_on"`
+		Body      string         `json:"body"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("title", p.Title); err != nil {
+		return nil, err
+	}
+	if err := requireParam("project", p.Project); err != nil {
+		return nil, err
+	}
+	if p.Type == "" {
+		p.Type = model.TypeTask
+	}
+	st, _, err := s.reg.ForProject(ctx, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	return st.CreateTask(ctx, p.Title, p.Project, store.TaskCreateOpts{
+		Type:      p.Type,
+		Epic:      p.Epic,
+		Priority:  p.Priority,
+		DependsOn: p.DependsOn,
+		Body:      p.Body,
+	})
+}
+
+func getTask(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	st, id, err := s.byID(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	t, body, err := st.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"task": t, "body": body}, nil
+}
+
+func listTasks(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		Project string         `json:"project"`
+		Epic    string         `json:"epic"`
+		Status  model.Status   `json:"status"`
+		Type    model.TaskType `json:"type"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("project", p.Project); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForProject(ctx, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := st.ListTasks(ctx, store.TaskFilter{ProjectID: p.Project, EpicID: p.Epic, Status: p.Status, Type: p.Type})
+	if tasks == nil && err == nil {
+		tasks = []model.Task{}
+	}
+	return tasks, err
+}
+
+func listTasksPage(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		Project string         `json:"project"`
+		Epic    string         `json:"epic"`
+		Status  model.Status   `json:"status"`
+		Type    model.TaskType `json:"type"`
+		pageParams
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("project", p.Project); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForProject(ctx, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	tasks, next, err := st.ListTasksPage(ctx, store.TaskFilter{ProjectID: p.Project, EpicID: p.Epic, Status: p.Status, Type: p.Type}, p.opts())
+	if err != nil {
+		return nil, err
+	}
+	if tasks == nil {
+		tasks = []model.Task{}
+	}
+	return map[string]any{"tasks": tasks, "next_cursor": next}, nil
+}
+
+func updateTask(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		ID        string          `json:"id"`
+		Title     *string         `json:"title"`
+		Status    *model.Status   `json:"status"`
+		Priority  json.RawMessage `json:"priority"` // null clears
+		Epic      *string         `json:"epic"`
+		DependsOn *[]string       `json:"depends_on"`
+		Waiting   json.RawMessage `json:"waiting"` // null clears
+		AutoClose *bool           `json:"auto_close"`
+		Body      *string         `json:"body"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("id", p.ID); err != nil {
+		return nil, err
+	}
+	upd := store.TaskUpdate{Title: p.Title, Status: p.Status, Epic: p.Epic, DependsOn: p.DependsOn, AutoClose: p.AutoClose, Body: p.Body}
+	if upd.Priority, err = optional[int](p.Priority); err != nil {
+		return nil, err
+	}
+	if upd.Waiting, err = optional[model.WaitingOn](p.Waiting); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForEntity(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return st.UpdateTask(ctx, p.ID, upd)
+}
+
+func deleteTask(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	st, id, err := s.byID(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.DeleteTask(ctx, id); err != nil {
+		return nil, err
+	}
+	return map[string]any{"deleted": id}, nil
+}
+
+// moveTask moves a task within its store; moving between stores recreates
+// the task, which only the CLI's task move does.
+func moveTask(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		ID      string `json:"id"`
+		Project string `json:"project"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("id", p.ID); err != nil {
+		return nil, err
+	}
+	if err := requireParam("project", p.Project); err != nil {
+		return nil, err
+	}
+	st, srcName, err := s.reg.ForEntity(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	_, dstName, err := s.reg.ForProject(ctx, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	if srcName != dstName {
+		return nil, fmt.Errorf("%s is on %s and %s on %s; move it between stores with: compass task move %s --to-project %s",
+			p.ID, srcName, p.Project, dstName, p.ID, p.Project)
+	}
+	return st.MoveTask(ctx, p.ID, p.Project)
+}
+
+func readyTasks(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	st, project, err := s.byProject(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := st.ReadyTasks(ctx, project)
+	if tasks == nil && err == nil {
+		tasks = []*model.Task{}
+	}
+	return tasks, err
+}
+
+func claimTask(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	st, project, err := s.byProject(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	t, err := st.ClaimTask(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	// A JSON null result means there was nothing to claim.
+	return map[string]any{"task": t}, nil
+}
+
+// --- Documents ---
+
+func createDocument(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		Title   string        `json:"title"`
+		Project string        `json:"project"`
+		Kind    model.DocKind `json:"kind"`
+		Body    string        `json:"body"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("title", p.Title); err != nil {
+		return nil, err
+	}
+	if err := requireParam("project", p.Project); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForProject(ctx, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	return st.CreateDocument(ctx, p.Title, p.Project, store.DocumentCreateOpts{Kind: p.Kind, Body: p.Body})
+}
+
+func getDocument(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	st, id, err := s.byID(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	d, body, err := st.GetDocument(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"document": d, "body": body}, nil
+}
+
+func listDocuments(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		Project   string        `json:"project"`
+		Kind      model.DocKind `json:"kind"`
+		CreatedBy string        `json:"created_by"`
+		Since     time.Time     `json:"since"`
+		Search    string        `json:"search"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("project", p.Project); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForProject(ctx, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := st.ListDocuments(ctx, store.DocumentFilter{ProjectID: p.Project, Kind: p.Kind, CreatedBy: p.CreatedBy, Since: p.Since, Search: p.Search})
+	if docs == nil && err == nil {
+		docs = []model.Document{}
+	}
+	return docs, err
+}
+
+func listDocumentsPage(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		Project   string        `json:"project"`
+		Kind      model.DocKind `json:"kind"`
+		CreatedBy string        `json:"created_by"`
+		Since     time.Time     `json:"since"`
+		Search    string        `json:"search"`
+		pageParams
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("project", p.Project); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForProject(ctx, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	docs, next, err := st.ListDocumentsPage(ctx, store.DocumentFilter{ProjectID: p.Project, Kind: p.Kind, CreatedBy: p.CreatedBy, Since: p.Since, Search: p.Search}, p.opts())
+	if err != nil {
+		return nil, err
+	}
+	if docs == nil {
+		docs = []model.Document{}
+	}
+	return map[string]any{"documents": docs, "next_cursor": next}, nil
+}
+
+func updateDocument(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		ID     string           `json:"id"`
+		Title  *string          `json:"title"`
+		Kind   *model.DocKind   `json:"kind"`
+		Status *model.ADRStatus `json:"status"`
+		Body   *string          `json:"body"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("id", p.ID); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForEntity(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return st.UpdateDocument(ctx, p.ID, store.DocumentUpdate{Title: p.Title, Kind: p.Kind, Status: p.Status, Body: p.Body})
+}
+
+func deleteDocument(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	st, id, err := s.byID(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.DeleteDocument(ctx, id); err != nil {
+		return nil, err
+	}
+	return map[string]any{"deleted": id}, nil
+}
+
+// --- Releases ---
+
+func createRelease(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		Version    string   `json:"version"`
+		Project    string   `json:"project"`
+		TargetDate string   `json:"target_date"`
+		Items      []string `json:"items"`
+		Body       string   `json:"body"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("version", p.Version); err != nil {
+		return nil, err
+	}
+	if err := requireParam("project", p.Project); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForProject(ctx, p.Project)
+	if err != nil {
+		return nil, err
+	}
+	return st.CreateRelease(ctx, p.Version, p.Project, store.ReleaseCreateOpts{
+		TargetDate: p.TargetDate,
+		Items:      p.Items,
+		Body:       p.Body,
+	})
+}
+
+func getRelease(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	st, id, err := s.byID(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	r, body, err := st.GetRelease(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"release": r, "body": body}, nil
+}
+
+func listReleases(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	st, project, err := s.byProject(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := st.ListReleases(ctx, project)
+	if releases == nil && err == nil {
+		releases = []model.Release{}
+	}
+	return releases, err
+}
+
+func updateRelease(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		ID         string               `json:"id"`
+		TargetDate *string              `json:"target_date"`
+		Items      *[]string            `json:"items"`
+		Status     *model.ReleaseStatus `json:"status"`
+		Body       *string              `json:"body"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("id", p.ID); err != nil {
+		return nil, err
+	}
+	st, _, err := s.reg.ForEntity(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return st.UpdateRelease(ctx, p.ID, store.ReleaseUpdate{
+		TargetDate: p.TargetDate,
+		Items:      p.Items,
+		Status:     p.Status,
+		Body:       p.Body,
+	})
+}
+
+// --- Search ---
+
+func search(ctx context.Context, s *Server, raw json.RawMessage) (any, error) {
+	p, err := decode[struct {
+		Query     string `json:"query"`
+		Project   string `json:"project"`
+		Store     string `json:"store"`
+		Regex     bool   `json:"regex"`
+		TitleOnly bool   `json:"title_only"`
+		BodyOnly  bool   `json:"body_only"`
+		Type      string `json:"type"`
+	}](raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireParam("query", p.Query); err != nil {
+		return nil, err
+	}
+	opts := store.SearchOpts{Regex: p.Regex, TitleOnly: p.TitleOnly, BodyOnly: p.BodyOnly, Type: p.Type}
+	results := []store.SearchResult{}
+	if p.Project != "" {
+		st, _, err := s.reg.ForProject(ctx, p.Project)
+		if err != nil {
+			return nil, err
+		}
+		opts.ProjectID = p.Project
+		sr, err := st.Search(ctx, p.Query, opts)
+		return append(results, sr...), err
+	}
+	byStore, _, err := store.FanOut(ctx, s.reg, p.Store, store.FanOutTimeout, func(ctx context.Context, st store.Store) ([]store.SearchResult, error) {
+		return st.Search(ctx, p.Query, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(byStore))
+	for name := range byStore {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		results = append(results, byStore[name]...)
+	}
+	return results, nil
+}
@@ -0,0 +1,136 @@
+// Package rpc serves store operations as newline-delimited JSON-RPC 2.0, so
+// editors and agents can keep one compass process warm instead of paying
+// startup and registry cost on every command.
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/rogersnm/compass/internal/store"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeServerError    = -32000
+)
+
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server dispatches requests to the stores in a registry. Entities are
+// routed the same way CLI commands route them.
+type Server struct {
+	reg *store.Registry
+}
+
+func NewServer(reg *store.Registry) *Server {
+	return &Server{reg: reg}
+}
+
+// Serve reads one request per line from r and writes one response per line
+// to w until r is exhausted. Notifications (requests without an id) are
+// executed but get no response. Requests run under ctx.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	enc := json.NewEncoder(w)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := enc.Encode(errorResponse(nil, CodeParseError, "parse error: "+err.Error())); err != nil {
+				return err
+			}
+			continue
+		}
+		resp := s.Handle(ctx, req)
+		if req.ID == nil {
+			continue
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// Handle executes a single request.
+func (s *Server) Handle(ctx context.Context, req Request) Response {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, CodeInvalidRequest, "invalid request")
+	}
+	m, ok := methods[req.Method]
+	if !ok {
+		return errorResponse(req.ID, CodeMethodNotFound, fmt.Sprintf("method %q not found", req.Method))
+	}
+	params := req.Params
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	result, err := m(ctx, s, params)
+	if err != nil {
+		if pe, ok := err.(paramsError); ok {
+			return errorResponse(req.ID, CodeInvalidParams, pe.Error())
+		}
+		return errorResponse(req.ID, CodeServerError, err.Error())
+	}
+	return Response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) Response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return Response{JSONRPC: "2.0", ID: id, Error: &Error{Code: code, Message: msg}}
+}
+
+// paramsError marks errors caused by malformed or missing parameters.
+type paramsError struct{ msg string }
+
+func (e paramsError) Error() string { return e.msg }
+
+// decode unmarshals params into a new T, rejecting unknown fields.
+func decode[T any](raw json.RawMessage) (T, error) {
+	var v T
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&v); err != nil {
+		return v, paramsError{"invalid params: " + err.Error()}
+	}
+	return v, nil
+}
+
+func requireParam(field, value string) error {
+	if value == "" {
+		return paramsError{fmt.Sprintf("invalid params: %q is required", field)}
+	}
+	return nil
+}
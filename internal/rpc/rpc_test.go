@@ -0,0 +1,212 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupServer(t *testing.T) (*Server, *store.LocalStore) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &config.Config{Version: 2, LocalEnabled: true, DefaultStore: "local", Projects: map[string]string{}}
+	reg := store.NewRegistry(cfg, dir)
+	ls := store.NewLocal(dir)
+	reg.Add("local", ls)
+	return NewServer(reg), ls
+}
+
+// call sends one request through Serve and decodes the single response.
+func call(t *testing.T, srv *Server, method string, params any) Response {
+	t.Helper()
+	p, err := json.Marshal(params)
+	require.NoError(t, err)
+	req, err := json.Marshal(Request{JSONRPC: "2.0", ID: json.RawMessage("1"), Method: method, Params: p})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, srv.Serve(t.Context(), bytes.NewReader(append(req, '\n')), &out))
+	var resp Response
+	require.NoError(t, json.Unmarshal(out.Bytes(), &resp))
+	return resp
+}
+
+func TestServe_TaskLifecycle(t *testing.T) {
+	srv, ls := setupServer(t)
+
+	resp := call(t, srv, "CreateProject", map[string]any{"name": "Demo", "key": "DM"})
+	require.Nil(t, resp.Error)
+
+	resp = call(t, srv, "CreateTask", map[string]any{"title": "First", "project": "DM", "priority": 1, "body": "details"})
+	require.Nil(t, resp.Error)
+	taskID := resp.Result.(map[string]any)["id"].(string)
+
+	resp = call(t, srv, "GetTask", map[string]any{"id": taskID})
+	require.Nil(t, resp.Error)
+	result := resp.Result.(map[string]any)
+	assert.Equal(t, "details", result["body"])
+	assert.Equal(t, "First", result["task"].(map[string]any)["title"])
+
+	// null clears the priority
+	resp = call(t, srv, "UpdateTask", map[string]any{"id": taskID, "title": "Renamed", "priority": nil})
+	require.Nil(t, resp.Error)
+	got, _, err := ls.GetTask(t.Context(), taskID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", got.Title)
+	assert.Nil(t, got.Priority)
+
+	resp = call(t, srv, "ListTasks", map[string]any{"project": "DM"})
+	require.Nil(t, resp.Error)
+	assert.Len(t, resp.Result, 1)
+
+	resp = call(t, srv, "ClaimTask", map[string]any{"project": "DM"})
+	require.Nil(t, resp.Error)
+	assert.Equal(t, taskID, resp.Result.(map[string]any)["task"].(map[string]any)["id"])
+
+	resp = call(t, srv, "Search", map[string]any{"query": "renamed"})
+	require.Nil(t, resp.Error)
+	assert.Len(t, resp.Result, 1)
+}
+
+func TestServe_ListPages(t *testing.T) {
+	srv, _ := setupServer(t)
+	resp := call(t, srv, "CreateProject", map[string]any{"name": "Demo", "key": "DM"})
+	require.Nil(t, resp.Error)
+	for _, title := range []string{"Charlie", "Alpha", "Bravo"} {
+		resp = call(t, srv, "CreateTask", map[string]any{"title": title, "project": "DM"})
+		require.Nil(t, resp.Error)
+		resp = call(t, srv, "CreateDocument", map[string]any{"title": title, "project": "DM"})
+		require.Nil(t, resp.Error)
+	}
+	titles := func(items any) []string {
+		var out []string
+		for _, it := range items.([]any) {
+			out = append(out, it.(map[string]any)["title"].(string))
+		}
+		return out
+	}
+
+	resp = call(t, srv, "ListTasksPage", map[string]any{"project": "DM", "sort": "title", "limit": 2})
+	require.Nil(t, resp.Error)
+	page := resp.Result.(map[string]any)
+	assert.Equal(t, []string{"Alpha", "Bravo"}, titles(page["tasks"]))
+	require.NotEmpty(t, page["next_cursor"])
+
+	resp = call(t, srv, "ListTasksPage", map[string]any{"project": "DM", "sort": "title", "limit": 2, "cursor": page["next_cursor"]})
+	require.Nil(t, resp.Error)
+	page = resp.Result.(map[string]any)
+	assert.Equal(t, []string{"Charlie"}, titles(page["tasks"]))
+	assert.Empty(t, page["next_cursor"])
+
+	resp = call(t, srv, "ListDocumentsPage", map[string]any{"project": "DM", "sort": "-title", "offset": 1})
+	require.Nil(t, resp.Error)
+	assert.Equal(t, []string{"Bravo", "Alpha"}, titles(resp.Result.(map[string]any)["documents"]))
+
+	resp = call(t, srv, "ListTasksPage", map[string]any{"project": "DM", "sort": "colour"})
+	require.NotNil(t, resp.Error)
+}
+
+func TestServe_MoveTask(t *testing.T) {
+	srv, ls := setupServer(t)
+	for _, key := range []string{"DM", "DX"} {
+		resp := call(t, srv, "CreateProject", map[string]any{"name": key, "key": key})
+		require.Nil(t, resp.Error)
+	}
+	resp := call(t, srv, "CreateTask", map[string]any{"title": "First", "project": "DM"})
+	require.Nil(t, resp.Error)
+	taskID := resp.Result.(map[string]any)["id"].(string)
+
+	resp = call(t, srv, "MoveTask", map[string]any{"id": taskID, "project": "DX"})
+	require.Nil(t, resp.Error)
+	moved := resp.Result.(map[string]any)
+	assert.Equal(t, "DX", moved["project"])
+	assert.Equal(t, "First", moved["title"])
+	_, _, err := ls.GetTask(t.Context(), taskID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, _, err = ls.GetTask(t.Context(), moved["id"].(string))
+	assert.NoError(t, err)
+
+	resp = call(t, srv, "MoveTask", map[string]any{"id": moved["id"]})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, CodeInvalidParams, resp.Error.Code)
+}
+
+func TestServe_ProjectChanges(t *testing.T) {
+	srv, ls := setupServer(t)
+	resp := call(t, srv, "CreateProject", map[string]any{"name": "Demo", "key": "DM"})
+	require.Nil(t, resp.Error)
+	resp = call(t, srv, "CreateTask", map[string]any{"title": "First", "project": "DM"})
+	require.Nil(t, resp.Error)
+
+	resp = call(t, srv, "UpdateProject", map[string]any{"id": "DM", "name": "Demos", "body": "All the demos."})
+	require.Nil(t, resp.Error)
+	assert.Equal(t, "Demos", resp.Result.(map[string]any)["name"])
+	proj, body, err := ls.GetProject(t.Context(), "DM")
+	require.NoError(t, err)
+	assert.Equal(t, "Demos", proj.Name)
+	assert.Contains(t, body, "All the demos.")
+
+	resp = call(t, srv, "RekeyProject", map[string]any{"id": "DM", "key": "DX"})
+	require.Nil(t, resp.Error)
+	assert.Equal(t, "DX", resp.Result.(map[string]any)["id"])
+	tasks, err := ls.ListTasks(t.Context(), store.TaskFilter{ProjectID: "DX"})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	resp = call(t, srv, "ListTasks", map[string]any{"project": "DX"})
+	require.Nil(t, resp.Error, "the new key is routed")
+	assert.Len(t, resp.Result, 1)
+
+	resp = call(t, srv, "RekeyProject", map[string]any{"id": "DX"})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, CodeInvalidParams, resp.Error.Code)
+}
+
+func TestServe_Errors(t *testing.T) {
+	srv, _ := setupServer(t)
+
+	resp := call(t, srv, "Nope", map[string]any{})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, CodeMethodNotFound, resp.Error.Code)
+
+	resp = call(t, srv, "GetTask", map[string]any{})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, CodeInvalidParams, resp.Error.Code)
+
+	resp = call(t, srv, "GetTask", map[string]any{"id": "DM-TAAAAA", "extra": true})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, CodeInvalidParams, resp.Error.Code)
+
+	resp = call(t, srv, "GetProject", map[string]any{"id": "NOPE"})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, CodeServerError, resp.Error.Code)
+}
+
+func TestServe_ParseErrorAndNotifications(t *testing.T) {
+	srv, ls := setupServer(t)
+	in := strings.Join([]string{
+		`not json`,
+		`{"jsonrpc":"2.0","method":"CreateProject","params":{"name":"Quiet","key":"QT"}}`,
+		`{"jsonrpc":"1.0","id":7,"method":"ListProjects"}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	require.NoError(t, srv.Serve(t.Context(), strings.NewReader(in), &out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2) // the notification gets no response
+
+	var resp Response
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &resp))
+	assert.Equal(t, CodeParseError, resp.Error.Code)
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &resp))
+	assert.Equal(t, CodeInvalidRequest, resp.Error.Code)
+	assert.Equal(t, "7", string(resp.ID))
+
+	_, _, err := ls.GetProject(t.Context(), "QT")
+	assert.NoError(t, err)
+}
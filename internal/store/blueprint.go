@@ -0,0 +1,226 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// BlueprintVersion is the current blueprint file format version.
+const BlueprintVersion = 1
+
+// Blueprint is a shareable project skeleton: the project description and
+// settings, its epics and task skeletons (without status or authorship), and
+// its documents.
+// Tasks reference each other by Ref, which is the task's ID in the source
+// project; fresh IDs are assigned when the blueprint is applied.
+type Blueprint struct {
+	Version int    `yaml:"version"`
+	Name    string `yaml:"name"`
+	Body    string `yaml:"body,omitempty"`
+	// Project settings, as in project.md.
+	WIPLimits      map[model.Status]int `yaml:"wip_limits,omitempty"`
+	AutoCloseEpics bool                 `yaml:"auto_close_epics,omitempty"`
+	IDLength       int                  `yaml:"id_length,omitempty"`
+	Tasks          []BlueprintTask      `yaml:"tasks,omitempty"`
+	Documents      []BlueprintDocument  `yaml:"documents,omitempty"`
+}
+
+type BlueprintTask struct {
+	Ref       string         `yaml:"ref"`
+	Title     string         `yaml:"title"`
+	Type      model.TaskType `yaml:"type"`
+	Epic      string         `yaml:"epic,omitempty"`
+	Priority  *int           `yaml:"priority,omitempty"`
+	DependsOn []string       `yaml:"depends_on,omitempty"`
+	Body      string         `yaml:"body,omitempty"`
+}
+
+type BlueprintDocument struct {
+	Title string        `yaml:"title"`
+	Kind  model.DocKind `yaml:"kind,omitempty"`
+	Body  string        `yaml:"body,omitempty"`
+}
+
+// ExportBlueprint captures a project as a blueprint.
+func ExportBlueprint(ctx context.Context, s Store, projectID string) (*Blueprint, error) {
+	p, body, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	bp := &Blueprint{
+		Version:        BlueprintVersion,
+		Name:           p.Name,
+		Body:           body,
+		WIPLimits:      p.WIPLimits,
+		AutoCloseEpics: p.AutoCloseEpics,
+		IDLength:       p.IDLength,
+	}
+
+	tasks, err := s.ListTasks(ctx, TaskFilter{ProjectID: projectID})
+	if err != nil {
+		return nil, err
+	}
+	// Epics first so the file reads top-down, then creation order.
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if (tasks[i].Type == model.TypeEpic) != (tasks[j].Type == model.TypeEpic) {
+			return tasks[i].Type == model.TypeEpic
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+	for _, t := range tasks {
+		_, tbody, err := s.GetTask(ctx, t.ID)
+		if err != nil {
+			return nil, err
+		}
+		bp.Tasks = append(bp.Tasks, BlueprintTask{
+			Ref:       t.ID,
+			Title:     t.Title,
+			Type:      t.Type,
+			Epic:      t.Epic,
+			Priority:  t.Priority,
+			DependsOn: t.DependsOn,
+			Body:      tbody,
+		})
+	}
+
+	docs, err := s.ListDocuments(ctx, DocumentFilter{ProjectID: projectID})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].CreatedAt.Before(docs[j].CreatedAt)
+	})
+	for _, d := range docs {
+		_, dbody, err := s.GetDocument(ctx, d.ID)
+		if err != nil {
+			return nil, err
+		}
+		bp.Documents = append(bp.Documents, BlueprintDocument{Title: d.Title, Kind: d.Kind, Body: dbody})
+	}
+	return bp, nil
+}
+
+// ApplyBlueprint creates a new project from a blueprint. name overrides the
+// blueprint's project name when non-empty; key may be empty to auto-generate.
+// If any entity fails to create, the new project is deleted.
+func ApplyBlueprint(ctx context.Context, s Store, bp *Blueprint, name, key string) (*model.Project, error) {
+	if bp.Version > BlueprintVersion {
+		return nil, invalidf("blueprint version %d is newer than supported version %d", bp.Version, BlueprintVersion)
+	}
+	order, err := bp.taskOrder()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = bp.Name
+	}
+
+	p, err := s.CreateProject(ctx, name, key, bp.Body)
+	if err != nil {
+		return nil, err
+	}
+	fail := func(cause error) (*model.Project, error) {
+		if err := s.DeleteProject(ctx, p.ID); err != nil {
+			return nil, fmt.Errorf("%w (rollback failed, remove project %s manually: %v)", cause, p.ID, err)
+		}
+		return nil, cause
+	}
+	// Settings go on first, so id_length applies to the IDs created below.
+	if len(bp.WIPLimits) > 0 || bp.AutoCloseEpics || bp.IDLength != 0 {
+		upd := ProjectUpdate{AutoCloseEpics: &bp.AutoCloseEpics, IDLength: &bp.IDLength}
+		if len(bp.WIPLimits) > 0 {
+			upd.WIPLimits = &bp.WIPLimits
+		}
+		updated, err := s.UpdateProject(ctx, p.ID, upd)
+		if err != nil {
+			return fail(fmt.Errorf("applying project settings: %w", err))
+		}
+		p = updated
+	}
+
+	ids := make(map[string]string, len(bp.Tasks))
+	for _, bt := range order {
+		opts := TaskCreateOpts{Type: bt.Type, Priority: bt.Priority, Body: bt.Body}
+		if bt.Epic != "" {
+			opts.Epic = ids[bt.Epic]
+		}
+		for _, dep := range bt.DependsOn {
+			opts.DependsOn = append(opts.DependsOn, ids[dep])
+		}
+		t, err := s.CreateTask(ctx, bt.Title, p.ID, opts)
+		if err != nil {
+			return fail(fmt.Errorf("creating task %q: %w", bt.Title, err))
+		}
+		ids[bt.Ref] = t.ID
+	}
+
+	for _, bd := range bp.Documents {
+		if _, err := s.CreateDocument(ctx, bd.Title, p.ID, DocumentCreateOpts{Kind: bd.Kind, Body: bd.Body}); err != nil {
+			return fail(fmt.Errorf("creating document %q: %w", bd.Title, err))
+		}
+	}
+	return p, nil
+}
+
+// taskOrder validates task references and returns tasks in creation order:
+// epics first, then tasks with dependencies before their dependents.
+func (bp *Blueprint) taskOrder() ([]BlueprintTask, error) {
+	byRef := make(map[string]BlueprintTask, len(bp.Tasks))
+	for _, bt := range bp.Tasks {
+		if bt.Ref == "" {
+			return nil, invalidf("blueprint task %q has no ref", bt.Title)
+		}
+		if _, dup := byRef[bt.Ref]; dup {
+			return nil, invalidf("duplicate blueprint task ref %q", bt.Ref)
+		}
+		byRef[bt.Ref] = bt
+	}
+
+	var order, pending []BlueprintTask
+	for _, bt := range bp.Tasks {
+		if bt.Epic != "" {
+			if e, ok := byRef[bt.Epic]; !ok || e.Type != model.TypeEpic {
+				return nil, invalidf("task %q: epic %q is not an epic in the blueprint", bt.Title, bt.Epic)
+			}
+		}
+		for _, dep := range bt.DependsOn {
+			if _, ok := byRef[dep]; !ok {
+				return nil, invalidf("task %q: dependency %q not in the blueprint", bt.Title, dep)
+			}
+		}
+		if bt.Type == model.TypeEpic {
+			order = append(order, bt)
+		} else {
+			pending = append(pending, bt)
+		}
+	}
+
+	// Place tasks in file order as soon as their dependencies are placed.
+	placed := make(map[string]bool, len(bp.Tasks))
+	for len(pending) > 0 {
+		var next []BlueprintTask
+		for _, bt := range pending {
+			ready := true
+			for _, dep := range bt.DependsOn {
+				if !placed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				order = append(order, bt)
+				placed[bt.Ref] = true
+			} else {
+				next = append(next, bt)
+			}
+		}
+		if len(next) == len(pending) {
+			return nil, invalidf("blueprint task dependencies contain a cycle (involving %q)", next[0].Title)
+		}
+		pending = next
+	}
+	return order, nil
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// entityCache memoizes parsed entity files by path. An entry is used only
+// while the file's modification time and size still match, so a long-lived
+// process re-parses just the files that changed since it last read them.
+type entityCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	mod  time.Time
+	size int64
+	meta any
+	body string
+}
+
+// EnableCache keeps parsed entities in memory for the store's reads. Only
+// long-running processes such as "compass daemon" benefit; every read still
+// stats the file, so the cache is never staler than the disk.
+func (s *LocalStore) EnableCache() {
+	s.cache = &entityCache{entries: map[string]cacheEntry{}}
+}
+
+// readEntity reads an entity through the store's cache, if enabled.
+// Read-modify-write paths use ReadEntity directly.
+func readEntity[T any](s *LocalStore, path string) (T, string, error) {
+	if s.cache == nil {
+		return ReadEntity[T](path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ReadEntity[T](path)
+	}
+
+	c := s.cache
+	c.mu.Lock()
+	e, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && e.mod.Equal(info.ModTime()) && e.size == info.Size() {
+		if v, ok := e.meta.(T); ok {
+			return cloneEntity(v), e.body, nil
+		}
+	}
+
+	v, body, err := ReadEntity[T](path)
+	if err != nil {
+		return v, body, err
+	}
+	c.mu.Lock()
+	c.entries[path] = cacheEntry{mod: info.ModTime(), size: info.Size(), meta: cloneEntity(v), body: body}
+	c.mu.Unlock()
+	return v, body, nil
+}
+
+// cloneEntity copies the slices of an entity so callers can't modify a
+// cached value through the one they were handed.
+func cloneEntity[T any](v T) T {
+	switch e := any(&v).(type) {
+	case *model.Task:
+		e.DependsOn = slices.Clone(e.DependsOn)
+		e.History = slices.Clone(e.History)
+		e.Watchers = slices.Clone(e.Watchers)
+	case *model.Release:
+		e.Items = slices.Clone(e.Items)
+	}
+	return v
+}
+
+// WarmCache reads every project, task, document and release so the cache
+// holds them, and forgets files that no longer exist. It is a no-op when the
+// cache is disabled.
+func (s *LocalStore) WarmCache(ctx context.Context) error {
+	if s.cache == nil {
+		return nil
+	}
+	projects, err := s.ListProjects(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range projects {
+		if _, err := s.ListTasks(ctx, TaskFilter{ProjectID: p.ID}); err != nil {
+			return err
+		}
+		if _, err := s.ListDocuments(ctx, DocumentFilter{ProjectID: p.ID}); err != nil {
+			return err
+		}
+		if _, err := s.ListReleases(ctx, p.ID); err != nil {
+			return err
+		}
+	}
+
+	c := s.cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path := range c.entries {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			delete(c.entries, path)
+		}
+	}
+	return nil
+}
@@ -2,16 +2,24 @@ package store
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/id"
 	"github.com/rogersnm/compass/internal/markdown"
 	"github.com/rogersnm/compass/internal/model"
 )
@@ -20,9 +28,19 @@ const CloudAPIBase = "https://compasscloud.io/api/v1"
 
 // CloudStore implements Store using the compass-cloud HTTP API.
 type CloudStore struct {
+	name    string // configured store name, used in re-login hints
 	apiBase string
 	apiKey  string
+	org     string // org slug scoping every request, if set
 	client  *http.Client
+	limit   rateLimit
+	trace   io.Writer       // where requests are logged, if set
+	deps    model.DepPolicy // how ReadyTasks treats missing dependencies
+
+	// notice and sleep replace the rate limit message on stderr and the
+	// wait, in tests.
+	notice func(msg string)
+	sleep  func(time.Duration)
 }
 
 // compile-time check
@@ -49,9 +67,73 @@ func NewCloudStoreWithBase(apiBase, apiKey string) *CloudStore {
 	}
 }
 
+// NewCloudStoreFromConfig creates a CloudStore for the store configured as
+// name, applying its proxy and TLS settings.
+func NewCloudStoreFromConfig(name string, sc config.CloudStoreConfig) (*CloudStore, error) {
+	client, err := NewHTTPClient(sc)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := sc.ResolveAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	cs := NewCloudStoreWithBase(sc.URL(), apiKey)
+	cs.name = name
+	cs.org = sc.Org
+	cs.client = client
+	return cs, nil
+}
+
+// NewHTTPClient builds an http.Client honouring a store's proxy, CA bundle,
+// and insecure-skip-verify settings. Without a configured proxy, the standard
+// HTTPS_PROXY/NO_PROXY environment variables apply.
+func NewHTTPClient(sc config.CloudStoreConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if sc.Proxy != "" {
+		proxyURL, err := url.Parse(sc.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", sc.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if sc.CAFile != "" || sc.InsecureSkipVerify {
+		tlsConfig := &tls.Config{InsecureSkipVerify: sc.InsecureSkipVerify}
+		if sc.CAFile != "" {
+			pem, err := os.ReadFile(sc.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading CA bundle: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA bundle %s", sc.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &http.Client{Timeout: 30 * time.Second, Transport: transport}, nil
+}
+
 // --- HTTP helpers ---
 
-func (cs *CloudStore) doJSON(method, path string, body any) (*http.Response, error) {
+func (cs *CloudStore) doJSON(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	req, err := cs.newJSONRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	return cs.do(req)
+}
+
+// newJSONRequest builds a request to path with body, if any, as JSON, for
+// callers that set headers of their own before sending it with do.
+func (cs *CloudStore) newJSONRequest(ctx context.Context, method, path string, body any) (*http.Request, error) {
 	var bodyReader io.Reader
 	if body != nil {
 		b, err := json.Marshal(body)
@@ -61,15 +143,125 @@ func (cs *CloudStore) doJSON(method, path string, body any) (*http.Response, err
 		bodyReader = bytes.NewReader(b)
 	}
 
-	req, err := http.NewRequest(method, cs.apiBase+path, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, cs.apiBase+path, bodyReader)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+cs.apiKey)
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
-	return cs.client.Do(req)
+	return req, nil
+}
+
+// do sends req with the store's credentials and actor, and maps 401 to
+// ErrUnauthorized.
+// It honours the server's rate limit: when the last response said no
+// requests are left it waits for the window to reset, and a 429 is retried
+// after the wait the server asks for, up to maxRateLimitRetries times.
+func (cs *CloudStore) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+cs.apiKey)
+	if cs.org != "" {
+		req.Header.Set("X-Org-Slug", cs.org)
+	}
+	// The server attributes changes to the API key's user unless told
+	// they're made on behalf of another actor.
+	if a := Actor(); a != "" {
+		req.Header.Set("X-Compass-Actor", a)
+	}
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		if wait := cs.limit.wait(time.Now()); wait > 0 {
+			if wait > maxRateLimitWait {
+				return nil, cs.rateLimited(wait)
+			}
+			if err := cs.pause(req.Context(), wait, "Rate limit reached on %s, waiting %s", cs.label(), roundUp(wait)); err != nil {
+				return nil, err
+			}
+		}
+		start := time.Now()
+		var err error
+		resp, err = cs.client.Do(req)
+		cs.logRequest(req, resp, err, time.Since(start))
+		if err != nil {
+			if ctxErr := req.Context().Err(); errors.Is(ctxErr, context.Canceled) {
+				return nil, ctxErr
+			}
+			return nil, err
+		}
+		cs.limit.update(resp.Header, time.Now())
+		if resp.StatusCode != http.StatusTooManyRequests {
+			break
+		}
+		resp.Body.Close()
+		wait := retryAfter(resp.Header, time.Now(), attempt)
+		if attempt == maxRateLimitRetries || wait > maxRateLimitWait {
+			return nil, cs.rateLimited(wait)
+		}
+		if err := cs.pause(req.Context(), wait, "Rate limited by %s, retrying in %s", cs.label(), roundUp(wait)); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		if cs.name == "" {
+			return nil, ErrUnauthorized
+		}
+		return nil, fmt.Errorf("%s: %w; run: compass store login %s", cs.name, ErrUnauthorized, cs.name)
+	}
+	return resp, nil
+}
+
+// SetTrace logs every request the store sends to w, one line each with
+// the method, path, status, duration and the server's request ID, for
+// diagnosing API problems. A nil w turns logging off.
+func (cs *CloudStore) SetTrace(w io.Writer) {
+	cs.trace = w
+}
+
+// SetDepPolicy sets how ReadyTasks treats dependencies that no longer
+// exist, as LocalStore.SetDepPolicy does.
+func (cs *CloudStore) SetDepPolicy(p model.DepPolicy) {
+	cs.deps = p
+}
+
+func (cs *CloudStore) logRequest(req *http.Request, resp *http.Response, err error, took time.Duration) {
+	if cs.trace == nil {
+		return
+	}
+	result := "error: " + fmt.Sprint(err)
+	if err == nil {
+		result = strconv.Itoa(resp.StatusCode)
+		if rid := resp.Header.Get("X-Request-Id"); rid != "" {
+			result += " request_id=" + rid
+		}
+	}
+	fmt.Fprintf(cs.trace, "%s %s %s %s %s %dms\n", time.Now().UTC().Format(time.RFC3339), cs.label(),
+		req.Method, req.URL.RequestURI(), result, took.Milliseconds())
+}
+
+// Ping lists the caller's organizations, the cheapest authenticated
+// endpoint. The server version comes from the X-Compass-Version header.
+func (cs *CloudStore) Ping(ctx context.Context) (PingResult, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", cs.apiBase+"/orgs", nil)
+	if err != nil {
+		return PingResult{}, err
+	}
+	start := time.Now()
+	resp, err := cs.do(req)
+	if err != nil {
+		return PingResult{}, err
+	}
+	resp.Body.Close()
+	res := PingResult{Latency: time.Since(start), Version: resp.Header.Get("X-Compass-Version")}
+	if resp.StatusCode >= 400 {
+		return res, &APIError{Status: resp.StatusCode}
+	}
+	return res, nil
 }
 
 type apiError struct {
@@ -79,16 +271,50 @@ type apiError struct {
 	} `json:"error"`
 }
 
+// APIError is an error response from a cloud store. By status it matches
+// ErrNotFound, ErrConflict, ErrValidation or ErrUnauthorized.
+type APIError struct {
+	Status  int
+	Message string // the server's message, if it sent one
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API error %d", e.Status)
+	}
+	return fmt.Sprintf("API error %d: %s", e.Status, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	switch e.Status {
+	case http.StatusNotFound, http.StatusGone:
+		return ErrNotFound
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return ErrConflict
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrValidation
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// readAPIError returns the error in a failed response's body.
+func readAPIError(resp *http.Response) *APIError {
+	e := &APIError{Status: resp.StatusCode}
+	var body apiError
+	if err := json.NewDecoder(resp.Body).Decode(&body); err == nil {
+		e.Message = body.Error.Message
+	}
+	return e
+}
+
 func decodeResponse[T any](resp *http.Response) (T, error) {
 	defer resp.Body.Close()
 	var zero T
 
 	if resp.StatusCode >= 400 {
-		var apiErr apiError
-		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Error.Message != "" {
-			return zero, fmt.Errorf("API error %d: %s", resp.StatusCode, apiErr.Error.Message)
-		}
-		return zero, fmt.Errorf("API error %d", resp.StatusCode)
+		return zero, readAPIError(resp)
 	}
 
 	var wrapper struct {
@@ -100,6 +326,26 @@ func decodeResponse[T any](resp *http.Response) (T, error) {
 	return wrapper.Data, nil
 }
 
+// --- Organizations ---
+
+// Org is a cloud organization the API key's account belongs to.
+type Org struct {
+	Slug    string `json:"slug"`
+	Name    string `json:"name"`
+	Role    string `json:"role"`
+	Default bool   `json:"default"` // the org used when no slug is sent
+}
+
+// ListOrgs returns the organizations available to the API key. It is not part
+// of the Store interface because local stores have no organizations.
+func (cs *CloudStore) ListOrgs(ctx context.Context) ([]Org, error) {
+	resp, err := cs.doJSON(ctx, "GET", "/orgs", nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse[[]Org](resp)
+}
+
 // --- API response types ---
 
 type apiProject struct {
@@ -109,69 +355,167 @@ type apiProject struct {
 	Body      string     `json:"body"`
 	CreatedBy string     `json:"created_by"`
 	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at"`
+	// WIPLimits is keyed by status.
+	WIPLimits      map[model.Status]int `json:"wip_limits"`
+	AutoCloseEpics bool                 `json:"auto_close_epics"`
 }
 
 func (p *apiProject) toModel() *model.Project {
 	return &model.Project{
-		ID:        p.Key,
-		Name:      p.Name,
-		CreatedBy: p.CreatedBy,
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.CreatedAt,
+		ID:             p.Key,
+		Name:           p.Name,
+		CreatedBy:      p.CreatedBy,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      updatedAt(p.UpdatedAt, p.CreatedAt),
+		WIPLimits:      p.WIPLimits,
+		AutoCloseEpics: p.AutoCloseEpics,
 	}
 }
 
 type apiTask struct {
-	TaskID     string     `json:"task_id"`
-	Key        string     `json:"key"`
-	Title      string     `json:"title"`
-	Type       string     `json:"type"`
-	Status     string     `json:"status"`
-	Priority   *int       `json:"priority"`
-	EpicKey    string     `json:"epic_key"`
-	DependsOn  []string   `json:"depends_on"`
-	ProjectKey string     `json:"project_key"`
-	Body       string     `json:"body"`
-	CreatedBy  string     `json:"created_by"`
-	CreatedAt  time.Time  `json:"created_at"`
-	DeletedAt  *time.Time `json:"deleted_at"`
+	TaskID        string               `json:"task_id"`
+	Key           string               `json:"key"`
+	Title         string               `json:"title"`
+	Type          string               `json:"type"`
+	Status        string               `json:"status"`
+	Priority      *int                 `json:"priority"`
+	EpicKey       string               `json:"epic_key"`
+	DependsOn     []string             `json:"depends_on"`
+	WaitingOn     *apiWaitingOn        `json:"waiting_on"`
+	BlockedReason string               `json:"blocked_reason"`
+	DueDate       string               `json:"due_date"`
+	Assignee      string               `json:"assignee"`
+	Slug          string               `json:"slug"`
+	Watchers      []string             `json:"watchers"`
+	ProjectKey    string               `json:"project_key"`
+	Body          string               `json:"body"`
+	CreatedBy     string               `json:"created_by"`
+	ClosedAt      *time.Time           `json:"closed_at"`
+	AutoClose     *bool                `json:"auto_close"`
+	History       []model.StatusChange `json:"history"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+	DeletedAt     *time.Time           `json:"deleted_at"`
+}
+
+type apiWaitingOn struct {
+	Description string `json:"description"`
+	URL         string `json:"url,omitempty"`
+	Until       string `json:"until,omitempty"`
+}
+
+func newAPIWaitingOn(w *model.WaitingOn) *apiWaitingOn {
+	if w == nil {
+		return nil
+	}
+	return &apiWaitingOn{Description: w.Description, URL: w.URL, Until: w.Until}
 }
 
 func (t *apiTask) toModel() *model.Task {
+	var waiting *model.WaitingOn
+	if t.WaitingOn != nil {
+		waiting = &model.WaitingOn{Description: t.WaitingOn.Description, URL: t.WaitingOn.URL, Until: t.WaitingOn.Until}
+	}
 	return &model.Task{
-		ID:        t.Key,
-		Title:     t.Title,
-		Type:      model.TaskType(t.Type),
-		Project:   t.ProjectKey,
-		Status:    model.Status(t.Status),
-		Priority:  t.Priority,
-		Epic:      t.EpicKey,
-		DependsOn: t.DependsOn,
-		CreatedBy: t.CreatedBy,
-		CreatedAt: t.CreatedAt,
-		UpdatedAt: t.CreatedAt,
+		ID:            t.Key,
+		Title:         t.Title,
+		Type:          model.TaskType(t.Type),
+		Project:       t.ProjectKey,
+		Status:        model.Status(t.Status),
+		Priority:      t.Priority,
+		Epic:          t.EpicKey,
+		DependsOn:     t.DependsOn,
+		Waiting:       waiting,
+		BlockedReason: t.BlockedReason,
+		Due:           t.DueDate,
+		Assignee:      t.Assignee,
+		Slug:          t.Slug,
+		Watchers:      t.Watchers,
+		ClosedAt:      t.ClosedAt,
+		AutoClose:     t.AutoClose,
+		History:       t.History,
+		CreatedBy:     t.CreatedBy,
+		CreatedAt:     t.CreatedAt,
+		UpdatedAt:     updatedAt(t.UpdatedAt, t.CreatedAt),
+		DeletedAt:     t.DeletedAt,
 	}
 }
 
 type apiDocument struct {
-	DocumentID string     `json:"document_id"`
-	Key        string     `json:"key"`
-	Title      string     `json:"title"`
-	Body       string     `json:"body"`
-	CreatedBy  string     `json:"created_by"`
-	CreatedAt  time.Time  `json:"created_at"`
-	DeletedAt  *time.Time `json:"deleted_at"`
+	DocumentID   string     `json:"document_id"`
+	Key          string     `json:"key"`
+	Title        string     `json:"title"`
+	Kind         string     `json:"kind"`
+	Slug         string     `json:"slug"`
+	ADRNumber    int        `json:"adr_number"`
+	ADRStatus    string     `json:"adr_status"`
+	Supersedes   string     `json:"supersedes"`
+	SupersededBy string     `json:"superseded_by"`
+	Body         string     `json:"body"`
+	CreatedBy    string     `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at"`
 }
 
 func (d *apiDocument) toModel() *model.Document {
 	return &model.Document{
-		ID:        d.Key,
-		Title:     d.Title,
-		CreatedBy: d.CreatedBy,
-		CreatedAt: d.CreatedAt,
-		UpdatedAt: d.CreatedAt,
+		ID:           d.Key,
+		Title:        d.Title,
+		Kind:         model.DocKind(d.Kind),
+		Slug:         d.Slug,
+		Number:       d.ADRNumber,
+		Status:       model.ADRStatus(d.ADRStatus),
+		Supersedes:   d.Supersedes,
+		SupersededBy: d.SupersededBy,
+		CreatedBy:    d.CreatedBy,
+		CreatedAt:    d.CreatedAt,
+		UpdatedAt:    updatedAt(d.UpdatedAt, d.CreatedAt),
+		DeletedAt:    d.DeletedAt,
+	}
+}
+
+type apiRelease struct {
+	ReleaseID    string     `json:"release_id"`
+	Key          string     `json:"key"`
+	Version      string     `json:"version"`
+	Status       string     `json:"status"`
+	TargetDate   string     `json:"target_date"`
+	Items        []string   `json:"items"`
+	ChangelogKey string     `json:"changelog_key"`
+	ProjectKey   string     `json:"project_key"`
+	Body         string     `json:"body"`
+	CreatedBy    string     `json:"created_by"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	CutAt        *time.Time `json:"cut_at"`
+}
+
+func (r *apiRelease) toModel() *model.Release {
+	return &model.Release{
+		ID:         r.Key,
+		Version:    r.Version,
+		Project:    r.ProjectKey,
+		Status:     model.ReleaseStatus(r.Status),
+		TargetDate: r.TargetDate,
+		Items:      r.Items,
+		Changelog:  r.ChangelogKey,
+		CutAt:      r.CutAt,
+		CreatedBy:  r.CreatedBy,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  updatedAt(r.UpdatedAt, r.CreatedAt),
+	}
+}
+
+// updatedAt returns an entity's last update, falling back to its creation
+// for servers that don't send updated_at.
+func updatedAt(updated, created time.Time) time.Time {
+	if updated.IsZero() {
+		return created
 	}
+	return updated
 }
 
 type apiSearchResult struct {
@@ -183,7 +527,7 @@ type apiSearchResult struct {
 
 // --- Projects ---
 
-func (cs *CloudStore) CreateProject(name, key, body string) (*model.Project, error) {
+func (cs *CloudStore) CreateProject(ctx context.Context, name, key, body string) (*model.Project, error) {
 	payload := map[string]string{"name": name}
 	if key != "" {
 		payload["key"] = key
@@ -191,7 +535,7 @@ func (cs *CloudStore) CreateProject(name, key, body string) (*model.Project, err
 	if body != "" {
 		payload["body"] = body
 	}
-	resp, err := cs.doJSON("POST", "/projects", payload)
+	resp, err := cs.doJSON(ctx, "POST", "/projects", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -202,8 +546,8 @@ func (cs *CloudStore) CreateProject(name, key, body string) (*model.Project, err
 	return ap.toModel(), nil
 }
 
-func (cs *CloudStore) GetProject(projectID string) (*model.Project, string, error) {
-	resp, err := cs.doJSON("GET", "/projects/"+url.PathEscape(projectID), nil)
+func (cs *CloudStore) GetProject(ctx context.Context, projectID string) (*model.Project, string, error) {
+	resp, err := cs.doJSON(ctx, "GET", "/projects/"+url.PathEscape(projectID), nil)
 	if err != nil {
 		return nil, "", err
 	}
@@ -214,7 +558,7 @@ func (cs *CloudStore) GetProject(projectID string) (*model.Project, string, erro
 	return ap.toModel(), ap.Body, nil
 }
 
-func (cs *CloudStore) ListProjects() ([]model.Project, error) {
+func (cs *CloudStore) ListProjects(ctx context.Context) ([]model.Project, error) {
 	var all []model.Project
 	cursor := ""
 	for {
@@ -222,7 +566,7 @@ func (cs *CloudStore) ListProjects() ([]model.Project, error) {
 		if cursor != "" {
 			path += "&cursor=" + url.QueryEscape(cursor)
 		}
-		resp, err := cs.doJSON("GET", path, nil)
+		resp, err := cs.doJSON(ctx, "GET", path, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -241,21 +585,59 @@ func (cs *CloudStore) ListProjects() ([]model.Project, error) {
 	return all, nil
 }
 
-func (cs *CloudStore) DeleteProject(projectID string) error {
-	resp, err := cs.doJSON("DELETE", "/projects/"+url.PathEscape(projectID), nil)
+func (cs *CloudStore) DeleteProject(ctx context.Context, projectID string) error {
+	resp, err := cs.doJSON(ctx, "DELETE", "/projects/"+url.PathEscape(projectID), nil)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("API error %d deleting project", resp.StatusCode)
+		return fmt.Errorf("deleting project: %w", readAPIError(resp))
 	}
 	return nil
 }
 
+func (cs *CloudStore) UpdateProject(ctx context.Context, projectID string, upd ProjectUpdate) (*model.Project, error) {
+	payload := map[string]any{}
+	if upd.Name != nil {
+		payload["name"] = *upd.Name
+	}
+	if upd.AutoCloseEpics != nil {
+		payload["auto_close_epics"] = *upd.AutoCloseEpics
+	}
+	if upd.WIPLimits != nil {
+		payload["wip_limits"] = *upd.WIPLimits
+	}
+	if upd.IDLength != nil {
+		payload["id_length"] = *upd.IDLength
+	}
+	if upd.Body != nil {
+		payload["body"] = *upd.Body
+	}
+	return cs.patchProject(ctx, projectID, payload)
+}
+
+// RekeyProject asks the server to change the project key; the server
+// rewrites the IDs of the project's entities.
+func (cs *CloudStore) RekeyProject(ctx context.Context, oldKey, newKey string) (*model.Project, error) {
+	return cs.patchProject(ctx, oldKey, map[string]any{"key": newKey})
+}
+
+func (cs *CloudStore) patchProject(ctx context.Context, projectID string, payload map[string]any) (*model.Project, error) {
+	resp, err := cs.doJSON(ctx, "PATCH", "/projects/"+url.PathEscape(projectID), payload)
+	if err != nil {
+		return nil, err
+	}
+	ap, err := decodeResponse[apiProject](resp)
+	if err != nil {
+		return nil, err
+	}
+	return ap.toModel(), nil
+}
+
 // --- Tasks ---
 
-func (cs *CloudStore) CreateTask(title, projectID string, opts TaskCreateOpts) (*model.Task, error) {
+func (cs *CloudStore) CreateTask(ctx context.Context, title, projectID string, opts TaskCreateOpts) (*model.Task, error) {
 	payload := map[string]any{
 		"title": title,
 	}
@@ -272,10 +654,28 @@ func (cs *CloudStore) CreateTask(title, projectID string, opts TaskCreateOpts) (
 		payload["body"] = opts.Body
 	}
 	if len(opts.DependsOn) > 0 {
+		if err := validateDepTargets(ctx, cs, opts.DependsOn, projectID); err != nil {
+			return nil, err
+		}
 		payload["depends_on"] = opts.DependsOn
 	}
+	if opts.Waiting != nil {
+		payload["waiting_on"] = newAPIWaitingOn(opts.Waiting)
+	}
+	if opts.Due != "" {
+		payload["due_date"] = opts.Due
+	}
+	if opts.Assignee != "" {
+		payload["assignee"] = opts.Assignee
+	}
+	if opts.Slug != "" {
+		if err := checkSlug(ctx, cs, projectID, opts.Slug, ""); err != nil {
+			return nil, err
+		}
+		payload["slug"] = opts.Slug
+	}
 
-	resp, err := cs.doJSON("POST", "/projects/"+url.PathEscape(projectID)+"/tasks", payload)
+	resp, err := cs.doJSON(ctx, "POST", "/projects/"+url.PathEscape(projectID)+"/tasks", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -288,8 +688,8 @@ func (cs *CloudStore) CreateTask(title, projectID string, opts TaskCreateOpts) (
 	return t, nil
 }
 
-func (cs *CloudStore) GetTask(taskID string) (*model.Task, string, error) {
-	resp, err := cs.doJSON("GET", "/tasks/"+url.PathEscape(taskID), nil)
+func (cs *CloudStore) GetTask(ctx context.Context, taskID string) (*model.Task, string, error) {
+	resp, err := cs.doJSON(ctx, "GET", "/tasks/"+url.PathEscape(taskID), nil)
 	if err != nil {
 		return nil, "", err
 	}
@@ -300,10 +700,10 @@ func (cs *CloudStore) GetTask(taskID string) (*model.Task, string, error) {
 	return at.toModel(), at.Body, nil
 }
 
-func (cs *CloudStore) ListTasks(filter TaskFilter) ([]model.Task, error) {
+func (cs *CloudStore) ListTasks(ctx context.Context, filter TaskFilter) ([]model.Task, error) {
 	if filter.ProjectID == "" {
 		// List across all projects: list projects first, then tasks per project
-		projects, err := cs.ListProjects()
+		projects, err := cs.ListProjects(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -311,7 +711,7 @@ func (cs *CloudStore) ListTasks(filter TaskFilter) ([]model.Task, error) {
 		for _, p := range projects {
 			f := filter
 			f.ProjectID = p.ID
-			tasks, err := cs.ListTasks(f)
+			tasks, err := cs.ListTasks(ctx, f)
 			if err != nil {
 				continue
 			}
@@ -320,52 +720,104 @@ func (cs *CloudStore) ListTasks(filter TaskFilter) ([]model.Task, error) {
 		return all, nil
 	}
 
-	var all []model.Task
-	cursor := ""
-	for {
-		path := "/projects/" + url.PathEscape(filter.ProjectID) + "/tasks?limit=100"
-		if filter.Status != "" {
-			path += "&status=" + url.QueryEscape(string(filter.Status))
-		}
-		if filter.Type != "" {
-			path += "&type=" + url.QueryEscape(string(filter.Type))
+	tasks, _, err := cs.ListTasksPage(ctx, filter, PageOpts{})
+	return tasks, err
+}
+
+// ListTasksPage sorts and pages on the server. Without a project it lists
+// every project's tasks and pages them in memory.
+func (cs *CloudStore) ListTasksPage(ctx context.Context, filter TaskFilter, page PageOpts) ([]model.Task, string, error) {
+	if filter.ProjectID == "" {
+		tasks, err := cs.ListTasks(ctx, filter)
+		if err != nil {
+			return nil, "", err
 		}
-		if filter.EpicID != "" {
-			path += "&epic=" + url.QueryEscape(filter.EpicID)
+		return pageSlice(tasks, page, taskSorts, TaskSortFields)
+	}
+	if err := page.validate(TaskSortFields); err != nil {
+		return nil, "", err
+	}
+	q := url.Values{}
+	if filter.Status != "" {
+		q.Set("status", string(filter.Status))
+	}
+	if filter.Type != "" {
+		q.Set("type", string(filter.Type))
+	}
+	if filter.EpicID != "" {
+		q.Set("epic", filter.EpicID)
+	}
+	return fetchPage(ctx, cs, "/projects/"+url.PathEscape(filter.ProjectID)+"/tasks", q, page, func(at apiTask) model.Task {
+		t := *at.toModel()
+		t.Project = filter.ProjectID
+		return t
+	})
+}
+
+// fetchPage follows next_cursor until page.Limit items (or everything, when
+// Limit is 0) have been fetched, requesting at most 100 per call. It returns
+// the server's cursor for the item after the last one returned.
+func fetchPage[A, M any](ctx context.Context, cs *CloudStore, path string, q url.Values, page PageOpts, conv func(A) M) ([]M, string, error) {
+	if page.Sort != "" {
+		q.Set("sort", page.Sort)
+	}
+	cursor := page.Cursor
+	if cursor == "" && page.Offset > 0 {
+		q.Set("offset", strconv.Itoa(page.Offset))
+	}
+
+	var all []M
+	for {
+		n := 100
+		if page.Limit > 0 && page.Limit-len(all) < n {
+			n = page.Limit - len(all)
 		}
+		q.Set("limit", strconv.Itoa(n))
 		if cursor != "" {
-			path += "&cursor=" + url.QueryEscape(cursor)
+			q.Set("cursor", cursor)
+			q.Del("offset")
 		}
-		resp, err := cs.doJSON("GET", path, nil)
+		resp, err := cs.doJSON(ctx, "GET", path+"?"+q.Encode(), nil)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		page, err := decodePagedResponse[apiTask](resp)
+		res, err := decodePagedResponse[A](resp)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		for _, at := range page.data {
-			t := *at.toModel()
-			t.Project = filter.ProjectID
-			all = append(all, t)
+		for _, a := range res.data {
+			all = append(all, conv(a))
 		}
-		if page.nextCursor == "" {
-			break
+		if res.nextCursor == "" || (page.Limit > 0 && len(all) >= page.Limit) {
+			return all, res.nextCursor, nil
 		}
-		cursor = page.nextCursor
+		cursor = res.nextCursor
 	}
-	return all, nil
 }
 
-func (cs *CloudStore) UpdateTask(taskID string, upd TaskUpdate) (*model.Task, error) {
-	if upd.Status != nil {
-		t, _, err := cs.GetTask(taskID)
+func (cs *CloudStore) UpdateTask(ctx context.Context, taskID string, upd TaskUpdate) (*model.Task, error) {
+	if upd.Status != nil || upd.DependsOn != nil {
+		t, _, err := cs.GetTask(ctx, taskID)
 		if err != nil {
 			return nil, err
 		}
-		if t.Type == model.TypeEpic {
+		if upd.Status != nil && t.Type == model.TypeEpic {
 			return nil, fmt.Errorf("cannot change epic status: epics do not have a status")
 		}
+		if upd.DependsOn != nil {
+			// Checked here as the local store does, so an update that
+			// would close a cycle reports the dependencies to drop.
+			if t.Type == model.TypeEpic && len(*upd.DependsOn) > 0 {
+				return nil, invalidf("epic-type tasks cannot have dependencies")
+			}
+			t.DependsOn = *upd.DependsOn
+			if t.Project == "" {
+				t.Project, _, _, _ = id.Parse(t.ID)
+			}
+			if err := validateDeps(ctx, cs, t, t.Project); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	payload := map[string]any{}
@@ -378,14 +830,65 @@ func (cs *CloudStore) UpdateTask(taskID string, upd TaskUpdate) (*model.Task, er
 	if upd.Priority != nil {
 		payload["priority"] = *upd.Priority // can be nil to clear
 	}
+	if upd.Epic != nil {
+		if *upd.Epic == "" {
+			payload["epic_key"] = nil
+		} else {
+			payload["epic_key"] = *upd.Epic
+		}
+	}
 	if upd.Body != nil {
 		payload["body"] = *upd.Body
 	}
 	if upd.DependsOn != nil {
 		payload["depends_on"] = *upd.DependsOn
 	}
+	if upd.Waiting != nil {
+		payload["waiting_on"] = newAPIWaitingOn(*upd.Waiting) // can be nil to clear
+	}
+	if upd.Due != nil {
+		if *upd.Due == "" {
+			payload["due_date"] = nil
+		} else {
+			payload["due_date"] = *upd.Due
+		}
+	}
+	if upd.Assignee != nil {
+		payload["assignee"] = *upd.Assignee
+	}
+	if upd.Watchers != nil {
+		payload["watchers"] = *upd.Watchers
+	}
+	if upd.Slug != nil {
+		if err := checkNewSlug(ctx, cs, taskID, *upd.Slug); err != nil {
+			return nil, err
+		}
+		payload["slug"] = *upd.Slug
+	}
+	if upd.BlockedReason != nil {
+		payload["blocked_reason"] = *upd.BlockedReason
+	} else if upd.Status != nil && *upd.Status != model.StatusBlocked {
+		payload["blocked_reason"] = ""
+	}
+	if upd.AutoClose != nil {
+		payload["auto_close"] = *upd.AutoClose
+	}
+
+	resp, err := cs.doJSON(ctx, "PATCH", "/tasks/"+url.PathEscape(taskID), payload)
+	if err != nil {
+		return nil, err
+	}
+	at, err := decodeResponse[apiTask](resp)
+	if err != nil {
+		return nil, err
+	}
+	return at.toModel(), nil
+}
 
-	resp, err := cs.doJSON("PATCH", "/tasks/"+url.PathEscape(taskID), payload)
+// MoveTask asks the server to reassign a task to another project on the same
+// store. The server issues the new ID and drops epic and dependency links.
+func (cs *CloudStore) MoveTask(ctx context.Context, taskID, projectID string) (*model.Task, error) {
+	resp, err := cs.doJSON(ctx, "POST", "/tasks/"+url.PathEscape(taskID)+"/move", map[string]string{"project": projectID})
 	if err != nil {
 		return nil, err
 	}
@@ -396,20 +899,20 @@ func (cs *CloudStore) UpdateTask(taskID string, upd TaskUpdate) (*model.Task, er
 	return at.toModel(), nil
 }
 
-func (cs *CloudStore) DeleteTask(taskID string) error {
-	resp, err := cs.doJSON("DELETE", "/tasks/"+url.PathEscape(taskID), nil)
+func (cs *CloudStore) DeleteTask(ctx context.Context, taskID string) error {
+	resp, err := cs.doJSON(ctx, "DELETE", "/tasks/"+url.PathEscape(taskID), nil)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("API error %d deleting task", resp.StatusCode)
+		return fmt.Errorf("deleting task: %w", readAPIError(resp))
 	}
 	return nil
 }
 
-func (cs *CloudStore) AllTaskMap(projectID string) (map[string]*model.Task, error) {
-	tasks, err := cs.ListTasks(TaskFilter{ProjectID: projectID})
+func (cs *CloudStore) AllTaskMap(ctx context.Context, projectID string) (map[string]*model.Task, error) {
+	tasks, err := cs.ListTasks(ctx, TaskFilter{ProjectID: projectID})
 	if err != nil {
 		return nil, err
 	}
@@ -420,9 +923,9 @@ func (cs *CloudStore) AllTaskMap(projectID string) (map[string]*model.Task, erro
 	return m, nil
 }
 
-func (cs *CloudStore) ReadyTasks(projectID string) ([]*model.Task, error) {
+func (cs *CloudStore) ReadyTasks(ctx context.Context, projectID string) ([]*model.Task, error) {
 	// Use the dedicated ready endpoint
-	resp, err := cs.doJSON("GET", "/projects/"+url.PathEscape(projectID)+"/tasks/ready", nil)
+	resp, err := cs.doJSON(ctx, "GET", "/projects/"+url.PathEscape(projectID)+"/tasks/ready", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -434,19 +937,70 @@ func (cs *CloudStore) ReadyTasks(projectID string) ([]*model.Task, error) {
 	for _, at := range items {
 		t := at.toModel()
 		t.Project = projectID
+		// Waits lapse by date, and older servers don't know the blocked
+		// status, so filter client-side as well.
+		if t.Status == model.StatusBlocked || t.Waiting.Active(time.Now()) {
+			continue
+		}
 		result = append(result, t)
 	}
+	// Dependencies are checked against the project's tasks, as the local
+	// store does, so ready means the same thing on every store (and
+	// follows the missing_deps policy). Only fetched when needed.
+	if slices.ContainsFunc(result, func(t *model.Task) bool { return len(t.DependsOn) > 0 }) {
+		allTasks, err := cs.AllTaskMap(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		result = slices.DeleteFunc(result, func(t *model.Task) bool { return t.IsBlocked(allTasks, cs.deps) })
+	}
 	return result, nil
 }
 
+// ClaimTask asks the server to pick the top ready task and mark it
+// in_progress in one request. The server responds 204 when nothing is ready.
+func (cs *CloudStore) ClaimTask(ctx context.Context, projectID string) (*model.Task, error) {
+	resp, err := cs.doJSON(ctx, "POST", "/projects/"+url.PathEscape(projectID)+"/tasks/claim", nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		resp.Body.Close()
+		return nil, nil
+	}
+	at, err := decodeResponse[apiTask](resp)
+	if err != nil {
+		return nil, err
+	}
+	t := at.toModel()
+	t.Project = projectID
+	return t, nil
+}
+
 // --- Documents ---
 
-func (cs *CloudStore) CreateDocument(title, projectID, body string) (*model.Document, error) {
-	payload := map[string]string{"title": title}
-	if body != "" {
-		payload["body"] = body
+// CreateDocument numbers new ADRs client-side from the project's existing
+// documents, since the API stores the number as plain data.
+func (cs *CloudStore) CreateDocument(ctx context.Context, title, projectID string, opts DocumentCreateOpts) (*model.Document, error) {
+	payload := map[string]any{"title": title}
+	if opts.Body != "" {
+		payload["body"] = opts.Body
+	}
+	if opts.Slug != "" {
+		if err := checkSlug(ctx, cs, projectID, opts.Slug, ""); err != nil {
+			return nil, err
+		}
+		payload["slug"] = opts.Slug
 	}
-	resp, err := cs.doJSON("POST", "/projects/"+url.PathEscape(projectID)+"/documents", payload)
+	if opts.Kind != "" {
+		var d model.Document
+		list := func() ([]model.Document, error) { return cs.ListDocuments(ctx, DocumentFilter{ProjectID: projectID}) }
+		if err := applyDocKind(&d, opts.Kind, list); err != nil {
+			return nil, err
+		}
+		addDocKindFields(payload, &d)
+	}
+	resp, err := cs.doJSON(ctx, "POST", "/projects/"+url.PathEscape(projectID)+"/documents", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -459,8 +1013,8 @@ func (cs *CloudStore) CreateDocument(title, projectID, body string) (*model.Docu
 	return d, nil
 }
 
-func (cs *CloudStore) GetDocument(docID string) (*model.Document, string, error) {
-	resp, err := cs.doJSON("GET", "/documents/"+url.PathEscape(docID), nil)
+func (cs *CloudStore) GetDocument(ctx context.Context, docID string) (*model.Document, string, error) {
+	resp, err := cs.doJSON(ctx, "GET", "/documents/"+url.PathEscape(docID), nil)
 	if err != nil {
 		return nil, "", err
 	}
@@ -471,15 +1025,17 @@ func (cs *CloudStore) GetDocument(docID string) (*model.Document, string, error)
 	return ad.toModel(), ad.Body, nil
 }
 
-func (cs *CloudStore) ListDocuments(projectID string) ([]model.Document, error) {
-	if projectID == "" {
-		projects, err := cs.ListProjects()
+func (cs *CloudStore) ListDocuments(ctx context.Context, filter DocumentFilter) ([]model.Document, error) {
+	if filter.ProjectID == "" {
+		projects, err := cs.ListProjects(ctx)
 		if err != nil {
 			return nil, err
 		}
 		var all []model.Document
 		for _, p := range projects {
-			docs, err := cs.ListDocuments(p.ID)
+			f := filter
+			f.ProjectID = p.ID
+			docs, err := cs.ListDocuments(ctx, f)
 			if err != nil {
 				continue
 			}
@@ -488,43 +1044,111 @@ func (cs *CloudStore) ListDocuments(projectID string) ([]model.Document, error)
 		return all, nil
 	}
 
-	var all []model.Document
-	cursor := ""
-	for {
-		path := "/projects/" + url.PathEscape(projectID) + "/documents?limit=100"
-		if cursor != "" {
-			path += "&cursor=" + url.QueryEscape(cursor)
-		}
-		resp, err := cs.doJSON("GET", path, nil)
+	docs, _, err := cs.listDocumentsPage(ctx, filter.ProjectID, PageOpts{})
+	if err != nil || !filter.narrowed() {
+		return docs, err
+	}
+	// The text is matched by a server-side search; the rest is checked here.
+	var found map[string]bool
+	if filter.Search != "" {
+		results, err := cs.Search(ctx, filter.Search, SearchOpts{ProjectID: filter.ProjectID, Type: "document"})
 		if err != nil {
 			return nil, err
 		}
-		page, err := decodePagedResponse[apiDocument](resp)
-		if err != nil {
-			return nil, err
+		found = map[string]bool{}
+		for _, r := range results {
+			found[r.ID] = true
 		}
-		for _, ad := range page.data {
-			d := *ad.toModel()
-			d.Project = projectID
-			all = append(all, d)
+	}
+	kept := docs[:0]
+	for _, d := range docs {
+		if filter.match(&d) && (found == nil || found[d.ID]) {
+			kept = append(kept, d)
 		}
-		if page.nextCursor == "" {
-			break
+	}
+	return kept, nil
+}
+
+// ListDocumentsPage sorts and pages on the server. Without a project, or
+// with conditions beyond it, it lists the matching documents and pages them
+// in memory.
+func (cs *CloudStore) ListDocumentsPage(ctx context.Context, filter DocumentFilter, page PageOpts) ([]model.Document, string, error) {
+	if filter.ProjectID == "" || filter.narrowed() {
+		docs, err := cs.ListDocuments(ctx, filter)
+		if err != nil {
+			return nil, "", err
 		}
-		cursor = page.nextCursor
+		return pageSlice(docs, page, documentSorts, DocumentSortFields)
 	}
-	return all, nil
+	return cs.listDocumentsPage(ctx, filter.ProjectID, page)
 }
 
-func (cs *CloudStore) UpdateDocument(docID string, title, body *string) (*model.Document, error) {
+func (cs *CloudStore) listDocumentsPage(ctx context.Context, projectID string, page PageOpts) ([]model.Document, string, error) {
+	if err := page.validate(DocumentSortFields); err != nil {
+		return nil, "", err
+	}
+	return fetchPage(ctx, cs, "/projects/"+url.PathEscape(projectID)+"/documents", url.Values{}, page, func(ad apiDocument) model.Document {
+		d := *ad.toModel()
+		d.Project = projectID
+		return d
+	})
+}
+
+func (cs *CloudStore) UpdateDocument(ctx context.Context, docID string, upd DocumentUpdate) (*model.Document, error) {
 	payload := map[string]any{}
-	if title != nil {
-		payload["title"] = *title
+	if upd.Title != nil {
+		payload["title"] = *upd.Title
 	}
-	if body != nil {
-		payload["body"] = *body
+	if upd.Slug != nil {
+		if err := checkNewSlug(ctx, cs, docID, *upd.Slug); err != nil {
+			return nil, err
+		}
+		payload["slug"] = *upd.Slug
 	}
-	resp, err := cs.doJSON("PATCH", "/documents/"+url.PathEscape(docID), payload)
+	if upd.Kind != nil {
+		d, _, err := cs.GetDocument(ctx, docID)
+		if err != nil {
+			return nil, err
+		}
+		projectID, err := id.ProjectKeyFrom(docID)
+		if err != nil {
+			return nil, err
+		}
+		list := func() ([]model.Document, error) { return cs.ListDocuments(ctx, DocumentFilter{ProjectID: projectID}) }
+		if err := applyDocKind(d, *upd.Kind, list); err != nil {
+			return nil, err
+		}
+		addDocKindFields(payload, d)
+	}
+	if upd.Status != nil {
+		payload["adr_status"] = string(*upd.Status)
+	}
+	if upd.Supersedes != nil {
+		payload["supersedes"] = *upd.Supersedes
+	}
+	if upd.SupersededBy != nil {
+		payload["superseded_by"] = *upd.SupersededBy
+	}
+	if upd.Body != nil {
+		payload["body"] = *upd.Body
+	}
+	req, err := cs.newJSONRequest(ctx, "PATCH", "/documents/"+url.PathEscape(docID), payload)
+	if err != nil {
+		return nil, err
+	}
+	if v := upd.IfUnchanged; v != nil {
+		// Checked here for servers that ignore the precondition; those
+		// that honour it close the gap between the check and the write.
+		d, body, err := cs.GetDocument(ctx, docID)
+		if err != nil {
+			return nil, err
+		}
+		if !v.matches(d.UpdatedAt, body) {
+			return nil, conflictf("document %s changed since it was read", docID)
+		}
+		req.Header.Set("If-Unmodified-Since", v.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+	resp, err := cs.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -535,26 +1159,162 @@ func (cs *CloudStore) UpdateDocument(docID string, title, body *string) (*model.
 	return ad.toModel(), nil
 }
 
-func (cs *CloudStore) DeleteDocument(docID string) error {
-	resp, err := cs.doJSON("DELETE", "/documents/"+url.PathEscape(docID), nil)
+func addDocKindFields(payload map[string]any, d *model.Document) {
+	payload["kind"] = string(d.Kind)
+	payload["adr_number"] = d.Number
+	payload["adr_status"] = string(d.Status)
+	payload["supersedes"] = d.Supersedes
+	payload["superseded_by"] = d.SupersededBy
+}
+
+func (cs *CloudStore) DeleteDocument(ctx context.Context, docID string) error {
+	resp, err := cs.doJSON(ctx, "DELETE", "/documents/"+url.PathEscape(docID), nil)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("API error %d deleting document", resp.StatusCode)
+		return fmt.Errorf("deleting document: %w", readAPIError(resp))
 	}
 	return nil
 }
 
+// --- Releases ---
+
+func (cs *CloudStore) CreateRelease(ctx context.Context, version, projectID string, opts ReleaseCreateOpts) (*model.Release, error) {
+	payload := map[string]any{"version": version}
+	if opts.TargetDate != "" {
+		payload["target_date"] = opts.TargetDate
+	}
+	if len(opts.Items) > 0 {
+		payload["items"] = opts.Items
+	}
+	if opts.Body != "" {
+		payload["body"] = opts.Body
+	}
+	resp, err := cs.doJSON(ctx, "POST", "/projects/"+url.PathEscape(projectID)+"/releases", payload)
+	if err != nil {
+		return nil, err
+	}
+	ar, err := decodeResponse[apiRelease](resp)
+	if err != nil {
+		return nil, err
+	}
+	r := ar.toModel()
+	r.Project = projectID
+	return r, nil
+}
+
+func (cs *CloudStore) GetRelease(ctx context.Context, releaseID string) (*model.Release, string, error) {
+	resp, err := cs.doJSON(ctx, "GET", "/releases/"+url.PathEscape(releaseID), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	ar, err := decodeResponse[apiRelease](resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return ar.toModel(), ar.Body, nil
+}
+
+func (cs *CloudStore) ListReleases(ctx context.Context, projectID string) ([]model.Release, error) {
+	if projectID == "" {
+		projects, err := cs.ListProjects(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var all []model.Release
+		for _, p := range projects {
+			releases, err := cs.ListReleases(ctx, p.ID)
+			if err != nil {
+				continue
+			}
+			all = append(all, releases...)
+		}
+		return all, nil
+	}
+
+	var all []model.Release
+	cursor := ""
+	for {
+		path := "/projects/" + url.PathEscape(projectID) + "/releases?limit=100"
+		if cursor != "" {
+			path += "&cursor=" + url.QueryEscape(cursor)
+		}
+		resp, err := cs.doJSON(ctx, "GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		page, err := decodePagedResponse[apiRelease](resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, ar := range page.data {
+			r := *ar.toModel()
+			r.Project = projectID
+			all = append(all, r)
+		}
+		if page.nextCursor == "" {
+			break
+		}
+		cursor = page.nextCursor
+	}
+	return all, nil
+}
+
+func (cs *CloudStore) UpdateRelease(ctx context.Context, releaseID string, upd ReleaseUpdate) (*model.Release, error) {
+	payload := map[string]any{}
+	if upd.TargetDate != nil {
+		payload["target_date"] = *upd.TargetDate
+	}
+	if upd.Items != nil {
+		payload["items"] = *upd.Items
+	}
+	if upd.Status != nil {
+		payload["status"] = string(*upd.Status)
+	}
+	if upd.Changelog != nil {
+		payload["changelog_key"] = *upd.Changelog
+	}
+	if upd.Body != nil {
+		payload["body"] = *upd.Body
+	}
+	resp, err := cs.doJSON(ctx, "PATCH", "/releases/"+url.PathEscape(releaseID), payload)
+	if err != nil {
+		return nil, err
+	}
+	ar, err := decodeResponse[apiRelease](resp)
+	if err != nil {
+		return nil, err
+	}
+	return ar.toModel(), nil
+}
+
 // --- Search ---
 
-func (cs *CloudStore) Search(query, projectID string) ([]SearchResult, error) {
-	path := "/search?q=" + url.QueryEscape(query)
-	if projectID != "" {
-		path += "&project=" + url.QueryEscape(projectID)
+// Search sends the options to the API as the fields, type and regex
+// parameters.
+func (cs *CloudStore) Search(ctx context.Context, query string, opts SearchOpts) ([]SearchResult, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	q := url.Values{"q": {query}}
+	if opts.ProjectID != "" {
+		q.Set("project", opts.ProjectID)
+	}
+	if opts.Regex {
+		q.Set("regex", "true")
 	}
-	resp, err := cs.doJSON("GET", path, nil)
+	switch {
+	case opts.TitleOnly:
+		q.Set("fields", "title")
+	case opts.BodyOnly:
+		q.Set("fields", "body")
+	}
+	if opts.Type != "" {
+		q.Set("type", opts.Type)
+	}
+	resp, err := cs.doJSON(ctx, "GET", "/search?"+q.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -564,6 +1324,9 @@ func (cs *CloudStore) Search(query, projectID string) ([]SearchResult, error) {
 	}
 	var results []SearchResult
 	for _, item := range items {
+		if !opts.wants(item.Type) {
+			continue
+		}
 		results = append(results, SearchResult{
 			Type:    item.Type,
 			ID:      item.ID,
@@ -574,13 +1337,37 @@ func (cs *CloudStore) Search(query, projectID string) ([]SearchResult, error) {
 	return results, nil
 }
 
+// Bodies lists a project's tasks and documents, whose list responses carry
+// their bodies, rather than fetching each one.
+func (cs *CloudStore) Bodies(ctx context.Context, projectID string) (map[string]string, error) {
+	type entry struct{ id, body string }
+	base := "/projects/" + url.PathEscape(projectID)
+	tasks, _, err := fetchPage(ctx, cs, base+"/tasks", url.Values{}, PageOpts{}, func(at apiTask) entry {
+		return entry{at.Key, at.Body}
+	})
+	if err != nil {
+		return nil, err
+	}
+	docs, _, err := fetchPage(ctx, cs, base+"/documents", url.Values{}, PageOpts{}, func(ad apiDocument) entry {
+		return entry{ad.Key, ad.Body}
+	})
+	if err != nil {
+		return nil, err
+	}
+	bodies := make(map[string]string, len(tasks)+len(docs))
+	for _, e := range append(tasks, docs...) {
+		bodies[e.id] = e.body
+	}
+	return bodies, nil
+}
+
 // --- Entity operations ---
 
 func (cs *CloudStore) ResolveEntityPath(entityID string) (string, error) {
 	return "", fmt.Errorf("ResolveEntityPath not supported in cloud mode")
 }
 
-func (cs *CloudStore) DownloadEntity(entityID, destDir string) (string, error) {
+func (cs *CloudStore) DownloadEntity(ctx context.Context, entityID, destDir string) (string, error) {
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return "", fmt.Errorf("creating %s: %w", destDir, err)
 	}
@@ -588,7 +1375,7 @@ func (cs *CloudStore) DownloadEntity(entityID, destDir string) (string, error) {
 
 	// Determine entity type from ID pattern (e.g. PROJ-TABCDE vs PROJ-DABCDE)
 	if isDocumentID(entityID) {
-		d, body, err := cs.GetDocument(entityID)
+		d, body, err := cs.GetDocument(ctx, entityID)
 		if err != nil {
 			return "", err
 		}
@@ -600,7 +1387,7 @@ func (cs *CloudStore) DownloadEntity(entityID, destDir string) (string, error) {
 			return "", fmt.Errorf("writing %s: %w", destPath, err)
 		}
 	} else {
-		t, body, err := cs.GetTask(entityID)
+		t, body, err := cs.GetTask(ctx, entityID)
 		if err != nil {
 			return "", err
 		}
@@ -615,13 +1402,13 @@ func (cs *CloudStore) DownloadEntity(entityID, destDir string) (string, error) {
 	return destPath, nil
 }
 
-func (cs *CloudStore) UploadTask(localPath string) (*model.Task, error) {
+func (cs *CloudStore) UploadTask(ctx context.Context, localPath string) (*model.Task, error) {
 	t, body, err := ReadEntity[model.Task](localPath)
 	if err != nil {
 		return nil, fmt.Errorf("reading local file: %w", err)
 	}
 
-	updated, err := cs.UpdateTask(t.ID, TaskUpdate{
+	updated, err := cs.UpdateTask(ctx, t.ID, TaskUpdate{
 		Title: &t.Title,
 		Body:  &body,
 	})
@@ -633,13 +1420,13 @@ func (cs *CloudStore) UploadTask(localPath string) (*model.Task, error) {
 	return updated, nil
 }
 
-func (cs *CloudStore) UploadDocument(localPath string) (*model.Document, error) {
+func (cs *CloudStore) UploadDocument(ctx context.Context, localPath string) (*model.Document, error) {
 	d, body, err := ReadEntity[model.Document](localPath)
 	if err != nil {
 		return nil, fmt.Errorf("reading local file: %w", err)
 	}
 
-	updated, err := cs.UpdateDocument(d.ID, &d.Title, &body)
+	updated, err := cs.UpdateDocument(ctx, d.ID, DocumentUpdate{Title: &d.Title, Body: &body})
 	if err != nil {
 		return nil, err
 	}
@@ -676,11 +1463,7 @@ func decodePagedResponse[T any](resp *http.Response) (pagedResult[T], error) {
 	var zero pagedResult[T]
 
 	if resp.StatusCode >= 400 {
-		var apiErr apiError
-		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Error.Message != "" {
-			return zero, fmt.Errorf("API error %d: %s", resp.StatusCode, apiErr.Error.Message)
-		}
-		return zero, fmt.Errorf("API error %d", resp.StatusCode)
+		return zero, readAPIError(resp)
 	}
 
 	var wrapper struct {
@@ -87,27 +87,27 @@ func TestIntegration_ProjectCRUD(t *testing.T) {
 	cs := NewCloudStoreWithBase(apiURL, apiKey)
 
 	// Create
-	p, err := cs.CreateProject("Integration Test", "IT", "")
+	p, err := cs.CreateProject(t.Context(), "Integration Test", "IT", "")
 	require.NoError(t, err)
 	assert.Equal(t, "IT", p.ID)
 	assert.Equal(t, "Integration Test", p.Name)
 
 	// Get
-	got, body, err := cs.GetProject("IT")
+	got, body, err := cs.GetProject(t.Context(), "IT")
 	require.NoError(t, err)
 	assert.Equal(t, "IT", got.ID)
 	assert.Equal(t, "", body)
 
 	// List
-	projects, err := cs.ListProjects()
+	projects, err := cs.ListProjects(t.Context())
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(projects), 1)
 
 	// Delete
-	err = cs.DeleteProject("IT")
+	err = cs.DeleteProject(t.Context(), "IT")
 	require.NoError(t, err)
 
-	_, _, err = cs.GetProject("IT")
+	_, _, err = cs.GetProject(t.Context(), "IT")
 	assert.Error(t, err)
 }
 
@@ -116,33 +116,33 @@ func TestIntegration_TaskCRUD(t *testing.T) {
 	apiKey := setupTestUser(t, apiURL)
 	cs := NewCloudStoreWithBase(apiURL, apiKey)
 
-	p, err := cs.CreateProject("Task Test", "TT", "")
+	p, err := cs.CreateProject(t.Context(), "Task Test", "TT", "")
 	require.NoError(t, err)
 
 	// Create
-	task, err := cs.CreateTask("My Task", p.ID, TaskCreateOpts{Body: "task body"})
+	task, err := cs.CreateTask(t.Context(), "My Task", p.ID, TaskCreateOpts{Body: "task body"})
 	require.NoError(t, err)
 	assert.Equal(t, "My Task", task.Title)
 
 	// Get
-	got, body, err := cs.GetTask(task.ID)
+	got, body, err := cs.GetTask(t.Context(), task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, task.ID, got.ID)
 	assert.Equal(t, "task body", body)
 
 	// Update
 	newTitle := "Updated Task"
-	updated, err := cs.UpdateTask(task.ID, TaskUpdate{Title: &newTitle})
+	updated, err := cs.UpdateTask(t.Context(), task.ID, TaskUpdate{Title: &newTitle})
 	require.NoError(t, err)
 	assert.Equal(t, "Updated Task", updated.Title)
 
 	// List
-	tasks, err := cs.ListTasks(TaskFilter{ProjectID: p.ID})
+	tasks, err := cs.ListTasks(t.Context(), TaskFilter{ProjectID: p.ID})
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(tasks), 1)
 
 	// Delete
-	err = cs.DeleteTask(task.ID)
+	err = cs.DeleteTask(t.Context(), task.ID)
 	require.NoError(t, err)
 }
 
@@ -151,16 +151,16 @@ func TestIntegration_DocumentCRUD(t *testing.T) {
 	apiKey := setupTestUser(t, apiURL)
 	cs := NewCloudStoreWithBase(apiURL, apiKey)
 
-	p, err := cs.CreateProject("Doc Test", "DT", "")
+	p, err := cs.CreateProject(t.Context(), "Doc Test", "DT", "")
 	require.NoError(t, err)
 
 	// Create
-	doc, err := cs.CreateDocument("My Doc", p.ID, "doc body")
+	doc, err := cs.CreateDocument(t.Context(), "My Doc", p.ID, DocumentCreateOpts{Body: "doc body"})
 	require.NoError(t, err)
 	assert.Equal(t, "My Doc", doc.Title)
 
 	// Get
-	got, body, err := cs.GetDocument(doc.ID)
+	got, body, err := cs.GetDocument(t.Context(), doc.ID)
 	require.NoError(t, err)
 	assert.Equal(t, doc.ID, got.ID)
 	assert.Equal(t, "doc body", body)
@@ -168,17 +168,17 @@ func TestIntegration_DocumentCRUD(t *testing.T) {
 	// Update
 	newTitle := "Updated Doc"
 	newBody := "new body"
-	updated, err := cs.UpdateDocument(doc.ID, &newTitle, &newBody)
+	updated, err := cs.UpdateDocument(t.Context(), doc.ID, DocumentUpdate{Title: &newTitle, Body: &newBody})
 	require.NoError(t, err)
 	assert.Equal(t, "Updated Doc", updated.Title)
 
 	// List
-	docs, err := cs.ListDocuments(p.ID)
+	docs, err := cs.ListDocuments(t.Context(), DocumentFilter{ProjectID: p.ID})
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(docs), 1)
 
 	// Delete
-	err = cs.DeleteDocument(doc.ID)
+	err = cs.DeleteDocument(t.Context(), doc.ID)
 	require.NoError(t, err)
 }
 
@@ -187,27 +187,27 @@ func TestIntegration_ReadyTasks(t *testing.T) {
 	apiKey := setupTestUser(t, apiURL)
 	cs := NewCloudStoreWithBase(apiURL, apiKey)
 
-	p, err := cs.CreateProject("Ready Test", "RT", "")
+	p, err := cs.CreateProject(t.Context(), "Ready Test", "RT", "")
 	require.NoError(t, err)
 
 	// Create tasks
-	t1, err := cs.CreateTask("Task 1", p.ID, TaskCreateOpts{})
+	t1, err := cs.CreateTask(t.Context(), "Task 1", p.ID, TaskCreateOpts{})
 	require.NoError(t, err)
-	_, err = cs.CreateTask("Task 2", p.ID, TaskCreateOpts{})
+	_, err = cs.CreateTask(t.Context(), "Task 2", p.ID, TaskCreateOpts{})
 	require.NoError(t, err)
 
 	// Both should be ready
-	ready, err := cs.ReadyTasks(p.ID)
+	ready, err := cs.ReadyTasks(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(ready), 2)
 
 	// Close one
 	closed := model.StatusClosed
-	_, err = cs.UpdateTask(t1.ID, TaskUpdate{Status: &closed})
+	_, err = cs.UpdateTask(t.Context(), t1.ID, TaskUpdate{Status: &closed})
 	require.NoError(t, err)
 
 	// Should still have at least one ready
-	ready, err = cs.ReadyTasks(p.ID)
+	ready, err = cs.ReadyTasks(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(ready), 1)
 }
@@ -217,15 +217,15 @@ func TestIntegration_DownloadUpload(t *testing.T) {
 	apiKey := setupTestUser(t, apiURL)
 	cs := NewCloudStoreWithBase(apiURL, apiKey)
 
-	p, err := cs.CreateProject("Download Test", "CT", "")
+	p, err := cs.CreateProject(t.Context(), "Download Test", "CT", "")
 	require.NoError(t, err)
 
-	task, err := cs.CreateTask("Download Task", p.ID, TaskCreateOpts{Body: "original body"})
+	task, err := cs.CreateTask(t.Context(), "Download Task", p.ID, TaskCreateOpts{Body: "original body"})
 	require.NoError(t, err)
 
 	// Download
 	destDir := t.TempDir()
-	localPath, err := cs.DownloadEntity(task.ID, destDir)
+	localPath, err := cs.DownloadEntity(t.Context(), task.ID, destDir)
 	require.NoError(t, err)
 	assert.FileExists(t, localPath)
 
@@ -240,7 +240,7 @@ func TestIntegration_DownloadUpload(t *testing.T) {
 	require.NoError(t, cs.WriteEntity(localPath, &local, "modified body"))
 
 	// Upload
-	result, err := cs.UploadTask(localPath)
+	result, err := cs.UploadTask(t.Context(), localPath)
 	require.NoError(t, err)
 	assert.Equal(t, "Modified Title", result.Title)
 
@@ -248,7 +248,7 @@ func TestIntegration_DownloadUpload(t *testing.T) {
 	assert.NoFileExists(t, localPath)
 
 	// Verify API updated
-	got, body, err := cs.GetTask(task.ID)
+	got, body, err := cs.GetTask(t.Context(), task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "Modified Title", got.Title)
 	assert.Equal(t, "modified body", body)
@@ -259,13 +259,13 @@ func TestIntegration_Search(t *testing.T) {
 	apiKey := setupTestUser(t, apiURL)
 	cs := NewCloudStoreWithBase(apiURL, apiKey)
 
-	p, err := cs.CreateProject("Search Test", "ST", "")
+	p, err := cs.CreateProject(t.Context(), "Search Test", "ST", "")
 	require.NoError(t, err)
 
-	_, err = cs.CreateTask("Authentication Module", p.ID, TaskCreateOpts{})
+	_, err = cs.CreateTask(t.Context(), "Authentication Module", p.ID, TaskCreateOpts{})
 	require.NoError(t, err)
 
-	results, err := cs.Search("Authentication", "")
+	results, err := cs.Search(t.Context(), "Authentication", SearchOpts{})
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(results), 1)
 }
@@ -1,11 +1,20 @@
 package store
 
 import (
+	"context"
 	"encoding/json"
+	"encoding/pem"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/rogersnm/compass/internal/config"
 	"github.com/rogersnm/compass/internal/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -46,12 +55,34 @@ func TestCloudStore_CreateProject(t *testing.T) {
 	})
 	defer srv.Close()
 
-	p, err := cs.CreateProject("My Project", "MP", "")
+	p, err := cs.CreateProject(t.Context(), "My Project", "MP", "")
 	require.NoError(t, err)
 	assert.Equal(t, "MP", p.ID)
 	assert.Equal(t, "My Project", p.Name)
 }
 
+func TestCloudStore_Ping(t *testing.T) {
+	status := http.StatusOK
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/orgs", r.URL.Path)
+		w.Header().Set("X-Compass-Version", "1.4.2")
+		jsonResponse(w, status, map[string]any{"data": []any{}})
+	})
+	defer srv.Close()
+
+	res, err := cs.Ping(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.2", res.Version)
+
+	status = http.StatusUnauthorized
+	_, err = cs.Ping(t.Context())
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	srv.Close()
+	_, err = cs.Ping(t.Context())
+	assert.Error(t, err)
+}
+
 func TestCloudStore_GetProject(t *testing.T) {
 	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "GET", r.Method)
@@ -69,7 +100,7 @@ func TestCloudStore_GetProject(t *testing.T) {
 	})
 	defer srv.Close()
 
-	p, body, err := cs.GetProject("MP")
+	p, body, err := cs.GetProject(t.Context(), "MP")
 	require.NoError(t, err)
 	assert.Equal(t, "MP", p.ID)
 	assert.Equal(t, "project body", body)
@@ -86,7 +117,7 @@ func TestCloudStore_ListProjects(t *testing.T) {
 	})
 	defer srv.Close()
 
-	projects, err := cs.ListProjects()
+	projects, err := cs.ListProjects(t.Context())
 	require.NoError(t, err)
 	assert.Len(t, projects, 2)
 	assert.Equal(t, "P1", projects[0].ID)
@@ -101,7 +132,7 @@ func TestCloudStore_DeleteProject(t *testing.T) {
 	})
 	defer srv.Close()
 
-	err := cs.DeleteProject("MP")
+	err := cs.DeleteProject(t.Context(), "MP")
 	require.NoError(t, err)
 }
 
@@ -128,7 +159,7 @@ func TestCloudStore_CreateTask(t *testing.T) {
 	})
 	defer srv.Close()
 
-	task, err := cs.CreateTask("My Task", "MP", TaskCreateOpts{})
+	task, err := cs.CreateTask(t.Context(), "My Task", "MP", TaskCreateOpts{})
 	require.NoError(t, err)
 	assert.Equal(t, "MP-TABCDE", task.ID)
 	assert.Equal(t, "My Task", task.Title)
@@ -153,12 +184,46 @@ func TestCloudStore_GetTask(t *testing.T) {
 	})
 	defer srv.Close()
 
-	task, body, err := cs.GetTask("MP-TABCDE")
+	task, body, err := cs.GetTask(t.Context(), "MP-TABCDE")
 	require.NoError(t, err)
 	assert.Equal(t, "MP-TABCDE", task.ID)
 	assert.Equal(t, "task body", body)
 }
 
+func TestCloudStore_Timestamps(t *testing.T) {
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		task := map[string]any{
+			"task_id":    "uuid-task",
+			"key":        "MP-TABCDE",
+			"title":      "My Task",
+			"type":       "task",
+			"status":     "open",
+			"created_by": "alice",
+			"created_at": "2026-01-01T00:00:00Z",
+			"updated_at": "2026-02-01T00:00:00Z",
+			"deleted_at": "2026-03-01T00:00:00Z",
+		}
+		if r.URL.Path == "/tasks/MP-TOLDER" {
+			// Older servers don't send updated_at.
+			task = map[string]any{"key": "MP-TOLDER", "title": "Old", "type": "task", "status": "open", "created_at": "2026-01-01T00:00:00Z"}
+		}
+		jsonResponse(w, 200, map[string]any{"data": task})
+	})
+	defer srv.Close()
+
+	task, _, err := cs.GetTask(t.Context(), "MP-TABCDE")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", task.CreatedBy)
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), task.UpdatedAt)
+	require.NotNil(t, task.DeletedAt)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), *task.DeletedAt)
+
+	task, _, err = cs.GetTask(t.Context(), "MP-TOLDER")
+	require.NoError(t, err)
+	assert.Equal(t, task.CreatedAt, task.UpdatedAt)
+	assert.Nil(t, task.DeletedAt)
+}
+
 func TestCloudStore_UpdateTask(t *testing.T) {
 	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "GET" {
@@ -199,7 +264,7 @@ func TestCloudStore_UpdateTask(t *testing.T) {
 	defer srv.Close()
 
 	s := model.StatusInProgress
-	task, err := cs.UpdateTask("MP-TABCDE", TaskUpdate{Status: &s})
+	task, err := cs.UpdateTask(t.Context(), "MP-TABCDE", TaskUpdate{Status: &s})
 	require.NoError(t, err)
 	assert.Equal(t, model.StatusInProgress, task.Status)
 }
@@ -222,7 +287,7 @@ func TestCloudStore_UpdateTask_EpicStatusRejected(t *testing.T) {
 	defer srv.Close()
 
 	s := model.StatusInProgress
-	_, err := cs.UpdateTask("MP-TEPIC1", TaskUpdate{Status: &s})
+	_, err := cs.UpdateTask(t.Context(), "MP-TEPIC1", TaskUpdate{Status: &s})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "epics do not have a status")
 }
@@ -235,7 +300,7 @@ func TestCloudStore_DeleteTask(t *testing.T) {
 	})
 	defer srv.Close()
 
-	err := cs.DeleteTask("MP-TABCDE")
+	err := cs.DeleteTask(t.Context(), "MP-TABCDE")
 	require.NoError(t, err)
 }
 
@@ -251,12 +316,40 @@ func TestCloudStore_ListTasks(t *testing.T) {
 	})
 	defer srv.Close()
 
-	tasks, err := cs.ListTasks(TaskFilter{ProjectID: "MP"})
+	tasks, err := cs.ListTasks(t.Context(), TaskFilter{ProjectID: "MP"})
 	require.NoError(t, err)
 	assert.Len(t, tasks, 2)
 	assert.Equal(t, "MP-T00001", tasks[0].ID)
 }
 
+func TestCloudStore_ListTasksPage(t *testing.T) {
+	var queries []url.Values
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		queries = append(queries, q)
+		// One task per response, so the client must follow the cursor.
+		task := map[string]any{"task_id": "uuid", "key": "MP-T0000" + q.Get("limit"), "title": "T", "type": "task", "status": "open", "created_at": "2026-01-01T00:00:00Z"}
+		jsonResponse(w, 200, map[string]any{"data": []any{task}, "next_cursor": "c" + q.Get("limit")})
+	})
+	defer srv.Close()
+
+	tasks, next, err := cs.ListTasksPage(t.Context(), TaskFilter{ProjectID: "MP", Status: model.StatusOpen}, PageOpts{Sort: "-priority", Limit: 3, Offset: 10})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 3)
+	require.Len(t, queries, 3)
+	assert.Equal(t, "-priority", queries[0].Get("sort"))
+	assert.Equal(t, "open", queries[0].Get("status"))
+	assert.Equal(t, "10", queries[0].Get("offset"))
+	assert.Equal(t, "3", queries[0].Get("limit"))
+	assert.Equal(t, "2", queries[1].Get("limit"))
+	assert.Equal(t, "c3", queries[1].Get("cursor"))
+	assert.Empty(t, queries[1].Get("offset"))
+	assert.Equal(t, "c1", next)
+
+	_, _, err = cs.ListTasksPage(t.Context(), TaskFilter{ProjectID: "MP"}, PageOpts{Sort: "size"})
+	assert.Error(t, err)
+}
+
 func TestCloudStore_ReadyTasks(t *testing.T) {
 	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/projects/MP/tasks/ready", r.URL.Path)
@@ -268,7 +361,7 @@ func TestCloudStore_ReadyTasks(t *testing.T) {
 	})
 	defer srv.Close()
 
-	ready, err := cs.ReadyTasks("MP")
+	ready, err := cs.ReadyTasks(t.Context(), "MP")
 	require.NoError(t, err)
 	assert.Len(t, ready, 1)
 	assert.Equal(t, "Ready Task", ready[0].Title)
@@ -291,12 +384,107 @@ func TestCloudStore_CreateDocument(t *testing.T) {
 	})
 	defer srv.Close()
 
-	d, err := cs.CreateDocument("My Doc", "MP", "doc body")
+	d, err := cs.CreateDocument(t.Context(), "My Doc", "MP", DocumentCreateOpts{Body: "doc body"})
 	require.NoError(t, err)
 	assert.Equal(t, "MP-DABCDE", d.ID)
 	assert.Equal(t, "MP", d.Project)
 }
 
+func TestCloudStore_UpdateDocument_IfUnchanged(t *testing.T) {
+	var precondition string
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		doc := map[string]any{"key": "MP-DABCDE", "title": "Doc", "body": "old", "created_at": "2026-01-01T00:00:00Z", "updated_at": "2026-01-02T00:00:00Z"}
+		if r.Method == "PATCH" {
+			precondition = r.Header.Get("If-Unmodified-Since")
+		}
+		jsonResponse(w, 200, map[string]any{"data": doc})
+	})
+	defer srv.Close()
+
+	body := "new"
+	read := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	_, err := cs.UpdateDocument(t.Context(), "MP-DABCDE", DocumentUpdate{Body: &body, IfUnchanged: &DocumentVersion{UpdatedAt: read, Body: "old"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Fri, 02 Jan 2026 00:00:00 GMT", precondition)
+
+	precondition = ""
+	_, err = cs.UpdateDocument(t.Context(), "MP-DABCDE", DocumentUpdate{Body: &body, IfUnchanged: &DocumentVersion{UpdatedAt: read, Body: "stale"}})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Empty(t, precondition, "nothing is sent")
+}
+
+func TestCloudStore_SimilarTasks(t *testing.T) {
+	var gets []string
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		gets = append(gets, r.URL.Path)
+		switch r.URL.Path {
+		case "/tasks/MP-T00001":
+			jsonResponse(w, 200, map[string]any{"data": map[string]any{"key": "MP-T00001", "title": "Login page", "type": "task", "status": "open", "project_key": "MP", "body": "OAuth redirect", "created_at": "2026-01-01T00:00:00Z"}})
+		case "/projects/MP/tasks":
+			jsonResponse(w, 200, map[string]any{
+				"data": []map[string]any{
+					{"key": "MP-T00001", "title": "Login page", "type": "task", "status": "open", "body": "OAuth redirect", "created_at": "2026-01-01T00:00:00Z"},
+					{"key": "MP-T00002", "title": "Billing", "type": "task", "status": "open", "body": "Fix the OAuth redirect loop", "created_at": "2026-01-01T00:00:00Z"},
+				},
+			})
+		case "/projects/MP/documents":
+			jsonResponse(w, 200, map[string]any{
+				"data": []map[string]any{
+					{"key": "MP-DAAAAA", "title": "Deploys", "body": "Nothing in common", "created_at": "2026-01-01T00:00:00Z"},
+				},
+			})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	})
+	defer srv.Close()
+
+	similar, err := SimilarTasks(t.Context(), cs, "MP-T00001", 0)
+	require.NoError(t, err)
+	require.Len(t, similar, 1)
+	assert.Equal(t, "MP-T00002", similar[0].ID, "scored on the listed body")
+	assert.NotContains(t, gets, "/tasks/MP-T00002", "bodies come from the listing")
+	assert.NotContains(t, gets, "/documents/MP-DAAAAA")
+}
+
+func TestCloudStore_ListDocumentsFilter(t *testing.T) {
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects/MP/documents":
+			jsonResponse(w, 200, map[string]any{
+				"data": []map[string]any{
+					{"key": "MP-DAAAAA", "title": "Login spec", "created_by": "alice", "created_at": "2026-01-01T00:00:00Z"},
+					{"key": "MP-DBBBBB", "title": "Deploys", "created_by": "bob", "created_at": "2026-03-01T00:00:00Z"},
+					{"key": "MP-DCCCCC", "title": "OAuth notes", "created_by": "bob", "created_at": "2026-03-02T00:00:00Z"},
+				},
+			})
+		case "/search":
+			assert.Equal(t, "oauth", r.URL.Query().Get("q"))
+			assert.Equal(t, "document", r.URL.Query().Get("type"))
+			jsonResponse(w, 200, map[string]any{
+				"data": []map[string]any{
+					{"type": "document", "id": "MP-DAAAAA", "title": "Login spec"},
+					{"type": "document", "id": "MP-DCCCCC", "title": "OAuth notes"},
+				},
+			})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	})
+	defer srv.Close()
+
+	since := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	docs, err := cs.ListDocuments(t.Context(), DocumentFilter{ProjectID: "MP", CreatedBy: "bob", Since: since, Search: "oauth"})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "MP-DCCCCC", docs[0].ID)
+
+	docs, _, err = cs.ListDocumentsPage(t.Context(), DocumentFilter{ProjectID: "MP", CreatedBy: "bob"}, PageOpts{Sort: "-created", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "MP-DCCCCC", docs[0].ID)
+}
+
 func TestCloudStore_Search(t *testing.T) {
 	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "auth", r.URL.Query().Get("q"))
@@ -308,12 +496,52 @@ func TestCloudStore_Search(t *testing.T) {
 	})
 	defer srv.Close()
 
-	results, err := cs.Search("auth", "")
+	results, err := cs.Search(t.Context(), "auth", SearchOpts{})
 	require.NoError(t, err)
 	assert.Len(t, results, 1)
 	assert.Equal(t, "Auth Task", results[0].Title)
 }
 
+func TestCloudStore_SearchOpts(t *testing.T) {
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "true", q.Get("regex"))
+		assert.Equal(t, "title", q.Get("fields"))
+		assert.Equal(t, "document", q.Get("type"))
+		assert.Equal(t, "MP", q.Get("project"))
+		jsonResponse(w, 200, map[string]any{
+			"data": []map[string]any{
+				{"type": "document", "id": "MP-DABCDE", "title": "Auth Doc"},
+				{"type": "task", "id": "MP-TABCDE", "title": "Auth Task"},
+			},
+		})
+	})
+	defer srv.Close()
+
+	results, err := cs.Search(t.Context(), "^auth", SearchOpts{ProjectID: "MP", Regex: true, TitleOnly: true, Type: "document"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Auth Doc", results[0].Title)
+}
+
+func TestCloudStore_ActorHeader(t *testing.T) {
+	var got []string
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("X-Compass-Actor"))
+		jsonResponse(w, 200, map[string]any{"data": []any{}})
+	})
+	defer srv.Close()
+	t.Setenv("COMPASS_ACTOR", "")
+	t.Cleanup(func() { SetActor("") })
+
+	_, err := cs.Search(t.Context(), "x", SearchOpts{})
+	require.NoError(t, err)
+	SetActor("claude-code")
+	_, err = cs.Search(t.Context(), "x", SearchOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "claude-code"}, got)
+}
+
 func TestCloudStore_APIError(t *testing.T) {
 	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
 		jsonResponse(w, 404, map[string]any{
@@ -325,9 +553,15 @@ func TestCloudStore_APIError(t *testing.T) {
 	})
 	defer srv.Close()
 
-	_, _, err := cs.GetProject("ZZZZ")
+	_, _, err := cs.GetProject(t.Context(), "ZZZZ")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "Project not found")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	for status, kind := range map[int]error{409: ErrConflict, 422: ErrValidation, 403: ErrUnauthorized} {
+		assert.ErrorIs(t, &APIError{Status: status}, kind)
+	}
+	assert.NotErrorIs(t, &APIError{Status: 500}, ErrNotFound)
 }
 
 func TestCloudStore_DownloadTask(t *testing.T) {
@@ -348,7 +582,7 @@ func TestCloudStore_DownloadTask(t *testing.T) {
 	defer srv.Close()
 
 	destDir := t.TempDir()
-	localPath, err := cs.DownloadEntity("MP-TABCDE", destDir)
+	localPath, err := cs.DownloadEntity(t.Context(), "MP-TABCDE", destDir)
 	require.NoError(t, err)
 	assert.FileExists(t, localPath)
 	assert.Contains(t, localPath, "MP-TABCDE.md")
@@ -377,7 +611,7 @@ func TestCloudStore_DownloadDocument(t *testing.T) {
 	defer srv.Close()
 
 	destDir := t.TempDir()
-	localPath, err := cs.DownloadEntity("MP-DABCDE", destDir)
+	localPath, err := cs.DownloadEntity(t.Context(), "MP-DABCDE", destDir)
 	require.NoError(t, err)
 	assert.FileExists(t, localPath)
 
@@ -420,7 +654,7 @@ func TestCloudStore_UploadTask(t *testing.T) {
 	localPath := destDir + "/MP-TABCDE.md"
 	require.NoError(t, cs.WriteEntity(localPath, task, "updated body"))
 
-	result, err := cs.UploadTask(localPath)
+	result, err := cs.UploadTask(t.Context(), localPath)
 	require.NoError(t, err)
 	assert.Equal(t, "MP-TABCDE", result.ID)
 	assert.Equal(t, "Updated Title", patchBody["title"])
@@ -435,3 +669,336 @@ func TestCloudStore_ResolveEntityPath_Unsupported(t *testing.T) {
 	_, err := cs.ResolveEntityPath("MP-TABCDE")
 	assert.Error(t, err)
 }
+
+func TestCloudStore_CreateRelease(t *testing.T) {
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/projects/MP/releases", r.URL.Path)
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "1.0.0", body["version"])
+		assert.Equal(t, "2026-03-01", body["target_date"])
+		assert.Equal(t, []any{"MP-TABCDE"}, body["items"])
+
+		jsonResponse(w, 201, map[string]any{
+			"data": map[string]any{
+				"release_id":  "uuid-rel",
+				"key":         "MP-RABCDE",
+				"version":     "1.0.0",
+				"status":      "planned",
+				"target_date": "2026-03-01",
+				"items":       []string{"MP-TABCDE"},
+				"created_at":  "2026-01-01T00:00:00Z",
+			},
+		})
+	})
+	defer srv.Close()
+
+	r, err := cs.CreateRelease(t.Context(), "1.0.0", "MP", ReleaseCreateOpts{TargetDate: "2026-03-01", Items: []string{"MP-TABCDE"}})
+	require.NoError(t, err)
+	assert.Equal(t, "MP-RABCDE", r.ID)
+	assert.Equal(t, "MP", r.Project)
+	assert.Equal(t, model.ReleasePlanned, r.Status)
+}
+
+func TestCloudStore_UpdateProject(t *testing.T) {
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "/projects/MP", r.URL.Path)
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, map[string]any{"body": "New overview", "auto_close_epics": true}, body)
+
+		jsonResponse(w, 200, map[string]any{
+			"data": map[string]any{
+				"project_id":       "uuid-proj",
+				"key":              "MP",
+				"name":             "My Project",
+				"body":             "New overview",
+				"auto_close_epics": true,
+				"created_at":       "2026-01-01T00:00:00Z",
+			},
+		})
+	})
+	defer srv.Close()
+
+	text, on := "New overview", true
+	p, err := cs.UpdateProject(t.Context(), "MP", ProjectUpdate{Body: &text, AutoCloseEpics: &on})
+	require.NoError(t, err)
+	assert.Equal(t, "MP", p.ID)
+	assert.True(t, p.AutoCloseEpics)
+}
+
+func TestCloudStore_UpdateTask_AutoClose(t *testing.T) {
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "/tasks/MP-TABCDE", r.URL.Path)
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, map[string]any{"auto_close": false}, body)
+
+		jsonResponse(w, 200, map[string]any{
+			"data": map[string]any{
+				"task_id":    "uuid-task",
+				"key":        "MP-TABCDE",
+				"title":      "My Epic",
+				"type":       "epic",
+				"auto_close": false,
+				"created_at": "2026-01-01T00:00:00Z",
+			},
+		})
+	})
+	defer srv.Close()
+
+	off := false
+	task, err := cs.UpdateTask(t.Context(), "MP-TABCDE", TaskUpdate{AutoClose: &off})
+	require.NoError(t, err)
+	require.NotNil(t, task.AutoClose)
+	assert.False(t, *task.AutoClose)
+}
+
+func TestCloudStore_UpdateTask_Waiting(t *testing.T) {
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		waiting, ok := body["waiting_on"].(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, "vendor reply", waiting["description"])
+		assert.Equal(t, "2026-03-01", waiting["until"])
+
+		jsonResponse(w, 200, map[string]any{
+			"data": map[string]any{
+				"task_id":    "uuid-task",
+				"key":        "MP-TABCDE",
+				"title":      "My Task",
+				"type":       "task",
+				"status":     "open",
+				"waiting_on": map[string]any{"description": "vendor reply", "until": "2026-03-01"},
+				"created_at": "2026-01-01T00:00:00Z",
+			},
+		})
+	})
+	defer srv.Close()
+
+	wo := &model.WaitingOn{Description: "vendor reply", Until: "2026-03-01"}
+	task, err := cs.UpdateTask(t.Context(), "MP-TABCDE", TaskUpdate{Waiting: &wo})
+	require.NoError(t, err)
+	require.NotNil(t, task.Waiting)
+	assert.Equal(t, "vendor reply", task.Waiting.Description)
+	assert.Equal(t, "2026-03-01", task.Waiting.Until)
+}
+
+func TestCloudStore_ClaimTask(t *testing.T) {
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/projects/MP/tasks/claim", r.URL.Path)
+		jsonResponse(w, 200, map[string]any{
+			"data": map[string]any{
+				"task_id":    "uuid-task",
+				"key":        "MP-TABCDE",
+				"title":      "My Task",
+				"type":       "task",
+				"status":     "in_progress",
+				"created_at": "2026-01-01T00:00:00Z",
+			},
+		})
+	})
+	defer srv.Close()
+
+	task, err := cs.ClaimTask(t.Context(), "MP")
+	require.NoError(t, err)
+	require.NotNil(t, task)
+	assert.Equal(t, "MP-TABCDE", task.ID)
+	assert.Equal(t, model.StatusInProgress, task.Status)
+}
+
+func TestCloudStore_ClaimTask_NoneReady(t *testing.T) {
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer srv.Close()
+
+	task, err := cs.ClaimTask(t.Context(), "MP")
+	require.NoError(t, err)
+	assert.Nil(t, task)
+}
+
+func tlsStoreConfig(t *testing.T, srv *httptest.Server) config.CloudStoreConfig {
+	t.Helper()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	return config.CloudStoreConfig{Hostname: u.Host, Protocol: "https", APIKey: "k"}
+}
+
+func emptyProjectList(w http.ResponseWriter, r *http.Request) {
+	jsonResponse(w, 200, map[string]any{"data": []map[string]any{}})
+}
+
+func TestCloudStore_CAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(emptyProjectList))
+	defer srv.Close()
+	sc := tlsStoreConfig(t, srv)
+
+	// Untrusted self-signed certificate fails by default.
+	cs, err := NewCloudStoreFromConfig("test", sc)
+	require.NoError(t, err)
+	_, err = cs.ListProjects(t.Context())
+	require.Error(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, pemData, 0644))
+	sc.CAFile = caFile
+
+	cs, err = NewCloudStoreFromConfig("test", sc)
+	require.NoError(t, err)
+	_, err = cs.ListProjects(t.Context())
+	require.NoError(t, err)
+}
+
+func TestCloudStore_InsecureSkipVerify(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(emptyProjectList))
+	defer srv.Close()
+	sc := tlsStoreConfig(t, srv)
+	sc.InsecureSkipVerify = true
+
+	cs, err := NewCloudStoreFromConfig("test", sc)
+	require.NoError(t, err)
+	_, err = cs.ListProjects(t.Context())
+	require.NoError(t, err)
+}
+
+func TestCloudStore_Proxy(t *testing.T) {
+	var proxiedHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.URL.Host
+		emptyProjectList(w, r)
+	}))
+	defer proxy.Close()
+
+	sc := config.CloudStoreConfig{Hostname: "compass.internal", Protocol: "http", APIKey: "k", Proxy: proxy.URL}
+	cs, err := NewCloudStoreFromConfig("test", sc)
+	require.NoError(t, err)
+	_, err = cs.ListProjects(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "compass.internal", proxiedHost)
+}
+
+func TestNewHTTPClient_InvalidSettings(t *testing.T) {
+	_, err := NewHTTPClient(config.CloudStoreConfig{Proxy: "not a url"})
+	assert.ErrorContains(t, err, "invalid proxy URL")
+
+	_, err = NewHTTPClient(config.CloudStoreConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.ErrorContains(t, err, "reading CA bundle")
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("nothing here"), 0644))
+	_, err = NewHTTPClient(config.CloudStoreConfig{CAFile: empty})
+	assert.ErrorContains(t, err, "no certificates")
+}
+
+func TestCloudStore_Unauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, 401, map[string]any{"error": map[string]any{"code": "unauthorized", "message": "invalid api key"}})
+	}))
+	defer srv.Close()
+
+	cs := NewCloudStoreWithBase(srv.URL, "expired")
+	_, err := cs.ListProjects(t.Context())
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	u, _ := url.Parse(srv.URL)
+	cs, err = NewCloudStoreFromConfig("work", config.CloudStoreConfig{Hostname: u.Host, Protocol: "http", APIKey: "expired"})
+	require.NoError(t, err)
+	_, _, err = cs.GetProject(t.Context(), "MP")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.Contains(t, err.Error(), "compass store login work")
+}
+
+func TestCloudStore_RateLimit(t *testing.T) {
+	var calls int
+	var bodies []string
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.Method {
+		case "POST":
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			if calls == 1 {
+				w.Header().Set("Retry-After", "2")
+				jsonResponse(w, 429, map[string]any{"error": map[string]any{"message": "slow down"}})
+				return
+			}
+			jsonResponse(w, 201, map[string]any{"data": map[string]any{"project_id": "u", "key": "MP", "name": "My Project"}})
+		default:
+			// The first page uses up the window; the second must wait for it.
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "5")
+			next := ""
+			if r.URL.Query().Get("cursor") == "" {
+				next = "c2"
+			}
+			jsonResponse(w, 200, map[string]any{"data": []any{}, "next_cursor": next})
+		}
+	})
+	defer srv.Close()
+	var notices []string
+	var waits []time.Duration
+	cs.notice = func(msg string) { notices = append(notices, msg) }
+	cs.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	_, err := cs.CreateProject(t.Context(), "My Project", "MP", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	require.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1], "the retry resends the body")
+	assert.Equal(t, []time.Duration{2 * time.Second}, waits)
+	host := strings.TrimPrefix(srv.URL, "http://")
+	assert.Equal(t, []string{"Rate limited by " + host + ", retrying in 2s"}, notices)
+
+	waits, notices = nil, nil
+	_, err = cs.ListProjects(t.Context())
+	require.NoError(t, err)
+	require.Len(t, waits, 1)
+	assert.InDelta(t, 5*time.Second, waits[0], float64(time.Second))
+	require.Len(t, notices, 1)
+	assert.Contains(t, notices[0], "Rate limit reached on "+host)
+
+	// A server that keeps refusing, or wants too long a wait, gives up.
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(429)
+	})
+	cs.limit = rateLimit{}
+	_, _, err = cs.GetProject(t.Context(), "MP")
+	assert.ErrorIs(t, err, ErrRateLimited)
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(429)
+	})
+	_, _, err = cs.GetProject(t.Context(), "MP")
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Contains(t, err.Error(), "try again in 1h0m0s")
+}
+
+func TestCloudStore_Cancel(t *testing.T) {
+	// Cancelling stops a listing mid-pagination.
+	ctx, cancel := context.WithCancel(t.Context())
+	pages := 0
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		if pages++; pages == 3 {
+			cancel()
+		}
+		jsonResponse(w, 200, map[string]any{"data": []any{}, "next_cursor": "more"})
+	})
+	defer srv.Close()
+
+	_, err := cs.ListProjects(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 3, pages)
+}
@@ -1,20 +1,78 @@
 package store
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/rogersnm/compass/internal/id"
 	"github.com/rogersnm/compass/internal/model"
 )
 
-func (s *LocalStore) CreateDocument(title, projectID, body string) (*model.Document, error) {
-	if _, _, err := s.GetProject(projectID); err != nil {
-		return nil, fmt.Errorf("project %s not found", projectID)
+type DocumentCreateOpts struct {
+	Kind model.DocKind
+	Slug string
+	Body string
+}
+
+type DocumentUpdate struct {
+	Title *string
+	Slug  *string // "" clears
+	// Kind recategorizes the document. Becoming an ADR assigns the next
+	// number; leaving it drops the number and status.
+	Kind         *model.DocKind
+	Status       *model.ADRStatus
+	Supersedes   *string
+	SupersededBy *string
+	Body         *string
+	// IfUnchanged makes the update fail with ErrConflict when the document
+	// is no longer as it was read, so a read-modify-write edit doesn't
+	// overwrite a change made in between.
+	IfUnchanged *DocumentVersion
+}
+
+// DocumentVersion is a document as it was read: its last update and body.
+type DocumentVersion struct {
+	UpdatedAt time.Time
+	Body      string
+}
+
+// matches reports whether a document updated at updatedAt with body is
+// still version v.
+func (v *DocumentVersion) matches(updatedAt time.Time, body string) bool {
+	return v.UpdatedAt.Equal(updatedAt) && v.Body == body
+}
+
+// applyDocKind sets d's kind and the ADR fields that go with it. A new ADR
+// takes the next number among the project's documents, which list returns,
+// and starts out proposed.
+func applyDocKind(d *model.Document, kind model.DocKind, list func() ([]model.Document, error)) error {
+	d.Kind = kind
+	if kind != model.DocADR {
+		d.Number, d.Status, d.Supersedes, d.SupersededBy = 0, "", "", ""
+		return nil
+	}
+	if d.Number == 0 {
+		docs, err := list()
+		if err != nil {
+			return err
+		}
+		d.Number = model.NextADRNumber(docs)
+	}
+	if d.Status == "" {
+		d.Status = model.ADRProposed
 	}
+	return nil
+}
 
-	did, err := id.NewDocID(projectID)
+func (s *LocalStore) CreateDocument(ctx context.Context, title, projectID string, opts DocumentCreateOpts) (*model.Document, error) {
+	if _, _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, notFoundf("project %s not found", projectID)
+	}
+
+	did, err := s.newID(ctx, projectID, id.Document)
 	if err != nil {
 		return nil, err
 	}
@@ -23,37 +81,84 @@ func (s *LocalStore) CreateDocument(title, projectID, body string) (*model.Docum
 		ID:        did,
 		Title:     title,
 		Project:   projectID,
-		CreatedBy: currentUser(),
+		Slug:      opts.Slug,
+		CreatedBy: CurrentUser(),
 		CreatedAt: now(),
 		UpdatedAt: now(),
 	}
+	list := func() ([]model.Document, error) { return s.ListDocuments(ctx, DocumentFilter{ProjectID: projectID}) }
+	if err := applyDocKind(d, opts.Kind, list); err != nil {
+		return nil, err
+	}
 	if err := d.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+	if err := checkSlug(ctx, s, projectID, d.Slug, ""); err != nil {
 		return nil, err
 	}
 
 	path := filepath.Join(s.ProjectDir(projectID), "documents", did+".md")
-	if err := s.WriteEntity(path, d, body); err != nil {
+	if err := s.WriteEntity(path, d, opts.Body); err != nil {
 		return nil, fmt.Errorf("writing document: %w", err)
 	}
 	return d, nil
 }
 
-func (s *LocalStore) GetDocument(docID string) (*model.Document, string, error) {
+func (s *LocalStore) GetDocument(ctx context.Context, docID string) (*model.Document, string, error) {
 	path, err := s.ResolveEntityPath(docID)
 	if err != nil {
 		return nil, "", err
 	}
-	d, body, err := ReadEntity[model.Document](path)
+	d, body, err := readEntity[model.Document](s, path)
 	if err != nil {
 		return nil, "", err
 	}
 	return &d, body, nil
 }
 
-func (s *LocalStore) ListDocuments(projectID string) ([]model.Document, error) {
+// DocumentFilter narrows a document listing. Zero fields match everything.
+type DocumentFilter struct {
+	ProjectID string
+	Kind      model.DocKind
+	CreatedBy string
+	// Since keeps documents updated at or after it.
+	Since time.Time
+	// Search keeps documents whose title or body contains it, ignoring
+	// case.
+	Search string
+}
+
+// narrowed reports whether the filter does more than pick a project.
+func (f DocumentFilter) narrowed() bool {
+	return f.Kind != "" || f.CreatedBy != "" || !f.Since.IsZero() || f.Search != ""
+}
+
+// match checks the conditions on a document's metadata; Search is left to
+// the caller, as it needs the body.
+func (f DocumentFilter) match(d *model.Document) bool {
+	return (f.Kind == "" || d.Kind == f.Kind) &&
+		(f.CreatedBy == "" || d.CreatedBy == f.CreatedBy) &&
+		!d.UpdatedAt.Before(f.Since)
+}
+
+// ListDocumentsPage lists a project's documents (all projects if empty),
+// sorted and windowed in memory.
+func (s *LocalStore) ListDocumentsPage(ctx context.Context, filter DocumentFilter, page PageOpts) ([]model.Document, string, error) {
+	docs, err := s.ListDocuments(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	return pageSlice(docs, page, documentSorts, DocumentSortFields)
+}
+
+func (s *LocalStore) ListDocuments(ctx context.Context, filter DocumentFilter) ([]model.Document, error) {
+	var search matcher
+	if filter.Search != "" {
+		search, _ = newMatcher(filter.Search, false)
+	}
 	var dirs []string
-	if projectID != "" {
-		dirs = []string{s.ProjectDir(projectID)}
+	if filter.ProjectID != "" {
+		dirs = []string{s.ProjectDir(filter.ProjectID)}
 	} else {
 		var err error
 		dirs, err = s.listProjectDirs()
@@ -70,37 +175,75 @@ func (s *LocalStore) ListDocuments(projectID string) ([]model.Document, error) {
 			continue
 		}
 		for _, f := range files {
-			doc, _, err := ReadEntity[model.Document](f)
-			if err != nil {
+			doc, body, err := readEntity[model.Document](s, f)
+			if err != nil || !filter.match(&doc) {
 				continue
 			}
+			if search != nil {
+				if i, _ := search(doc.Title); i < 0 {
+					if i, _ := search(body); i < 0 {
+						continue
+					}
+				}
+			}
 			docs = append(docs, doc)
 		}
 	}
 	return docs, nil
 }
 
-func (s *LocalStore) UpdateDocument(docID string, title, body *string) (*model.Document, error) {
+func (s *LocalStore) UpdateDocument(ctx context.Context, docID string, upd DocumentUpdate) (*model.Document, error) {
 	path, err := s.ResolveEntityPath(docID)
 	if err != nil {
 		return nil, err
 	}
+	unlock, err := lockEntity(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 	d, existingBody, err := ReadEntity[model.Document](path)
 	if err != nil {
 		return nil, err
 	}
+	if upd.IfUnchanged != nil && !upd.IfUnchanged.matches(d.UpdatedAt, existingBody) {
+		return nil, conflictf("document %s changed since it was read", docID)
+	}
 
-	if title != nil {
-		d.Title = *title
+	if upd.Title != nil {
+		d.Title = *upd.Title
+	}
+	if upd.Slug != nil {
+		d.Slug = *upd.Slug
+	}
+	if upd.Kind != nil {
+		list := func() ([]model.Document, error) { return s.ListDocuments(ctx, DocumentFilter{ProjectID: d.Project}) }
+		if err := applyDocKind(&d, *upd.Kind, list); err != nil {
+			return nil, err
+		}
+	}
+	if upd.Status != nil {
+		d.Status = *upd.Status
+	}
+	if upd.Supersedes != nil {
+		d.Supersedes = *upd.Supersedes
+	}
+	if upd.SupersededBy != nil {
+		d.SupersededBy = *upd.SupersededBy
 	}
 	finalBody := existingBody
-	if body != nil {
-		finalBody = *body
+	if upd.Body != nil {
+		finalBody = *upd.Body
 	}
 	d.UpdatedAt = now()
 
 	if err := d.Validate(); err != nil {
-		return nil, err
+		return nil, invalid(err)
+	}
+	if upd.Slug != nil {
+		if err := checkSlug(ctx, s, d.Project, d.Slug, d.ID); err != nil {
+			return nil, err
+		}
 	}
 	if err := s.WriteEntity(path, &d, finalBody); err != nil {
 		return nil, err
@@ -108,10 +251,16 @@ func (s *LocalStore) UpdateDocument(docID string, title, body *string) (*model.D
 	return &d, nil
 }
 
-func (s *LocalStore) DeleteDocument(docID string) error {
+func (s *LocalStore) DeleteDocument(ctx context.Context, docID string) error {
 	path, err := s.ResolveEntityPath(docID)
 	if err != nil {
 		return err
 	}
+	unlock, err := lockEntity(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	s.changed.Store(true)
 	return os.Remove(path)
 }
@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+
+	"github.com/rogersnm/compass/internal/id"
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// dryRunStore wraps a Store so that mutations report what they would do
+// instead of doing it: the files the local store would write or remove, or
+// the request a cloud store would send. Reads pass through, so commands
+// work out their changes from real data. The Registry wraps every store
+// with it under --dry-run.
+//
+// Mutations return the entity as it is now (or, for creates, as it would
+// be made), so commands carry on to their next change.
+type dryRunStore struct {
+	Store
+	out io.Writer
+}
+
+// entityPather is the local store, or a store built on one, such as the
+// daemon client.
+type entityPather interface {
+	entityPath(entityID string) (string, error)
+}
+
+// report prints "Would <what>: <change>", where the change is the request
+// for a cloud store and verb applied to file for a local one.
+func (d *dryRunStore) report(what, method, apiPath, verb, file string) {
+	change := verb + " " + file
+	if cs, ok := d.Store.(*CloudStore); ok {
+		change = method + " " + cs.apiBase + apiPath
+	}
+	fmt.Fprintf(d.out, "Would %s: %s\n", what, change)
+}
+
+// file returns the path of an entity in the local store, whether or not it
+// exists yet.
+func (d *dryRunStore) file(entityID string) string {
+	if p, ok := d.Store.(entityPather); ok {
+		if path, err := p.entityPath(entityID); err == nil {
+			return path
+		}
+	}
+	return entityID
+}
+
+// dir returns a project's directory in the local store.
+func (d *dryRunStore) dir(projectKey string) string {
+	return filepath.Dir(d.file(projectKey)) + string(filepath.Separator)
+}
+
+func (d *dryRunStore) CreateProject(ctx context.Context, name, key, body string) (*model.Project, error) {
+	if key == "" {
+		var err error
+		if key, err = id.GenerateKey(name); err != nil {
+			return nil, invalid(err)
+		}
+	}
+	d.report("create project "+key, "POST", "/projects", "write", d.file(key))
+	return &model.Project{ID: key, Name: name}, nil
+}
+
+func (d *dryRunStore) DeleteProject(ctx context.Context, projectID string) error {
+	if _, _, err := d.Store.GetProject(ctx, projectID); err != nil {
+		return err
+	}
+	what := "delete project " + projectID
+	tasks, err := d.Store.ListTasks(ctx, TaskFilter{ProjectID: projectID})
+	if err == nil {
+		docs, err := d.Store.ListDocuments(ctx, DocumentFilter{ProjectID: projectID})
+		if err == nil {
+			what += fmt.Sprintf(" with %d tasks and %d documents", len(tasks), len(docs))
+		}
+	}
+	d.report(what, "DELETE", "/projects/"+url.PathEscape(projectID), "remove", d.dir(projectID))
+	return nil
+}
+
+func (d *dryRunStore) UpdateProject(ctx context.Context, projectID string, upd ProjectUpdate) (*model.Project, error) {
+	p, _, err := d.Store.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	d.report("update project "+projectID, "PATCH", "/projects/"+url.PathEscape(projectID), "write", d.file(projectID))
+	return p, nil
+}
+
+func (d *dryRunStore) RekeyProject(ctx context.Context, oldKey, newKey string) (*model.Project, error) {
+	p, _, err := d.Store.GetProject(ctx, oldKey)
+	if err != nil {
+		return nil, err
+	}
+	d.report(fmt.Sprintf("rekey project %s to %s", oldKey, newKey), "PATCH", "/projects/"+url.PathEscape(oldKey),
+		"move", d.dir(oldKey)+" to "+d.dir(newKey))
+	return p, nil
+}
+
+func (d *dryRunStore) CreateTask(ctx context.Context, title, projectID string, opts TaskCreateOpts) (*model.Task, error) {
+	taskID, err := id.NewTaskID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	kind := "task"
+	if opts.Type == model.TypeEpic {
+		kind = "epic"
+	}
+	d.report(fmt.Sprintf("create %s %s %q", kind, taskID, title), "POST", "/projects/"+url.PathEscape(projectID)+"/tasks", "write", d.file(taskID))
+	return &model.Task{ID: taskID, Title: title, Project: projectID, Type: opts.Type, Priority: opts.Priority, Epic: opts.Epic, DependsOn: opts.DependsOn}, nil
+}
+
+func (d *dryRunStore) UpdateTask(ctx context.Context, taskID string, upd TaskUpdate) (*model.Task, error) {
+	t, _, err := d.Store.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	d.report("update task "+taskID, "PATCH", "/tasks/"+url.PathEscape(taskID), "write", d.file(taskID))
+	return t, nil
+}
+
+func (d *dryRunStore) DeleteTask(ctx context.Context, taskID string) error {
+	if _, _, err := d.Store.GetTask(ctx, taskID); err != nil {
+		return err
+	}
+	d.report("delete task "+taskID, "DELETE", "/tasks/"+url.PathEscape(taskID), "remove", d.file(taskID))
+	return nil
+}
+
+func (d *dryRunStore) MoveTask(ctx context.Context, taskID, projectID string) (*model.Task, error) {
+	t, _, err := d.Store.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	d.report(fmt.Sprintf("move task %s to %s", taskID, projectID), "POST", "/tasks/"+url.PathEscape(taskID)+"/move",
+		"move", d.file(taskID)+" to "+d.dir(projectID))
+	return t, nil
+}
+
+func (d *dryRunStore) ClaimTask(ctx context.Context, projectID string) (*model.Task, error) {
+	ready, err := d.Store.ReadyTasks(ctx, projectID)
+	if err != nil || len(ready) == 0 {
+		return nil, err
+	}
+	t := ready[0]
+	d.report("claim task "+t.ID, "POST", "/projects/"+url.PathEscape(projectID)+"/tasks/claim", "write", d.file(t.ID))
+	return t, nil
+}
+
+func (d *dryRunStore) CreateDocument(ctx context.Context, title, projectID string, opts DocumentCreateOpts) (*model.Document, error) {
+	docID, err := id.NewDocID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	d.report(fmt.Sprintf("create document %s %q", docID, title), "POST", "/projects/"+url.PathEscape(projectID)+"/documents", "write", d.file(docID))
+	return &model.Document{ID: docID, Title: title, Project: projectID}, nil
+}
+
+func (d *dryRunStore) UpdateDocument(ctx context.Context, docID string, upd DocumentUpdate) (*model.Document, error) {
+	doc, _, err := d.Store.GetDocument(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	d.report("update document "+docID, "PATCH", "/documents/"+url.PathEscape(docID), "write", d.file(docID))
+	return doc, nil
+}
+
+func (d *dryRunStore) DeleteDocument(ctx context.Context, docID string) error {
+	if _, _, err := d.Store.GetDocument(ctx, docID); err != nil {
+		return err
+	}
+	d.report("delete document "+docID, "DELETE", "/documents/"+url.PathEscape(docID), "remove", d.file(docID))
+	return nil
+}
+
+func (d *dryRunStore) CreateRelease(ctx context.Context, version, projectID string, opts ReleaseCreateOpts) (*model.Release, error) {
+	releaseID, err := id.NewReleaseID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	d.report(fmt.Sprintf("create release %s %s", releaseID, version), "POST", "/projects/"+url.PathEscape(projectID)+"/releases", "write", d.file(releaseID))
+	return &model.Release{ID: releaseID, Version: version, Project: projectID}, nil
+}
+
+func (d *dryRunStore) UpdateRelease(ctx context.Context, releaseID string, upd ReleaseUpdate) (*model.Release, error) {
+	r, _, err := d.Store.GetRelease(ctx, releaseID)
+	if err != nil {
+		return nil, err
+	}
+	d.report("update release "+releaseID, "PATCH", "/releases/"+url.PathEscape(releaseID), "write", d.file(releaseID))
+	return r, nil
+}
+
+func (d *dryRunStore) UploadTask(ctx context.Context, localPath string) (*model.Task, error) {
+	t, _, err := ReadEntity[model.Task](localPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading local file: %w", err)
+	}
+	d.report("upload task "+t.ID, "PATCH", "/tasks/"+url.PathEscape(t.ID), "write", d.file(t.ID))
+	return &t, nil
+}
+
+func (d *dryRunStore) UploadDocument(ctx context.Context, localPath string) (*model.Document, error) {
+	doc, _, err := ReadEntity[model.Document](localPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading local file: %w", err)
+	}
+	d.report("upload document "+doc.ID, "PATCH", "/documents/"+url.PathEscape(doc.ID), "write", d.file(doc.ID))
+	return &doc, nil
+}
+
+func (d *dryRunStore) WriteEntity(path string, meta any, body string) error {
+	fmt.Fprintf(d.out, "Would write %s\n", path)
+	return nil
+}
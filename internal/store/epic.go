@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// AdoptTasks sets epicID as the parent epic of every task in taskIDs. The epic
+// and all tasks are validated before any task is written, so a bad ID leaves
+// every task untouched. Tasks already under the epic are skipped.
+func AdoptTasks(ctx context.Context, s Store, epicID string, taskIDs []string) ([]*model.Task, error) {
+	epic, err := validateEpic(ctx, s, epicID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	var problems []string
+	seen := make(map[string]bool)
+	for _, tid := range taskIDs {
+		if seen[tid] {
+			continue
+		}
+		seen[tid] = true
+
+		t, _, err := s.GetTask(ctx, tid)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			problems = append(problems, fmt.Sprintf("%s: not found", tid))
+		case err != nil:
+			return nil, err
+		case t.Type == model.TypeEpic:
+			problems = append(problems, fmt.Sprintf("%s: is an epic", tid))
+		case t.Project != epic.Project:
+			problems = append(problems, fmt.Sprintf("%s: in project %s, not %s", tid, t.Project, epic.Project))
+		case t.Epic == epic.ID:
+			// already adopted
+		default:
+			pending = append(pending, t.ID)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, invalidf("cannot adopt into %s:\n  %s", epic.ID, strings.Join(problems, "\n  "))
+	}
+
+	adopted := make([]*model.Task, 0, len(pending))
+	for _, tid := range pending {
+		t, err := s.UpdateTask(ctx, tid, TaskUpdate{Epic: &epic.ID})
+		if err != nil {
+			return adopted, fmt.Errorf("adopting %s: %w", tid, err)
+		}
+		adopted = append(adopted, t)
+	}
+	return adopted, nil
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of error both stores return, for callers (and exit codes) that
+// need to tell them apart. Test with errors.Is; the messages stay specific,
+// such as "epic AUTH-TABCDE not found".
+var (
+	// ErrNotFound: the project or entity doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict: the change clashes with the current state, such as a
+	// key already taken or a release already cut.
+	ErrConflict = errors.New("conflict")
+	// ErrValidation: the request itself is invalid, such as a bad field
+	// value or a dependency on an epic.
+	ErrValidation = errors.New("invalid")
+	// ErrUnauthorized: a cloud store rejected the API key, typically
+	// because it expired or was revoked.
+	ErrUnauthorized = errors.New("API key expired or revoked")
+)
+
+// kindError marks err as one of the kinds above without changing its
+// message.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string   { return e.err.Error() }
+func (e *kindError) Unwrap() []error { return []error{e.kind, e.err} }
+
+func withKind(kind, err error) error {
+	if err == nil || errors.Is(err, kind) {
+		return err
+	}
+	return &kindError{kind, err}
+}
+
+func notFoundf(format string, a ...any) error {
+	return withKind(ErrNotFound, fmt.Errorf(format, a...))
+}
+
+func conflictf(format string, a ...any) error {
+	return withKind(ErrConflict, fmt.Errorf(format, a...))
+}
+
+func invalidf(format string, a ...any) error {
+	return withKind(ErrValidation, fmt.Errorf(format, a...))
+}
+
+// invalid marks a validation failure, such as from model Validate methods.
+func invalid(err error) error {
+	return withKind(ErrValidation, err)
+}
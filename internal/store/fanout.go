@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// FanOutTimeout bounds how long a command spanning every store waits for
+// any single store before reporting partial results.
+const FanOutTimeout = 5 * time.Second
+
+// StoreError records a store that failed or timed out during a fan-out.
+type StoreError struct {
+	Store string
+	Err   error
+}
+
+func (e StoreError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Store, e.Err)
+}
+
+// FanOut calls fn concurrently on every registered store (or only the store
+// named only, when non-empty) and waits at most timeout for each. Results
+// are keyed by store name; stores that fail or time out are returned as
+// errors, sorted by name, so callers can show partial results. fn's context
+// is cancelled at the timeout, or when ctx is, so stragglers stop.
+func FanOut[T any](ctx context.Context, r *Registry, only string, timeout time.Duration, fn func(ctx context.Context, s Store) (T, error)) (map[string]T, []StoreError, error) {
+	names := r.Names()
+	if only != "" {
+		if !r.has(only) {
+			return nil, nil, fmt.Errorf("store %q not configured", only)
+		}
+		names = []string{only}
+	}
+
+	// Construct lazily added stores up front; the registry is not safe for
+	// concurrent use.
+	var errs []StoreError
+	targets := make(map[string]Store, len(names))
+	for _, name := range names {
+		s, err := r.Get(name)
+		if err != nil {
+			errs = append(errs, StoreError{name, err})
+			continue
+		}
+		targets[name] = s
+	}
+
+	type outcome struct {
+		name string
+		val  T
+		err  error
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ch := make(chan outcome, len(targets))
+	for name, s := range targets {
+		go func() {
+			v, err := fn(ctx, s)
+			ch <- outcome{name, v, err}
+		}()
+	}
+
+	results := make(map[string]T, len(targets))
+	pending := make(map[string]bool, len(targets))
+	for name := range targets {
+		pending[name] = true
+	}
+	for len(pending) > 0 {
+		select {
+		case o := <-ch:
+			delete(pending, o.name)
+			if o.err != nil {
+				errs = append(errs, StoreError{o.name, o.err})
+			} else {
+				results[o.name] = o.val
+			}
+		case <-ctx.Done():
+			err := ctx.Err()
+			if errors.Is(err, context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s", timeout)
+			}
+			for name := range pending {
+				errs = append(errs, StoreError{name, err})
+			}
+			pending = nil
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Store < errs[j].Store })
+	return results, errs, nil
+}
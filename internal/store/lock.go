@@ -0,0 +1,58 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockTimeout = 10 * time.Second
+	lockStale   = 30 * time.Second
+	lockRetry   = 20 * time.Millisecond
+)
+
+// acquireLock takes an exclusive lock by creating path with O_EXCL, which is
+// atomic on every platform compass supports. It retries until lockTimeout and
+// breaks locks older than lockStale, which can only be left behind by a
+// crashed process. The returned func releases the lock.
+func acquireLock(path string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("acquiring lock %s: %w", path, err)
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, conflictf("timed out waiting for lock %s", path)
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// lockEntity takes the advisory lock for the entity file at path. Every
+// read-modify-write of an existing entity holds it, so two processes sharing
+// a data directory can't lose each other's updates.
+func lockEntity(path string) (func(), error) {
+	return acquireLock(path + ".lock")
+}
+
+// lockDataDir takes the data directory lock. Operations that rewrite or
+// remove a whole project hold it so they never interleave with each other.
+func (s *LocalStore) lockDataDir() (func(), error) {
+	if err := os.MkdirAll(s.BaseDir, 0755); err != nil {
+		return nil, err
+	}
+	return acquireLock(filepath.Join(s.BaseDir, ".lock"))
+}
@@ -0,0 +1,227 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// multiStore reads a project that the project cache maps to more than one
+// store, as "store fetch" does when two stores have the same key and the
+// user asks to see both. Listings are merged and lookups try each store in
+// turn. Changes are refused: which store they belong on is ambiguous, so
+// they need a store-qualified reference.
+type multiStore struct {
+	Store  // the first store, for anything not merged below
+	stores []Store
+	names  []string
+	key    string
+}
+
+func newMultiStore(key string, names []string, stores []Store) *multiStore {
+	return &multiStore{Store: stores[0], stores: stores, names: names, key: key}
+}
+
+func (m *multiStore) deny() error {
+	return fmt.Errorf("%w: %s is on %s; to change it, qualify it with one, as in %s:%s",
+		ErrReadOnly, m.key, strings.Join(m.names, " and "), m.names[0], m.key)
+}
+
+// first returns the first store's answer that isn't ErrNotFound.
+func first[T any](m *multiStore, get func(Store) (T, string, error)) (T, string, error) {
+	var (
+		v    T
+		path string
+		err  error
+	)
+	for _, s := range m.stores {
+		if v, path, err = get(s); err == nil || !errors.Is(err, ErrNotFound) {
+			return v, path, err
+		}
+	}
+	return v, path, err
+}
+
+// merged concatenates every store's listing, in store order.
+func merged[T any](m *multiStore, list func(Store) ([]T, error)) ([]T, error) {
+	var all []T
+	for i, s := range m.stores {
+		items, err := list(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.names[i], err)
+		}
+		all = append(all, items...)
+	}
+	return all, nil
+}
+
+func (m *multiStore) GetProject(ctx context.Context, projectID string) (*model.Project, string, error) {
+	return first(m, func(s Store) (*model.Project, string, error) { return s.GetProject(ctx, projectID) })
+}
+
+func (m *multiStore) ListProjects(ctx context.Context) ([]model.Project, error) {
+	return merged(m, func(s Store) ([]model.Project, error) { return s.ListProjects(ctx) })
+}
+
+func (m *multiStore) GetTask(ctx context.Context, taskID string) (*model.Task, string, error) {
+	return first(m, func(s Store) (*model.Task, string, error) { return s.GetTask(ctx, taskID) })
+}
+
+func (m *multiStore) ListTasks(ctx context.Context, filter TaskFilter) ([]model.Task, error) {
+	return merged(m, func(s Store) ([]model.Task, error) { return s.ListTasks(ctx, filter) })
+}
+
+func (m *multiStore) ListTasksPage(ctx context.Context, filter TaskFilter, page PageOpts) ([]model.Task, string, error) {
+	tasks, err := m.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	return PageTasks(tasks, page)
+}
+
+func (m *multiStore) AllTaskMap(ctx context.Context, projectID string) (map[string]*model.Task, error) {
+	all := map[string]*model.Task{}
+	for i, s := range m.stores {
+		tasks, err := s.AllTaskMap(ctx, projectID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.names[i], err)
+		}
+		for id, t := range tasks {
+			all[id] = t
+		}
+	}
+	return all, nil
+}
+
+func (m *multiStore) ReadyTasks(ctx context.Context, projectID string) ([]*model.Task, error) {
+	return merged(m, func(s Store) ([]*model.Task, error) { return s.ReadyTasks(ctx, projectID) })
+}
+
+func (m *multiStore) GetDocument(ctx context.Context, docID string) (*model.Document, string, error) {
+	return first(m, func(s Store) (*model.Document, string, error) { return s.GetDocument(ctx, docID) })
+}
+
+func (m *multiStore) ListDocuments(ctx context.Context, filter DocumentFilter) ([]model.Document, error) {
+	return merged(m, func(s Store) ([]model.Document, error) { return s.ListDocuments(ctx, filter) })
+}
+
+func (m *multiStore) ListDocumentsPage(ctx context.Context, filter DocumentFilter, page PageOpts) ([]model.Document, string, error) {
+	docs, err := m.ListDocuments(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	return PageDocuments(docs, page)
+}
+
+func (m *multiStore) GetRelease(ctx context.Context, releaseID string) (*model.Release, string, error) {
+	return first(m, func(s Store) (*model.Release, string, error) { return s.GetRelease(ctx, releaseID) })
+}
+
+func (m *multiStore) ListReleases(ctx context.Context, projectID string) ([]model.Release, error) {
+	return merged(m, func(s Store) ([]model.Release, error) { return s.ListReleases(ctx, projectID) })
+}
+
+func (m *multiStore) Search(ctx context.Context, query string, opts SearchOpts) ([]SearchResult, error) {
+	return merged(m, func(s Store) ([]SearchResult, error) { return s.Search(ctx, query, opts) })
+}
+
+func (m *multiStore) Bodies(ctx context.Context, projectID string) (map[string]string, error) {
+	all := map[string]string{}
+	for i, s := range m.stores {
+		bodies, err := s.Bodies(ctx, projectID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.names[i], err)
+		}
+		for id, body := range bodies {
+			all[id] = body
+		}
+	}
+	return all, nil
+}
+
+func (m *multiStore) ResolveEntityPath(entityID string) (string, error) {
+	_, path, err := first(m, func(s Store) (struct{}, string, error) {
+		path, err := s.ResolveEntityPath(entityID)
+		return struct{}{}, path, err
+	})
+	return path, err
+}
+
+func (m *multiStore) DownloadEntity(ctx context.Context, entityID, destDir string) (string, error) {
+	_, path, err := first(m, func(s Store) (struct{}, string, error) {
+		path, err := s.DownloadEntity(ctx, entityID, destDir)
+		return struct{}{}, path, err
+	})
+	return path, err
+}
+
+func (m *multiStore) CreateProject(ctx context.Context, name, key, body string) (*model.Project, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) DeleteProject(ctx context.Context, projectID string) error {
+	return m.deny()
+}
+
+func (m *multiStore) UpdateProject(ctx context.Context, projectID string, upd ProjectUpdate) (*model.Project, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) RekeyProject(ctx context.Context, oldKey, newKey string) (*model.Project, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) CreateTask(ctx context.Context, title, projectID string, opts TaskCreateOpts) (*model.Task, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) UpdateTask(ctx context.Context, taskID string, upd TaskUpdate) (*model.Task, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) DeleteTask(ctx context.Context, taskID string) error {
+	return m.deny()
+}
+
+func (m *multiStore) MoveTask(ctx context.Context, taskID, projectID string) (*model.Task, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) ClaimTask(ctx context.Context, projectID string) (*model.Task, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) CreateDocument(ctx context.Context, title, projectID string, opts DocumentCreateOpts) (*model.Document, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) UpdateDocument(ctx context.Context, docID string, upd DocumentUpdate) (*model.Document, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) DeleteDocument(ctx context.Context, docID string) error {
+	return m.deny()
+}
+
+func (m *multiStore) CreateRelease(ctx context.Context, version, projectID string, opts ReleaseCreateOpts) (*model.Release, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) UpdateRelease(ctx context.Context, releaseID string, upd ReleaseUpdate) (*model.Release, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) UploadTask(ctx context.Context, localPath string) (*model.Task, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) UploadDocument(ctx context.Context, localPath string) (*model.Document, error) {
+	return nil, m.deny()
+}
+
+func (m *multiStore) WriteEntity(path string, meta any, body string) error {
+	return m.deny()
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// PageOpts selects a sorted window of a listing. The zero value lists
+// everything in creation order.
+type PageOpts struct {
+	// Sort is a field name ("created", "updated", "title", "id", and for
+	// tasks "priority" and "status"), optionally prefixed with "-" to
+	// reverse the order.
+	Sort   string
+	Limit  int // 0 means no limit
+	Offset int
+	// Cursor continues from a previous page and takes precedence over
+	// Offset. It is opaque and only valid for the store that returned it.
+	Cursor string
+}
+
+type sortFunc[T any] func(a, b *T) int
+
+var taskSorts = map[string]sortFunc[model.Task]{
+	"id":      func(a, b *model.Task) int { return cmp.Compare(a.ID, b.ID) },
+	"title":   func(a, b *model.Task) int { return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)) },
+	"status":  func(a, b *model.Task) int { return cmp.Compare(a.Status, b.Status) },
+	"created": func(a, b *model.Task) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated": func(a, b *model.Task) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+	// Unprioritized tasks sort after P3.
+	"priority": func(a, b *model.Task) int { return cmp.Compare(priorityRank(a.Priority), priorityRank(b.Priority)) },
+}
+
+var documentSorts = map[string]sortFunc[model.Document]{
+	"id":      func(a, b *model.Document) int { return cmp.Compare(a.ID, b.ID) },
+	"title":   func(a, b *model.Document) int { return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)) },
+	"created": func(a, b *model.Document) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated": func(a, b *model.Document) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+func priorityRank(p *int) int {
+	if p == nil {
+		return 1 << 30
+	}
+	return *p
+}
+
+// TaskSortFields and DocumentSortFields list the accepted PageOpts.Sort
+// fields, for help text and validation.
+var (
+	TaskSortFields     = sortedKeys(taskSorts)
+	DocumentSortFields = sortedKeys(documentSorts)
+)
+
+func sortedKeys[T any](m map[string]sortFunc[T]) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (p PageOpts) validate(fields []string) error {
+	if p.Limit < 0 || p.Offset < 0 {
+		return invalidf("limit and offset must not be negative")
+	}
+	if field := strings.TrimPrefix(p.Sort, "-"); field != "" && !slices.Contains(fields, field) {
+		return invalidf("invalid sort field %q (valid: %s)", field, strings.Join(fields, ", "))
+	}
+	return nil
+}
+
+// pageSlice sorts items in memory and returns the requested window and the
+// cursor for the next one ("" when there are no more). Ties fall back to
+// creation order then ID so pages are stable.
+func pageSlice[T any](items []T, p PageOpts, sorts map[string]sortFunc[T], fields []string) ([]T, string, error) {
+	if err := p.validate(fields); err != nil {
+		return nil, "", err
+	}
+	field, desc := strings.TrimPrefix(p.Sort, "-"), strings.HasPrefix(p.Sort, "-")
+	if field == "" {
+		field = "created"
+	}
+	primary, created, id := sorts[field], sorts["created"], sorts["id"]
+	slices.SortStableFunc(items, func(a, b T) int {
+		c := primary(&a, &b)
+		if desc {
+			c = -c
+		}
+		if c == 0 {
+			c = created(&a, &b)
+		}
+		if c == 0 {
+			c = id(&a, &b)
+		}
+		return c
+	})
+
+	start := p.Offset
+	if p.Cursor != "" {
+		n, err := strconv.Atoi(p.Cursor)
+		if err != nil || n < 0 {
+			return nil, "", invalidf("invalid cursor %q", p.Cursor)
+		}
+		start = n
+	}
+	if start >= len(items) {
+		return nil, "", nil
+	}
+	end := len(items)
+	if p.Limit > 0 && start+p.Limit < end {
+		end = start + p.Limit
+	}
+	next := ""
+	if end < len(items) {
+		next = strconv.Itoa(end)
+	}
+	return items[start:end], next, nil
+}
+
+// PageTasks and PageDocuments sort and window a listing in memory, for
+// stores that list from elsewhere but page like LocalStore.
+func PageTasks(tasks []model.Task, page PageOpts) ([]model.Task, string, error) {
+	return pageSlice(tasks, page, taskSorts, TaskSortFields)
+}
+
+func PageDocuments(docs []model.Document, page PageOpts) ([]model.Document, string, error) {
+	return pageSlice(docs, page, documentSorts, DocumentSortFields)
+}
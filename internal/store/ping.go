@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ProbeTimeout bounds the health check the Registry runs before probing a
+// store for an uncached project, so a dead store costs seconds, not the
+// full request timeout.
+const ProbeTimeout = 2 * time.Second
+
+// PingResult reports a successful health check.
+type PingResult struct {
+	Latency time.Duration
+	Version string // server version, empty if the store doesn't report one
+}
+
+// Ping checks that the data directory is readable.
+func (s *LocalStore) Ping(ctx context.Context) (PingResult, error) {
+	start := time.Now()
+	if _, err := os.ReadDir(s.BaseDir); err != nil {
+		return PingResult{}, fmt.Errorf("reading %s: %w", s.BaseDir, err)
+	}
+	return PingResult{Latency: time.Since(start)}, nil
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// PlanResult lists the entities created from an outline, in creation order.
+type PlanResult struct {
+	Epics []*model.Task
+	Tasks []*model.Task
+}
+
+// CreatePlan creates the epics and tasks described by an outline in a single
+// project. Creation is all-or-nothing: if any entity fails to create, every
+// entity created so far is deleted and the original error is returned.
+func CreatePlan(ctx context.Context, s Store, projectID string, o *markdown.Outline) (*PlanResult, error) {
+	// Resolve every after: reference up front so bad outlines fail before
+	// anything is written.
+	deps := make([][]int, 0, o.TaskCount())
+	ext := make([][]string, 0, o.TaskCount())
+	pos := 0
+	for _, e := range o.Epics {
+		for _, t := range e.Tasks {
+			var idxs []int
+			var ids []string
+			for _, ref := range t.After {
+				idx, err := o.ResolveAfter(ref, pos)
+				if err != nil {
+					return nil, fmt.Errorf("task %q: %w", t.Title, err)
+				}
+				if idx < 0 {
+					ids = append(ids, ref)
+				} else {
+					idxs = append(idxs, idx)
+				}
+			}
+			deps = append(deps, idxs)
+			ext = append(ext, ids)
+			pos++
+		}
+	}
+
+	res := &PlanResult{}
+	rollback := func(cause error) error {
+		var failed []string
+		for i := len(res.Tasks) - 1; i >= 0; i-- {
+			if err := s.DeleteTask(ctx, res.Tasks[i].ID); err != nil {
+				failed = append(failed, res.Tasks[i].ID)
+			}
+		}
+		for i := len(res.Epics) - 1; i >= 0; i-- {
+			if err := s.DeleteTask(ctx, res.Epics[i].ID); err != nil {
+				failed = append(failed, res.Epics[i].ID)
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("%w (rollback failed, remove manually: %s)", cause, strings.Join(failed, ", "))
+		}
+		return cause
+	}
+
+	pos = 0
+	for _, e := range o.Epics {
+		epic, err := s.CreateTask(ctx, e.Title, projectID, TaskCreateOpts{Type: model.TypeEpic, Body: e.Body})
+		if err != nil {
+			return nil, rollback(fmt.Errorf("creating epic %q: %w", e.Title, err))
+		}
+		res.Epics = append(res.Epics, epic)
+
+		for _, t := range e.Tasks {
+			dependsOn := append([]string(nil), ext[pos]...)
+			for _, idx := range deps[pos] {
+				dependsOn = append(dependsOn, res.Tasks[idx].ID)
+			}
+			task, err := s.CreateTask(ctx, t.Title, projectID, TaskCreateOpts{
+				Epic:      epic.ID,
+				DependsOn: dependsOn,
+				Body:      t.Body,
+			})
+			if err != nil {
+				return nil, rollback(fmt.Errorf("creating task %q: %w", t.Title, err))
+			}
+			res.Tasks = append(res.Tasks, task)
+			pos++
+		}
+	}
+	return res, nil
+}
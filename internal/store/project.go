@@ -1,15 +1,17 @@
 package store
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/rogersnm/compass/internal/id"
 	"github.com/rogersnm/compass/internal/model"
 )
 
-func (s *LocalStore) CreateProject(name, key, body string) (*model.Project, error) {
+func (s *LocalStore) CreateProject(ctx context.Context, name, key, body string) (*model.Project, error) {
 	if key == "" {
 		generated, err := id.GenerateKey(name)
 		if err != nil {
@@ -30,7 +32,7 @@ func (s *LocalStore) CreateProject(name, key, body string) (*model.Project, erro
 				}
 			}
 			if !found {
-				return nil, fmt.Errorf("cannot auto-generate unique key for %q: all variants taken (use --key)", name)
+				return nil, invalidf("cannot auto-generate unique key for %q: all variants taken (use --key)", name)
 			}
 		}
 		key = candidate
@@ -39,19 +41,19 @@ func (s *LocalStore) CreateProject(name, key, body string) (*model.Project, erro
 			return nil, err
 		}
 		if s.projectKeyExists(key) {
-			return nil, fmt.Errorf("project key %q already exists", key)
+			return nil, conflictf("project key %q already exists", key)
 		}
 	}
 
 	p := &model.Project{
 		ID:        key,
 		Name:      name,
-		CreatedBy: currentUser(),
+		CreatedBy: CurrentUser(),
 		CreatedAt: now(),
 		UpdatedAt: now(),
 	}
 	if err := p.Validate(); err != nil {
-		return nil, err
+		return nil, invalid(err)
 	}
 
 	if err := s.EnsureProjectDirs(key); err != nil {
@@ -70,27 +72,34 @@ func (s *LocalStore) projectKeyExists(key string) bool {
 	return err == nil
 }
 
-func (s *LocalStore) GetProject(projectID string) (*model.Project, string, error) {
+func (s *LocalStore) GetProject(ctx context.Context, projectID string) (*model.Project, string, error) {
 	path, err := s.ResolveEntityPath(projectID)
 	if err != nil {
 		return nil, "", err
 	}
-	p, body, err := ReadEntity[model.Project](path)
+	p, body, err := readEntity[model.Project](s, path)
 	if err != nil {
 		return nil, "", err
 	}
 	return &p, body, nil
 }
 
-func (s *LocalStore) DeleteProject(projectID string) error {
+func (s *LocalStore) DeleteProject(ctx context.Context, projectID string) error {
+	unlock, err := s.lockDataDir()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	dir := s.ProjectDir(projectID)
 	if _, err := os.Stat(dir); err != nil {
-		return fmt.Errorf("%s not found", projectID)
+		return notFoundf("%s not found", projectID)
 	}
+	s.changed.Store(true)
 	return os.RemoveAll(dir)
 }
 
-func (s *LocalStore) ListProjects() ([]model.Project, error) {
+func (s *LocalStore) ListProjects(ctx context.Context) ([]model.Project, error) {
 	dirs, err := s.listProjectDirs()
 	if err != nil {
 		return nil, err
@@ -99,7 +108,7 @@ func (s *LocalStore) ListProjects() ([]model.Project, error) {
 	var projects []model.Project
 	for _, d := range dirs {
 		path := filepath.Join(d, "project.md")
-		p, _, err := ReadEntity[model.Project](path)
+		p, _, err := readEntity[model.Project](s, path)
 		if err != nil {
 			continue
 		}
@@ -107,3 +116,166 @@ func (s *LocalStore) ListProjects() ([]model.Project, error) {
 	}
 	return projects, nil
 }
+
+// ProjectUpdate holds the project fields to change; nil fields are left
+// alone.
+type ProjectUpdate struct {
+	Name           *string
+	AutoCloseEpics *bool
+	WIPLimits      *map[model.Status]int
+	IDLength       *int
+	Body           *string
+}
+
+// UpdateProject changes a project's name, description and settings. The
+// key and every entity ID stay the same. Changing auto_close_epics closes
+// or reopens the project's epics to match.
+func (s *LocalStore) UpdateProject(ctx context.Context, projectID string, upd ProjectUpdate) (*model.Project, error) {
+	path, err := s.ResolveEntityPath(projectID)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := lockEntity(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	p, body, err := ReadEntity[model.Project](path)
+	if err != nil {
+		return nil, err
+	}
+	if upd.Name != nil {
+		p.Name = *upd.Name
+	}
+	if upd.AutoCloseEpics != nil {
+		p.AutoCloseEpics = *upd.AutoCloseEpics
+	}
+	if upd.WIPLimits != nil {
+		p.WIPLimits = *upd.WIPLimits
+	}
+	if upd.IDLength != nil {
+		p.IDLength = *upd.IDLength
+	}
+	if upd.Body != nil {
+		body = *upd.Body
+	}
+	p.UpdatedAt = now()
+	if err := p.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+	if err := s.WriteEntity(path, &p, body); err != nil {
+		return nil, err
+	}
+	if upd.AutoCloseEpics != nil {
+		epics, err := s.ListTasks(ctx, TaskFilter{ProjectID: p.ID, Type: model.TypeEpic})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range epics {
+			if err := s.syncEpicClosed(ctx, e.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &p, nil
+}
+
+// RekeyProject changes a project's key: the project directory is renamed and
+// every task, document and release ID in it is rewritten from OLD-... to
+// NEW-..., along with the epic, depends_on, items and changelog references
+// between them. IDs mentioned in markdown bodies are left as written.
+func (s *LocalStore) RekeyProject(ctx context.Context, oldKey, newKey string) (*model.Project, error) {
+	if err := id.ValidateKey(newKey); err != nil {
+		return nil, err
+	}
+	unlock, err := s.lockDataDir()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if !s.projectKeyExists(oldKey) {
+		return nil, notFoundf("%s not found", oldKey)
+	}
+	if s.projectKeyExists(newKey) {
+		return nil, conflictf("project key %q already exists", newKey)
+	}
+	s.changed.Store(true)
+	if err := os.Rename(s.ProjectDir(oldKey), s.ProjectDir(newKey)); err != nil {
+		return nil, fmt.Errorf("renaming project directory: %w", err)
+	}
+
+	dir := s.ProjectDir(newKey)
+	rk := func(ref string) string { return rekeyID(ref, oldKey, newKey) }
+	err = rekeyEntities(s, filepath.Join(dir, "tasks"), func(t *model.Task) string {
+		t.ID, t.Project, t.Epic = rk(t.ID), newKey, rk(t.Epic)
+		for i, dep := range t.DependsOn {
+			t.DependsOn[i] = rk(dep)
+		}
+		return t.ID
+	})
+	if err == nil {
+		err = rekeyEntities(s, filepath.Join(dir, "documents"), func(d *model.Document) string {
+			d.ID, d.Project = rk(d.ID), newKey
+			return d.ID
+		})
+	}
+	if err == nil {
+		err = rekeyEntities(s, filepath.Join(dir, "releases"), func(r *model.Release) string {
+			r.ID, r.Project, r.Changelog = rk(r.ID), newKey, rk(r.Changelog)
+			for i, item := range r.Items {
+				r.Items[i] = rk(item)
+			}
+			return r.ID
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rewriting %s entities: %w", newKey, err)
+	}
+
+	path := filepath.Join(dir, "project.md")
+	p, body, err := ReadEntity[model.Project](path)
+	if err != nil {
+		return nil, err
+	}
+	p.ID = newKey
+	p.UpdatedAt = now()
+	if err := s.WriteEntity(path, &p, body); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// rekeyID swaps the project prefix of an entity ID. IDs from other projects
+// and empty references are returned unchanged.
+func rekeyID(ref, oldKey, newKey string) string {
+	if rest, ok := strings.CutPrefix(ref, oldKey+"-"); ok {
+		return newKey + "-" + rest
+	}
+	return ref
+}
+
+// rekeyEntities rewrites every entity file in dir with rewrite, which returns
+// the entity's new ID, and renames the file to match.
+func rekeyEntities[T any](s *LocalStore, dir string, rewrite func(*T) string) error {
+	files, err := s.ListFiles(dir, "*.md")
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		e, body, err := ReadEntity[T](f)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(dir, rewrite(&e)+".md")
+		if err := s.WriteEntity(dest, &e, body); err != nil {
+			return err
+		}
+		if dest != f {
+			if err := os.Remove(f); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// Query is a parsed task query such as
+//
+//	status:open priority<=1 assignee:alice "oauth"
+//
+// Conditions on the same field are ORed for ":" and ANDed for comparisons,
+// and conditions on different fields are ANDed. Bare words and quoted
+// phrases must all appear in the task's title or body.
+type Query struct {
+	// Filter holds the conditions every store can apply itself; cloud
+	// stores send them to the API.
+	Filter TaskFilter
+	// Text lists the words and phrases to match.
+	Text []string
+
+	conds []queryCond
+}
+
+type queryCond struct {
+	field, op, value string
+}
+
+// QueryFields lists the fields a query can filter on.
+var QueryFields = []string{"assignee", "due", "epic", "priority", "project", "status", "type"}
+
+var queryOps = []string{"<=", ">=", "!=", ":", "=", "<", ">"}
+
+// ParseQuery parses a task query. It returns an error for unknown fields,
+// bad values, or comparisons on fields that can't be ordered.
+func ParseQuery(s string) (*Query, error) {
+	words, err := splitQuery(s)
+	if err != nil {
+		return nil, err
+	}
+	q := &Query{}
+	for _, w := range words {
+		if w.quoted {
+			q.Text = append(q.Text, w.text)
+			continue
+		}
+		field, op, value, ok := cutOp(w.text)
+		if !ok {
+			q.Text = append(q.Text, w.text)
+			continue
+		}
+		c, err := parseCond(strings.ToLower(field), op, value)
+		if err != nil {
+			return nil, err
+		}
+		q.conds = append(q.conds, c)
+	}
+	q.pushDown()
+	return q, nil
+}
+
+// HasFilters reports whether the query filters on fields, as opposed to
+// only matching text.
+func (q *Query) HasFilters() bool {
+	return len(q.conds) > 0
+}
+
+type queryWord struct {
+	text   string
+	quoted bool
+}
+
+// splitQuery splits s on spaces, keeping "quoted phrases" together.
+func splitQuery(s string) ([]queryWord, error) {
+	var words []queryWord
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return words, nil
+		}
+		if s[0] == '"' {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				return nil, invalidf("unterminated quote in query")
+			}
+			if phrase := s[1 : end+1]; phrase != "" {
+				words = append(words, queryWord{phrase, true})
+			}
+			s = s[end+2:]
+			continue
+		}
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		words = append(words, queryWord{s[:end], false})
+		s = s[end:]
+	}
+}
+
+// cutOp splits "field<op>value". Words without an operator, or starting
+// with one, are text.
+func cutOp(w string) (field, op, value string, ok bool) {
+	i := strings.IndexAny(w, ":=!<>")
+	if i <= 0 {
+		return "", "", "", false
+	}
+	for _, op := range queryOps {
+		if strings.HasPrefix(w[i:], op) {
+			return w[:i], op, strings.Trim(w[i+len(op):], `"`), true
+		}
+	}
+	return "", "", "", false
+}
+
+func parseCond(field, op, value string) (queryCond, error) {
+	if op == "=" {
+		op = ":"
+	}
+	c := queryCond{field, op, value}
+	ordered := op != ":" && op != "!="
+	switch field {
+	case "status":
+		if err := model.ValidateStatus(model.Status(value)); err != nil {
+			return c, invalid(err)
+		}
+	case "type":
+		if value != string(model.TypeTask) && value != string(model.TypeEpic) {
+			return c, invalidf("invalid type %q: must be task or epic", value)
+		}
+	case "priority":
+		v := strings.TrimPrefix(strings.ToUpper(value), "P")
+		if n, err := strconv.Atoi(v); err != nil || n < 0 || n > 3 {
+			return c, invalidf("invalid priority %q: must be 0-3", value)
+		}
+		c.value = v
+		return c, nil
+	case "due":
+		if _, err := time.Parse(model.DateFormat, value); err != nil {
+			return c, invalidf("invalid due date %q: must be YYYY-MM-DD", value)
+		}
+		return c, nil
+	case "project", "epic":
+		c.value = strings.ToUpper(value)
+	case "assignee":
+	default:
+		return c, invalidf("unknown query field %q (want one of %s)", field, strings.Join(QueryFields, ", "))
+	}
+	if ordered {
+		return c, invalidf("%s%s%s: only priority and due can be compared with %s", field, op, value, op)
+	}
+	return c, nil
+}
+
+// pushDown moves conditions that TaskFilter can express into Filter: a
+// single "field:value" on project, epic, status or type.
+func (q *Query) pushDown() {
+	count := map[string]int{}
+	for _, c := range q.conds {
+		count[c.field]++
+	}
+	for _, c := range q.conds {
+		if c.op != ":" || count[c.field] != 1 {
+			continue
+		}
+		switch c.field {
+		case "project":
+			q.Filter.ProjectID = c.value
+		case "epic":
+			q.Filter.EpicID = c.value
+		case "status":
+			q.Filter.Status = model.Status(c.value)
+		case "type":
+			q.Filter.Type = model.TaskType(c.value)
+		}
+	}
+}
+
+// Match reports whether t meets the query's field conditions. Text is
+// matched separately, as it needs the task's body.
+func (q *Query) Match(t *model.Task) bool {
+	either := map[string]bool{} // fields with a ":" condition, and whether one matched
+	for _, c := range q.conds {
+		v := taskField(t, c.field)
+		switch c.op {
+		case ":":
+			either[c.field] = either[c.field] || strings.EqualFold(v, c.value)
+		case "!=":
+			if strings.EqualFold(v, c.value) {
+				return false
+			}
+		default:
+			// Tasks without a priority or due date never compare.
+			if v == "" || !compare(v, c.op, c.value) {
+				return false
+			}
+		}
+	}
+	for _, ok := range either {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func taskField(t *model.Task, field string) string {
+	switch field {
+	case "status":
+		return string(t.Status)
+	case "type":
+		return string(t.Type)
+	case "project":
+		return t.Project
+	case "epic":
+		return t.Epic
+	case "assignee":
+		return t.Assignee
+	case "due":
+		return t.Due
+	case "priority":
+		if t.Priority != nil {
+			return strconv.Itoa(*t.Priority)
+		}
+	}
+	return ""
+}
+
+// compare orders priorities and YYYY-MM-DD dates, both of which sort as
+// strings.
+func compare(a, op, b string) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+// QueryTasks returns the tasks matching q with a snippet for the text they
+// matched, in ID order. The filter and text are run by the store (as a
+// listing and a search, so cloud stores answer them server-side) and the
+// remaining conditions are checked here. opts sets how the text is matched;
+// its project and type are taken from q.
+func QueryTasks(ctx context.Context, s Store, q *Query, opts SearchOpts) ([]SearchResult, error) {
+	opts.ProjectID, opts.Type = q.Filter.ProjectID, "task"
+	tasks, err := s.ListTasks(ctx, q.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var snippets map[string]string
+	for i, text := range q.Text {
+		found, err := s.Search(ctx, text, opts)
+		if err != nil {
+			return nil, err
+		}
+		hits := map[string]string{}
+		for _, r := range found {
+			if prev, ok := snippets[r.ID]; ok && prev != "" {
+				hits[r.ID] = prev
+			} else if i == 0 || ok {
+				hits[r.ID] = r.Snippet
+			}
+		}
+		snippets = hits
+	}
+
+	var results []SearchResult
+	for i := range tasks {
+		t := &tasks[i]
+		if !q.Match(t) {
+			continue
+		}
+		snip, ok := snippets[t.ID]
+		if q.Text != nil && !ok {
+			continue
+		}
+		results = append(results, SearchResult{Type: "task", ID: t.ID, Title: t.Title, Snippet: snip})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return results, nil
+}
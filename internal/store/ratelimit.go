@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when a cloud store keeps rejecting requests
+// with 429 Too Many Requests, or asks for a longer wait than compass will
+// sit through.
+var ErrRateLimited = errors.New("rate limited")
+
+const (
+	// maxRateLimitRetries is how many times a request rejected with 429 is
+	// sent again.
+	maxRateLimitRetries = 3
+	// maxRateLimitWait is the longest compass waits for a rate limit window
+	// to reset before giving up.
+	maxRateLimitWait = time.Minute
+)
+
+// rateLimit tracks the X-RateLimit-Remaining and X-RateLimit-Reset headers
+// of a store's last response, so requests made in a loop, such as a long
+// listing's pages, wait for the window to reset instead of running into
+// 429s.
+type rateLimit struct {
+	mu        sync.Mutex
+	known     bool // a response has reported the remaining count
+	remaining int
+	reset     time.Time
+}
+
+// update records the limits reported by a response's headers.
+func (rl *rateLimit) update(h http.Header, now time.Time) {
+	n, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.known = true
+	rl.remaining = n
+	rl.reset = resetTime(h.Get("X-RateLimit-Reset"), now)
+}
+
+// wait returns how long to hold off before the next request: until the
+// reset when the window has no requests left, otherwise zero.
+func (rl *rateLimit) wait(now time.Time) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if !rl.known || rl.remaining > 0 || !rl.reset.After(now) {
+		return 0
+	}
+	return rl.reset.Sub(now)
+}
+
+// resetTime parses X-RateLimit-Reset, which servers send either as a Unix
+// time or as seconds from now.
+func resetTime(v string, now time.Time) time.Time {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return time.Time{}
+	}
+	if n > 1e9 {
+		return time.Unix(n, 0)
+	}
+	return now.Add(time.Duration(n) * time.Second)
+}
+
+// retryAfter returns how long a 429 response asks the client to wait, from
+// Retry-After (seconds or an HTTP date) or else X-RateLimit-Reset. Without
+// either it backs off by attempt.
+func retryAfter(h http.Header, now time.Time, attempt int) time.Duration {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(t.Sub(now), 0)
+		}
+	}
+	if reset := resetTime(h.Get("X-RateLimit-Reset"), now); !reset.IsZero() {
+		return max(reset.Sub(now), 0)
+	}
+	return time.Second << attempt
+}
+
+// roundUp rounds a wait up to whole seconds for messages.
+func roundUp(d time.Duration) time.Duration {
+	return (d + time.Second - 1).Truncate(time.Second)
+}
+
+// label names the store in rate limit messages.
+func (cs *CloudStore) label() string {
+	if cs.name != "" {
+		return cs.name
+	}
+	if u, err := url.Parse(cs.apiBase); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "the cloud store"
+}
+
+// pause tells the user why compass is waiting, as a warning unless notice
+// is set, then waits, returning early with the context's error if the
+// request is cancelled.
+func (cs *CloudStore) pause(ctx context.Context, d time.Duration, format string, args ...any) error {
+	if cs.notice != nil {
+		cs.notice(fmt.Sprintf(format, args...))
+	} else {
+		slog.Warn(fmt.Sprintf(format, args...))
+	}
+	if cs.sleep != nil {
+		cs.sleep(d)
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rateLimited is the error for a request compass stopped retrying.
+func (cs *CloudStore) rateLimited(wait time.Duration) error {
+	return fmt.Errorf("%w by %s; try again in %s", ErrRateLimited, cs.label(), roundUp(wait))
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// ErrReadOnly is returned (wrapped) for mutations routed to a read-only store.
+var ErrReadOnly = errors.New("store is read-only")
+
+// readOnlyStore wraps a Store and rejects every mutating operation. The
+// Registry wraps stores marked read_only in config.
+type readOnlyStore struct {
+	Store
+	name string
+}
+
+func (r *readOnlyStore) deny() error {
+	return fmt.Errorf("%w: %q (run 'compass store set-readonly %s --off' to allow changes)", ErrReadOnly, r.name, r.name)
+}
+
+func (r *readOnlyStore) CreateProject(ctx context.Context, name, key, body string) (*model.Project, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) DeleteProject(ctx context.Context, projectID string) error {
+	return r.deny()
+}
+
+func (r *readOnlyStore) UpdateProject(ctx context.Context, projectID string, upd ProjectUpdate) (*model.Project, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) RekeyProject(ctx context.Context, oldKey, newKey string) (*model.Project, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) CreateTask(ctx context.Context, title, projectID string, opts TaskCreateOpts) (*model.Task, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) UpdateTask(ctx context.Context, taskID string, upd TaskUpdate) (*model.Task, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) DeleteTask(ctx context.Context, taskID string) error {
+	return r.deny()
+}
+
+func (r *readOnlyStore) MoveTask(ctx context.Context, taskID, projectID string) (*model.Task, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) ClaimTask(ctx context.Context, projectID string) (*model.Task, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) CreateDocument(ctx context.Context, title, projectID string, opts DocumentCreateOpts) (*model.Document, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) UpdateDocument(ctx context.Context, docID string, upd DocumentUpdate) (*model.Document, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) DeleteDocument(ctx context.Context, docID string) error {
+	return r.deny()
+}
+
+func (r *readOnlyStore) CreateRelease(ctx context.Context, version, projectID string, opts ReleaseCreateOpts) (*model.Release, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) UpdateRelease(ctx context.Context, releaseID string, upd ReleaseUpdate) (*model.Release, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) UploadTask(ctx context.Context, localPath string) (*model.Task, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) UploadDocument(ctx context.Context, localPath string) (*model.Document, error) {
+	return nil, r.deny()
+}
+
+func (r *readOnlyStore) WriteEntity(path string, meta any, body string) error {
+	return r.deny()
+}
+
+// localBacked is a store that serves the local filesystem through a
+// LocalStore of its own, such as the daemon client.
+type localBacked interface {
+	Local() *LocalStore
+}
+
+// AsLocal returns the LocalStore behind s, looking through read-only and
+// dry-run wrappers and stores backed by one, or false if s is not backed
+// by the local filesystem.
+func AsLocal(s Store) (*LocalStore, bool) {
+	if ro, ok := s.(*readOnlyStore); ok {
+		s = ro.Store
+	}
+	if dr, ok := s.(*dryRunStore); ok {
+		s = dr.Store
+	}
+	if lb, ok := s.(localBacked); ok {
+		return lb.Local(), true
+	}
+	ls, ok := s.(*LocalStore)
+	return ls, ok
+}
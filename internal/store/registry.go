@@ -1,9 +1,13 @@
 package store
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"sort"
+	"strings"
 
 	"github.com/rogersnm/compass/internal/config"
 	"github.com/rogersnm/compass/internal/id"
@@ -11,32 +15,84 @@ import (
 
 // Registry routes commands to the correct Store based on project key.
 type Registry struct {
-	stores       map[string]Store // "local" and/or store names -> Store
-	defaultStore string           // "local" or a store name
+	stores       map[string]Store                 // "local" and/or store names -> Store
+	openers      map[string]func() (Store, error) // stores constructed on first use
+	defaultStore string                           // "local" or a store name
 	cfg          *config.Config
 	dataDir      string
+	probed       map[string]error // Ping outcome per store, checked once per process
+	dryRun       io.Writer        // set by SetDryRun
+	// qualified routes project keys named in store-qualified references,
+	// overriding the project cache; see Qualify.
+	qualified map[string]string
+	override  string // set by Override
 }
 
+// NewRegistry routes with cfg's project cache, persisting changes to it in
+// dataDir's config.yaml. An empty dataDir keeps the cache in memory only.
 func NewRegistry(cfg *config.Config, dataDir string) *Registry {
 	return &Registry{
 		stores:       make(map[string]Store),
+		openers:      make(map[string]func() (Store, error)),
 		defaultStore: cfg.DefaultStore,
 		cfg:          cfg,
 		dataDir:      dataDir,
 	}
 }
 
+// SetDryRun makes stores added from now on report their mutations to w
+// instead of making them.
+func (r *Registry) SetDryRun(w io.Writer) {
+	r.dryRun = w
+}
+
+// Add registers a store. Stores marked read-only in config are wrapped so
+// that mutations routed to them fail with ErrReadOnly, even in a dry run.
 func (r *Registry) Add(name string, s Store) {
+	if r.dryRun != nil {
+		s = &dryRunStore{Store: s, out: r.dryRun}
+	}
+	if r.cfg.IsReadOnly(name) {
+		s = &readOnlyStore{Store: s, name: name}
+	}
+	delete(r.openers, name)
 	r.stores[name] = s
 }
 
-// Get returns a store by name.
+// AddLazy registers a store that is constructed the first time it is used,
+// so commands that never touch it pay nothing for it (HTTP transports, CA
+// bundles). Construction errors surface from Get.
+func (r *Registry) AddLazy(name string, open func() (Store, error)) {
+	delete(r.stores, name)
+	r.openers[name] = open
+}
+
+// Get returns a store by name, constructing it if it was added lazily.
 func (r *Registry) Get(name string) (Store, error) {
-	s, ok := r.stores[name]
+	if s, ok := r.stores[name]; ok {
+		return s, nil
+	}
+	open, ok := r.openers[name]
 	if !ok {
 		return nil, fmt.Errorf("store %q not configured", name)
 	}
-	return s, nil
+	s, err := open()
+	if err != nil {
+		return nil, fmt.Errorf("store %s: %w", name, err)
+	}
+	r.Add(name, s)
+	return r.stores[name], nil
+}
+
+// Opened returns the names of the stores constructed so far, sorted. Lazy
+// stores appear once a command has used them.
+func (r *Registry) Opened() []string {
+	names := make([]string, 0, len(r.stores))
+	for name := range r.stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // DefaultStore returns the default store and its name.
@@ -53,84 +109,231 @@ func (r *Registry) Default() (Store, string, error) {
 
 // ForProject resolves a project key to its store using the cached mapping.
 // On cache miss, probes all stores (local first).
-func (r *Registry) ForProject(projectKey string) (Store, string, error) {
+func (r *Registry) ForProject(ctx context.Context, projectKey string) (Store, string, error) {
+	if r.override != "" {
+		if _, ok := r.qualified[projectKey]; !ok {
+			s, err := r.Get(r.override)
+			if err != nil {
+				return nil, "", err
+			}
+			return s, r.override, nil
+		}
+	}
+	if name, ok := r.qualified[projectKey]; ok {
+		s, err := r.Get(name)
+		if err != nil {
+			return nil, "", err
+		}
+		return s, name, nil
+	}
 	if r.cfg.Projects != nil {
 		if storeName, ok := r.cfg.Projects[projectKey]; ok {
-			s, err := r.Get(storeName)
+			s, err := r.cached(projectKey, storeName)
 			if err == nil {
-				if _, _, err := s.GetProject(projectKey); err == nil {
+				if _, _, err = s.GetProject(ctx, projectKey); err == nil {
 					return s, storeName, nil
 				}
 			}
-			// Stale cache entry; prune it
+			// Only an entry whose store is gone or no longer has the project
+			// is stale. Anything else, such as a timeout, keeps the entry.
+			if !errors.Is(err, ErrNotFound) && r.configured(storeName) {
+				return nil, "", err
+			}
 			r.UncacheProject(projectKey)
 		}
 	}
 
-	// Cache miss: probe all stores, local first
+	// Cache miss: probe all stores, local first, skipping any that don't
+	// answer a short health check.
 	for _, name := range r.probeOrder() {
-		s := r.stores[name]
-		if _, _, err := s.GetProject(projectKey); err == nil {
-			r.CacheProject(projectKey, name)
+		s, err := r.Get(name)
+		if err != nil || r.ping(ctx, name, s) != nil {
+			continue
+		}
+		if _, _, err := s.GetProject(ctx, projectKey); err == nil {
+			if r.dryRun == nil {
+				r.CacheProject(projectKey, name)
+			}
 			return s, name, nil
 		}
 	}
 
-	return nil, "", fmt.Errorf("project %s not found on any configured store", projectKey)
+	return nil, "", notFoundf("project %s not found on any configured store", projectKey)
+}
+
+// cached returns the store a project cache entry names, or, for a key
+// mapped to several stores, a read-only view of all of them.
+func (r *Registry) cached(projectKey, value string) (Store, error) {
+	names := config.SplitStores(value)
+	if len(names) == 1 {
+		return r.Get(value)
+	}
+	stores := make([]Store, len(names))
+	for i, name := range names {
+		s, err := r.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		stores[i] = s
+	}
+	return newMultiStore(projectKey, names, stores), nil
+}
+
+// Override routes every project to the named store, ignoring the project
+// cache, and makes it the default for new projects. Store-qualified
+// references still reach their own store.
+func (r *Registry) Override(name string) error {
+	if !r.has(name) {
+		return fmt.Errorf("store %q not configured", name)
+	}
+	r.override = name
+	r.defaultStore = name
+	return nil
+}
+
+// Qualify strips the store from a store-qualified reference such as
+// "work:AUTH-TABCDE", "work:AUTH" or "work:AUTH/login-form", or from a
+// project alias such as "AUTH@work", and routes the reference's project to
+// that store for the rest of the process. That reaches a project whose key
+// another store also has, which the project cache can only map to one of
+// them. The cache is left as it is. References that aren't qualified with
+// a configured store's name are returned unchanged.
+func (r *Registry) Qualify(ref string) (string, error) {
+	var name, rest, key string
+	if i := strings.LastIndex(ref, ":"); i >= 0 {
+		name, rest = ref[:i], ref[i+1:]
+		var ok bool
+		if key, _, ok = ParseSlugRef(rest); !ok {
+			var err error
+			if key, err = id.ProjectKeyFrom(rest); err != nil {
+				return ref, nil
+			}
+		}
+	} else if k, n, ok := config.SplitAlias(ref); ok && id.ValidateKey(k) == nil {
+		name, rest, key = n, k, k
+	} else {
+		return ref, nil
+	}
+	if !r.has(name) {
+		return ref, nil
+	}
+	if prev, ok := r.qualified[key]; ok && prev != name {
+		return "", invalidf("%s is qualified with both %s and %s", key, prev, name)
+	}
+	if r.qualified == nil {
+		r.qualified = make(map[string]string)
+	}
+	r.qualified[key] = name
+	return rest, nil
+}
+
+// ping health-checks a store with ProbeTimeout, remembering the outcome so
+// a dead store is only waited on once.
+func (r *Registry) ping(ctx context.Context, name string, s Store) error {
+	if err, ok := r.probed[name]; ok {
+		return err
+	}
+	if r.probed == nil {
+		r.probed = make(map[string]error)
+	}
+	ctx, cancel := context.WithTimeout(ctx, ProbeTimeout)
+	defer cancel()
+	_, err := s.Ping(ctx)
+	r.probed[name] = err
+	return err
 }
 
 // ForEntity extracts the project key from an entity ID and routes to its store.
-func (r *Registry) ForEntity(entityID string) (Store, string, error) {
+func (r *Registry) ForEntity(ctx context.Context, entityID string) (Store, string, error) {
 	key, err := id.ProjectKeyFrom(entityID)
 	if err != nil {
 		return nil, "", err
 	}
-	return r.ForProject(key)
+	return r.ForProject(ctx, key)
 }
 
-// All returns all configured stores.
+// All returns all configured stores, constructing any added lazily. Stores
+// that fail to construct are omitted.
 func (r *Registry) All() map[string]Store {
+	for _, name := range r.Names() {
+		r.Get(name)
+	}
 	return r.stores
 }
 
 // CloudStoreNames returns sorted names of cloud stores.
 func (r *Registry) CloudStoreNames() []string {
 	var names []string
-	for name := range r.stores {
+	for _, name := range r.Names() {
 		if name != "local" {
 			names = append(names, name)
 		}
 	}
-	sort.Strings(names)
 	return names
 }
 
-// CacheProject writes the project-to-store mapping and persists config.
+// CacheProject writes the project-to-store mapping and persists the
+// project cache. In a dry run it only says it would.
 func (r *Registry) CacheProject(key, storeName string) {
+	if r.dryRun != nil {
+		fmt.Fprintf(r.dryRun, "Would cache project %s on %s\n", key, storeName)
+		return
+	}
 	if r.cfg.Projects == nil {
 		r.cfg.Projects = make(map[string]string)
 	}
 	r.cfg.Projects[key] = storeName
-	if err := config.Save(r.dataDir, r.cfg); err != nil {
-		log.Printf("warning: failed to persist project cache: %v", err)
+	if r.dataDir == "" {
+		return
+	}
+	if err := config.SaveProjectCache(r.dataDir, r.cfg.Projects); err != nil {
+		slog.Warn("persisting project cache", "err", err)
 	}
 }
 
-// UncacheProject removes a project from the cache and persists.
+// UncacheProject removes a project from the cache and persists. In a dry
+// run it only says it would.
 func (r *Registry) UncacheProject(key string) {
+	if r.dryRun != nil {
+		fmt.Fprintf(r.dryRun, "Would uncache project %s\n", key)
+		return
+	}
 	if r.cfg.Projects == nil {
 		return
 	}
 	delete(r.cfg.Projects, key)
-	if err := config.Save(r.dataDir, r.cfg); err != nil {
-		log.Printf("warning: failed to persist project cache: %v", err)
+	if r.dataDir == "" {
+		return
+	}
+	if err := config.SaveProjectCache(r.dataDir, r.cfg.Projects); err != nil {
+		slog.Warn("persisting project cache", "err", err)
+	}
+}
+
+// Uncache removes one store's mapping of a project from the cache, keeping
+// any other stores the entry maps to, and persists. In a dry run it only
+// says it would.
+func (r *Registry) Uncache(m config.ProjectMapping) {
+	if r.dryRun != nil {
+		fmt.Fprintf(r.dryRun, "Would uncache project %s from %s\n", m.Entry, m.Store)
+		return
+	}
+	if r.cfg.Projects == nil {
+		return
+	}
+	config.Uncache(r.cfg.Projects, m)
+	if r.dataDir == "" {
+		return
+	}
+	if err := config.SaveProjectCache(r.dataDir, r.cfg.Projects); err != nil {
+		slog.Warn("persisting project cache", "err", err)
 	}
 }
 
 // probeOrder returns store names with "local" first.
 func (r *Registry) probeOrder() []string {
 	var names []string
-	if _, ok := r.stores["local"]; ok {
+	if r.has("local") {
 		names = append(names, "local")
 	}
 	for _, n := range r.CloudStoreNames() {
@@ -141,7 +344,7 @@ func (r *Registry) probeOrder() []string {
 
 // IsEmpty returns true when no stores are registered.
 func (r *Registry) IsEmpty() bool {
-	return len(r.stores) == 0
+	return len(r.stores) == 0 && len(r.openers) == 0
 }
 
 // Names returns all store names.
@@ -150,10 +353,30 @@ func (r *Registry) Names() []string {
 	for n := range r.stores {
 		names = append(names, n)
 	}
+	for n := range r.openers {
+		names = append(names, n)
+	}
 	sort.Strings(names)
 	return names
 }
 
+func (r *Registry) has(name string) bool {
+	_, ok := r.stores[name]
+	_, lazy := r.openers[name]
+	return ok || lazy
+}
+
+// configured reports whether every store a project cache entry names is
+// configured.
+func (r *Registry) configured(value string) bool {
+	for _, name := range config.SplitStores(value) {
+		if !r.has(name) {
+			return false
+		}
+	}
+	return true
+}
+
 // SetDefault changes the default store.
 func (r *Registry) SetDefault(name string) {
 	r.defaultStore = name
@@ -1,9 +1,16 @@
 package store
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rogersnm/compass/internal/config"
+	"github.com/rogersnm/compass/internal/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,10 +32,10 @@ func setupRegistry(t *testing.T) (*Registry, *LocalStore, string) {
 
 func TestForProject_CacheHit(t *testing.T) {
 	reg, ls, _ := setupRegistry(t)
-	ls.CreateProject("Test", "TP", "")
+	ls.CreateProject(t.Context(), "Test", "TP", "")
 	reg.CacheProject("TP", "local")
 
-	s, name, err := reg.ForProject("TP")
+	s, name, err := reg.ForProject(t.Context(), "TP")
 	require.NoError(t, err)
 	assert.Equal(t, "local", name)
 	assert.Equal(t, ls, s)
@@ -36,9 +43,9 @@ func TestForProject_CacheHit(t *testing.T) {
 
 func TestForProject_CacheMiss(t *testing.T) {
 	reg, ls, _ := setupRegistry(t)
-	ls.CreateProject("Test", "TP", "")
+	ls.CreateProject(t.Context(), "Test", "TP", "")
 
-	s, name, err := reg.ForProject("TP")
+	s, name, err := reg.ForProject(t.Context(), "TP")
 	require.NoError(t, err)
 	assert.Equal(t, "local", name)
 	assert.Equal(t, ls, s)
@@ -48,40 +55,184 @@ func TestForProject_CacheMiss(t *testing.T) {
 
 func TestForProject_NotFound(t *testing.T) {
 	reg, _, _ := setupRegistry(t)
-	_, _, err := reg.ForProject("NOPE")
+	_, _, err := reg.ForProject(t.Context(), "NOPE")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found on any configured store")
 }
 
 func TestForProject_StaleCache(t *testing.T) {
 	reg, ls, _ := setupRegistry(t)
-	ls.CreateProject("Test", "TP", "")
+	ls.CreateProject(t.Context(), "Test", "TP", "")
 	reg.CacheProject("TP", "local")
 
 	// Delete the project to make cache stale
-	ls.DeleteProject("TP")
+	ls.DeleteProject(t.Context(), "TP")
 
-	_, _, err := reg.ForProject("TP")
+	_, _, err := reg.ForProject(t.Context(), "TP")
 	assert.Error(t, err) // not found anywhere after prune
 	// Cache should be pruned
 	_, ok := reg.cfg.Projects["TP"]
 	assert.False(t, ok)
 }
 
+// unreachable is a store whose projects can't be read.
+type unreachable struct{ Store }
+
+func (unreachable) GetProject(context.Context, string) (*model.Project, string, error) {
+	return nil, "", fmt.Errorf("connection refused")
+}
+
+func TestForProject_CacheKeptOnStoreError(t *testing.T) {
+	reg, ls, _ := setupRegistry(t)
+	cloud := NewLocal(t.TempDir())
+	reg.Add("cloud", unreachable{cloud})
+	ls.CreateProject(t.Context(), "Mine", "TP", "")
+	cloud.CreateProject(t.Context(), "Theirs", "TP", "")
+	reg.CacheProject("TP", config.JoinStores("local", "cloud"))
+
+	_, _, err := reg.ForProject(t.Context(), "TP")
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, "cloud,local", reg.cfg.Projects["TP"])
+
+	reg.CacheProject("TP", "gone")
+	_, name, err := reg.ForProject(t.Context(), "TP")
+	require.NoError(t, err)
+	assert.Equal(t, "local", name, "an entry naming a removed store is stale")
+}
+
+// ctxStore is a store whose reads give up once their context is done.
+type ctxStore struct{ Store }
+
+func (c ctxStore) GetProject(ctx context.Context, projectID string) (*model.Project, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	return c.Store.GetProject(ctx, projectID)
+}
+
+func TestForProject_Cancelled(t *testing.T) {
+	reg, ls, _ := setupRegistry(t)
+	reg.Add("local", ctxStore{ls})
+	ls.CreateProject(t.Context(), "Test", "TP", "")
+	reg.CacheProject("TP", "local")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, _, err := reg.ForProject(ctx, "TP")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "local", reg.cfg.Projects["TP"])
+}
+
 func TestForEntity(t *testing.T) {
 	reg, ls, _ := setupRegistry(t)
-	ls.CreateProject("Test", "TP", "")
-	ls.CreateTask("Task", "TP", TaskCreateOpts{})
+	ls.CreateProject(t.Context(), "Test", "TP", "")
+	ls.CreateTask(t.Context(), "Task", "TP", TaskCreateOpts{})
 
-	tasks, _ := ls.ListTasks(TaskFilter{ProjectID: "TP"})
+	tasks, _ := ls.ListTasks(t.Context(), TaskFilter{ProjectID: "TP"})
 	require.Len(t, tasks, 1)
 
-	s, name, err := reg.ForEntity(tasks[0].ID)
+	s, name, err := reg.ForEntity(t.Context(), tasks[0].ID)
 	require.NoError(t, err)
 	assert.Equal(t, "local", name)
 	assert.Equal(t, ls, s)
 }
 
+func TestRegistry_Qualify(t *testing.T) {
+	reg, ls, _ := setupRegistry(t)
+	work := NewLocal(t.TempDir())
+	reg.Add("work", work)
+	ls.CreateProject(t.Context(), "Mine", "TP", "")
+	work.CreateProject(t.Context(), "Theirs", "TP", "")
+	reg.CacheProject("TP", "local")
+
+	for _, ref := range []string{"TP", "TP-TABCDE", "nope:TP-TABCDE", "work:Not a ref", "work:"} {
+		got, err := reg.Qualify(ref)
+		require.NoError(t, err)
+		assert.Equal(t, ref, got, "%s is not qualified", ref)
+	}
+	_, name, err := reg.ForProject(t.Context(), "TP")
+	require.NoError(t, err)
+	assert.Equal(t, "local", name)
+
+	got, err := reg.Qualify("work:TP-TABCDE")
+	require.NoError(t, err)
+	assert.Equal(t, "TP-TABCDE", got)
+	s, name, err := reg.ForEntity(t.Context(), "TP-TABCDE")
+	require.NoError(t, err)
+	assert.Equal(t, "work", name)
+	assert.Equal(t, work, s)
+	assert.Equal(t, "local", reg.cfg.Projects["TP"], "cache unchanged")
+
+	got, err = reg.Qualify("work:TP/login")
+	require.NoError(t, err)
+	assert.Equal(t, "TP/login", got)
+
+	_, err = reg.Qualify("local:TP")
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestForProject_Multi(t *testing.T) {
+	reg, ls, _ := setupRegistry(t)
+	work := NewLocal(t.TempDir())
+	reg.Add("work", work)
+	ls.CreateProject(t.Context(), "Mine", "TP", "")
+	work.CreateProject(t.Context(), "Theirs", "TP", "")
+	mine, _ := ls.CreateTask(t.Context(), "Mine", "TP", TaskCreateOpts{})
+	theirs, _ := work.CreateTask(t.Context(), "Theirs", "TP", TaskCreateOpts{})
+	reg.CacheProject("TP", config.JoinStores("work", "local"))
+
+	s, name, err := reg.ForProject(t.Context(), "TP")
+	require.NoError(t, err)
+	assert.Equal(t, "local,work", name)
+	tasks, err := s.ListTasks(t.Context(), TaskFilter{ProjectID: "TP"})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+	got, _, err := s.GetTask(t.Context(), theirs.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Theirs", got.Title)
+	page, next, err := s.ListTasksPage(t.Context(), TaskFilter{ProjectID: "TP"}, PageOpts{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, page, 1)
+	assert.NotEmpty(t, next)
+
+	_, err = s.UpdateTask(t.Context(), mine.ID, TaskUpdate{})
+	assert.ErrorIs(t, err, ErrReadOnly)
+	assert.ErrorContains(t, err, "local:TP")
+
+	// An alias reaches one store's project.
+	ref, err := reg.Qualify("TP@work")
+	require.NoError(t, err)
+	assert.Equal(t, "TP", ref)
+	s, name, err = reg.ForProject(t.Context(), "TP")
+	require.NoError(t, err)
+	assert.Equal(t, "work", name)
+	assert.Equal(t, work, s)
+}
+
+func TestRegistry_Override(t *testing.T) {
+	reg, ls, _ := setupRegistry(t)
+	work := NewLocal(t.TempDir())
+	reg.Add("work", work)
+	ls.CreateProject(t.Context(), "Mine", "TP", "")
+	reg.CacheProject("TP", "local")
+
+	assert.Error(t, reg.Override("nowhere"))
+	require.NoError(t, reg.Override("work"))
+	s, name, err := reg.ForProject(t.Context(), "TP")
+	require.NoError(t, err)
+	assert.Equal(t, "work", name)
+	assert.Equal(t, work, s)
+	_, name, err = reg.Default()
+	require.NoError(t, err)
+	assert.Equal(t, "work", name)
+
+	_, err = reg.Qualify("local:TP")
+	require.NoError(t, err)
+	_, name, err = reg.ForProject(t.Context(), "TP")
+	require.NoError(t, err)
+	assert.Equal(t, "local", name, "qualified references win")
+}
+
 func TestUncacheProject(t *testing.T) {
 	reg, _, _ := setupRegistry(t)
 	reg.CacheProject("TP", "local")
@@ -115,3 +266,203 @@ func TestRegistry_DefaultNotSet(t *testing.T) {
 	_, _, err := reg.Default()
 	assert.Error(t, err)
 }
+
+func TestRegistry_ReadOnlyStoreRejectsMutations(t *testing.T) {
+	dir := t.TempDir()
+	ls := NewLocal(dir)
+	p, _ := ls.CreateProject(t.Context(), "Test", "TP", "")
+	task, _ := ls.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+
+	cfg := &config.Config{Version: 2, LocalEnabled: true, LocalReadOnly: true, Projects: map[string]string{}}
+	reg := NewRegistry(cfg, dir)
+	reg.Add("local", ls)
+
+	s, _, err := reg.ForProject(t.Context(), "TP")
+	require.NoError(t, err)
+
+	got, _, err := s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, got.ID)
+
+	_, err = s.CreateTask(t.Context(), "New", p.ID, TaskCreateOpts{})
+	assert.ErrorIs(t, err, ErrReadOnly)
+	assert.Contains(t, err.Error(), "local")
+
+	title := "Renamed"
+	_, err = s.UpdateTask(t.Context(), task.ID, TaskUpdate{Title: &title})
+	assert.ErrorIs(t, err, ErrReadOnly)
+	assert.ErrorIs(t, s.DeleteTask(t.Context(), task.ID), ErrReadOnly)
+
+	local, ok := AsLocal(s)
+	assert.True(t, ok)
+	assert.Equal(t, ls, local)
+}
+
+func TestRegistry_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	ls := NewLocal(dir)
+	p, _ := ls.CreateProject(t.Context(), "Test", "TP", "")
+	task, _ := ls.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+
+	var out strings.Builder
+	cfg := &config.Config{Version: 2, LocalEnabled: true, Projects: map[string]string{}}
+	reg := NewRegistry(cfg, dir)
+	reg.SetDryRun(&out)
+	reg.Add("local", ls)
+	s, _, err := reg.ForProject(t.Context(), "TP")
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteTask(t.Context(), task.ID))
+	path := filepath.Join(dir, "projects", "TP", "tasks", task.ID+".md")
+	assert.FileExists(t, path)
+	created, err := s.CreateTask(t.Context(), "New", p.ID, TaskCreateOpts{})
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteProject(t.Context(), p.ID))
+	assert.Equal(t, "Would delete task "+task.ID+": remove "+path+"\n"+
+		"Would create task "+created.ID+" \"New\": write "+filepath.Join(dir, "projects", "TP", "tasks", created.ID+".md")+"\n"+
+		"Would delete project TP with 1 tasks and 0 documents: remove "+filepath.Join(dir, "projects", "TP")+string(filepath.Separator)+"\n", out.String())
+
+	tasks, err := ls.ListTasks(t.Context(), TaskFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1, "nothing was created or deleted")
+	assert.ErrorIs(t, s.DeleteTask(t.Context(), "TP-TZZZZZ"), ErrNotFound)
+
+	local, ok := AsLocal(s)
+	assert.True(t, ok)
+	assert.Equal(t, ls, local)
+
+	out.Reset()
+	reg.CacheProject("XY", "local")
+	reg.UncacheProject("TP")
+	assert.Equal(t, "Would cache project XY on local\nWould uncache project TP\n", out.String())
+	assert.Empty(t, cfg.Projects)
+	assert.NoFileExists(t, filepath.Join(dir, config.ProjectCacheFile))
+}
+
+func TestRegistry_DryRunCloud(t *testing.T) {
+	cs, srv := newTestCloudStore(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "a dry run only reads")
+		jsonResponse(w, 200, map[string]any{"data": map[string]any{"key": "MP-TABCDE", "title": "My Task", "type": "task", "status": "open"}})
+	})
+	defer srv.Close()
+
+	var out strings.Builder
+	reg := NewRegistry(&config.Config{Version: 2}, "")
+	reg.SetDryRun(&out)
+	reg.Add("work", cs)
+	s, err := reg.Get("work")
+	require.NoError(t, err)
+
+	title := "Renamed"
+	got, err := s.UpdateTask(t.Context(), "MP-TABCDE", TaskUpdate{Title: &title})
+	require.NoError(t, err)
+	assert.Equal(t, "My Task", got.Title)
+	require.NoError(t, s.DeleteTask(t.Context(), "MP-TABCDE"))
+	assert.Equal(t, "Would update task MP-TABCDE: PATCH "+srv.URL+"/tasks/MP-TABCDE\n"+
+		"Would delete task MP-TABCDE: DELETE "+srv.URL+"/tasks/MP-TABCDE\n", out.String())
+}
+
+// stallingStore is a Store whose ListProjects blocks until released.
+type stallingStore struct {
+	Store
+	release chan struct{}
+}
+
+func (s *stallingStore) ListProjects(ctx context.Context) ([]model.Project, error) {
+	<-s.release
+	return nil, nil
+}
+
+// deadStore fails every health check and must not be probed further.
+type deadStore struct {
+	Store
+	t     *testing.T
+	pings int
+}
+
+func (s *deadStore) Ping(ctx context.Context) (PingResult, error) {
+	s.pings++
+	return PingResult{}, fmt.Errorf("connection refused")
+}
+
+func (s *deadStore) GetProject(ctx context.Context, projectID string) (*model.Project, string, error) {
+	s.t.Errorf("GetProject called on a store that failed its ping")
+	return nil, "", fmt.Errorf("unreachable")
+}
+
+func TestForProject_SkipsDeadStore(t *testing.T) {
+	reg, _, _ := setupRegistry(t)
+	dead := &deadStore{t: t}
+	reg.Add("dead.example", dead)
+
+	_, _, err := reg.ForProject(t.Context(), "NOPE")
+	assert.Error(t, err)
+	_, _, err = reg.ForProject(t.Context(), "NOPE2")
+	assert.Error(t, err)
+	assert.Equal(t, 1, dead.pings)
+}
+
+func TestFanOut_PartialResultsOnTimeout(t *testing.T) {
+	reg, ls, _ := setupRegistry(t)
+	ls.CreateProject(t.Context(), "Test", "TP", "")
+	slow := &stallingStore{release: make(chan struct{})}
+	defer close(slow.release)
+	reg.Add("slow.example", slow)
+
+	results, errs, err := FanOut(t.Context(), reg, "", 50*time.Millisecond, func(ctx context.Context, s Store) ([]model.Project, error) {
+		return s.ListProjects(ctx)
+	})
+	require.NoError(t, err)
+	require.Len(t, results["local"], 1)
+	assert.Equal(t, "TP", results["local"][0].ID)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "slow.example", errs[0].Store)
+	assert.Contains(t, errs[0].Error(), "timed out")
+}
+
+func TestFanOut_OnlyStore(t *testing.T) {
+	reg, ls, _ := setupRegistry(t)
+	ls.CreateProject(t.Context(), "Test", "TP", "")
+	slow := &stallingStore{release: make(chan struct{})}
+	defer close(slow.release)
+	reg.Add("slow.example", slow)
+
+	results, errs, err := FanOut(t.Context(), reg, "local", time.Second, func(ctx context.Context, s Store) ([]model.Project, error) {
+		return s.ListProjects(ctx)
+	})
+	require.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Len(t, results, 1)
+
+	_, _, err = FanOut(t.Context(), reg, "missing", time.Second, func(ctx context.Context, s Store) (int, error) { return 0, nil })
+	assert.Error(t, err)
+}
+
+func TestRegistry_AddLazy(t *testing.T) {
+	reg, _, _ := setupRegistry(t)
+	opened := 0
+	reg.AddLazy("cloud.example", func() (Store, error) {
+		opened++
+		return &stallingStore{release: make(chan struct{})}, nil
+	})
+	reg.AddLazy("broken.example", func() (Store, error) {
+		return nil, fmt.Errorf("bad CA bundle")
+	})
+
+	assert.Equal(t, []string{"broken.example", "cloud.example", "local"}, reg.Names())
+	assert.Equal(t, 0, opened, "lazy stores must not be constructed by Names")
+
+	_, err := reg.Get("cloud.example")
+	require.NoError(t, err)
+	_, err = reg.Get("cloud.example")
+	require.NoError(t, err)
+	assert.Equal(t, 1, opened)
+
+	_, err = reg.Get("broken.example")
+	assert.ErrorContains(t, err, "bad CA bundle")
+
+	_, errs, err := FanOut(t.Context(), reg, "broken.example", time.Second, func(ctx context.Context, s Store) (int, error) { return 0, nil })
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "broken.example", errs[0].Store)
+}
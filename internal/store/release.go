@@ -0,0 +1,294 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/id"
+	"github.com/rogersnm/compass/internal/model"
+)
+
+type ReleaseCreateOpts struct {
+	TargetDate string
+	Items      []string
+	Body       string
+}
+
+type ReleaseUpdate struct {
+	TargetDate *string
+	Items      *[]string
+	Status     *model.ReleaseStatus
+	Changelog  *string
+	Body       *string
+}
+
+func (s *LocalStore) CreateRelease(ctx context.Context, version, projectID string, opts ReleaseCreateOpts) (*model.Release, error) {
+	if _, _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, notFoundf("project %s not found", projectID)
+	}
+
+	existing, err := s.ListReleases(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range existing {
+		if r.Version == version {
+			return nil, conflictf("release %s already exists in project %s (%s)", version, projectID, r.ID)
+		}
+	}
+
+	if err := s.validateReleaseItems(ctx, opts.Items, projectID); err != nil {
+		return nil, err
+	}
+
+	rid, err := s.newID(ctx, projectID, id.Release)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &model.Release{
+		ID:         rid,
+		Version:    version,
+		Project:    projectID,
+		Status:     model.ReleasePlanned,
+		TargetDate: opts.TargetDate,
+		Items:      opts.Items,
+		CreatedBy:  CurrentUser(),
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+	}
+	if err := r.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+
+	path := filepath.Join(s.ProjectDir(projectID), "releases", rid+".md")
+	if err := s.WriteEntity(path, r, opts.Body); err != nil {
+		return nil, fmt.Errorf("writing release: %w", err)
+	}
+	return r, nil
+}
+
+func (s *LocalStore) GetRelease(ctx context.Context, releaseID string) (*model.Release, string, error) {
+	path, err := s.ResolveEntityPath(releaseID)
+	if err != nil {
+		return nil, "", err
+	}
+	r, body, err := readEntity[model.Release](s, path)
+	if err != nil {
+		return nil, "", err
+	}
+	return &r, body, nil
+}
+
+func (s *LocalStore) ListReleases(ctx context.Context, projectID string) ([]model.Release, error) {
+	var dirs []string
+	if projectID != "" {
+		dirs = []string{s.ProjectDir(projectID)}
+	} else {
+		var err error
+		dirs, err = s.listProjectDirs()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var releases []model.Release
+	for _, d := range dirs {
+		files, err := s.ListFiles(filepath.Join(d, "releases"), "*.md")
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			r, _, err := readEntity[model.Release](s, f)
+			if err != nil {
+				continue
+			}
+			releases = append(releases, r)
+		}
+	}
+	return releases, nil
+}
+
+func (s *LocalStore) UpdateRelease(ctx context.Context, releaseID string, upd ReleaseUpdate) (*model.Release, error) {
+	path, err := s.ResolveEntityPath(releaseID)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := lockEntity(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	r, body, err := ReadEntity[model.Release](path)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Status == model.ReleaseCut && (upd.Items != nil || upd.Status != nil) {
+		return nil, conflictf("release %s has already been cut", r.Version)
+	}
+
+	if upd.TargetDate != nil {
+		r.TargetDate = *upd.TargetDate
+	}
+	if upd.Items != nil {
+		if err := s.validateReleaseItems(ctx, *upd.Items, r.Project); err != nil {
+			return nil, err
+		}
+		r.Items = *upd.Items
+	}
+	if upd.Status != nil {
+		r.Status = *upd.Status
+		if r.Status == model.ReleaseCut {
+			cutAt := now()
+			r.CutAt = &cutAt
+		}
+	}
+	if upd.Changelog != nil {
+		r.Changelog = *upd.Changelog
+	}
+	if upd.Body != nil {
+		body = *upd.Body
+	}
+	r.UpdatedAt = now()
+
+	if err := r.Validate(); err != nil {
+		return nil, invalid(err)
+	}
+	if err := s.WriteEntity(path, &r, body); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *LocalStore) validateReleaseItems(ctx context.Context, items []string, projectID string) error {
+	for _, item := range items {
+		t, _, err := s.GetTask(ctx, item)
+		if err != nil {
+			return notFoundf("release item %s not found", item)
+		}
+		if t.Project != projectID {
+			return invalidf("release item %s is in project %s, not %s", item, t.Project, projectID)
+		}
+	}
+	return nil
+}
+
+// ReleaseTasks expands a release's items into the type=task tasks it covers.
+// Epic items contribute all of their child tasks. The returned map is keyed
+// by epic ID ("" for tasks included directly).
+func ReleaseTasks(ctx context.Context, s Store, r *model.Release) (map[string][]model.Task, error) {
+	grouped := make(map[string][]model.Task)
+	seen := make(map[string]bool)
+	for _, item := range r.Items {
+		t, _, err := s.GetTask(ctx, item)
+		if err != nil {
+			return nil, fmt.Errorf("release item %s: %w", item, err)
+		}
+		if t.Type == model.TypeEpic {
+			children, err := s.ListTasks(ctx, TaskFilter{ProjectID: r.Project, EpicID: t.ID, Type: model.TypeTask})
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range children {
+				if !seen[c.ID] {
+					seen[c.ID] = true
+					grouped[t.ID] = append(grouped[t.ID], c)
+				}
+			}
+			continue
+		}
+		if !seen[t.ID] {
+			seen[t.ID] = true
+			grouped[t.Epic] = append(grouped[t.Epic], *t)
+		}
+	}
+	return grouped, nil
+}
+
+// CutRelease verifies that every task included in a release is closed, writes
+// a changelog document to the release's project, and marks the release cut.
+// Nothing is written until every task has been checked, and the changelog is
+// deleted again if the release can't be marked cut.
+func CutRelease(ctx context.Context, s Store, releaseID string) (*model.Release, *model.Document, error) {
+	r, _, err := s.GetRelease(ctx, releaseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.Status == model.ReleaseCut {
+		return nil, nil, conflictf("release %s has already been cut", r.Version)
+	}
+
+	grouped, err := ReleaseTasks(ctx, s, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var open []string
+	for _, tasks := range grouped {
+		for _, t := range tasks {
+			if t.Status != model.StatusClosed {
+				open = append(open, t.ID)
+			}
+		}
+	}
+	if len(open) > 0 {
+		sort.Strings(open)
+		return nil, nil, conflictf("cannot cut release %s: %d task(s) not closed: %s", r.Version, len(open), strings.Join(open, ", "))
+	}
+
+	d, err := s.CreateDocument(ctx, fmt.Sprintf("Release %s changelog", r.Version), r.Project, DocumentCreateOpts{Body: renderChangelog(ctx, s, r, grouped)})
+	if err != nil {
+		return nil, nil, fmt.Errorf("writing changelog: %w", err)
+	}
+
+	cut := model.ReleaseCut
+	updated, err := s.UpdateRelease(ctx, r.ID, ReleaseUpdate{Status: &cut, Changelog: &d.ID})
+	if err != nil {
+		if derr := s.DeleteDocument(ctx, d.ID); derr != nil {
+			return nil, nil, fmt.Errorf("%w (rollback failed, remove manually: %s)", err, d.ID)
+		}
+		return nil, nil, err
+	}
+	return updated, d, nil
+}
+
+func renderChangelog(ctx context.Context, s Store, r *model.Release, grouped map[string][]model.Task) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Release %s\n\n", r.Version)
+	fmt.Fprintf(&sb, "Cut %s.\n", now().Format(model.DateFormat))
+
+	epicIDs := make([]string, 0, len(grouped))
+	for epicID := range grouped {
+		if epicID != "" {
+			epicIDs = append(epicIDs, epicID)
+		}
+	}
+	sort.Strings(epicIDs)
+	if _, ok := grouped[""]; ok {
+		epicIDs = append(epicIDs, "")
+	}
+
+	for _, epicID := range epicIDs {
+		heading := "Other changes"
+		if epicID != "" {
+			heading = epicID
+			if epic, _, err := s.GetTask(ctx, epicID); err == nil {
+				heading = fmt.Sprintf("%s (%s)", epic.Title, epic.ID)
+			}
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n", heading)
+
+		tasks := grouped[epicID]
+		sort.Slice(tasks, func(i, j int) bool {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		})
+		for _, t := range tasks {
+			fmt.Fprintf(&sb, "- %s (%s)\n", t.Title, t.ID)
+		}
+	}
+	return sb.String()
+}
@@ -1,114 +1,183 @@
 package store
 
 import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/rogersnm/compass/internal/model"
 )
 
 type SearchResult struct {
-	Type    string
-	ID      string
-	Title   string
-	Snippet string
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+// SearchOpts narrows a search. The zero value matches the query as a
+// case-insensitive substring of every entity's title and body.
+type SearchOpts struct {
+	ProjectID string
+	// Regex treats the query as a case-insensitive regular expression
+	// (RE2 syntax).
+	Regex bool
+	// TitleOnly and BodyOnly restrict matching to titles or to bodies.
+	TitleOnly bool
+	BodyOnly  bool
+	// Type limits results to one of SearchTypes.
+	Type string
+}
+
+// SearchTypes lists the result types a search can be limited to. Epics are
+// tasks.
+var SearchTypes = []string{"project", "task", "document"}
+
+func (o SearchOpts) validate() error {
+	if o.TitleOnly && o.BodyOnly {
+		return invalidf("title-only and body-only can't be combined")
+	}
+	if o.Type != "" && !slices.Contains(SearchTypes, o.Type) {
+		return invalidf("invalid search type %q: must be one of %s", o.Type, strings.Join(SearchTypes, ", "))
+	}
+	return nil
+}
+
+// wants reports whether results of type typ are included.
+func (o SearchOpts) wants(typ string) bool {
+	return o.Type == "" || o.Type == typ
 }
 
-func (s *LocalStore) Search(query, projectID string) ([]SearchResult, error) {
+// matcher returns the span of the first match of a query in text, or -1s.
+type matcher func(text string) (start, end int)
+
+func newMatcher(query string, regex bool) (matcher, error) {
+	if regex {
+		re, err := regexp.Compile("(?i)" + query)
+		if err != nil {
+			return nil, invalidf("invalid search pattern: %v", err)
+		}
+		return func(text string) (int, int) {
+			if loc := re.FindStringIndex(text); loc != nil {
+				return loc[0], loc[1]
+			}
+			return -1, -1
+		}, nil
+	}
 	q := strings.ToLower(query)
-	var results []SearchResult
+	return func(text string) (int, int) {
+		i := strings.Index(strings.ToLower(text), q)
+		if i < 0 {
+			return -1, -1
+		}
+		return i, i + len(q)
+	}, nil
+}
 
-	projects, err := s.ListProjects()
+func (s *LocalStore) Search(ctx context.Context, query string, opts SearchOpts) ([]SearchResult, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	match, err := newMatcher(query, opts.Regex)
 	if err != nil {
 		return nil, err
 	}
-	for _, p := range projects {
-		if projectID != "" && p.ID != projectID {
-			continue
-		}
-		if matchesQuery(q, p.Name) {
-			results = append(results, SearchResult{Type: "project", ID: p.ID, Title: p.Name})
+	var results []SearchResult
+	// add records a hit on an entity's title or body, preferring a body
+	// match's snippet.
+	add := func(typ, id, title, body string) {
+		if !opts.TitleOnly {
+			if start, end := match(body); start >= 0 {
+				results = append(results, SearchResult{Type: typ, ID: id, Title: title, Snippet: snippet(body, start, end)})
+				return
+			}
 		}
-		// Also search project body
-		if _, body, err := s.GetProject(p.ID); err == nil && matchesQuery(q, body) {
-			if !hasResult(results, p.ID) {
-				results = append(results, SearchResult{
-					Type: "project", ID: p.ID, Title: p.Name,
-					Snippet: snippet(body, q),
-				})
+		if !opts.BodyOnly {
+			if start, _ := match(title); start >= 0 {
+				results = append(results, SearchResult{Type: typ, ID: id, Title: title})
 			}
 		}
 	}
 
-	filterProject := projectID
-	docs, err := s.ListDocuments(filterProject)
-	if err != nil {
-		return nil, err
-	}
-	for _, d := range docs {
-		if matchesQuery(q, d.Title) {
-			results = append(results, SearchResult{Type: "document", ID: d.ID, Title: d.Title})
+	if opts.wants("project") {
+		projects, err := s.ListProjects(ctx)
+		if err != nil {
+			return nil, err
 		}
-		if _, body, err := s.GetDocument(d.ID); err == nil && matchesQuery(q, body) {
-			if !hasResult(results, d.ID) {
-				results = append(results, SearchResult{
-					Type: "document", ID: d.ID, Title: d.Title,
-					Snippet: snippet(body, q),
-				})
+		for _, p := range projects {
+			if opts.ProjectID != "" && p.ID != opts.ProjectID {
+				continue
 			}
+			var body string
+			if !opts.TitleOnly {
+				_, body, _ = s.GetProject(ctx, p.ID)
+			}
+			add("project", p.ID, p.Name, body)
 		}
 	}
 
-	tasks, err := s.ListTasks(TaskFilter{ProjectID: filterProject})
-	if err != nil {
-		return nil, err
+	if opts.wants("document") {
+		docs, err := s.ListDocuments(ctx, DocumentFilter{ProjectID: opts.ProjectID})
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range docs {
+			var body string
+			if !opts.TitleOnly {
+				_, body, _ = s.GetDocument(ctx, d.ID)
+			}
+			add("document", d.ID, d.Title, body)
+		}
 	}
-	for _, t := range tasks {
-		if matchesQuery(q, t.Title) {
-			results = append(results, SearchResult{Type: "task", ID: t.ID, Title: t.Title})
+
+	if opts.wants("task") {
+		tasks, err := s.ListTasks(ctx, TaskFilter{ProjectID: opts.ProjectID})
+		if err != nil {
+			return nil, err
 		}
-		if _, body, err := s.GetTask(t.ID); err == nil && matchesQuery(q, body) {
-			if !hasResult(results, t.ID) {
-				results = append(results, SearchResult{
-					Type: "task", ID: t.ID, Title: t.Title,
-					Snippet: snippet(body, q),
-				})
+		for _, t := range tasks {
+			var body string
+			if !opts.TitleOnly {
+				_, body, _ = s.GetTask(ctx, t.ID)
 			}
+			add("task", t.ID, t.Title, body)
 		}
 	}
 
 	return results, nil
 }
 
-func matchesQuery(q, text string) bool {
-	return strings.Contains(strings.ToLower(text), q)
-}
-
-func hasResult(results []SearchResult, id string) bool {
-	for _, r := range results {
-		if r.ID == id {
-			return true
+// Bodies returns the body of every task and document in a project by ID,
+// reading each file once.
+func (s *LocalStore) Bodies(ctx context.Context, projectID string) (map[string]string, error) {
+	dir := s.ProjectDir(projectID)
+	bodies := map[string]string{}
+	files, _ := s.ListFiles(filepath.Join(dir, "tasks"), "*.md")
+	for _, f := range files {
+		if t, body, err := readEntity[model.Task](s, f); err == nil {
+			bodies[t.ID] = body
 		}
 	}
-	return false
+	files, _ = s.ListFiles(filepath.Join(dir, "documents"), "*.md")
+	for _, f := range files {
+		if d, body, err := readEntity[model.Document](s, f); err == nil {
+			bodies[d.ID] = body
+		}
+	}
+	return bodies, nil
 }
 
-func snippet(body, query string) string {
-	lower := strings.ToLower(body)
-	idx := strings.Index(lower, query)
-	if idx < 0 {
-		return ""
-	}
-	start := idx - 40
-	if start < 0 {
-		start = 0
-	}
-	end := idx + len(query) + 40
-	if end > len(body) {
-		end = len(body)
-	}
-	s := body[start:end]
-	if start > 0 {
+// snippet returns the text around body[start:end] on one line.
+func snippet(body string, start, end int) string {
+	from := max(start-40, 0)
+	to := min(end+40, len(body))
+	s := body[from:to]
+	if from > 0 {
 		s = "..." + s
 	}
-	if end < len(body) {
+	if to < len(body) {
 		s = s + "..."
 	}
 	return strings.ReplaceAll(s, "\n", " ")
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// Similar is a task or document that shares words with another entity.
+type Similar struct {
+	Type   string       `json:"type"` // "task" or "document"
+	ID     string       `json:"id"`
+	Title  string       `json:"title"`
+	Status model.Status `json:"status,omitempty"`
+	// Score is the weighted share of words the two have in common, from
+	// 0 to 1.
+	Score float64 `json:"score"`
+}
+
+// minSimilarity is the lowest score worth reporting.
+const minSimilarity = 0.05
+
+// SimilarTasks returns the tasks and documents in a task's project that
+// share the most words with it, best first and at most limit of them (all
+// when limit is 0). Title words count twice as much as body words. The
+// project is listed once and scored in memory.
+func SimilarTasks(ctx context.Context, s Store, taskID string, limit int) ([]Similar, error) {
+	t, body, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	want := wordWeights(t.Title, body)
+
+	var found []Similar
+	consider := func(typ, id, title string, status model.Status, body string) {
+		if score := overlap(want, wordWeights(title, body)); score >= minSimilarity {
+			found = append(found, Similar{Type: typ, ID: id, Title: title, Status: status, Score: score})
+		}
+	}
+	bodies, err := s.Bodies(ctx, t.Project)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.ListTasks(ctx, TaskFilter{ProjectID: t.Project})
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range tasks {
+		if other.ID != t.ID {
+			consider("task", other.ID, other.Title, other.Status, bodies[other.ID])
+		}
+	}
+	docs, err := s.ListDocuments(ctx, DocumentFilter{ProjectID: t.Project})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		consider("document", d.ID, d.Title, "", bodies[d.ID])
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Score != found[j].Score {
+			return found[i].Score > found[j].Score
+		}
+		return found[i].ID < found[j].ID
+	})
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+// stopWords are too common to say anything about what a task is about.
+var stopWords = map[string]bool{
+	"and": true, "are": true, "but": true, "for": true, "from": true,
+	"has": true, "have": true, "into": true, "not": true, "that": true,
+	"the": true, "then": true, "this": true, "was": true, "when": true,
+	"will": true, "with": true, "should": true, "can": true, "all": true,
+}
+
+// wordWeights returns the distinct words of a title and body, weighted 2
+// for title words and 1 for the rest. Words shorter than three letters and
+// stop words are dropped.
+func wordWeights(title, body string) map[string]float64 {
+	w := map[string]float64{}
+	add := func(text string, weight float64) {
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if len(word) < 3 || stopWords[word] {
+				continue
+			}
+			w[word] = max(w[word], weight)
+		}
+	}
+	add(body, 1)
+	add(title, 2)
+	return w
+}
+
+// overlap is the weighted Jaccard similarity of two word sets.
+func overlap(a, b map[string]float64) float64 {
+	var shared, total float64
+	for word, wa := range a {
+		wb := b[word]
+		shared += min(wa, wb)
+		total += max(wa, wb)
+	}
+	for word, wb := range b {
+		if _, ok := a[word]; !ok {
+			total += wb
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return shared / total
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/id"
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// ParseSlugRef splits a reference like "AUTH/login-form" into its project
+// key and slug, reporting false for anything else, IDs included.
+func ParseSlugRef(ref string) (key, slug string, ok bool) {
+	key, slug, found := strings.Cut(ref, "/")
+	if !found || id.ValidateKey(key) != nil || model.ValidateSlug(slug) != nil {
+		return "", "", false
+	}
+	return key, slug, true
+}
+
+// Slugs maps the slugs of projectID's tasks and documents to their IDs.
+func Slugs(ctx context.Context, s Store, projectID string) (map[string]string, error) {
+	slugs := map[string]string{}
+	tasks, err := s.ListTasks(ctx, TaskFilter{ProjectID: projectID})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		if t.Slug != "" {
+			slugs[t.Slug] = t.ID
+		}
+	}
+	docs, err := s.ListDocuments(ctx, DocumentFilter{ProjectID: projectID})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		if d.Slug != "" {
+			slugs[d.Slug] = d.ID
+		}
+	}
+	return slugs, nil
+}
+
+// ResolveSlug returns the ID of the task or document in projectID with
+// slug.
+func ResolveSlug(ctx context.Context, s Store, projectID, slug string) (string, error) {
+	slugs, err := Slugs(ctx, s, projectID)
+	if err != nil {
+		return "", err
+	}
+	entityID, ok := slugs[slug]
+	if !ok {
+		return "", notFoundf("no task or document in %s has slug %q", projectID, slug)
+	}
+	return entityID, nil
+}
+
+// FreeSlug returns base, or base with the first free "-2", "-3"... suffix
+// if an entity in projectID other than selfID already has it.
+func FreeSlug(ctx context.Context, s Store, projectID, base, selfID string) (string, error) {
+	slugs, err := Slugs(ctx, s, projectID)
+	if err != nil {
+		return "", err
+	}
+	slug := base
+	for n := 2; slugs[slug] != "" && slugs[slug] != selfID; n++ {
+		suffix := "-" + strconv.Itoa(n)
+		slug = strings.TrimRight(base[:min(len(base), model.MaxSlugLen-len(suffix))], "-") + suffix
+	}
+	return slug, nil
+}
+
+// checkSlug fails with ErrValidation for a malformed slug and with
+// ErrConflict when an entity in projectID other than selfID already has it.
+func checkSlug(ctx context.Context, s Store, projectID, slug, selfID string) error {
+	if slug == "" {
+		return nil
+	}
+	if err := model.ValidateSlug(slug); err != nil {
+		return invalid(err)
+	}
+	slugs, err := Slugs(ctx, s, projectID)
+	if err != nil {
+		return err
+	}
+	if other := slugs[slug]; other != "" && other != selfID {
+		return conflictf("slug %q is already used by %s", slug, other)
+	}
+	return nil
+}
+
+// checkNewSlug is checkSlug for giving the existing entity entityID slug.
+func checkNewSlug(ctx context.Context, s Store, entityID, slug string) error {
+	projectID, err := id.ProjectKeyFrom(entityID)
+	if err != nil {
+		return err
+	}
+	return checkSlug(ctx, s, projectID, slug, entityID)
+}
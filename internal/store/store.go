@@ -1,10 +1,12 @@
 package store
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/user"
 	"path/filepath"
+	"sync/atomic"
 	"time"
 
 	"github.com/rogersnm/compass/internal/id"
@@ -15,6 +17,9 @@ import (
 // LocalStore implements Store using the local filesystem.
 type LocalStore struct {
 	BaseDir string
+	cache   *entityCache    // nil unless EnableCache was called
+	deps    model.DepPolicy // how ReadyTasks treats missing dependencies
+	changed atomic.Bool     // set by every write, for Changed
 }
 
 // compile-time check
@@ -24,6 +29,12 @@ func NewLocal(baseDir string) *LocalStore {
 	return &LocalStore{BaseDir: baseDir}
 }
 
+// SetDepPolicy sets how ReadyTasks treats dependencies that no longer
+// exist. The default, model.StrictDeps, counts them as blocking.
+func (s *LocalStore) SetDepPolicy(p model.DepPolicy) {
+	s.deps = p
+}
+
 func (s *LocalStore) ProjectsDir() string {
 	return filepath.Join(s.BaseDir, "projects")
 }
@@ -45,7 +56,14 @@ func (s *LocalStore) EnsureProjectDirs(projectKey string) error {
 	return nil
 }
 
+// Changed reports whether the store has written or removed any entity since
+// it was opened.
+func (s *LocalStore) Changed() bool {
+	return s.changed.Load()
+}
+
 func (s *LocalStore) WriteEntity(path string, meta any, body string) error {
+	s.changed.Store(true)
 	data, err := markdown.Marshal(meta, body)
 	if err != nil {
 		return err
@@ -53,7 +71,32 @@ func (s *LocalStore) WriteEntity(path string, meta any, body string) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return fmt.Errorf("creating parent dir: %w", err)
 	}
-	return os.WriteFile(path, data, 0644)
+	return WriteFileAtomic(path, data)
+}
+
+// WriteFileAtomic writes data to a temporary file beside path and renames it
+// into place, so readers never see a partially written file.
+func WriteFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
 }
 
 func ReadEntity[T any](path string) (T, string, error) {
@@ -76,27 +119,33 @@ func (s *LocalStore) ListFiles(dir, pattern string) ([]string, error) {
 
 // ResolveEntityPath computes the file path for an entity ID directly from the ID structure.
 func (s *LocalStore) ResolveEntityPath(entityID string) (string, error) {
-	key, entityType, _, err := id.Parse(entityID)
+	path, err := s.entityPath(entityID)
 	if err != nil {
 		return "", err
 	}
+	if _, err := os.Stat(path); err != nil {
+		return "", notFoundf("%s not found", entityID)
+	}
+	return path, nil
+}
 
-	var path string
+// entityPath is where an entity's file is, or would be once created.
+func (s *LocalStore) entityPath(entityID string) (string, error) {
+	key, entityType, _, err := id.Parse(entityID)
+	if err != nil {
+		return "", err
+	}
 	switch entityType {
 	case id.Project:
-		path = filepath.Join(s.ProjectDir(key), "project.md")
+		return filepath.Join(s.ProjectDir(key), "project.md"), nil
 	case id.Task:
-		path = filepath.Join(s.ProjectDir(key), "tasks", entityID+".md")
+		return filepath.Join(s.ProjectDir(key), "tasks", entityID+".md"), nil
 	case id.Document:
-		path = filepath.Join(s.ProjectDir(key), "documents", entityID+".md")
-	default:
-		return "", fmt.Errorf("unknown entity type for %s", entityID)
-	}
-
-	if _, err := os.Stat(path); err != nil {
-		return "", fmt.Errorf("%s not found", entityID)
+		return filepath.Join(s.ProjectDir(key), "documents", entityID+".md"), nil
+	case id.Release:
+		return filepath.Join(s.ProjectDir(key), "releases", entityID+".md"), nil
 	}
-	return path, nil
+	return "", fmt.Errorf("unknown entity type for %s", entityID)
 }
 
 func (s *LocalStore) listProjectDirs() ([]string, error) {
@@ -117,7 +166,7 @@ func (s *LocalStore) listProjectDirs() ([]string, error) {
 }
 
 // DownloadEntity copies an entity's .md file to destDir/<ID>.md and returns the local path.
-func (s *LocalStore) DownloadEntity(entityID, destDir string) (string, error) {
+func (s *LocalStore) DownloadEntity(ctx context.Context, entityID, destDir string) (string, error) {
 	srcPath, err := s.ResolveEntityPath(entityID)
 	if err != nil {
 		return "", err
@@ -137,7 +186,7 @@ func (s *LocalStore) DownloadEntity(entityID, destDir string) (string, error) {
 }
 
 // UploadTask reads a local task file, validates it, writes it back to the store, and removes the local file.
-func (s *LocalStore) UploadTask(localPath string) (*model.Task, error) {
+func (s *LocalStore) UploadTask(ctx context.Context, localPath string) (*model.Task, error) {
 	t, body, err := ReadEntity[model.Task](localPath)
 	if err != nil {
 		return nil, fmt.Errorf("reading local file: %w", err)
@@ -147,10 +196,10 @@ func (s *LocalStore) UploadTask(localPath string) (*model.Task, error) {
 		t.Status = ""
 	}
 	if err := t.Validate(); err != nil {
-		return nil, err
+		return nil, invalid(err)
 	}
 	if len(t.DependsOn) > 0 {
-		if err := s.validateDeps(&t, t.Project); err != nil {
+		if err := validateDeps(ctx, s, &t, t.Project); err != nil {
 			return nil, err
 		}
 	}
@@ -167,13 +216,13 @@ func (s *LocalStore) UploadTask(localPath string) (*model.Task, error) {
 }
 
 // UploadDocument reads a local document file, validates it, writes it back to the store, and removes the local file.
-func (s *LocalStore) UploadDocument(localPath string) (*model.Document, error) {
+func (s *LocalStore) UploadDocument(ctx context.Context, localPath string) (*model.Document, error) {
 	d, body, err := ReadEntity[model.Document](localPath)
 	if err != nil {
 		return nil, fmt.Errorf("reading local file: %w", err)
 	}
 	if err := d.Validate(); err != nil {
-		return nil, err
+		return nil, invalid(err)
 	}
 	d.UpdatedAt = now()
 	storePath, err := s.ResolveEntityPath(d.ID)
@@ -187,11 +236,65 @@ func (s *LocalStore) UploadDocument(localPath string) (*model.Document, error) {
 	return &d, nil
 }
 
+// newEntityID generates IDs; tests replace it to force collisions.
+var newEntityID = id.New
+
+// maxIDAttempts is how many IDs newID draws before giving up.
+const maxIDAttempts = 10
+
+// newID returns an unused ID for a new entity of type kind in projectID,
+// with the project's id_length. A random ID that names an existing entity
+// is drawn again.
+func (s *LocalStore) newID(ctx context.Context, projectID string, kind id.EntityType) (string, error) {
+	length := id.DefaultHashLen
+	if p, _, err := s.GetProject(ctx, projectID); err == nil && p.IDLength != 0 {
+		length = p.IDLength
+	}
+	for range maxIDAttempts {
+		entityID, err := newEntityID(projectID, kind, length)
+		if err != nil {
+			return "", err
+		}
+		path, err := s.entityPath(entityID)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return entityID, nil
+		}
+	}
+	return "", conflictf("no unused %s ID in %s after %d attempts; raise id_length in its project.md", kind, projectID, maxIDAttempts)
+}
+
 func now() time.Time {
 	return time.Now().UTC().Truncate(time.Second)
 }
 
-func currentUser() string {
+// actor overrides the OS user as the author of changes; see SetActor.
+var actor string
+
+// SetActor records changes as made by name, such as an agent or bot
+// identity, rather than the OS user. An empty name falls back to
+// COMPASS_ACTOR and then the OS user.
+func SetActor(name string) {
+	actor = name
+}
+
+// Actor returns the identity set by SetActor or COMPASS_ACTOR, or "" when
+// changes are attributed to the OS user.
+func Actor() string {
+	if actor != "" {
+		return actor
+	}
+	return os.Getenv("COMPASS_ACTOR")
+}
+
+// CurrentUser is the name recorded as the author of new entities and
+// status changes: the actor if one is set, otherwise the OS user.
+func CurrentUser() string {
+	if a := Actor(); a != "" {
+		return a
+	}
 	if u, err := user.Current(); err == nil {
 		return u.Username
 	}
@@ -1,40 +1,63 @@
 package store
 
-import "github.com/rogersnm/compass/internal/model"
+import (
+	"context"
+
+	"github.com/rogersnm/compass/internal/model"
+)
 
 // Store defines the interface for all storage operations. LocalStore implements
 // this for file-based storage; CloudStore will implement it for HTTP-backed storage.
+// Operations take a context so that cancelling it, as Ctrl-C does, stops
+// requests in flight; the local filesystem ignores it. ResolveEntityPath and
+// WriteEntity only touch local files.
 type Store interface {
 	// Projects
-	CreateProject(name, key, body string) (*model.Project, error)
-	GetProject(projectID string) (*model.Project, string, error)
-	ListProjects() ([]model.Project, error)
-	DeleteProject(projectID string) error
+	CreateProject(ctx context.Context, name, key, body string) (*model.Project, error)
+	GetProject(ctx context.Context, projectID string) (*model.Project, string, error)
+	ListProjects(ctx context.Context) ([]model.Project, error)
+	DeleteProject(ctx context.Context, projectID string) error
+	UpdateProject(ctx context.Context, projectID string, upd ProjectUpdate) (*model.Project, error)
+	RekeyProject(ctx context.Context, oldKey, newKey string) (*model.Project, error)
 
 	// Tasks
-	CreateTask(title, projectID string, opts TaskCreateOpts) (*model.Task, error)
-	GetTask(taskID string) (*model.Task, string, error)
-	ListTasks(filter TaskFilter) ([]model.Task, error)
-	UpdateTask(taskID string, upd TaskUpdate) (*model.Task, error)
-	DeleteTask(taskID string) error
-	AllTaskMap(projectID string) (map[string]*model.Task, error)
-	ReadyTasks(projectID string) ([]*model.Task, error)
+	CreateTask(ctx context.Context, title, projectID string, opts TaskCreateOpts) (*model.Task, error)
+	GetTask(ctx context.Context, taskID string) (*model.Task, string, error)
+	ListTasks(ctx context.Context, filter TaskFilter) ([]model.Task, error)
+	ListTasksPage(ctx context.Context, filter TaskFilter, page PageOpts) ([]model.Task, string, error)
+	UpdateTask(ctx context.Context, taskID string, upd TaskUpdate) (*model.Task, error)
+	DeleteTask(ctx context.Context, taskID string) error
+	MoveTask(ctx context.Context, taskID, projectID string) (*model.Task, error)
+	AllTaskMap(ctx context.Context, projectID string) (map[string]*model.Task, error)
+	ReadyTasks(ctx context.Context, projectID string) ([]*model.Task, error)
+	ClaimTask(ctx context.Context, projectID string) (*model.Task, error)
 
 	// Documents
-	CreateDocument(title, projectID, body string) (*model.Document, error)
-	GetDocument(docID string) (*model.Document, string, error)
-	ListDocuments(projectID string) ([]model.Document, error)
-	UpdateDocument(docID string, title, body *string) (*model.Document, error)
-	DeleteDocument(docID string) error
+	CreateDocument(ctx context.Context, title, projectID string, opts DocumentCreateOpts) (*model.Document, error)
+	GetDocument(ctx context.Context, docID string) (*model.Document, string, error)
+	ListDocuments(ctx context.Context, filter DocumentFilter) ([]model.Document, error)
+	ListDocumentsPage(ctx context.Context, filter DocumentFilter, page PageOpts) ([]model.Document, string, error)
+	UpdateDocument(ctx context.Context, docID string, upd DocumentUpdate) (*model.Document, error)
+	DeleteDocument(ctx context.Context, docID string) error
+
+	// Releases
+	CreateRelease(ctx context.Context, version, projectID string, opts ReleaseCreateOpts) (*model.Release, error)
+	GetRelease(ctx context.Context, releaseID string) (*model.Release, string, error)
+	ListReleases(ctx context.Context, projectID string) ([]model.Release, error)
+	UpdateRelease(ctx context.Context, releaseID string, upd ReleaseUpdate) (*model.Release, error)
 
 	// Search
-	Search(query, projectID string) ([]SearchResult, error)
+	Search(ctx context.Context, query string, opts SearchOpts) ([]SearchResult, error)
+	Bodies(ctx context.Context, projectID string) (map[string]string, error)
 
 	// Entity operations
 	ResolveEntityPath(entityID string) (string, error)
-	DownloadEntity(entityID, destDir string) (string, error)
-	UploadTask(localPath string) (*model.Task, error)
-	UploadDocument(localPath string) (*model.Document, error)
+	DownloadEntity(ctx context.Context, entityID, destDir string) (string, error)
+	UploadTask(ctx context.Context, localPath string) (*model.Task, error)
+	UploadDocument(ctx context.Context, localPath string) (*model.Document, error)
+
+	// Health: a cheap authenticated round trip, bounded by ctx
+	Ping(ctx context.Context) (PingResult, error)
 
 	// Low-level (used by commands for raw file access)
 	WriteEntity(path string, meta any, body string) error
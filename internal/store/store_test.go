@@ -1,8 +1,18 @@
 package store
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/rogersnm/compass/internal/dag"
+	"github.com/rogersnm/compass/internal/id"
+	"github.com/rogersnm/compass/internal/markdown"
 	"github.com/rogersnm/compass/internal/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,10 +55,10 @@ func TestWriteEntity_ReadEntity_RoundTrip(t *testing.T) {
 
 func TestResolveEntityPath_Task(t *testing.T) {
 	s := newTestStore(t)
-	p, err := s.CreateProject("Test Project", "", "")
+	p, err := s.CreateProject(t.Context(), "Test Project", "", "")
 	require.NoError(t, err)
 
-	task, err := s.CreateTask("My Task", p.ID, TaskCreateOpts{})
+	task, err := s.CreateTask(t.Context(), "My Task", p.ID, TaskCreateOpts{})
 	require.NoError(t, err)
 
 	path, err := s.ResolveEntityPath(task.ID)
@@ -64,14 +74,14 @@ func TestResolveEntityPath_NotFound(t *testing.T) {
 
 func TestResolveEntityPath_AcrossProjects(t *testing.T) {
 	s := newTestStore(t)
-	p1, err := s.CreateProject("Project One", "", "")
+	p1, err := s.CreateProject(t.Context(), "Project One", "", "")
 	require.NoError(t, err)
-	p2, err := s.CreateProject("Project Two", "", "")
+	p2, err := s.CreateProject(t.Context(), "Project Two", "", "")
 	require.NoError(t, err)
 
-	_, err = s.CreateTask("Task1", p1.ID, TaskCreateOpts{})
+	_, err = s.CreateTask(t.Context(), "Task1", p1.ID, TaskCreateOpts{})
 	require.NoError(t, err)
-	t2, err := s.CreateTask("Task2", p2.ID, TaskCreateOpts{})
+	t2, err := s.CreateTask(t.Context(), "Task2", p2.ID, TaskCreateOpts{})
 	require.NoError(t, err)
 
 	path, err := s.ResolveEntityPath(t2.ID)
@@ -83,7 +93,7 @@ func TestResolveEntityPath_AcrossProjects(t *testing.T) {
 
 func TestCreateProject(t *testing.T) {
 	s := newTestStore(t)
-	p, err := s.CreateProject("My Project", "", "")
+	p, err := s.CreateProject(t.Context(), "My Project", "", "")
 	require.NoError(t, err)
 	assert.NotEmpty(t, p.ID)
 	assert.Equal(t, "My Project", p.Name)
@@ -94,58 +104,58 @@ func TestCreateProject(t *testing.T) {
 
 func TestCreateProject_AutoKey(t *testing.T) {
 	s := newTestStore(t)
-	p, err := s.CreateProject("Authentication Service", "", "")
+	p, err := s.CreateProject(t.Context(), "Authentication Service", "", "")
 	require.NoError(t, err)
 	assert.Equal(t, "AUTH", p.ID)
 }
 
 func TestCreateProject_AutoKeyCollision(t *testing.T) {
 	s := newTestStore(t)
-	p1, err := s.CreateProject("Authentication", "", "")
+	p1, err := s.CreateProject(t.Context(), "Authentication", "", "")
 	require.NoError(t, err)
 	assert.Equal(t, "AUTH", p1.ID)
 
-	p2, err := s.CreateProject("Authorization", "", "")
+	p2, err := s.CreateProject(t.Context(), "Authorization", "", "")
 	require.NoError(t, err)
 	assert.Equal(t, "AUTH2", p2.ID)
 }
 
 func TestCreateProject_ExplicitKey(t *testing.T) {
 	s := newTestStore(t)
-	p, err := s.CreateProject("Backend API", "API", "")
+	p, err := s.CreateProject(t.Context(), "Backend API", "API", "")
 	require.NoError(t, err)
 	assert.Equal(t, "API", p.ID)
 }
 
 func TestCreateProject_ExplicitKeyCollision(t *testing.T) {
 	s := newTestStore(t)
-	_, err := s.CreateProject("Backend API", "API", "")
+	_, err := s.CreateProject(t.Context(), "Backend API", "API", "")
 	require.NoError(t, err)
 
-	_, err = s.CreateProject("Another API", "API", "")
+	_, err = s.CreateProject(t.Context(), "Another API", "API", "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "already exists")
 }
 
 func TestCreateProject_EmptyName(t *testing.T) {
 	s := newTestStore(t)
-	_, err := s.CreateProject("", "", "")
+	_, err := s.CreateProject(t.Context(), "", "", "")
 	assert.Error(t, err)
 }
 
 func TestCreateProject_ShortNameNeedsKey(t *testing.T) {
 	s := newTestStore(t)
-	_, err := s.CreateProject("X", "", "")
+	_, err := s.CreateProject(t.Context(), "X", "", "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "need at least 2 alpha")
 }
 
 func TestGetProject(t *testing.T) {
 	s := newTestStore(t)
-	p, err := s.CreateProject("Test Project", "TP", "project body")
+	p, err := s.CreateProject(t.Context(), "Test Project", "TP", "project body")
 	require.NoError(t, err)
 
-	got, body, err := s.GetProject(p.ID)
+	got, body, err := s.GetProject(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.Equal(t, p.ID, got.ID)
 	assert.Equal(t, "Test Project", got.Name)
@@ -154,106 +164,367 @@ func TestGetProject(t *testing.T) {
 
 func TestGetProject_NotFound(t *testing.T) {
 	s := newTestStore(t)
-	_, _, err := s.GetProject("ZZZZ")
+	_, _, err := s.GetProject(t.Context(), "ZZZZ")
 	assert.Error(t, err)
 }
 
 func TestListProjects_Empty(t *testing.T) {
 	s := newTestStore(t)
-	projects, err := s.ListProjects()
+	projects, err := s.ListProjects(t.Context())
 	require.NoError(t, err)
 	assert.Empty(t, projects)
 }
 
 func TestListProjects_Multiple(t *testing.T) {
 	s := newTestStore(t)
-	s.CreateProject("Project One", "PR", "")
-	s.CreateProject("Second Proj", "SP", "")
-	s.CreateProject("Third Thing", "TH", "")
+	s.CreateProject(t.Context(), "Project One", "PR", "")
+	s.CreateProject(t.Context(), "Second Proj", "SP", "")
+	s.CreateProject(t.Context(), "Third Thing", "TH", "")
 
-	projects, err := s.ListProjects()
+	projects, err := s.ListProjects(t.Context())
 	require.NoError(t, err)
 	assert.Len(t, projects, 3)
 }
 
+func TestUpdateProject(t *testing.T) {
+	s := newTestStore(t)
+	s.CreateProject(t.Context(), "Old Name", "TP", "Old overview")
+
+	name := "New Name"
+	p, err := s.UpdateProject(t.Context(), "TP", ProjectUpdate{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "TP", p.ID)
+
+	got, body, err := s.GetProject(t.Context(), "TP")
+	require.NoError(t, err)
+	assert.Equal(t, "New Name", got.Name)
+	assert.Equal(t, "Old overview", strings.TrimSpace(body))
+
+	newBody := "## Goals\n\nShip it."
+	_, err = s.UpdateProject(t.Context(), "TP", ProjectUpdate{Body: &newBody})
+	require.NoError(t, err)
+	got, body, _ = s.GetProject(t.Context(), "TP")
+	assert.Equal(t, "New Name", got.Name)
+	assert.Equal(t, newBody, strings.TrimSpace(body))
+
+	empty := ""
+	_, err = s.UpdateProject(t.Context(), "TP", ProjectUpdate{Name: &empty})
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestRekeyProject(t *testing.T) {
+	s := newTestStore(t)
+	s.CreateProject(t.Context(), "Test Project", "TP", "")
+	epic, _ := s.CreateTask(t.Context(), "Epic", "TP", TaskCreateOpts{Type: model.TypeEpic})
+	dep, _ := s.CreateTask(t.Context(), "Dep", "TP", TaskCreateOpts{})
+	task, _ := s.CreateTask(t.Context(), "Task", "TP", TaskCreateOpts{Epic: epic.ID, DependsOn: []string{dep.ID}, Body: "body"})
+	doc, _ := s.CreateDocument(t.Context(), "Doc", "TP", DocumentCreateOpts{})
+	rel, _ := s.CreateRelease(t.Context(), "1.0.0", "TP", ReleaseCreateOpts{Items: []string{task.ID}})
+
+	p, err := s.RekeyProject(t.Context(), "TP", "NEW")
+	require.NoError(t, err)
+	assert.Equal(t, "NEW", p.ID)
+	assert.Equal(t, "Test Project", p.Name)
+
+	newID := func(old string) string { return "NEW" + strings.TrimPrefix(old, "TP") }
+	got, body, err := s.GetTask(t.Context(), newID(task.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "NEW", got.Project)
+	assert.Equal(t, newID(epic.ID), got.Epic)
+	assert.Equal(t, []string{newID(dep.ID)}, got.DependsOn)
+	assert.Equal(t, "body", body)
+
+	gotDoc, _, err := s.GetDocument(t.Context(), newID(doc.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "NEW", gotDoc.Project)
+
+	gotRel, _, err := s.GetRelease(t.Context(), newID(rel.ID))
+	require.NoError(t, err)
+	assert.Equal(t, []string{newID(task.ID)}, gotRel.Items)
+
+	_, _, err = s.GetProject(t.Context(), "TP")
+	assert.Error(t, err)
+	_, _, err = s.GetTask(t.Context(), task.ID)
+	assert.Error(t, err)
+}
+
+func TestRekeyProject_Errors(t *testing.T) {
+	s := newTestStore(t)
+	s.CreateProject(t.Context(), "One", "ONE", "")
+	s.CreateProject(t.Context(), "Two", "TWO", "")
+
+	_, err := s.RekeyProject(t.Context(), "ONE", "TWO")
+	assert.ErrorContains(t, err, "already exists")
+	_, err = s.RekeyProject(t.Context(), "ONE", "bad-key")
+	assert.Error(t, err)
+	_, err = s.RekeyProject(t.Context(), "ZZZ", "NEW")
+	assert.ErrorContains(t, err, "not found")
+}
+
 // --- Document tests ---
 
 func TestCreateDocument(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 
-	d, err := s.CreateDocument("My Doc", p.ID, "")
+	d, err := s.CreateDocument(t.Context(), "My Doc", p.ID, DocumentCreateOpts{})
 	require.NoError(t, err)
 	assert.NotEmpty(t, d.ID)
 	assert.Equal(t, "My Doc", d.Title)
 	assert.Equal(t, p.ID, d.Project)
 }
 
+func TestCreate_IDCollision(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	first, err := s.CreateTask(t.Context(), "First", p.ID, TaskCreateOpts{})
+	require.NoError(t, err)
+
+	// The first draw repeats an existing ID, the second is free.
+	draws := []string{first.ID, p.ID + "-T22222"}
+	newEntityID = func(key string, kind id.EntityType, length int) (string, error) {
+		next := draws[0]
+		if len(draws) > 1 {
+			draws = draws[1:]
+		}
+		return next, nil
+	}
+	t.Cleanup(func() { newEntityID = id.New })
+
+	second, err := s.CreateTask(t.Context(), "Second", p.ID, TaskCreateOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, p.ID+"-T22222", second.ID)
+	got, _, err := s.GetTask(t.Context(), first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "First", got.Title, "existing task left alone")
+
+	// Every draw taken.
+	_, err = s.CreateTask(t.Context(), "Third", p.ID, TaskCreateOpts{})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorContains(t, err, "id_length")
+}
+
+func TestCreate_IDLength(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	path, err := s.ResolveEntityPath(p.ID)
+	require.NoError(t, err)
+	proj, body, err := ReadEntity[model.Project](path)
+	require.NoError(t, err)
+	proj.IDLength = 7
+	require.NoError(t, s.WriteEntity(path, &proj, body))
+
+	task, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+	require.NoError(t, err)
+	_, _, hash, err := id.Parse(task.ID)
+	require.NoError(t, err)
+	assert.Len(t, hash, 7)
+	d, err := s.CreateDocument(t.Context(), "Doc", p.ID, DocumentCreateOpts{})
+	require.NoError(t, err)
+	assert.Len(t, d.ID, len(p.ID)+2+7)
+	got, _, err := s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Task", got.Title)
+}
+
+func TestSlugs(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, err := s.CreateTask(t.Context(), "Login form", p.ID, TaskCreateOpts{Slug: "login-form"})
+	require.NoError(t, err)
+
+	_, err = s.CreateDocument(t.Context(), "Login spec", p.ID, DocumentCreateOpts{Slug: "login-form"})
+	assert.ErrorIs(t, err, ErrConflict, "slugs are shared by tasks and documents")
+	_, err = s.CreateTask(t.Context(), "Bad", p.ID, TaskCreateOpts{Slug: "Login Form"})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	d, err := s.CreateDocument(t.Context(), "Login spec", p.ID, DocumentCreateOpts{Slug: "login-spec"})
+	require.NoError(t, err)
+	taken := "login-spec"
+	_, err = s.UpdateTask(t.Context(), task.ID, TaskUpdate{Slug: &taken})
+	assert.ErrorIs(t, err, ErrConflict)
+	same := "login-form"
+	_, err = s.UpdateTask(t.Context(), task.ID, TaskUpdate{Slug: &same})
+	assert.NoError(t, err, "an entity may keep its own slug")
+
+	got, err := ResolveSlug(t.Context(), s, p.ID, "login-spec")
+	require.NoError(t, err)
+	assert.Equal(t, d.ID, got)
+	_, err = ResolveSlug(t.Context(), s, p.ID, "nope")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	free, err := FreeSlug(t.Context(), s, p.ID, "login-form", "")
+	require.NoError(t, err)
+	assert.Equal(t, "login-form-2", free)
+	free, err = FreeSlug(t.Context(), s, p.ID, "login-form", task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "login-form", free)
+
+	key, slug, ok := ParseSlugRef("TP/login-form")
+	assert.True(t, ok)
+	assert.Equal(t, "TP", key)
+	assert.Equal(t, "login-form", slug)
+	for _, ref := range []string{task.ID, "TP", "docs/login.md", "tp/login-form"} {
+		_, _, ok := ParseSlugRef(ref)
+		assert.False(t, ok, ref)
+	}
+}
+
 func TestCreateDocument_WithBody(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 
-	d, err := s.CreateDocument("Doc", p.ID, "# Hello\n\nBody content.")
+	d, err := s.CreateDocument(t.Context(), "Doc", p.ID, DocumentCreateOpts{Body: "# Hello\n\nBody content."})
 	require.NoError(t, err)
 
-	_, body, err := s.GetDocument(d.ID)
+	_, body, err := s.GetDocument(t.Context(), d.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "# Hello\n\nBody content.", body)
 }
 
 func TestCreateDocument_InvalidProject(t *testing.T) {
 	s := newTestStore(t)
-	_, err := s.CreateDocument("Doc", "ZZZZ", "")
+	_, err := s.CreateDocument(t.Context(), "Doc", "ZZZZ", DocumentCreateOpts{})
 	assert.Error(t, err)
 }
 
 func TestListDocuments_FilterByProject(t *testing.T) {
 	s := newTestStore(t)
-	p1, _ := s.CreateProject("Project One", "PR", "")
-	p2, _ := s.CreateProject("Second Proj", "SP", "")
-	s.CreateDocument("D1", p1.ID, "")
-	s.CreateDocument("D2", p1.ID, "")
-	s.CreateDocument("D3", p2.ID, "")
+	p1, _ := s.CreateProject(t.Context(), "Project One", "PR", "")
+	p2, _ := s.CreateProject(t.Context(), "Second Proj", "SP", "")
+	s.CreateDocument(t.Context(), "D1", p1.ID, DocumentCreateOpts{})
+	s.CreateDocument(t.Context(), "D2", p1.ID, DocumentCreateOpts{})
+	s.CreateDocument(t.Context(), "D3", p2.ID, DocumentCreateOpts{})
 
-	docs, err := s.ListDocuments(p1.ID)
+	docs, err := s.ListDocuments(t.Context(), DocumentFilter{ProjectID: p1.ID})
 	require.NoError(t, err)
 	assert.Len(t, docs, 2)
 }
 
 func TestListDocuments_AllProjects(t *testing.T) {
 	s := newTestStore(t)
-	p1, _ := s.CreateProject("Project One", "PR", "")
-	p2, _ := s.CreateProject("Second Proj", "SP", "")
-	s.CreateDocument("D1", p1.ID, "")
-	s.CreateDocument("D2", p2.ID, "")
+	p1, _ := s.CreateProject(t.Context(), "Project One", "PR", "")
+	p2, _ := s.CreateProject(t.Context(), "Second Proj", "SP", "")
+	s.CreateDocument(t.Context(), "D1", p1.ID, DocumentCreateOpts{})
+	s.CreateDocument(t.Context(), "D2", p2.ID, DocumentCreateOpts{})
 
-	docs, err := s.ListDocuments("")
+	docs, err := s.ListDocuments(t.Context(), DocumentFilter{})
 	require.NoError(t, err)
 	assert.Len(t, docs, 2)
 }
 
+func TestListDocuments_Filter(t *testing.T) {
+	s := newTestStore(t)
+	t.Setenv("COMPASS_ACTOR", "")
+	t.Cleanup(func() { SetActor("") })
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	SetActor("alice")
+	spec, _ := s.CreateDocument(t.Context(), "Login spec", p.ID, DocumentCreateOpts{Kind: model.DocSpec, Body: "Uses OAuth."})
+	SetActor("bob")
+	runbook, _ := s.CreateDocument(t.Context(), "Deploys", p.ID, DocumentCreateOpts{Kind: model.DocRunbook, Body: "Roll back the oauth proxy first."})
+	notes, _ := s.CreateDocument(t.Context(), "Standup", p.ID, DocumentCreateOpts{})
+
+	ids := func(f DocumentFilter) []string {
+		f.ProjectID = p.ID
+		docs, err := s.ListDocuments(t.Context(), f)
+		require.NoError(t, err)
+		var out []string
+		for _, d := range docs {
+			out = append(out, d.ID)
+		}
+		return out
+	}
+	assert.ElementsMatch(t, []string{spec.ID}, ids(DocumentFilter{CreatedBy: "alice"}))
+	assert.ElementsMatch(t, []string{runbook.ID}, ids(DocumentFilter{Kind: model.DocRunbook}))
+	assert.ElementsMatch(t, []string{spec.ID, runbook.ID}, ids(DocumentFilter{Search: "OAUTH"}))
+	assert.ElementsMatch(t, []string{notes.ID}, ids(DocumentFilter{Search: "stand"}))
+	assert.ElementsMatch(t, []string{runbook.ID}, ids(DocumentFilter{CreatedBy: "bob", Search: "oauth"}))
+	assert.Len(t, ids(DocumentFilter{Since: time.Now().Add(-time.Hour)}), 3)
+	assert.Empty(t, ids(DocumentFilter{Since: time.Now().Add(time.Hour)}))
+
+	docs, _, err := s.ListDocumentsPage(t.Context(), DocumentFilter{ProjectID: p.ID, CreatedBy: "bob"}, PageOpts{Sort: "title"})
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "Deploys", docs[0].Title)
+}
+
 func TestUpdateDocument(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	d, _ := s.CreateDocument("Original", p.ID, "old body")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	d, _ := s.CreateDocument(t.Context(), "Original", p.ID, DocumentCreateOpts{Body: "old body"})
 
 	newTitle := "Updated"
 	newBody := "new body"
-	updated, err := s.UpdateDocument(d.ID, &newTitle, &newBody)
+	updated, err := s.UpdateDocument(t.Context(), d.ID, DocumentUpdate{Title: &newTitle, Body: &newBody})
 	require.NoError(t, err)
 	assert.Equal(t, "Updated", updated.Title)
 
-	_, body, _ := s.GetDocument(d.ID)
+	_, body, _ := s.GetDocument(t.Context(), d.ID)
 	assert.Equal(t, "new body", body)
 }
 
+func TestUpdateDocument_IfUnchanged(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	d, _ := s.CreateDocument(t.Context(), "Doc", p.ID, DocumentCreateOpts{Body: "old body"})
+	read := &DocumentVersion{UpdatedAt: d.UpdatedAt, Body: "old body"}
+
+	// Another writer changes the body within the same second.
+	other := "their body"
+	_, err := s.UpdateDocument(t.Context(), d.ID, DocumentUpdate{Body: &other})
+	require.NoError(t, err)
+
+	mine := "my body"
+	_, err = s.UpdateDocument(t.Context(), d.ID, DocumentUpdate{Body: &mine, IfUnchanged: read})
+	assert.ErrorIs(t, err, ErrConflict)
+	_, body, _ := s.GetDocument(t.Context(), d.ID)
+	assert.Equal(t, "their body", body)
+
+	cur, _, _ := s.GetDocument(t.Context(), d.ID)
+	_, err = s.UpdateDocument(t.Context(), d.ID, DocumentUpdate{Body: &mine, IfUnchanged: &DocumentVersion{UpdatedAt: cur.UpdatedAt, Body: body}})
+	require.NoError(t, err)
+}
+
+func TestCreateDocument_ADRNumbering(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+
+	spec, err := s.CreateDocument(t.Context(), "Spec", p.ID, DocumentCreateOpts{Kind: model.DocSpec})
+	require.NoError(t, err)
+	assert.Zero(t, spec.Number)
+
+	first, err := s.CreateDocument(t.Context(), "Use Postgres", p.ID, DocumentCreateOpts{Kind: model.DocADR})
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Number)
+	assert.Equal(t, model.ADRProposed, first.Status)
+
+	second, err := s.CreateDocument(t.Context(), "Use gRPC", p.ID, DocumentCreateOpts{Kind: model.DocADR})
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Number)
+
+	// Recategorizing into an ADR takes the next number; out of it drops it.
+	adr := model.DocADR
+	got, err := s.UpdateDocument(t.Context(), spec.ID, DocumentUpdate{Kind: &adr})
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.Number)
+
+	design := model.DocDesign
+	got, err = s.UpdateDocument(t.Context(), spec.ID, DocumentUpdate{Kind: &design})
+	require.NoError(t, err)
+	assert.Zero(t, got.Number)
+	assert.Empty(t, got.Status)
+
+	_, err = s.CreateDocument(t.Context(), "Bad", p.ID, DocumentCreateOpts{Kind: "memo"})
+	assert.ErrorContains(t, err, "invalid document kind")
+}
+
 // --- Task tests ---
 
 func TestCreateTask_Minimal(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 
-	task, err := s.CreateTask("Task", p.ID, TaskCreateOpts{})
+	task, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
 	require.NoError(t, err)
 	assert.NotEmpty(t, task.ID)
 	assert.Equal(t, model.TypeTask, task.Type)
@@ -264,9 +535,9 @@ func TestCreateTask_Minimal(t *testing.T) {
 
 func TestCreateTask_EpicType(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 
-	epic, err := s.CreateTask("Auth Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	epic, err := s.CreateTask(t.Context(), "Auth Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
 	require.NoError(t, err)
 	assert.Equal(t, model.TypeEpic, epic.Type)
 	assert.Empty(t, epic.Status)
@@ -274,115 +545,163 @@ func TestCreateTask_EpicType(t *testing.T) {
 
 func TestCreateTask_WithEpic(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	epic, _ := s.CreateTask("Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
 
-	task, err := s.CreateTask("Task", p.ID, TaskCreateOpts{Epic: epic.ID})
+	task, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{Epic: epic.ID})
 	require.NoError(t, err)
 	assert.Equal(t, epic.ID, task.Epic)
 }
 
 func TestCreateTask_EpicRefMustBeEpicType(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	regularTask, _ := s.CreateTask("Not Epic", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	regularTask, _ := s.CreateTask(t.Context(), "Not Epic", p.ID, TaskCreateOpts{})
 
-	_, err := s.CreateTask("Task", p.ID, TaskCreateOpts{Epic: regularTask.ID})
+	_, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{Epic: regularTask.ID})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not an epic-type task")
 }
 
+func TestCreateTask_EpicRefMustBeInProject(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	other, _ := s.CreateProject(t.Context(), "Other Project", "OP", "")
+	epic, _ := s.CreateTask(t.Context(), "Epic", other.ID, TaskCreateOpts{Type: model.TypeEpic})
+
+	_, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{Epic: epic.ID})
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Contains(t, err.Error(), "is in project OP, not TP")
+}
+
 func TestCreateTask_WithDependencies(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	t1, _ := s.CreateTask("T1", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	t1, _ := s.CreateTask(t.Context(), "T1", p.ID, TaskCreateOpts{})
 
-	t2, err := s.CreateTask("T2", p.ID, TaskCreateOpts{DependsOn: []string{t1.ID}})
+	t2, err := s.CreateTask(t.Context(), "T2", p.ID, TaskCreateOpts{DependsOn: []string{t1.ID}})
 	require.NoError(t, err)
 	assert.Equal(t, []string{t1.ID}, t2.DependsOn)
 }
 
 func TestCreateTask_CannotDependOnEpic(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	epic, _ := s.CreateTask("Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
 
-	_, err := s.CreateTask("Task", p.ID, TaskCreateOpts{DependsOn: []string{epic.ID}})
+	_, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{DependsOn: []string{epic.ID}})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot depend on epic-type task")
 }
 
 func TestCreateTask_WithBody(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 
-	task, err := s.CreateTask("Task", p.ID, TaskCreateOpts{Body: "task body"})
+	task, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{Body: "task body"})
 	require.NoError(t, err)
 
-	_, body, _ := s.GetTask(task.ID)
+	_, body, _ := s.GetTask(t.Context(), task.ID)
 	assert.Equal(t, "task body", body)
 }
 
 func TestCreateTask_InvalidEpic(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 
-	_, err := s.CreateTask("Task", p.ID, TaskCreateOpts{Epic: "TP-TZZZZZ"})
+	_, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{Epic: "TP-TZZZZZ"})
 	assert.Error(t, err)
 }
 
 func TestCreateTask_InvalidDependency(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 
-	_, err := s.CreateTask("Task", p.ID, TaskCreateOpts{DependsOn: []string{"TP-TZZZZZ"}})
+	_, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{DependsOn: []string{"TP-TZZZZZ"}})
 	assert.Error(t, err)
 }
 
 func TestCreateTask_CyclicDep(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	t1, _ := s.CreateTask("T1", p.ID, TaskCreateOpts{})
-	t2, _ := s.CreateTask("T2", p.ID, TaskCreateOpts{DependsOn: []string{t1.ID}})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	t1, _ := s.CreateTask(t.Context(), "T1", p.ID, TaskCreateOpts{})
+	t2, _ := s.CreateTask(t.Context(), "T2", p.ID, TaskCreateOpts{DependsOn: []string{t1.ID}})
 
 	// T1 depends on T2 would create a cycle
-	_, err := s.UpdateTask(t1.ID, TaskUpdate{DependsOn: &[]string{t2.ID}})
+	_, err := s.UpdateTask(t.Context(), t1.ID, TaskUpdate{DependsOn: &[]string{t2.ID}})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cycle")
 }
 
 func TestListTasks_FilterByStatus(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	s.CreateTask("T1", p.ID, TaskCreateOpts{})
-	t2, _ := s.CreateTask("T2", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	s.CreateTask(t.Context(), "T1", p.ID, TaskCreateOpts{})
+	t2, _ := s.CreateTask(t.Context(), "T2", p.ID, TaskCreateOpts{})
 	inProg := model.StatusInProgress
-	s.UpdateTask(t2.ID, TaskUpdate{Status: &inProg})
+	s.UpdateTask(t.Context(), t2.ID, TaskUpdate{Status: &inProg})
 
-	tasks, err := s.ListTasks(TaskFilter{Status: model.StatusOpen})
+	tasks, err := s.ListTasks(t.Context(), TaskFilter{Status: model.StatusOpen})
 	require.NoError(t, err)
 	assert.Len(t, tasks, 1)
 }
 
+func TestListTasksPage(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	for i, title := range []string{"Charlie", "alpha", "Bravo", "Delta", "Echo"} {
+		pri := i % 3
+		s.CreateTask(t.Context(), title, p.ID, TaskCreateOpts{Priority: &pri})
+	}
+
+	page, next, err := s.ListTasksPage(t.Context(), TaskFilter{ProjectID: p.ID}, PageOpts{Sort: "title", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "alpha", page[0].Title)
+	assert.Equal(t, "Bravo", page[1].Title)
+	assert.Equal(t, "2", next)
+
+	page, next, err = s.ListTasksPage(t.Context(), TaskFilter{ProjectID: p.ID}, PageOpts{Sort: "title", Limit: 2, Cursor: next})
+	require.NoError(t, err)
+	assert.Equal(t, "Charlie", page[0].Title)
+	assert.Equal(t, "4", next)
+
+	page, next, err = s.ListTasksPage(t.Context(), TaskFilter{ProjectID: p.ID}, PageOpts{Sort: "-title", Offset: 4})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "alpha", page[0].Title)
+	assert.Empty(t, next)
+
+	page, _, err = s.ListTasksPage(t.Context(), TaskFilter{ProjectID: p.ID}, PageOpts{Sort: "priority", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 0, *page[0].Priority)
+	assert.Equal(t, 0, *page[1].Priority)
+
+	_, _, err = s.ListTasksPage(t.Context(), TaskFilter{ProjectID: p.ID}, PageOpts{Sort: "colour"})
+	assert.Error(t, err)
+	_, _, err = s.ListTasksPage(t.Context(), TaskFilter{ProjectID: p.ID}, PageOpts{Cursor: "bogus"})
+	assert.Error(t, err)
+}
+
 func TestListTasks_FilterByEpic(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	epic, _ := s.CreateTask("Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
-	s.CreateTask("T1", p.ID, TaskCreateOpts{Epic: epic.ID})
-	s.CreateTask("T2", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	s.CreateTask(t.Context(), "T1", p.ID, TaskCreateOpts{Epic: epic.ID})
+	s.CreateTask(t.Context(), "T2", p.ID, TaskCreateOpts{})
 
-	tasks, err := s.ListTasks(TaskFilter{EpicID: epic.ID})
+	tasks, err := s.ListTasks(t.Context(), TaskFilter{EpicID: epic.ID})
 	require.NoError(t, err)
 	assert.Len(t, tasks, 1)
 }
 
 func TestListTasks_FilterByType(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	s.CreateTask("T1", p.ID, TaskCreateOpts{})
-	s.CreateTask("Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	s.CreateTask(t.Context(), "T1", p.ID, TaskCreateOpts{})
+	s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
 
-	tasks, err := s.ListTasks(TaskFilter{Type: model.TypeTask})
+	tasks, err := s.ListTasks(t.Context(), TaskFilter{Type: model.TypeTask})
 	require.NoError(t, err)
 	assert.Len(t, tasks, 1)
 	assert.Equal(t, "T1", tasks[0].Title)
@@ -390,82 +709,167 @@ func TestListTasks_FilterByType(t *testing.T) {
 
 func TestUpdateTask_Title(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	task, _ := s.CreateTask("Old Title", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Old Title", p.ID, TaskCreateOpts{})
 
 	newTitle := "New Title"
-	updated, err := s.UpdateTask(task.ID, TaskUpdate{Title: &newTitle})
+	updated, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Title: &newTitle})
 	require.NoError(t, err)
 	assert.Equal(t, "New Title", updated.Title)
 
-	got, _, _ := s.GetTask(task.ID)
+	got, _, _ := s.GetTask(t.Context(), task.ID)
 	assert.Equal(t, "New Title", got.Title)
 }
 
 func TestUpdateTask_Body(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	task, _ := s.CreateTask("Task", p.ID, TaskCreateOpts{Body: "old body"})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{Body: "old body"})
 
 	newBody := "new body"
-	_, err := s.UpdateTask(task.ID, TaskUpdate{Body: &newBody})
+	_, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Body: &newBody})
 	require.NoError(t, err)
 
-	_, body, _ := s.GetTask(task.ID)
+	_, body, _ := s.GetTask(t.Context(), task.ID)
 	assert.Equal(t, "new body", body)
 }
 
 func TestUpdateTask_Status(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	task, _ := s.CreateTask("Task", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
 
 	status := model.StatusInProgress
-	updated, err := s.UpdateTask(task.ID, TaskUpdate{Status: &status})
+	updated, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Status: &status})
 	require.NoError(t, err)
 	assert.Equal(t, model.StatusInProgress, updated.Status)
 }
 
+func TestUpdateTask_ClosedAt(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+
+	closed := model.StatusClosed
+	updated, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Status: &closed})
+	require.NoError(t, err)
+	require.NotNil(t, updated.ClosedAt)
+	first := *updated.ClosedAt
+
+	// Closing again keeps the original time; reopening clears it.
+	updated, err = s.UpdateTask(t.Context(), task.ID, TaskUpdate{Status: &closed})
+	require.NoError(t, err)
+	assert.Equal(t, first, *updated.ClosedAt)
+
+	open := model.StatusOpen
+	updated, err = s.UpdateTask(t.Context(), task.ID, TaskUpdate{Status: &open})
+	require.NoError(t, err)
+	assert.Nil(t, updated.ClosedAt)
+
+	// The repeated close is not a change, so history has two entries.
+	require.Len(t, updated.History, 2)
+	assert.Equal(t, model.StatusClosed, updated.History[0].Status)
+	assert.Equal(t, model.StatusOpen, updated.History[1].Status)
+	assert.Equal(t, CurrentUser(), updated.History[1].By)
+}
+
 func TestUpdateTask_EpicStatusRejected(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	epic, _ := s.CreateTask("Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
 
 	status := model.StatusInProgress
-	_, err := s.UpdateTask(epic.ID, TaskUpdate{Status: &status})
+	_, err := s.UpdateTask(t.Context(), epic.ID, TaskUpdate{Status: &status})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "epics do not have a status")
 }
 
 func TestUpdateTask_EpicNonStatusAllowed(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	epic, _ := s.CreateTask("Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
 
 	newTitle := "Updated Epic"
-	updated, err := s.UpdateTask(epic.ID, TaskUpdate{Title: &newTitle})
+	updated, err := s.UpdateTask(t.Context(), epic.ID, TaskUpdate{Title: &newTitle})
 	require.NoError(t, err)
 	assert.Equal(t, "Updated Epic", updated.Title)
 }
 
+func TestUpdateTask_AutoClosesEpic(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	on, off := true, false
+	_, err := s.UpdateProject(t.Context(), p.ID, ProjectUpdate{AutoCloseEpics: &on})
+	require.NoError(t, err)
+
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	a, _ := s.CreateTask(t.Context(), "A", p.ID, TaskCreateOpts{Epic: epic.ID})
+	b, _ := s.CreateTask(t.Context(), "B", p.ID, TaskCreateOpts{Epic: epic.ID})
+	closed, open := model.StatusClosed, model.StatusOpen
+
+	_, err = s.UpdateTask(t.Context(), a.ID, TaskUpdate{Status: &closed})
+	require.NoError(t, err)
+	got, _, _ := s.GetTask(t.Context(), epic.ID)
+	assert.Nil(t, got.ClosedAt, "B is still open")
+
+	_, err = s.UpdateTask(t.Context(), b.ID, TaskUpdate{Status: &closed})
+	require.NoError(t, err)
+	got, _, _ = s.GetTask(t.Context(), epic.ID)
+	assert.NotNil(t, got.ClosedAt)
+
+	_, err = s.UpdateTask(t.Context(), b.ID, TaskUpdate{Status: &open})
+	require.NoError(t, err)
+	got, _, _ = s.GetTask(t.Context(), epic.ID)
+	assert.Nil(t, got.ClosedAt, "reopening a task reopens the epic")
+
+	// An epic's own auto_close overrides the project's.
+	_, err = s.UpdateTask(t.Context(), epic.ID, TaskUpdate{AutoClose: &off})
+	require.NoError(t, err)
+	_, err = s.UpdateTask(t.Context(), b.ID, TaskUpdate{Status: &closed})
+	require.NoError(t, err)
+	got, _, _ = s.GetTask(t.Context(), epic.ID)
+	assert.Nil(t, got.ClosedAt)
+
+	// Turning it on settles the epic at once.
+	got, err = s.UpdateTask(t.Context(), epic.ID, TaskUpdate{AutoClose: &on})
+	require.NoError(t, err)
+	assert.NotNil(t, got.ClosedAt)
+
+	// So does changing the project's setting, for epics that follow it.
+	_, err = s.UpdateTask(t.Context(), a.ID, TaskUpdate{AutoClose: &on})
+	assert.ErrorIs(t, err, ErrValidation, "only epics auto-close")
+	other, _ := s.CreateTask(t.Context(), "Other", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	c, _ := s.CreateTask(t.Context(), "C", p.ID, TaskCreateOpts{Epic: other.ID})
+	_, err = s.UpdateTask(t.Context(), c.ID, TaskUpdate{Status: &closed})
+	require.NoError(t, err)
+	got, _, _ = s.GetTask(t.Context(), other.ID)
+	require.NotNil(t, got.ClosedAt)
+	_, err = s.UpdateProject(t.Context(), p.ID, ProjectUpdate{AutoCloseEpics: &off})
+	require.NoError(t, err)
+	got, _, _ = s.GetTask(t.Context(), other.ID)
+	assert.Nil(t, got.ClosedAt)
+	got, _, _ = s.GetTask(t.Context(), epic.ID)
+	assert.NotNil(t, got.ClosedAt, "the epic's own auto_close still holds")
+}
+
 func TestUpdateTask_UpdatesTimestamp(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	task, _ := s.CreateTask("Task", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
 
 	status := model.StatusInProgress
-	updated, err := s.UpdateTask(task.ID, TaskUpdate{Status: &status})
+	updated, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Status: &status})
 	require.NoError(t, err)
 	assert.True(t, !updated.UpdatedAt.Before(task.UpdatedAt))
 }
 
 func TestAllTaskMap(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	t1, _ := s.CreateTask("T1", p.ID, TaskCreateOpts{})
-	t2, _ := s.CreateTask("T2", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	t1, _ := s.CreateTask(t.Context(), "T1", p.ID, TaskCreateOpts{})
+	t2, _ := s.CreateTask(t.Context(), "T2", p.ID, TaskCreateOpts{})
 
-	m, err := s.AllTaskMap(p.ID)
+	m, err := s.AllTaskMap(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.Contains(t, m, t1.ID)
 	assert.Contains(t, m, t2.ID)
@@ -475,31 +879,31 @@ func TestAllTaskMap(t *testing.T) {
 
 func TestReadyTasks_NoTasks(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 
-	ready, err := s.ReadyTasks(p.ID)
+	ready, err := s.ReadyTasks(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.Empty(t, ready)
 }
 
 func TestReadyTasks_AllReady(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	s.CreateTask("T1", p.ID, TaskCreateOpts{})
-	s.CreateTask("T2", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	s.CreateTask(t.Context(), "T1", p.ID, TaskCreateOpts{})
+	s.CreateTask(t.Context(), "T2", p.ID, TaskCreateOpts{})
 
-	ready, err := s.ReadyTasks(p.ID)
+	ready, err := s.ReadyTasks(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.Len(t, ready, 2)
 }
 
 func TestReadyTasks_BlockedExcluded(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	t1, _ := s.CreateTask("T1", p.ID, TaskCreateOpts{})
-	s.CreateTask("T2", p.ID, TaskCreateOpts{DependsOn: []string{t1.ID}})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	t1, _ := s.CreateTask(t.Context(), "T1", p.ID, TaskCreateOpts{})
+	s.CreateTask(t.Context(), "T2", p.ID, TaskCreateOpts{DependsOn: []string{t1.ID}})
 
-	ready, err := s.ReadyTasks(p.ID)
+	ready, err := s.ReadyTasks(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.Len(t, ready, 1)
 	assert.Equal(t, t1.ID, ready[0].ID)
@@ -507,23 +911,23 @@ func TestReadyTasks_BlockedExcluded(t *testing.T) {
 
 func TestReadyTasks_ClosedExcluded(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	t1, _ := s.CreateTask("T1", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	t1, _ := s.CreateTask(t.Context(), "T1", p.ID, TaskCreateOpts{})
 	closed := model.StatusClosed
-	s.UpdateTask(t1.ID, TaskUpdate{Status: &closed})
+	s.UpdateTask(t.Context(), t1.ID, TaskUpdate{Status: &closed})
 
-	ready, err := s.ReadyTasks(p.ID)
+	ready, err := s.ReadyTasks(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.Empty(t, ready)
 }
 
 func TestReadyTasks_EpicsExcluded(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	s.CreateTask("Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
-	s.CreateTask("Task", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
 
-	ready, err := s.ReadyTasks(p.ID)
+	ready, err := s.ReadyTasks(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.Len(t, ready, 1)
 	assert.Equal(t, "Task", ready[0].Title)
@@ -531,20 +935,20 @@ func TestReadyTasks_EpicsExcluded(t *testing.T) {
 
 func TestReadyTasks_UnblocksAfterClose(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	t1, _ := s.CreateTask("T1", p.ID, TaskCreateOpts{})
-	t2, _ := s.CreateTask("T2", p.ID, TaskCreateOpts{DependsOn: []string{t1.ID}})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	t1, _ := s.CreateTask(t.Context(), "T1", p.ID, TaskCreateOpts{})
+	t2, _ := s.CreateTask(t.Context(), "T2", p.ID, TaskCreateOpts{DependsOn: []string{t1.ID}})
 
 	// Before closing t1, only t1 is ready
-	ready, err := s.ReadyTasks(p.ID)
+	ready, err := s.ReadyTasks(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.Len(t, ready, 1)
 
 	// Close t1, t2 becomes ready
 	closed := model.StatusClosed
-	s.UpdateTask(t1.ID, TaskUpdate{Status: &closed})
+	s.UpdateTask(t.Context(), t1.ID, TaskUpdate{Status: &closed})
 
-	ready, err = s.ReadyTasks(p.ID)
+	ready, err = s.ReadyTasks(t.Context(), p.ID)
 	require.NoError(t, err)
 	assert.Len(t, ready, 1)
 	assert.Equal(t, t2.ID, ready[0].ID)
@@ -554,15 +958,15 @@ func TestReadyTasks_UnblocksAfterClose(t *testing.T) {
 
 func TestCreateTask_WithPriority(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 
 	pri := 1
-	task, err := s.CreateTask("Task", p.ID, TaskCreateOpts{Priority: &pri})
+	task, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{Priority: &pri})
 	require.NoError(t, err)
 	require.NotNil(t, task.Priority)
 	assert.Equal(t, 1, *task.Priority)
 
-	got, _, err := s.GetTask(task.ID)
+	got, _, err := s.GetTask(t.Context(), task.ID)
 	require.NoError(t, err)
 	require.NotNil(t, got.Priority)
 	assert.Equal(t, 1, *got.Priority)
@@ -570,35 +974,35 @@ func TestCreateTask_WithPriority(t *testing.T) {
 
 func TestCreateTask_WithoutPriority(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 
-	task, err := s.CreateTask("Task", p.ID, TaskCreateOpts{})
+	task, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
 	require.NoError(t, err)
 	assert.Nil(t, task.Priority)
 
-	got, _, err := s.GetTask(task.ID)
+	got, _, err := s.GetTask(t.Context(), task.ID)
 	require.NoError(t, err)
 	assert.Nil(t, got.Priority)
 }
 
 func TestCreateTask_InvalidPriority(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 
 	pri := 5
-	_, err := s.CreateTask("Task", p.ID, TaskCreateOpts{Priority: &pri})
+	_, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{Priority: &pri})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid priority")
 }
 
 func TestUpdateTask_SetPriority(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	task, _ := s.CreateTask("Task", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
 
 	pri := 2
 	pp := &pri
-	updated, err := s.UpdateTask(task.ID, TaskUpdate{Priority: &pp})
+	updated, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Priority: &pp})
 	require.NoError(t, err)
 	require.NotNil(t, updated.Priority)
 	assert.Equal(t, 2, *updated.Priority)
@@ -606,24 +1010,24 @@ func TestUpdateTask_SetPriority(t *testing.T) {
 
 func TestUpdateTask_ClearPriority(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
 	pri := 1
-	task, _ := s.CreateTask("Task", p.ID, TaskCreateOpts{Priority: &pri})
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{Priority: &pri})
 
 	var cleared *int
-	updated, err := s.UpdateTask(task.ID, TaskUpdate{Priority: &cleared})
+	updated, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Priority: &cleared})
 	require.NoError(t, err)
 	assert.Nil(t, updated.Priority)
 }
 
 func TestUpdateTask_InvalidPriority(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	task, _ := s.CreateTask("Task", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
 
 	pri := 4
 	pp := &pri
-	_, err := s.UpdateTask(task.ID, TaskUpdate{Priority: &pp})
+	_, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Priority: &pp})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid priority")
 }
@@ -632,108 +1036,288 @@ func TestUpdateTask_InvalidPriority(t *testing.T) {
 
 func TestDeleteTask(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	task, _ := s.CreateTask("Task", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
 
-	require.NoError(t, s.DeleteTask(task.ID))
+	require.NoError(t, s.DeleteTask(t.Context(), task.ID))
 
-	_, _, err := s.GetTask(task.ID)
+	_, _, err := s.GetTask(t.Context(), task.ID)
 	assert.Error(t, err)
 }
 
 func TestDeleteTask_NotFound(t *testing.T) {
 	s := newTestStore(t)
-	assert.Error(t, s.DeleteTask("ZZZZ-TZZZZZ"))
+	assert.Error(t, s.DeleteTask(t.Context(), "ZZZZ-TZZZZZ"))
+}
+
+func TestMoveTask(t *testing.T) {
+	s := newTestStore(t)
+	s.CreateProject(t.Context(), "Source", "SRC", "")
+	s.CreateProject(t.Context(), "Target", "DST", "")
+	dep, _ := s.CreateTask(t.Context(), "Dep", "SRC", TaskCreateOpts{})
+	task, _ := s.CreateTask(t.Context(), "Task", "SRC", TaskCreateOpts{DependsOn: []string{dep.ID}, Body: "body"})
+	status := model.StatusInProgress
+	s.UpdateTask(t.Context(), task.ID, TaskUpdate{Status: &status})
+
+	moved, err := s.MoveTask(t.Context(), task.ID, "DST")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(moved.ID, "DST-T"))
+	assert.Equal(t, "DST", moved.Project)
+	assert.Equal(t, model.StatusInProgress, moved.Status)
+	assert.Empty(t, moved.DependsOn)
+	assert.Equal(t, task.CreatedAt.Unix(), moved.CreatedAt.Unix())
+
+	_, body, err := s.GetTask(t.Context(), moved.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "body", body)
+	_, _, err = s.GetTask(t.Context(), task.ID)
+	assert.Error(t, err)
+
+	_, err = s.MoveTask(t.Context(), moved.ID, "DST")
+	assert.ErrorContains(t, err, "already in project")
+	_, err = s.MoveTask(t.Context(), dep.ID, "ZZZ")
+	assert.ErrorContains(t, err, "not found")
 }
 
 func TestDeleteDocument(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	d, _ := s.CreateDocument("Doc", p.ID, "body")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	d, _ := s.CreateDocument(t.Context(), "Doc", p.ID, DocumentCreateOpts{Body: "body"})
 
-	require.NoError(t, s.DeleteDocument(d.ID))
+	require.NoError(t, s.DeleteDocument(t.Context(), d.ID))
 
-	_, _, err := s.GetDocument(d.ID)
+	_, _, err := s.GetDocument(t.Context(), d.ID)
 	assert.Error(t, err)
 }
 
 func TestDeleteDocument_NotFound(t *testing.T) {
 	s := newTestStore(t)
-	assert.Error(t, s.DeleteDocument("ZZZZ-DZZZZZ"))
+	assert.Error(t, s.DeleteDocument(t.Context(), "ZZZZ-DZZZZZ"))
 }
 
 func TestDeleteProject(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	s.CreateTask("Task", p.ID, TaskCreateOpts{})
-	s.CreateDocument("Doc", p.ID, "body")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+	s.CreateDocument(t.Context(), "Doc", p.ID, DocumentCreateOpts{Body: "body"})
 
-	require.NoError(t, s.DeleteProject(p.ID))
+	require.NoError(t, s.DeleteProject(t.Context(), p.ID))
 
-	_, _, err := s.GetProject(p.ID)
+	_, _, err := s.GetProject(t.Context(), p.ID)
 	assert.Error(t, err)
 	// Tasks and docs should be gone too
-	tasks, _ := s.ListTasks(TaskFilter{ProjectID: p.ID})
+	tasks, _ := s.ListTasks(t.Context(), TaskFilter{ProjectID: p.ID})
 	assert.Empty(t, tasks)
-	docs, _ := s.ListDocuments(p.ID)
+	docs, _ := s.ListDocuments(t.Context(), DocumentFilter{ProjectID: p.ID})
 	assert.Empty(t, docs)
 }
 
 func TestDeleteProject_NotFound(t *testing.T) {
 	s := newTestStore(t)
-	assert.Error(t, s.DeleteProject("ZZZZ"))
+	assert.Error(t, s.DeleteProject(t.Context(), "ZZZZ"))
+}
+
+func TestCurrentUser_Actor(t *testing.T) {
+	t.Cleanup(func() { SetActor("") })
+	t.Setenv("COMPASS_ACTOR", "")
+	assert.NotEmpty(t, CurrentUser())
+	assert.Empty(t, Actor())
+
+	t.Setenv("COMPASS_ACTOR", "ci-bot")
+	assert.Equal(t, "ci-bot", CurrentUser())
+
+	SetActor("claude-code")
+	assert.Equal(t, "claude-code", CurrentUser())
+
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	task, err := s.CreateTask(t.Context(), "Login", p.ID, TaskCreateOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, "claude-code", task.CreatedBy)
+	closed := model.StatusClosed
+	task, err = s.UpdateTask(t.Context(), task.ID, TaskUpdate{Status: &closed})
+	require.NoError(t, err)
+	assert.Equal(t, "claude-code", task.History[len(task.History)-1].By)
 }
 
 // --- Search tests ---
 
 func TestSearch_MatchTitle(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Authentication Service", "", "")
-	s.CreateTask("Auth Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
-	s.CreateTask("Login Form", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Authentication Service", "", "")
+	s.CreateTask(t.Context(), "Auth Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	s.CreateTask(t.Context(), "Login Form", p.ID, TaskCreateOpts{})
 
-	results, err := s.Search("auth", "")
+	results, err := s.Search(t.Context(), "auth", SearchOpts{})
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(results), 2)
 }
 
 func TestSearch_MatchBody(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Project Test", "", "")
-	s.CreateDocument("Doc", p.ID, "This mentions authentication details.")
+	p, _ := s.CreateProject(t.Context(), "Project Test", "", "")
+	s.CreateDocument(t.Context(), "Doc", p.ID, DocumentCreateOpts{Body: "This mentions authentication details."})
 
-	results, err := s.Search("authentication", "")
+	results, err := s.Search(t.Context(), "authentication", SearchOpts{})
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(results), 1)
 }
 
+func TestSearch_TitleAndBodyMatchHasSnippet(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Project Test", "", "")
+	s.CreateDocument(t.Context(), "Auth notes", p.ID, DocumentCreateOpts{Body: "Tokens expire after an hour of auth inactivity."})
+
+	results, err := s.Search(t.Context(), "auth", SearchOpts{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Contains(t, results[0].Snippet, "auth inactivity")
+
+	results, err = s.Search(t.Context(), "auth", SearchOpts{TitleOnly: true})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Empty(t, results[0].Snippet)
+}
+
 func TestSearch_CaseInsensitive(t *testing.T) {
 	s := newTestStore(t)
-	s.CreateProject("Authentication", "", "")
+	s.CreateProject(t.Context(), "Authentication", "", "")
 
-	results, err := s.Search("AUTHENTICATION", "")
+	results, err := s.Search(t.Context(), "AUTHENTICATION", SearchOpts{})
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(results), 1)
 }
 
 func TestSearch_NoResults(t *testing.T) {
 	s := newTestStore(t)
-	s.CreateProject("Test Project", "TP", "")
+	s.CreateProject(t.Context(), "Test Project", "TP", "")
 
-	results, err := s.Search("nonexistent", "")
+	results, err := s.Search(t.Context(), "nonexistent", SearchOpts{})
 	require.NoError(t, err)
 	assert.Empty(t, results)
 }
 
+func TestSearch_Options(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Login Service", "LS", "")
+	s.CreateTask(t.Context(), "Login form", p.ID, TaskCreateOpts{Body: "error code E1234"})
+	s.CreateTask(t.Context(), "Signup", p.ID, TaskCreateOpts{Body: "links to the login form"})
+	s.CreateDocument(t.Context(), "Login spec", p.ID, DocumentCreateOpts{})
+
+	// Tasks come back in ID order, which is random.
+	hits := func(opts SearchOpts, query string) []string {
+		t.Helper()
+		results, err := s.Search(t.Context(), query, opts)
+		require.NoError(t, err)
+		var hits []string
+		for _, r := range results {
+			hits = append(hits, r.Type+":"+r.Title)
+		}
+		return hits
+	}
+
+	assert.ElementsMatch(t, []string{"project:Login Service", "document:Login spec", "task:Login form", "task:Signup"}, hits(SearchOpts{}, "login"))
+	assert.Equal(t, []string{"project:Login Service", "document:Login spec", "task:Login form"}, hits(SearchOpts{TitleOnly: true}, "login"))
+	assert.Equal(t, []string{"task:Signup"}, hits(SearchOpts{BodyOnly: true}, "login"))
+	assert.ElementsMatch(t, []string{"task:Login form", "task:Signup"}, hits(SearchOpts{Type: "task"}, "login"))
+	assert.Equal(t, []string{"task:Login form"}, hits(SearchOpts{Regex: true}, `e\d{4}`))
+
+	results, _ := s.Search(t.Context(), `E\d+`, SearchOpts{Regex: true})
+	require.Len(t, results, 1)
+	assert.Equal(t, "error code E1234", results[0].Snippet)
+
+	for _, opts := range []SearchOpts{{Regex: true}, {TitleOnly: true, BodyOnly: true}, {Type: "epic"}} {
+		_, err := s.Search(t.Context(), "(", opts)
+		assert.ErrorIs(t, err, ErrValidation)
+	}
+}
+
+func TestSimilarTasks(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	task, _ := s.CreateTask(t.Context(), "OAuth token refresh fails", p.ID, TaskCreateOpts{Body: "Refresh tokens expire after an hour."})
+	dup, _ := s.CreateTask(t.Context(), "Token refresh broken", p.ID, TaskCreateOpts{Body: "OAuth refresh tokens expire early."})
+	doc, _ := s.CreateDocument(t.Context(), "OAuth design", p.ID, DocumentCreateOpts{Body: "How tokens are issued."})
+	s.CreateTask(t.Context(), "Dark mode", p.ID, TaskCreateOpts{Body: "Colours for the settings page."})
+
+	similar, err := SimilarTasks(t.Context(), s, task.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, similar, 2)
+	assert.Equal(t, dup.ID, similar[0].ID)
+	assert.Equal(t, doc.ID, similar[1].ID)
+	assert.Equal(t, "document", similar[1].Type)
+	assert.Greater(t, similar[0].Score, similar[1].Score)
+
+	similar, err = SimilarTasks(t.Context(), s, task.ID, 1)
+	require.NoError(t, err)
+	assert.Len(t, similar, 1)
+
+	_, err = SimilarTasks(t.Context(), s, "AUTH-TZZZZZ", 0)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestParseQuery(t *testing.T) {
+	q, err := ParseQuery(`status:open priority<=P1 assignee:alice "oauth flow" login`)
+	require.NoError(t, err)
+	assert.Equal(t, TaskFilter{Status: model.StatusOpen}, q.Filter)
+	assert.Equal(t, []string{"oauth flow", "login"}, q.Text)
+	assert.True(t, q.HasFilters())
+
+	// Repeated fields are ORed, so they can't be sent as a filter.
+	q, err = ParseQuery("status:open status:blocked project:auth")
+	require.NoError(t, err)
+	assert.Equal(t, TaskFilter{ProjectID: "AUTH"}, q.Filter)
+
+	q, err = ParseQuery("just words")
+	require.NoError(t, err)
+	assert.False(t, q.HasFilters())
+
+	for _, bad := range []string{"label:backend", "status:done", "priority:7", "assignee<bob", "due>soon", `"open`} {
+		_, err := ParseQuery(bad)
+		assert.ErrorIs(t, err, ErrValidation, bad)
+	}
+}
+
+func TestQueryTasks(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	p0, p2 := 0, 2
+	urgent, _ := s.CreateTask(t.Context(), "Fix login", p.ID, TaskCreateOpts{Priority: &p0, Body: "the oauth flow drops tokens"})
+	s.CreateTask(t.Context(), "Polish login", p.ID, TaskCreateOpts{Priority: &p2, Body: "oauth button colours"})
+	s.CreateTask(t.Context(), "Unprioritised login", p.ID, TaskCreateOpts{Body: "oauth"})
+	done, _ := s.CreateTask(t.Context(), "Old login", p.ID, TaskCreateOpts{Priority: &p0, Body: "oauth"})
+	closed := model.StatusClosed
+	s.UpdateTask(t.Context(), done.ID, TaskUpdate{Status: &closed})
+
+	q, err := ParseQuery(`status:open priority<=1 "oauth"`)
+	require.NoError(t, err)
+	results, err := QueryTasks(t.Context(), s, q, SearchOpts{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, urgent.ID, results[0].ID)
+	assert.Contains(t, results[0].Snippet, "oauth")
+
+	q, _ = ParseQuery("status:open status:closed priority:0")
+	results, err = QueryTasks(t.Context(), s, q, SearchOpts{})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	// Every word must match.
+	q, _ = ParseQuery("type:task login colours")
+	results, err = QueryTasks(t.Context(), s, q, SearchOpts{})
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
 // --- Download/Upload tests ---
 
 func TestDownloadEntity_Task(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	task, _ := s.CreateTask("My Task", p.ID, TaskCreateOpts{Body: "task body"})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "My Task", p.ID, TaskCreateOpts{Body: "task body"})
 
 	destDir := t.TempDir()
-	localPath, err := s.DownloadEntity(task.ID, destDir)
+	localPath, err := s.DownloadEntity(t.Context(), task.ID, destDir)
 	require.NoError(t, err)
 	assert.FileExists(t, localPath)
 	assert.Contains(t, localPath, task.ID+".md")
@@ -748,11 +1332,11 @@ func TestDownloadEntity_Task(t *testing.T) {
 
 func TestDownloadEntity_Document(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	doc, _ := s.CreateDocument("My Doc", p.ID, "doc body")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	doc, _ := s.CreateDocument(t.Context(), "My Doc", p.ID, DocumentCreateOpts{Body: "doc body"})
 
 	destDir := t.TempDir()
-	localPath, err := s.DownloadEntity(doc.ID, destDir)
+	localPath, err := s.DownloadEntity(t.Context(), doc.ID, destDir)
 	require.NoError(t, err)
 	assert.FileExists(t, localPath)
 
@@ -764,17 +1348,17 @@ func TestDownloadEntity_Document(t *testing.T) {
 
 func TestDownloadEntity_NotFound(t *testing.T) {
 	s := newTestStore(t)
-	_, err := s.DownloadEntity("ZZZZ-TZZZZZ", t.TempDir())
+	_, err := s.DownloadEntity(t.Context(), "ZZZZ-TZZZZZ", t.TempDir())
 	assert.Error(t, err)
 }
 
 func TestUploadTask_RoundTrip(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	task, _ := s.CreateTask("Original", p.ID, TaskCreateOpts{Body: "old body"})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Original", p.ID, TaskCreateOpts{Body: "old body"})
 
 	destDir := t.TempDir()
-	localPath, err := s.DownloadEntity(task.ID, destDir)
+	localPath, err := s.DownloadEntity(t.Context(), task.ID, destDir)
 	require.NoError(t, err)
 
 	// Modify the local file: change the body
@@ -783,7 +1367,7 @@ func TestUploadTask_RoundTrip(t *testing.T) {
 	require.NoError(t, s.WriteEntity(localPath, &modified, "new body"))
 
 	// Upload
-	result, err := s.UploadTask(localPath)
+	result, err := s.UploadTask(t.Context(), localPath)
 	require.NoError(t, err)
 	assert.Equal(t, task.ID, result.ID)
 
@@ -791,18 +1375,18 @@ func TestUploadTask_RoundTrip(t *testing.T) {
 	assert.NoFileExists(t, localPath)
 
 	// Store should have the updated body
-	_, body, err := s.GetTask(task.ID)
+	_, body, err := s.GetTask(t.Context(), task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "new body", body)
 }
 
 func TestUploadDocument_RoundTrip(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	doc, _ := s.CreateDocument("Original", p.ID, "old body")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	doc, _ := s.CreateDocument(t.Context(), "Original", p.ID, DocumentCreateOpts{Body: "old body"})
 
 	destDir := t.TempDir()
-	localPath, err := s.DownloadEntity(doc.ID, destDir)
+	localPath, err := s.DownloadEntity(t.Context(), doc.ID, destDir)
 	require.NoError(t, err)
 
 	// Modify the local file
@@ -812,7 +1396,7 @@ func TestUploadDocument_RoundTrip(t *testing.T) {
 	require.NoError(t, s.WriteEntity(localPath, &modified, "new body"))
 
 	// Upload
-	result, err := s.UploadDocument(localPath)
+	result, err := s.UploadDocument(t.Context(), localPath)
 	require.NoError(t, err)
 	assert.Equal(t, doc.ID, result.ID)
 
@@ -820,7 +1404,7 @@ func TestUploadDocument_RoundTrip(t *testing.T) {
 	assert.NoFileExists(t, localPath)
 
 	// Store should have updates
-	got, body, err := s.GetDocument(doc.ID)
+	got, body, err := s.GetDocument(t.Context(), doc.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "Updated Title", got.Title)
 	assert.Equal(t, "new body", body)
@@ -828,11 +1412,11 @@ func TestUploadDocument_RoundTrip(t *testing.T) {
 
 func TestUploadTask_InvalidFrontmatter(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	task, _ := s.CreateTask("Task", p.ID, TaskCreateOpts{})
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
 
 	destDir := t.TempDir()
-	localPath, err := s.DownloadEntity(task.ID, destDir)
+	localPath, err := s.DownloadEntity(t.Context(), task.ID, destDir)
 	require.NoError(t, err)
 
 	// Corrupt the file: clear the title to trigger validation error
@@ -841,7 +1425,7 @@ func TestUploadTask_InvalidFrontmatter(t *testing.T) {
 	modified.Title = ""
 	require.NoError(t, s.WriteEntity(localPath, &modified, body))
 
-	_, err = s.UploadTask(localPath)
+	_, err = s.UploadTask(t.Context(), localPath)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "title")
 
@@ -851,11 +1435,11 @@ func TestUploadTask_InvalidFrontmatter(t *testing.T) {
 
 func TestUploadDocument_InvalidFrontmatter(t *testing.T) {
 	s := newTestStore(t)
-	p, _ := s.CreateProject("Test Project", "TP", "")
-	doc, _ := s.CreateDocument("Doc", p.ID, "body")
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	doc, _ := s.CreateDocument(t.Context(), "Doc", p.ID, DocumentCreateOpts{Body: "body"})
 
 	destDir := t.TempDir()
-	localPath, err := s.DownloadEntity(doc.ID, destDir)
+	localPath, err := s.DownloadEntity(t.Context(), doc.ID, destDir)
 	require.NoError(t, err)
 
 	// Clear the title
@@ -864,8 +1448,573 @@ func TestUploadDocument_InvalidFrontmatter(t *testing.T) {
 	modified.Title = ""
 	require.NoError(t, s.WriteEntity(localPath, &modified, body))
 
-	_, err = s.UploadDocument(localPath)
+	_, err = s.UploadDocument(t.Context(), localPath)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "title")
 	assert.FileExists(t, localPath)
 }
+
+// --- Release tests ---
+
+func TestCreateRelease(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+
+	r, err := s.CreateRelease(t.Context(), "1.0.0", p.ID, ReleaseCreateOpts{TargetDate: "2026-03-01", Items: []string{task.ID}})
+	require.NoError(t, err)
+	assert.Contains(t, r.ID, "TP-R")
+	assert.Equal(t, model.ReleasePlanned, r.Status)
+
+	got, _, err := s.GetRelease(t.Context(), r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", got.Version)
+	assert.Equal(t, []string{task.ID}, got.Items)
+}
+
+func TestCreateRelease_DuplicateVersion(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	_, err := s.CreateRelease(t.Context(), "1.0.0", p.ID, ReleaseCreateOpts{})
+	require.NoError(t, err)
+
+	_, err = s.CreateRelease(t.Context(), "1.0.0", p.ID, ReleaseCreateOpts{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+}
+
+func TestCreateRelease_InvalidItem(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	_, err := s.CreateRelease(t.Context(), "1.0.0", p.ID, ReleaseCreateOpts{Items: []string{"TP-TZZZZZ"}})
+	assert.Error(t, err)
+}
+
+func TestListReleases(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	s.CreateRelease(t.Context(), "1.0.0", p.ID, ReleaseCreateOpts{})
+	s.CreateRelease(t.Context(), "1.1.0", p.ID, ReleaseCreateOpts{})
+
+	releases, err := s.ListReleases(t.Context(), p.ID)
+	require.NoError(t, err)
+	assert.Len(t, releases, 2)
+}
+
+func TestCutRelease_RejectsOpenTasks(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	child, _ := s.CreateTask(t.Context(), "Child", p.ID, TaskCreateOpts{Epic: epic.ID})
+	r, _ := s.CreateRelease(t.Context(), "1.0.0", p.ID, ReleaseCreateOpts{Items: []string{epic.ID}})
+
+	_, _, err := CutRelease(t.Context(), s, r.ID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), child.ID)
+}
+
+func TestCutRelease_WritesChangelog(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	epic, _ := s.CreateTask(t.Context(), "Login Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	child, _ := s.CreateTask(t.Context(), "Login form", p.ID, TaskCreateOpts{Epic: epic.ID})
+	loose, _ := s.CreateTask(t.Context(), "Fix typo", p.ID, TaskCreateOpts{})
+	closed := model.StatusClosed
+	s.UpdateTask(t.Context(), child.ID, TaskUpdate{Status: &closed})
+	s.UpdateTask(t.Context(), loose.ID, TaskUpdate{Status: &closed})
+	r, _ := s.CreateRelease(t.Context(), "1.0.0", p.ID, ReleaseCreateOpts{Items: []string{epic.ID, loose.ID}})
+
+	cut, d, err := CutRelease(t.Context(), s, r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ReleaseCut, cut.Status)
+	assert.Equal(t, d.ID, cut.Changelog)
+	assert.NotNil(t, cut.CutAt)
+
+	_, body, err := s.GetDocument(t.Context(), d.ID)
+	require.NoError(t, err)
+	assert.Contains(t, body, "# Release 1.0.0")
+	assert.Contains(t, body, "## Login Epic ("+epic.ID+")")
+	assert.Contains(t, body, "- Login form ("+child.ID+")")
+	assert.Contains(t, body, "## Other changes")
+	assert.Contains(t, body, "- Fix typo ("+loose.ID+")")
+
+	_, _, err = CutRelease(t.Context(), s, r.ID)
+	assert.Error(t, err)
+}
+
+// failingRelease is a store whose release updates fail.
+type failingRelease struct{ Store }
+
+func (failingRelease) UpdateRelease(context.Context, string, ReleaseUpdate) (*model.Release, error) {
+	return nil, fmt.Errorf("disk full")
+}
+
+func TestCutRelease_RollsBackChangelog(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Fix typo", p.ID, TaskCreateOpts{})
+	closed := model.StatusClosed
+	s.UpdateTask(t.Context(), task.ID, TaskUpdate{Status: &closed})
+	r, _ := s.CreateRelease(t.Context(), "1.0.0", p.ID, ReleaseCreateOpts{Items: []string{task.ID}})
+
+	_, _, err := CutRelease(t.Context(), failingRelease{s}, r.ID)
+	require.ErrorContains(t, err, "disk full")
+
+	docs, err := s.ListDocuments(t.Context(), DocumentFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	assert.Empty(t, docs, "changelog is removed")
+	got, _, _ := s.GetRelease(t.Context(), r.ID)
+	assert.NotEqual(t, model.ReleaseCut, got.Status)
+}
+
+// --- Plan tests ---
+
+func TestCreatePlan(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	o, err := markdown.ParseOutline("# Login\n- Schema\n- API (after: 1)\n- Form (after: API)\n")
+	require.NoError(t, err)
+
+	res, err := CreatePlan(t.Context(), s, p.ID, o)
+	require.NoError(t, err)
+	require.Len(t, res.Epics, 1)
+	require.Len(t, res.Tasks, 3)
+	assert.Equal(t, model.TypeEpic, res.Epics[0].Type)
+	assert.Equal(t, res.Epics[0].ID, res.Tasks[1].Epic)
+	assert.Equal(t, []string{res.Tasks[0].ID}, res.Tasks[1].DependsOn)
+	assert.Equal(t, []string{res.Tasks[1].ID}, res.Tasks[2].DependsOn)
+}
+
+func TestCreatePlan_RollsBackOnFailure(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	o, err := markdown.ParseOutline("# Login\n- Schema\n- API (after: TP-TZZZZZ)\n")
+	require.NoError(t, err)
+
+	_, err = CreatePlan(t.Context(), s, p.ID, o)
+	assert.Error(t, err)
+
+	tasks, err := s.ListTasks(t.Context(), TaskFilter{ProjectID: p.ID})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestUpdateTask_Waiting(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+
+	w := &model.WaitingOn{Description: "vendor reply", URL: "https://example.com/t/1"}
+	_, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Waiting: &w})
+	require.NoError(t, err)
+
+	got, _, _ := s.GetTask(t.Context(), task.ID)
+	require.NotNil(t, got.Waiting)
+	assert.Equal(t, "vendor reply", got.Waiting.Description)
+
+	ready, err := s.ReadyTasks(t.Context(), p.ID)
+	require.NoError(t, err)
+	assert.Empty(t, ready)
+
+	var cleared *model.WaitingOn
+	_, err = s.UpdateTask(t.Context(), task.ID, TaskUpdate{Waiting: &cleared})
+	require.NoError(t, err)
+
+	ready, err = s.ReadyTasks(t.Context(), p.ID)
+	require.NoError(t, err)
+	assert.Len(t, ready, 1)
+}
+
+func TestReadyTasks_LapsedWait(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	_, err := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{Waiting: &model.WaitingOn{Description: "freeze", Until: "2000-01-01"}})
+	require.NoError(t, err)
+
+	ready, err := s.ReadyTasks(t.Context(), p.ID)
+	require.NoError(t, err)
+	assert.Len(t, ready, 1)
+}
+
+func TestReadyTasks_ManuallyBlocked(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+
+	blocked, reason := model.StatusBlocked, "vendor"
+	_, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Status: &blocked, BlockedReason: &reason})
+	require.NoError(t, err)
+	ready, err := s.ReadyTasks(t.Context(), p.ID)
+	require.NoError(t, err)
+	assert.Empty(t, ready)
+
+	open := model.StatusOpen
+	got, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Status: &open})
+	require.NoError(t, err)
+	assert.Empty(t, got.BlockedReason)
+	ready, err = s.ReadyTasks(t.Context(), p.ID)
+	require.NoError(t, err)
+	assert.Len(t, ready, 1)
+
+	_, err = s.UpdateTask(t.Context(), task.ID, TaskUpdate{BlockedReason: &reason})
+	assert.ErrorContains(t, err, "only allowed on blocked tasks")
+}
+
+// --- Adopt tests ---
+
+func TestAdoptTasks(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	t1, _ := s.CreateTask(t.Context(), "One", p.ID, TaskCreateOpts{})
+	t2, _ := s.CreateTask(t.Context(), "Two", p.ID, TaskCreateOpts{})
+
+	adopted, err := AdoptTasks(t.Context(), s, epic.ID, []string{t1.ID, t2.ID, t1.ID})
+	require.NoError(t, err)
+	assert.Len(t, adopted, 2)
+
+	got, _, _ := s.GetTask(t.Context(), t2.ID)
+	assert.Equal(t, epic.ID, got.Epic)
+}
+
+func TestAdoptTasks_ValidatesBeforeWriting(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	other, _ := s.CreateProject(t.Context(), "Other", "OT", "")
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	t1, _ := s.CreateTask(t.Context(), "One", p.ID, TaskCreateOpts{})
+	foreign, _ := s.CreateTask(t.Context(), "Foreign", other.ID, TaskCreateOpts{})
+
+	_, err := AdoptTasks(t.Context(), s, epic.ID, []string{t1.ID, foreign.ID})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), foreign.ID)
+
+	got, _, _ := s.GetTask(t.Context(), t1.ID)
+	assert.Empty(t, got.Epic)
+}
+
+func TestAdoptTasks_NotAnEpic(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	t1, _ := s.CreateTask(t.Context(), "One", p.ID, TaskCreateOpts{})
+	t2, _ := s.CreateTask(t.Context(), "Two", p.ID, TaskCreateOpts{})
+
+	_, err := AdoptTasks(t.Context(), s, t1.ID, []string{t2.ID})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not an epic")
+}
+
+// unauthorizedTask is a store that refuses to read one task.
+type unauthorizedTask struct {
+	Store
+	id string
+}
+
+func (u unauthorizedTask) GetTask(ctx context.Context, taskID string) (*model.Task, string, error) {
+	if taskID == u.id {
+		return nil, "", ErrUnauthorized
+	}
+	return u.Store.GetTask(ctx, taskID)
+}
+
+func TestAdoptTasks_StoreError(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	t1, _ := s.CreateTask(t.Context(), "One", p.ID, TaskCreateOpts{})
+
+	_, err := AdoptTasks(t.Context(), unauthorizedTask{s, t1.ID}, epic.ID, []string{t1.ID})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.NotErrorIs(t, err, ErrValidation)
+
+	_, err = AdoptTasks(t.Context(), unauthorizedTask{s, epic.ID}, epic.ID, []string{t1.ID})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.NotErrorIs(t, err, ErrNotFound)
+
+	_, err = AdoptTasks(t.Context(), s, epic.ID, []string{"TP-TZZZZZ"})
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.ErrorContains(t, err, "TP-TZZZZZ: not found")
+}
+
+// --- Blueprint tests ---
+
+func TestBlueprint_RoundTrip(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Source", "SRC", "Project notes")
+	wip := map[model.Status]int{model.StatusInProgress: 2}
+	autoClose, idLen := true, 6
+	_, err := s.UpdateProject(t.Context(), p.ID, ProjectUpdate{WIPLimits: &wip, AutoCloseEpics: &autoClose, IDLength: &idLen})
+	require.NoError(t, err)
+	epic, _ := s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	pri := 1
+	t1, _ := s.CreateTask(t.Context(), "First", p.ID, TaskCreateOpts{Epic: epic.ID, Priority: &pri, Body: "step one"})
+	t2, _ := s.CreateTask(t.Context(), "Second", p.ID, TaskCreateOpts{Epic: epic.ID, DependsOn: []string{t1.ID}})
+	closed := model.StatusClosed
+	s.UpdateTask(t.Context(), t2.ID, TaskUpdate{Status: &closed})
+	s.CreateDocument(t.Context(), "Runbook", p.ID, DocumentCreateOpts{Kind: model.DocRunbook, Body: "# Runbook"})
+
+	bp, err := ExportBlueprint(t.Context(), s, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Source", bp.Name)
+	assert.Len(t, bp.Tasks, 3)
+	assert.Len(t, bp.Documents, 1)
+
+	np, err := ApplyBlueprint(t.Context(), s, bp, "Copy", "CPY")
+	require.NoError(t, err)
+	assert.Equal(t, "CPY", np.ID)
+	assert.Equal(t, wip, np.WIPLimits)
+	assert.True(t, np.AutoCloseEpics)
+	assert.Equal(t, 6, np.IDLength)
+
+	tasks, err := s.ListTasks(t.Context(), TaskFilter{ProjectID: "CPY", Type: model.TypeTask})
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	byTitle := map[string]model.Task{}
+	for _, tk := range tasks {
+		byTitle[tk.Title] = tk
+		assert.Equal(t, model.StatusOpen, tk.Status)
+	}
+	assert.Equal(t, []string{byTitle["First"].ID}, byTitle["Second"].DependsOn)
+	require.NotNil(t, byTitle["First"].Priority)
+	_, body, _ := s.GetTask(t.Context(), byTitle["First"].ID)
+	assert.Equal(t, "step one", body)
+
+	assert.Len(t, strings.TrimPrefix(byTitle["First"].ID, "CPY-T"), 6, "id_length applies to new IDs")
+
+	docs, _ := s.ListDocuments(t.Context(), DocumentFilter{ProjectID: "CPY"})
+	require.Len(t, docs, 1)
+	assert.Equal(t, model.DocRunbook, docs[0].Kind)
+}
+
+func TestApplyBlueprint_RejectsCycle(t *testing.T) {
+	s := newTestStore(t)
+	bp := &Blueprint{Version: BlueprintVersion, Name: "Cyclic", Tasks: []BlueprintTask{
+		{Ref: "a", Title: "A", Type: model.TypeTask, DependsOn: []string{"b"}},
+		{Ref: "b", Title: "B", Type: model.TypeTask, DependsOn: []string{"a"}},
+	}}
+	_, err := ApplyBlueprint(t.Context(), s, bp, "", "CYC")
+	assert.Error(t, err)
+
+	projects, _ := s.ListProjects(t.Context())
+	assert.Empty(t, projects)
+}
+
+// --- Claim tests ---
+
+func TestClaimTask(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+
+	claimed, err := s.ClaimTask(t.Context(), p.ID)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, task.ID, claimed.ID)
+	assert.Equal(t, model.StatusInProgress, claimed.Status)
+
+	claimed, err = s.ClaimTask(t.Context(), p.ID)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+}
+
+func TestClaimTask_ConcurrentClaimsAreDistinct(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	for i := 0; i < 5; i++ {
+		s.CreateTask(t.Context(), fmt.Sprintf("Task %d", i), p.ID, TaskCreateOpts{})
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	seen := map[string]int{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			claimed, err := s.ClaimTask(t.Context(), p.ID)
+			if assert.NoError(t, err) && assert.NotNil(t, claimed) {
+				mu.Lock()
+				seen[claimed.ID]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 5)
+}
+
+func TestUpdateTask_WaitsForEntityLock(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+	path, err := s.ResolveEntityPath(task.ID)
+	require.NoError(t, err)
+
+	unlock, err := lockEntity(path)
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() {
+		title := "Renamed"
+		_, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Title: &title})
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Fatal("update ran while another process held the entity lock")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	require.NoError(t, <-done)
+
+	got, _, err := s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", got.Title)
+}
+
+func TestWriteEntity_LeavesNoTempOrLockFiles(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+	for i := 0; i < 3; i++ {
+		title := fmt.Sprintf("Title %d", i)
+		_, err := s.UpdateTask(t.Context(), task.ID, TaskUpdate{Title: &title})
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.DeleteProject(t.Context(), "TP"))
+
+	entries, err := os.ReadDir(s.BaseDir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.NotEqual(t, ".lock", e.Name())
+	}
+	p, _ = s.CreateProject(t.Context(), "Test Project", "TP", "")
+	task, _ = s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+	title := "Again"
+	_, err = s.UpdateTask(t.Context(), task.ID, TaskUpdate{Title: &title})
+	require.NoError(t, err)
+	files, err := os.ReadDir(filepath.Join(s.ProjectDir(p.ID), "tasks"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, task.ID+".md", files[0].Name())
+}
+
+func TestEnableCache(t *testing.T) {
+	s := newTestStore(t)
+	s.EnableCache()
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	dep, _ := s.CreateTask(t.Context(), "Dep", p.ID, TaskCreateOpts{})
+	task, _ := s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{DependsOn: []string{dep.ID}})
+
+	got, _, err := s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	got.DependsOn[0] = "TP-TZZZZZ"
+
+	// A change made by another process is picked up on the next read.
+	title := "Renamed"
+	_, err = NewLocal(s.BaseDir).UpdateTask(t.Context(), task.ID, TaskUpdate{Title: &title})
+	require.NoError(t, err)
+	got, _, err = s.GetTask(t.Context(), task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", got.Title)
+	assert.Equal(t, []string{dep.ID}, got.DependsOn)
+
+	require.NoError(t, s.DeleteTask(t.Context(), dep.ID))
+	require.NoError(t, s.WarmCache(t.Context()))
+	assert.Len(t, s.cache.entries, 2) // project.md and the remaining task
+}
+
+// --- Usage tests ---
+
+func TestUsage(t *testing.T) {
+	s := newTestStore(t)
+	p, _ := s.CreateProject(t.Context(), "Test Project", "TP", "")
+	s.CreateTask(t.Context(), "Epic", p.ID, TaskCreateOpts{Type: model.TypeEpic})
+	s.CreateTask(t.Context(), "Task", p.ID, TaskCreateOpts{})
+	s.CreateTask(t.Context(), "Task 2", p.ID, TaskCreateOpts{})
+	s.CreateDocument(t.Context(), "Doc", p.ID, DocumentCreateOpts{Body: "body"})
+	s.CreateRelease(t.Context(), "1.0.0", p.ID, ReleaseCreateOpts{})
+
+	u, err := Usage(t.Context(), s, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, u.Tasks)
+	assert.Equal(t, 1, u.Epics)
+	assert.Equal(t, 1, u.Documents)
+	assert.Equal(t, 1, u.Releases)
+	assert.Equal(t, 5, u.Entities())
+	assert.Greater(t, u.Bytes, int64(0))
+
+	total, err := s.DataDirSize()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, total, u.Bytes)
+}
+
+func TestValidateDir(t *testing.T) {
+	s := newTestStore(t)
+	_, err := s.CreateProject(t.Context(), "Auth", "AUTH", "")
+	require.NoError(t, err)
+	epic, err := s.CreateTask(t.Context(), "Epic", "AUTH", TaskCreateOpts{Type: model.TypeEpic})
+	require.NoError(t, err)
+	task, err := s.CreateTask(t.Context(), "Login", "AUTH", TaskCreateOpts{Epic: epic.ID})
+	require.NoError(t, err)
+
+	problems, files, err := ValidateDir(s.BaseDir)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+	assert.Equal(t, 3, files)
+
+	path := filepath.Join(s.ProjectDir("AUTH"), "tasks", task.ID+".md")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data = []byte(strings.Replace(string(data), "title: Login\n", "title: Login\nlabels: [auth]\ndue: soon\ndepends_on:\n    - AUTH-TZZZZZ\n", 1))
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	other, err := s.CreateTask(t.Context(), "Other", "AUTH", TaskCreateOpts{})
+	require.NoError(t, err)
+	path = filepath.Join(s.ProjectDir("AUTH"), "tasks", other.ID+".md")
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	data = []byte(strings.Replace(string(data), "title: Other\n", "title: Other\nepic: "+task.ID+"\n", 1))
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	problems, _, err = ValidateDir(s.BaseDir)
+	require.NoError(t, err)
+	var msgs []string
+	for _, p := range problems {
+		msgs = append(msgs, p.Entity+" "+p.Message)
+	}
+	assert.ElementsMatch(t, []string{
+		task.ID + " line 4: field labels not found in type model.Task",
+		task.ID + ` invalid due date "soon": must be YYYY-MM-DD`,
+		task.ID + " depends_on: AUTH-TZZZZZ: not found",
+		other.ID + " epic: " + task.ID + ": is not an epic",
+	}, msgs)
+}
+
+func TestErrorKinds(t *testing.T) {
+	s := newTestStore(t)
+	ctx := t.Context()
+	_, err := s.CreateProject(ctx, "Test", "TP", "")
+	require.NoError(t, err)
+	task, err := s.CreateTask(ctx, "Task", "TP", TaskCreateOpts{})
+	require.NoError(t, err)
+
+	_, _, err = s.GetTask(ctx, "TP-TZZZZZ")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, "TP-TZZZZZ not found", err.Error())
+
+	_, err = s.CreateProject(ctx, "Again", "TP", "")
+	assert.ErrorIs(t, err, ErrConflict)
+
+	_, err = s.CreateTask(ctx, "Sub", "TP", TaskCreateOpts{Epic: task.ID})
+	assert.ErrorIs(t, err, ErrValidation)
+	_, err = s.CreateTask(ctx, "", "TP", TaskCreateOpts{})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	dep := []string{task.ID}
+	other, err := s.CreateTask(ctx, "Other", "TP", TaskCreateOpts{DependsOn: dep})
+	require.NoError(t, err)
+	back := []string{other.ID}
+	_, err = s.UpdateTask(ctx, task.ID, TaskUpdate{DependsOn: &back})
+	assert.ErrorIs(t, err, ErrValidation)
+	var ce *dag.CycleError
+	assert.ErrorAs(t, err, &ce, "the cycle stays inspectable")
+}
@@ -1,6 +1,8 @@
 package store
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,6 +19,10 @@ type TaskCreateOpts struct {
 	Epic      string
 	Priority  *int
 	DependsOn []string
+	Waiting   *model.WaitingOn
+	Due       string
+	Assignee  string
+	Slug      string
 	Body      string
 }
 
@@ -31,13 +37,24 @@ type TaskUpdate struct {
 	Title     *string
 	Status    *model.Status
 	Priority  **int
+	Epic      *string
 	DependsOn *[]string
+	Waiting   **model.WaitingOn
+	Due       *string // "" clears
+	Assignee  *string // "" clears
+	Slug      *string // "" clears
+	Watchers  *[]string
+	// BlockedReason is cleared automatically when Status moves a task out
+	// of blocked.
+	BlockedReason *string
+	// AutoClose, on an epic, overrides its project's auto_close_epics.
+	AutoClose *bool
 	Body      *string
 }
 
-func (s *LocalStore) CreateTask(title, projectID string, opts TaskCreateOpts) (*model.Task, error) {
-	if _, _, err := s.GetProject(projectID); err != nil {
-		return nil, fmt.Errorf("project %s not found", projectID)
+func (s *LocalStore) CreateTask(ctx context.Context, title, projectID string, opts TaskCreateOpts) (*model.Task, error) {
+	if _, _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, notFoundf("project %s not found", projectID)
 	}
 
 	taskType := opts.Type
@@ -46,16 +63,12 @@ func (s *LocalStore) CreateTask(title, projectID string, opts TaskCreateOpts) (*
 	}
 
 	if opts.Epic != "" {
-		epic, _, err := s.GetTask(opts.Epic)
-		if err != nil {
-			return nil, fmt.Errorf("epic %s not found", opts.Epic)
-		}
-		if epic.Type != model.TypeEpic {
-			return nil, fmt.Errorf("%s is not an epic-type task", opts.Epic)
+		if _, err := validateEpic(ctx, s, opts.Epic, projectID); err != nil {
+			return nil, err
 		}
 	}
 
-	tid, err := id.NewTaskID(projectID)
+	tid, err := s.newID(ctx, projectID, id.Task)
 	if err != nil {
 		return nil, err
 	}
@@ -74,15 +87,22 @@ func (s *LocalStore) CreateTask(title, projectID string, opts TaskCreateOpts) (*
 		Status:    status,
 		Priority:  opts.Priority,
 		DependsOn: opts.DependsOn,
-		CreatedBy: currentUser(),
+		Waiting:   opts.Waiting,
+		Due:       opts.Due,
+		Assignee:  opts.Assignee,
+		Slug:      opts.Slug,
+		CreatedBy: CurrentUser(),
 		CreatedAt: now(),
 		UpdatedAt: now(),
 	}
 	if err := t.Validate(); err != nil {
-		return nil, err
+		return nil, invalid(err)
 	}
 
-	if err := s.validateDeps(t, projectID); err != nil {
+	if err := validateDeps(ctx, s, t, projectID); err != nil {
+		return nil, err
+	}
+	if err := checkSlug(ctx, s, projectID, t.Slug, ""); err != nil {
 		return nil, err
 	}
 
@@ -90,22 +110,25 @@ func (s *LocalStore) CreateTask(title, projectID string, opts TaskCreateOpts) (*
 	if err := s.WriteEntity(path, t, opts.Body); err != nil {
 		return nil, fmt.Errorf("writing task: %w", err)
 	}
+	if err := s.syncEpicClosed(ctx, t.Epic); err != nil {
+		return nil, err
+	}
 	return t, nil
 }
 
-func (s *LocalStore) GetTask(taskID string) (*model.Task, string, error) {
+func (s *LocalStore) GetTask(ctx context.Context, taskID string) (*model.Task, string, error) {
 	path, err := s.ResolveEntityPath(taskID)
 	if err != nil {
 		return nil, "", err
 	}
-	t, body, err := ReadEntity[model.Task](path)
+	t, body, err := readEntity[model.Task](s, path)
 	if err != nil {
 		return nil, "", err
 	}
 	return &t, body, nil
 }
 
-func (s *LocalStore) ListTasks(filter TaskFilter) ([]model.Task, error) {
+func (s *LocalStore) ListTasks(ctx context.Context, filter TaskFilter) ([]model.Task, error) {
 	var dirs []string
 	if filter.ProjectID != "" {
 		dirs = []string{s.ProjectDir(filter.ProjectID)}
@@ -125,7 +148,7 @@ func (s *LocalStore) ListTasks(filter TaskFilter) ([]model.Task, error) {
 			continue
 		}
 		for _, f := range files {
-			t, _, err := ReadEntity[model.Task](f)
+			t, _, err := readEntity[model.Task](s, f)
 			if err != nil {
 				continue
 			}
@@ -144,18 +167,32 @@ func (s *LocalStore) ListTasks(filter TaskFilter) ([]model.Task, error) {
 	return tasks, nil
 }
 
-func (s *LocalStore) UpdateTask(taskID string, upd TaskUpdate) (*model.Task, error) {
+// ListTasksPage lists tasks matching filter, sorted and windowed in memory.
+func (s *LocalStore) ListTasksPage(ctx context.Context, filter TaskFilter, page PageOpts) ([]model.Task, string, error) {
+	tasks, err := s.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	return pageSlice(tasks, page, taskSorts, TaskSortFields)
+}
+
+func (s *LocalStore) UpdateTask(ctx context.Context, taskID string, upd TaskUpdate) (*model.Task, error) {
 	path, err := s.ResolveEntityPath(taskID)
 	if err != nil {
 		return nil, err
 	}
+	unlock, err := lockEntity(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 	t, body, err := ReadEntity[model.Task](path)
 	if err != nil {
 		return nil, err
 	}
 
 	if upd.Status != nil && t.Type == model.TypeEpic {
-		return nil, fmt.Errorf("cannot change epic status: epics do not have a status")
+		return nil, invalidf("cannot change epic status: epics do not have a status")
 	}
 
 	// Clear any legacy stored status on epics before applying updates.
@@ -167,25 +204,78 @@ func (s *LocalStore) UpdateTask(taskID string, upd TaskUpdate) (*model.Task, err
 		t.Title = *upd.Title
 	}
 	if upd.Status != nil {
+		if *upd.Status != t.Status {
+			t.History = append(t.History, model.StatusChange{Status: *upd.Status, At: now(), By: CurrentUser()})
+		}
+		if *upd.Status == model.StatusClosed && t.Status != model.StatusClosed {
+			closedAt := now()
+			t.ClosedAt = &closedAt
+		} else if *upd.Status != model.StatusClosed {
+			t.ClosedAt = nil
+		}
 		t.Status = *upd.Status
+		if t.Status != model.StatusBlocked {
+			t.BlockedReason = ""
+		}
+	}
+	if upd.BlockedReason != nil {
+		t.BlockedReason = *upd.BlockedReason
 	}
 	if upd.Priority != nil {
 		t.Priority = *upd.Priority
 	}
+	prevEpic := t.Epic
+	if upd.Epic != nil {
+		if *upd.Epic != "" {
+			if _, err := validateEpic(ctx, s, *upd.Epic, t.Project); err != nil {
+				return nil, err
+			}
+		}
+		t.Epic = *upd.Epic
+	}
 	if upd.DependsOn != nil {
 		t.DependsOn = *upd.DependsOn
 	}
+	if upd.Waiting != nil {
+		t.Waiting = *upd.Waiting
+	}
+	if upd.Due != nil {
+		t.Due = *upd.Due
+	}
+	if upd.Assignee != nil {
+		t.Assignee = *upd.Assignee
+	}
+	if upd.Watchers != nil {
+		t.Watchers = *upd.Watchers
+	}
+	if upd.Slug != nil {
+		t.Slug = *upd.Slug
+	}
 	if upd.Body != nil {
 		body = *upd.Body
 	}
+	if upd.AutoClose != nil {
+		autoClose := *upd.AutoClose
+		t.AutoClose = &autoClose
+	}
 	t.UpdatedAt = now()
 
 	if err := t.Validate(); err != nil {
-		return nil, err
+		return nil, invalid(err)
+	}
+	if upd.AutoClose != nil {
+		if _, err := s.settleEpic(ctx, &t); err != nil {
+			return nil, err
+		}
 	}
 
 	if upd.DependsOn != nil {
-		if err := s.validateDeps(&t, t.Project); err != nil {
+		if err := validateDeps(ctx, s, &t, t.Project); err != nil {
+			return nil, err
+		}
+	}
+	if upd.Slug != nil {
+		if err := checkSlug(ctx, s, t.Project, t.Slug, t.ID); err != nil {
 			return nil, err
 		}
 	}
@@ -193,20 +283,86 @@ func (s *LocalStore) UpdateTask(taskID string, upd TaskUpdate) (*model.Task, err
 	if err := s.WriteEntity(path, &t, body); err != nil {
 		return nil, err
 	}
+	if upd.Status != nil || upd.Epic != nil {
+		for _, epicID := range []string{prevEpic, t.Epic} {
+			if err := s.syncEpicClosed(ctx, epicID); err != nil {
+				return nil, err
+			}
+			if t.Epic == prevEpic {
+				break
+			}
+		}
+	}
 	return &t, nil
 }
 
-func (s *LocalStore) DeleteTask(taskID string) error {
+// MoveTask reassigns a task to another project in this store under a new ID
+// in the target's key space, keeping its history, status and body. Epic and
+// dependency links are dropped because they cannot cross projects; callers
+// are responsible for dependents that still reference the old ID.
+func (s *LocalStore) MoveTask(ctx context.Context, taskID, projectID string) (*model.Task, error) {
 	path, err := s.ResolveEntityPath(taskID)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := lockEntity(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	t, body, err := ReadEntity[model.Task](path)
+	if err != nil {
+		return nil, err
+	}
+	if t.Type == model.TypeEpic {
+		return nil, invalidf("cannot move epic-type task %s; move its tasks individually", taskID)
+	}
+	if t.Project == projectID {
+		return nil, conflictf("%s is already in project %s", taskID, projectID)
+	}
+	if _, _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, notFoundf("project %s not found", projectID)
+	}
+	if err := checkSlug(ctx, s, projectID, t.Slug, ""); err != nil {
+		return nil, err
+	}
+
+	t.ID, err = s.newID(ctx, projectID, id.Task)
+	if err != nil {
+		return nil, err
+	}
+	t.Project = projectID
+	t.Epic = ""
+	t.DependsOn = nil
+	t.UpdatedAt = now()
+
+	dest := filepath.Join(s.ProjectDir(projectID), "tasks", t.ID+".md")
+	if err := s.WriteEntity(dest, &t, body); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *LocalStore) DeleteTask(ctx context.Context, taskID string) error {
+	path, err := s.ResolveEntityPath(taskID)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockEntity(path)
 	if err != nil {
 		return err
 	}
+	defer unlock()
+	s.changed.Store(true)
 	return os.Remove(path)
 }
 
 // AllTaskMap returns a map of all tasks in a project, keyed by ID.
-func (s *LocalStore) AllTaskMap(projectID string) (map[string]*model.Task, error) {
-	tasks, err := s.ListTasks(TaskFilter{ProjectID: projectID})
+func (s *LocalStore) AllTaskMap(ctx context.Context, projectID string) (map[string]*model.Task, error) {
+	tasks, err := s.ListTasks(ctx, TaskFilter{ProjectID: projectID})
 	if err != nil {
 		return nil, err
 	}
@@ -218,13 +374,14 @@ func (s *LocalStore) AllTaskMap(projectID string) (map[string]*model.Task, error
 }
 
 // ReadyTasks returns open, unblocked tasks (type=task only), oldest first.
-func (s *LocalStore) ReadyTasks(projectID string) ([]*model.Task, error) {
-	tasks, err := s.ListTasks(TaskFilter{ProjectID: projectID, Type: model.TypeTask})
+// Manually blocked tasks are never open, so they are excluded too.
+func (s *LocalStore) ReadyTasks(ctx context.Context, projectID string) ([]*model.Task, error) {
+	tasks, err := s.ListTasks(ctx, TaskFilter{ProjectID: projectID, Type: model.TypeTask})
 	if err != nil {
 		return nil, err
 	}
 
-	allTasks, err := s.AllTaskMap(projectID)
+	allTasks, err := s.AllTaskMap(ctx, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -232,7 +389,7 @@ func (s *LocalStore) ReadyTasks(projectID string) ([]*model.Task, error) {
 	var ready []*model.Task
 	for i := range tasks {
 		t := &tasks[i]
-		if t.Status == model.StatusOpen && !t.IsBlocked(allTasks) {
+		if t.Status == model.StatusOpen && !t.IsBlocked(allTasks, s.deps) {
 			ready = append(ready, t)
 		}
 	}
@@ -243,21 +400,126 @@ func (s *LocalStore) ReadyTasks(projectID string) ([]*model.Task, error) {
 	return ready, nil
 }
 
-func (s *LocalStore) validateDeps(t *model.Task, projectID string) error {
-	for _, dep := range t.DependsOn {
-		dt, _, err := s.GetTask(dep)
+// ClaimTask atomically picks the top ready task in a project and marks it
+// in_progress. A per-project lock file serializes concurrent claims. Returns
+// nil when no task is ready.
+func (s *LocalStore) ClaimTask(ctx context.Context, projectID string) (*model.Task, error) {
+	if _, _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, notFoundf("project %s not found", projectID)
+	}
+	unlock, err := acquireLock(filepath.Join(s.ProjectDir(projectID), ".claim.lock"))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	ready, err := s.ReadyTasks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ready) == 0 {
+		return nil, nil
+	}
+	status := model.StatusInProgress
+	return s.UpdateTask(ctx, ready[0].ID, TaskUpdate{Status: &status})
+}
+
+// syncEpicClosed closes epic epicID when it auto-closes and all its tasks
+// are closed, and reopens it when one isn't or it no longer auto-closes.
+func (s *LocalStore) syncEpicClosed(ctx context.Context, epicID string) error {
+	if epicID == "" {
+		return nil
+	}
+	path, err := s.ResolveEntityPath(epicID)
+	if errors.Is(err, ErrNotFound) {
+		// A dangling epic reference; maintain prune-refs removes those.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	unlock, err := lockEntity(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	epic, body, err := ReadEntity[model.Task](path)
+	if err != nil {
+		return err
+	}
+	changed, err := s.settleEpic(ctx, &epic)
+	if err != nil || !changed {
+		return err
+	}
+	epic.UpdatedAt = now()
+	return s.WriteEntity(path, &epic, body)
+}
+
+// settleEpic sets or clears epic's closed_at to match its tasks, as
+// syncEpicClosed describes, and reports whether it changed.
+func (s *LocalStore) settleEpic(ctx context.Context, epic *model.Task) (bool, error) {
+	p, _, err := s.GetProject(ctx, epic.Project)
+	if err != nil {
+		return false, err
+	}
+	done := false
+	if epic.AutoCloses(p) {
+		tasks, err := s.ListTasks(ctx, TaskFilter{ProjectID: epic.Project, EpicID: epic.ID})
 		if err != nil {
-			return fmt.Errorf("dependency %s not found", dep)
-		}
-		if dt.Project != projectID {
-			return fmt.Errorf("dependency %s is in project %s, not %s", dep, dt.Project, projectID)
+			return false, err
 		}
-		if dt.Type == model.TypeEpic {
-			return fmt.Errorf("cannot depend on epic-type task %s", dep)
+		done = len(tasks) > 0
+		for _, c := range tasks {
+			if c.Status != model.StatusClosed {
+				done = false
+				break
+			}
 		}
 	}
+	switch {
+	case done && epic.ClosedAt == nil:
+		closedAt := now()
+		epic.ClosedAt = &closedAt
+	case !done && epic.ClosedAt != nil:
+		epic.ClosedAt = nil
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// validateEpic returns epicID from s, checking that it is an epic in
+// projectID. An empty projectID accepts an epic in any project. Creating,
+// updating and adopting tasks all use it, so a parent epic is held to the
+// same rules however it is set.
+func validateEpic(ctx context.Context, s Store, epicID, projectID string) (*model.Task, error) {
+	epic, _, err := s.GetTask(ctx, epicID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, notFoundf("epic %s not found", epicID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if epic.Type != model.TypeEpic {
+		return nil, invalidf("%s is not an epic-type task", epicID)
+	}
+	if projectID != "" && epic.Project != projectID {
+		return nil, invalidf("epic %s is in project %s, not %s", epicID, epic.Project, projectID)
+	}
+	return epic, nil
+}
+
+// validateDeps checks t's dependencies in s: each must be a task, not an
+// epic, in projectID, and together with the project's other tasks they
+// must not form a cycle. A cycle is reported as a *dag.CycleError naming
+// the dependencies of t that would break it. Both stores use it, so a
+// dependency graph is held to the same rules wherever it lives.
+func validateDeps(ctx context.Context, s Store, t *model.Task, projectID string) error {
+	if err := validateDepTargets(ctx, s, t.DependsOn, projectID); err != nil {
+		return err
+	}
 
-	existing, err := s.ListTasks(TaskFilter{ProjectID: projectID, Type: model.TypeTask})
+	existing, err := s.ListTasks(ctx, TaskFilter{ProjectID: projectID, Type: model.TypeTask})
 	if err != nil {
 		return err
 	}
@@ -278,5 +540,27 @@ func (s *LocalStore) validateDeps(t *model.Task, projectID string) error {
 	}
 
 	g := dag.BuildFromTasks(allTasks)
-	return g.ValidateAcyclic()
+	err = g.ValidateAcyclic()
+	var ce *dag.CycleError
+	if errors.As(err, &ce) {
+		ce.Breakers = g.CycleBreakers(t.ID)
+	}
+	return invalid(err)
+}
+
+// validateDepTargets checks that each of deps is a task in projectID.
+func validateDepTargets(ctx context.Context, s Store, deps []string, projectID string) error {
+	for _, dep := range deps {
+		dt, _, err := s.GetTask(ctx, dep)
+		if err != nil {
+			return notFoundf("dependency %s not found", dep)
+		}
+		if dt.Project != projectID {
+			return invalidf("dependency %s is in project %s, not %s", dep, dt.Project, projectID)
+		}
+		if dt.Type == model.TypeEpic {
+			return invalidf("cannot depend on epic-type task %s", dep)
+		}
+	}
+	return nil
 }
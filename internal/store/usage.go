@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/rogersnm/compass/internal/model"
+)
+
+// ProjectUsage summarizes how much data a project holds in a store.
+type ProjectUsage struct {
+	Project   string
+	Tasks     int
+	Epics     int
+	Documents int
+	Releases  int
+	Bytes     int64 // -1 when the store cannot report disk usage
+}
+
+// Entities returns the total number of entities counted in u.
+func (u ProjectUsage) Entities() int {
+	return u.Tasks + u.Epics + u.Documents + u.Releases
+}
+
+// Usage counts the entities in a project. Disk usage is only available for
+// the local store.
+func Usage(ctx context.Context, s Store, projectID string) (ProjectUsage, error) {
+	u := ProjectUsage{Project: projectID, Bytes: -1}
+
+	tasks, err := s.ListTasks(ctx, TaskFilter{ProjectID: projectID})
+	if err != nil {
+		return u, err
+	}
+	for _, t := range tasks {
+		if t.Type == model.TypeEpic {
+			u.Epics++
+		} else {
+			u.Tasks++
+		}
+	}
+
+	docs, err := s.ListDocuments(ctx, DocumentFilter{ProjectID: projectID})
+	if err != nil {
+		return u, err
+	}
+	u.Documents = len(docs)
+
+	releases, err := s.ListReleases(ctx, projectID)
+	if err != nil {
+		return u, err
+	}
+	u.Releases = len(releases)
+
+	if ls, ok := AsLocal(s); ok {
+		if u.Bytes, err = dirSize(ls.ProjectDir(projectID)); err != nil {
+			return u, err
+		}
+	}
+	return u, nil
+}
+
+// DataDirSize returns the total size of the local data directory, including
+// config and any files outside project directories.
+func (s *LocalStore) DataDirSize() (int64, error) {
+	return dirSize(s.BaseDir)
+}
+
+func dirSize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, err
+}
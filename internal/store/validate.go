@@ -0,0 +1,292 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rogersnm/compass/internal/id"
+	"github.com/rogersnm/compass/internal/markdown"
+	"github.com/rogersnm/compass/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is one thing wrong with an entity file.
+type Problem struct {
+	File    string `json:"file"`
+	Entity  string `json:"entity,omitempty"`
+	Message string `json:"message"`
+}
+
+// Lookup reports whether an entity exists and, for tasks, whether it is an
+// epic. ValidateFile uses it to check references.
+type Lookup func(entityID string) (found, epic bool)
+
+// reference is an ID one entity mentions in its frontmatter.
+type reference struct {
+	field string
+	id    string
+	want  id.EntityType
+	epic  bool // the reference must be an epic
+}
+
+// checked is what validating one file learned about it.
+type checked struct {
+	id   string
+	kind id.EntityType
+	epic bool
+	refs []reference
+}
+
+// ValidateFile checks a single entity file: that its frontmatter has only
+// known fields and passes its model's validation, and that its ID matches
+// the file name. References are resolved with lookup, which may be nil to
+// skip them.
+func ValidateFile(path string, lookup Lookup) []Problem {
+	c, problems := checkFile(path, path)
+	if c != nil && lookup != nil {
+		problems = append(problems, checkRefs(path, c, lookup)...)
+	}
+	return problems
+}
+
+// ValidateDir checks every entity file under dir, which is either a local
+// data directory (containing projects/) or one project directory
+// (containing project.md), and the references between them. Files are
+// reported relative to dir. It returns the number of files checked.
+func ValidateDir(dir string) ([]Problem, int, error) {
+	var projectDirs []string
+	if _, err := os.Stat(filepath.Join(dir, "project.md")); err == nil {
+		projectDirs = []string{dir}
+	} else {
+		dirs, err := NewLocal(dir).listProjectDirs()
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(dirs) == 0 {
+			return nil, 0, fmt.Errorf("%s is neither a compass data directory nor a project directory", dir)
+		}
+		projectDirs = dirs
+	}
+
+	var files []string
+	for _, pd := range projectDirs {
+		files = append(files, filepath.Join(pd, "project.md"))
+		for _, sub := range []string{"tasks", "documents", "releases"} {
+			matches, err := filepath.Glob(filepath.Join(pd, sub, "*.md"))
+			if err != nil {
+				return nil, 0, err
+			}
+			files = append(files, matches...)
+		}
+	}
+
+	var problems []Problem
+	known := map[string]*checked{}
+	type entry struct {
+		rel string
+		c   *checked
+	}
+	var entries []entry
+	for _, f := range files {
+		rel, err := filepath.Rel(dir, f)
+		if err != nil {
+			rel = f
+		}
+		c, ps := checkFile(f, rel)
+		problems = append(problems, ps...)
+		if c == nil {
+			continue
+		}
+		if prev, ok := known[c.id]; ok && prev.kind == c.kind {
+			problems = append(problems, Problem{File: rel, Entity: c.id, Message: "duplicate ID"})
+			continue
+		}
+		known[c.id] = c
+		entries = append(entries, entry{rel, c})
+	}
+
+	lookup := func(ref string) (bool, bool) {
+		c, ok := known[ref]
+		return ok, ok && c.epic
+	}
+	for _, e := range entries {
+		problems = append(problems, checkRefs(e.rel, e.c, lookup)...)
+	}
+	return problems, len(files), nil
+}
+
+// checkFile validates path on its own, naming it name in problems. The
+// returned entity is nil when the file can't be read as one.
+func checkFile(path, name string) (*checked, []Problem) {
+	problem := func(entity, format string, args ...any) Problem {
+		return Problem{File: name, Entity: entity, Message: fmt.Sprintf(format, args...)}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, []Problem{problem("", "%v", err)}
+	}
+	front, _, err := markdown.Split(data)
+	if err != nil {
+		return nil, []Problem{problem("", "%v", err)}
+	}
+
+	base := filepath.Base(path)
+	var kind id.EntityType
+	if base == "project.md" {
+		kind = id.Project
+	} else if kind, err = id.TypeOf(strings.TrimSuffix(base, ".md")); err != nil {
+		return nil, []Problem{problem("", "file name is not an entity ID: %v", err)}
+	}
+	if sub := filepath.Base(filepath.Dir(path)); dirKinds[sub] != "" && dirKinds[sub] != kind {
+		return nil, []Problem{problem("", "a %s file in the %s directory", kind, sub)}
+	}
+
+	c := &checked{kind: kind}
+	var (
+		problems []Problem
+		complete bool
+		project  string
+		validate func() error
+	)
+	switch kind {
+	case id.Project:
+		var p model.Project
+		problems, complete = decodeStrict(front, &p, name)
+		c.id, validate = p.ID, p.Validate
+	case id.Task:
+		var t model.Task
+		problems, complete = decodeStrict(front, &t, name)
+		c.id, c.epic, project, validate = t.ID, t.Type == model.TypeEpic, t.Project, t.Validate
+		if t.Epic != "" {
+			c.refs = append(c.refs, reference{field: "epic", id: t.Epic, want: id.Task, epic: true})
+		}
+		for _, dep := range t.DependsOn {
+			c.refs = append(c.refs, reference{field: "depends_on", id: dep, want: id.Task})
+		}
+	case id.Document:
+		var d model.Document
+		problems, complete = decodeStrict(front, &d, name)
+		c.id, project, validate = d.ID, d.Project, d.Validate
+		if d.Supersedes != "" {
+			c.refs = append(c.refs, reference{field: "supersedes", id: d.Supersedes, want: id.Document})
+		}
+		if d.SupersededBy != "" {
+			c.refs = append(c.refs, reference{field: "superseded_by", id: d.SupersededBy, want: id.Document})
+		}
+	case id.Release:
+		var r model.Release
+		problems, complete = decodeStrict(front, &r, name)
+		c.id, project, validate = r.ID, r.Project, r.Validate
+		for _, item := range r.Items {
+			c.refs = append(c.refs, reference{field: "items", id: item, want: id.Task})
+		}
+		if r.Changelog != "" {
+			c.refs = append(c.refs, reference{field: "changelog", id: r.Changelog, want: id.Document})
+		}
+	}
+	for i := range problems {
+		problems[i].Entity = c.id
+	}
+	// A value that failed to decode is left zero, so validating the rest
+	// would only report follow-on errors.
+	if complete {
+		if err := validate(); err != nil {
+			problems = append(problems, problem(c.id, "%v", err))
+		}
+	}
+	if c.id == "" {
+		return nil, problems
+	}
+	key, idKind, _, err := id.Parse(c.id)
+	dir := filepath.Dir(path)
+	switch {
+	case err != nil:
+		problems = append(problems, problem(c.id, "%v", err))
+	case idKind != kind:
+		problems = append(problems, problem(c.id, "ID is a %s ID in a %s file", idKind, kind))
+	case kind == id.Project && filepath.Base(filepath.Dir(dir)) == "projects" && filepath.Base(dir) != c.id:
+		problems = append(problems, problem(c.id, "project directory is named %s", filepath.Base(dir)))
+	case kind != id.Project && base != c.id+".md":
+		problems = append(problems, problem(c.id, "file is named %s", base))
+	case kind != id.Project && project != "" && project != key:
+		problems = append(problems, problem(c.id, "project is %s but the ID belongs to %s", project, key))
+	}
+	return c, problems
+}
+
+// dirKinds maps the store's entity subdirectories to what they hold.
+var dirKinds = map[string]id.EntityType{
+	"tasks":     id.Task,
+	"documents": id.Document,
+	"releases":  id.Release,
+}
+
+func checkRefs(name string, c *checked, lookup Lookup) []Problem {
+	var problems []Problem
+	for _, r := range c.refs {
+		problem := func(format string, args ...any) {
+			msg := fmt.Sprintf("%s: %s: ", r.field, r.id) + fmt.Sprintf(format, args...)
+			problems = append(problems, Problem{File: name, Entity: c.id, Message: msg})
+		}
+		kind, err := id.TypeOf(r.id)
+		if err != nil {
+			problem("not a valid ID")
+			continue
+		}
+		if kind != r.want {
+			problem("is a %s, not a %s", kind, r.want)
+			continue
+		}
+		found, epic := lookup(r.id)
+		switch {
+		case !found:
+			problem("not found")
+		case r.epic && !epic:
+			problem("is not an epic")
+		}
+	}
+	return problems
+}
+
+// decodeStrict decodes front into v, reporting each unknown field and
+// badly typed value (dates included) as its own problem. complete is false
+// if any known field failed to decode.
+func decodeStrict(front []byte, v any, name string) (problems []Problem, complete bool) {
+	dec := yaml.NewDecoder(bytes.NewReader(front))
+	dec.KnownFields(true)
+	err := dec.Decode(v)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil, true
+	}
+	var te *yaml.TypeError
+	if !errors.As(err, &te) {
+		return []Problem{{File: name, Message: fileLine(strings.TrimPrefix(err.Error(), "yaml: "))}}, false
+	}
+	complete = true
+	for _, msg := range te.Errors {
+		problems = append(problems, Problem{File: name, Message: fileLine(msg)})
+		if !strings.Contains(msg, " not found in type ") {
+			complete = false
+		}
+	}
+	return problems, complete
+}
+
+var yamlLineRe = regexp.MustCompile(`^line (\d+):`)
+
+// fileLine rewrites the frontmatter line numbers in a YAML error to line
+// numbers in the file, which has the opening --- line above them.
+func fileLine(msg string) string {
+	return yamlLineRe.ReplaceAllStringFunc(strings.TrimSpace(msg), func(m string) string {
+		n, _ := strconv.Atoi(yamlLineRe.FindStringSubmatch(m)[1])
+		return fmt.Sprintf("line %d:", n+1)
+	})
+}
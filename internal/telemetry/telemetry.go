@@ -0,0 +1,149 @@
+// Package telemetry records anonymous usage events: which command ran, how
+// long it took and what kind of store it used. Events are buffered in the
+// data directory and sent in batches. Nothing is recorded unless the user
+// turns telemetry on.
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultEndpoint receives events when config.yaml names no other.
+const DefaultEndpoint = "https://compasscloud.io/api/v1/telemetry"
+
+// FileName is the buffer of unsent events, one JSON object per line.
+const FileName = "telemetry.jsonl"
+
+// Events are sent once this many are buffered, or the oldest is a day old.
+const (
+	batchSize = 20
+	maxAge    = 24 * time.Hour
+)
+
+// Event is one command run. It carries no arguments, IDs, hostnames or
+// content; Time is truncated to the hour.
+type Event struct {
+	Command    string    `json:"command"`     // e.g. "task create"
+	DurationMS int64     `json:"duration_ms"` // wall time of the command
+	StoreType  string    `json:"store_type"`  // "local", "cloud" or "none"
+	OK         bool      `json:"ok"`          // false if the command failed
+	Version    string    `json:"version"`
+	OS         string    `json:"os"`
+	Arch       string    `json:"arch"`
+	Time       time.Time `json:"time"`
+}
+
+// Record appends e to the buffer in dataDir.
+func Record(dataDir string, e Event) error {
+	e.Time = e.Time.UTC().Truncate(time.Hour)
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dataDir, FileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Buffered returns the events waiting to be sent, oldest first.
+func Buffered(dataDir string) ([]Event, error) {
+	return readEvents(filepath.Join(dataDir, FileName))
+}
+
+func readEvents(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var events []Event
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e Event
+		// Skip lines cut short by a crash rather than losing the rest.
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			events = append(events, e)
+		}
+	}
+	return events, sc.Err()
+}
+
+// Clear deletes buffered events.
+func Clear(dataDir string) error {
+	err := os.Remove(filepath.Join(dataDir, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Due reports whether enough events are buffered, or the oldest has waited
+// long enough, to send them.
+func Due(events []Event, now time.Time) bool {
+	return len(events) >= batchSize || len(events) > 0 && now.Sub(events[0].Time) >= maxAge
+}
+
+// Flush sends the buffered events to endpoint as {"events": [...]}. The
+// buffer is moved aside first so commands running meanwhile start a new
+// one; if sending fails, the events are put back.
+func Flush(ctx context.Context, dataDir, endpoint string) error {
+	path := filepath.Join(dataDir, FileName)
+	sending := fmt.Sprintf("%s.sending-%d", path, os.Getpid())
+	if err := os.Rename(path, sending); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer os.Remove(sending)
+	events, err := readEvents(sending)
+	if err != nil || len(events) == 0 {
+		return err
+	}
+	if err := send(ctx, endpoint, events); err != nil {
+		for _, e := range events {
+			Record(dataDir, e)
+		}
+		return err
+	}
+	return nil
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+func send(ctx context.Context, endpoint string, events []Event) error {
+	data, err := json.Marshal(map[string]any{"events": events})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+	return nil
+}
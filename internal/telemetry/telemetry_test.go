@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndFlush(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 1, 14, 35, 0, 0, time.UTC)
+	require.NoError(t, Record(dir, Event{Command: "task create", DurationMS: 12, StoreType: "local", OK: true, Time: now}))
+	require.NoError(t, Record(dir, Event{Command: "task list", StoreType: "cloud", Time: now}))
+
+	events, err := Buffered(dir)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "task create", events[0].Command)
+	assert.Equal(t, time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC), events[0].Time, "times are kept to the hour")
+	assert.False(t, Due(events, now))
+	assert.True(t, Due(events, now.Add(25*time.Hour)))
+
+	var got struct {
+		Events []Event `json:"events"`
+	}
+	status := http.StatusInternalServerError
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	// a failed send keeps the events
+	assert.Error(t, Flush(t.Context(), dir, srv.URL))
+	events, err = Buffered(dir)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	status = http.StatusNoContent
+	require.NoError(t, Flush(t.Context(), dir, srv.URL))
+	assert.Len(t, got.Events, 2)
+	assert.Equal(t, "cloud", got.Events[1].StoreType)
+	events, err = Buffered(dir)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestBuffered_SkipsTornLines(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, Record(dir, Event{Command: "task show"}))
+	f, err := os.OpenFile(filepath.Join(dir, FileName), os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	f.WriteString(`{"command": "task cl`)
+	f.Close()
+
+	events, err := Buffered(dir)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "task show", events[0].Command)
+
+	require.NoError(t, Clear(dir))
+	require.NoError(t, Clear(dir), "clearing twice is fine")
+}
@@ -0,0 +1,283 @@
+// Package upgrade finds compass releases, verifies their archives against
+// the published checksums and swaps the running binary for the new one.
+package upgrade
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Release feeds. Both return the GitHub release JSON: a tag_name and the
+// assets attached to it.
+const (
+	GitHubURL       = "https://api.github.com/repos/rogersnm/compass/releases/latest"
+	CompassCloudURL = "https://compasscloud.io/api/v1/releases/latest"
+)
+
+// FeedURL resolves the update_source setting: "github" (the default),
+// "compasscloud", or the URL of a feed serving the same JSON.
+func FeedURL(source string) (string, error) {
+	switch source {
+	case "", "github":
+		return GitHubURL, nil
+	case "compasscloud":
+		return CompassCloudURL, nil
+	}
+	if strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") {
+		return source, nil
+	}
+	return "", fmt.Errorf("unknown update source %q (valid: github, compasscloud or a URL)", source)
+}
+
+// Release is a published version and its downloadable files.
+type Release struct {
+	Version string            // without the leading "v"
+	Assets  map[string]string // file name -> download URL
+}
+
+// Client fetches releases from a feed.
+type Client struct {
+	HTTP *http.Client
+	Feed string
+}
+
+// NewClient returns a client for the feed at url.
+func NewClient(url string) *Client {
+	return &Client{HTTP: &http.Client{Timeout: 60 * time.Second}, Feed: url}
+}
+
+// Latest returns the newest release on the feed.
+func (c *Client) Latest(ctx context.Context) (*Release, error) {
+	data, err := c.get(ctx, c.Feed)
+	if err != nil {
+		return nil, fmt.Errorf("checking for releases: %w", err)
+	}
+	var feed struct {
+		TagName string `json:"tag_name"`
+		Assets  []struct {
+			Name string `json:"name"`
+			URL  string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return nil, fmt.Errorf("reading release feed: %w", err)
+	}
+	if feed.TagName == "" {
+		return nil, fmt.Errorf("release feed has no tag_name")
+	}
+	rel := &Release{Version: strings.TrimPrefix(feed.TagName, "v"), Assets: map[string]string{}}
+	for _, a := range feed.Assets {
+		rel.Assets[a.Name] = a.URL
+	}
+	return rel, nil
+}
+
+// Download fetches the release's archive for goos/goarch, checks it
+// against checksums.txt and returns the compass binary inside.
+func (c *Client) Download(ctx context.Context, rel *Release, goos, goarch string) ([]byte, error) {
+	name := ArchiveName(rel.Version, goos, goarch)
+	url, ok := rel.Assets[name]
+	if !ok {
+		return nil, fmt.Errorf("release %s has no build for %s/%s", rel.Version, goos, goarch)
+	}
+	sumsURL, ok := rel.Assets["checksums.txt"]
+	if !ok {
+		return nil, fmt.Errorf("release %s has no checksums.txt", rel.Version)
+	}
+	sums, err := c.get(ctx, sumsURL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading checksums: %w", err)
+	}
+	archive, err := c.get(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", name, err)
+	}
+	if err := Verify(archive, name, sums); err != nil {
+		return nil, err
+	}
+	return extract(archive, name, binaryName(goos))
+}
+
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ArchiveName is the release archive for a platform, as GoReleaser names
+// it: compass_1.4.0_darwin_arm64.tar.gz, or .zip on Windows.
+func ArchiveName(version, goos, goarch string) string {
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("compass_%s_%s_%s%s", version, goos, goarch, ext)
+}
+
+func binaryName(goos string) string {
+	if goos == "windows" {
+		return "compass.exe"
+	}
+	return "compass"
+}
+
+// Verify checks archive against its line in a checksums.txt of
+// "<sha256>  <file name>" lines.
+func Verify(archive []byte, name string, sums []byte) error {
+	sc := bufio.NewScanner(bytes.NewReader(sums))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 || fields[1] != name {
+			continue
+		}
+		sum := sha256.Sum256(archive)
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, fields[0]) {
+			return fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, fields[0])
+		}
+		return nil
+	}
+	return fmt.Errorf("checksums.txt has no entry for %s", name)
+}
+
+// extract returns the file named bin from a .tar.gz or .zip archive.
+func extract(archive []byte, name, bin string) ([]byte, error) {
+	if strings.HasSuffix(name, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %w", name, err)
+		}
+		for _, f := range zr.File {
+			if path.Base(f.Name) != bin {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(rc)
+		}
+		return nil, fmt.Errorf("%s has no %s", name, bin)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", name, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s has no %s", name, bin)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		if h.Typeflag == tar.TypeReg && path.Base(h.Name) == bin {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// Install replaces the executable at exe with bin. The new binary is
+// written next to it and renamed into place, so an interrupted upgrade
+// leaves the old one working.
+func Install(exe string, bin []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".compass-upgrade-*")
+	if err != nil {
+		return fmt.Errorf("writing new binary: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(bin); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing new binary: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	// Windows can't replace a running executable, but can rename it.
+	old := exe + ".old"
+	os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return fmt.Errorf("replacing %s: %w", exe, err)
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		os.Rename(old, exe)
+		return fmt.Errorf("replacing %s: %w", exe, err)
+	}
+	os.Remove(old)
+	return nil
+}
+
+// Newer reports whether version latest is newer than current. A current
+// version that isn't a release, such as "dev", is never behind.
+func Newer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range 3 {
+		if l.nums[i] != c.nums[i] {
+			return l.nums[i] > c.nums[i]
+		}
+	}
+	// 1.2.0 is newer than 1.2.0-rc1.
+	return l.pre == "" && c.pre != "" || l.pre != "" && c.pre != "" && l.pre > c.pre
+}
+
+type semver struct {
+	nums [3]int
+	pre  string
+}
+
+func parseVersion(v string) (semver, bool) {
+	var s semver
+	v = strings.TrimPrefix(v, "v")
+	v, s.pre, _ = strings.Cut(v, "-")
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return s, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return s, false
+		}
+		s.nums[i] = n
+	}
+	return s, true
+}
@@ -0,0 +1,152 @@
+package upgrade
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewer(t *testing.T) {
+	assert.True(t, Newer("1.4.0", "1.3.9"))
+	assert.True(t, Newer("v1.10.0", "1.9.0"))
+	assert.True(t, Newer("1.4.0", "1.4.0-rc1"))
+	assert.False(t, Newer("1.4.0", "1.4.0"))
+	assert.False(t, Newer("1.3.0", "1.4.0"))
+	assert.False(t, Newer("1.4.0-rc1", "1.4.0"))
+	assert.False(t, Newer("1.4.0", "dev"))
+	assert.False(t, Newer("", "1.4.0"))
+}
+
+func TestFeedURL(t *testing.T) {
+	u, err := FeedURL("")
+	require.NoError(t, err)
+	assert.Equal(t, GitHubURL, u)
+	u, err = FeedURL("compasscloud")
+	require.NoError(t, err)
+	assert.Equal(t, CompassCloudURL, u)
+	u, err = FeedURL("https://releases.example.com/latest")
+	require.NoError(t, err)
+	assert.Equal(t, "https://releases.example.com/latest", u)
+	_, err = FeedURL("gitlab")
+	assert.Error(t, err)
+}
+
+func tarGz(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{"README.md", []byte("readme")}, {name, content}} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0755, Size: int64(len(f.data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(f.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// releaseServer serves a feed for version with one archive per entry of
+// archives, and a checksums.txt listing them.
+func releaseServer(t *testing.T, version string, archives map[string][]byte) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	var sums bytes.Buffer
+	type asset struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	}
+	assets := []asset{{"checksums.txt", srv.URL + "/checksums.txt"}}
+	for name, data := range archives {
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(&sums, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+		assets = append(assets, asset{name, srv.URL + "/" + name})
+		mux.HandleFunc("/"+name, func(w http.ResponseWriter, r *http.Request) { w.Write(data) })
+	}
+	mux.HandleFunc("/checksums.txt", func(w http.ResponseWriter, r *http.Request) { w.Write(sums.Bytes()) })
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"tag_name": "v" + version, "assets": assets})
+	})
+	return srv
+}
+
+func TestDownload(t *testing.T) {
+	name := ArchiveName("1.4.0", "linux", "amd64")
+	srv := releaseServer(t, "1.4.0", map[string][]byte{name: tarGz(t, "compass", []byte("new binary"))})
+
+	c := NewClient(srv.URL + "/latest")
+	rel, err := c.Latest(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.0", rel.Version)
+
+	bin, err := c.Download(t.Context(), rel, "linux", "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(bin))
+
+	_, err = c.Download(t.Context(), rel, "plan9", "amd64")
+	assert.ErrorContains(t, err, "no build for plan9/amd64")
+}
+
+func TestDownload_Zip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("compass.exe")
+	require.NoError(t, err)
+	w.Write([]byte("windows binary"))
+	require.NoError(t, zw.Close())
+
+	name := ArchiveName("1.4.0", "windows", "amd64")
+	assert.Equal(t, "compass_1.4.0_windows_amd64.zip", name)
+	srv := releaseServer(t, "1.4.0", map[string][]byte{name: buf.Bytes()})
+	c := NewClient(srv.URL + "/latest")
+	rel, err := c.Latest(t.Context())
+	require.NoError(t, err)
+	bin, err := c.Download(t.Context(), rel, "windows", "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, "windows binary", string(bin))
+}
+
+func TestVerify(t *testing.T) {
+	archive := []byte("archive")
+	sum := sha256.Sum256(archive)
+	sums := []byte(hex.EncodeToString(sum[:]) + "  compass_1.4.0_linux_amd64.tar.gz\n")
+
+	assert.NoError(t, Verify(archive, "compass_1.4.0_linux_amd64.tar.gz", sums))
+	assert.ErrorContains(t, Verify([]byte("tampered"), "compass_1.4.0_linux_amd64.tar.gz", sums), "checksum mismatch")
+	assert.ErrorContains(t, Verify(archive, "compass_1.4.0_darwin_arm64.tar.gz", sums), "no entry")
+}
+
+func TestInstall(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "compass")
+	require.NoError(t, os.WriteFile(exe, []byte("old binary"), 0755))
+
+	require.NoError(t, Install(exe, []byte("new binary")))
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(data))
+	info, err := os.Stat(exe)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	entries, err := os.ReadDir(filepath.Dir(exe))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temp or .old files are left behind")
+}